@@ -276,6 +276,7 @@ export const FILTERS: Record<NegatableFilter, NegatableFilterDefinition> &
     },
     [FilterType.rev]: {
         alias: 'rev',
+        discreteValues: () => predicateCompletion('rev'),
         description: 'Search a revision (branch, commit hash, or tag) instead of the default branch.',
         singular: true,
     },
@@ -165,6 +165,16 @@ const toContainsHover = (token: MetaPredicate): string => {
             return `**Built-in predicate**. Search only inside repositories that contain **file content** matching the regular expression \`${parameters}\`.`
         case 'contains.commit.after':
             return `**Built-in predicate**. Search only inside repositories that have been committed to since \`${parameters}\`.`
+        case 'has.owner':
+            return parameters
+                ? `**Built-in predicate**. Search only inside files owned by \`${parameters}\` according to the CODEOWNERS file of their repository.`
+                : '**Built-in predicate**. Search only inside files that have an owner according to the CODEOWNERS file of their repository.'
+        case 'at.time': {
+            const [time, reference] = parameters.split(',').map(parameter => parameter.trim())
+            return `**Built-in predicate**. Search the last commit before \`${time}\` of ${
+                reference ? `\`${reference}\`` : 'the default branch'
+            }.`
+        }
     }
     return ''
 }
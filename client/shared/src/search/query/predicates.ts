@@ -35,6 +35,19 @@ export const PREDICATES: Access[] = [
                 name: 'contains',
                 fields: [{ name: 'content' }],
             },
+            {
+                name: 'has',
+                fields: [{ name: 'owner' }],
+            },
+        ],
+    },
+    {
+        name: 'rev',
+        fields: [
+            {
+                name: 'at',
+                fields: [{ name: 'time' }],
+            },
         ],
     },
 ]
@@ -166,5 +179,14 @@ export const predicateCompletion = (field: string): Completion[] => {
             },
         ]
     }
+    if (field === 'rev') {
+        return [
+            {
+                label: 'at.time(...)',
+                insertText: 'at.time(${1:1 year ago})',
+                asSnippet: true,
+            },
+        ]
+    }
     return []
 }
@@ -26,6 +26,7 @@ export interface PathMatch {
     repoStars?: number
     branches?: string[]
     version?: string
+    owners?: string[]
 }
 
 export interface ContentMatch {
@@ -35,6 +36,7 @@ export interface ContentMatch {
     repoStars?: number
     branches?: string[]
     version?: string
+    owners?: string[]
     lineMatches: LineMatch[]
 }
 
@@ -52,6 +54,7 @@ export interface SymbolMatch {
     repoStars?: number
     branches?: string[]
     version?: string
+    owners?: string[]
     symbols: MatchedSymbol[]
 }
 
@@ -37,13 +37,19 @@ export const SignInPage: React.FunctionComponent<SignInPageProps> = props => {
         return <Redirect to={returnTo} />
     }
 
-    const [[builtInAuthProvider], thirdPartyAuthProviders] = partition(
+    const [[builtInAuthProvider], nonBuiltinAuthProviders] = partition(
         props.context.authProviders,
         provider => provider.isBuiltin
     )
+    // LDAP auth providers sign in with a username and password, like the builtin auth provider.
+    const [ldapAuthProviders, thirdPartyAuthProviders] = partition(
+        nonBuiltinAuthProviders,
+        provider => provider.serviceType === 'ldap'
+    )
+    const hasPasswordForm = !!builtInAuthProvider || ldapAuthProviders.length > 0
 
     const body =
-        !builtInAuthProvider && thirdPartyAuthProviders.length === 0 ? (
+        !hasPasswordForm && thirdPartyAuthProviders.length === 0 ? (
             <div className="alert alert-info mt-3">
                 No authentication providers are available. Contact a site administrator for help.
             </div>
@@ -60,10 +66,26 @@ export const SignInPage: React.FunctionComponent<SignInPageProps> = props => {
                         <UsernamePasswordSignInForm
                             {...props}
                             onAuthError={setError}
-                            noThirdPartyProviders={thirdPartyAuthProviders.length === 0}
+                            noThirdPartyProviders={
+                                ldapAuthProviders.length === 0 && thirdPartyAuthProviders.length === 0
+                            }
                         />
                     )}
-                    {builtInAuthProvider && thirdPartyAuthProviders.length > 0 && <OrDivider className="mb-3 py-1" />}
+                    {ldapAuthProviders.map((provider, index) => (
+                        /* eslint-disable react/no-array-index-key */
+                        <React.Fragment key={index}>
+                            {(builtInAuthProvider || index > 0) && <OrDivider className="mb-3 py-1" />}
+                            <UsernamePasswordSignInForm
+                                {...props}
+                                provider={provider}
+                                onAuthError={setError}
+                                noThirdPartyProviders={
+                                    index === ldapAuthProviders.length - 1 && thirdPartyAuthProviders.length === 0
+                                }
+                            />
+                        </React.Fragment>
+                    ))}
+                    {hasPasswordForm && thirdPartyAuthProviders.length > 0 && <OrDivider className="mb-3 py-1" />}
                     {thirdPartyAuthProviders.map((provider, index) => (
                         // Use index as key because display name may not be unique. This is OK
                         // here because this list will not be updated during this component's lifetime.
@@ -17,6 +17,11 @@ interface Props {
     history: H.History
     onAuthError: (error: Error | null) => void
     noThirdPartyProviders?: boolean
+    /**
+     * The LDAP auth provider to sign in with. If not set, the user signs in with the builtin auth
+     * provider.
+     */
+    provider?: SourcegraphContext['authProviders'][number]
     context: Pick<
         SourcegraphContext,
         'allowSignup' | 'authProviders' | 'sourcegraphDotComMode' | 'xhrHeaders' | 'resetPasswordEnabled'
@@ -24,17 +29,21 @@ interface Props {
 }
 
 /**
- * The form for signing in with a username and password.
+ * The form for signing in with a username and password, which are checked by the builtin auth
+ * provider or an LDAP auth provider.
  */
 export const UsernamePasswordSignInForm: React.FunctionComponent<Props> = ({
     location,
     onAuthError,
     noThirdPartyProviders,
+    provider,
     context,
 }) => {
     const [usernameOrEmail, setUsernameOrEmail] = useState('')
     const [password, setPassword] = useState('')
     const [loading, setLoading] = useState(false)
+    // The sign-in page may show several of these forms, whose input IDs must be distinct.
+    const idSuffix = provider ? `-${provider.authenticationURL ?? provider.displayName}` : ''
 
     const onUsernameOrEmailFieldChange = useCallback((event: React.ChangeEvent<HTMLInputElement>): void => {
         setUsernameOrEmail(event.target.value)
@@ -53,7 +62,7 @@ export const UsernamePasswordSignInForm: React.FunctionComponent<Props> = ({
 
             setLoading(true)
             eventLogger.log('InitiateSignIn')
-            fetch('/-/sign-in', {
+            fetch(provider?.authenticationURL ?? '/-/sign-in', {
                 credentials: 'same-origin',
                 method: 'POST',
                 headers: {
@@ -61,10 +70,9 @@ export const UsernamePasswordSignInForm: React.FunctionComponent<Props> = ({
                     Accept: 'application/json',
                     'Content-Type': 'application/json',
                 },
-                body: JSON.stringify({
-                    email: usernameOrEmail,
-                    password,
-                }),
+                body: JSON.stringify(
+                    provider ? { username: usernameOrEmail, password } : { email: usernameOrEmail, password }
+                ),
             })
                 .then(response => {
                     if (response.status === 200) {
@@ -86,18 +94,18 @@ export const UsernamePasswordSignInForm: React.FunctionComponent<Props> = ({
                     onAuthError(asError(error))
                 })
         },
-        [usernameOrEmail, loading, location, password, onAuthError, context]
+        [usernameOrEmail, loading, location, password, onAuthError, context, provider]
     )
 
     return (
         <>
             <Form onSubmit={handleSubmit}>
                 <div className="form-group d-flex flex-column align-content-start">
-                    <label htmlFor="username-or-email" className="align-self-start">
-                        Username or email
+                    <label htmlFor={`username-or-email${idSuffix}`} className="align-self-start">
+                        {provider ? `${provider.displayName} username` : 'Username or email'}
                     </label>
                     <input
-                        id="username-or-email"
+                        id={`username-or-email${idSuffix}`}
                         className="form-control signin-signup-form__input"
                         type="text"
                         onChange={onUsernameOrEmailFieldChange}
@@ -105,7 +113,7 @@ export const UsernamePasswordSignInForm: React.FunctionComponent<Props> = ({
                         value={usernameOrEmail}
                         disabled={loading}
                         autoCapitalize="off"
-                        autoFocus={true}
+                        autoFocus={!provider}
                         // There is no well supported way to declare username OR email here.
                         // Using username seems to be the best approach and should still support this behaviour.
                         // See: https://github.com/whatwg/html/issues/4445
@@ -114,14 +122,15 @@ export const UsernamePasswordSignInForm: React.FunctionComponent<Props> = ({
                 </div>
                 <div className="form-group d-flex flex-column align-content-start">
                     <div className="d-flex justify-content-between">
-                        <label htmlFor="password">Password</label>
-                        {context.resetPasswordEnabled && (
+                        <label htmlFor={`password${idSuffix}`}>Password</label>
+                        {context.resetPasswordEnabled && !provider && (
                             <small className="form-text text-muted">
                                 <Link to="/password-reset">Forgot password?</Link>
                             </small>
                         )}
                     </div>
                     <PasswordInput
+                        id={`password${idSuffix}`}
                         className="signin-signup-form__input"
                         onChange={onPasswordFieldChange}
                         value={password}
@@ -137,7 +146,13 @@ export const UsernamePasswordSignInForm: React.FunctionComponent<Props> = ({
                     })}
                 >
                     <button className="btn btn-primary btn-block" type="submit" disabled={loading}>
-                        {loading ? <LoadingSpinner className="icon-inline" /> : 'Sign in'}
+                        {loading ? (
+                            <LoadingSpinner className="icon-inline" />
+                        ) : provider ? (
+                            `Sign in with ${provider.displayName}`
+                        ) : (
+                            'Sign in'
+                        )}
                     </button>
                 </div>
             </Form>
@@ -4,4 +4,18 @@
 export enum AccessTokenScopes {
     UserAll = 'user:all',
     SiteAdminSudo = 'site-admin:sudo',
+    SearchRead = 'search:read',
+    RepoRead = 'repo:read',
+    CodeIntelUpload = 'codeintel:upload',
+    BatchChangesWrite = 'batch-changes:write',
 }
+
+/**
+ * The access token scopes that restrict a token without the user:all scope, with their descriptions.
+ */
+export const RESTRICTED_ACCESS_TOKEN_SCOPES: [AccessTokenScopes, string][] = [
+    [AccessTokenScopes.SearchRead, 'Read-only access to search, including the contents of the results'],
+    [AccessTokenScopes.RepoRead, 'Read-only access to repositories and their contents'],
+    [AccessTokenScopes.CodeIntelUpload, 'Ability to upload code intelligence data'],
+    [AccessTokenScopes.BatchChangesWrite, 'Ability to create, apply and close batch changes'],
+]
@@ -28,6 +28,7 @@ export const accessTokenFragment = gql`
         note
         createdAt
         lastUsedAt
+        expiresAt
         subject {
             username
         }
@@ -130,6 +131,12 @@ export const AccessTokenNode: React.FunctionComponent<AccessTokenNodeProps> = ({
                                 by <Link to={userURL(node.creator.username)}>{node.creator.username}</Link>
                             </>
                         )}
+                        {node.expiresAt && (
+                            <>
+                                , {new Date(node.expiresAt) < new Date() ? 'expired' : 'expires'}{' '}
+                                <Timestamp date={node.expiresAt} />
+                            </>
+                        )}
                     </small>
                 </div>
                 <div>
@@ -13,7 +13,7 @@ import { asError, createAggregateError, isErrorLike } from '@sourcegraph/shared/
 import { useObservable } from '@sourcegraph/shared/src/util/useObservable'
 import { Container, PageHeader } from '@sourcegraph/wildcard'
 
-import { AccessTokenScopes } from '../../../auth/accessToken'
+import { AccessTokenScopes, RESTRICTED_ACCESS_TOKEN_SCOPES } from '../../../auth/accessToken'
 import { requestGraphQL } from '../../../backend/graphql'
 import { ErrorAlert } from '../../../components/alerts'
 import { PageTitle } from '../../../components/PageTitle'
@@ -25,18 +25,19 @@ import { UserSettingsAreaRouteContext } from '../UserSettingsArea'
 function createAccessToken(
     user: Scalars['ID'],
     scopes: string[],
-    note: string
+    note: string,
+    expiresAt: Scalars['DateTime'] | null
 ): Observable<CreateAccessTokenResult['createAccessToken']> {
     return requestGraphQL<CreateAccessTokenResult, CreateAccessTokenVariables>(
         gql`
-            mutation CreateAccessToken($user: ID!, $scopes: [String!]!, $note: String!) {
-                createAccessToken(user: $user, scopes: $scopes, note: $note) {
+            mutation CreateAccessToken($user: ID!, $scopes: [String!]!, $note: String!, $expiresAt: DateTime) {
+                createAccessToken(user: $user, scopes: $scopes, note: $note, expiresAt: $expiresAt) {
                     id
                     token
                 }
             }
         `,
-        { user, scopes, note }
+        { user, scopes, note, expiresAt }
     ).pipe(
         map(({ data, errors }) => {
             if (!data || !data.createAccessToken || (errors && errors.length > 0)) {
@@ -49,6 +50,9 @@ function createAccessToken(
     )
 }
 
+/** The options for the expiry of a new access token, in days (0 means the token doesn't expire). */
+const EXPIRY_DAYS = [0, 7, 30, 90, 365]
+
 interface Props
     extends Pick<UserSettingsAreaRouteContext, 'authenticatedUser' | 'user'>,
         Pick<RouteComponentProps<{}>, 'history' | 'match'>,
@@ -78,6 +82,8 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
     const [note, setNote] = useState<string>('')
     /** The selected scopes checkboxes. */
     const [scopes, setScopes] = useState<string[]>([AccessTokenScopes.UserAll])
+    /** The selected number of days until the token expires (0 means never). */
+    const [expiryDays, setExpiryDays] = useState<number>(0)
 
     const onNoteChange = useCallback<React.ChangeEventHandler<HTMLInputElement>>(event => {
         setNote(event.currentTarget.value)
@@ -89,6 +95,10 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
         setScopes(previous => (checked ? [...previous, value] : previous.filter(scope => scope !== value)))
     }, [])
 
+    const onExpiryChange = useCallback<React.ChangeEventHandler<HTMLSelectElement>>(event => {
+        setExpiryDays(parseInt(event.currentTarget.value, 10))
+    }, [])
+
     const submits = useMemo(() => new Subject<React.FormEvent<HTMLFormElement>>(), [])
     const onSubmit = useCallback<React.FormEventHandler<HTMLFormElement>>(event => submits.next(event), [submits])
 
@@ -100,7 +110,14 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
                     concatMap(() =>
                         concat(
                             ['loading'],
-                            createAccessToken(user.id, scopes, note).pipe(
+                            createAccessToken(
+                                user.id,
+                                scopes,
+                                note,
+                                expiryDays > 0
+                                    ? new Date(Date.now() + expiryDays * 24 * 60 * 60 * 1000).toISOString()
+                                    : null
+                            ).pipe(
                                 tap(result => {
                                     // Go back to access tokens list page and display the token secret value.
                                     history.push(`${match.url.replace(/\/new$/, '')}`)
@@ -111,7 +128,7 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
                         )
                     )
                 ),
-            [history, match.url, note, onDidCreateAccessToken, scopes, submits, user.id]
+            [expiryDays, history, match.url, note, onDidCreateAccessToken, scopes, submits, user.id]
         )
     )
 
@@ -148,7 +165,8 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
                         </label>
                         <p>
                             <small className="form-help text-muted">
-                                Tokens with limited user scopes are not yet supported.
+                                Tokens without the {AccessTokenScopes.UserAll} scope may only perform the actions
+                                allowed by their other scopes.
                             </small>
                         </p>
                         <div className="form-check">
@@ -156,10 +174,9 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
                                 className="form-check-input"
                                 type="checkbox"
                                 id="user-settings-create-access-token-page__scope-user:all"
-                                checked={true}
+                                checked={scopes.includes(AccessTokenScopes.UserAll)}
                                 value={AccessTokenScopes.UserAll}
                                 onChange={onScopesChange}
-                                disabled={true}
                             />
                             <label
                                 className="form-check-label"
@@ -169,6 +186,24 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
                                 to the user account
                             </label>
                         </div>
+                        {RESTRICTED_ACCESS_TOKEN_SCOPES.map(([scope, description]) => (
+                            <div className="form-check mt-2" key={scope}>
+                                <input
+                                    className="form-check-input"
+                                    type="checkbox"
+                                    id={`user-settings-create-access-token-page__scope-${scope}`}
+                                    checked={scopes.includes(scope)}
+                                    value={scope}
+                                    onChange={onScopesChange}
+                                />
+                                <label
+                                    className="form-check-label"
+                                    htmlFor={`user-settings-create-access-token-page__scope-${scope}`}
+                                >
+                                    <strong>{scope}</strong> — {description}
+                                </label>
+                            </div>
+                        ))}
                         {user.siteAdmin && !window.context.sourcegraphDotComMode && (
                             <div className="form-check mt-2">
                                 <input
@@ -189,6 +224,21 @@ export const UserSettingsCreateAccessTokenPage: React.FunctionComponent<Props> =
                             </div>
                         )}
                     </div>
+                    <div className="form-group mt-3 mb-0">
+                        <label htmlFor="user-settings-create-access-token-page__expiry">Expiration</label>
+                        <select
+                            className="form-control"
+                            id="user-settings-create-access-token-page__expiry"
+                            value={expiryDays}
+                            onChange={onExpiryChange}
+                        >
+                            {EXPIRY_DAYS.map(days => (
+                                <option key={days} value={days}>
+                                    {days === 0 ? 'Never' : `${days} days`}
+                                </option>
+                            ))}
+                        </select>
+                    </div>
                 </Container>
                 <div className="mb-3">
                     <button
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/errors"
 	"github.com/inconshreveable/log15"
 
 	"github.com/sourcegraph/sourcegraph/internal/actor"
@@ -14,6 +15,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
 
+// ErrUserDeactivated is returned by GetAndSaveUser if the user has been deactivated (e.g. through
+// SCIM).
+var ErrUserDeactivated = errors.New("user is deactivated")
+
 var MockGetAndSaveUser func(ctx context.Context, op GetAndSaveUserOp) (userID int32, safeErrMsg string, err error)
 
 type GetAndSaveUserOp struct {
@@ -37,6 +42,7 @@ type GetAndSaveUserOp struct {
 //    d. If op.CreateIfNotExist is true, attempt to create a new user with the properties
 //       specified in op.UserProps. This may fail if the desired username is already taken.
 //    e. If a new user is successfully created, attempt to grant pending permissions.
+//    Deactivated users are rejected with ErrUserDeactivated.
 // 2. Ensure that the user is associated with the external account information. This means
 //    creating the external account if it does not already exist or updating it if it
 //    already does.
@@ -134,6 +140,10 @@ func GetAndSaveUser(ctx context.Context, db dbutil.DB, op GetAndSaveUserOp) (use
 		if err != nil {
 			return 0, "Unexpected error getting the Sourcegraph user account. Ask a site admin for help.", err
 		}
+		// 🚨 SECURITY: Deactivated users must not be able to sign in with any auth provider.
+		if user.DeactivatedAt != nil {
+			return 0, "Your Sourcegraph user account has been deactivated. Ask a site admin for help.", ErrUserDeactivated
+		}
 		var userUpdate database.UserUpdate
 		if user.DisplayName == "" && op.UserProps.DisplayName != "" {
 			userUpdate.DisplayName = &op.UserProps.DisplayName
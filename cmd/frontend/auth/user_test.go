@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/davecgh/go-spew/spew"
@@ -535,6 +536,33 @@ func TestMetadataOnlyAutomaticallySetOnFirstOccurrence(t *testing.T) {
 	}
 }
 
+func TestGetAndSaveUserDeactivated(t *testing.T) {
+	deactivatedAt := time.Now()
+	user := &types.User{ID: 1, DeactivatedAt: &deactivatedAt}
+
+	database.Mocks.ExternalAccounts.LookupUserAndSave = func(extsvc.AccountSpec, extsvc.AccountData) (userID int32, err error) {
+		return user.ID, nil
+	}
+	database.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return user, nil
+	}
+	defer func() { database.Mocks = database.MockStores{} }()
+
+	op := GetAndSaveUserOp{
+		ExternalAccount: ext("github", "fake-service", "fake-client", "account-u1"),
+	}
+	userID, safeErrMsg, err := GetAndSaveUser(context.Background(), nil, op)
+	if !errors.Is(err, ErrUserDeactivated) {
+		t.Fatalf("got error %v, want %v", err, ErrUserDeactivated)
+	}
+	if userID != 0 {
+		t.Errorf("got user ID %d, want 0", userID)
+	}
+	if safeErrMsg == "" {
+		t.Error("got empty safe error message")
+	}
+}
+
 type mockParams struct {
 	userInfos               []userInfo
 	lookupUserAndSaveErr    error
@@ -51,6 +51,32 @@ func CheckUserIsSiteAdmin(ctx context.Context, db dbutil.DB, userID int32) error
 	return nil
 }
 
+// ErrLastSiteAdmin is returned by CheckNotLastSiteAdmin.
+var ErrLastSiteAdmin = errors.New("user is the last active site admin")
+
+// CheckNotLastSiteAdmin returns ErrLastSiteAdmin if u is the last active site admin, who must not
+// be demoted, deactivated or deleted so that the site can still be administered.
+// countActiveSiteAdmins returns the number of site admins that aren't deactivated.
+func CheckNotLastSiteAdmin(ctx context.Context, u *types.User, countActiveSiteAdmins func(context.Context) (int, error)) error {
+	if !u.SiteAdmin || u.DeactivatedAt != nil {
+		return nil
+	}
+	n, err := countActiveSiteAdmins(ctx)
+	if err != nil {
+		return err
+	}
+	if n <= 1 {
+		return ErrLastSiteAdmin
+	}
+	return nil
+}
+
+// CountActiveSiteAdmins returns the number of site admins that aren't deactivated, for use with
+// CheckNotLastSiteAdmin.
+func CountActiveSiteAdmins(ctx context.Context, db dbutil.DB) (int, error) {
+	return database.Users(db).Count(ctx, &database.UsersListOptions{SiteAdmins: true, ExcludeDeactivated: true})
+}
+
 // InsufficientAuthorizationError is an error that occurs when the authentication is technically valid
 // (e.g., the token is not expired) but does not yield a user with privileges to perform a certain
 // action.
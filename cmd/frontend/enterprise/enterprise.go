@@ -21,6 +21,8 @@ type Services struct {
 	GitLabWebhook             http.Handler
 	BitbucketServerWebhook    http.Handler
 	NewCodeIntelUploadHandler NewCodeIntelUploadHandler
+	CodeIntelExportHandler    http.Handler
+	SearchJobsHandler         http.Handler
 	NewExecutorProxyHandler   NewExecutorProxyHandler
 	AuthzResolver             graphqlbackend.AuthzResolver
 	BatchChangesResolver      graphqlbackend.BatchChangesResolver
@@ -47,6 +49,8 @@ func DefaultServices() Services {
 		GitLabWebhook:             makeNotFoundHandler("gitlab webhook"),
 		BitbucketServerWebhook:    makeNotFoundHandler("bitbucket server webhook"),
 		NewCodeIntelUploadHandler: func(_ bool) http.Handler { return makeNotFoundHandler("code intel upload") },
+		CodeIntelExportHandler:    makeNotFoundHandler("code intel export"),
+		SearchJobsHandler:         makeNotFoundHandler("search jobs"),
 		NewExecutorProxyHandler:   func() http.Handler { return makeNotFoundHandler("executor proxy") },
 	}
 }
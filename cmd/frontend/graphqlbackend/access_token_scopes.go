@@ -0,0 +1,304 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+)
+
+// scopeFields are the top-level fields, as "operation.field", that an access token restricted to
+// a scope may request. The fields in scopeFieldsAlways may be requested with any scope, but only
+// with the subfields listed there.
+var scopeFields = map[string][]string{
+	authz.ScopeSearchRead: {
+		"query.search",
+		"query.searchContexts",
+		"query.autoDefinedSearchContexts",
+	},
+	authz.ScopeRepoRead: {
+		"query.repository",
+		"query.repositoryRedirect",
+		"query.repositories",
+	},
+	authz.ScopeCodeIntelUpload: {},
+	authz.ScopeBatchChangesWrite: {
+		"query.batchChange",
+		"query.batchChanges",
+		"query.batchChangesCodeHosts",
+		"query.namespaceByName",
+		"mutation.createBatchSpec",
+		"mutation.createChangesetSpec",
+		"mutation.createBatchChange",
+		"mutation.applyBatchChange",
+		"mutation.closeBatchChange",
+		"mutation.moveBatchChange",
+		"mutation.deleteBatchChange",
+		"mutation.createBatchSpecExecution",
+		"mutation.syncChangeset",
+		"mutation.reenqueueChangeset",
+		"mutation.detachChangesets",
+		"mutation.reenqueueChangesets",
+		"mutation.mergeChangesets",
+		"mutation.closeChangesets",
+		"mutation.publishChangesets",
+		"mutation.createChangesetComments",
+	},
+}
+
+// scopeFieldsAlways are the top-level fields, as "operation.field", that may be requested with
+// any scope. Clients like src-cli query the current user to check the token.
+var scopeFieldsAlways = map[string]bool{
+	"query.currentUser": true,
+}
+
+// scopeUserAllTypes are the types whose fields require the user:all scope, wherever they appear
+// in a query, mapped to the fields that don't. This keeps the emails, settings and access tokens
+// of users out of reach of restricted tokens, even through nested paths like the author of a
+// commit.
+var scopeUserAllTypes = map[string][]string{
+	"User":                  {"id", "username"},
+	"UserEmail":             nil,
+	"AccessToken":           nil,
+	"AccessTokenConnection": nil,
+	"Settings":              nil,
+	"SettingsCascade":       nil,
+}
+
+// CheckAccessTokenScopes returns an error if the actor in ctx authenticated with an access token
+// that is restricted to scopes that don't allow all top-level fields of the operations in query,
+// or if the query selects fields of a type in scopeUserAllTypes without the user:all scope.
+//
+// 🚨 SECURITY: This must be called before executing GraphQL requests from external clients.
+func CheckAccessTokenScopes(ctx context.Context, query string) error {
+	a := actor.FromContext(ctx)
+	if a.Scopes == nil {
+		return nil
+	}
+
+	allowed := map[string]bool{}
+	for _, scope := range a.Scopes {
+		for _, f := range scopeFields[scope] {
+			allowed[f] = true
+		}
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return errors.Wrap(err, "parsing query")
+	}
+
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[frag.Name.Value] = frag
+		}
+	}
+
+	types, err := scopeSchemaTypes()
+	if err != nil {
+		return err
+	}
+	c := &scopeChecker{
+		scopes:    a.Scopes,
+		types:     types,
+		fragments: fragments,
+		visiting:  map[string]bool{},
+	}
+
+	// All operations are checked, not just the one that is executed, so that we don't depend on
+	// how operations are selected.
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		root := "Query"
+		if op.Operation == ast.OperationTypeMutation {
+			root = "Mutation"
+		}
+		for _, field := range selectionFields(op.SelectionSet, fragments, map[string]bool{}) {
+			name := field.Name.Value
+			// Introspection fields like __typename don't expose any data.
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			key := op.Operation + "." + name
+			if !allowed[key] && !scopeFieldsAlways[key] {
+				return errors.Errorf("the scopes of the access token (%q) do not allow the %s field %q", sortedScopes(a.Scopes), op.Operation, name)
+			}
+		}
+		if err := c.checkSelectionSet(op.SelectionSet, root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scopeChecker checks the fields selected in a query against scopeUserAllTypes.
+type scopeChecker struct {
+	scopes    []string
+	types     map[string]map[string]string
+	fragments map[string]*ast.FragmentDefinition
+	visiting  map[string]bool
+}
+
+// checkSelectionSet checks the fields in set, which are selected on a value of typeName, and all
+// of their subfields. typeName is empty if the type is unknown.
+func (c *scopeChecker) checkSelectionSet(set *ast.SelectionSet, typeName string) error {
+	if set == nil {
+		return nil
+	}
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			name := sel.Name.Value
+			if strings.HasPrefix(name, "__") {
+				continue
+			}
+			if allowedFields, ok := scopeUserAllTypes[typeName]; ok && !containsString(allowedFields, name) {
+				return errors.Errorf("the scopes of the access token (%q) do not allow the field %q", sortedScopes(c.scopes), typeName+"."+name)
+			}
+			if err := c.checkSelectionSet(sel.SelectionSet, c.types[typeName][name]); err != nil {
+				return err
+			}
+		case *ast.InlineFragment:
+			fragmentType := typeName
+			if sel.TypeCondition != nil {
+				fragmentType = sel.TypeCondition.Name.Value
+			}
+			if err := c.checkSelectionSet(sel.SelectionSet, fragmentType); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			frag, ok := c.fragments[name]
+			// Fragments that spread themselves are invalid and are rejected when the query is
+			// executed, we only need to avoid recursing forever.
+			if !ok || c.visiting[name] {
+				continue
+			}
+			fragmentType := typeName
+			if frag.TypeCondition != nil {
+				fragmentType = frag.TypeCondition.Name.Value
+			}
+			c.visiting[name] = true
+			err := c.checkSelectionSet(frag.SelectionSet, fragmentType)
+			delete(c.visiting, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	scopeSchemaTypesOnce sync.Once
+	scopeSchemaTypesMap  map[string]map[string]string
+	scopeSchemaTypesErr  error
+)
+
+// scopeSchemaTypes returns the fields of the object and interface types of the full GraphQL
+// schema, mapped to the names of their types.
+func scopeSchemaTypes() (map[string]map[string]string, error) {
+	scopeSchemaTypesOnce.Do(func() {
+		// The parser doesn't support extensions of interfaces, so we parse all extensions as
+		// definitions and merge their fields.
+		source := strings.Join([]string{
+			mainSchema,
+			batchesSchema,
+			codeIntelSchema,
+			dotcomSchema,
+			licenseSchema,
+			codeMonitorsSchema,
+			insightsSchema,
+			authzSchema,
+		}, "\n")
+		source = strings.ReplaceAll(source, "\nextend ", "\n")
+
+		doc, err := parser.Parse(parser.ParseParams{Source: source})
+		if err != nil {
+			scopeSchemaTypesErr = errors.Wrap(err, "parsing schema")
+			return
+		}
+
+		types := map[string]map[string]string{}
+		add := func(typeName string, fields []*ast.FieldDefinition) {
+			if types[typeName] == nil {
+				types[typeName] = map[string]string{}
+			}
+			for _, f := range fields {
+				types[typeName][f.Name.Value] = namedType(f.Type)
+			}
+		}
+		for _, def := range doc.Definitions {
+			switch def := def.(type) {
+			case *ast.ObjectDefinition:
+				add(def.Name.Value, def.Fields)
+			case *ast.InterfaceDefinition:
+				add(def.Name.Value, def.Fields)
+			}
+		}
+		scopeSchemaTypesMap = types
+	})
+	return scopeSchemaTypesMap, scopeSchemaTypesErr
+}
+
+// namedType returns the name of t without list and non-null wrappers.
+func namedType(t ast.Type) string {
+	switch t := t.(type) {
+	case *ast.Named:
+		return t.Name.Value
+	case *ast.List:
+		return namedType(t.Type)
+	case *ast.NonNull:
+		return namedType(t.Type)
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedScopes(scopes []string) []string {
+	scopes = append([]string(nil), scopes...)
+	sort.Strings(scopes)
+	return scopes
+}
+
+// selectionFields returns the fields in set, including the fields in the fragments it spreads.
+func selectionFields(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, seen map[string]bool) []*ast.Field {
+	if set == nil {
+		return nil
+	}
+	var fields []*ast.Field
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			fields = append(fields, sel)
+		case *ast.InlineFragment:
+			fields = append(fields, selectionFields(sel.SelectionSet, fragments, seen)...)
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			if frag, ok := fragments[name]; ok && !seen[name] {
+				seen[name] = true
+				fields = append(fields, selectionFields(frag.SelectionSet, fragments, seen)...)
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,131 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+)
+
+func TestCheckAccessTokenScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		query   string
+		wantErr bool
+	}{
+		{
+			name:   "unrestricted",
+			scopes: nil,
+			query:  `mutation { deleteUser(user: "x") { alwaysNil } }`,
+		},
+		{
+			name:   "search",
+			scopes: []string{authz.ScopeSearchRead},
+			query:  `query Search { search(query: "x") { results { matchCount } } currentUser { username } __typename }`,
+		},
+		{
+			name:    "search without scope",
+			scopes:  []string{authz.ScopeRepoRead},
+			query:   `query { search(query: "x") { results { matchCount } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "mutation",
+			scopes:  []string{authz.ScopeSearchRead, authz.ScopeRepoRead},
+			query:   `mutation { deleteUser(user: "x") { alwaysNil } }`,
+			wantErr: true,
+		},
+		{
+			name:   "current user via fragment",
+			scopes: []string{authz.ScopeRepoRead},
+			query:  `query { currentUser { ...U } } fragment U on User { id username __typename }`,
+		},
+		{
+			name:    "current user emails",
+			scopes:  []string{authz.ScopeSearchRead},
+			query:   `query { currentUser { username emails { email } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "current user access tokens in fragment",
+			scopes:  []string{authz.ScopeSearchRead},
+			query:   `query { currentUser { ...U } } fragment U on User { accessTokens { totalCount } }`,
+			wantErr: true,
+		},
+		{
+			name:   "batch changes mutation",
+			scopes: []string{authz.ScopeBatchChangesWrite},
+			query:  `mutation { applyBatchChange(batchSpec: "x") { id } }`,
+		},
+		{
+			name:    "fields in fragments",
+			scopes:  []string{authz.ScopeSearchRead},
+			query:   `query { ...F } fragment F on Query { ... on Query { site { id } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "fields in other operations",
+			scopes:  []string{authz.ScopeSearchRead},
+			query:   `query A { search(query: "x") { results { matchCount } } } query B { site { id } }`,
+			wantErr: true,
+		},
+		{
+			name:    "node",
+			scopes:  []string{authz.ScopeRepoRead},
+			query:   `query { node(id: "x") { id } }`,
+			wantErr: true,
+		},
+		{
+			name:   "nested user id",
+			scopes: []string{authz.ScopeRepoRead},
+			query:  `query { repository(name: "r") { commit(rev: "HEAD") { author { person { user { id username } } } } } }`,
+		},
+		{
+			name:    "nested user emails through repository",
+			scopes:  []string{authz.ScopeRepoRead},
+			query:   `query { repository(name: "r") { commit(rev: "HEAD") { author { person { user { emails { email } } } } } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "nested user access tokens and settings through repository",
+			scopes:  []string{authz.ScopeRepoRead},
+			query:   `query { repository(name: "r") { commit(rev: "HEAD") { author { person { user { accessTokens { totalCount } settingsCascade { final } } } } } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "nested user emails through search results",
+			scopes:  []string{authz.ScopeSearchRead},
+			query:   `query { search(query: "x") { results { results { ... on CommitSearchResult { commit { author { person { user { emails { email } } } } } } } } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "nested user settings through fragment",
+			scopes:  []string{authz.ScopeSearchRead},
+			query:   `query { search(query: "x") { results { results { ...C } } } } fragment C on CommitSearchResult { commit { author { person { user { ...U } } } } } fragment U on User { settingsCascade { final } }`,
+			wantErr: true,
+		},
+		{
+			name:    "user namespace emails",
+			scopes:  []string{authz.ScopeBatchChangesWrite},
+			query:   `query { namespaceByName(name: "u") { ... on User { emails { email } } } }`,
+			wantErr: true,
+		},
+		{
+			name:    "user namespace settings through interface",
+			scopes:  []string{authz.ScopeBatchChangesWrite},
+			query:   `query { namespaceByName(name: "u") { ... on SettingsSubject { latestSettings { contents } } } }`,
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1, Scopes: test.scopes})
+			err := CheckAccessTokenScopes(ctx, test.query)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package graphqlbackend
 
 import (
 	"context"
+	"encoding/json"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/graph-gophers/graphql-go"
@@ -20,9 +22,10 @@ import (
 )
 
 type createAccessTokenInput struct {
-	User   graphql.ID
-	Scopes []string
-	Note   string
+	User      graphql.ID
+	Scopes    []string
+	Note      string
+	ExpiresAt *DateTime
 }
 
 func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAccessTokenInput) (*createAccessTokenResult, error) {
@@ -53,14 +56,17 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 	}
 
 	// Validate scopes.
-	var hasUserAllScope bool
+	var hasUserAllScope, hasSudoScope, hasRestrictedScope bool
 	seenScope := map[string]struct{}{}
 	sort.Strings(args.Scopes)
 	for _, scope := range args.Scopes {
 		switch scope {
 		case authz.ScopeUserAll:
 			hasUserAllScope = true
+		case authz.ScopeSearchRead, authz.ScopeRepoRead, authz.ScopeCodeIntelUpload, authz.ScopeBatchChangesWrite:
+			hasRestrictedScope = true
 		case authz.ScopeSiteAdminSudo:
+			hasSudoScope = true
 			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:sudo" scope.
 			if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
 				return nil, err
@@ -76,11 +82,31 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 		}
 		seenScope[scope] = struct{}{}
 	}
-	if !hasUserAllScope {
-		return nil, errors.Errorf("all access tokens must have scope %q", authz.ScopeUserAll)
+	if !hasUserAllScope && !hasRestrictedScope {
+		return nil, errors.Errorf("access tokens must have scope %q or at least one of the scopes %q", authz.ScopeUserAll, authz.RestrictedScopes)
+	}
+	if hasSudoScope && !hasUserAllScope {
+		return nil, errors.Errorf("access tokens with scope %q must also have scope %q", authz.ScopeSiteAdminSudo, authz.ScopeUserAll)
+	}
+
+	var expiresAt *time.Time
+	if args.ExpiresAt != nil {
+		if !args.ExpiresAt.Time.After(time.Now()) {
+			return nil, errors.New("access token expiry date must be in the future")
+		}
+		expiresAt = &args.ExpiresAt.Time
 	}
 
-	id, token, err := database.AccessTokens(r.db).Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
+	a := actor.FromContext(ctx)
+	id, token, err := database.AccessTokens(r.db).CreateWithExpiry(ctx, userID, args.Scopes, args.Note, a.UID, expiresAt)
+	if err == nil {
+		logAccessTokenEvent(ctx, r.db, database.SecurityEventNameAccessTokenCreated, a.UID, accessTokenEventArgs{
+			AccessTokenID: id,
+			SubjectUserID: userID,
+			Scopes:        args.Scopes,
+			ExpiresAt:     expiresAt,
+		})
+	}
 
 	if conf.CanSendEmail() {
 		if err := backend.UserEmails.SendUserEmailOnFieldUpdate(ctx, userID, "created an access token"); err != nil {
@@ -113,6 +139,7 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 	}
 
 	var subjectUserID int32
+	var deletedID int64
 	switch {
 	case args.ByID != nil:
 		accessTokenID, err := unmarshalAccessTokenID(*args.ByID)
@@ -124,6 +151,7 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 			return nil, err
 		}
 		subjectUserID = token.SubjectUserID
+		deletedID = token.ID
 
 		// 🚨 SECURITY: Only site admins and the user can delete a user's access token.
 		if err := backend.CheckSiteAdminOrSameUser(ctx, r.db, token.SubjectUserID); err != nil {
@@ -139,6 +167,7 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 			return nil, err
 		}
 		subjectUserID = token.SubjectUserID
+		deletedID = token.ID
 
 		// 🚨 SECURITY: This is easier than the ByID case because anyone holding the access token's
 		// secret value is assumed to be allowed to delete it.
@@ -148,6 +177,11 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 
 	}
 
+	logAccessTokenEvent(ctx, r.db, database.SecurityEventNameAccessTokenDeleted, actor.FromContext(ctx).UID, accessTokenEventArgs{
+		AccessTokenID: deletedID,
+		SubjectUserID: subjectUserID,
+	})
+
 	if conf.CanSendEmail() {
 		if err := backend.UserEmails.SendUserEmailOnFieldUpdate(ctx, subjectUserID, "deleted an access token"); err != nil {
 			log15.Warn("Failed to send email to inform user of access token deletion", "error", err)
@@ -157,6 +191,35 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 	return &EmptyResponse{}, nil
 }
 
+// accessTokenEventArgs is the argument of the security events about access tokens.
+type accessTokenEventArgs struct {
+	AccessTokenID int64      `json:"access_token_id"`
+	SubjectUserID int32      `json:"subject_user_id"`
+	Scopes        []string   `json:"scopes,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+}
+
+// logAccessTokenEvent logs a security event about an access token performed by the user with ID
+// userID.
+func logAccessTokenEvent(ctx context.Context, db dbutil.DB, name database.SecurityEventName, userID int32, eventArgs accessTokenEventArgs) {
+	args, err := json.Marshal(eventArgs)
+	if err != nil {
+		log15.Error("logAccessTokenEvent: failed to marshal JSON", "eventArgs", eventArgs)
+	}
+
+	// Unlike LogEvent, Insert records the event on all instances, since token events matter
+	// most to the admins of private instances.
+	if err := database.SecurityEventLogs(db).Insert(ctx, &database.SecurityEvent{
+		Name:      name,
+		UserID:    uint32(userID),
+		Argument:  args,
+		Source:    "BACKEND",
+		Timestamp: time.Now(),
+	}); err != nil {
+		log15.Error("logAccessTokenEvent: failed to log security event", "err", err)
+	}
+}
+
 func (r *siteResolver) AccessTokens(ctx context.Context, args *struct {
 	graphqlutil.ConnectionArgs
 }) (*accessTokenConnectionResolver, error) {
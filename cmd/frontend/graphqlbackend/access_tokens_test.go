@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/graph-gophers/graphql-go"
@@ -41,6 +42,12 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 			}
 			return 1, "t", nil
 		}
+		database.Mocks.SecurityEventLogs.Insert = func(ctx context.Context, e *database.SecurityEvent) error {
+			if e.Name != database.SecurityEventNameAccessTokenCreated {
+				t.Errorf("got security event %q, want %q", e.Name, database.SecurityEventNameAccessTokenCreated)
+			}
+			return nil
+		}
 	}
 
 	const uid1GQLID = "VXNlcjox"
@@ -88,6 +95,63 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 		}
 	})
 
+	t.Run("authenticated as user, using restricted scopes with expiry", func(t *testing.T) {
+		resetMocks()
+		mockAccessTokensCreate(t, 1, []string{authz.ScopeRepoRead, authz.ScopeSearchRead})
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&schemaResolver{db: db}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:      uid1GQLID,
+			Scopes:    []string{authz.ScopeSearchRead, authz.ScopeRepoRead},
+			Note:      "n",
+			ExpiresAt: &DateTime{Time: time.Now().Add(time.Hour)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Token() != "t" {
+			t.Errorf("got token %q, want %q", result.Token(), "t")
+		}
+	})
+
+	t.Run("authenticated as user, using expiry in the past", func(t *testing.T) {
+		resetMocks()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&schemaResolver{db: db}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:      uid1GQLID,
+			Scopes:    []string{authz.ScopeUserAll},
+			Note:      "n",
+			ExpiresAt: &DateTime{Time: time.Now().Add(-time.Hour)},
+		})
+		if err == nil {
+			t.Error("err == nil")
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
+	t.Run("authenticated as site admin, using sudo scope without user:all", func(t *testing.T) {
+		resetMocks()
+		database.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&schemaResolver{db: db}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:   uid1GQLID,
+			Scopes: []string{authz.ScopeSiteAdminSudo, authz.ScopeSearchRead},
+			Note:   "n",
+		})
+		if err == nil {
+			t.Error("err == nil")
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
 	t.Run("authenticated as user, using site-admin-only scopes", func(t *testing.T) {
 		resetMocks()
 		database.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
@@ -293,6 +357,12 @@ func TestMutation_DeleteAccessToken(t *testing.T) {
 			}
 			return &database.AccessToken{ID: 1, SubjectUserID: 2}, nil
 		}
+		database.Mocks.SecurityEventLogs.Insert = func(ctx context.Context, e *database.SecurityEvent) error {
+			if e.Name != database.SecurityEventNameAccessTokenDeleted {
+				t.Errorf("got security event %q, want %q", e.Name, database.SecurityEventNameAccessTokenDeleted)
+			}
+			return nil
+		}
 	}
 
 	token1GQLID := graphql.ID("QWNjZXNzVG9rZW46MQ==")
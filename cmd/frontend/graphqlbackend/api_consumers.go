@@ -0,0 +1,122 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/apiratelimit"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+var apiRateLimitEndpoints = map[string]apiratelimit.Endpoint{
+	"GRAPHQL":          apiratelimit.EndpointGraphQL,
+	"SEARCH":           apiratelimit.EndpointSearch,
+	"CODEINTEL_UPLOAD": apiratelimit.EndpointCodeIntelUpload,
+}
+
+func (r *siteResolver) APIConsumers(ctx context.Context, args *struct {
+	Endpoint string
+	First    int32
+	DaysAgo  int32
+}) ([]*apiConsumerResolver, error) {
+	// 🚨 SECURITY: Only site admins may see who uses the API.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	endpoint, ok := apiRateLimitEndpoints[args.Endpoint]
+	if !ok {
+		return nil, errors.Errorf("unknown endpoint %q", args.Endpoint)
+	}
+	if args.First < 0 || args.DaysAgo < 0 {
+		return nil, errors.New("first and daysAgo must not be negative")
+	}
+
+	day := time.Now().UTC().AddDate(0, 0, -int(args.DaysAgo))
+	consumers, err := apiratelimit.TopConsumers(endpoint, day, int(args.First))
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*apiConsumerResolver, 0, len(consumers))
+	for _, c := range consumers {
+		resolvers = append(resolvers, &apiConsumerResolver{db: r.db, consumer: c})
+	}
+	return resolvers, nil
+}
+
+type apiConsumerResolver struct {
+	db       dbutil.DB
+	consumer *apiratelimit.Consumer
+}
+
+func (r *apiConsumerResolver) Kind() string {
+	switch r.consumer.Subject.Kind {
+	case apiratelimit.SubjectToken:
+		return "ACCESS_TOKEN"
+	case apiratelimit.SubjectIP:
+		return "IP"
+	default:
+		return "USER"
+	}
+}
+
+func (r *apiConsumerResolver) User(ctx context.Context) (*UserResolver, error) {
+	userID := r.consumer.Subject.UserID
+	if r.consumer.Subject.Kind == apiratelimit.SubjectToken {
+		token, err := r.accessToken(ctx)
+		if token == nil || err != nil {
+			return nil, err
+		}
+		userID = token.SubjectUserID
+	}
+	if userID == 0 {
+		return nil, nil
+	}
+
+	user, err := UserByIDInt32(ctx, r.db, userID)
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *apiConsumerResolver) AccessToken(ctx context.Context) (*accessTokenResolver, error) {
+	token, err := r.accessToken(ctx)
+	if token == nil || err != nil {
+		return nil, err
+	}
+	return &accessTokenResolver{db: r.db, accessToken: *token}, nil
+}
+
+func (r *apiConsumerResolver) accessToken(ctx context.Context) (*database.AccessToken, error) {
+	if r.consumer.Subject.Kind != apiratelimit.SubjectToken {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(r.consumer.Subject.ID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	token, err := database.AccessTokens(r.db).GetByID(ctx, id)
+	if err == database.ErrAccessTokenNotFound {
+		return nil, nil
+	}
+	return token, err
+}
+
+func (r *apiConsumerResolver) IPAddress() *string {
+	if r.consumer.Subject.Kind != apiratelimit.SubjectIP {
+		return nil
+	}
+	return &r.consumer.Subject.ID
+}
+
+func (r *apiConsumerResolver) Requests() int32 { return int32(r.consumer.Requests) }
+
+func (r *apiConsumerResolver) LimitedRequests() int32 { return int32(r.consumer.Limited) }
@@ -0,0 +1,82 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/apiratelimit"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestSiteAPIConsumers(t *testing.T) {
+	resetMocks()
+	database.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	database.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		if id != 2 {
+			return nil, database.MockUserNotFoundErr
+		}
+		return &types.User{ID: 2, Username: "alice"}, nil
+	}
+	database.Mocks.AccessTokens.GetByID = func(id int64) (*database.AccessToken, error) {
+		if id != 7 {
+			return nil, database.ErrAccessTokenNotFound
+		}
+		return &database.AccessToken{ID: 7, SubjectUserID: 2, Note: "ci"}, nil
+	}
+	defer resetMocks()
+
+	apiratelimit.MockTopConsumers = func(endpoint apiratelimit.Endpoint, day time.Time, n int) ([]*apiratelimit.Consumer, error) {
+		if endpoint != apiratelimit.EndpointSearch {
+			t.Errorf("have endpoint %q, want %q", endpoint, apiratelimit.EndpointSearch)
+		}
+		if want := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02"); day.Format("2006-01-02") != want {
+			t.Errorf("have day %s, want %s", day.Format("2006-01-02"), want)
+		}
+		if n != 4 {
+			t.Errorf("have n %d, want 4", n)
+		}
+		return []*apiratelimit.Consumer{
+			{Subject: apiratelimit.Subject{Kind: apiratelimit.SubjectToken, ID: "7"}, Requests: 300, Limited: 12},
+			{Subject: apiratelimit.Subject{Kind: apiratelimit.SubjectUser, ID: "2", UserID: 2}, Requests: 40},
+			{Subject: apiratelimit.Subject{Kind: apiratelimit.SubjectIP, ID: "203.0.113.9"}, Requests: 10, Limited: 1},
+			{Subject: apiratelimit.Subject{Kind: apiratelimit.SubjectToken, ID: "8"}, Requests: 1},
+		}, nil
+	}
+	defer func() { apiratelimit.MockTopConsumers = nil }()
+
+	RunTests(t, []*Test{
+		{
+			Schema: mustParseGraphQLSchema(t),
+			Query: `
+				{
+					site {
+						apiConsumers(endpoint: SEARCH, first: 4, daysAgo: 1) {
+							kind
+							user { username }
+							accessToken { note }
+							ipAddress
+							requests
+							limitedRequests
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"site": {
+						"apiConsumers": [
+							{"kind": "ACCESS_TOKEN", "user": {"username": "alice"}, "accessToken": {"note": "ci"}, "ipAddress": null, "requests": 300, "limitedRequests": 12},
+							{"kind": "USER", "user": {"username": "alice"}, "accessToken": null, "ipAddress": null, "requests": 40, "limitedRequests": 0},
+							{"kind": "IP", "user": null, "accessToken": null, "ipAddress": "203.0.113.9", "requests": 10, "limitedRequests": 1},
+							{"kind": "ACCESS_TOKEN", "user": null, "accessToken": null, "ipAddress": null, "requests": 1, "limitedRequests": 0}
+						]
+					}
+				}
+			`,
+		},
+	})
+}
@@ -24,6 +24,12 @@ type CodeIntelResolver interface {
 	CommitGraph(ctx context.Context, id graphql.ID) (CodeIntelligenceCommitGraphResolver, error)
 	QueueAutoIndexJobForRepo(ctx context.Context, args *struct{ Repository graphql.ID }) (*EmptyResponse, error)
 	GitBlobLSIFData(ctx context.Context, args *GitBlobLSIFDataArgs) (GitBlobLSIFDataResolver, error)
+	LSIFUploadSymbolDiff(ctx context.Context, args *LSIFUploadSymbolDiffArgs) (LSIFSymbolDiffResolver, error)
+	LSIFSymbolDiffByRepo(ctx context.Context, args *LSIFRepositorySymbolDiffArgs) (LSIFSymbolDiffResolver, error)
+	LSIFRetentionPolicies(ctx context.Context) ([]LSIFRetentionPolicyResolver, error)
+	CreateLSIFRetentionPolicy(ctx context.Context, args *CreateLSIFRetentionPolicyArgs) (LSIFRetentionPolicyResolver, error)
+	DeleteLSIFRetentionPolicy(ctx context.Context, args *struct{ ID graphql.ID }) (*EmptyResponse, error)
+	LSIFUploadsExpiredByRetentionPolicies(ctx context.Context, args *LSIFUploadsExpiredByRetentionPoliciesArgs) ([]LSIFUploadResolver, error)
 
 	NodeResolvers() map[string]NodeByIDFunc
 }
@@ -63,6 +69,39 @@ type LSIFUploadConnectionResolver interface {
 	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
 }
 
+type LSIFUploadSymbolDiffArgs struct {
+	Base graphql.ID
+	Head graphql.ID
+}
+
+type LSIFRepositorySymbolDiffArgs struct {
+	RepositoryID graphql.ID
+	Base         string
+	Head         string
+	Root         *string
+	Indexer      *string
+}
+
+type LSIFSymbolDiffResolver interface {
+	Base() LSIFUploadResolver
+	Head() LSIFUploadResolver
+	Added() []LSIFSymbolResolver
+	Removed() []LSIFSymbolResolver
+	Changed() []LSIFSymbolChangeResolver
+}
+
+type LSIFSymbolResolver interface {
+	Scheme() string
+	Identifier() string
+	Hover() *Markdown
+	Definitions(ctx context.Context) ([]LocationResolver, error)
+}
+
+type LSIFSymbolChangeResolver interface {
+	Base() LSIFSymbolResolver
+	Head() LSIFSymbolResolver
+}
+
 type LSIFIndexesQueryArgs struct {
 	graphqlutil.ConnectionArgs
 	Query *string
@@ -122,6 +161,30 @@ type IndexConfigurationResolver interface {
 	Configuration() *string
 }
 
+type LSIFRetentionPolicyResolver interface {
+	ID() graphql.ID
+	Name() string
+	RepositoryPattern() string
+	RefPattern() string
+	MaxAgeSeconds() *int32
+	KeepMostRecent() *int32
+	IndexingEnabled() bool
+	CreatedAt() DateTime
+}
+
+type CreateLSIFRetentionPolicyArgs struct {
+	Name              string
+	RepositoryPattern *string
+	RefPattern        string
+	MaxAgeSeconds     *int32
+	KeepMostRecent    *int32
+	IndexingEnabled   bool
+}
+
+type LSIFUploadsExpiredByRetentionPoliciesArgs struct {
+	Repository *graphql.ID
+}
+
 type UpdateRepositoryIndexConfigurationArgs struct {
 	Repository    graphql.ID
 	Configuration string
@@ -152,6 +215,8 @@ type GitBlobLSIFDataResolver interface {
 	Ranges(ctx context.Context, args *LSIFRangesArgs) (CodeIntelligenceRangeConnectionResolver, error)
 	Definitions(ctx context.Context, args *LSIFQueryPositionArgs) (LocationConnectionResolver, error)
 	References(ctx context.Context, args *LSIFPagedQueryPositionArgs) (LocationConnectionResolver, error)
+	IncomingCalls(ctx context.Context, args *LSIFPagedQueryPositionArgs) (CallHierarchyIncomingCallConnectionResolver, error)
+	OutgoingCalls(ctx context.Context, args *LSIFQueryPositionArgs) (CallHierarchyOutgoingCallConnectionResolver, error)
 	Hover(ctx context.Context, args *LSIFQueryPositionArgs) (HoverResolver, error)
 	Documentation(ctx context.Context, args *LSIFQueryPositionArgs) (DocumentationResolver, error)
 }
@@ -211,6 +276,30 @@ type LocationConnectionResolver interface {
 	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
 }
 
+type CallHierarchyItemResolver interface {
+	Location(ctx context.Context) (LocationResolver, error)
+	Hover(ctx context.Context) (HoverResolver, error)
+}
+
+type CallHierarchyIncomingCallConnectionResolver interface {
+	Nodes(ctx context.Context) ([]CallHierarchyIncomingCallResolver, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type CallHierarchyIncomingCallResolver interface {
+	From(ctx context.Context) (CallHierarchyItemResolver, error)
+	FromRanges(ctx context.Context) ([]LocationResolver, error)
+}
+
+type CallHierarchyOutgoingCallConnectionResolver interface {
+	Nodes(ctx context.Context) ([]CallHierarchyOutgoingCallResolver, error)
+}
+
+type CallHierarchyOutgoingCallResolver interface {
+	To(ctx context.Context) (CallHierarchyItemResolver, error)
+	FromRanges(ctx context.Context) ([]LocationResolver, error)
+}
+
 type HoverResolver interface {
 	Markdown() Markdown
 	Range() RangeResolver
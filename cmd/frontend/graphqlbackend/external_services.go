@@ -18,6 +18,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -89,6 +90,8 @@ func (r *schemaResolver) AddExternalService(ctx context.Context, args *addExtern
 	if err := database.ExternalServices(r.db).Create(ctx, conf.Get, externalService); err != nil {
 		return nil, err
 	}
+	audit.SetTarget(ctx, "external service %d", externalService.ID)
+	audit.SetChange(ctx, "", describeExternalService(externalService))
 
 	res := &externalServiceResolver{db: r.db, externalService: externalService}
 	if err := syncExternalService(ctx, externalService, 5*time.Second, r.repoupdaterClient); err != nil {
@@ -147,10 +150,12 @@ func (r *schemaResolver) UpdateExternalService(ctx context.Context, args *update
 	}
 
 	// Fetch from database again to get all fields with updated values.
+	before := es
 	es, err = database.ExternalServices(r.db).GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	auditExternalServiceUpdate(ctx, before, es)
 
 	res := &externalServiceResolver{db: r.db, externalService: es}
 	if err = syncExternalService(ctx, es, 5*time.Second, r.repoupdaterClient); err != nil {
@@ -233,6 +238,8 @@ func (r *schemaResolver) DeleteExternalService(ctx context.Context, args *delete
 	if err := database.ExternalServices(r.db).Delete(ctx, id); err != nil {
 		return nil, err
 	}
+	audit.SetTarget(ctx, "external service %d", es.ID)
+	audit.SetChange(ctx, describeExternalService(es), "deleted")
 	now := time.Now()
 	es.DeletedAt = now
 
@@ -247,6 +254,23 @@ func (r *schemaResolver) DeleteExternalService(ctx context.Context, args *delete
 	return &EmptyResponse{}, nil
 }
 
+// describeExternalService describes an external service in the audit log. The configuration is not
+// included, because it contains secrets.
+func describeExternalService(es *types.ExternalService) string {
+	return fmt.Sprintf("%s %q", es.Kind, es.DisplayName)
+}
+
+// auditExternalServiceUpdate records the update of an external service in the audit log, with the
+// paths of the configuration that changed (but not their values).
+func auditExternalServiceUpdate(ctx context.Context, before, after *types.ExternalService) {
+	summary := describeExternalService(after)
+	if paths := changedJSONPaths(before.Config, after.Config); len(paths) > 0 {
+		summary += ", changed config " + strings.Join(paths, ", ")
+	}
+	audit.SetTarget(ctx, "external service %d", after.ID)
+	audit.SetChange(ctx, describeExternalService(before), summary)
+}
+
 type ExternalServicesArgs struct {
 	Namespace *graphql.ID
 	graphqlutil.ConnectionArgs
@@ -10,6 +10,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/externallink"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/vfsutil"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
@@ -214,6 +215,9 @@ func (r *GitCommitResolver) Blob(ctx context.Context, args *struct {
 	Path string
 }) (*GitTreeEntryResolver, error) {
 	stat, err := git.Stat(ctx, r.gitRepo, api.CommitID(r.oid), args.Path)
+	if err != nil && vfsutil.IsArchivedFile(args.Path) {
+		return r.archivedBlob(ctx, args.Path)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -227,6 +231,23 @@ func (r *GitCommitResolver) Blob(ctx context.Context, args *struct {
 	}, nil
 }
 
+// archivedBlob returns the blob of the file in an archive at the virtual
+// path name, see vfsutil.ReadArchivedFile.
+func (r *GitCommitResolver) archivedBlob(ctx context.Context, name string) (*GitTreeEntryResolver, error) {
+	content, err := vfsutil.ReadArchivedFile(ctx, r.gitRepo, api.CommitID(r.oid), name)
+	if err != nil {
+		return nil, err
+	}
+	entry := &GitTreeEntryResolver{
+		db:      r.db,
+		commit:  r,
+		stat:    CreateFileInfo(name, false),
+		content: content,
+	}
+	entry.contentOnce.Do(func() {})
+	return entry, nil
+}
+
 func (r *GitCommitResolver) File(ctx context.Context, args *struct {
 	Path string
 }) (*GitTreeEntryResolver, error) {
@@ -20,6 +20,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/externallink"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cloneurls"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/highlight"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/vfsutil"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
@@ -82,6 +83,16 @@ func (r *GitTreeEntryResolver) Content(ctx context.Context) (string, error) {
 			r.Path(),
 			0,
 		)
+		if r.contentErr != nil && vfsutil.IsArchivedFile(r.Path()) {
+			// Search results can be files in archives, which only exist
+			// at virtual paths.
+			r.content, r.contentErr = vfsutil.ReadArchivedFile(
+				ctx,
+				r.commit.repoResolver.RepoName(),
+				api.CommitID(r.commit.OID()),
+				r.Path(),
+			)
+		}
 	})
 
 	return string(r.content), r.contentErr
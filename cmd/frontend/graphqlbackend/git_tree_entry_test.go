@@ -1,17 +1,22 @@
 package graphqlbackend
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestGitTreeEntry_RawZipArchiveURL(t *testing.T) {
@@ -77,3 +82,59 @@ func TestGitTreeEntry_Content(t *testing.T) {
 		t.Fatalf("wrong file size, want=%d have=%d", want, have)
 	}
 }
+
+func TestGitTreeEntry_Content_archived(t *testing.T) {
+	var jar bytes.Buffer
+	zw := zip.NewWriter(&jar)
+	w, err := zw.Create("com/x/Y.java")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("class Y {}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if name != "lib/foo.jar" {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		return jar.Bytes(), nil
+	}
+	t.Cleanup(func() { git.Mocks.ReadFile = nil })
+
+	db := new(dbtesting.MockDB)
+	newEntry := func() *GitTreeEntryResolver {
+		return &GitTreeEntryResolver{
+			db: db,
+			commit: &GitCommitResolver{
+				repoResolver: NewRepositoryResolver(db, &types.Repo{Name: "my/repo"}),
+			},
+			stat: CreateFileInfo("lib/foo.jar!/com/x/Y.java", false),
+		}
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := newEntry().Content(context.Background())
+		if !os.IsNotExist(err) {
+			t.Fatalf("expected not exist error, got %v", err)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+			SearchArchives: &schema.SearchArchives{Enabled: true},
+		}})
+		t.Cleanup(func() { conf.Mock(nil) })
+
+		content, err := newEntry().Content(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content != "class Y {}" {
+			t.Fatalf("got %q", content)
+		}
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cloneurls"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -121,9 +122,27 @@ VARIABLES
 	}
 }
 
-func (prometheusTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+func (t *prometheusTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
 	start := time.Now()
+
+	// Every mutation except the analytics ones is recorded in the audit log. The resolvers can
+	// describe the target of the mutation and how it changed with audit.SetTarget and
+	// audit.SetChange.
+	var record *audit.Record
+	if isAuditedField(typeName, fieldName) && t.db != nil {
+		ctx, record = audit.Begin(ctx, "graphql."+fieldName, args)
+	}
+
 	return ctx, func(err *gqlerrors.QueryError) {
+		if record != nil {
+			// A nil *QueryError must not be passed as a non-nil error.
+			if err != nil {
+				record.Finish(ctx, t.db, err)
+			} else {
+				record.Finish(ctx, t.db, nil)
+			}
+		}
+
 		isErrStr := strconv.FormatBool(err != nil)
 		graphqlFieldHistogram.WithLabelValues(
 			prometheusTypeName(typeName),
@@ -141,6 +160,23 @@ func (prometheusTracer) TraceField(ctx context.Context, label, typeName, fieldNa
 	}
 }
 
+// unauditedMutations are the mutations that aren't recorded in the audit log: the analytics
+// mutations of the event logger, which the web app calls on every page view, even for anonymous
+// users.
+var unauditedMutations = map[string]struct{}{
+	"logEvent":     {},
+	"logUserEvent": {},
+}
+
+// isAuditedField returns true if calls of the field of the type are recorded in the audit log.
+func isAuditedField(typeName, fieldName string) bool {
+	if typeName != "Mutation" || strings.HasPrefix(fieldName, "__") {
+		return false
+	}
+	_, ok := unauditedMutations[fieldName]
+	return !ok
+}
+
 var allowedPrometheusFieldNames = map[[2]string]struct{}{
 	{"AccessTokenConnection", "nodes"}:          {},
 	{"File", "isDirectory"}:                     {},
@@ -103,6 +103,24 @@ func TestResolverTo(t *testing.T) {
 	}
 }
 
+func TestIsAuditedField(t *testing.T) {
+	for _, tc := range []struct {
+		typeName, fieldName string
+		want                bool
+	}{
+		{"Mutation", "updateSiteConfiguration", true},
+		{"Mutation", "deleteUser", true},
+		{"Mutation", "logEvent", false},
+		{"Mutation", "logUserEvent", false},
+		{"Mutation", "__typename", false},
+		{"Query", "site", false},
+	} {
+		if got := isAuditedField(tc.typeName, tc.fieldName); got != tc.want {
+			t.Errorf("isAuditedField(%q, %q) = %v, want %v", tc.typeName, tc.fieldName, got, tc.want)
+		}
+	}
+}
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 	if !testing.Verbose() {
@@ -0,0 +1,154 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/txemail"
+)
+
+func (r *siteResolver) OutboundEmails(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+	After *string
+	State *string
+}) (*outboundEmailConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins may see the emails sent to users.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	var opt txemail.OutboxListOptions
+	if args.First != nil {
+		opt.Limit = int(*args.First)
+	}
+	if args.After != nil {
+		id, err := strconv.Atoi(*args.After)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cursor")
+		}
+		opt.BeforeID = id
+	}
+	if args.State != nil {
+		opt.Status = strings.ToLower(*args.State)
+	}
+	return &outboundEmailConnectionResolver{store: txemail.NewOutboxStore(r.db), opt: opt}, nil
+}
+
+func (r *schemaResolver) RetryOutboundEmail(ctx context.Context, args *struct {
+	ID int32
+}) (*outboundEmailResolver, error) {
+	// 🚨 SECURITY: Only site admins may retry emails.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	store := txemail.NewOutboxStore(r.db)
+	ok, err := store.Retry(ctx, int(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	msg, exists, err := store.GetByID(ctx, int(args.ID))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.Errorf("outbound email %d not found", args.ID)
+	}
+	if !ok {
+		if msg.Status() == txemail.OutboxStateFailed && msg.TextBody == "" && msg.HTMLBody == "" {
+			return nil, errors.Errorf("outbound email %d can't be retried, because its body was deleted", args.ID)
+		}
+		return nil, errors.Errorf("outbound email %d can't be retried, because it is %s", args.ID, msg.Status())
+	}
+	return &outboundEmailResolver{msg: msg}, nil
+}
+
+type outboundEmailConnectionResolver struct {
+	store *txemail.OutboxStore
+	opt   txemail.OutboxListOptions
+
+	// cache results because they are used by multiple fields
+	once     sync.Once
+	messages []*txemail.OutboxMessage
+	err      error
+}
+
+func (r *outboundEmailConnectionResolver) compute(ctx context.Context) ([]*txemail.OutboxMessage, error) {
+	r.once.Do(func() {
+		opt := r.opt
+		if opt.Limit > 0 {
+			opt.Limit++ // so we can detect if there is a next page
+		}
+		r.messages, r.err = r.store.List(ctx, opt)
+	})
+	return r.messages, r.err
+}
+
+func (r *outboundEmailConnectionResolver) Nodes(ctx context.Context) ([]*outboundEmailResolver, error) {
+	messages, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.Limit > 0 && len(messages) > r.opt.Limit {
+		messages = messages[:r.opt.Limit]
+	}
+
+	l := make([]*outboundEmailResolver, 0, len(messages))
+	for _, m := range messages {
+		l = append(l, &outboundEmailResolver{msg: m})
+	}
+	return l, nil
+}
+
+func (r *outboundEmailConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	opt := r.opt
+	opt.BeforeID = 0
+	count, err := r.store.Count(ctx, opt)
+	return int32(count), err
+}
+
+func (r *outboundEmailConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	messages, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.Limit > 0 && len(messages) > r.opt.Limit {
+		return graphqlutil.NextPageCursor(strconv.Itoa(messages[r.opt.Limit-1].ID)), nil
+	}
+	return graphqlutil.HasNextPage(false), nil
+}
+
+type outboundEmailResolver struct {
+	msg *txemail.OutboxMessage
+}
+
+func (r *outboundEmailResolver) ID() int32 { return int32(r.msg.ID) }
+
+func (r *outboundEmailResolver) Recipients() []string { return r.msg.Recipients }
+
+func (r *outboundEmailResolver) Subject() string { return r.msg.Subject }
+
+func (r *outboundEmailResolver) State() string { return strings.ToUpper(r.msg.Status()) }
+
+func (r *outboundEmailResolver) FailedAttempts() int32 { return int32(r.msg.NumFailures) }
+
+func (r *outboundEmailResolver) FailureMessage() *string { return r.msg.FailureMessage }
+
+func (r *outboundEmailResolver) CreatedAt() DateTime { return DateTime{Time: r.msg.CreatedAt} }
+
+func (r *outboundEmailResolver) NextAttemptAt() *DateTime {
+	if r.msg.Status() != txemail.OutboxStateQueued || r.msg.NumFailures == 0 {
+		return nil
+	}
+	return DateTimeOrNil(r.msg.ProcessAfter)
+}
+
+func (r *outboundEmailResolver) DeliveredAt() *DateTime { return DateTimeOrNil(r.msg.DeliveredAt) }
+
+func (r *outboundEmailResolver) ProviderMessageID() *string { return r.msg.ProviderMessageID }
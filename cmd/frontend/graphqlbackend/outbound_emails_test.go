@@ -0,0 +1,61 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/txemail"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestOutboundEmails_NonAdmin(t *testing.T) {
+	resetMocks()
+	database.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	defer resetMocks()
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	db := new(dbtesting.MockDB)
+
+	if _, err := (&siteResolver{db: db}).OutboundEmails(ctx, &struct {
+		graphqlutil.ConnectionArgs
+		After *string
+		State *string
+	}{}); err != backend.ErrMustBeSiteAdmin {
+		t.Errorf("got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+	}
+	if _, err := (&schemaResolver{db: db}).RetryOutboundEmail(ctx, &struct{ ID int32 }{ID: 1}); err != backend.ErrMustBeSiteAdmin {
+		t.Errorf("got error %v, want %v", err, backend.ErrMustBeSiteAdmin)
+	}
+}
+
+func TestOutboundEmailResolver(t *testing.T) {
+	retryAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		msg           txemail.OutboxMessage
+		state         string
+		nextAttemptAt *DateTime
+	}{
+		{txemail.OutboxMessage{State: "queued"}, "QUEUED", nil},
+		{txemail.OutboxMessage{State: "queued", NumFailures: 2, ProcessAfter: &retryAt}, "QUEUED", &DateTime{Time: retryAt}},
+		{txemail.OutboxMessage{State: "errored", NumFailures: 1}, "QUEUED", nil},
+		{txemail.OutboxMessage{State: "processing", NumFailures: 1, ProcessAfter: &retryAt}, "SENDING", nil},
+		{txemail.OutboxMessage{State: "completed"}, "SENT", nil},
+		{txemail.OutboxMessage{State: "failed", NumFailures: 10, ProcessAfter: &retryAt}, "FAILED", nil},
+	} {
+		r := &outboundEmailResolver{msg: &tc.msg}
+		if state := r.State(); state != tc.state {
+			t.Errorf("%s: got state %q, want %q", tc.msg.State, state, tc.state)
+		}
+		if next := r.NextAttemptAt(); (next == nil) != (tc.nextAttemptAt == nil) || (next != nil && !next.Equal(tc.nextAttemptAt.Time)) {
+			t.Errorf("%s: got next attempt at %v, want %v", tc.msg.State, next, tc.nextAttemptAt)
+		}
+	}
+}
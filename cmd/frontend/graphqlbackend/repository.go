@@ -357,6 +357,21 @@ func (r *RepositoryResolver) LSIFIndexes(ctx context.Context, args *LSIFIndexesQ
 	})
 }
 
+func (r *RepositoryResolver) LSIFSymbolDiff(ctx context.Context, args *struct {
+	Base    string
+	Head    string
+	Root    *string
+	Indexer *string
+}) (LSIFSymbolDiffResolver, error) {
+	return EnterpriseResolvers.codeIntelResolver.LSIFSymbolDiffByRepo(ctx, &LSIFRepositorySymbolDiffArgs{
+		RepositoryID: r.ID(),
+		Base:         args.Base,
+		Head:         args.Head,
+		Root:         args.Root,
+		Indexer:      args.Indexer,
+	})
+}
+
 func (r *RepositoryResolver) IndexConfiguration(ctx context.Context) (IndexConfigurationResolver, error) {
 	return EnterpriseResolvers.codeIntelResolver.IndexConfiguration(ctx, r.ID())
 }
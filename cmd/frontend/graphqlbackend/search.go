@@ -45,6 +45,10 @@ type SearchArgs struct {
 	// to make it visible in the browser.
 	Stream streaming.Sender
 
+	// Exhaustive if true searches for all results of queries without a
+	// count: field, as if they had count:all.
+	Exhaustive bool
+
 	// For tests
 	Settings *schema.Settings
 }
@@ -54,6 +58,7 @@ type SearchImplementer interface {
 	Suggestions(context.Context, *searchSuggestionsArgs) ([]SearchSuggestionResolver, error)
 	//lint:ignore U1000 is used by graphql via reflection
 	Stats(context.Context) (*searchResultsStats, error)
+	Aggregations(context.Context, *searchAggregationsArgs) (*searchAggregationsResolver, error)
 
 	Inputs() run.SearchInputs
 }
@@ -95,6 +100,9 @@ func NewSearchImplementer(ctx context.Context, db dbutil.DB, args *SearchArgs) (
 	if err != nil {
 		return alertForQuery(args.Query, err).wrapSearchImplementer(db), nil
 	}
+	if args.Exhaustive {
+		plan = query.MapPlan(plan, query.CountAll)
+	}
 	tr.LazyPrintf("parsing done")
 
 	defaultLimit := defaultMaxSearchResults
@@ -0,0 +1,103 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	searchrepos "github.com/sourcegraph/sourcegraph/internal/search/repos"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+type searchAggregationsArgs struct {
+	Mode  string
+	Limit int32
+}
+
+// Aggregations runs the search for all of its results, unless the query has a
+// count: field, and groups and counts them by args.Mode.
+func (r *searchResolver) Aggregations(ctx context.Context, args *searchAggregationsArgs) (_ *searchAggregationsResolver, err error) {
+	tr, ctx := trace.New(ctx, "Aggregations", args.Mode)
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	mode, err := streaming.ParseAggregationMode(strings.ToLower(args.Mode))
+	if err != nil {
+		return nil, err
+	}
+	aggregations, err := streaming.NewSearchAggregations(mode, r.Plan)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := *r.SearchInputs
+	inputs.Plan = query.MapPlan(inputs.Plan, query.CountAll)
+	inputs.Query = inputs.Plan.ToParseTree()
+
+	// The repositories resolved by r are not shared, since r may be
+	// resolving them concurrently for its results.
+	exhaustive := *r
+	exhaustive.SearchInputs = &inputs
+	exhaustive.stream = nil
+	exhaustive.reposMu = &sync.Mutex{}
+	exhaustive.resolved = &searchrepos.Resolved{}
+	exhaustive.repoErr = nil
+
+	results, err := exhaustive.Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aggregations.Update(streaming.SearchEvent{
+		Results: results.Matches,
+		Stats:   results.Stats,
+	})
+	return &searchAggregationsResolver{aggregations.Compute(int(args.Limit))}, nil
+}
+
+func (alertSearchImplementer) Aggregations(context.Context, *searchAggregationsArgs) (*searchAggregationsResolver, error) {
+	return nil, nil
+}
+
+type searchAggregationsResolver struct {
+	aggregations *streaming.Aggregations
+}
+
+func (r *searchAggregationsResolver) Mode() string {
+	return strings.ToUpper(string(r.aggregations.Mode))
+}
+
+func (r *searchAggregationsResolver) Groups() []*searchAggregationResolver {
+	groups := make([]*searchAggregationResolver, 0, len(r.aggregations.Groups))
+	for _, g := range r.aggregations.Groups {
+		groups = append(groups, &searchAggregationResolver{g})
+	}
+	return groups
+}
+
+func (r *searchAggregationsResolver) OtherGroupCount() int32 {
+	return int32(r.aggregations.OtherGroupCount)
+}
+
+func (r *searchAggregationsResolver) OtherResultCount() int32 {
+	return int32(r.aggregations.OtherResultCount)
+}
+
+func (r *searchAggregationsResolver) Approximate() bool {
+	return r.aggregations.Approximate
+}
+
+type searchAggregationResolver struct {
+	aggregation streaming.Aggregation
+}
+
+func (r *searchAggregationResolver) Label() string {
+	return r.aggregation.Label
+}
+
+func (r *searchAggregationResolver) Count() int32 {
+	return int32(r.aggregation.Count)
+}
@@ -0,0 +1,79 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+	"github.com/sourcegraph/sourcegraph/internal/search/unindexed"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSearchResolver_Aggregations(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	mockDecodedViewerFinalSettings = &schema.Settings{}
+	defer func() { mockDecodedViewerFinalSettings = nil }()
+
+	database.Mocks.Repos.ListRepoNames = func(_ context.Context, op database.ReposListOptions) ([]types.RepoName, error) {
+		return []types.RepoName{{ID: 1, Name: "repo"}}, nil
+	}
+	database.Mocks.Repos.Count = mockCount
+	defer func() { database.Mocks = database.MockStores{} }()
+
+	var fileMatchLimit int32
+	unindexed.MockSearchFilesInRepos = func(args *search.TextParameters) ([]result.Match, *streaming.Stats, error) {
+		fileMatchLimit = args.PatternInfo.FileMatchLimit
+		repo := types.RepoName{ID: 1, Name: "repo"}
+		return []result.Match{
+			&result.FileMatch{
+				File: result.File{Repo: repo, Path: "a.go"},
+				LineMatches: []*result.LineMatch{
+					{Preview: "foo(bar)", OffsetAndLengths: [][2]int32{{0, 8}}},
+					{Preview: "foo(baz)", OffsetAndLengths: [][2]int32{{0, 8}}},
+				},
+			},
+			&result.FileMatch{
+				File:        result.File{Repo: repo, Path: "b.go"},
+				LineMatches: []*result.LineMatch{{Preview: "foo(bar)", OffsetAndLengths: [][2]int32{{0, 8}}}},
+			},
+		}, &streaming.Stats{}, nil
+	}
+	defer func() { unindexed.MockSearchFilesInRepos = nil }()
+
+	r, err := (&schemaResolver{db: db}).Search(context.Background(), &SearchArgs{Query: `repo:repo foo\((\w+)\) index:no`, Version: "V1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggregations, err := r.Aggregations(context.Background(), &searchAggregationsArgs{Mode: "CAPTURE_GROUP", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fileMatchLimit != query.CountAllLimit {
+		t.Errorf("expected search for all results, got file match limit %d", fileMatchLimit)
+	}
+
+	type group struct {
+		Label string
+		Count int32
+	}
+	var groups []group
+	for _, g := range aggregations.Groups() {
+		groups = append(groups, group{Label: g.Label(), Count: g.Count()})
+	}
+	if diff := cmp.Diff([]group{{Label: "bar", Count: 2}}, groups); diff != "" {
+		t.Errorf("unexpected groups (-want +got):\n%s", diff)
+	}
+	if aggregations.Mode() != "CAPTURE_GROUP" || aggregations.OtherGroupCount() != 1 || aggregations.OtherResultCount() != 1 || aggregations.Approximate() {
+		t.Errorf("unexpected aggregations %+v", aggregations.aggregations)
+	}
+}
@@ -402,10 +402,12 @@ func (*missingRepoRevsError) Error() string {
 func alertForMissingRepoRevs(missingRepoRevs []*search.RepositoryRevisions) *searchAlert {
 	var description string
 	if len(missingRepoRevs) == 1 {
-		if len(missingRepoRevs[0].RevSpecs()) == 1 {
-			description = fmt.Sprintf("The repository %s matched by your repo: filter could not be searched because it does not contain the revision %q.", missingRepoRevs[0].Repo.Name, missingRepoRevs[0].RevSpecs()[0])
+		if revs := missingRepoRevs[0].RevSpecs(); len(revs) == 1 && strings.HasPrefix(revs[0], "at.time(") {
+			description = fmt.Sprintf("The repository %s matched by your repo: filter could not be searched because it has no commit matching rev:%s.", missingRepoRevs[0].Repo.Name, revs[0])
+		} else if len(revs) == 1 {
+			description = fmt.Sprintf("The repository %s matched by your repo: filter could not be searched because it does not contain the revision %q.", missingRepoRevs[0].Repo.Name, revs[0])
 		} else {
-			description = fmt.Sprintf("The repository %s matched by your repo: filter could not be searched because it has multiple specified revisions: @%s.", missingRepoRevs[0].Repo.Name, strings.Join(missingRepoRevs[0].RevSpecs(), ","))
+			description = fmt.Sprintf("The repository %s matched by your repo: filter could not be searched because it has multiple specified revisions: @%s.", missingRepoRevs[0].Repo.Name, strings.Join(revs, ","))
 		}
 	} else {
 		sampleSize := 10
@@ -503,6 +505,7 @@ func alertForError(err error) *searchAlert {
 		alert *searchAlert
 		rErr  *run.RepoLimitError
 		tErr  *run.TimeLimitError
+		oErr  *run.OwnersError
 		mErr  *missingRepoRevsError
 	)
 
@@ -537,6 +540,13 @@ func alertForError(err error) *searchAlert {
 			description:    fmt.Sprintf(`%s search can currently only handle searching across %d repositories at a time. Try using the "repo:" filter to narrow down which repositories to search.`, strings.Title(tErr.ResultType), tErr.Max),
 			priority:       1,
 		}
+	} else if errors.As(err, &oErr) {
+		alert = &searchAlert{
+			prometheusType: "code_owners_unavailable",
+			title:          "Some code owners could not be resolved",
+			description:    fmt.Sprintf("The CODEOWNERS file of %s could not be read, so its files are missing from the results of this query. Try again later, or remove the `file:has.owner` filter.", oErr.Repo),
+			priority:       3,
+		}
 	}
 	return alert
 }
@@ -190,6 +190,18 @@ func TestAlertForDiffCommitSearchLimits(t *testing.T) {
 	}
 }
 
+func TestAlertForOwnersError(t *testing.T) {
+	multiErr := multierror.Append(&multierror.Error{}, &run.OwnersError{Repo: "github.com/foo/bar", Commit: "c1", Err: errors.New("gitserver unavailable")})
+	alert := alertForError(multiErr)
+	if alert == nil {
+		t.Fatal("expected alert")
+	}
+	want := "The CODEOWNERS file of github.com/foo/bar could not be read, so its files are missing from the results of this query. Try again later, or remove the `file:has.owner` filter."
+	if diff := cmp.Diff(want, alert.description); diff != "" {
+		t.Fatalf("mismatched alert (-want, +got):\n%s", diff)
+	}
+}
+
 func TestErrorToAlertStructuralSearch(t *testing.T) {
 	cases := []struct {
 		name           string
@@ -499,6 +499,7 @@ func (r *searchResolver) toRepoOptions(q query.Q, opts resolveRepositoriesOpts)
 	visibility := query.ParseVisibility(visibilityStr)
 
 	commitAfter, _ := q.StringValue(query.FieldRepoHasCommitAfter)
+	revAtTime, _ := q.StringValue(query.FieldRevAtTime)
 	searchContextSpec, _ := q.StringValue(query.FieldContext)
 
 	var versionContextName string
@@ -526,6 +527,7 @@ func (r *searchResolver) toRepoOptions(q query.Q, opts resolveRepositoriesOpts)
 		OnlyPrivate:        visibility == query.Private,
 		OnlyPublic:         visibility == query.Public,
 		CommitAfter:        commitAfter,
+		RevAtTime:          revAtTime,
 		Query:              q,
 		Ranked:             true,
 		Limit:              opts.limit,
@@ -1425,6 +1427,11 @@ func (r *searchResolver) doResults(ctx context.Context, args *search.TextParamet
 	}
 
 	agg := run.NewAggregator(r.db, stream)
+	if ownerFilter := run.NewOwnerFilter(args.Query); ownerFilter != nil {
+		agg.AddFilter(func(matches []result.Match) ([]result.Match, error) {
+			return ownerFilter.Filter(ctx, matches)
+		})
+	}
 
 	// finalize converts the content of the aggregator to a proper return value.
 	// finalize relies on all WaitGroups being done since it relies on collecting
@@ -11,6 +11,7 @@ import (
 	"github.com/graph-gophers/graphql-go/relay"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confdb"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/siteid"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
@@ -132,7 +133,11 @@ func (r *siteConfigurationResolver) ID(ctx context.Context) (int32, error) {
 	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
 		return 0, err
 	}
-	return 0, nil // TODO(slimsag): future: return the real ID here to prevent races
+	versions, err := confdb.SiteList(ctx, confdb.SiteListOptions{Limit: 1})
+	if err != nil || len(versions) == 0 {
+		return 0, err
+	}
+	return versions[0].ID, nil
 }
 
 func (r *siteConfigurationResolver) EffectiveContents(ctx context.Context) (JSONCString, error) {
@@ -175,12 +180,17 @@ func (r *schemaResolver) UpdateSiteConfiguration(ctx context.Context, args *stru
 		return false, errors.Errorf("site configuration is invalid: %s", strings.Join(problems, ","))
 	}
 
+	before, err := confdb.SiteGetLatest(ctx)
+	if err != nil {
+		return false, err
+	}
 	prev := globals.ConfigurationServerFrontendOnly.Raw()
 	prev.Site = args.Input
 	// TODO(slimsag): future: actually pass lastID through to prevent race conditions
 	if err := globals.ConfigurationServerFrontendOnly.Write(ctx, prev); err != nil {
 		return false, err
 	}
+	auditSiteConfigChange(ctx, before, args.Input)
 	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
 }
 
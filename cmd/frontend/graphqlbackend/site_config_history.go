@@ -0,0 +1,336 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+)
+
+func (r *siteConfigurationResolver) History(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+	After *string
+}) (*siteConfigurationChangeConnectionResolver, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	var opt confdb.SiteListOptions
+	if args.First != nil {
+		opt.Limit = int(*args.First)
+	}
+	if args.After != nil {
+		id, err := strconv.ParseInt(*args.After, 10, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid cursor")
+		}
+		opt.BeforeID = int32(id)
+	}
+	return &siteConfigurationChangeConnectionResolver{db: r.db, opt: opt}, nil
+}
+
+func (r *siteConfigurationResolver) Diff(ctx context.Context, args *struct {
+	From int32
+	To   *int32
+}) ([]*siteConfigurationDiffEntryResolver, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return nil, err
+	}
+
+	from, err := siteConfigByID(ctx, args.From)
+	if err != nil {
+		return nil, err
+	}
+	to := globals.ConfigurationServerFrontendOnly.Raw().Site
+	if args.To != nil {
+		c, err := siteConfigByID(ctx, *args.To)
+		if err != nil {
+			return nil, err
+		}
+		to = c.Contents
+	}
+
+	var before, after interface{}
+	if err := jsonc.Unmarshal(from.Contents, &before); err != nil {
+		return nil, errors.Wrapf(err, "parsing site configuration %d", from.ID)
+	}
+	if err := jsonc.Unmarshal(to, &after); err != nil {
+		return nil, errors.Wrap(err, "parsing site configuration")
+	}
+	return diffJSON("", before, after), nil
+}
+
+func (r *schemaResolver) RollbackSiteConfiguration(ctx context.Context, args *struct {
+	ID int32
+}) (bool, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, r.db); err != nil {
+		return false, err
+	}
+	if !canUpdateSiteConfiguration() {
+		return false, errors.New("updating site configuration not allowed when using SITE_CONFIG_FILE")
+	}
+
+	c, err := siteConfigByID(ctx, args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	// The schema may have changed since the version was saved, so a version that was valid then
+	// may not be valid anymore.
+	if problems, err := conf.ValidateSite(c.Contents); err != nil {
+		return false, errors.Errorf("failed to validate site configuration: %w", err)
+	} else if len(problems) > 0 {
+		return false, errors.Errorf("site configuration %d is invalid: %s", c.ID, strings.Join(problems, ","))
+	}
+
+	before, err := confdb.SiteGetLatest(ctx)
+	if err != nil {
+		return false, err
+	}
+	prev := globals.ConfigurationServerFrontendOnly.Raw()
+	prev.Site = c.Contents
+	if err := globals.ConfigurationServerFrontendOnly.Write(ctx, prev); err != nil {
+		return false, err
+	}
+	auditSiteConfigChange(ctx, before, c.Contents)
+	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
+}
+
+// auditSiteConfigChange records the change of the site configuration from the version before to
+// the contents after in the audit log, as the versions and the paths that changed. The values are
+// not recorded, because they may be secrets.
+func auditSiteConfigChange(ctx context.Context, before *confdb.SiteConfig, after string) {
+	summary := "changed " + strings.Join(changedJSONPaths(before.Contents, after), ", ")
+	if latest, err := confdb.SiteGetLatest(ctx); err == nil {
+		summary = fmt.Sprintf("version %d, %s", latest.ID, summary)
+	}
+	audit.SetTarget(ctx, "site configuration")
+	audit.SetChange(ctx, fmt.Sprintf("version %d", before.ID), summary)
+}
+
+func siteConfigByID(ctx context.Context, id int32) (*confdb.SiteConfig, error) {
+	c, err := confdb.SiteGetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, errors.Errorf("site configuration %d not found", id)
+	}
+	return c, nil
+}
+
+// siteConfigurationChangeConnectionResolver resolves a list of site configuration versions.
+//
+// 🚨 SECURITY: When instantiating a siteConfigurationChangeConnectionResolver value, the caller
+// MUST check that the current user is a site admin.
+type siteConfigurationChangeConnectionResolver struct {
+	db  dbutil.DB
+	opt confdb.SiteListOptions
+
+	// cache results because they are used by multiple fields
+	once     sync.Once
+	versions []*confdb.SiteConfig
+	err      error
+}
+
+func (r *siteConfigurationChangeConnectionResolver) compute(ctx context.Context) ([]*confdb.SiteConfig, error) {
+	r.once.Do(func() {
+		opt := r.opt
+		if opt.Limit > 0 {
+			opt.Limit++ // so we can detect if there is a next page
+		}
+		r.versions, r.err = confdb.SiteList(ctx, opt)
+	})
+	return r.versions, r.err
+}
+
+func (r *siteConfigurationChangeConnectionResolver) Nodes(ctx context.Context) ([]*siteConfigurationChangeResolver, error) {
+	versions, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.Limit > 0 && len(versions) > r.opt.Limit {
+		versions = versions[:r.opt.Limit]
+	}
+
+	l := make([]*siteConfigurationChangeResolver, 0, len(versions))
+	for _, v := range versions {
+		l = append(l, &siteConfigurationChangeResolver{db: r.db, siteConfig: v})
+	}
+	return l, nil
+}
+
+func (r *siteConfigurationChangeConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := confdb.SiteCount(ctx)
+	return int32(count), err
+}
+
+func (r *siteConfigurationChangeConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	versions, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.Limit > 0 && len(versions) > r.opt.Limit {
+		return graphqlutil.NextPageCursor(strconv.Itoa(int(versions[r.opt.Limit-1].ID))), nil
+	}
+	return graphqlutil.HasNextPage(false), nil
+}
+
+type siteConfigurationChangeResolver struct {
+	db         dbutil.DB
+	siteConfig *confdb.SiteConfig
+}
+
+func (r *siteConfigurationChangeResolver) ID() int32 { return r.siteConfig.ID }
+
+func (r *siteConfigurationChangeResolver) Author(ctx context.Context) (*UserResolver, error) {
+	if r.siteConfig.AuthorUserID == 0 {
+		return nil, nil
+	}
+	user, err := UserByIDInt32(ctx, r.db, r.siteConfig.AuthorUserID)
+	if err != nil && errcode.IsNotFound(err) {
+		// Don't throw an error if a user has been deleted.
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *siteConfigurationChangeResolver) Contents() JSONCString {
+	return JSONCString(r.siteConfig.Contents)
+}
+
+func (r *siteConfigurationChangeResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.siteConfig.CreatedAt}
+}
+
+const (
+	siteConfigDiffAdded   = "ADDED"
+	siteConfigDiffRemoved = "REMOVED"
+	siteConfigDiffChanged = "CHANGED"
+)
+
+type siteConfigurationDiffEntryResolver struct {
+	path          string
+	kind          string
+	before, after interface{}
+}
+
+func (r *siteConfigurationDiffEntryResolver) Path() string { return r.path }
+func (r *siteConfigurationDiffEntryResolver) Kind() string { return r.kind }
+
+func (r *siteConfigurationDiffEntryResolver) Before() *JSONValue {
+	if r.kind == siteConfigDiffAdded {
+		return nil
+	}
+	return &JSONValue{r.before}
+}
+
+func (r *siteConfigurationDiffEntryResolver) After() *JSONValue {
+	if r.kind == siteConfigDiffRemoved {
+		return nil
+	}
+	return &JSONValue{r.after}
+}
+
+// diffJSON returns the differences between two unmarshaled JSON values, with the paths of the
+// differences as JSON Pointers below path. Objects are compared by key and arrays by index, so
+// inserting an element into an array is reported as changes to the elements after it.
+func diffJSON(path string, before, after interface{}) []*siteConfigurationDiffEntryResolver {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(b)+len(a))
+		for k := range b {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := b[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		var diffs []*siteConfigurationDiffEntryResolver
+		for _, k := range keys {
+			p := path + "/" + escapeJSONPointer(k)
+			bv, inBefore := b[k]
+			av, inAfter := a[k]
+			switch {
+			case !inBefore:
+				diffs = append(diffs, &siteConfigurationDiffEntryResolver{path: p, kind: siteConfigDiffAdded, after: av})
+			case !inAfter:
+				diffs = append(diffs, &siteConfigurationDiffEntryResolver{path: p, kind: siteConfigDiffRemoved, before: bv})
+			default:
+				diffs = append(diffs, diffJSON(p, bv, av)...)
+			}
+		}
+		return diffs
+
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok {
+			break
+		}
+		var diffs []*siteConfigurationDiffEntryResolver
+		for i := 0; i < len(b) || i < len(a); i++ {
+			p := path + "/" + strconv.Itoa(i)
+			switch {
+			case i >= len(b):
+				diffs = append(diffs, &siteConfigurationDiffEntryResolver{path: p, kind: siteConfigDiffAdded, after: a[i]})
+			case i >= len(a):
+				diffs = append(diffs, &siteConfigurationDiffEntryResolver{path: p, kind: siteConfigDiffRemoved, before: b[i]})
+			default:
+				diffs = append(diffs, diffJSON(p, b[i], a[i])...)
+			}
+		}
+		return diffs
+	}
+
+	if reflect.DeepEqual(before, after) {
+		return nil
+	}
+	return []*siteConfigurationDiffEntryResolver{{path: path, kind: siteConfigDiffChanged, before: before, after: after}}
+}
+
+// changedJSONPaths returns the JSON Pointers of the values that differ between two JSONC
+// documents.
+func changedJSONPaths(before, after string) []string {
+	var b, a interface{}
+	_ = jsonc.Unmarshal(before, &b)
+	_ = jsonc.Unmarshal(after, &a)
+	diffs := diffJSON("", b, a)
+	paths := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		paths = append(paths, d.path)
+	}
+	return paths
+}
+
+// escapeJSONPointer escapes a reference token of a JSON Pointer (RFC 6901).
+func escapeJSONPointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
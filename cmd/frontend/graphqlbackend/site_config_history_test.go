@@ -0,0 +1,106 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestDiffJSON(t *testing.T) {
+	type entry struct {
+		Path, Kind    string
+		Before, After interface{}
+	}
+
+	tests := map[string]struct {
+		before, after string
+		want          []entry
+	}{
+		"equal": {
+			before: `{"a": 1, "b": [1, {"c": true}]}`,
+			after:  `{"b": [1, {"c": true}], "a": 1} // comment`,
+		},
+		"added, removed and changed properties": {
+			before: `{"a": 1, "b": "x", "c": null}`,
+			after:  `{"a": 2, "c": null, "d": {"e": []}}`,
+			want: []entry{
+				{Path: "/a", Kind: "CHANGED", Before: 1.0, After: 2.0},
+				{Path: "/b", Kind: "REMOVED", Before: "x"},
+				{Path: "/d", Kind: "ADDED", After: map[string]interface{}{"e": []interface{}{}}},
+			},
+		},
+		"nested": {
+			before: `{"auth.providers": [{"type": "builtin"}, {"type": "saml", "a/b~": 1}]}`,
+			after:  `{"auth.providers": [{"type": "builtin", "allowSignup": true}, {"type": "saml", "a/b~": 2}]}`,
+			want: []entry{
+				{Path: "/auth.providers/0/allowSignup", Kind: "ADDED", After: true},
+				{Path: "/auth.providers/1/a~1b~0", Kind: "CHANGED", Before: 1.0, After: 2.0},
+			},
+		},
+		"array elements added and removed": {
+			before: `{"a": [1, 2], "b": [1, 2, 3]}`,
+			after:  `{"a": [1, 2, 3], "b": [1]}`,
+			want: []entry{
+				{Path: "/a/2", Kind: "ADDED", After: 3.0},
+				{Path: "/b/1", Kind: "REMOVED", Before: 2.0},
+				{Path: "/b/2", Kind: "REMOVED", Before: 3.0},
+			},
+		},
+		"type changed": {
+			before: `{"a": [1], "b": {"c": 1}}`,
+			after:  `{"a": {"0": 1}, "b": "c"}`,
+			want: []entry{
+				{Path: "/a", Kind: "CHANGED", Before: []interface{}{1.0}, After: map[string]interface{}{"0": 1.0}},
+				{Path: "/b", Kind: "CHANGED", Before: map[string]interface{}{"c": 1.0}, After: "c"},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var before, after interface{}
+			if err := jsonc.Unmarshal(test.before, &before); err != nil {
+				t.Fatal(err)
+			}
+			if err := jsonc.Unmarshal(test.after, &after); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []entry
+			for _, d := range diffJSON("", before, after) {
+				e := entry{Path: d.Path(), Kind: d.Kind()}
+				if v := d.Before(); v != nil {
+					e.Before = v.Value
+				}
+				if v := d.After(); v != nil {
+					e.After = v.Value
+				}
+				got = append(got, e)
+			}
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("diff mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRollbackSiteConfiguration_NonAdmin(t *testing.T) {
+	resetMocks()
+	database.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1}, nil
+	}
+	defer resetMocks()
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	_, err := (&schemaResolver{db: new(dbtesting.MockDB)}).RollbackSiteConfiguration(ctx, &struct{ ID int32 }{ID: 1})
+	if want := backend.ErrMustBeSiteAdmin; err != want {
+		t.Errorf("got error %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,213 @@
+// Package apiratelimit enforces per-user, per-access-token and per-IP rate limits and daily
+// quotas on the API endpoints that are expensive to serve, as configured in the "api.ratelimit"
+// site configuration. Limits are tracked in Redis, so that they are shared by all frontend
+// instances, and while they are enabled the usage of each consumer is recorded to show site
+// admins the top consumers.
+package apiratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/throttled/throttled/v2"
+	"github.com/throttled/throttled/v2/store/redigostore"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/redispool"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// Endpoint is an API endpoint that is rate limited.
+type Endpoint string
+
+const (
+	// EndpointGraphQL is the GraphQL API. Requests are counted in estimated query cost.
+	EndpointGraphQL Endpoint = "graphql"
+	// EndpointSearch is the streaming search API.
+	EndpointSearch Endpoint = "search"
+	// EndpointCodeIntelUpload is the code intelligence (LSIF) upload API.
+	EndpointCodeIntelUpload Endpoint = "codeintel-upload"
+)
+
+// DefaultGraphQLCost is the cost of GraphQL requests whose cost can't be estimated, so that they
+// can't be used to bypass the limits.
+const DefaultGraphQLCost = 500
+
+// Endpoints are all the rate limited endpoints.
+var Endpoints = []Endpoint{EndpointGraphQL, EndpointSearch, EndpointCodeIntelUpload}
+
+// Result is the outcome of checking the limits of a request.
+type Result struct {
+	// Enforced is whether any limit applies to the request.
+	Enforced bool
+	// Limited is whether the request exceeds a limit and must be rejected.
+	Limited bool
+	// RateLimitResult describes the most restrictive limit the request is subject to.
+	throttled.RateLimitResult
+}
+
+// A Limiter checks requests against the configured limits and records the usage of the API.
+type Limiter struct {
+	store    throttled.GCRAStore
+	usage    UsageStore
+	policies atomic.Value // map[Endpoint]*policy, nil while rate limiting is disabled
+}
+
+// NewLimiter returns a Limiter that keeps track of limits in store and records usage in usage.
+// No limits are enforced and no usage is recorded until the limiter is configured.
+func NewLimiter(store throttled.GCRAStore, usage UsageStore) *Limiter {
+	l := &Limiter{store: store, usage: usage}
+	l.policies.Store(map[Endpoint]*policy(nil))
+	return l
+}
+
+// Check checks whether a request to the endpoint with the given cost is within the limits of
+// its subject, and records its usage if rate limiting is enabled. The cost of the request is
+// deducted from the remaining limits unless it is limited.
+func (l *Limiter) Check(r *http.Request, endpoint Endpoint, cost int) (Result, error) {
+	s, ok := SubjectFromRequest(r)
+	if !ok {
+		return Result{}, nil
+	}
+
+	policies := l.policies.Load().(map[Endpoint]*policy)
+	if policies == nil {
+		// Rate limiting is disabled.
+		return Result{}, nil
+	}
+
+	var res Result
+	if p := policies[endpoint]; p != nil {
+		var err error
+		if res, err = p.check(endpoint, s, cost); err != nil {
+			return Result{}, err
+		}
+	}
+
+	if l.usage != nil {
+		if err := l.usage.Record(endpoint, s, cost, res.Limited); err != nil {
+			log15.Warn("apiratelimit: recording API usage", "endpoint", endpoint, "error", err)
+		}
+	}
+	return res, nil
+}
+
+// Middleware returns a handler that rejects the requests to next that exceed the limits of the
+// endpoint with 429 Too Many Requests. Each request costs 1. If the limits can't be checked,
+// the request is allowed.
+//
+// It must be called after the actor is set on the request context.
+func (l *Limiter) Middleware(endpoint Endpoint, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := l.Check(r, endpoint, 1)
+		if err != nil {
+			log15.Error("apiratelimit: checking API rate limit", "endpoint", endpoint, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		WriteHeaders(w, res)
+		if res.Limited {
+			http.Error(w, "API rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WriteHeaders sets the standard rate limit headers describing res on the response:
+// X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset (the number of seconds until the
+// limit is fully replenished) and, if the request was limited, Retry-After.
+func WriteHeaders(w http.ResponseWriter, res Result) {
+	if !res.Enforced {
+		return
+	}
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+	if res.Limited && res.RetryAfter > 0 {
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// updateFromConfig replaces the policies of the limiter with the ones configured in c. If c is
+// nil or disabled, no limits are enforced and no usage is recorded.
+func (l *Limiter) updateFromConfig(c *schema.ApiRatelimit) {
+	var policies map[Endpoint]*policy
+	if c != nil && c.Enabled {
+		var err error
+		if policies, err = newPolicies(l.store, c); err != nil {
+			log15.Warn("apiratelimit: invalid api.ratelimit configuration, keeping the previous limits", "error", err)
+			return
+		}
+	}
+	l.policies.Store(policies)
+}
+
+var defaultLimiter atomic.Value // *Limiter
+
+// Init sets up the limiter used by Middleware, Check and TopConsumers, which keeps track of
+// limits and usage in Redis and is kept up to date with the site configuration.
+func Init() error {
+	store, err := redigostore.New(redispool.Cache, "api:rl:", 0)
+	if err != nil {
+		return err
+	}
+
+	l := NewLimiter(store, NewRedisUsageStore(redispool.Cache))
+	conf.Watch(func() {
+		l.updateFromConfig(conf.Get().ApiRatelimit)
+	})
+	defaultLimiter.Store(l)
+	return nil
+}
+
+func getDefault() *Limiter {
+	l, _ := defaultLimiter.Load().(*Limiter)
+	return l
+}
+
+// Check checks a request against the limits of the endpoint with the limiter set up by Init
+// (see (*Limiter).Check). No limits are enforced if Init was not called.
+func Check(r *http.Request, endpoint Endpoint, cost int) (Result, error) {
+	if l := getDefault(); l != nil {
+		return l.Check(r, endpoint, cost)
+	}
+	return Result{}, nil
+}
+
+// Middleware enforces the limits of the endpoint on the requests to next with the limiter set
+// up by Init (see (*Limiter).Middleware). No limits are enforced if Init was not called.
+func Middleware(endpoint Endpoint, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l := getDefault(); l != nil {
+			l.Middleware(endpoint, next).ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MockTopConsumers mocks TopConsumers in tests.
+var MockTopConsumers func(endpoint Endpoint, day time.Time, n int) ([]*Consumer, error)
+
+// TopConsumers returns the n subjects that used the endpoint the most on the day of the given
+// time (in UTC) with the limiter set up by Init. It returns nothing if Init was not called.
+func TopConsumers(endpoint Endpoint, day time.Time, n int) ([]*Consumer, error) {
+	if MockTopConsumers != nil {
+		return MockTopConsumers(endpoint, day, n)
+	}
+	if l := getDefault(); l != nil && l.usage != nil {
+		return l.usage.TopConsumers(endpoint, day, n)
+	}
+	return nil, nil
+}
@@ -0,0 +1,289 @@
+package apiratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/throttled/throttled/v2/store/memstore"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func newTestLimiter(t *testing.T, c *schema.ApiRatelimit) (*Limiter, *fakeUsageStore) {
+	t.Helper()
+	store, err := memstore.New(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage := &fakeUsageStore{}
+	l := NewLimiter(store, usage)
+	l.updateFromConfig(c)
+	return l, usage
+}
+
+func newRequest(a *actor.Actor) *http.Request {
+	r := httptest.NewRequest("POST", "/.api/search/stream", nil)
+	r.RemoteAddr = "198.51.100.1:1234"
+	if a != nil {
+		r = r.WithContext(actor.WithActor(context.Background(), a))
+	}
+	return r
+}
+
+// allowed returns the number of requests with the given cost that are allowed before r is
+// limited, up to max.
+func allowed(t *testing.T, l *Limiter, endpoint Endpoint, r *http.Request, cost, max int) int {
+	t.Helper()
+	for i := 0; i < max; i++ {
+		res, err := l.Check(r, endpoint, cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Limited {
+			return i
+		}
+	}
+	return max
+}
+
+func TestLimiter_Check(t *testing.T) {
+	user := &actor.Actor{UID: 1}
+	token := &actor.Actor{UID: 1, AccessTokenID: 5}
+
+	t.Run("disabled", func(t *testing.T) {
+		l, usage := newTestLimiter(t, &schema.ApiRatelimit{Enabled: false, PerUser: 1, PerIP: 1})
+		if n := allowed(t, l, EndpointGraphQL, newRequest(user), 1, 10); n != 10 {
+			t.Errorf("got %d allowed requests, want 10", n)
+		}
+		if len(usage.records) != 0 {
+			t.Errorf("got records %v, want none", usage.records)
+		}
+	})
+
+	t.Run("enabled without limits records usage", func(t *testing.T) {
+		l, usage := newTestLimiter(t, &schema.ApiRatelimit{Enabled: true})
+		if n := allowed(t, l, EndpointSearch, newRequest(user), 1, 1); n != 1 {
+			t.Errorf("got %d allowed requests, want 1", n)
+		}
+		if want := []string{"search user:1 1 false"}; !cmp.Equal(want, usage.records) {
+			t.Errorf("got records %v, want %v", usage.records, want)
+		}
+	})
+
+	t.Run("limited requests are not charged", func(t *testing.T) {
+		l, _ := newTestLimiter(t, &schema.ApiRatelimit{Enabled: true, PerUser: 10, PerToken: 20})
+		// The user's limit is used up directly, so requests with the token are rejected by the
+		// user's limit and must not use up the token's own limit.
+		if n := allowed(t, l, EndpointGraphQL, newRequest(user), 1, 100); n != 3 {
+			t.Errorf("got %d allowed user requests, want 3", n)
+		}
+		if n := allowed(t, l, EndpointGraphQL, newRequest(token), 1, 100); n != 0 {
+			t.Errorf("got %d allowed token requests, want 0", n)
+		}
+		tokenLimit := l.policies.Load().(map[Endpoint]*policy)[EndpointGraphQL].hourly[SubjectToken]
+		limited, r, err := tokenLimit.RateLimit("graphql:hour:token:5", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if limited || r.Remaining != 5 {
+			t.Errorf("got token limit (limited %v, remaining %d), want the full limit (limited false, remaining 5)", limited, r.Remaining)
+		}
+	})
+
+	t.Run("per user, token and IP", func(t *testing.T) {
+		l, _ := newTestLimiter(t, &schema.ApiRatelimit{Enabled: true, PerUser: 20, PerToken: 10, PerIP: 5})
+		// The limits allow bursts of 20% of the hourly limit, plus one request.
+		for _, tc := range []struct {
+			name string
+			r    *http.Request
+			want int
+		}{
+			{"token", newRequest(token), 3},
+			// Tokens are also charged to their user, who has 2 requests left.
+			{"other token of the user", newRequest(&actor.Actor{UID: 1, AccessTokenID: 6}), 2},
+			{"user", newRequest(user), 0},
+			{"other user", newRequest(&actor.Actor{UID: 2}), 5},
+			{"ip", newRequest(nil), 2},
+			{"internal", newRequest(actor.FromContext(actor.WithInternalActor(context.Background()))), 100},
+		} {
+			if n := allowed(t, l, EndpointGraphQL, tc.r, 1, 100); n != tc.want {
+				t.Errorf("%s: got %d allowed requests, want %d", tc.name, n, tc.want)
+			}
+		}
+	})
+
+	t.Run("cost over burst", func(t *testing.T) {
+		l, _ := newTestLimiter(t, &schema.ApiRatelimit{Enabled: true, PerUser: 100})
+		// The burst is 20 requests, so requests costing more are charged the whole limit.
+		if n := allowed(t, l, EndpointGraphQL, newRequest(user), DefaultGraphQLCost, 10); n != 1 {
+			t.Errorf("got %d allowed requests, want 1", n)
+		}
+		res, err := l.Check(newRequest(user), EndpointGraphQL, DefaultGraphQLCost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Limited || res.RetryAfter <= 0 {
+			t.Errorf("got limited %v and retry after %v, want a limited request with a retry delay", res.Limited, res.RetryAfter)
+		}
+	})
+
+	t.Run("token defaults to user limit", func(t *testing.T) {
+		l, _ := newTestLimiter(t, &schema.ApiRatelimit{Enabled: true, PerUser: 10})
+		if n := allowed(t, l, EndpointGraphQL, newRequest(token), 1, 100); n != 3 {
+			t.Errorf("got %d allowed requests, want 3", n)
+		}
+	})
+
+	t.Run("daily quota", func(t *testing.T) {
+		l, _ := newTestLimiter(t, &schema.ApiRatelimit{
+			Enabled: true,
+			Search: &schema.ApiRateLimitPolicy{
+				PerUser:    100000,
+				DailyQuota: &schema.ApiRateLimitQuota{PerUser: 4},
+			},
+		})
+		if n := allowed(t, l, EndpointSearch, newRequest(user), 1, 100); n != 4 {
+			t.Errorf("got %d allowed requests, want 4", n)
+		}
+		// GraphQL has no limits configured.
+		if n := allowed(t, l, EndpointGraphQL, newRequest(user), 1, 100); n != 100 {
+			t.Errorf("got %d allowed GraphQL requests, want 100", n)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		l, _ := newTestLimiter(t, &schema.ApiRatelimit{
+			Enabled: true,
+			PerUser: 10,
+			PerIP:   10,
+			Overrides: []*schema.Overrides{
+				{Key: "1", Limit: "unlimited"},
+				{Key: "2", Limit: "blocked"},
+				{Key: "203.0.113.9", Limit: float64(50)},
+			},
+		})
+		ip := newRequest(nil)
+		ip.RemoteAddr = "203.0.113.9:1234"
+		for _, tc := range []struct {
+			name string
+			r    *http.Request
+			want int
+		}{
+			{"unlimited user", newRequest(user), 100},
+			{"unlimited user's token", newRequest(token), 100},
+			{"blocked user", newRequest(&actor.Actor{UID: 2}), 0},
+			{"ip", ip, 11},
+			{"other user", newRequest(&actor.Actor{UID: 3}), 3},
+		} {
+			if n := allowed(t, l, EndpointGraphQL, tc.r, 1, 100); n != tc.want {
+				t.Errorf("%s: got %d allowed requests, want %d", tc.name, n, tc.want)
+			}
+		}
+	})
+
+	t.Run("invalid config keeps previous limits", func(t *testing.T) {
+		l, _ := newTestLimiter(t, &schema.ApiRatelimit{Enabled: true, PerUser: 10})
+		l.updateFromConfig(&schema.ApiRatelimit{
+			Enabled:   true,
+			Overrides: []*schema.Overrides{{Key: "1", Limit: "sometimes"}},
+		})
+		if n := allowed(t, l, EndpointGraphQL, newRequest(user), 1, 100); n != 3 {
+			t.Errorf("got %d allowed requests, want 3", n)
+		}
+	})
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	l, usage := newTestLimiter(t, &schema.ApiRatelimit{
+		Enabled:          true,
+		CodeIntelUploads: &schema.ApiRateLimitPolicy{PerUser: 5},
+	})
+	h := l.Middleware(EndpointCodeIntelUpload, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest(&actor.Actor{UID: 1}))
+		return w
+	}
+
+	w := serve()
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusAccepted)
+	}
+	for header, want := range map[string]string{
+		"X-RateLimit-Limit":     "2",
+		"X-RateLimit-Remaining": "1",
+		"X-RateLimit-Reset":     "720",
+		"Retry-After":           "",
+	} {
+		if have := w.Header().Get(header); have != want {
+			t.Errorf("got %s %q, want %q", header, have, want)
+		}
+	}
+
+	serve()
+	w = serve()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if have := w.Header().Get("X-RateLimit-Remaining"); have != "0" {
+		t.Errorf("got X-RateLimit-Remaining %q, want 0", have)
+	}
+	if have := w.Header().Get("Retry-After"); have == "" || have == "0" {
+		t.Errorf("got Retry-After %q, want a positive number of seconds", have)
+	}
+
+	want := []string{
+		"codeintel-upload user:1 1 false",
+		"codeintel-upload user:1 1 false",
+		"codeintel-upload user:1 1 true",
+	}
+	if diff := cmp.Diff(want, usage.records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseSubject(t *testing.T) {
+	for key, want := range map[string]*Subject{
+		"user:42":        {Kind: SubjectUser, ID: "42", UserID: 42},
+		"token:7":        {Kind: SubjectToken, ID: "7"},
+		"ip:2001:db8::1": {Kind: SubjectIP, ID: "2001:db8::1"},
+		"user:alice":     nil,
+		"group:1":        nil,
+		"1":              nil,
+	} {
+		s, ok := parseSubject(key)
+		if want == nil {
+			if ok {
+				t.Errorf("%q: got subject %+v, want none", key, s)
+			}
+			continue
+		}
+		if !ok || s != *want {
+			t.Errorf("%q: got subject %+v (%v), want %+v", key, s, ok, *want)
+		} else if s.String() != key {
+			t.Errorf("%q: got key %q", key, s.String())
+		}
+	}
+}
+
+type fakeUsageStore struct {
+	records []string
+}
+
+func (s *fakeUsageStore) Record(endpoint Endpoint, subject Subject, cost int, limited bool) error {
+	s.records = append(s.records, fmt.Sprintf("%s %s %d %v", endpoint, subject, cost, limited))
+	return nil
+}
+
+func (s *fakeUsageStore) TopConsumers(Endpoint, time.Time, int) ([]*Consumer, error) {
+	return nil, nil
+}
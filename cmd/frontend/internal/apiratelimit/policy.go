@@ -0,0 +1,274 @@
+package apiratelimit
+
+import (
+	"strconv"
+
+	"github.com/cockroachdb/errors"
+	"github.com/throttled/throttled/v2"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// We can burst up to a max of 20% of an hourly limit, like the GraphQL rate limiter does.
+const maxBurstPercentage = 0.2
+
+type limiter interface {
+	RateLimit(key string, quantity int) (bool, throttled.RateLimitResult, error)
+}
+
+// policy is the set of limits of an endpoint.
+type policy struct {
+	hourly map[SubjectKind]limiter
+	daily  map[SubjectKind]limiter
+	// overrides replace the hourly limits of the users and IP addresses they are keyed by.
+	overrides map[string]limiter
+}
+
+func newPolicies(store throttled.GCRAStore, c *schema.ApiRatelimit) (map[Endpoint]*policy, error) {
+	graphql, err := newPolicy(store, &schema.ApiRateLimitPolicy{
+		PerUser:    c.PerUser,
+		PerToken:   c.PerToken,
+		PerIP:      c.PerIP,
+		DailyQuota: c.DailyQuota,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "graphql")
+	}
+	if graphql.overrides, err = newOverrides(store, c.Overrides); err != nil {
+		return nil, errors.Wrap(err, "overrides")
+	}
+
+	policies := map[Endpoint]*policy{EndpointGraphQL: graphql}
+	for endpoint, c := range map[Endpoint]*schema.ApiRateLimitPolicy{
+		EndpointSearch:          c.Search,
+		EndpointCodeIntelUpload: c.CodeIntelUploads,
+	} {
+		if c == nil {
+			continue
+		}
+		if policies[endpoint], err = newPolicy(store, c); err != nil {
+			return nil, errors.Wrap(err, string(endpoint))
+		}
+	}
+	return policies, nil
+}
+
+func newPolicy(store throttled.GCRAStore, c *schema.ApiRateLimitPolicy) (*policy, error) {
+	p := &policy{
+		hourly: map[SubjectKind]limiter{},
+		daily:  map[SubjectKind]limiter{},
+	}
+
+	hourly := map[SubjectKind]int{
+		SubjectUser:  c.PerUser,
+		SubjectToken: withDefault(c.PerToken, c.PerUser),
+		SubjectIP:    c.PerIP,
+	}
+	for kind, limit := range hourly {
+		if limit <= 0 {
+			continue
+		}
+		l, err := newHourlyLimiter(store, limit)
+		if err != nil {
+			return nil, err
+		}
+		p.hourly[kind] = l
+	}
+
+	if q := c.DailyQuota; q != nil {
+		daily := map[SubjectKind]int{
+			SubjectUser:  q.PerUser,
+			SubjectToken: withDefault(q.PerToken, q.PerUser),
+			SubjectIP:    q.PerIP,
+		}
+		for kind, quota := range daily {
+			if quota <= 0 {
+				continue
+			}
+			// The whole quota can be used at once, after which it is replenished
+			// continuously over the day.
+			l, err := throttled.NewGCRARateLimiter(store, throttled.RateQuota{
+				MaxRate:  throttled.PerDay(quota),
+				MaxBurst: quota - 1,
+			})
+			if err != nil {
+				return nil, err
+			}
+			p.daily[kind] = l
+		}
+	}
+
+	return p, nil
+}
+
+func newOverrides(store throttled.GCRAStore, overrides []*schema.Overrides) (map[string]limiter, error) {
+	m := make(map[string]limiter, len(overrides))
+	for _, o := range overrides {
+		switch l := o.Limit.(type) {
+		case string:
+			switch l {
+			case "blocked":
+				m[o.Key] = blockedLimiter{}
+			case "unlimited":
+				m[o.Key] = nil
+			default:
+				return nil, errors.Errorf("unknown limit %q for key %q", l, o.Key)
+			}
+		case int, float64:
+			// Numbers are decoded as float64 from the JSON configuration.
+			limit := toInt(l)
+			if limit <= 0 {
+				return nil, errors.Errorf("invalid limit %d for key %q", limit, o.Key)
+			}
+			rl, err := newHourlyLimiter(store, limit)
+			if err != nil {
+				return nil, errors.Wrapf(err, "key %q", o.Key)
+			}
+			m[o.Key] = rl
+		default:
+			return nil, errors.Errorf("invalid limit %v for key %q", l, o.Key)
+		}
+	}
+	return m, nil
+}
+
+func newHourlyLimiter(store throttled.GCRAStore, limit int) (*throttled.GCRARateLimiter, error) {
+	return throttled.NewGCRARateLimiter(store, throttled.RateQuota{
+		MaxRate:  throttled.PerHour(limit),
+		MaxBurst: int(float64(limit) * maxBurstPercentage),
+	})
+}
+
+// check checks the hourly limits and daily quotas of the subject. The request is only charged to
+// the limiters once all of them allow it, so that a request rejected by one limit doesn't use
+// up the others. Access tokens are also charged to the limits of their user, so that a user
+// can't multiply their limits by creating more tokens.
+//
+// Requests that cost more than the burst of a limiter are charged the whole burst instead, so
+// that they are allowed once the limit is fully replenished.
+func (p *policy) check(endpoint Endpoint, s Subject, cost int) (Result, error) {
+	type keyedLimiter struct {
+		key string
+		limiter
+		cost int
+	}
+
+	subjects := []Subject{s}
+	if s.Kind == SubjectToken {
+		subjects = append(subjects, Subject{Kind: SubjectUser, ID: strconv.Itoa(int(s.UserID)), UserID: s.UserID})
+	}
+
+	var limiters []keyedLimiter
+	for _, s := range subjects {
+		hourly, ok := p.hourlyLimiter(s)
+		if !ok {
+			// The subject is unlimited.
+			return Result{}, nil
+		}
+		if hourly != nil {
+			limiters = append(limiters, keyedLimiter{key: string(endpoint) + ":hour:" + s.String(), limiter: hourly})
+		}
+		if daily := p.daily[s.Kind]; daily != nil {
+			limiters = append(limiters, keyedLimiter{key: string(endpoint) + ":day:" + s.String(), limiter: daily})
+		}
+	}
+	if len(limiters) == 0 {
+		return Result{}, nil
+	}
+
+	// Peek at the remaining limits without charging the request.
+	for i, l := range limiters {
+		limited, r, err := l.RateLimit(l.key, 0)
+		if err != nil {
+			return Result{}, err
+		}
+		l.cost = cost
+		if l.cost > r.Limit {
+			l.cost = r.Limit
+		}
+		limiters[i].cost = l.cost
+		if !limited && r.Remaining >= l.cost {
+			continue
+		}
+		// The request exceeds this limit. A limited request doesn't change the state of the
+		// limiter, so this only computes when the request can be retried.
+		if limited, r, err = l.RateLimit(l.key, l.cost); err != nil {
+			return Result{}, err
+		}
+		if limited {
+			return Result{Enforced: true, Limited: true, RateLimitResult: r}, nil
+		}
+		// The limit was replenished in the meantime and the request was charged, so it must
+		// not be charged again below.
+		limiters[i].limiter = chargedLimiter{r}
+	}
+
+	res := Result{Enforced: true}
+	for i, l := range limiters {
+		limited, r, err := l.RateLimit(l.key, l.cost)
+		if err != nil {
+			return Result{}, err
+		}
+		if i == 0 || limited || r.Remaining < res.Remaining {
+			res.RateLimitResult = r
+		}
+		if limited {
+			// Concurrent requests used up the limit since we peeked at it.
+			res.Limited = true
+			break
+		}
+	}
+	return res, nil
+}
+
+// hourlyLimiter returns the hourly limiter of the subject, taking overrides into account. It
+// returns false if the subject is unlimited. Access tokens are overridden by the ID of their
+// user.
+func (p *policy) hourlyLimiter(s Subject) (limiter, bool) {
+	key := s.ID
+	if s.Kind == SubjectToken {
+		key = strconv.Itoa(int(s.UserID))
+	}
+	o, ok := p.overrides[key]
+	switch {
+	case !ok:
+		return p.hourly[s.Kind], true
+	case o == nil:
+		return nil, false
+	default:
+		return o, true
+	}
+}
+
+// blockedLimiter limits every request.
+type blockedLimiter struct{}
+
+func (blockedLimiter) RateLimit(string, int) (bool, throttled.RateLimitResult, error) {
+	return true, throttled.RateLimitResult{Limit: 0, Remaining: 0}, nil
+}
+
+// chargedLimiter returns the result of a limiter that was already charged for the request.
+type chargedLimiter struct {
+	throttled.RateLimitResult
+}
+
+func (l chargedLimiter) RateLimit(string, int) (bool, throttled.RateLimitResult, error) {
+	return false, l.RateLimitResult, nil
+}
+
+func withDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
+
+func toInt(v interface{}) int {
+	switch v := v.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
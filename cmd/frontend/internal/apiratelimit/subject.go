@@ -0,0 +1,76 @@
+package apiratelimit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/clientip"
+)
+
+// SubjectKind is the kind of consumer of the API that limits are applied to.
+type SubjectKind string
+
+const (
+	// SubjectUser is a user authenticated with a session cookie or another authentication
+	// provider.
+	SubjectUser SubjectKind = "user"
+	// SubjectToken is an access token. It is limited separately from its user.
+	SubjectToken SubjectKind = "token"
+	// SubjectIP is the IP address of an anonymous user.
+	SubjectIP SubjectKind = "ip"
+)
+
+// Subject is a consumer of the API.
+type Subject struct {
+	Kind SubjectKind
+	// ID is the ID of the user or access token, or the IP address.
+	ID string
+	// UserID is the ID of the user, or of the owner of the access token. It is 0 for anonymous
+	// users.
+	UserID int32
+}
+
+// String returns the key the subject is tracked with, such as "user:42".
+func (s Subject) String() string { return string(s.Kind) + ":" + s.ID }
+
+// parseSubject parses the key of a subject returned by String. The user ID of tokens is not
+// part of the key, so it is not set.
+func parseSubject(key string) (s Subject, ok bool) {
+	i := strings.Index(key, ":")
+	if i < 0 {
+		return Subject{}, false
+	}
+	s.Kind, s.ID = SubjectKind(key[:i]), key[i+1:]
+	switch s.Kind {
+	case SubjectUser:
+		id, err := strconv.ParseInt(s.ID, 10, 32)
+		if err != nil {
+			return Subject{}, false
+		}
+		s.UserID = int32(id)
+	case SubjectToken, SubjectIP:
+	default:
+		return Subject{}, false
+	}
+	return s, true
+}
+
+// SubjectFromRequest returns the subject the limits of r apply to. It returns false if no
+// limits apply to the request, which is the case for internal actors.
+//
+// It must be called after the actor is set on the request context.
+func SubjectFromRequest(r *http.Request) (Subject, bool) {
+	a := actor.FromContext(r.Context())
+	switch {
+	case a.Internal:
+		return Subject{}, false
+	case a.AccessTokenID != 0:
+		return Subject{Kind: SubjectToken, ID: strconv.FormatInt(a.AccessTokenID, 10), UserID: a.UID}, true
+	case a.IsAuthenticated():
+		return Subject{Kind: SubjectUser, ID: a.UIDString(), UserID: a.UID}, true
+	default:
+		return Subject{Kind: SubjectIP, ID: clientip.FromRequest(r)}, true
+	}
+}
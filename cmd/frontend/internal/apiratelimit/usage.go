@@ -0,0 +1,117 @@
+package apiratelimit
+
+import (
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// Consumer is the usage of an endpoint by a subject on a day.
+type Consumer struct {
+	Subject Subject
+	// Requests is the total cost of the requests made by the subject, including the ones that
+	// were limited.
+	Requests int
+	// Limited is the number of requests that were rejected because they exceeded a limit.
+	Limited int
+}
+
+// A UsageStore records the usage of the API by each subject.
+type UsageStore interface {
+	// Record adds a request with the given cost by the subject to the usage of the endpoint.
+	Record(endpoint Endpoint, s Subject, cost int, limited bool) error
+	// TopConsumers returns the n subjects that used the endpoint the most on the day of the
+	// given time (in UTC), by decreasing usage.
+	TopConsumers(endpoint Endpoint, day time.Time, n int) ([]*Consumer, error)
+}
+
+// usageRetention is how long the usage of a day is kept.
+const usageRetention = 8 * 24 * time.Hour
+
+// RedisUsageStore is a UsageStore that keeps the usage of each endpoint and day in Redis sorted
+// sets, scored by total cost.
+type RedisUsageStore struct {
+	pool *redis.Pool
+}
+
+// NewRedisUsageStore returns a UsageStore backed by the given Redis pool.
+func NewRedisUsageStore(pool *redis.Pool) *RedisUsageStore {
+	return &RedisUsageStore{pool: pool}
+}
+
+func usageKeys(endpoint Endpoint, day time.Time) (requests, limited string) {
+	date := day.UTC().Format("2006-01-02")
+	return "api:usage:" + string(endpoint) + ":" + date, "api:limited:" + string(endpoint) + ":" + date
+}
+
+func (s *RedisUsageStore) Record(endpoint Endpoint, subject Subject, cost int, limited bool) error {
+	c := s.pool.Get()
+	defer c.Close()
+
+	requestsKey, limitedKey := usageKeys(endpoint, time.Now())
+	ttl := int(usageRetention.Seconds())
+
+	if err := c.Send("MULTI"); err != nil {
+		return err
+	}
+	if err := c.Send("ZINCRBY", requestsKey, cost, subject.String()); err != nil {
+		return err
+	}
+	if err := c.Send("EXPIRE", requestsKey, ttl); err != nil {
+		return err
+	}
+	if limited {
+		if err := c.Send("ZINCRBY", limitedKey, 1, subject.String()); err != nil {
+			return err
+		}
+		if err := c.Send("EXPIRE", limitedKey, ttl); err != nil {
+			return err
+		}
+	}
+	_, err := c.Do("EXEC")
+	return err
+}
+
+func (s *RedisUsageStore) TopConsumers(endpoint Endpoint, day time.Time, n int) ([]*Consumer, error) {
+	c := s.pool.Get()
+	defer c.Close()
+
+	requestsKey, limitedKey := usageKeys(endpoint, day)
+	values, err := redis.Values(c.Do("ZREVRANGE", requestsKey, 0, n-1, "WITHSCORES"))
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	consumers := make([]*Consumer, 0, len(values)/2)
+	for len(values) > 0 {
+		var key string
+		var requests int
+		if values, err = redis.Scan(values, &key, &requests); err != nil {
+			return nil, err
+		}
+		subject, ok := parseSubject(key)
+		if !ok {
+			continue
+		}
+		keys = append(keys, key)
+		consumers = append(consumers, &Consumer{Subject: subject, Requests: requests})
+	}
+
+	for _, key := range keys {
+		if err := c.Send("ZSCORE", limitedKey, key); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Flush(); err != nil {
+		return nil, err
+	}
+	for _, consumer := range consumers {
+		limited, err := redis.Int(c.Receive())
+		if err != nil && err != redis.ErrNil {
+			return nil, err
+		}
+		consumer.Limited = limited
+	}
+	return consumers, nil
+}
@@ -252,6 +252,11 @@ func HandleSignIn(db dbutil.DB) func(w http.ResponseWriter, r *http.Request) {
 			httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
 			return
 		}
+		// 🚨 SECURITY: Deactivated users must not be able to sign in, see auth.ErrUserDeactivated.
+		if usr.DeactivatedAt != nil {
+			httpLogAndError(w, "Authentication failed", http.StatusUnauthorized, "err", "user is deactivated")
+			return
+		}
 
 		actor.UID = usr.ID
 
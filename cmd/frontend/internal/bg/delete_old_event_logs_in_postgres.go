@@ -6,6 +6,8 @@ import (
 
 	"github.com/inconshreveable/log15"
 
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 )
 
@@ -38,3 +40,20 @@ func DeleteOldSecurityEventLogsInPostgres(ctx context.Context, db dbutil.DB) {
 		time.Sleep(time.Hour)
 	}
 }
+
+// defaultAuditLogRetentionDays is the default of the auditLog.retentionDays site configuration
+// property.
+const defaultAuditLogRetentionDays = 90
+
+func DeleteOldAuditLogsInPostgres(ctx context.Context, db dbutil.DB) {
+	for {
+		days := defaultAuditLogRetentionDays
+		if c := conf.Get().AuditLog; c != nil && c.RetentionDays > 0 {
+			days = c.RetentionDays
+		}
+		if err := database.AuditLogs(db).DeleteOlderThan(ctx, time.Now().AddDate(0, 0, -days)); err != nil {
+			log15.Error("deleting expired rows from audit_log table", "error", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
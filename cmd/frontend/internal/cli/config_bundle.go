@@ -0,0 +1,307 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confbundle"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/database/locker"
+	"github.com/sourcegraph/sourcegraph/internal/encryption/keyring"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+var (
+	configBundleDir           = env.Get("CONFIG_BUNDLE_DIR", "", "Directory of a declarative configuration bundle with the site configuration, external services, settings and search contexts.")
+	configBundleMode          = env.Get("CONFIG_BUNDLE_MODE", "apply", "Either apply (apply CONFIG_BUNDLE_DIR at startup and when it changes) or plan (only report drift between CONFIG_BUNDLE_DIR and the database).")
+	configBundleCheckInterval = env.Get("CONFIG_BUNDLE_CHECK_INTERVAL", "5m", "How often to check for drift between CONFIG_BUNDLE_DIR and the database.")
+)
+
+var metricConfigBundleDrift = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "src_frontend_config_bundle_drift",
+	Help: "The number of changes needed to make the database match the configuration bundle.",
+})
+
+func checkConfigBundleEnv() error {
+	if configBundleDir == "" {
+		return nil
+	}
+	if configBundleMode != "apply" && configBundleMode != "plan" {
+		return errors.Errorf("invalid CONFIG_BUNDLE_MODE %q (must be apply or plan)", configBundleMode)
+	}
+	for _, name := range []string{"SITE_CONFIG_FILE", "GLOBAL_SETTINGS_FILE", "EXTSVC_CONFIG_FILE"} {
+		if os.Getenv(name) != "" {
+			return errors.Errorf("CONFIG_BUNDLE_DIR and %s can't be used together", name)
+		}
+	}
+	return nil
+}
+
+// applyConfigBundleSiteConfig applies the site configuration of the configuration bundle. It must
+// be called before the configuration server is initialized, like overrideSiteConfig, and before
+// the rest of the bundle is applied by watchConfigBundle.
+func applyConfigBundleSiteConfig(ctx context.Context, db dbutil.DB) error {
+	if configBundleDir == "" || configBundleMode != "apply" {
+		return nil
+	}
+	b, err := confbundle.Load(configBundleDir)
+	if err != nil {
+		return err
+	}
+	return withConfigBundleLock(ctx, db, func() error {
+		changes, err := confbundle.Diff(ctx, db, &confbundle.Bundle{Site: b.Site})
+		if err != nil {
+			return err
+		}
+		return confbundle.Apply(ctx, changes)
+	})
+}
+
+// withConfigBundleLock calls f while holding a Postgres advisory lock, so that the frontend
+// replicas don't apply the configuration bundle at the same time. A replica that waited for the
+// lock diffs the bundle again and finds that another replica has already applied it.
+func withConfigBundleLock(ctx context.Context, db dbutil.DB, f func() error) (err error) {
+	_, unlock, err := locker.NewWithDB(db, "config_bundle").Lock(ctx, 0, true)
+	if err != nil {
+		return errors.Wrap(err, "locking configuration bundle")
+	}
+	defer func() { err = unlock(err) }()
+	return f()
+}
+
+// watchConfigBundle applies the configuration bundle (unless CONFIG_BUNDLE_MODE is plan) and then
+// applies it again whenever its files change. Drift between the bundle and the database, such as
+// from changes made in the web UI, is checked periodically and reported to site admins.
+func watchConfigBundle(ctx context.Context, db dbutil.DB) error {
+	if configBundleDir == "" {
+		return nil
+	}
+	interval, err := time.ParseDuration(configBundleCheckInterval)
+	if err != nil {
+		return errors.Wrap(err, "invalid CONFIG_BUNDLE_CHECK_INTERVAL")
+	}
+
+	w := &configBundleWatcher{db: db, dir: configBundleDir, apply: configBundleMode == "apply"}
+	if err := w.check(ctx, w.apply); err != nil {
+		return err
+	}
+	graphqlbackend.AlertFuncs = append(graphqlbackend.AlertFuncs, w.alerts)
+
+	go w.watch(ctx, interval)
+	return nil
+}
+
+type configBundleWatcher struct {
+	db    dbutil.DB
+	dir   string
+	apply bool
+
+	mu    sync.Mutex
+	drift []*confbundle.Change // the changes that were not applied at the last check
+	err   error                // the error of the last check
+}
+
+func (w *configBundleWatcher) watch(ctx context.Context, interval time.Duration) {
+	log := log15.Root().New("svc", "config.bundle")
+	events, err := watchPaths(ctx, confbundle.Dirs(w.dir)...)
+	if err != nil {
+		log.Error("failed to watch configuration bundle", "error", err)
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		apply := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case err, ok := <-events:
+			if !ok {
+				return
+			}
+			if err != nil {
+				log.Warn("error while watching configuration bundle", "error", err)
+				metricConfigOverrideUpdates.WithLabelValues("watch_failed").Inc()
+				continue
+			}
+			apply = w.apply
+		}
+
+		if err := w.check(ctx, apply); err != nil {
+			log.Error("failed to check configuration bundle", "error", err, "dir", w.dir)
+			if apply {
+				metricConfigOverrideUpdates.WithLabelValues("update_failed").Inc()
+			}
+		} else if apply {
+			metricConfigOverrideUpdates.WithLabelValues("success").Inc()
+		}
+	}
+}
+
+// check compares the configuration bundle with the database, and applies the changes if apply is
+// true. The changes that weren't applied are reported as drift.
+func (w *configBundleWatcher) check(ctx context.Context, apply bool) (err error) {
+	log := log15.Root().New("svc", "config.bundle")
+
+	var changes []*confbundle.Change
+	defer func() {
+		w.mu.Lock()
+		w.drift, w.err = changes, err
+		w.mu.Unlock()
+		metricConfigBundleDrift.Set(float64(len(changes)))
+	}()
+
+	b, err := confbundle.Load(w.dir)
+	if err != nil {
+		return err
+	}
+
+	if !apply {
+		changes, err = confbundle.Diff(ctx, w.db, b)
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			log.Warn("Database has drifted from configuration bundle.", "change", c.String())
+		}
+		return nil
+	}
+
+	return withConfigBundleLock(ctx, w.db, func() error {
+		changes, err = confbundle.Diff(ctx, w.db, b)
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			log.Info("Applying configuration bundle.", "change", c.String())
+		}
+		if err := confbundle.Apply(ctx, changes); err != nil {
+			return err
+		}
+		changes = nil
+		return nil
+	})
+}
+
+func (w *configBundleWatcher) alerts(args graphqlbackend.AlertFuncArgs) []*graphqlbackend.Alert {
+	// 🚨 SECURITY: Only site admins can act on the configuration bundle, and the errors may
+	// contain sensitive information.
+	if !args.IsSiteAdmin {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return []*graphqlbackend.Alert{{
+			TypeValue:    graphqlbackend.AlertTypeError,
+			MessageValue: "Failed to check the configuration bundle (CONFIG_BUNDLE_DIR): " + w.err.Error(),
+		}}
+	}
+	if len(w.drift) == 0 {
+		return nil
+	}
+	l := make([]string, 0, len(w.drift))
+	for _, c := range w.drift {
+		l = append(l, c.String())
+	}
+	return []*graphqlbackend.Alert{{
+		TypeValue:    graphqlbackend.AlertTypeWarning,
+		MessageValue: "The configuration has drifted from the configuration bundle (CONFIG_BUNDLE_DIR). Applying the bundle would:\n* " + strings.Join(l, "\n* "),
+	}}
+}
+
+// errConfigBundleChanges is returned by planConfigBundle if applying the bundle would change the
+// database.
+var errConfigBundleChanges = &exitCodeError{error: errors.New("the database doesn't match the configuration bundle"), code: 2}
+
+// exitCodeError is an error that makes the frontend exit with a specific status.
+type exitCodeError struct {
+	error
+	code int
+}
+
+func (e *exitCodeError) ExitCode() int { return e.code }
+
+// planConfigBundle implements the plan-config-bundle command, which prints the changes that
+// applying the configuration bundle in dir (or CONFIG_BUNDLE_DIR) would make, without making them.
+// It returns errConfigBundleChanges if there are changes, so that the frontend exits with status 2
+// for use in CI.
+//
+// The command connects to the database with a read-only connection, doesn't run migrations, and
+// doesn't save the default site configuration if there is none yet.
+func planConfigBundle(ctx context.Context, args []string) error {
+	dir := configBundleDir
+	if len(args) > 0 {
+		dir = args[0]
+	}
+	if dir == "" {
+		return errors.New("usage: frontend plan-config-bundle [dir] (or set CONFIG_BUNDLE_DIR)")
+	}
+
+	if err := dbconn.SetupGlobalConnection(dbconn.Opts{DBName: "frontend", AppName: "frontend-plan-config-bundle", ReadOnly: true}); err != nil {
+		return errors.Wrap(err, "connecting to frontend database")
+	}
+	db := dbconn.Global
+
+	// External service configurations may be encrypted, so the keyring is needed to compare them,
+	// and it depends on the site configuration.
+	globals.ConfigurationServerFrontendOnly = conf.InitConfigurationServerFrontendOnly(readOnlyConfigurationSource{})
+	if err := keyring.Init(ctx); err != nil {
+		return errors.Wrap(err, "initializing encryption keyring")
+	}
+
+	b, err := confbundle.Load(dir)
+	if err != nil {
+		return err
+	}
+	changes, err := confbundle.Diff(ctx, db, b)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Println("No changes. The database matches the configuration bundle.")
+		return nil
+	}
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	fmt.Printf("\n%d changes.\n", len(changes))
+	return errConfigBundleChanges
+}
+
+// readOnlyConfigurationSource is the configuration source of plan-config-bundle. It reads the site
+// configuration without saving the default one, and refuses writes.
+type readOnlyConfigurationSource struct{}
+
+func (readOnlyConfigurationSource) Read(ctx context.Context) (conftypes.RawUnified, error) {
+	site, err := confdb.SiteGetLatestOrDefault(ctx)
+	if err != nil {
+		return conftypes.RawUnified{}, errors.Wrap(err, "confdb.SiteGetLatestOrDefault")
+	}
+	return conftypes.RawUnified{
+		Site:               site.Contents,
+		ServiceConnections: serviceConnections(),
+	}, nil
+}
+
+func (readOnlyConfigurationSource) Write(ctx context.Context, input conftypes.RawUnified) error {
+	return errors.New("the site configuration can't be changed by plan-config-bundle")
+}
@@ -24,12 +24,14 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/apiratelimit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/ui"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/app/updatecheck"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/bg"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cli/loghandlers"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/siteid"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/vfsutil"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbconn"
@@ -46,6 +48,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/sysreq"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/tracer"
+	"github.com/sourcegraph/sourcegraph/internal/txemail"
 	"github.com/sourcegraph/sourcegraph/internal/version"
 )
 
@@ -133,6 +136,12 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 	log.SetFlags(0)
 	log.SetPrefix("")
 
+	// The plan-config-bundle command only reads the database, so it must not run migrations or
+	// start any part of the server.
+	if len(os.Args) >= 2 && os.Args[1] == "plan-config-bundle" {
+		return planConfigBundle(ctx, os.Args[2:])
+	}
+
 	if err := profiler.Init(); err != nil {
 		log.Fatalf("failed to initialize profiling: %v", err)
 	}
@@ -147,10 +156,17 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 
 	ui.InitRouter(db)
 
+	if err := checkConfigBundleEnv(); err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+
 	// override site config first
 	if err := overrideSiteConfig(ctx); err != nil {
 		log.Fatalf("failed to apply site config overrides: %v", err)
 	}
+	if err := applyConfigBundleSiteConfig(ctx, db); err != nil {
+		log.Fatalf("failed to apply site config from configuration bundle: %v", err)
+	}
 	globals.ConfigurationServerFrontendOnly = conf.InitConfigurationServerFrontendOnly(&configurationSource{})
 	conf.MustValidateDefaults()
 
@@ -168,6 +184,9 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 	if err := overrideExtSvcConfig(ctx, db); err != nil {
 		log.Fatalf("failed to override external service config: %v", err)
 	}
+	if err := watchConfigBundle(ctx, db); err != nil {
+		log.Fatalf("failed to apply configuration bundle: %v", err)
+	}
 
 	// Filter trace logs
 	d, _ := time.ParseDuration(traceThreshold)
@@ -247,11 +266,13 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 
 	globals.WatchExternalURL(defaultExternalURL(nginxAddr, httpAddr))
 	globals.WatchPermissionsUserMapping()
+	audit.Watch()
 
 	goroutine.Go(func() { bg.CheckRedisCacheEvictionPolicy() })
 	goroutine.Go(func() { bg.DeleteOldCacheDataInRedis() })
 	goroutine.Go(func() { bg.DeleteOldEventLogsInPostgres(context.Background(), db) })
 	goroutine.Go(func() { bg.DeleteOldSecurityEventLogsInPostgres(context.Background(), db) })
+	goroutine.Go(func() { bg.DeleteOldAuditLogsInPostgres(context.Background(), db) })
 	goroutine.Go(func() { updatecheck.Start(db) })
 
 	// Parse GraphQL schema and set up resolvers that depend on dbconn.Global
@@ -270,6 +291,14 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 		return err
 	}
 
+	if err := apiratelimit.Init(); err != nil {
+		return err
+	}
+
+	// Transactional emails are queued in the outbox, which must be set up before the
+	// servers start.
+	emailOutboxRoutines := txemail.InitOutbox(context.Background(), db)
+
 	server, err := makeExternalAPI(db, schema, enterprise, rateLimitWatcher)
 	if err != nil {
 		return err
@@ -284,6 +313,7 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 		server,
 		outOfBandMigrationRunner,
 	}
+	routines = append(routines, emailOutboxRoutines...)
 	if internalAPI != nil {
 		routines = append(routines, internalAPI)
 	}
@@ -302,7 +332,7 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 
 func makeExternalAPI(db dbutil.DB, schema *graphql.Schema, enterprise enterprise.Services, rateLimiter graphqlbackend.LimitWatcher) (goroutine.BackgroundRoutine, error) {
 	// Create the external HTTP handler.
-	externalHandler, err := newExternalHTTPHandler(db, schema, enterprise.GitHubWebhook, enterprise.GitLabWebhook, enterprise.BitbucketServerWebhook, enterprise.NewCodeIntelUploadHandler, enterprise.NewExecutorProxyHandler, rateLimiter)
+	externalHandler, err := newExternalHTTPHandler(db, schema, enterprise.GitHubWebhook, enterprise.GitLabWebhook, enterprise.BitbucketServerWebhook, enterprise.NewCodeIntelUploadHandler, enterprise.CodeIntelExportHandler, enterprise.SearchJobsHandler, enterprise.NewExecutorProxyHandler, rateLimiter)
 	if err != nil {
 		return nil, err
 	}
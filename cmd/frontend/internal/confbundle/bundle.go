@@ -0,0 +1,289 @@
+// Package confbundle implements declarative configuration of a Sourcegraph instance from a
+// directory of JSON and YAML files (a "bundle"), and detects drift between a bundle and the
+// database.
+package confbundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/ghodss/yaml"
+
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+)
+
+// The paths of the parts of a bundle, relative to the bundle directory. The site configuration and
+// settings files may have any of the extensions in fileExtensions.
+const (
+	siteFile            = "site"
+	globalSettingsFile  = "settings/global"
+	orgSettingsDir      = "settings/orgs"
+	externalServicesDir = "external-services"
+	searchContextsDir   = "search-contexts"
+)
+
+var fileExtensions = []string{".json", ".jsonc", ".yaml", ".yml"}
+
+// Bundle is the configuration of a Sourcegraph instance described by a bundle. A nil field means
+// that the bundle doesn't manage that part of the configuration, which is then left as it is.
+type Bundle struct {
+	// Site is the site configuration JSON.
+	Site *string
+	// GlobalSettings is the global settings JSON.
+	GlobalSettings *string
+	// OrgSettings is the settings JSON of organizations, by organization name. The settings of
+	// organizations that aren't in the map are left as they are.
+	OrgSettings map[string]string
+	// ExternalServices are the site-level external services. Site-level external services that
+	// aren't in the bundle are deleted.
+	ExternalServices []*ExternalService
+	// SearchContexts are the instance-level search contexts. Instance-level search contexts that
+	// aren't in the bundle are deleted.
+	SearchContexts []*SearchContext
+}
+
+// ExternalService is an external service in a bundle.
+type ExternalService struct {
+	// Kind is the kind of the external service, such as "GITHUB".
+	Kind string `json:"kind"`
+	// DisplayName identifies the external service, so it must be unique in the bundle.
+	DisplayName string `json:"displayName"`
+	// Config is the configuration JSON of the external service.
+	Config json.RawMessage `json:"config"`
+}
+
+// SearchContext is an instance-level search context in a bundle.
+type SearchContext struct {
+	Name         string                     `json:"name"`
+	Description  string                     `json:"description"`
+	Public       bool                       `json:"public"`
+	Repositories []*SearchContextRepository `json:"repositories"`
+}
+
+// SearchContextRepository is a repository in a search context, and the revisions of it to search.
+type SearchContextRepository struct {
+	Repository string   `json:"repository"`
+	Revisions  []string `json:"revisions"`
+}
+
+// Load reads the bundle in the directory dir, which has these files, all of them optional:
+//
+//	site.json                        the site configuration
+//	settings/global.json             the global settings
+//	settings/orgs/<org name>.json    the settings of an organization
+//	external-services/*.json         one external service per file
+//	search-contexts/*.json           one instance-level search context per file
+//
+// Each file may be JSON (with comments and trailing commas allowed) or YAML, with a .yaml or .yml
+// extension. The directories of external services and search contexts make the bundle manage all
+// of them, so an empty directory deletes all of them.
+func Load(dir string) (*Bundle, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, errors.Wrap(err, "reading configuration bundle")
+	}
+
+	var b Bundle
+	var err error
+	if b.Site, err = readOptionalDocument(dir, siteFile); err != nil {
+		return nil, err
+	}
+	if b.GlobalSettings, err = readOptionalDocument(dir, globalSettingsFile); err != nil {
+		return nil, err
+	}
+
+	orgFiles, err := listDocuments(filepath.Join(dir, orgSettingsDir))
+	if err != nil {
+		return nil, err
+	}
+	if orgFiles != nil {
+		b.OrgSettings = make(map[string]string, len(orgFiles))
+	}
+	for _, path := range orgFiles {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if _, ok := b.OrgSettings[name]; ok {
+			return nil, errors.Errorf("%s: duplicate settings for organization %q", path, name)
+		}
+		contents, err := readDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		b.OrgSettings[name] = contents
+	}
+
+	extsvcFiles, err := listDocuments(filepath.Join(dir, externalServicesDir))
+	if err != nil {
+		return nil, err
+	}
+	if extsvcFiles != nil {
+		b.ExternalServices = make([]*ExternalService, 0, len(extsvcFiles))
+	}
+	seen := map[string]string{}
+	for _, path := range extsvcFiles {
+		var svc ExternalService
+		if err := unmarshalDocument(path, &svc); err != nil {
+			return nil, err
+		}
+		switch {
+		case svc.Kind == "":
+			return nil, errors.Errorf("%s: external service must have a kind", path)
+		case svc.DisplayName == "":
+			return nil, errors.Errorf("%s: external service must have a displayName", path)
+		case len(svc.Config) == 0:
+			return nil, errors.Errorf("%s: external service must have a config", path)
+		case seen[svc.DisplayName] != "":
+			return nil, errors.Errorf("%s: external service %q is already defined in %s", path, svc.DisplayName, seen[svc.DisplayName])
+		}
+		seen[svc.DisplayName] = path
+		svc.Kind = strings.ToUpper(svc.Kind)
+		b.ExternalServices = append(b.ExternalServices, &svc)
+	}
+
+	searchContextFiles, err := listDocuments(filepath.Join(dir, searchContextsDir))
+	if err != nil {
+		return nil, err
+	}
+	if searchContextFiles != nil {
+		b.SearchContexts = make([]*SearchContext, 0, len(searchContextFiles))
+	}
+	seen = map[string]string{}
+	for _, path := range searchContextFiles {
+		var sc SearchContext
+		if err := unmarshalDocument(path, &sc); err != nil {
+			return nil, err
+		}
+		switch {
+		case sc.Name == "":
+			return nil, errors.Errorf("%s: search context must have a name", path)
+		case seen[sc.Name] != "":
+			return nil, errors.Errorf("%s: search context %q is already defined in %s", path, sc.Name, seen[sc.Name])
+		}
+		for _, r := range sc.Repositories {
+			if r.Repository == "" {
+				return nil, errors.Errorf("%s: search context repositories must have a repository", path)
+			}
+		}
+		seen[sc.Name] = path
+		b.SearchContexts = append(b.SearchContexts, &sc)
+	}
+
+	return &b, nil
+}
+
+// readOptionalDocument reads the JSON of the document at the path name relative to dir, with any
+// of the supported extensions. It returns nil if there is no such document.
+func readOptionalDocument(dir, name string) (*string, error) {
+	var found []string
+	for _, ext := range fileExtensions {
+		path := filepath.Join(dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			found = append(found, path)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		contents, err := readDocument(found[0])
+		if err != nil {
+			return nil, err
+		}
+		return &contents, nil
+	default:
+		return nil, errors.Errorf("only one of %s may exist", strings.Join(found, ", "))
+	}
+}
+
+// listDocuments returns the paths of the documents in dir, sorted. It returns nil if dir doesn't
+// exist, and a non-nil empty slice if it's empty.
+func listDocuments(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	paths := []string{}
+	for _, e := range entries {
+		// Skip hidden files, which include the ..data symlinks of Kubernetes ConfigMap volumes.
+		if strings.HasPrefix(e.Name(), ".") || !isDocument(e.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		// Follow symlinks, which ConfigMap volumes are made of.
+		if fi, err := os.Stat(path); err != nil {
+			return nil, err
+		} else if fi.IsDir() {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func isDocument(name string) bool {
+	ext := filepath.Ext(name)
+	for _, e := range fileExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// readDocument returns the JSON of the document at path. JSON documents are returned as they are,
+// to keep their comments and formatting. YAML documents are converted to JSON.
+func readDocument(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		data, err = yaml.YAMLToJSON(data)
+		if err != nil {
+			return "", errors.Wrapf(err, "%s: invalid YAML", path)
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return "", errors.Wrapf(err, "%s: invalid YAML", path)
+		}
+		data = buf.Bytes()
+	default:
+		if _, err := jsonc.Parse(string(data)); err != nil {
+			return "", errors.Wrapf(err, "%s: invalid JSON", path)
+		}
+	}
+	return string(data), nil
+}
+
+func unmarshalDocument(path string, v interface{}) error {
+	contents, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+	if err := jsonc.Unmarshal(contents, v); err != nil {
+		return errors.Wrapf(err, "%s", path)
+	}
+	return nil
+}
+
+// Dirs returns the directories of the bundle in dir that exist, to watch for changes.
+func Dirs(dir string) []string {
+	dirs := []string{dir}
+	for _, d := range []string{filepath.Dir(globalSettingsFile), orgSettingsDir, externalServicesDir, searchContextsDir} {
+		path := filepath.Join(dir, d)
+		if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+			dirs = append(dirs, path)
+		}
+	}
+	return dirs
+}
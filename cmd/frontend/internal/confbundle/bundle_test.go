@@ -0,0 +1,141 @@
+package confbundle
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"site.json":                  `{"externalURL": "https://sourcegraph.example.com", /* comment */}`,
+		"settings/global.yaml":       "search.defaultPatternType: literal\n",
+		"settings/orgs/acme.json":    `{"motd": ["hello"]}`,
+		"external-services/gh.yaml":  "kind: github\ndisplayName: GitHub\nconfig:\n  url: https://github.com\n  token: abc\n",
+		"external-services/README":   "not a document",
+		"external-services/.hidden":  "{}",
+		"search-contexts/docs.jsonc": `{"name": "docs", "public": true, "repositories": [{"repository": "github.com/a/b", "revisions": ["main"]}]}`,
+	})
+	// Kubernetes ConfigMap volumes have hidden directories with the actual files.
+	if err := os.Mkdir(filepath.Join(dir, "..data"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site := `{"externalURL": "https://sourcegraph.example.com", /* comment */}`
+	globalSettings := "{\n  \"search.defaultPatternType\": \"literal\"\n}"
+	want := &Bundle{
+		Site:           &site,
+		GlobalSettings: &globalSettings,
+		OrgSettings:    map[string]string{"acme": `{"motd": ["hello"]}`},
+		ExternalServices: []*ExternalService{{
+			Kind:        "GITHUB",
+			DisplayName: "GitHub",
+			Config:      json.RawMessage(`{"token":"abc","url":"https://github.com"}`),
+		}},
+		SearchContexts: []*SearchContext{{
+			Name:         "docs",
+			Public:       true,
+			Repositories: []*SearchContextRepository{{Repository: "github.com/a/b", Revisions: []string{"main"}}},
+		}},
+	}
+	if diff := cmp.Diff(want, b); diff != "" {
+		t.Errorf("bundle mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoad_Unmanaged(t *testing.T) {
+	b, err := Load(writeFiles(t, map[string]string{"site.yml": "{}"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Site == nil {
+		t.Error("got nil site configuration")
+	}
+	if b.GlobalSettings != nil || b.OrgSettings != nil || b.ExternalServices != nil || b.SearchContexts != nil {
+		t.Errorf("got %+v, want only the site configuration to be managed", b)
+	}
+
+	// An empty directory manages all external services, by deleting them.
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, externalServicesDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	b, err = Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ExternalServices == nil || len(b.ExternalServices) != 0 {
+		t.Errorf("got external services %v, want an empty non-nil slice", b.ExternalServices)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := map[string]struct {
+		files   map[string]string
+		wantErr string
+	}{
+		"two site configurations": {
+			files:   map[string]string{"site.json": "{}", "site.yaml": "{}"},
+			wantErr: "only one of",
+		},
+		"invalid JSON": {
+			files:   map[string]string{"settings/global.json": "{"},
+			wantErr: "invalid JSON",
+		},
+		"invalid YAML": {
+			files:   map[string]string{"settings/orgs/acme.yaml": "a: [b"},
+			wantErr: "invalid YAML",
+		},
+		"external service without kind": {
+			files:   map[string]string{"external-services/a.json": `{"displayName": "A", "config": {}}`},
+			wantErr: "must have a kind",
+		},
+		"duplicate external service": {
+			files: map[string]string{
+				"external-services/a.json": `{"kind": "GITHUB", "displayName": "A", "config": {}}`,
+				"external-services/b.json": `{"kind": "GITLAB", "displayName": "A", "config": {}}`,
+			},
+			wantErr: "already defined",
+		},
+		"search context without name": {
+			files:   map[string]string{"search-contexts/a.json": `{"description": "a"}`},
+			wantErr: "must have a name",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeFiles(t, test.files))
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("got error %v, want it to contain %q", err, test.wantErr)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("got no error for a missing directory")
+	}
+}
@@ -0,0 +1,362 @@
+package confbundle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// Action is what a Change does.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// The kinds of configuration that a Change changes.
+const (
+	KindSiteConfig      = "site configuration"
+	KindGlobalSettings  = "global settings"
+	KindOrgSettings     = "organization settings"
+	KindExternalService = "external service"
+	KindSearchContext   = "search context"
+)
+
+// Change is a change to the database that makes it match a bundle.
+type Change struct {
+	Action Action
+	// Kind is the kind of the changed configuration (one of the Kind* constants).
+	Kind string
+	// Name identifies the changed configuration among the configuration of its kind, such as the
+	// display name of an external service. It is empty for the site configuration and global
+	// settings.
+	Name string
+
+	apply func(ctx context.Context) error
+}
+
+func (c *Change) String() string {
+	if c.Name == "" {
+		return fmt.Sprintf("%s %s", c.Action, c.Kind)
+	}
+	return fmt.Sprintf("%s %s %q", c.Action, c.Kind, c.Name)
+}
+
+// Diff returns the changes that make the database match the bundle, in the order in which they
+// must be applied. If there are no changes, the database hasn't drifted from the bundle.
+//
+// 🚨 SECURITY: The changes are applied as an internal actor, so the bundle must come from a
+// trusted source (such as a directory on the frontend's file system).
+func Diff(ctx context.Context, db dbutil.DB, b *Bundle) ([]*Change, error) {
+	return diff(actor.WithInternalActor(ctx), dbStore{db: db}, b)
+}
+
+// Apply applies the changes returned by Diff, in order. It stops at the first change that fails.
+func Apply(ctx context.Context, changes []*Change) error {
+	ctx = actor.WithInternalActor(ctx)
+	for _, c := range changes {
+		if err := c.apply(ctx); err != nil {
+			return errors.Wrapf(err, "failed to %s", c)
+		}
+	}
+	return nil
+}
+
+func diff(ctx context.Context, s store, b *Bundle) ([]*Change, error) {
+	var changes []*Change
+	for _, f := range []func(context.Context, store, *Bundle) ([]*Change, error){
+		diffSiteConfig,
+		diffSettings,
+		diffExternalServices,
+		diffSearchContexts,
+	} {
+		c, err := f(ctx, s, b)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c...)
+	}
+	return changes, nil
+}
+
+func diffSiteConfig(ctx context.Context, s store, b *Bundle) ([]*Change, error) {
+	if b.Site == nil {
+		return nil, nil
+	}
+	contents := *b.Site
+
+	// Never apply a site configuration that would break the instance. This uses conf.Validate
+	// instead of conf.ValidateSite because the site configuration of the bundle is applied before
+	// the configuration server is initialized.
+	if problems, err := conf.Validate(conftypes.RawUnified{Site: contents}); err != nil {
+		return nil, errors.Wrap(err, "validating site configuration")
+	} else if len(problems) > 0 {
+		return nil, errors.Errorf("site configuration is invalid: %s", strings.Join(problems.Messages(), ","))
+	}
+
+	current, err := s.GetSiteConfig(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting site configuration")
+	}
+	if jsonEqual(current.Contents, contents) {
+		return nil, nil
+	}
+	return []*Change{{
+		Action: ActionUpdate,
+		Kind:   KindSiteConfig,
+		apply: func(ctx context.Context) error {
+			return s.UpdateSiteConfig(ctx, current.ID, contents)
+		},
+	}}, nil
+}
+
+func diffSettings(ctx context.Context, s store, b *Bundle) ([]*Change, error) {
+	var changes []*Change
+	add := func(kind, name string, subject api.SettingsSubject, contents string) error {
+		current, err := s.GetSettings(ctx, subject)
+		if err != nil {
+			return errors.Wrapf(err, "getting %s", kind)
+		}
+		c := &Change{Action: ActionCreate, Kind: kind, Name: name}
+		var lastID *int32
+		if current != nil {
+			if jsonEqual(current.Contents, contents) {
+				return nil
+			}
+			c.Action = ActionUpdate
+			lastID = &current.ID
+		}
+		c.apply = func(ctx context.Context) error {
+			return s.UpdateSettings(ctx, subject, lastID, contents)
+		}
+		changes = append(changes, c)
+		return nil
+	}
+
+	if b.GlobalSettings != nil {
+		if err := add(KindGlobalSettings, "", api.SettingsSubject{Site: true}, *b.GlobalSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(b.OrgSettings))
+	for name := range b.OrgSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		org, err := s.GetOrgByName(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting organization %q", name)
+		}
+		orgID := org.ID
+		if err := add(KindOrgSettings, name, api.SettingsSubject{Org: &orgID}, b.OrgSettings[name]); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+func diffExternalServices(ctx context.Context, s store, b *Bundle) ([]*Change, error) {
+	if b.ExternalServices == nil {
+		return nil, nil
+	}
+	existing, err := s.ListExternalServices(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing external services")
+	}
+
+	want := make(map[string]*ExternalService, len(b.ExternalServices))
+	for _, svc := range b.ExternalServices {
+		want[svc.DisplayName] = svc
+	}
+
+	// Delete first, so that external services whose kind changed can be created again with the
+	// same display name.
+	var changes []*Change
+	matched := map[string]*types.ExternalService{}
+	for _, e := range existing {
+		w, ok := want[e.DisplayName]
+		if ok && matched[e.DisplayName] == nil && w.Kind == e.Kind {
+			matched[e.DisplayName] = e
+			continue
+		}
+		id := e.ID
+		changes = append(changes, &Change{
+			Action: ActionDelete,
+			Kind:   KindExternalService,
+			Name:   e.DisplayName,
+			apply: func(ctx context.Context) error {
+				return s.DeleteExternalService(ctx, id)
+			},
+		})
+	}
+
+	for _, w := range b.ExternalServices {
+		config, err := json.MarshalIndent(w.Config, "", "  ")
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshaling config of external service %q", w.DisplayName)
+		}
+		svc := &types.ExternalService{Kind: w.Kind, DisplayName: w.DisplayName, Config: string(config)}
+
+		e := matched[w.DisplayName]
+		switch {
+		case e == nil:
+			changes = append(changes, &Change{
+				Action: ActionCreate,
+				Kind:   KindExternalService,
+				Name:   w.DisplayName,
+				apply: func(ctx context.Context) error {
+					return s.CreateExternalService(ctx, svc)
+				},
+			})
+		case !jsonEqual(e.Config, svc.Config):
+			id := e.ID
+			changes = append(changes, &Change{
+				Action: ActionUpdate,
+				Kind:   KindExternalService,
+				Name:   w.DisplayName,
+				apply: func(ctx context.Context) error {
+					return s.UpdateExternalService(ctx, id, svc)
+				},
+			})
+		}
+	}
+	return changes, nil
+}
+
+func diffSearchContexts(ctx context.Context, s store, b *Bundle) ([]*Change, error) {
+	if b.SearchContexts == nil {
+		return nil, nil
+	}
+	existing, err := s.ListSearchContexts(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "listing search contexts")
+	}
+
+	// Search context names are case-insensitive.
+	want := make(map[string]*SearchContext, len(b.SearchContexts))
+	for _, sc := range b.SearchContexts {
+		want[strings.ToLower(sc.Name)] = sc
+	}
+
+	var changes []*Change
+	matched := map[string]*types.SearchContext{}
+	for _, e := range existing {
+		key := strings.ToLower(e.Name)
+		if _, ok := want[key]; ok && matched[key] == nil {
+			matched[key] = e
+			continue
+		}
+		id := e.ID
+		changes = append(changes, &Change{
+			Action: ActionDelete,
+			Kind:   KindSearchContext,
+			Name:   e.Name,
+			apply: func(ctx context.Context) error {
+				return s.DeleteSearchContext(ctx, id)
+			},
+		})
+	}
+
+	for _, w := range b.SearchContexts {
+		revs, err := resolveRepositoryRevisions(ctx, s, w)
+		if err != nil {
+			return nil, err
+		}
+		sc := &types.SearchContext{Name: w.Name, Description: w.Description, Public: w.Public}
+
+		e := matched[strings.ToLower(w.Name)]
+		if e == nil {
+			changes = append(changes, &Change{
+				Action: ActionCreate,
+				Kind:   KindSearchContext,
+				Name:   w.Name,
+				apply: func(ctx context.Context) error {
+					return s.CreateSearchContext(ctx, sc, revs)
+				},
+			})
+			continue
+		}
+
+		currentRevs, err := s.GetSearchContextRepositoryRevisions(ctx, e.ID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting repositories of search context %q", e.Name)
+		}
+		if e.Name == w.Name && e.Description == w.Description && e.Public == w.Public && sameRepositoryRevisions(currentRevs, revs) {
+			continue
+		}
+		sc.ID = e.ID
+		changes = append(changes, &Change{
+			Action: ActionUpdate,
+			Kind:   KindSearchContext,
+			Name:   w.Name,
+			apply: func(ctx context.Context) error {
+				return s.UpdateSearchContext(ctx, sc, revs)
+			},
+		})
+	}
+	return changes, nil
+}
+
+// resolveRepositoryRevisions looks up the repositories of the search context. Repositories without
+// revisions are searched at their default branch.
+func resolveRepositoryRevisions(ctx context.Context, s store, sc *SearchContext) ([]*types.SearchContextRepositoryRevisions, error) {
+	revs := make([]*types.SearchContextRepositoryRevisions, 0, len(sc.Repositories))
+	for _, r := range sc.Repositories {
+		repo, err := s.GetRepoByName(ctx, api.RepoName(r.Repository))
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting repository %q of search context %q", r.Repository, sc.Name)
+		}
+		revisions := r.Revisions
+		if len(revisions) == 0 {
+			revisions = []string{"HEAD"}
+		}
+		revs = append(revs, &types.SearchContextRepositoryRevisions{
+			Repo:      types.RepoName{ID: repo.ID, Name: repo.Name},
+			Revisions: revisions,
+		})
+	}
+	return revs, nil
+}
+
+func sameRepositoryRevisions(a, b []*types.SearchContextRepositoryRevisions) bool {
+	byRepo := func(revs []*types.SearchContextRepositoryRevisions) map[api.RepoID][]string {
+		m := make(map[api.RepoID][]string, len(revs))
+		for _, r := range revs {
+			m[r.Repo.ID] = append(m[r.Repo.ID], r.Revisions...)
+		}
+		for _, revisions := range m {
+			sort.Strings(revisions)
+		}
+		return m
+	}
+	return reflect.DeepEqual(byRepo(a), byRepo(b))
+}
+
+// jsonEqual reports whether a and b are the same JSON value, ignoring comments and formatting.
+func jsonEqual(a, b string) bool {
+	var av, bv interface{}
+	if err := jsonc.Unmarshal(a, &av); err != nil {
+		return false
+	}
+	if err := jsonc.Unmarshal(b, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
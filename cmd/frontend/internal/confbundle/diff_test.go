@@ -0,0 +1,291 @@
+package confbundle
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+type fakeStore struct {
+	site           *confdb.SiteConfig
+	settings       map[string]*api.Settings // "global" or org name -> settings
+	orgs           map[string]int32         // org name -> ID
+	extsvcs        []*types.ExternalService
+	searchContexts []*types.SearchContext
+	searchRevs     map[int64][]*types.SearchContextRepositoryRevisions
+	repos          map[api.RepoName]api.RepoID
+
+	nextID int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		site:       &confdb.SiteConfig{ID: 1, Contents: `{}`},
+		settings:   map[string]*api.Settings{},
+		orgs:       map[string]int32{"acme": 1},
+		searchRevs: map[int64][]*types.SearchContextRepositoryRevisions{},
+		repos:      map[api.RepoName]api.RepoID{"github.com/a/a": 1, "github.com/a/b": 2},
+		nextID:     100,
+	}
+}
+
+func (s *fakeStore) settingsKey(subject api.SettingsSubject) string {
+	if subject.Org != nil {
+		for name, id := range s.orgs {
+			if id == *subject.Org {
+				return name
+			}
+		}
+	}
+	return "global"
+}
+
+func (s *fakeStore) GetSiteConfig(ctx context.Context) (*confdb.SiteConfig, error) {
+	return s.site, nil
+}
+
+func (s *fakeStore) UpdateSiteConfig(ctx context.Context, lastID int32, contents string) error {
+	s.site = &confdb.SiteConfig{ID: lastID + 1, Contents: contents}
+	return nil
+}
+
+func (s *fakeStore) GetSettings(ctx context.Context, subject api.SettingsSubject) (*api.Settings, error) {
+	return s.settings[s.settingsKey(subject)], nil
+}
+
+func (s *fakeStore) UpdateSettings(ctx context.Context, subject api.SettingsSubject, lastID *int32, contents string) error {
+	s.settings[s.settingsKey(subject)] = &api.Settings{Subject: subject, Contents: contents}
+	return nil
+}
+
+func (s *fakeStore) GetOrgByName(ctx context.Context, name string) (*types.Org, error) {
+	id, ok := s.orgs[name]
+	if !ok {
+		return nil, &database.OrgNotFoundError{Message: name}
+	}
+	return &types.Org{ID: id, Name: name}, nil
+}
+
+func (s *fakeStore) ListExternalServices(ctx context.Context) ([]*types.ExternalService, error) {
+	return s.extsvcs, nil
+}
+
+func (s *fakeStore) CreateExternalService(ctx context.Context, svc *types.ExternalService) error {
+	s.nextID++
+	created := *svc
+	created.ID = s.nextID
+	s.extsvcs = append(s.extsvcs, &created)
+	return nil
+}
+
+func (s *fakeStore) UpdateExternalService(ctx context.Context, id int64, svc *types.ExternalService) error {
+	for _, e := range s.extsvcs {
+		if e.ID == id {
+			e.DisplayName, e.Config = svc.DisplayName, svc.Config
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) DeleteExternalService(ctx context.Context, id int64) error {
+	var kept []*types.ExternalService
+	for _, e := range s.extsvcs {
+		if e.ID != id {
+			kept = append(kept, e)
+		}
+	}
+	s.extsvcs = kept
+	return nil
+}
+
+func (s *fakeStore) ListSearchContexts(ctx context.Context) ([]*types.SearchContext, error) {
+	return s.searchContexts, nil
+}
+
+func (s *fakeStore) GetSearchContextRepositoryRevisions(ctx context.Context, id int64) ([]*types.SearchContextRepositoryRevisions, error) {
+	return s.searchRevs[id], nil
+}
+
+func (s *fakeStore) GetRepoByName(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+	id, ok := s.repos[name]
+	if !ok {
+		return nil, &database.RepoNotFoundErr{Name: name}
+	}
+	return &types.Repo{ID: id, Name: name}, nil
+}
+
+func (s *fakeStore) CreateSearchContext(ctx context.Context, sc *types.SearchContext, revs []*types.SearchContextRepositoryRevisions) error {
+	s.nextID++
+	created := *sc
+	created.ID = s.nextID
+	s.searchContexts = append(s.searchContexts, &created)
+	s.searchRevs[created.ID] = revs
+	return nil
+}
+
+func (s *fakeStore) UpdateSearchContext(ctx context.Context, sc *types.SearchContext, revs []*types.SearchContextRepositoryRevisions) error {
+	for i, e := range s.searchContexts {
+		if e.ID == sc.ID {
+			updated := *sc
+			s.searchContexts[i] = &updated
+		}
+	}
+	s.searchRevs[sc.ID] = revs
+	return nil
+}
+
+func (s *fakeStore) DeleteSearchContext(ctx context.Context, id int64) error {
+	var kept []*types.SearchContext
+	for _, e := range s.searchContexts {
+		if e.ID != id {
+			kept = append(kept, e)
+		}
+	}
+	s.searchContexts = kept
+	return nil
+}
+
+func changeStrings(changes []*Change) []string {
+	var l []string
+	for _, c := range changes {
+		l = append(l, c.String())
+	}
+	return l
+}
+
+func TestDiff(t *testing.T) {
+	ctx := context.Background()
+	s := newFakeStore()
+	s.settings["global"] = &api.Settings{ID: 1, Contents: `{"a": 1}`}
+	s.extsvcs = []*types.ExternalService{
+		{ID: 1, Kind: "GITHUB", DisplayName: "GitHub", Config: `{"url": "https://github.com", "token": "old"}`},
+		{ID: 2, Kind: "GITLAB", DisplayName: "GitLab", Config: `{"url": "https://gitlab.com"}`},
+		{ID: 3, Kind: "GITHUB", DisplayName: "Unmanaged", Config: `{}`},
+		{ID: 4, Kind: "GITHUB", DisplayName: "Renamed kind", Config: `{}`},
+	}
+	s.searchContexts = []*types.SearchContext{
+		{ID: 1, Name: "Docs", Public: true},
+		{ID: 2, Name: "old"},
+	}
+	s.searchRevs[1] = []*types.SearchContextRepositoryRevisions{{Repo: types.RepoName{ID: 1, Name: "github.com/a/a"}, Revisions: []string{"HEAD"}}}
+
+	site := `{"externalURL": "https://sourcegraph.example.com"}`
+	globalSettings := `{"a": 1, /* same value, different formatting */}`
+	b := &Bundle{
+		Site:           &site,
+		GlobalSettings: &globalSettings,
+		OrgSettings:    map[string]string{"acme": `{"b": 2}`},
+		ExternalServices: []*ExternalService{
+			{Kind: "GITHUB", DisplayName: "GitHub", Config: json.RawMessage(`{"url": "https://github.com", "token": "new"}`)},
+			{Kind: "GITLAB", DisplayName: "GitLab", Config: json.RawMessage(`{"url":"https://gitlab.com"}`)},
+			{Kind: "BITBUCKETSERVER", DisplayName: "Renamed kind", Config: json.RawMessage(`{}`)},
+			{Kind: "GITHUB", DisplayName: "New", Config: json.RawMessage(`{}`)},
+		},
+		SearchContexts: []*SearchContext{
+			{Name: "docs", Public: true, Repositories: []*SearchContextRepository{{Repository: "github.com/a/a"}, {Repository: "github.com/a/b", Revisions: []string{"main"}}}},
+			{Name: "new", Repositories: []*SearchContextRepository{{Repository: "github.com/a/b"}}},
+		},
+	}
+
+	changes, err := diff(ctx, s, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`update site configuration`,
+		`create organization settings "acme"`,
+		`delete external service "Unmanaged"`,
+		`delete external service "Renamed kind"`,
+		`update external service "GitHub"`,
+		`create external service "Renamed kind"`,
+		`create external service "New"`,
+		`delete search context "old"`,
+		`update search context "docs"`,
+		`create search context "new"`,
+	}
+	if diff := cmp.Diff(want, changeStrings(changes)); diff != "" {
+		t.Fatalf("changes mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := Apply(ctx, changes); err != nil {
+		t.Fatal(err)
+	}
+	if s.site.Contents != site {
+		t.Errorf("got site configuration %q, want %q", s.site.Contents, site)
+	}
+	if got := s.settings["acme"].Contents; got != `{"b": 2}` {
+		t.Errorf("got organization settings %q", got)
+	}
+	var gotExtsvcs []string
+	for _, e := range s.extsvcs {
+		gotExtsvcs = append(gotExtsvcs, e.Kind+" "+e.DisplayName)
+	}
+	if diff := cmp.Diff([]string{"GITHUB GitHub", "GITLAB GitLab", "BITBUCKETSERVER Renamed kind", "GITHUB New"}, gotExtsvcs); diff != "" {
+		t.Errorf("external services mismatch (-want +got):\n%s", diff)
+	}
+	if got := s.searchRevs[1]; len(got) != 2 || got[1].Repo.ID != 2 || got[1].Revisions[0] != "main" {
+		t.Errorf("got repository revisions %+v of updated search context", got)
+	}
+
+	// After applying the changes, there is no drift.
+	changes, err = diff(ctx, s, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("got changes %q after applying, want none", changeStrings(changes))
+	}
+}
+
+func TestDiff_Unmanaged(t *testing.T) {
+	s := newFakeStore()
+	s.extsvcs = []*types.ExternalService{{ID: 1, Kind: "GITHUB", DisplayName: "GitHub", Config: `{}`}}
+	s.searchContexts = []*types.SearchContext{{ID: 1, Name: "docs"}}
+
+	changes, err := diff(context.Background(), s, &Bundle{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("got changes %q for an empty bundle, want none", changeStrings(changes))
+	}
+}
+
+func TestDiff_Errors(t *testing.T) {
+	invalidSite := `{"notARealOption": true}`
+	tests := map[string]struct {
+		bundle  *Bundle
+		wantErr string
+	}{
+		"invalid site configuration": {
+			bundle:  &Bundle{Site: &invalidSite},
+			wantErr: "site configuration is invalid",
+		},
+		"nonexistent organization": {
+			bundle:  &Bundle{OrgSettings: map[string]string{"missing": "{}"}},
+			wantErr: `organization "missing"`,
+		},
+		"nonexistent repository": {
+			bundle: &Bundle{SearchContexts: []*SearchContext{
+				{Name: "docs", Repositories: []*SearchContextRepository{{Repository: "github.com/missing/missing"}}},
+			}},
+			wantErr: `repository "github.com/missing/missing"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := diff(context.Background(), newFakeStore(), test.bundle)
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("got error %v, want it to contain %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package confbundle
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/confdb"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// store is the subset of the database that bundles are compared with and applied to.
+type store interface {
+	GetSiteConfig(ctx context.Context) (*confdb.SiteConfig, error)
+	UpdateSiteConfig(ctx context.Context, lastID int32, contents string) error
+
+	GetSettings(ctx context.Context, subject api.SettingsSubject) (*api.Settings, error)
+	UpdateSettings(ctx context.Context, subject api.SettingsSubject, lastID *int32, contents string) error
+	GetOrgByName(ctx context.Context, name string) (*types.Org, error)
+
+	ListExternalServices(ctx context.Context) ([]*types.ExternalService, error)
+	CreateExternalService(ctx context.Context, svc *types.ExternalService) error
+	UpdateExternalService(ctx context.Context, id int64, svc *types.ExternalService) error
+	DeleteExternalService(ctx context.Context, id int64) error
+
+	ListSearchContexts(ctx context.Context) ([]*types.SearchContext, error)
+	GetSearchContextRepositoryRevisions(ctx context.Context, id int64) ([]*types.SearchContextRepositoryRevisions, error)
+	GetRepoByName(ctx context.Context, name api.RepoName) (*types.Repo, error)
+	CreateSearchContext(ctx context.Context, sc *types.SearchContext, revs []*types.SearchContextRepositoryRevisions) error
+	UpdateSearchContext(ctx context.Context, sc *types.SearchContext, revs []*types.SearchContextRepositoryRevisions) error
+	DeleteSearchContext(ctx context.Context, id int64) error
+}
+
+type dbStore struct {
+	db dbutil.DB
+}
+
+// GetSiteConfig doesn't save the default site config if there is none, so that diffing works with
+// a read-only connection. UpdateSiteConfig saves it first if needed.
+func (s dbStore) GetSiteConfig(ctx context.Context) (*confdb.SiteConfig, error) {
+	return confdb.SiteGetLatestOrDefault(ctx)
+}
+
+func (s dbStore) UpdateSiteConfig(ctx context.Context, lastID int32, contents string) error {
+	_, err := confdb.SiteCreateIfUpToDate(ctx, &lastID, contents)
+	return err
+}
+
+func (s dbStore) GetSettings(ctx context.Context, subject api.SettingsSubject) (*api.Settings, error) {
+	return database.Settings(s.db).GetLatest(ctx, subject)
+}
+
+func (s dbStore) UpdateSettings(ctx context.Context, subject api.SettingsSubject, lastID *int32, contents string) error {
+	_, err := database.Settings(s.db).CreateIfUpToDate(ctx, subject, lastID, nil, contents)
+	return err
+}
+
+func (s dbStore) GetOrgByName(ctx context.Context, name string) (*types.Org, error) {
+	return database.Orgs(s.db).GetByName(ctx, name)
+}
+
+func (s dbStore) ListExternalServices(ctx context.Context) ([]*types.ExternalService, error) {
+	return database.ExternalServices(s.db).List(ctx, database.ExternalServicesListOptions{NoNamespace: true})
+}
+
+func (s dbStore) CreateExternalService(ctx context.Context, svc *types.ExternalService) error {
+	return database.ExternalServices(s.db).Create(ctx, conf.Get, svc)
+}
+
+func (s dbStore) UpdateExternalService(ctx context.Context, id int64, svc *types.ExternalService) error {
+	return database.ExternalServices(s.db).Update(ctx, conf.Get().AuthProviders, id, &database.ExternalServiceUpdate{
+		DisplayName: &svc.DisplayName,
+		Config:      &svc.Config,
+	})
+}
+
+func (s dbStore) DeleteExternalService(ctx context.Context, id int64) error {
+	return database.ExternalServices(s.db).Delete(ctx, id)
+}
+
+func (s dbStore) ListSearchContexts(ctx context.Context) ([]*types.SearchContext, error) {
+	const pageSize = 100
+	var all []*types.SearchContext
+	for {
+		page, err := database.SearchContexts(s.db).ListSearchContexts(ctx,
+			database.ListSearchContextsPageOptions{First: pageSize, After: int32(len(all))},
+			database.ListSearchContextsOptions{NoNamespace: true},
+		)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < pageSize {
+			return all, nil
+		}
+	}
+}
+
+func (s dbStore) GetSearchContextRepositoryRevisions(ctx context.Context, id int64) ([]*types.SearchContextRepositoryRevisions, error) {
+	return database.SearchContexts(s.db).GetSearchContextRepositoryRevisions(ctx, id)
+}
+
+func (s dbStore) GetRepoByName(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+	return database.Repos(s.db).GetByName(ctx, name)
+}
+
+func (s dbStore) CreateSearchContext(ctx context.Context, sc *types.SearchContext, revs []*types.SearchContextRepositoryRevisions) error {
+	_, err := database.SearchContexts(s.db).CreateSearchContextWithRepositoryRevisions(ctx, sc, revs)
+	return err
+}
+
+func (s dbStore) UpdateSearchContext(ctx context.Context, sc *types.SearchContext, revs []*types.SearchContextRepositoryRevisions) error {
+	_, err := database.SearchContexts(s.db).UpdateSearchContextWithRepositoryRevisions(ctx, sc, revs)
+	return err
+}
+
+func (s dbStore) DeleteSearchContext(ctx context.Context, id int64) error {
+	return database.SearchContexts(s.db).DeleteSearchContext(ctx, id)
+}
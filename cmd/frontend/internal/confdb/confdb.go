@@ -10,16 +10,18 @@ import (
 	"github.com/keegancsmith/sqlf"
 	"github.com/sourcegraph/jsonx"
 
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/conf/confdefaults"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbconn"
 )
 
 // SiteConfig contains the contents of a site config along with associated metadata.
 type SiteConfig struct {
-	ID        int32     // the unique ID of this config
-	Contents  string    // the raw JSON content (with comments and trailing commas allowed)
-	CreatedAt time.Time // the date when this config was created
-	UpdatedAt time.Time // the date when this config was updated
+	ID           int32     // the unique ID of this config
+	AuthorUserID int32     // the ID of the user who saved this config, or 0 if unknown (e.g. the default)
+	Contents     string    // the raw JSON content (with comments and trailing commas allowed)
+	CreatedAt    time.Time // the date when this config was created
+	UpdatedAt    time.Time // the date when this config was updated
 }
 
 // ErrNewerEdit is returned by SiteCreateIfUpToDate when a newer edit has already been applied and
@@ -30,7 +32,8 @@ var ErrNewerEdit = errors.New("someone else has already applied a newer edit")
 // supplied "lastID" is equal to the one that was most recently saved to the database.
 //
 // The site config that was most recently saved to the database is returned.
-// An error is returned if "contents" is invalid JSON.
+// An error is returned if "contents" is invalid JSON. The user of the actor in
+// ctx, if any, is recorded as the author of the site config.
 //
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
@@ -72,6 +75,74 @@ func SiteGetLatest(ctx context.Context) (latest *SiteConfig, err error) {
 	return getLatest(ctx, tx)
 }
 
+// SiteGetLatestOrDefault returns the site config that was most recently saved to the
+// database, or the default site config (with ID 0) if there is none yet. Unlike
+// SiteGetLatest, it never saves the default, so it can be used with a read-only connection.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteGetLatestOrDefault(ctx context.Context) (*SiteConfig, error) {
+	latest, err := getLatest(ctx, dbconn.Global)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil {
+		return &SiteConfig{Contents: confdefaults.Default.Site}, nil
+	}
+	return latest, nil
+}
+
+// SiteGetByID returns the site config with the given ID, or nil if there is none.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteGetByID(ctx context.Context, id int32) (*SiteConfig, error) {
+	q := sqlf.Sprintf(siteSelectQueryFmtstr+" AND id=%s", "site", id)
+	versions, err := query(ctx, dbconn.Global, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+	return versions[0], nil
+}
+
+// SiteListOptions contains the options for listing the history of the site config.
+type SiteListOptions struct {
+	// BeforeID, if set, only lists the site configs with a smaller ID, for pagination.
+	BeforeID int32
+	// Limit is the maximum number of site configs to list. If 0, all are listed.
+	Limit int
+}
+
+// SiteList returns the site configs that were saved to the database, most recent first.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteList(ctx context.Context, opt SiteListOptions) ([]*SiteConfig, error) {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if opt.BeforeID != 0 {
+		conds = append(conds, sqlf.Sprintf("id < %s", opt.BeforeID))
+	}
+	limit := sqlf.Sprintf("")
+	if opt.Limit > 0 {
+		limit = sqlf.Sprintf("LIMIT %s", opt.Limit)
+	}
+	q := sqlf.Sprintf(siteSelectQueryFmtstr+" AND %s ORDER BY id DESC %s", "site", sqlf.Join(conds, "AND"), limit)
+	return query(ctx, dbconn.Global, q)
+}
+
+// SiteCount returns the number of site configs that were saved to the database.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteCount(ctx context.Context) (int, error) {
+	var count int
+	err := dbconn.Global.QueryRowContext(ctx, "SELECT COUNT(*) FROM critical_and_site_config WHERE type=$1", "site").Scan(&count)
+	return count, err
+}
+
 func newTransaction(ctx context.Context) (tx queryable, done func(), err error) {
 	rtx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
@@ -115,6 +186,11 @@ func createIfUpToDate(ctx context.Context, tx queryable, lastID *int32, contents
 	}
 
 	new := SiteConfig{Contents: contents}
+	var authorUserID *int32
+	if a := actor.FromContext(ctx); a.IsAuthenticated() && !a.Internal {
+		new.AuthorUserID = a.UID
+		authorUserID = &a.UID
+	}
 
 	latest, err = getLatest(ctx, tx)
 	if err != nil {
@@ -126,8 +202,8 @@ func createIfUpToDate(ctx context.Context, tx queryable, lastID *int32, contents
 
 	err = tx.QueryRowContext(
 		ctx,
-		"INSERT INTO critical_and_site_config(type, contents) VALUES($1, $2) RETURNING id, created_at, updated_at",
-		"site", new.Contents,
+		"INSERT INTO critical_and_site_config(type, contents, author_user_id) VALUES($1, $2, $3) RETURNING id, created_at, updated_at",
+		"site", new.Contents, authorUserID,
 	).Scan(&new.ID, &new.CreatedAt, &new.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -135,13 +211,11 @@ func createIfUpToDate(ctx context.Context, tx queryable, lastID *int32, contents
 	return &new, nil
 }
 
+const siteSelectQueryFmtstr = "SELECT s.id, COALESCE(s.author_user_id, 0), s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s"
+
 func getLatest(ctx context.Context, tx queryable) (*SiteConfig, error) {
-	q := sqlf.Sprintf("SELECT s.id, s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC LIMIT 1", "site")
-	rows, err := tx.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
-	if err != nil {
-		return nil, err
-	}
-	versions, err := parseQueryRows(ctx, rows)
+	q := sqlf.Sprintf(siteSelectQueryFmtstr+" ORDER BY id DESC LIMIT 1", "site")
+	versions, err := query(ctx, tx, q)
 	if err != nil {
 		return nil, err
 	}
@@ -152,12 +226,20 @@ func getLatest(ctx context.Context, tx queryable) (*SiteConfig, error) {
 	return versions[0], nil
 }
 
+func query(ctx context.Context, tx queryable, q *sqlf.Query) ([]*SiteConfig, error) {
+	rows, err := tx.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	return parseQueryRows(ctx, rows)
+}
+
 func parseQueryRows(ctx context.Context, rows *sql.Rows) ([]*SiteConfig, error) {
 	versions := []*SiteConfig{}
 	defer rows.Close()
 	for rows.Next() {
 		f := SiteConfig{}
-		err := rows.Scan(&f.ID, &f.Contents, &f.CreatedAt, &f.UpdatedAt)
+		err := rows.Scan(&f.ID, &f.AuthorUserID, &f.Contents, &f.CreatedAt, &f.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbconn"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
 )
 
@@ -198,3 +200,63 @@ func TestSiteCreateIfUpToDate(t *testing.T) {
 		})
 	}
 }
+
+func TestSiteHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	var userID int32
+	if err := dbconn.Global.QueryRowContext(ctx, "INSERT INTO users (username) VALUES ('u') RETURNING id").Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := SiteGetLatest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := SiteCreateIfUpToDate(actor.WithActor(ctx, actor.FromUser(userID)), &latest.ID, `{"a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.AuthorUserID != userID {
+		t.Errorf("got author %d, want %d", created.AuthorUserID, userID)
+	}
+
+	got, err := SiteGetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.AuthorUserID != userID || got.Contents != `{"a": 1}` {
+		t.Errorf("got %+v, want the created site config", got)
+	}
+	if got, err := SiteGetByID(ctx, created.ID+1); err != nil || got != nil {
+		t.Errorf("got %+v, %v, want no site config", got, err)
+	}
+
+	all, err := SiteList(ctx, SiteListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].ID != created.ID || all[1].ID != latest.ID || all[1].AuthorUserID != 0 {
+		t.Errorf("got %+v, want the created and the default site config", all)
+	}
+	before, err := SiteList(ctx, SiteListOptions{BeforeID: created.ID, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 1 || before[0].ID != latest.ID {
+		t.Errorf("got %+v, want the default site config", before)
+	}
+
+	count, err := SiteCount(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("got count %d, want 2", count)
+	}
+}
@@ -29,6 +29,8 @@ func newTest() *httptestutil.Client {
 		enterpriseServices.GitLabWebhook,
 		enterpriseServices.BitbucketServerWebhook,
 		enterpriseServices.NewCodeIntelUploadHandler,
+		enterpriseServices.CodeIntelExportHandler,
+		enterpriseServices.SearchJobsHandler,
 		rateLimiter,
 	))
 }
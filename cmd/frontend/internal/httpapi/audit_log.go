@@ -0,0 +1,116 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+const (
+	// auditLogPageSize is the number of audit log entries read from the database at a time.
+	auditLogPageSize = 1000
+
+	// auditLogFollowInterval is how often new audit log entries are read when following the
+	// audit log.
+	auditLogFollowInterval = 2 * time.Second
+)
+
+// auditLogHandler exports the audit log as JSON lines (one JSON-encoded database.AuditLogEntry per
+// line) in the order in which the entries were committed. The query parameters are:
+//
+// - after: only export the entries committed after the entry with this ID, to resume an export
+// - since: only export the entries at or after this time (RFC 3339)
+// - follow: if true, keep the response open and stream new entries as they are written
+func auditLogHandler(db dbutil.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 🚨 SECURITY: Only site admins may read the audit log.
+		if err := backend.CheckCurrentUserIsSiteAdmin(r.Context(), db); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		opt := database.AuditLogsListOptions{Limit: auditLogPageSize}
+		q := r.URL.Query()
+		if v := q.Get("after"); v != "" {
+			after, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				http.Error(w, "invalid after parameter: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			opt.AfterID = after
+		}
+		if v := q.Get("since"); v != "" {
+			since, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, "invalid since parameter: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			opt.Since = since
+		}
+		follow, _ := strconv.ParseBool(q.Get("follow"))
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		flusher, _ := w.(http.Flusher)
+		enc := json.NewEncoder(w)
+
+		ctx := r.Context()
+		if opt.AfterID != 0 {
+			// Resume in commit order after the entry. If it no longer exists, entries with a
+			// greater ID are exported.
+			txID, err := database.AuditLogs(db).GetTxID(ctx, opt.AfterID)
+			if err != nil {
+				log15.Error("Failed to export audit log.", "error", err)
+				http.Error(w, "failed to read audit log", http.StatusInternalServerError)
+				return
+			}
+			opt.AfterTxID = txID
+		}
+
+		wrote := false
+		for {
+			entries, err := database.AuditLogs(db).List(ctx, opt)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log15.Error("Failed to export audit log.", "error", err)
+				// Once entries are written, the status can't be changed. Clients resume the export
+				// with the after parameter.
+				if !wrote {
+					http.Error(w, "failed to read audit log", http.StatusInternalServerError)
+				}
+				return
+			}
+			for _, e := range entries {
+				if err := enc.Encode(e); err != nil {
+					return
+				}
+				wrote = true
+				opt.AfterTxID, opt.AfterID = e.TxID, e.ID
+			}
+			if len(entries) == auditLogPageSize {
+				continue
+			}
+			if !follow {
+				return
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(auditLogFollowInterval):
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+func TestAuditLogHandler(t *testing.T) {
+	t.Cleanup(func() { database.Mocks = database.MockStores{} })
+
+	var siteAdmin bool
+	database.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: siteAdmin}, nil
+	}
+	// Entries 1, 2 and 3 are committed by the transactions 10, 30 and 20, and are listed in commit
+	// order.
+	entries := []*database.AuditLogEntry{
+		{ID: 1, TxID: 10, Action: "graphql.deleteUser", Outcome: database.AuditLogOutcomeSuccess},
+		{ID: 3, TxID: 20, Action: "graphql.deleteUser", Outcome: database.AuditLogOutcomeSuccess},
+		{ID: 2, TxID: 30, Action: "graphql.deleteUser", Outcome: database.AuditLogOutcomeSuccess},
+	}
+	database.Mocks.AuditLogs.GetTxID = func(ctx context.Context, id int64) (int64, error) {
+		for _, e := range entries {
+			if e.ID == id {
+				return e.TxID, nil
+			}
+		}
+		return 0, nil
+	}
+	var listed []database.AuditLogsListOptions
+	database.Mocks.AuditLogs.List = func(ctx context.Context, opt database.AuditLogsListOptions) ([]*database.AuditLogEntry, error) {
+		listed = append(listed, opt)
+		for _, e := range entries {
+			if e.TxID > opt.AfterTxID || (e.TxID == opt.AfterTxID && e.ID > opt.AfterID) {
+				return []*database.AuditLogEntry{e}, nil
+			}
+		}
+		return nil, nil
+	}
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		auditLogHandler(nil).ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		return rec
+	}
+
+	t.Run("non-admin", func(t *testing.T) {
+		if rec := serve("/audit-log"); rec.Code != http.StatusForbidden {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+
+	siteAdmin = true
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, url := range []string{"/audit-log?after=x", "/audit-log?since=yesterday"} {
+			if rec := serve(url); rec.Code != http.StatusBadRequest {
+				t.Errorf("%s: got status %d, want %d", url, rec.Code, http.StatusBadRequest)
+			}
+		}
+	})
+
+	t.Run("export", func(t *testing.T) {
+		listed = nil
+		rec := serve("/audit-log?after=3&since=2021-06-01T00:00:00Z")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d", rec.Code)
+		}
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		if len(lines) != 1 || !strings.HasPrefix(lines[0], `{"id":2,`) {
+			t.Errorf("got lines %q, want entry 2", lines)
+		}
+		if len(listed) != 1 || listed[0].AfterID != 3 || listed[0].AfterTxID != 20 || listed[0].Since.IsZero() {
+			t.Errorf("got list options %+v", listed)
+		}
+	})
+}
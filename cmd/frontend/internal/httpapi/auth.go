@@ -1,7 +1,10 @@
 package httpapi
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/inconshreveable/log15"
 
@@ -63,23 +66,33 @@ func AccessTokenAuthMiddleware(db dbutil.DB, next http.Handler) http.Handler {
 			//
 			// 🚨 SECURITY: It's important we check for the correct scopes to know what this token
 			// is allowed to do.
-			var requiredScope string
-			if sudoUser == "" {
-				requiredScope = authz.ScopeUserAll
-			} else {
-				requiredScope = authz.ScopeSiteAdminSudo
+			accessToken, err := database.AccessTokens(db).LookupToken(r.Context(), token)
+			if err == nil && sudoUser != "" && !hasScope(accessToken.Scopes, authz.ScopeSiteAdminSudo) {
+				err = database.ErrAccessTokenNotFound
 			}
-			subjectUserID, err := database.AccessTokens(db).Lookup(r.Context(), token, requiredScope)
 			if err != nil {
 				log15.Error("Invalid access token.", "token", token, "err", err)
 				http.Error(w, "Invalid access token.", http.StatusUnauthorized)
 				return
 			}
+			subjectUserID := accessToken.SubjectUserID
+			logAccessTokenUsed(r, db, accessToken)
 
 			// Determine the actor's user ID.
 			var actorUserID int32
+			var scopes []string
 			if sudoUser == "" {
 				actorUserID = subjectUserID
+
+				// 🚨 SECURITY: Tokens without the "user:all" scope may only be used for the
+				// requests their scopes allow.
+				if !hasScope(accessToken.Scopes, authz.ScopeUserAll) {
+					scopes = accessToken.Scopes
+					if !scopesAllowRequest(scopes, r) {
+						http.Error(w, "The scopes of the access token do not allow this request.", http.StatusForbidden)
+						return
+					}
+				}
 			} else {
 				// 🚨 SECURITY: Confirm that the sudo token's subject is still a site admin, to
 				// prevent users from retaining site admin privileges after being demoted.
@@ -107,9 +120,65 @@ func AccessTokenAuthMiddleware(db dbutil.DB, next http.Handler) http.Handler {
 				log15.Debug("HTTP request used sudo token.", "requestURI", r.URL.RequestURI(), "tokenSubjectUserID", subjectUserID, "actorUserID", actorUserID, "actorUsername", user.Username)
 			}
 
-			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID}))
+			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID, Scopes: scopes, AccessTokenID: accessToken.ID}))
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// scopesAllowRequest returns true if an access token restricted to scopes may be used for r.
+func scopesAllowRequest(scopes []string, r *http.Request) bool {
+	p := r.URL.Path
+	switch {
+	case p == "/.api/graphql":
+		// GraphQL requests are checked field by field, see graphqlbackend.CheckAccessTokenScopes.
+		return true
+	case p == "/.api/search/stream", p == "/search/stream", strings.HasPrefix(p, "/.api/search/jobs"):
+		return hasScope(scopes, authz.ScopeSearchRead)
+	case p == "/.api/lsif/upload":
+		return hasScope(scopes, authz.ScopeCodeIntelUpload)
+	case !strings.HasPrefix(p, "/.api/") && strings.Contains(p, "/-/raw"):
+		return hasScope(scopes, authz.ScopeRepoRead)
+	}
+	return false
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// accessTokenUsedEventInterval is the minimum interval between the security events logged for the
+// uses of an access token, so that API clients don't flood the security event log.
+const accessTokenUsedEventInterval = time.Hour
+
+// logAccessTokenUsed logs a security event for the use of t, if it wasn't used recently.
+func logAccessTokenUsed(r *http.Request, db dbutil.DB, t *database.AccessToken) {
+	if t.LastUsedAt != nil && time.Since(*t.LastUsedAt) < accessTokenUsedEventInterval {
+		return
+	}
+
+	args, err := json.Marshal(map[string]interface{}{
+		"access_token_id": t.ID,
+		"scopes":          t.Scopes,
+	})
+	if err != nil {
+		log15.Error("logAccessTokenUsed: failed to marshal JSON", "err", err)
+	}
+
+	if err := database.SecurityEventLogs(db).Insert(r.Context(), &database.SecurityEvent{
+		Name:      database.SecurityEventNameAccessTokenUsed,
+		URL:       r.URL.Path,
+		UserID:    uint32(t.SubjectUserID),
+		Argument:  args,
+		Source:    "BACKEND",
+		Timestamp: time.Now(),
+	}); err != nil {
+		log15.Error("logAccessTokenUsed: failed to log security event", "err", err)
+	}
+}
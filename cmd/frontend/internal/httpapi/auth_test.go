@@ -71,9 +71,9 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", "token badbad")
 		var calledAccessTokensLookup bool
-		database.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
 			calledAccessTokensLookup = true
-			return 0, errors.New("x")
+			return nil, errors.New("x")
 		}
 		defer func() { database.Mocks = database.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusUnauthorized, "Invalid access token.\n")
@@ -87,21 +87,22 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			req, _ := http.NewRequest("GET", "/", nil)
 			req.Header.Set("Authorization", headerValue)
 			var calledAccessTokensLookup bool
-			database.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
-				}
-				return 123, nil
+				return &database.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 			}
 			defer func() { database.Mocks = database.MockStores{} }()
+			events := mockSecurityEventLogs()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
 			if !calledAccessTokensLookup {
 				t.Error("!calledAccessTokensLookup")
 			}
+			if len(*events) != 1 || (*events)[0].Name != database.SecurityEventNameAccessTokenUsed {
+				t.Errorf("got security events %+v, want one %s event", *events, database.SecurityEventNameAccessTokenUsed)
+			}
 		})
 	}
 
@@ -111,17 +112,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req.Header.Set("Authorization", "token abcdef")
 		req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 		var calledAccessTokensLookup bool
-		database.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeUserAll; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &database.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 		}
 		defer func() { database.Mocks = database.MockStores{} }()
+		mockSecurityEventLogs()
 		checkHTTPResponse(t, req, http.StatusOK, "user 123")
 		if !calledAccessTokensLookup {
 			t.Error("!calledAccessTokensLookup")
@@ -145,17 +144,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			}
 			req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 			var calledAccessTokensLookup bool
-			database.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
-				}
-				return 123, nil
+				return &database.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
 			}
 			defer func() { database.Mocks = database.MockStores{} }()
+			mockSecurityEventLogs()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
 			if !calledAccessTokensLookup {
 				t.Error("!calledAccessTokensLookup")
@@ -167,15 +164,12 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		database.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &database.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		database.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -194,6 +188,7 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			return &types.User{ID: 456, SiteAdmin: true}, nil
 		}
 		defer func() { database.Mocks = database.MockStores{} }()
+		mockSecurityEventLogs()
 		checkHTTPResponse(t, req, http.StatusOK, "user 456")
 		if !calledAccessTokensLookup {
 			t.Error("!calledAccessTokensLookup")
@@ -212,15 +207,12 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		database.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &database.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		database.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -231,6 +223,7 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			return &types.User{ID: userID, SiteAdmin: false}, nil
 		}
 		defer func() { database.Mocks = database.MockStores{} }()
+		mockSecurityEventLogs()
 		checkHTTPResponse(t, req, http.StatusForbidden, "The subject user of a sudo access token must be a site admin.\n")
 		if !calledAccessTokensLookup {
 			t.Error("!calledAccessTokensLookup")
@@ -244,15 +237,12 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="doesntexist"`)
 		var calledAccessTokensLookup bool
-		database.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
-			}
-			return 123, nil
+			return &database.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll, authz.ScopeSiteAdminSudo}}, nil
 		}
 		var calledUsersGetByID bool
 		database.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -271,6 +261,7 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			return nil, &errcode.Mock{IsNotFound: true}
 		}
 		defer func() { database.Mocks = database.MockStores{} }()
+		mockSecurityEventLogs()
 		checkHTTPResponse(t, req, http.StatusForbidden, "Unable to sudo to nonexistent user.\n")
 		if !calledAccessTokensLookup {
 			t.Error("!calledAccessTokensLookup")
@@ -282,4 +273,64 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			t.Error("!calledUsersGetByUsername")
 		}
 	})
+
+	t.Run("sudo token without sudo scope", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
+		database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
+			return &database.AccessToken{SubjectUserID: 123, Scopes: []string{authz.ScopeUserAll}}, nil
+		}
+		defer func() { database.Mocks = database.MockStores{} }()
+		mockSecurityEventLogs()
+		checkHTTPResponse(t, req, http.StatusUnauthorized, "Invalid access token.\n")
+	})
+
+	for _, tc := range []struct {
+		path           string
+		scope          string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{"/.api/search/stream", authz.ScopeSearchRead, http.StatusOK, "user 123 [search:read]"},
+		{"/.api/graphql", authz.ScopeRepoRead, http.StatusOK, "user 123 [repo:read]"},
+		{"/github.com/foo/bar/-/raw/README.md", authz.ScopeRepoRead, http.StatusOK, "user 123 [repo:read]"},
+		{"/.api/lsif/upload", authz.ScopeCodeIntelUpload, http.StatusOK, "user 123 [codeintel:upload]"},
+		{"/.api/lsif/upload", authz.ScopeSearchRead, http.StatusForbidden, "The scopes of the access token do not allow this request.\n"},
+		{"/.api/repos/list", authz.ScopeRepoRead, http.StatusForbidden, "The scopes of the access token do not allow this request.\n"},
+		{"/site-admin", authz.ScopeBatchChangesWrite, http.StatusForbidden, "The scopes of the access token do not allow this request.\n"},
+	} {
+		t.Run("restricted token "+tc.scope+" "+tc.path, func(t *testing.T) {
+			handler := AccessTokenAuthMiddleware(new(dbtesting.MockDB), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				actor := actor.FromContext(r.Context())
+				fmt.Fprintf(w, "user %v %v", actor.UID, actor.Scopes)
+			}))
+			req, _ := http.NewRequest("GET", tc.path, nil)
+			req.Header.Set("Authorization", "token abcdef")
+			database.Mocks.AccessTokens.LookupToken = func(tokenHexEncoded string) (*database.AccessToken, error) {
+				return &database.AccessToken{SubjectUserID: 123, Scopes: []string{tc.scope}}, nil
+			}
+			defer func() { database.Mocks = database.MockStores{} }()
+			mockSecurityEventLogs()
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tc.wantStatusCode {
+				t.Errorf("got response status %d, want %d", rr.Code, tc.wantStatusCode)
+			}
+			if got := rr.Body.String(); got != tc.wantBody {
+				t.Errorf("got response body %q, want %q", got, tc.wantBody)
+			}
+		})
+	}
+}
+
+// mockSecurityEventLogs makes the security events that are logged be recorded in the returned
+// slice instead of the database.
+func mockSecurityEventLogs() *[]*database.SecurityEvent {
+	var events []*database.SecurityEvent
+	database.Mocks.SecurityEventLogs.Insert = func(ctx context.Context, e *database.SecurityEvent) error {
+		events = append(events, e)
+		return nil
+	}
+	return &events
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/throttled/throttled/v2"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/apiratelimit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/cookie"
@@ -75,6 +76,23 @@ func serveGraphQL(schema *graphql.Schema, rlw graphqlbackend.LimitWatcher, isInt
 
 		validationErrs := schema.ValidateWithVariables(params.Query, params.Variables)
 
+		// 🚨 SECURITY: Access tokens restricted to some scopes may only request the fields those
+		// scopes allow.
+		if len(validationErrs) == 0 {
+			if err := graphqlbackend.CheckAccessTokenScopes(r.Context(), params.Query); err != nil {
+				responseJSON, err := json.Marshal(&graphql.Response{
+					Errors: []*gqlerrors.QueryError{{Message: err.Error()}},
+				})
+				if err != nil {
+					return err
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				w.Write(responseJSON)
+				return nil
+			}
+		}
+
 		var cost *graphqlbackend.QueryCost
 		var costErr error
 
@@ -108,6 +126,30 @@ func serveGraphQL(schema *graphql.Schema, rlw graphqlbackend.LimitWatcher, isInt
 					}
 				}
 			}
+
+			// Internal requests are made by Sourcegraph services on behalf of users whose
+			// requests were already limited.
+			if !isInternal {
+				fieldCount := apiratelimit.DefaultGraphQLCost
+				if cost != nil {
+					fieldCount = cost.FieldCount
+				}
+				res, err := apiratelimit.Check(r, apiratelimit.EndpointGraphQL, fieldCount)
+				if err != nil {
+					log15.Error("checking API rate limit", "error", err)
+					traceData.limitError = err
+				} else {
+					apiratelimit.WriteHeaders(w, res)
+					if res.Enforced {
+						traceData.limited = res.Limited
+						traceData.limitResult = res.RateLimitResult
+					}
+					if res.Limited {
+						w.WriteHeader(http.StatusTooManyRequests)
+						return nil
+					}
+				}
+			}
 		}
 
 		traceData.execStart = time.Now()
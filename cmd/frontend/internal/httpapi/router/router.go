@@ -9,15 +9,19 @@ import (
 
 const (
 	LSIFUpload = "lsif.upload"
+	LSIFExport = "lsif.export"
 	GraphQL    = "graphql"
 
 	SearchStream = "search.stream"
+	SearchJobs   = "search.jobs"
 
 	SrcCliVersion  = "src-cli.version"
 	SrcCliDownload = "src-cli.download"
 
 	Registry = "registry"
 
+	AuditLog = "audit-log"
+
 	RepoShield  = "repo.shield"
 	RepoRefresh = "repo.refresh"
 	Telemetry   = "telemetry"
@@ -72,7 +76,10 @@ func New(base *mux.Router) *mux.Router {
 	base.Path("/gitlab-webhooks").Methods("POST").Name(GitLabWebhooks)
 	base.Path("/bitbucket-server-webhooks").Methods("POST").Name(BitbucketServerWebhooks)
 	base.Path("/lsif/upload").Methods("POST").Name(LSIFUpload)
+	base.Path("/lsif/export").Methods("GET").Name(LSIFExport)
 	base.Path("/search/stream").Methods("GET").Name(SearchStream)
+	base.PathPrefix("/search/jobs").Name(SearchJobs)
+	base.Path("/audit-log").Methods("GET").Name(AuditLog)
 	base.Path("/src-cli/version").Methods("GET").Name(SrcCliVersion)
 	base.Path("/src-cli/{rest:.*}").Methods("GET").Name(SrcCliDownload)
 
@@ -0,0 +1,411 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// filter is a parsed SCIM filter (RFC 7644, section 3.4.2.2), which is matched against the JSON
+// representation of a resource.
+type filter interface {
+	match(resource map[string]interface{}) bool
+}
+
+type andFilter struct{ left, right filter }
+
+func (f andFilter) match(r map[string]interface{}) bool { return f.left.match(r) && f.right.match(r) }
+
+type orFilter struct{ left, right filter }
+
+func (f orFilter) match(r map[string]interface{}) bool { return f.left.match(r) || f.right.match(r) }
+
+type notFilter struct{ f filter }
+
+func (f notFilter) match(r map[string]interface{}) bool { return !f.f.match(r) }
+
+// valuePathFilter matches resources with an element of the multi-valued attribute that matches
+// the filter, e.g. emails[type eq "work"].
+type valuePathFilter struct {
+	attr string
+	f    filter
+}
+
+func (f valuePathFilter) match(r map[string]interface{}) bool {
+	for _, v := range asList(getAttr(r, f.attr)) {
+		if m, ok := v.(map[string]interface{}); ok && f.f.match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareFilter matches resources with an attribute value that compares to value with op, e.g.
+// userName eq "alice".
+type compareFilter struct {
+	path  attrPath
+	op    string
+	value interface{} // string, bool, float64 or nil; unused for op "pr"
+}
+
+func (f compareFilter) match(r map[string]interface{}) bool {
+	values := f.path.values(r)
+	switch f.op {
+	case "pr":
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		return !compareFilter{path: f.path, op: "eq", value: f.value}.match(r)
+	}
+	if f.value == nil && f.op == "eq" {
+		return !compareFilter{path: f.path, op: "pr"}.match(r)
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(v interface{}, op string, value interface{}) bool {
+	switch value := value.(type) {
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		// Attributes are compared case-insensitively, which matches the caseExact=false of the
+		// attributes of the core schemas that clients filter on.
+		s, value = strings.ToLower(s), strings.ToLower(value)
+		switch op {
+		case "eq":
+			return s == value
+		case "co":
+			return strings.Contains(s, value)
+		case "sw":
+			return strings.HasPrefix(s, value)
+		case "ew":
+			return strings.HasSuffix(s, value)
+		case "gt":
+			return s > value
+		case "ge":
+			return s >= value
+		case "lt":
+			return s < value
+		case "le":
+			return s <= value
+		}
+	case bool:
+		b, ok := v.(bool)
+		return ok && op == "eq" && b == value
+	case float64:
+		n, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return n == value
+		case "gt":
+			return n > value
+		case "ge":
+			return n >= value
+		case "lt":
+			return n < value
+		case "le":
+			return n <= value
+		}
+	}
+	return false
+}
+
+// attrPath is an attribute path like userName or name.givenName.
+type attrPath struct {
+	attr, subAttr string
+}
+
+func parseAttrPath(s string) attrPath {
+	// Attributes may be qualified with the schema URN, e.g.
+	// urn:ietf:params:scim:schemas:core:2.0:User:userName.
+	if strings.HasPrefix(strings.ToLower(s), "urn:") {
+		if i := strings.LastIndex(s, ":"); i != -1 {
+			s = s[i+1:]
+		}
+	}
+	if i := strings.Index(s, "."); i != -1 {
+		return attrPath{attr: s[:i], subAttr: s[i+1:]}
+	}
+	return attrPath{attr: s}
+}
+
+// values returns the values of the attribute at the path in r. The values of a multi-valued
+// attribute of complex values without a sub-attribute are the values of their "value"
+// sub-attributes.
+func (p attrPath) values(r map[string]interface{}) []interface{} {
+	var values []interface{}
+	for _, v := range asList(getAttr(r, p.attr)) {
+		m, ok := v.(map[string]interface{})
+		switch {
+		case ok && p.subAttr != "":
+			values = append(values, asList(getAttr(m, p.subAttr))...)
+		case ok:
+			values = append(values, getAttr(m, "value"))
+		case p.subAttr == "":
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// getAttr returns the value of the attribute of m, whose name is matched case-insensitively.
+func getAttr(m map[string]interface{}, name string) interface{} {
+	if k, ok := attrKey(m, name); ok {
+		return m[k]
+	}
+	return nil
+}
+
+// attrKey returns the key of m which case-insensitively matches name.
+func attrKey(m map[string]interface{}, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+func asList(v interface{}) []interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	default:
+		return []interface{}{v}
+	}
+}
+
+// eqValue returns the attribute and value of f if it is a filter like `attr eq "value"`.
+func eqValue(f filter) (attr, value string, ok bool) {
+	c, ok := f.(compareFilter)
+	if !ok || c.op != "eq" || c.path.subAttr != "" {
+		return "", "", false
+	}
+	value, ok = c.value.(string)
+	return c.path.attr, value, ok
+}
+
+var compareOps = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// parseFilter parses a SCIM filter.
+func parseFilter(s string) (filter, error) {
+	tokens, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool // whether the token is a string literal, in which case text is its value
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			tokens = append(tokens, filterToken{text: string(c)})
+			i++
+		case c == '"':
+			// Find the closing quote, skipping escaped characters.
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(s) {
+				return nil, errors.New("unterminated string")
+			}
+			var value string
+			if err := json.Unmarshal([]byte(s[i:j+1]), &value); err != nil {
+				return nil, errors.Errorf("invalid string %s", s[i:j+1])
+			}
+			tokens = append(tokens, filterToken{text: value, quoted: true})
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t()[]\"", rune(s[j])) {
+				j++
+			}
+			tokens = append(tokens, filterToken{text: s[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return filterToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// peekKeyword reports whether the next token is the (case-insensitive) keyword.
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t, ok := p.peek()
+	return ok && !t.quoted && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) expect(text string) error {
+	if !p.peekKeyword(text) {
+		return errors.Errorf("expected %q", text)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *filterParser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return notFilter{f}, p.expect(")")
+	}
+	if p.peekKeyword("(") {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return f, p.expect(")")
+	}
+
+	t, ok := p.peek()
+	if !ok || t.quoted {
+		return nil, errors.New("expected attribute")
+	}
+	p.pos++
+
+	if p.peekKeyword("[") {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return valuePathFilter{attr: t.text, f: f}, p.expect("]")
+	}
+
+	path := parseAttrPath(t.text)
+	opToken, ok := p.peek()
+	if !ok || opToken.quoted {
+		return nil, errors.Errorf("expected operator after %q", t.text)
+	}
+	op := strings.ToLower(opToken.text)
+	p.pos++
+	if op == "pr" {
+		return compareFilter{path: path, op: op}, nil
+	}
+	if !compareOps[op] {
+		return nil, errors.Errorf("unknown operator %q", opToken.text)
+	}
+
+	v, ok := p.peek()
+	if !ok {
+		return nil, errors.Errorf("expected value after %q", opToken.text)
+	}
+	p.pos++
+	value, err := parseFilterValue(v)
+	if err != nil {
+		return nil, err
+	}
+	return compareFilter{path: path, op: op, value: value}, nil
+}
+
+func parseFilterValue(t filterToken) (interface{}, error) {
+	if t.quoted {
+		return t.text, nil
+	}
+	switch strings.ToLower(t.text) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	n, err := strconv.ParseFloat(t.text, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid value %q", t.text)
+	}
+	return n, nil
+}
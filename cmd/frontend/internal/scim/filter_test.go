@@ -0,0 +1,167 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilter(t *testing.T) {
+	var user map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"userName": "alice",
+		"name": {"givenName": "Alice"},
+		"active": true,
+		"emails": [
+			{"value": "alice@example.com", "type": "work", "primary": true},
+			{"value": "alice@example.org", "type": "home"}
+		],
+		"meta": {"lastModified": "2021-06-01T00:00:00Z"}
+	}`), &user); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]bool{
+		`userName eq "alice"`: true,
+		`UserName EQ "ALICE"`: true,
+		`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`: true,
+		`userName ne "alice"`:                               false,
+		`userName sw "al" and userName ew "ce"`:             true,
+		`name.givenName co "lic"`:                           true,
+		`name.familyName pr`:                                false,
+		`name.familyName eq null`:                           true,
+		`emails co "example.org"`:                           true,
+		`emails.value eq "bob@example.com"`:                 false,
+		`emails[type eq "work" and value co "example.com"]`: true,
+		`emails[type eq "home" and primary eq true]`:        false,
+		`active eq true`:                                    true,
+		`not (active eq true) or userName eq "alice"`:       true,
+		`not (active eq true) or (userName eq "bob")`:       false,
+		`meta.lastModified gt "2021-01-01T00:00:00Z"`:       true,
+		`userName eq "al\"ice"`:                             false,
+	}
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			f, err := parseFilter(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.match(user); got != want {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+
+	for _, input := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName xx "alice"`,
+		`userName eq "alice`,
+		`(userName eq "alice"`,
+		`userName eq "alice" extra`,
+		`emails[type eq "work"`,
+	} {
+		if _, err := parseFilter(input); err == nil {
+			t.Errorf("%q: got no error", input)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		ops      string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "replace without path",
+			resource: `{"active": true, "displayName": "a"}`,
+			ops:      `[{"op": "replace", "value": {"active": false, "DisplayName": "b"}}]`,
+			want:     `{"active": false, "displayName": "b"}`,
+		},
+		{
+			name:     "add to multi-valued attribute",
+			resource: `{"members": [{"value": "1"}]}`,
+			ops:      `[{"op": "add", "path": "members", "value": [{"value": "2"}]}]`,
+			want:     `{"members": [{"value": "1"}, {"value": "2"}]}`,
+		},
+		{
+			name:     "remove with filter",
+			resource: `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			ops:      `[{"op": "remove", "path": "members[value eq \"1\"]"}]`,
+			want:     `{"members": [{"value": "2"}]}`,
+		},
+		{
+			name:     "remove with value",
+			resource: `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			ops:      `[{"op": "remove", "path": "members", "value": [{"value": "2"}]}]`,
+			want:     `{"members": [{"value": "1"}]}`,
+		},
+		{
+			name:     "remove all",
+			resource: `{"members": [{"value": "1"}], "displayName": "a"}`,
+			ops:      `[{"op": "remove", "path": "members"}]`,
+			want:     `{"displayName": "a"}`,
+		},
+		{
+			name:     "replace sub-attribute of matching element",
+			resource: `{"emails": [{"value": "a@example.com", "type": "work"}, {"value": "b@example.com", "type": "home"}]}`,
+			ops:      `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "c@example.com"}]`,
+			want:     `{"emails": [{"value": "c@example.com", "type": "work"}, {"value": "b@example.com", "type": "home"}]}`,
+		},
+		{
+			name:     "replace sub-attribute of missing element",
+			resource: `{}`,
+			ops:      `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "c@example.com"}]`,
+			want:     `{"emails": [{"value": "c@example.com", "type": "work"}]}`,
+		},
+		{
+			name:     "set sub-attribute",
+			resource: `{"name": {"givenName": "a"}}`,
+			ops:      `[{"op": "add", "path": "name.familyName", "value": "b"}]`,
+			want:     `{"name": {"givenName": "a", "familyName": "b"}}`,
+		},
+		{
+			name:     "unknown op",
+			resource: `{}`,
+			ops:      `[{"op": "move", "path": "a"}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "remove without path",
+			resource: `{}`,
+			ops:      `[{"op": "remove"}]`,
+			wantErr:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var resource map[string]interface{}
+			var ops []patchOperation
+			if err := json.Unmarshal([]byte(test.resource), &resource); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(test.ops), &ops); err != nil {
+				t.Fatal(err)
+			}
+			err := applyPatch(resource, ops)
+			if gotErr := err != nil; gotErr != test.wantErr {
+				t.Fatalf("got error %v, want error %v", err, test.wantErr)
+			}
+			if test.wantErr {
+				return
+			}
+			var want map[string]interface{}
+			if err := json.Unmarshal([]byte(test.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, resource); diff != "" {
+				t.Errorf("unexpected resource (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// groupResource is the SCIM representation of a Sourcegraph organization.
+type groupResource struct {
+	Schemas     []string      `json:"schemas"`
+	ID          string        `json:"id,omitempty"`
+	DisplayName string        `json:"displayName"`
+	Members     []groupMember `json:"members"`
+	Meta        *meta         `json:"meta,omitempty"`
+}
+
+type groupMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+func (h *handler) toGroupResource(ctx context.Context, org *types.Org) (*groupResource, error) {
+	userIDs, err := h.store.ListOrgMembers(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	r := &groupResource{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.Itoa(int(org.ID)),
+		DisplayName: org.Name,
+		Members:     []groupMember{},
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      org.CreatedAt,
+			LastModified: org.UpdatedAt,
+			Location:     location("Groups", org.ID),
+		},
+	}
+	if org.DisplayName != nil && *org.DisplayName != "" {
+		r.DisplayName = *org.DisplayName
+	}
+	if len(userIDs) > 0 {
+		users, err := h.store.ListUsers(ctx, &database.UsersListOptions{UserIDs: userIDs})
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			r.Members = append(r.Members, groupMember{
+				Value:   strconv.Itoa(int(u.ID)),
+				Display: u.Username,
+				Ref:     location("Users", u.ID),
+			})
+		}
+	}
+	return r, nil
+}
+
+func (h *handler) getGroup(r *http.Request) (int, interface{}, error) {
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	org, err := h.store.GetOrg(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	resource, err := h.toGroupResource(r.Context(), org)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, resource, nil
+}
+
+func (h *handler) listGroups(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	params, err := parseListParams(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		orgs  []*types.Org
+		total int
+	)
+	if params.filter == nil {
+		total, err = h.store.CountOrgs(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+		orgs, err = h.store.ListOrgs(ctx, &database.OrgsListOptions{
+			LimitOffset: &database.LimitOffset{Limit: params.count, Offset: params.startIndex - 1},
+		})
+	} else {
+		orgs, err = h.store.ListOrgs(ctx, nil)
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+
+	resources := []interface{}{}
+	for _, org := range orgs {
+		resource, err := h.toGroupResource(ctx, org)
+		if err != nil {
+			return 0, nil, err
+		}
+		if params.filter == nil || matchResource(params.filter, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	if params.filter != nil {
+		total = len(resources)
+		resources = params.page(resources)
+	}
+	return http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   params.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+func (h *handler) createGroup(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	var desired groupResource
+	if err := decodeBody(r, &desired); err != nil {
+		return 0, nil, err
+	}
+	if desired.DisplayName == "" {
+		return 0, nil, badRequest("invalidValue", "displayName is required.")
+	}
+	// Organizations share their namespace with users.
+	name, err := auth.NormalizeUsername(desired.DisplayName)
+	if err != nil {
+		return 0, nil, badRequest("invalidValue", "Invalid displayName: %s", err)
+	}
+	if _, err := h.store.GetOrgByName(ctx, name); err == nil {
+		return 0, nil, conflict("A group with the name %q already exists.", name)
+	} else if !errcode.IsNotFound(err) {
+		return 0, nil, err
+	}
+	if _, err := h.store.GetUserByUsername(ctx, name); err == nil {
+		return 0, nil, conflict("The name %q is already taken by a user.", name)
+	} else if !errcode.IsNotFound(err) {
+		return 0, nil, err
+	}
+	userIDs, err := h.memberIDs(ctx, desired.Members)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	org, err := h.store.CreateOrg(ctx, name, &desired.DisplayName)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, userID := range userIDs {
+		if err := h.store.AddOrgMember(ctx, org.ID, userID); err != nil {
+			return 0, nil, err
+		}
+	}
+	h.store.LogSecurityEvent(ctx, securityEvent(database.SecurityEventNameSCIMGroupCreated, r.URL.Path, 0, map[string]interface{}{
+		"orgID":   org.ID,
+		"name":    org.Name,
+		"members": userIDs,
+	}))
+
+	resource, err := h.toGroupResource(ctx, org)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, resource, nil
+}
+
+func (h *handler) replaceGroup(r *http.Request) (int, interface{}, error) {
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	org, err := h.store.GetOrg(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	var desired groupResource
+	if err := decodeBody(r, &desired); err != nil {
+		return 0, nil, err
+	}
+	return h.updateGroup(r, org, &desired)
+}
+
+func (h *handler) patchGroup(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	org, err := h.store.GetOrg(ctx, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	var patch patchRequest
+	if err := decodeBody(r, &patch); err != nil {
+		return 0, nil, err
+	}
+
+	current, err := h.toGroupResource(ctx, org)
+	if err != nil {
+		return 0, nil, err
+	}
+	m, err := toMap(current)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := applyPatch(m, patch.Operations); err != nil {
+		return 0, nil, err
+	}
+	var desired groupResource
+	if err := fromMap(m, &desired); err != nil {
+		return 0, nil, err
+	}
+	return h.updateGroup(r, org, &desired)
+}
+
+// updateGroup updates the organization to match the desired resource. The name of an
+// organization can't be changed, so only its display name is updated.
+func (h *handler) updateGroup(r *http.Request, org *types.Org, desired *groupResource) (int, interface{}, error) {
+	ctx := r.Context()
+	wantIDs, err := h.memberIDs(ctx, desired.Members)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	changed := false
+	if desired.DisplayName != "" && (org.DisplayName == nil || *org.DisplayName != desired.DisplayName) {
+		if err := h.store.UpdateOrg(ctx, org.ID, &desired.DisplayName); err != nil {
+			return 0, nil, err
+		}
+		changed = true
+	}
+
+	haveIDs, err := h.store.ListOrgMembers(ctx, org.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	have := map[int32]bool{}
+	for _, id := range haveIDs {
+		have[id] = true
+	}
+	want := map[int32]bool{}
+	added, removed := []int32{}, []int32{}
+	for _, id := range wantIDs {
+		want[id] = true
+		if !have[id] {
+			if err := h.store.AddOrgMember(ctx, org.ID, id); err != nil {
+				return 0, nil, err
+			}
+			added = append(added, id)
+		}
+	}
+	for _, id := range haveIDs {
+		if !want[id] {
+			if err := h.store.RemoveOrgMember(ctx, org.ID, id); err != nil {
+				return 0, nil, err
+			}
+			removed = append(removed, id)
+		}
+	}
+
+	if changed || len(added) > 0 || len(removed) > 0 {
+		h.store.LogSecurityEvent(ctx, securityEvent(database.SecurityEventNameSCIMGroupUpdated, r.URL.Path, 0, map[string]interface{}{
+			"orgID":          org.ID,
+			"name":           org.Name,
+			"displayName":    desired.DisplayName,
+			"membersAdded":   added,
+			"membersRemoved": removed,
+		}))
+	}
+
+	org, err = h.store.GetOrg(ctx, org.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	resource, err := h.toGroupResource(ctx, org)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, resource, nil
+}
+
+// memberIDs returns the sorted, distinct IDs of the users who are the members. It returns an
+// error if one of them doesn't exist.
+func (h *handler) memberIDs(ctx context.Context, members []groupMember) ([]int32, error) {
+	seen := map[int32]bool{}
+	var ids []int32
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 32)
+		if err != nil {
+			return nil, badRequest("invalidValue", "Invalid member %q.", m.Value)
+		}
+		if seen[int32(id)] {
+			continue
+		}
+		if _, err := h.store.GetUser(ctx, int32(id)); err != nil {
+			if errcode.IsNotFound(err) {
+				return nil, badRequest("invalidValue", "Member %q is not a user.", m.Value)
+			}
+			return nil, err
+		}
+		seen[int32(id)] = true
+		ids = append(ids, int32(id))
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+func (h *handler) deleteGroup(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	org, err := h.store.GetOrg(ctx, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := h.store.DeleteOrg(ctx, id); err != nil {
+		return 0, nil, err
+	}
+	h.store.LogSecurityEvent(ctx, securityEvent(database.SecurityEventNameSCIMGroupDeleted, r.URL.Path, 0, map[string]interface{}{
+		"orgID": org.ID,
+		"name":  org.Name,
+	}))
+	return http.StatusNoContent, nil, nil
+}
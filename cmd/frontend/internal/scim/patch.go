@@ -0,0 +1,201 @@
+package scim
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+type patchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// patchPath is the parsed path of a PATCH operation, like members, name.givenName or
+// emails[type eq "work"].value.
+type patchPath struct {
+	attr    string
+	filter  filter // matches the elements of a multi-valued attribute, if set
+	subAttr string
+}
+
+func parsePatchPath(s string) (*patchPath, error) {
+	i := strings.Index(s, "[")
+	if i == -1 {
+		p := parseAttrPath(s)
+		return &patchPath{attr: p.attr, subAttr: p.subAttr}, nil
+	}
+	j := strings.LastIndex(s, "]")
+	if j < i {
+		return nil, errors.Errorf("invalid path %q", s)
+	}
+	f, err := parseFilter(s[i+1 : j])
+	if err != nil {
+		return nil, err
+	}
+	p := &patchPath{attr: parseAttrPath(s[:i]).attr, filter: f}
+	if rest := s[j+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") {
+			return nil, errors.Errorf("invalid path %q", s)
+		}
+		p.subAttr = rest[1:]
+	}
+	return p, nil
+}
+
+// applyPatch applies the operations of a PATCH request (RFC 7644, section 3.5.2) to the JSON
+// representation of a resource.
+func applyPatch(resource map[string]interface{}, ops []patchOperation) error {
+	for _, op := range ops {
+		if err := applyPatchOperation(resource, op); err != nil {
+			return badRequest("invalidPath", "Invalid %s operation: %s", op.Op, err)
+		}
+	}
+	return nil
+}
+
+func applyPatchOperation(resource map[string]interface{}, op patchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return errors.Errorf("unknown op %q", op.Op)
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return errors.New("path is required")
+		}
+		values, ok := op.Value.(map[string]interface{})
+		if !ok {
+			return errors.New("value must be an object if there is no path")
+		}
+		for name, v := range values {
+			if err := applyPatchOperation(resource, patchOperation{Op: kind, Path: name, Value: v}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	if path.filter != nil {
+		return patchElements(resource, kind, path, op.Value)
+	}
+
+	if path.subAttr != "" {
+		obj, _ := getAttr(resource, path.attr).(map[string]interface{})
+		if kind == "remove" {
+			if obj != nil {
+				if k, ok := attrKey(obj, path.subAttr); ok {
+					delete(obj, k)
+				}
+			}
+			return nil
+		}
+		if obj == nil {
+			obj = map[string]interface{}{}
+			setAttr(resource, path.attr, obj)
+		}
+		setAttr(obj, path.subAttr, op.Value)
+		return nil
+	}
+
+	existing, isList := getAttr(resource, path.attr).([]interface{})
+	switch {
+	case kind == "add" && isList:
+		setAttr(resource, path.attr, append(existing, asList(op.Value)...))
+	case kind == "add" || kind == "replace":
+		setAttr(resource, path.attr, op.Value)
+	case kind == "remove" && isList && op.Value != nil:
+		// Some clients remove elements of a multi-valued attribute by listing them in the value
+		// instead of the path, e.g. {"op":"remove","path":"members","value":[{"value":"1"}]}.
+		remove := map[interface{}]bool{}
+		for _, v := range asList(op.Value) {
+			if m, ok := v.(map[string]interface{}); ok {
+				remove[getAttr(m, "value")] = true
+			}
+		}
+		var kept []interface{}
+		for _, v := range existing {
+			if m, ok := v.(map[string]interface{}); !ok || !remove[getAttr(m, "value")] {
+				kept = append(kept, v)
+			}
+		}
+		setAttr(resource, path.attr, kept)
+	case kind == "remove":
+		if k, ok := attrKey(resource, path.attr); ok {
+			delete(resource, k)
+		}
+	}
+	return nil
+}
+
+// patchElements applies an operation to the elements of a multi-valued attribute that match the
+// filter of the path.
+func patchElements(resource map[string]interface{}, kind string, path *patchPath, value interface{}) error {
+	elements, _ := getAttr(resource, path.attr).([]interface{})
+	var (
+		kept    []interface{}
+		matched bool
+	)
+	for _, e := range elements {
+		m, ok := e.(map[string]interface{})
+		if !ok || !path.filter.match(m) {
+			kept = append(kept, e)
+			continue
+		}
+		matched = true
+		switch {
+		case kind == "remove" && path.subAttr == "":
+			continue
+		case kind == "remove":
+			if k, ok := attrKey(m, path.subAttr); ok {
+				delete(m, k)
+			}
+		case path.subAttr != "":
+			setAttr(m, path.subAttr, value)
+		default:
+			v, ok := value.(map[string]interface{})
+			if !ok {
+				return errors.New("value must be an object")
+			}
+			if kind == "replace" {
+				m = map[string]interface{}{}
+			}
+			for k, vv := range v {
+				setAttr(m, k, vv)
+			}
+		}
+		kept = append(kept, m)
+	}
+
+	// Some clients set a sub-attribute of an element that doesn't exist yet, e.g.
+	// {"op":"replace","path":"emails[type eq \"work\"].value","value":"alice@example.com"}, in
+	// which case the element is added.
+	if !matched && kind != "remove" {
+		attr, filterValue, ok := eqValue(path.filter)
+		if !ok || path.subAttr == "" {
+			return errors.New("no element matches the filter")
+		}
+		kept = append(kept, map[string]interface{}{attr: filterValue, path.subAttr: value})
+	}
+
+	setAttr(resource, path.attr, kept)
+	return nil
+}
+
+// setAttr sets the attribute of m, whose name is matched case-insensitively.
+func setAttr(m map[string]interface{}, name string, v interface{}) {
+	if k, ok := attrKey(m, name); ok {
+		name = k
+	}
+	m[name] = v
+}
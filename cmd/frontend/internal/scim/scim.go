@@ -0,0 +1,308 @@
+// Package scim implements a SCIM 2.0 (RFC 7643 and RFC 7644) API, which identity providers use
+// to provision and deprovision users (as SCIM Users) and organizations (as SCIM Groups).
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// PathPrefix is the path at which the SCIM API is served.
+const PathPrefix = "/.api/scim/v2"
+
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+const (
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+// NewHandler returns the handler for the SCIM API, which is mounted at PathPrefix.
+//
+// 🚨 SECURITY: The handler implements its own authentication with the token in the "auth.scim"
+// site configuration. Requests are performed with an internal actor.
+func NewHandler(db dbutil.DB) http.Handler {
+	return newHandler(dbStore{db: db})
+}
+
+type handler struct {
+	store  store
+	router *mux.Router
+}
+
+func newHandler(s store) http.Handler {
+	h := &handler{store: s}
+
+	r := mux.NewRouter().PathPrefix(PathPrefix).Subrouter()
+	r.StrictSlash(true)
+	r.Path("/ServiceProviderConfig").Methods("GET").HandlerFunc(h.serveServiceProviderConfig)
+	r.Path("/ResourceTypes").Methods("GET").HandlerFunc(h.serveResourceTypes)
+	r.Path("/Users").Methods("GET").Handler(h.handle(h.listUsers))
+	r.Path("/Users").Methods("POST").Handler(h.handle(h.createUser))
+	r.Path("/Users/{id}").Methods("GET").Handler(h.handle(h.getUser))
+	r.Path("/Users/{id}").Methods("PUT").Handler(h.handle(h.replaceUser))
+	r.Path("/Users/{id}").Methods("PATCH").Handler(h.handle(h.patchUser))
+	r.Path("/Users/{id}").Methods("DELETE").Handler(h.handle(h.deleteUser))
+	r.Path("/Groups").Methods("GET").Handler(h.handle(h.listGroups))
+	r.Path("/Groups").Methods("POST").Handler(h.handle(h.createGroup))
+	r.Path("/Groups/{id}").Methods("GET").Handler(h.handle(h.getGroup))
+	r.Path("/Groups/{id}").Methods("PUT").Handler(h.handle(h.replaceGroup))
+	r.Path("/Groups/{id}").Methods("PATCH").Handler(h.handle(h.patchGroup))
+	r.Path("/Groups/{id}").Methods("DELETE").Handler(h.handle(h.deleteGroup))
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &scimError{status: http.StatusNotFound, detail: "Unknown SCIM endpoint."})
+	})
+	r.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, &scimError{status: http.StatusMethodNotAllowed, detail: "Method not allowed."})
+	})
+	h.router = r
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cfg := conf.Get().AuthScim
+	if cfg == nil || cfg.Token == "" {
+		writeError(w, &scimError{status: http.StatusNotFound, detail: "The SCIM API is not enabled. Set auth.scim in the site configuration to enable it."})
+		return
+	}
+
+	// 🚨 SECURITY: Compare the token in constant time to not leak it through timing.
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+		writeError(w, &scimError{status: http.StatusUnauthorized, detail: "Invalid or missing SCIM bearer token."})
+		return
+	}
+
+	// The identity provider isn't a Sourcegraph user, so the requests are performed with an
+	// internal actor.
+	h.router.ServeHTTP(w, r.WithContext(actor.WithInternalActor(r.Context())))
+}
+
+// handle adapts a SCIM handler function, which returns the response status and body, into an
+// http.Handler.
+func (h *handler) handle(fn func(r *http.Request) (int, interface{}, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, body, err := fn(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, status, body)
+	})
+}
+
+// scimError is an error that is returned to the client as a SCIM error response.
+type scimError struct {
+	status   int
+	scimType string // e.g. "invalidFilter", "uniqueness" (see RFC 7644, section 3.12)
+	detail   string
+}
+
+func (e *scimError) Error() string { return e.detail }
+
+func badRequest(scimType, format string, args ...interface{}) error {
+	return &scimError{status: http.StatusBadRequest, scimType: scimType, detail: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &scimError{status: http.StatusNotFound, detail: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...interface{}) error {
+	return &scimError{status: http.StatusConflict, scimType: "uniqueness", detail: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*scimError)
+	if !ok {
+		if errcode.IsNotFound(err) {
+			e = &scimError{status: http.StatusNotFound, detail: err.Error()}
+		} else {
+			log15.Error("SCIM request failed", "err", err)
+			e = &scimError{status: http.StatusInternalServerError, detail: "Internal server error."}
+		}
+	}
+	writeJSON(w, e.status, struct {
+		Schemas  []string `json:"schemas"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail"`
+		Status   string   `json:"status"`
+	}{
+		Schemas:  []string{schemaError},
+		ScimType: e.scimType,
+		Detail:   e.detail,
+		Status:   strconv.Itoa(e.status),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log15.Error("Failed to write SCIM response", "err", err)
+	}
+}
+
+// decodeBody decodes the JSON request body into v.
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest("invalidSyntax", "Invalid request body: %s", err)
+	}
+	return nil
+}
+
+// resourceID parses the ID of the resource in the URL of the request.
+func resourceID(r *http.Request) (int32, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, notFound("Resource %q not found.", mux.Vars(r)["id"])
+	}
+	return int32(id), nil
+}
+
+type meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+func location(resourceType string, id int32) string {
+	return strings.TrimSuffix(globals.ExternalURL().String(), "/") + PathPrefix + "/" + resourceType + "/" + strconv.Itoa(int(id))
+}
+
+type listResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// listParams are the query parameters of a list request.
+type listParams struct {
+	filter     filter // nil if there is no filter
+	startIndex int    // 1-based
+	count      int
+}
+
+func parseListParams(r *http.Request) (*listParams, error) {
+	q := r.URL.Query()
+	p := &listParams{startIndex: 1, count: defaultCount}
+	if v := q.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, badRequest("invalidValue", "Invalid startIndex %q.", v)
+		}
+		if n > 1 {
+			p.startIndex = n
+		}
+	}
+	if v := q.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, badRequest("invalidValue", "Invalid count %q.", v)
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n > maxCount {
+			n = maxCount
+		}
+		p.count = n
+	}
+	if v := q.Get("filter"); v != "" {
+		f, err := parseFilter(v)
+		if err != nil {
+			return nil, badRequest("invalidFilter", "Invalid filter %q: %s", v, err)
+		}
+		p.filter = f
+	}
+	return p, nil
+}
+
+// page returns the resources in the page of all (which matched the filter) that the params
+// request.
+func (p *listParams) page(all []interface{}) []interface{} {
+	start := p.startIndex - 1
+	if start >= len(all) {
+		return []interface{}{}
+	}
+	end := start + p.count
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end]
+}
+
+func (h *handler) serveServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	type supported struct {
+		Supported bool `json:"supported"`
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schemas":          []string{schemaServiceProviderConfig},
+		"documentationUri": "https://docs.sourcegraph.com/admin/auth/scim",
+		"patch":            supported{true},
+		"bulk":             map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]interface{}{"supported": true, "maxResults": maxCount},
+		"changePassword":   supported{false},
+		"sort":             supported{false},
+		"etag":             supported{false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the token in the auth.scim site configuration.",
+			"primary":     true,
+		}},
+	})
+}
+
+func (h *handler) serveResourceTypes(w http.ResponseWriter, r *http.Request) {
+	resourceType := func(name, endpoint, schema string) map[string]interface{} {
+		return map[string]interface{}{
+			"schemas":  []string{schemaResourceType},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+		}
+	}
+	resources := []interface{}{
+		resourceType("User", "/Users", schemaUser),
+		resourceType("Group", "/Groups", schemaGroup),
+	}
+	writeJSON(w, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
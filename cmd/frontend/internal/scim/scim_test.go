@@ -0,0 +1,559 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const testToken = "0123456789abcdef0123456789abcdef"
+
+func TestHandler_auth(t *testing.T) {
+	h := newHandler(newFakeStore())
+
+	conf.Mock(&conf.Unified{})
+	defer conf.Mock(nil)
+	if rec := do(h, "GET", "/Users", testToken, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("disabled: got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: &schema.AuthScim{Token: testToken}}})
+	for _, token := range []string{"", "wrong"} {
+		if rec := do(h, "GET", "/Users", token, ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: got status %d, want %d", token, rec.Code, http.StatusUnauthorized)
+		}
+	}
+	if rec := do(h, "GET", "/Users", testToken, ""); rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
+
+func TestHandler_users(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: &schema.AuthScim{Token: testToken}}})
+	defer conf.Mock(nil)
+	s := newFakeStore()
+	h := newHandler(s)
+
+	var created userResource
+	rec := do(h, "POST", "/Users", testToken, `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "alice@example.com",
+		"name": {"givenName": "Alice", "familyName": "Smith"},
+		"emails": [{"value": "alice@example.com", "primary": true}, {"value": "a@example.org"}],
+		"active": true
+	}`)
+	decode(t, rec, http.StatusCreated, &created)
+	if created.UserName != "alice" || created.DisplayName != "Alice Smith" {
+		t.Errorf("got userName %q and displayName %q", created.UserName, created.DisplayName)
+	}
+	if got, want := s.emailsOf(1), []string{"*alice@example.com", "a@example.org"}; !cmp.Equal(got, want) {
+		t.Errorf("emails: %s", cmp.Diff(want, got))
+	}
+
+	t.Run("create existing", func(t *testing.T) {
+		rec := do(h, "POST", "/Users", testToken, `{"userName": "alice@example.com"}`)
+		if rec.Code != http.StatusConflict {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("filter by userName", func(t *testing.T) {
+		for _, userName := range []string{"alice", "ALICE@example.com"} {
+			var list listResponse
+			decode(t, do(h, "GET", "/Users?filter="+urlQuote(`userName eq "`+userName+`"`), testToken, ""), http.StatusOK, &list)
+			if list.TotalResults != 1 {
+				t.Errorf("%s: got %d results, want 1", userName, list.TotalResults)
+			}
+		}
+		var list listResponse
+		decode(t, do(h, "GET", "/Users?filter="+urlQuote(`userName eq "bob"`), testToken, ""), http.StatusOK, &list)
+		if list.TotalResults != 0 || len(list.Resources) != 0 {
+			t.Errorf("got %d results, want 0", list.TotalResults)
+		}
+	})
+
+	t.Run("filter by email", func(t *testing.T) {
+		var list listResponse
+		decode(t, do(h, "GET", "/Users?filter="+urlQuote(`emails[value co "example.org"] and active eq true`), testToken, ""), http.StatusOK, &list)
+		if list.TotalResults != 1 {
+			t.Errorf("got %d results, want 1", list.TotalResults)
+		}
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		var patched userResource
+		rec := do(h, "PATCH", "/Users/1", testToken, `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "Replace", "path": "displayName", "value": "Alice Jones"},
+				{"op": "remove", "path": "emails[value eq \"a@example.org\"]"},
+				{"op": "add", "path": "emails", "value": [{"value": "alice@example.net"}]}
+			]
+		}`)
+		decode(t, rec, http.StatusOK, &patched)
+		if patched.DisplayName != "Alice Jones" {
+			t.Errorf("got displayName %q", patched.DisplayName)
+		}
+		if got, want := s.emailsOf(1), []string{"*alice@example.com", "alice@example.net"}; !cmp.Equal(got, want) {
+			t.Errorf("emails: %s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("deactivate", func(t *testing.T) {
+		var patched userResource
+		rec := do(h, "PATCH", "/Users/1", testToken, `{"Operations": [{"op": "replace", "value": {"active": "False"}}]}`)
+		decode(t, rec, http.StatusOK, &patched)
+		if patched.Active == nil || *patched.Active {
+			t.Errorf("got active %v, want false", patched.Active)
+		}
+
+		// The user and their email addresses are kept.
+		var got userResource
+		decode(t, do(h, "GET", "/Users/1", testToken, ""), http.StatusOK, &got)
+		if got.Active == nil || *got.Active || len(got.Emails) != 2 {
+			t.Errorf("got active %v and emails %v, want inactive user with 2 emails", got.Active, got.Emails)
+		}
+		var list listResponse
+		decode(t, do(h, "GET", "/Users?filter="+urlQuote(`active eq false`), testToken, ""), http.StatusOK, &list)
+		if list.TotalResults != 1 {
+			t.Errorf("got %d inactive users, want 1", list.TotalResults)
+		}
+	})
+
+	t.Run("reactivate", func(t *testing.T) {
+		var replaced userResource
+		rec := do(h, "PUT", "/Users/1", testToken, `{"userName": "alice", "active": true}`)
+		decode(t, rec, http.StatusOK, &replaced)
+		if replaced.Active == nil || !*replaced.Active {
+			t.Errorf("got active %v, want true", replaced.Active)
+		}
+		if s.users[1].DeactivatedAt != nil {
+			t.Error("user is still deactivated")
+		}
+	})
+
+	want := []database.SecurityEventName{
+		database.SecurityEventNameSCIMUserCreated,
+		database.SecurityEventNameSCIMUserUpdated,
+		database.SecurityEventNameSCIMUserDeactivated,
+		database.SecurityEventNameSCIMUserReactivated,
+	}
+	if diff := cmp.Diff(want, s.eventNames()); diff != "" {
+		t.Errorf("security events (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandler_lastSiteAdmin(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: &schema.AuthScim{Token: testToken}}})
+	defer conf.Mock(nil)
+	s := newFakeStore()
+	h := newHandler(s)
+	for _, name := range []string{"admin", "other"} {
+		if _, err := s.CreateUser(context.Background(), database.NewUser{Username: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.users[1].SiteAdmin = true
+
+	for _, req := range []struct{ method, body string }{
+		{"PATCH", `{"Operations": [{"op": "replace", "path": "active", "value": false}]}`},
+		{"PUT", `{"userName": "admin", "displayName": "Changed", "active": false}`},
+		{"DELETE", ""},
+	} {
+		if rec := do(h, req.method, "/Users/1", testToken, req.body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d: %s", req.method, rec.Code, http.StatusBadRequest, rec.Body)
+		}
+	}
+	if u := s.users[1]; u.DeactivatedAt != nil || u.DisplayName != "" {
+		t.Errorf("last site admin was changed: %+v", u)
+	}
+
+	// Once there is another site admin, the first one can be deactivated.
+	s.users[2].SiteAdmin = true
+	if rec := do(h, "PATCH", "/Users/1", testToken, `{"Operations": [{"op": "replace", "path": "active", "value": false}]}`); rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	if rec := do(h, "DELETE", "/Users/2", testToken, ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+	}
+}
+
+func TestHandler_groups(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: &schema.AuthScim{Token: testToken}}})
+	defer conf.Mock(nil)
+	s := newFakeStore()
+	for _, name := range []string{"alice", "bob", "carol"} {
+		if _, err := s.CreateUser(context.Background(), database.NewUser{Username: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := newHandler(s)
+
+	var created groupResource
+	rec := do(h, "POST", "/Groups", testToken, `{"displayName": "Engineering Team", "members": [{"value": "1"}, {"value": "2"}]}`)
+	decode(t, rec, http.StatusCreated, &created)
+	if created.ID != "1" || created.DisplayName != "Engineering Team" {
+		t.Errorf("got id %q and displayName %q", created.ID, created.DisplayName)
+	}
+	if got := s.orgs[1].Name; got != "Engineering-Team" {
+		t.Errorf("got org name %q", got)
+	}
+	if got, want := s.members[1], []int32{1, 2}; !cmp.Equal(got, want) {
+		t.Errorf("members: %s", cmp.Diff(want, got))
+	}
+
+	t.Run("unknown member", func(t *testing.T) {
+		rec := do(h, "POST", "/Groups", testToken, `{"displayName": "x", "members": [{"value": "42"}]}`)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", rec.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("patch members", func(t *testing.T) {
+		rec := do(h, "PATCH", "/Groups/1", testToken, `{"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": "3"}]},
+			{"op": "remove", "path": "members[value eq \"1\"]"}
+		]}`)
+		decode(t, rec, http.StatusOK, &groupResource{})
+		if got, want := s.members[1], []int32{2, 3}; !cmp.Equal(got, want) {
+			t.Errorf("members: %s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		var replaced groupResource
+		rec := do(h, "PUT", "/Groups/1", testToken, `{"displayName": "Engineering", "members": [{"value": "1"}]}`)
+		decode(t, rec, http.StatusOK, &replaced)
+		if replaced.DisplayName != "Engineering" {
+			t.Errorf("got displayName %q", replaced.DisplayName)
+		}
+		if got, want := s.members[1], []int32{1}; !cmp.Equal(got, want) {
+			t.Errorf("members: %s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		var list listResponse
+		decode(t, do(h, "GET", "/Groups?filter="+urlQuote(`displayName eq "engineering"`), testToken, ""), http.StatusOK, &list)
+		if list.TotalResults != 1 {
+			t.Errorf("got %d results, want 1", list.TotalResults)
+		}
+	})
+
+	if rec := do(h, "DELETE", "/Groups/1", testToken, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("delete: got status %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if rec := do(h, "GET", "/Groups/1", testToken, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	want := []database.SecurityEventName{
+		database.SecurityEventNameSCIMGroupCreated,
+		database.SecurityEventNameSCIMGroupUpdated,
+		database.SecurityEventNameSCIMGroupUpdated,
+		database.SecurityEventNameSCIMGroupDeleted,
+	}
+	if diff := cmp.Diff(want, s.eventNames()); diff != "" {
+		t.Errorf("security events (-want +got):\n%s", diff)
+	}
+}
+
+func do(h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, PathPrefix+path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, wantStatus int, v interface{}) {
+	t.Helper()
+	if rec.Code != wantStatus {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, wantStatus, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func urlQuote(s string) string {
+	return strings.NewReplacer(" ", "%20", `"`, "%22", "[", "%5B", "]", "%5D").Replace(s)
+}
+
+type notFoundErr struct{}
+
+func (notFoundErr) Error() string  { return "not found" }
+func (notFoundErr) NotFound() bool { return true }
+
+// fakeStore is an in-memory store.
+type fakeStore struct {
+	users   map[int32]*types.User
+	emails  map[int32][]*database.UserEmail
+	orgs    map[int32]*types.Org
+	members map[int32][]int32
+	events  []*database.SecurityEvent
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:   map[int32]*types.User{},
+		emails:  map[int32][]*database.UserEmail{},
+		orgs:    map[int32]*types.Org{},
+		members: map[int32][]int32{},
+	}
+}
+
+// emailsOf returns the sorted email addresses of the user, with the primary one prefixed by "*".
+func (s *fakeStore) emailsOf(userID int32) []string {
+	var emails []string
+	for _, e := range s.emails[userID] {
+		if e.Primary {
+			emails = append(emails, "*"+e.Email)
+		} else {
+			emails = append(emails, e.Email)
+		}
+	}
+	sort.Strings(emails)
+	return emails
+}
+
+func (s *fakeStore) eventNames() []database.SecurityEventName {
+	var names []database.SecurityEventName
+	for _, e := range s.events {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+func (s *fakeStore) GetUser(_ context.Context, id int32) (*types.User, error) {
+	if u, ok := s.users[id]; ok {
+		return u, nil
+	}
+	return nil, notFoundErr{}
+}
+
+func (s *fakeStore) GetUserByUsername(_ context.Context, username string) (*types.User, error) {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Username, username) {
+			return u, nil
+		}
+	}
+	return nil, notFoundErr{}
+}
+
+func (s *fakeStore) GetUserByVerifiedEmail(_ context.Context, email string) (*types.User, error) {
+	for userID, emails := range s.emails {
+		for _, e := range emails {
+			if strings.EqualFold(e.Email, email) {
+				return s.users[userID], nil
+			}
+		}
+	}
+	return nil, notFoundErr{}
+}
+
+func (s *fakeStore) ListUsers(_ context.Context, opt *database.UsersListOptions) ([]*types.User, error) {
+	var users []*types.User
+	for id, u := range s.users {
+		if opt == nil || opt.UserIDs == nil || containsID(opt.UserIDs, id) {
+			users = append(users, u)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (s *fakeStore) CountUsers(ctx context.Context) (int, error) { return len(s.users), nil }
+
+func (s *fakeStore) CreateUser(_ context.Context, info database.NewUser) (*types.User, error) {
+	id := int32(len(s.users) + 1)
+	for s.users[id] != nil {
+		id++
+	}
+	u := &types.User{ID: id, Username: info.Username, DisplayName: info.DisplayName}
+	s.users[id] = u
+	if info.Email != "" {
+		s.emails[id] = []*database.UserEmail{{UserID: id, Email: info.Email, Primary: true}}
+	}
+	return u, nil
+}
+
+func (s *fakeStore) UpdateUser(_ context.Context, id int32, update database.UserUpdate) error {
+	u, ok := s.users[id]
+	if !ok {
+		return notFoundErr{}
+	}
+	if update.Username != "" {
+		u.Username = update.Username
+	}
+	if update.DisplayName != nil {
+		u.DisplayName = *update.DisplayName
+	}
+	return nil
+}
+
+func (s *fakeStore) DeleteUser(_ context.Context, id int32) error {
+	if _, ok := s.users[id]; !ok {
+		return notFoundErr{}
+	}
+	delete(s.users, id)
+	delete(s.emails, id)
+	return nil
+}
+
+func (s *fakeStore) SetUserDeactivated(_ context.Context, id int32, deactivated bool) error {
+	u, ok := s.users[id]
+	if !ok {
+		return notFoundErr{}
+	}
+	u.DeactivatedAt = nil
+	if deactivated {
+		now := time.Now()
+		u.DeactivatedAt = &now
+	}
+	return nil
+}
+
+func (s *fakeStore) CountActiveSiteAdmins(context.Context) (int, error) {
+	n := 0
+	for _, u := range s.users {
+		if u.SiteAdmin && u.DeactivatedAt == nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *fakeStore) ListUserEmails(_ context.Context, userID int32) ([]*database.UserEmail, error) {
+	return s.emails[userID], nil
+}
+
+func (s *fakeStore) ListUserEmailsByUsers(_ context.Context, userIDs []int32) (map[int32][]*database.UserEmail, error) {
+	emails := map[int32][]*database.UserEmail{}
+	for _, id := range userIDs {
+		emails[id] = s.emails[id]
+	}
+	return emails, nil
+}
+
+func (s *fakeStore) AddUserEmail(_ context.Context, userID int32, email string) error {
+	s.emails[userID] = append(s.emails[userID], &database.UserEmail{UserID: userID, Email: email})
+	return nil
+}
+
+func (s *fakeStore) RemoveUserEmail(_ context.Context, userID int32, email string) error {
+	var kept []*database.UserEmail
+	for _, e := range s.emails[userID] {
+		if e.Email != email {
+			kept = append(kept, e)
+		}
+	}
+	s.emails[userID] = kept
+	return nil
+}
+
+func (s *fakeStore) SetPrimaryUserEmail(_ context.Context, userID int32, email string) error {
+	for _, e := range s.emails[userID] {
+		e.Primary = e.Email == email
+	}
+	return nil
+}
+
+func (s *fakeStore) GetOrg(_ context.Context, id int32) (*types.Org, error) {
+	if org, ok := s.orgs[id]; ok {
+		return org, nil
+	}
+	return nil, notFoundErr{}
+}
+
+func (s *fakeStore) GetOrgByName(_ context.Context, name string) (*types.Org, error) {
+	for _, org := range s.orgs {
+		if strings.EqualFold(org.Name, name) {
+			return org, nil
+		}
+	}
+	return nil, notFoundErr{}
+}
+
+func (s *fakeStore) ListOrgs(_ context.Context, opt *database.OrgsListOptions) ([]*types.Org, error) {
+	var orgs []*types.Org
+	for _, org := range s.orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+	return orgs, nil
+}
+
+func (s *fakeStore) CountOrgs(ctx context.Context) (int, error) { return len(s.orgs), nil }
+
+func (s *fakeStore) CreateOrg(_ context.Context, name string, displayName *string) (*types.Org, error) {
+	org := &types.Org{ID: int32(len(s.orgs) + 1), Name: name, DisplayName: displayName}
+	s.orgs[org.ID] = org
+	return org, nil
+}
+
+func (s *fakeStore) UpdateOrg(_ context.Context, id int32, displayName *string) error {
+	org, ok := s.orgs[id]
+	if !ok {
+		return notFoundErr{}
+	}
+	org.DisplayName = displayName
+	return nil
+}
+
+func (s *fakeStore) DeleteOrg(_ context.Context, id int32) error {
+	if _, ok := s.orgs[id]; !ok {
+		return notFoundErr{}
+	}
+	delete(s.orgs, id)
+	delete(s.members, id)
+	return nil
+}
+
+func (s *fakeStore) ListOrgMembers(_ context.Context, orgID int32) ([]int32, error) {
+	return s.members[orgID], nil
+}
+
+func (s *fakeStore) AddOrgMember(_ context.Context, orgID, userID int32) error {
+	s.members[orgID] = append(s.members[orgID], userID)
+	sort.Slice(s.members[orgID], func(i, j int) bool { return s.members[orgID][i] < s.members[orgID][j] })
+	return nil
+}
+
+func (s *fakeStore) RemoveOrgMember(_ context.Context, orgID, userID int32) error {
+	var kept []int32
+	for _, id := range s.members[orgID] {
+		if id != userID {
+			kept = append(kept, id)
+		}
+	}
+	s.members[orgID] = kept
+	return nil
+}
+
+func (s *fakeStore) LogSecurityEvent(_ context.Context, e *database.SecurityEvent) {
+	s.events = append(s.events, e)
+}
+
+func containsID(ids []int32, id int32) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
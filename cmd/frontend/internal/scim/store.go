@@ -0,0 +1,201 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// store is the persistence used by the SCIM handlers. Users are stored as Sourcegraph users and
+// groups as organizations.
+type store interface {
+	GetUser(ctx context.Context, id int32) (*types.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*types.User, error)
+	GetUserByVerifiedEmail(ctx context.Context, email string) (*types.User, error)
+	ListUsers(ctx context.Context, opt *database.UsersListOptions) ([]*types.User, error)
+	CountUsers(ctx context.Context) (int, error)
+	CreateUser(ctx context.Context, info database.NewUser) (*types.User, error)
+	UpdateUser(ctx context.Context, id int32, update database.UserUpdate) error
+	DeleteUser(ctx context.Context, id int32) error
+	SetUserDeactivated(ctx context.Context, id int32, deactivated bool) error
+	CountActiveSiteAdmins(ctx context.Context) (int, error)
+
+	ListUserEmails(ctx context.Context, userID int32) ([]*database.UserEmail, error)
+	ListUserEmailsByUsers(ctx context.Context, userIDs []int32) (map[int32][]*database.UserEmail, error)
+	AddUserEmail(ctx context.Context, userID int32, email string) error
+	RemoveUserEmail(ctx context.Context, userID int32, email string) error
+	SetPrimaryUserEmail(ctx context.Context, userID int32, email string) error
+
+	GetOrg(ctx context.Context, id int32) (*types.Org, error)
+	GetOrgByName(ctx context.Context, name string) (*types.Org, error)
+	ListOrgs(ctx context.Context, opt *database.OrgsListOptions) ([]*types.Org, error)
+	CountOrgs(ctx context.Context) (int, error)
+	CreateOrg(ctx context.Context, name string, displayName *string) (*types.Org, error)
+	UpdateOrg(ctx context.Context, id int32, displayName *string) error
+	DeleteOrg(ctx context.Context, id int32) error
+
+	ListOrgMembers(ctx context.Context, orgID int32) ([]int32, error)
+	AddOrgMember(ctx context.Context, orgID, userID int32) error
+	RemoveOrgMember(ctx context.Context, orgID, userID int32) error
+
+	LogSecurityEvent(ctx context.Context, e *database.SecurityEvent)
+}
+
+type dbStore struct {
+	db dbutil.DB
+}
+
+var _ store = dbStore{}
+
+func (s dbStore) GetUser(ctx context.Context, id int32) (*types.User, error) {
+	return database.Users(s.db).GetByID(ctx, id)
+}
+
+func (s dbStore) GetUserByUsername(ctx context.Context, username string) (*types.User, error) {
+	return database.Users(s.db).GetByUsername(ctx, username)
+}
+
+func (s dbStore) GetUserByVerifiedEmail(ctx context.Context, email string) (*types.User, error) {
+	return database.Users(s.db).GetByVerifiedEmail(ctx, email)
+}
+
+func (s dbStore) ListUsers(ctx context.Context, opt *database.UsersListOptions) ([]*types.User, error) {
+	return database.Users(s.db).List(ctx, opt)
+}
+
+func (s dbStore) CountUsers(ctx context.Context) (int, error) {
+	return database.Users(s.db).Count(ctx, nil)
+}
+
+func (s dbStore) CreateUser(ctx context.Context, info database.NewUser) (*types.User, error) {
+	return database.Users(s.db).Create(ctx, info)
+}
+
+func (s dbStore) UpdateUser(ctx context.Context, id int32, update database.UserUpdate) error {
+	return database.Users(s.db).Update(ctx, id, update)
+}
+
+func (s dbStore) DeleteUser(ctx context.Context, id int32) error {
+	return database.Users(s.db).Delete(ctx, id)
+}
+
+func (s dbStore) SetUserDeactivated(ctx context.Context, id int32, deactivated bool) error {
+	return database.Users(s.db).SetDeactivated(ctx, id, deactivated)
+}
+
+func (s dbStore) CountActiveSiteAdmins(ctx context.Context) (int, error) {
+	return backend.CountActiveSiteAdmins(ctx, s.db)
+}
+
+func (s dbStore) ListUserEmails(ctx context.Context, userID int32) ([]*database.UserEmail, error) {
+	return database.UserEmails(s.db).ListByUser(ctx, database.UserEmailsListOptions{UserID: userID})
+}
+
+func (s dbStore) ListUserEmailsByUsers(ctx context.Context, userIDs []int32) (map[int32][]*database.UserEmail, error) {
+	emails, err := database.UserEmails(s.db).ListByUsers(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[int32][]*database.UserEmail, len(userIDs))
+	for _, e := range emails {
+		byUser[e.UserID] = append(byUser[e.UserID], e)
+	}
+	return byUser, nil
+}
+
+// AddUserEmail adds a verified email address to the user. The identity provider is trusted to
+// have verified it.
+func (s dbStore) AddUserEmail(ctx context.Context, userID int32, email string) error {
+	if err := database.UserEmails(s.db).Add(ctx, userID, email, nil); err != nil {
+		return err
+	}
+	return database.UserEmails(s.db).SetVerified(ctx, userID, email, true)
+}
+
+func (s dbStore) RemoveUserEmail(ctx context.Context, userID int32, email string) error {
+	return database.UserEmails(s.db).Remove(ctx, userID, email)
+}
+
+func (s dbStore) SetPrimaryUserEmail(ctx context.Context, userID int32, email string) error {
+	return database.UserEmails(s.db).SetPrimaryEmail(ctx, userID, email)
+}
+
+func (s dbStore) GetOrg(ctx context.Context, id int32) (*types.Org, error) {
+	return database.Orgs(s.db).GetByID(ctx, id)
+}
+
+func (s dbStore) GetOrgByName(ctx context.Context, name string) (*types.Org, error) {
+	return database.Orgs(s.db).GetByName(ctx, name)
+}
+
+func (s dbStore) ListOrgs(ctx context.Context, opt *database.OrgsListOptions) ([]*types.Org, error) {
+	return database.Orgs(s.db).List(ctx, opt)
+}
+
+func (s dbStore) CountOrgs(ctx context.Context) (int, error) {
+	return database.Orgs(s.db).Count(ctx, database.OrgsListOptions{})
+}
+
+func (s dbStore) CreateOrg(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+	return database.Orgs(s.db).Create(ctx, name, displayName)
+}
+
+func (s dbStore) UpdateOrg(ctx context.Context, id int32, displayName *string) error {
+	_, err := database.Orgs(s.db).Update(ctx, id, displayName)
+	return err
+}
+
+func (s dbStore) DeleteOrg(ctx context.Context, id int32) error {
+	return database.Orgs(s.db).Delete(ctx, id)
+}
+
+func (s dbStore) ListOrgMembers(ctx context.Context, orgID int32) ([]int32, error) {
+	members, err := database.OrgMembers(s.db).GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]int32, 0, len(members))
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID)
+	}
+	return userIDs, nil
+}
+
+func (s dbStore) AddOrgMember(ctx context.Context, orgID, userID int32) error {
+	_, err := database.OrgMembers(s.db).Create(ctx, orgID, userID)
+	return err
+}
+
+func (s dbStore) RemoveOrgMember(ctx context.Context, orgID, userID int32) error {
+	return database.OrgMembers(s.db).Remove(ctx, orgID, userID)
+}
+
+// LogSecurityEvent records a change made through the SCIM API. Unlike
+// (*database.SecurityEventLogStore).LogEvent, it records events on all instances, because the
+// SCIM API is how identity providers manage accounts on private instances.
+func (s dbStore) LogSecurityEvent(ctx context.Context, e *database.SecurityEvent) {
+	if err := database.SecurityEventLogs(s.db).Insert(ctx, e); err != nil {
+		log15.Error(string(e.Name), "err", err)
+	}
+}
+
+// securityEvent returns a security event for a change made through the SCIM API to the resource
+// at url.
+func securityEvent(name database.SecurityEventName, url string, userID int32, argument interface{}) *database.SecurityEvent {
+	arg, _ := json.Marshal(argument)
+	return &database.SecurityEvent{
+		Name:      name,
+		URL:       url,
+		UserID:    uint32(userID),
+		Argument:  arg,
+		Source:    "BACKEND",
+		Timestamp: time.Now(),
+	}
+}
@@ -0,0 +1,575 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// userResource is the SCIM representation of a Sourcegraph user.
+type userResource struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *userName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []userEmail `json:"emails,omitempty"`
+	Active      *scimBool   `json:"active,omitempty"`
+	Meta        *meta       `json:"meta,omitempty"`
+}
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type userEmail struct {
+	Value   string   `json:"value"`
+	Type    string   `json:"type,omitempty"`
+	Primary scimBool `json:"primary,omitempty"`
+}
+
+// scimBool is a boolean that is also decoded from the strings "true" and "false", which some
+// identity providers send.
+type scimBool bool
+
+func (b *scimBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case bool:
+		*b = scimBool(v)
+	case string:
+		parsed, err := strconv.ParseBool(strings.ToLower(v))
+		if err != nil {
+			return errors.Errorf("invalid boolean %q", v)
+		}
+		*b = scimBool(parsed)
+	case nil:
+		*b = false
+	default:
+		return errors.Errorf("invalid boolean %v", v)
+	}
+	return nil
+}
+
+func boolPtr(b bool) *scimBool {
+	v := scimBool(b)
+	return &v
+}
+
+// primaryEmail returns the primary email address of the user, which is the one marked as primary,
+// or else the first one, or else the username if it is an email address.
+func (u *userResource) primaryEmail() string {
+	for _, e := range u.Emails {
+		if e.Primary && e.Value != "" {
+			return e.Value
+		}
+	}
+	for _, e := range u.Emails {
+		if e.Value != "" {
+			return e.Value
+		}
+	}
+	if strings.Count(u.UserName, "@") == 1 {
+		return u.UserName
+	}
+	return ""
+}
+
+// displayNames returns the candidates for the display name of the user, in order of preference.
+func (u *userResource) displayNames() []string {
+	names := []string{u.DisplayName}
+	if u.Name != nil {
+		names = append(names, u.Name.Formatted, strings.TrimSpace(u.Name.GivenName+" "+u.Name.FamilyName))
+	}
+	return names
+}
+
+func (h *handler) toUserResource(ctx context.Context, u *types.User) (*userResource, error) {
+	emails, err := h.store.ListUserEmails(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return newUserResource(u, emails), nil
+}
+
+// toUserResources returns the resources of the users, fetching their email addresses in one
+// query.
+func (h *handler) toUserResources(ctx context.Context, users []*types.User) ([]*userResource, error) {
+	ids := make([]int32, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	emails, err := h.store.ListUserEmailsByUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]*userResource, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, newUserResource(u, emails[u.ID]))
+	}
+	return resources, nil
+}
+
+func newUserResource(u *types.User, emails []*database.UserEmail) *userResource {
+	r := &userResource{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.Itoa(int(u.ID)),
+		UserName:    u.Username,
+		DisplayName: u.DisplayName,
+		Active:      boolPtr(u.DeactivatedAt == nil),
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      u.CreatedAt,
+			LastModified: u.UpdatedAt,
+			Location:     location("Users", u.ID),
+		},
+	}
+	if u.DisplayName != "" {
+		r.Name = &userName{Formatted: u.DisplayName}
+	}
+	for _, e := range emails {
+		typ := "other"
+		if e.Primary {
+			typ = "work"
+		}
+		r.Emails = append(r.Emails, userEmail{Value: e.Email, Type: typ, Primary: scimBool(e.Primary)})
+	}
+	return r
+}
+
+func (h *handler) getUser(r *http.Request) (int, interface{}, error) {
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	u, err := h.store.GetUser(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	resource, err := h.toUserResource(r.Context(), u)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, resource, nil
+}
+
+func (h *handler) listUsers(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	params, err := parseListParams(r)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		users []*types.User
+		total int
+	)
+	if params.filter == nil {
+		total, err = h.store.CountUsers(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+		users, err = h.store.ListUsers(ctx, &database.UsersListOptions{
+			LimitOffset: &database.LimitOffset{Limit: params.count, Offset: params.startIndex - 1},
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var resources []interface{}
+	if attr, value, ok := eqValue(params.filter); ok && strings.EqualFold(attr, "userName") {
+		// Identity providers look up users by their userName before provisioning them, so this
+		// is done without listing all users. Because usernames are normalized, a user provisioned
+		// with an email address as their userName is found by that email address.
+		u, err := h.userByUserName(ctx, value)
+		if err != nil {
+			return 0, nil, err
+		}
+		if u != nil {
+			resource, err := h.toUserResource(ctx, u)
+			if err != nil {
+				return 0, nil, err
+			}
+			resources = append(resources, resource)
+		}
+		total = len(resources)
+		resources = params.page(resources)
+	} else if params.filter != nil {
+		users, err = h.store.ListUsers(ctx, nil)
+		if err != nil {
+			return 0, nil, err
+		}
+		all, err := h.toUserResources(ctx, users)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, resource := range all {
+			if matchResource(params.filter, resource) {
+				resources = append(resources, resource)
+			}
+		}
+		total = len(resources)
+		resources = params.page(resources)
+	} else {
+		page, err := h.toUserResources(ctx, users)
+		if err != nil {
+			return 0, nil, err
+		}
+		for _, resource := range page {
+			resources = append(resources, resource)
+		}
+	}
+
+	if resources == nil {
+		resources = []interface{}{}
+	}
+	return http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   params.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+// userByUserName returns the user with the username, or with the verified email address if
+// userName is an email address. It returns nil if there is no such user.
+func (h *handler) userByUserName(ctx context.Context, userName string) (*types.User, error) {
+	u, err := h.store.GetUserByUsername(ctx, userName)
+	if err == nil || !errcode.IsNotFound(err) {
+		return u, err
+	}
+	if strings.Count(userName, "@") == 1 {
+		u, err = h.store.GetUserByVerifiedEmail(ctx, userName)
+		if err == nil || !errcode.IsNotFound(err) {
+			return u, err
+		}
+	}
+	return nil, nil
+}
+
+// matchResource reports whether the JSON representation of resource matches f.
+func matchResource(f filter, resource interface{}) bool {
+	m, err := toMap(resource)
+	return err == nil && f.match(m)
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	return m, json.Unmarshal(data, &m)
+}
+
+func fromMap(m map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return badRequest("invalidValue", "Invalid resource: %s", err)
+	}
+	return nil
+}
+
+func (h *handler) createUser(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	var desired userResource
+	if err := decodeBody(r, &desired); err != nil {
+		return 0, nil, err
+	}
+	if desired.UserName == "" {
+		return 0, nil, badRequest("invalidValue", "userName is required.")
+	}
+	username, err := auth.NormalizeUsername(desired.UserName)
+	if err != nil {
+		return 0, nil, badRequest("invalidValue", "Invalid userName: %s", err)
+	}
+	if u, err := h.userByUserName(ctx, desired.UserName); err != nil {
+		return 0, nil, err
+	} else if u != nil {
+		return 0, nil, conflict("A user with userName %q already exists.", desired.UserName)
+	}
+
+	var displayName string
+	for _, name := range desired.displayNames() {
+		if name != "" {
+			displayName = name
+			break
+		}
+	}
+	primaryEmail := desired.primaryEmail()
+	u, err := h.store.CreateUser(ctx, database.NewUser{
+		Username:    username,
+		Email:       primaryEmail,
+		DisplayName: displayName,
+		// 🚨 SECURITY: The identity provider is trusted to have verified the email addresses.
+		EmailIsVerified: primaryEmail != "",
+	})
+	if err != nil {
+		if database.IsUsernameExists(err) || database.IsEmailExists(err) {
+			return 0, nil, conflict("%s", err)
+		}
+		return 0, nil, err
+	}
+	for _, e := range desired.Emails {
+		if e.Value != "" && !strings.EqualFold(e.Value, primaryEmail) {
+			if err := h.store.AddUserEmail(ctx, u.ID, e.Value); err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+	h.store.LogSecurityEvent(ctx, securityEvent(database.SecurityEventNameSCIMUserCreated, r.URL.Path, u.ID, map[string]string{"userName": desired.UserName}))
+
+	if desired.Active != nil && !*desired.Active {
+		if u, err = h.setUserActive(r, u, false); err != nil {
+			return 0, nil, err
+		}
+	}
+	resource, err := h.toUserResource(ctx, u)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusCreated, resource, nil
+}
+
+func (h *handler) replaceUser(r *http.Request) (int, interface{}, error) {
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	u, err := h.store.GetUser(r.Context(), id)
+	if err != nil {
+		return 0, nil, err
+	}
+	var desired userResource
+	if err := decodeBody(r, &desired); err != nil {
+		return 0, nil, err
+	}
+	return h.updateUser(r, u, &desired)
+}
+
+func (h *handler) patchUser(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	u, err := h.store.GetUser(ctx, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	var patch patchRequest
+	if err := decodeBody(r, &patch); err != nil {
+		return 0, nil, err
+	}
+
+	current, err := h.toUserResource(ctx, u)
+	if err != nil {
+		return 0, nil, err
+	}
+	m, err := toMap(current)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := applyPatch(m, patch.Operations); err != nil {
+		return 0, nil, err
+	}
+	var desired userResource
+	if err := fromMap(m, &desired); err != nil {
+		return 0, nil, err
+	}
+	return h.updateUser(r, u, &desired)
+}
+
+// updateUser updates the user u to match the desired resource, including deactivating or
+// reactivating them.
+func (h *handler) updateUser(r *http.Request, u *types.User, desired *userResource) (int, interface{}, error) {
+	ctx := r.Context()
+	if desired.Active != nil && !*desired.Active {
+		// Refuse before anything is changed.
+		if err := h.checkNotLastSiteAdmin(ctx, u); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var (
+		update  database.UserUpdate
+		changes []string
+	)
+	if desired.UserName != "" && desired.UserName != u.Username {
+		username, err := auth.NormalizeUsername(desired.UserName)
+		if err != nil {
+			return 0, nil, badRequest("invalidValue", "Invalid userName: %s", err)
+		}
+		if username != u.Username {
+			update.Username = username
+			changes = append(changes, "userName")
+		}
+	}
+	for _, name := range desired.displayNames() {
+		if name != "" && name != u.DisplayName {
+			update.DisplayName = &name
+			changes = append(changes, "displayName")
+			break
+		}
+	}
+	if len(changes) > 0 {
+		if err := h.store.UpdateUser(ctx, u.ID, update); err != nil {
+			if database.IsUsernameExists(err) || strings.Contains(err.Error(), "already in use") {
+				return 0, nil, conflict("%s", err)
+			}
+			return 0, nil, err
+		}
+	}
+
+	// Email addresses are only changed if they're given, so that users always keep an email
+	// address.
+	if desired.Emails != nil {
+		changed, err := h.syncUserEmails(ctx, u.ID, desired)
+		if err != nil {
+			return 0, nil, err
+		}
+		if changed {
+			changes = append(changes, "emails")
+		}
+	}
+
+	if len(changes) > 0 {
+		h.store.LogSecurityEvent(ctx, securityEvent(database.SecurityEventNameSCIMUserUpdated, r.URL.Path, u.ID, map[string][]string{"changed": changes}))
+	}
+
+	u, err := h.store.GetUser(ctx, u.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if desired.Active != nil {
+		if u, err = h.setUserActive(r, u, bool(*desired.Active)); err != nil {
+			return 0, nil, err
+		}
+	}
+	resource, err := h.toUserResource(ctx, u)
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, resource, nil
+}
+
+// syncUserEmails makes the email addresses of the user those of the desired resource, and
+// reports whether they changed.
+func (h *handler) syncUserEmails(ctx context.Context, userID int32, desired *userResource) (changed bool, err error) {
+	current, err := h.store.ListUserEmails(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	have := map[string]*database.UserEmail{}
+	for _, e := range current {
+		have[strings.ToLower(e.Email)] = e
+	}
+	want := map[string]bool{}
+	for _, e := range desired.Emails {
+		if e.Value == "" {
+			continue
+		}
+		want[strings.ToLower(e.Value)] = true
+		if have[strings.ToLower(e.Value)] == nil {
+			if err := h.store.AddUserEmail(ctx, userID, e.Value); err != nil {
+				if database.IsEmailExists(err) {
+					return false, conflict("%s", err)
+				}
+				return false, err
+			}
+			changed = true
+		}
+	}
+	if len(want) == 0 {
+		return changed, nil
+	}
+
+	if primary := desired.primaryEmail(); primary != "" {
+		if e := have[strings.ToLower(primary)]; e == nil || !e.Primary {
+			if err := h.store.SetPrimaryUserEmail(ctx, userID, primary); err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	}
+	for lower, e := range have {
+		if !want[lower] {
+			if err := h.store.RemoveUserEmail(ctx, userID, e.Email); err != nil {
+				return false, err
+			}
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// setUserActive deactivates or reactivates the user, and returns the updated user. Deactivated
+// users keep their account, but are signed out and can't sign in or use access tokens.
+func (h *handler) setUserActive(r *http.Request, u *types.User, active bool) (*types.User, error) {
+	ctx := r.Context()
+	if active == (u.DeactivatedAt == nil) {
+		return u, nil
+	}
+	name := database.SecurityEventNameSCIMUserReactivated
+	if !active {
+		if err := h.checkNotLastSiteAdmin(ctx, u); err != nil {
+			return nil, err
+		}
+		name = database.SecurityEventNameSCIMUserDeactivated
+	}
+	if err := h.store.SetUserDeactivated(ctx, u.ID, !active); err != nil {
+		return nil, err
+	}
+	h.store.LogSecurityEvent(ctx, securityEvent(name, r.URL.Path, u.ID, nil))
+	return h.store.GetUser(ctx, u.ID)
+}
+
+// checkNotLastSiteAdmin returns an error if u is the last active site admin, who must not be
+// deactivated or deleted so that the site can still be administered.
+func (h *handler) checkNotLastSiteAdmin(ctx context.Context, u *types.User) error {
+	err := backend.CheckNotLastSiteAdmin(ctx, u, h.store.CountActiveSiteAdmins)
+	if errors.Is(err, backend.ErrLastSiteAdmin) {
+		return badRequest("mutability", "%q is the last site admin, and can't be deactivated or deleted.", u.Username)
+	}
+	return err
+}
+
+func (h *handler) deleteUser(r *http.Request) (int, interface{}, error) {
+	ctx := r.Context()
+	id, err := resourceID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	u, err := h.store.GetUser(ctx, id)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := h.checkNotLastSiteAdmin(ctx, u); err != nil {
+		return 0, nil, err
+	}
+	if err := h.store.DeleteUser(ctx, id); err != nil {
+		return 0, nil, err
+	}
+	h.store.LogSecurityEvent(ctx, securityEvent(database.SecurityEventNameSCIMUserDeleted, r.URL.Path, id, nil))
+	return http.StatusNoContent, nil, nil
+}
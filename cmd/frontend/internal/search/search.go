@@ -25,10 +25,12 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	searchlogs "github.com/sourcegraph/sourcegraph/cmd/frontend/internal/search/logs"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/honey"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 	"github.com/sourcegraph/sourcegraph/internal/search/run"
 	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
@@ -90,6 +92,22 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	events, inputs, results := h.startSearch(ctx, args)
 	events = batchEvents(events, 50*time.Millisecond)
 
+	// Searches which aggregate send the counts of their results instead of
+	// the results. The plan is nil if the search can't run, in which case
+	// the error or alert of the search is sent below.
+	var aggregations *streaming.SearchAggregations
+	if args.Aggregate != "" && inputs.Plan != nil {
+		aggregations, err = streaming.NewSearchAggregations(args.Aggregate, inputs.Plan)
+		if err != nil {
+			// The search blocks until its events are consumed.
+			cancel()
+			for range events {
+			}
+			_ = eventWriter.Event("error", streamhttp.EventError{Message: err.Error()})
+			return
+		}
+	}
+
 	traceURL := ""
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		spanURL := trace.SpanURL(span)
@@ -104,6 +122,10 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// want to send everything we find before hitting a limit. Otherwise we
 	// can only send up to limit results.
 	display := args.Display
+	if aggregations != nil {
+		// The display limit applies to matches, which aren't sent.
+		display = -1
+	}
 	limit := inputs.MaxResults()
 	if display < 0 || display > limit {
 		display = limit
@@ -128,6 +150,8 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	filters := &streaming.SearchFilters{
 		Globbing: false, // TODO
+		// Queries filtering on owners resolve the owners of their matches.
+		ResolveOwners: !filtersOnOwners(inputs.Plan),
 	}
 
 	// Store marshalled matches and flush periodically or when we go over
@@ -140,11 +164,30 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return eventWriter.EventBytes("matches", data)
 		},
 	}
+	// Rewrites are sent after the matches of their files.
+	rewritesBuf := &jsonArrayBuf{
+		FlushSize: 32 * 1024,
+		Write: func(data []byte) error {
+			return eventWriter.EventBytes("rewrites", data)
+		},
+	}
+	sendAggregations := func(done bool) {
+		_ = eventWriter.Event("aggregations", fromAggregations(aggregations.Compute(args.AggregateLimit), done))
+	}
+
 	matchesFlush := func() {
 		if err := matchesBuf.Flush(); err != nil {
 			// EOF
 			return
 		}
+		if err := rewritesBuf.Flush(); err != nil {
+			// EOF
+			return
+		}
+
+		if aggregations != nil && aggregations.Dirty {
+			sendAggregations(false)
+		}
 
 		if progress.Dirty {
 			sendProgress()
@@ -183,6 +226,19 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		progress.Update(event)
 		filters.Update(event)
 
+		if aggregations != nil {
+			repoMetadata := h.getEventRepoMetadata(ctx, event)
+			matches := event.Results[:0]
+			for _, match := range event.Results {
+				if hasRepoMetadata(match, repoMetadata) {
+					matches = append(matches, match)
+				}
+			}
+			event.Results = matches
+			aggregations.Update(event)
+			continue
+		}
+
 		// Truncate the event to the match limit before fetching repo metadata
 		for i, match := range event.Results {
 			if display <= 0 {
@@ -198,10 +254,13 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			// Don't send matches which we cannot map to a repo the actor has access to. This
 			// check is expected to always pass. Missing metadata is a sign that we have
 			// searched repos that user shouldn't have access to.
-			if md, ok := repoMetadata[match.RepoName().ID]; !ok || md.Name != match.RepoName().Name {
+			if !hasRepoMetadata(match, repoMetadata) {
 				continue
 			}
 			matchesAppend(fromMatch(match, repoMetadata))
+			if fm, ok := match.(*result.FileMatch); ok && fm.RewriteDiff != "" {
+				_ = rewritesBuf.Append(fromRewrite(fm))
+			}
 		}
 
 		// Instantly send results if we have not sent any yet.
@@ -218,6 +277,16 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	matchesFlush()
 
+	if aggregations != nil {
+		sendAggregations(true)
+	}
+
+	if filters.ResolveOwners {
+		ownersCtx, cancelOwners := context.WithTimeout(ctx, ownerFacetTimeout)
+		filters.AddOwners(ownersCtx, codeowners.NewResolver().Owners)
+		cancelOwners()
+	}
+
 	// Send dynamic filters once.
 	if filters := filters.Compute(); len(filters) > 0 {
 		buf := make([]streamhttp.EventFilter, 0, len(filters))
@@ -291,6 +360,26 @@ func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// hasRepoMetadata returns true if the repository of match is in repoMetadata.
+// ownerFacetTimeout is the maximum time spent resolving the owners of file
+// matches for the owner facet once the search is done.
+const ownerFacetTimeout = 2 * time.Second
+
+// filtersOnOwners returns true if any query of plan filters on owners.
+func filtersOnOwners(plan query.Plan) bool {
+	for _, b := range plan {
+		if b.FindValue(query.FieldFileHasOwner) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func hasRepoMetadata(match result.Match, repoMetadata map[api.RepoID]*types.Repo) bool {
+	md, ok := repoMetadata[match.RepoName().ID]
+	return ok && md.Name == match.RepoName().Name
+}
+
 func (h *streamHandler) getEventRepoMetadata(ctx context.Context, event streaming.SearchEvent) map[api.RepoID]*types.Repo {
 	ids := repoIDs(event.Results)
 	if len(ids) == 0 {
@@ -322,6 +411,9 @@ func (h *streamHandler) startSearch(ctx context.Context, a *args) (events <-chan
 		PatternType:    strPtr(a.PatternType),
 		VersionContext: strPtr(a.VersionContext),
 
+		// Aggregations count all results of a search.
+		Exhaustive: a.Aggregate != "",
+
 		Stream: streaming.StreamFunc(func(event streaming.SearchEvent) {
 			eventsC <- event
 		}),
@@ -367,6 +459,11 @@ type args struct {
 	PatternType    string
 	VersionContext string
 	Display        int
+
+	// Aggregate is the mode by which results are aggregated, if any, and
+	// AggregateLimit the maximum number of groups which are sent.
+	Aggregate      streaming.AggregationMode
+	AggregateLimit int
 }
 
 func parseURLQuery(q url.Values) (*args, error) {
@@ -395,6 +492,17 @@ func parseURLQuery(q url.Values) (*args, error) {
 		return nil, errors.Errorf("display must be an integer, got %q: %w", display, err)
 	}
 
+	if aggregate := get("aggregate", ""); aggregate != "" {
+		if a.Aggregate, err = streaming.ParseAggregationMode(aggregate); err != nil {
+			return nil, err
+		}
+	}
+
+	aggregateLimit := get("aggregateLimit", "100")
+	if a.AggregateLimit, err = strconv.Atoi(aggregateLimit); err != nil {
+		return nil, errors.Errorf("aggregateLimit must be an integer, got %q: %w", aggregateLimit, err)
+	}
+
 	return &a, nil
 }
 
@@ -412,6 +520,21 @@ func fromStrPtr(s *string) string {
 	return *s
 }
 
+func fromAggregations(a *streaming.Aggregations, done bool) *streamhttp.EventAggregations {
+	groups := make([]streamhttp.EventAggregation, 0, len(a.Groups))
+	for _, g := range a.Groups {
+		groups = append(groups, streamhttp.EventAggregation{Label: g.Label, Count: g.Count})
+	}
+	return &streamhttp.EventAggregations{
+		Mode:             string(a.Mode),
+		Groups:           groups,
+		OtherGroupCount:  a.OtherGroupCount,
+		OtherResultCount: a.OtherResultCount,
+		Approximate:      a.Approximate,
+		Done:             done,
+	}
+}
+
 func fromMatch(match result.Match, repoCache map[api.RepoID]*types.Repo) streamhttp.EventMatch {
 	switch v := match.(type) {
 	case *result.FileMatch:
@@ -452,6 +575,7 @@ func fromPathMatch(fm *result.FileMatch, repoCache map[api.RepoID]*types.Repo) *
 		RepoStars:  stars,
 		Branches:   branches,
 		Version:    string(fm.CommitID),
+		Owners:     fm.Owners,
 	}
 }
 
@@ -482,10 +606,26 @@ func fromContentMatch(fm *result.FileMatch, repoCache map[api.RepoID]*types.Repo
 		RepoStars:   stars,
 		Branches:    branches,
 		Version:     string(fm.CommitID),
+		Owners:      fm.Owners,
 		LineMatches: lineMatches,
 	}
 }
 
+func fromRewrite(fm *result.FileMatch) *streamhttp.EventRewrite {
+	var branches []string
+	if fm.InputRev != nil {
+		branches = []string{*fm.InputRev}
+	}
+
+	return &streamhttp.EventRewrite{
+		Path:       fm.Path,
+		Repository: string(fm.Repo.Name),
+		Branches:   branches,
+		Version:    string(fm.CommitID),
+		Diff:       fm.RewriteDiff,
+	}
+}
+
 func fromSymbolMatch(fm *result.FileMatch, repoCache map[api.RepoID]*types.Repo) *streamhttp.EventSymbolMatch {
 	symbols := make([]streamhttp.Symbol, 0, len(fm.Symbols))
 	for _, sym := range fm.Symbols {
@@ -520,6 +660,7 @@ func fromSymbolMatch(fm *result.FileMatch, repoCache map[api.RepoID]*types.Repo)
 		RepoStars:  stars,
 		Branches:   branches,
 		Version:    string(fm.CommitID),
+		Owners:     fm.Owners,
 		Symbols:    symbols,
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
@@ -212,6 +213,222 @@ func TestDisplayLimit(t *testing.T) {
 	}
 }
 
+func TestAggregations(t *testing.T) {
+	database.Mocks.Repos.GetByIDs = func(ctx context.Context, ids ...api2.RepoID) (_ []*types.Repo, err error) {
+		res := make([]*types.Repo, 0, len(ids))
+		for _, id := range ids {
+			// The actor can't access repo3.
+			if id != 3 {
+				res = append(res, &types.Repo{ID: id, Name: api2.RepoName(fmt.Sprintf("repo%d", id))})
+			}
+		}
+		return res, nil
+	}
+	defer func() { database.Mocks.Repos.GetByIDs = nil }()
+
+	search := func(t *testing.T, queryString, aggregate string) (*streamhttp.EventAggregations, []string) {
+		mock := &mockSearchResolver{
+			done: make(chan struct{}),
+		}
+		var exhaustive bool
+		ts := httptest.NewServer(&streamHandler{
+			flushTickerInternal: 1 * time.Millisecond,
+			pingTickerInterval:  1 * time.Millisecond,
+			newSearchResolver: func(_ context.Context, _ dbutil.DB, args *graphqlbackend.SearchArgs) (searchResolver, error) {
+				mock.c = args.Stream
+				exhaustive = args.Exhaustive
+				plan, err := query.Pipeline(query.InitRegexp(queryString))
+				if err != nil {
+					t.Fatal(err)
+				}
+				mock.inputs = &run.SearchInputs{
+					Plan:  plan,
+					Query: plan.ToParseTree(),
+				}
+				return mock, nil
+			}})
+		defer ts.Close()
+
+		req, _ := streamhttp.NewRequest(ts.URL, queryString)
+		q := req.URL.Query()
+		q.Add("aggregate", aggregate)
+		req.URL.RawQuery = q.Encode()
+
+		var (
+			final   *streamhttp.EventAggregations
+			errors  []string
+			matches int
+		)
+		decoder := streamhttp.Decoder{
+			OnAggregations: func(a *streamhttp.EventAggregations) {
+				final = a
+			},
+			OnMatches: func(m []streamhttp.EventMatch) {
+				matches += len(m)
+			},
+			OnError: func(e *streamhttp.EventError) {
+				errors = append(errors, e.Message)
+			},
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		g := errgroup.Group{}
+		g.Go(func() error {
+			return decoder.ReadAll(resp.Body)
+		})
+
+		// The search is canceled before it sends results if the pattern
+		// has no capture group.
+		if aggregate != "capture_group" {
+			mock.c.Send(streaming.SearchEvent{
+				Results: []result.Match{mkRepoMatch(1), mkRepoMatch(2), mkRepoMatch(3)},
+			})
+			mock.c.Send(streaming.SearchEvent{
+				Results: []result.Match{&result.FileMatch{
+					File:        result.File{Repo: types.RepoName{ID: 1, Name: "repo1"}, Path: "a.go"},
+					LineMatches: []*result.LineMatch{{Preview: "foo(bar)", OffsetAndLengths: [][2]int32{{0, 8}}}},
+				}},
+			})
+		}
+		mock.Close()
+		if err := g.Wait(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !exhaustive {
+			t.Error("expected aggregation to search for all results")
+		}
+		if matches != 0 {
+			t.Errorf("expected no matches, got %d", matches)
+		}
+		return final, errors
+	}
+
+	t.Run("repo", func(t *testing.T) {
+		got, errors := search(t, "foo", "repo")
+		if len(errors) > 0 {
+			t.Fatalf("unexpected errors %v", errors)
+		}
+		want := &streamhttp.EventAggregations{
+			Mode:   "repo",
+			Groups: []streamhttp.EventAggregation{{Label: "repo1", Count: 2}, {Label: "repo2", Count: 1}},
+			Done:   true,
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("unexpected aggregations (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid capture group", func(t *testing.T) {
+		got, errors := search(t, "foo", "capture_group")
+		if got != nil || len(errors) != 1 {
+			t.Errorf("expected an error, got aggregations %v and errors %v", got, errors)
+		}
+	})
+}
+
+func TestRewrites(t *testing.T) {
+	database.Mocks.Repos.GetByIDs = func(ctx context.Context, ids ...api2.RepoID) (_ []*types.Repo, err error) {
+		res := make([]*types.Repo, 0, len(ids))
+		for _, id := range ids {
+			// The actor can't access repo3.
+			if id != 3 {
+				res = append(res, &types.Repo{ID: id, Name: api2.RepoName(fmt.Sprintf("repo%d", id))})
+			}
+		}
+		return res, nil
+	}
+	defer func() { database.Mocks.Repos.GetByIDs = nil }()
+
+	mock := &mockSearchResolver{
+		done: make(chan struct{}),
+	}
+	ts := httptest.NewServer(&streamHandler{
+		flushTickerInternal: 1 * time.Millisecond,
+		pingTickerInterval:  1 * time.Millisecond,
+		newSearchResolver: func(_ context.Context, _ dbutil.DB, args *graphqlbackend.SearchArgs) (searchResolver, error) {
+			mock.c = args.Stream
+			plan, err := query.Pipeline(query.InitRegexp(args.Query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			mock.inputs = &run.SearchInputs{
+				Plan:  plan,
+				Query: plan.ToParseTree(),
+			}
+			return mock, nil
+		}})
+	defer ts.Close()
+
+	req, _ := streamhttp.NewRequest(ts.URL, "foo rewrite:bar")
+
+	var (
+		rewrites []*streamhttp.EventRewrite
+		matches  int
+	)
+	decoder := streamhttp.Decoder{
+		OnRewrites: func(r []*streamhttp.EventRewrite) {
+			rewrites = append(rewrites, r...)
+		},
+		OnMatches: func(m []streamhttp.EventMatch) {
+			matches += len(m)
+		},
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	g := errgroup.Group{}
+	g.Go(func() error {
+		return decoder.ReadAll(resp.Body)
+	})
+
+	fileMatch := func(repo int, path, diff string) *result.FileMatch {
+		return &result.FileMatch{
+			File: result.File{
+				Repo:     types.RepoName{ID: api2.RepoID(repo), Name: api2.RepoName(fmt.Sprintf("repo%d", repo))},
+				Path:     path,
+				CommitID: "deadbeef",
+			},
+			LineMatches: []*result.LineMatch{{Preview: "foo", OffsetAndLengths: [][2]int32{{0, 3}}}},
+			RewriteDiff: diff,
+		}
+	}
+	mock.c.Send(streaming.SearchEvent{
+		Results: []result.Match{
+			fileMatch(1, "a.go", "--- a/a.go\n+++ b/a.go\n"),
+			// A file match without a diff isn't changed by the rewrite.
+			fileMatch(1, "b.go", ""),
+			fileMatch(3, "a.go", "--- a/a.go\n+++ b/a.go\n"),
+		},
+	})
+	mock.Close()
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if matches != 2 {
+		t.Errorf("expected 2 matches, got %d", matches)
+	}
+	want := []*streamhttp.EventRewrite{{
+		Path:       "a.go",
+		Repository: "repo1",
+		Version:    "deadbeef",
+		Diff:       "--- a/a.go\n+++ b/a.go\n",
+	}}
+	if diff := cmp.Diff(want, rewrites); diff != "" {
+		t.Errorf("unexpected rewrites (-want +got):\n%s", diff)
+	}
+}
+
 func mkRepoMatch(id int) *result.RepoMatch {
 	return &result.RepoMatch{
 		ID:   api2.RepoID(id),
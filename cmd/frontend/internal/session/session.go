@@ -379,6 +379,13 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 			return r.Context() // not authenticated
 		}
 
+		// Deactivated users are signed out, including of sessions they started after they were
+		// deactivated.
+		if usr.DeactivatedAt != nil {
+			_ = deleteSession(w, r)
+			return r.Context()
+		}
+
 		// Check that the session is still valid
 		if info.LastActive.Before(usr.InvalidatedSessionsAt) {
 			_ = deleteSession(w, r) // Delete the now invalid session
@@ -214,6 +214,41 @@ func TestManualSessionExpiry(t *testing.T) {
 	}
 }
 
+func TestDeactivatedUserSession(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	user := &types.User{ID: 123, CreatedAt: time.Now().Add(-time.Hour), InvalidatedSessionsAt: time.Now().Add(-time.Hour)}
+	database.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return user, nil
+	}
+	defer func() { database.Mocks = database.MockStores{} }()
+
+	w := httptest.NewRecorder()
+	actr := &actor.Actor{UID: 123, FromSessionCookie: true}
+	if err := SetActor(w, httptest.NewRequest("GET", "/", nil), actr, time.Hour, user.CreatedAt); err != nil {
+		t.Fatal(err)
+	}
+	authedReq := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Expires.After(time.Now()) || cookie.MaxAge > 0 {
+			authedReq.AddCookie(cookie)
+		}
+	}
+
+	if gotActor := actor.FromContext(authenticateByCookie(authedReq, httptest.NewRecorder())); gotActor.UID != 123 {
+		t.Fatalf("active user should be authenticated, got %v", gotActor)
+	}
+
+	// The session started after the sessions of the user were invalidated, but the user is
+	// deactivated.
+	deactivatedAt := time.Now().Add(-time.Hour)
+	user.DeactivatedAt = &deactivatedAt
+	if gotActor := actor.FromContext(authenticateByCookie(authedReq, httptest.NewRecorder())); gotActor.IsAuthenticated() {
+		t.Errorf("deactivated user should not be authenticated, got %v", gotActor)
+	}
+}
+
 func TestCookieMiddleware(t *testing.T) {
 	cleanup := ResetMockSessionStore(t)
 	defer cleanup()
@@ -0,0 +1,50 @@
+package vfsutil
+
+import (
+	"context"
+	"os"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/store"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// IsArchivedFile returns true if name is the virtual path of a file in an
+// archive in a repository, like "lib/foo.jar!/com/x/Y.java". Searcher
+// returns matches at such paths when the search.archives site configuration
+// is enabled.
+func IsArchivedFile(name string) bool {
+	_, _, ok := store.SplitArchivePath(name)
+	return ok
+}
+
+// ReadArchivedFile returns the contents of the file at the virtual path name
+// in repo at commit, where name is the path of a file in an archive like
+// "lib/foo.jar!/com/x/Y.java". The archive may itself be in an archive.
+//
+// Like searcher, it only reads archives when the search.archives site
+// configuration is enabled, and only up to its size budget.
+func ReadArchivedFile(ctx context.Context, repo api.RepoName, commit api.CommitID, name string) ([]byte, error) {
+	notExist := &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+
+	archive, file, ok := store.SplitArchivePath(name)
+	if !ok {
+		return nil, notExist
+	}
+	limit := conf.SearchArchivesMaxBytes()
+	if limit == 0 {
+		return nil, notExist
+	}
+
+	data, err := git.ReadFile(ctx, repo, commit, archive, limit)
+	if err != nil {
+		return nil, err
+	}
+	b, err := store.ReadArchiveFile(archive, data, file, limit)
+	if errcode.IsNotFound(err) {
+		return nil, notExist
+	}
+	return b, err
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/cockroachdb/errors"
+
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/enterprise"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/cli"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -23,6 +25,12 @@ func Main(enterpriseSetupHook func(db dbutil.DB, outOfBandMigrationRunner *oobmi
 	env.Lock()
 	err := cli.Main(enterpriseSetupHook)
 	if err != nil {
+		// Commands like plan-config-bundle use the exit status to report their result.
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitErr.ExitCode())
+		}
 		fmt.Fprintln(os.Stderr, "fatal:", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/commitindex"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// commitIndexDir is the directory in the GitDir storing the commit index.
+const commitIndexDir = "sg_commitindex"
+
+// commitIndexConcurrency is the maximum number of commit index updates running
+// at once. The first update of a large repository takes a while, so this keeps
+// the updates from competing with searches for CPU and IO.
+const commitIndexConcurrency = 2
+
+var (
+	commitIndexUpdateDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "src_gitserver_commit_index_update_duration_seconds",
+		Help:    "Time taken to update the commit index of a repository.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"success"})
+	commitIndexSearchCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "src_gitserver_commit_index_search_total",
+		Help: "Number of commit index searches, by whether the repository was indexed and the query filtered.",
+	}, []string{"indexed", "filtered"})
+)
+
+// commitIndexer runs commit index updates in the background.
+type commitIndexer struct {
+	sem chan struct{}
+
+	mu      sync.Mutex
+	running map[api.RepoName]bool // repos with an update running
+	pending map[api.RepoName]bool // repos which changed while their update was running
+}
+
+func newCommitIndexer() *commitIndexer {
+	return &commitIndexer{
+		sem:     make(chan struct{}, commitIndexConcurrency),
+		running: map[api.RepoName]bool{},
+		pending: map[api.RepoName]bool{},
+	}
+}
+
+// enqueueCommitIndexUpdate updates the commit index of repo in the background,
+// if the commit index is enabled. It is called after a repository is cloned or
+// fetched. Updates of a repository never run concurrently: if an update is
+// running, another one runs after it.
+func (s *Server) enqueueCommitIndexUpdate(repo api.RepoName) {
+	ci := s.commitIndexer
+	if ci == nil || !conf.CommitIndexEnabled() {
+		return
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if ci.running[repo] {
+		ci.pending[repo] = true
+		return
+	}
+	ci.running[repo] = true
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.updateCommitIndex(repo)
+
+			ci.mu.Lock()
+			if !ci.pending[repo] {
+				delete(ci.running, repo)
+				ci.mu.Unlock()
+				return
+			}
+			delete(ci.pending, repo)
+			ci.mu.Unlock()
+		}
+	}()
+}
+
+func (s *Server) updateCommitIndex(repo api.RepoName) {
+	ctx, cancel := s.serverContext()
+	defer cancel()
+
+	select {
+	case s.commitIndexer.sem <- struct{}{}:
+		defer func() { <-s.commitIndexer.sem }()
+	case <-ctx.Done():
+		return
+	}
+
+	dir := s.dir(repo)
+	start := time.Now()
+	err := commitindex.New(dir.Path(commitIndexDir)).Update(ctx, string(dir))
+	commitIndexUpdateDuration.WithLabelValues(strconv.FormatBool(err == nil)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		log15.Error("failed to update commit index", "repo", repo, "error", err)
+	}
+}
+
+// handleCommitIndexSearch searches the commit index of a repository. It
+// responds with Indexed set to false if the index isn't up to date with HEAD.
+func (s *Server) handleCommitIndexSearch(w http.ResponseWriter, r *http.Request) {
+	var req protocol.CommitIndexSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.commitIndexSearch(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	commitIndexSearchCounter.WithLabelValues(strconv.FormatBool(resp.Indexed), strconv.FormatBool(resp.Filtered)).Inc()
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (s *Server) commitIndexSearch(req protocol.CommitIndexSearchRequest) (*protocol.CommitIndexSearchResponse, error) {
+	var resp protocol.CommitIndexSearchResponse
+
+	dir := s.dir(protocol.NormalizeRepo(req.Repo))
+	if !repoCloned(dir) {
+		return &resp, nil
+	}
+	head, err := quickRevParseHead(dir)
+	if err != nil {
+		// The index is only used as an optimization, so leave resolving HEAD
+		// in unusual repositories to git log.
+		return &resp, nil
+	}
+
+	res, err := commitindex.New(dir.Path(commitIndexDir)).Search(commitindex.Query{
+		Diff:    req.Diff,
+		Message: req.Message,
+		Author:  req.Author,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Head == "" || res.Head != head {
+		return &resp, nil
+	}
+
+	resp.Indexed = true
+	resp.Filtered = res.Filtered
+	resp.Commits = make([]api.CommitID, 0, len(res.Commits))
+	for _, c := range res.Commits {
+		resp.Commits = append(resp.Commits, api.CommitID(c))
+	}
+	return &resp, nil
+}
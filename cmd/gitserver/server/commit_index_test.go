@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestServer_commitIndexSearch(t *testing.T) {
+	remote := t.TempDir()
+	cmd := func(name string, arg ...string) string {
+		return runCmd(t, remote, name, arg...)
+	}
+	head := api.CommitID(strings.TrimSpace(makeSingleCommitRepo(cmd)))
+
+	reposDir := t.TempDir()
+	repo := api.RepoName("example.com/foo/bar")
+	runCmd(t, reposDir, "git", "clone", "--bare", remote, filepath.Join(reposDir, string(repo), ".git"))
+
+	s := &Server{ReposDir: reposDir, ctx: context.Background(), commitIndexer: newCommitIndexer()}
+	req := protocol.CommitIndexSearchRequest{Repo: repo, Diff: []string{"hello"}}
+	search := func() *protocol.CommitIndexSearchResponse {
+		t.Helper()
+		resp, err := s.commitIndexSearch(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if diff := cmp.Diff(&protocol.CommitIndexSearchResponse{}, search()); diff != "" {
+		t.Fatalf("unexpected response before indexing (-want +got):\n%s", diff)
+	}
+
+	s.updateCommitIndex(repo)
+	want := &protocol.CommitIndexSearchResponse{Indexed: true, Filtered: true, Commits: []api.CommitID{head}}
+	if diff := cmp.Diff(want, search()); diff != "" {
+		t.Fatalf("unexpected response (-want +got):\n%s", diff)
+	}
+
+	// The index is stale once HEAD moves.
+	cmd("git", "commit", "--allow-empty", "-m", "second")
+	runCmd(t, filepath.Join(reposDir, string(repo), ".git"), "git", "fetch", remote, "+refs/heads/*:refs/heads/*")
+	if diff := cmp.Diff(&protocol.CommitIndexSearchResponse{}, search()); diff != "" {
+		t.Fatalf("unexpected response for stale index (-want +got):\n%s", diff)
+	}
+}
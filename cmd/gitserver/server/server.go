@@ -167,6 +167,9 @@ type Server struct {
 
 	repoUpdateLocksMu sync.Mutex // protects the map below and also updates to locks.once
 	repoUpdateLocks   map[api.RepoName]*locks
+
+	// commitIndexer updates commit indexes in the background.
+	commitIndexer *commitIndexer
 }
 
 type locks struct {
@@ -228,6 +231,7 @@ func (s *Server) Handler() http.Handler {
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.locker = &RepositoryLocker{}
 	s.repoUpdateLocks = make(map[api.RepoName]*locks)
+	s.commitIndexer = newCommitIndexer()
 
 	// GitMaxConcurrentClones controls the maximum number of clones that
 	// can happen at once on a single gitserver.
@@ -288,6 +292,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/repo-update", s.handleRepoUpdate)
 	mux.HandleFunc("/getGitolitePhabricatorMetadata", s.handleGetGitolitePhabricatorMetadata)
 	mux.HandleFunc("/create-commit-from-patch", s.handleCreateCommitFromPatch)
+	mux.HandleFunc("/commit-index-search", s.handleCommitIndexSearch)
 	mux.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
@@ -1404,6 +1409,8 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, opts *cloneOp
 		log15.Info("repo cloned", "repo", repo)
 		repoClonedCounter.Inc()
 
+		s.enqueueCommitIndexUpdate(repo)
+
 		return nil
 	}
 
@@ -1690,6 +1697,8 @@ func (s *Server) doBackgroundRepoUpdate(repo api.RepoName) error {
 		log15.Warn("Failed to update last changed time", "repo", repo, "error", err)
 	}
 
+	s.enqueueCommitIndexUpdate(repo)
+
 	return nil
 }
 
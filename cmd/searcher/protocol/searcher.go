@@ -123,6 +123,13 @@ type PatternInfo struct {
 	// file list in the frontend and passes it to searcher.
 	CombyRule string
 
+	// RewriteTemplate if non-empty is the template by which matches are
+	// rewritten, and the Diff of each FileMatch is the result of rewriting
+	// the file. It is a Comby rewrite template if IsStructuralPat is true,
+	// otherwise it is expanded like regexp.Regexp.Expand, e.g. "$1" or
+	// "${name}" refer to capture groups of the pattern.
+	RewriteTemplate string
+
 	// Select is the value of the the select field in the query. It is not necessary to
 	// use it since selection is done after the query completes, but exposing it can enable
 	// optimizations.
@@ -141,6 +148,9 @@ func (p *PatternInfo) String() string {
 			args = append(args, "comby")
 		}
 	}
+	if p.RewriteTemplate != "" {
+		args = append(args, fmt.Sprintf("rewrite:%q", p.RewriteTemplate))
+	}
 	if p.IsWordMatch {
 		args = append(args, "word")
 	}
@@ -202,6 +212,11 @@ type FileMatch struct {
 
 	// LimitHit is true if LineMatches may not include all LineMatches.
 	LimitHit bool
+
+	// Diff is the unified diff of rewriting the file with the
+	// RewriteTemplate of the request. It is empty if the request has no
+	// RewriteTemplate or the rewrite doesn't change the file.
+	Diff string `json:",omitempty"`
 }
 
 // LineMatch is the struct used by vscode to receive search results for a line.
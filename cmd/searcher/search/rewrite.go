@@ -0,0 +1,217 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/comby"
+	"github.com/sourcegraph/sourcegraph/internal/store"
+)
+
+// diffContextLines is the number of unchanged lines around changes in the
+// hunks of rewrite diffs, like the default of diff -u.
+const diffContextLines = 3
+
+// compileRewrite compiles the pattern of p to the regexp whose matches are
+// replaced by p.RewriteTemplate. Unlike compile it doesn't lowercase the
+// pattern for case insensitive searches, since the matched text is expanded
+// into the template.
+func compileRewrite(p *protocol.PatternInfo) (*regexp.Regexp, error) {
+	expr := p.Pattern
+	if !p.IsRegExp {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if p.IsWordMatch {
+		expr = `\b` + expr + `\b`
+	}
+	expr = "(?m:" + expr + ")"
+	if !p.IsCaseSensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// regexRewrite sets the Diff of each file match in matches to the diff of
+// replacing all matches of the pattern of p in the file with
+// p.RewriteTemplate. The template may refer to capture groups like $1 or
+// ${name}, see regexp.Regexp.Expand.
+func regexRewrite(ctx context.Context, p *protocol.PatternInfo, zf *store.ZipFile, matches []protocol.FileMatch) error {
+	re, err := compileRewrite(p)
+	if err != nil {
+		return badRequestError{err.Error()}
+	}
+
+	files := make(map[string]*store.SrcFile, len(zf.Files))
+	for i := range zf.Files {
+		files[zf.Files[i].Name] = &zf.Files[i]
+	}
+
+	template := []byte(p.RewriteTemplate)
+	for i := range matches {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Path matches are not rewritten.
+		if len(matches[i].LineMatches) == 0 {
+			continue
+		}
+		f, ok := files[matches[i].Path]
+		if !ok {
+			continue
+		}
+		orig := zf.DataFor(f)
+		rewritten := re.ReplaceAll(orig, template)
+		matches[i].Diff = unifiedDiff(matches[i].Path, string(orig), string(rewritten))
+	}
+	return nil
+}
+
+// structuralRewrite sets the Diff of each file match in matches to the diff
+// comby computes for rewriting the matches of args.MatchTemplate with
+// rewrite.
+func structuralRewrite(ctx context.Context, args comby.Args, rewrite string, matches []protocol.FileMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(matches))
+	for _, m := range matches {
+		paths = append(paths, m.Path)
+	}
+	args.FilePatterns = paths
+	args.RewriteTemplate = rewrite
+
+	diffs, err := comby.Diffs(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	byPath := make(map[string]string, len(diffs))
+	for _, d := range diffs {
+		byPath[d.URI] = normalizeCombyDiff(d)
+	}
+	for i := range matches {
+		matches[i].Diff = byPath[matches[i].Path]
+	}
+	return nil
+}
+
+// normalizeCombyDiff returns the diff of d with the a/ and b/ file names of
+// git diffs, which comby omits, so that diffs of regexp and structural
+// rewrites can be applied in the same way.
+func normalizeCombyDiff(d comby.FileDiff) string {
+	lines := strings.SplitAfterN(d.Diff, "\n", 3)
+	if len(lines) < 3 || !strings.HasPrefix(lines[0], "--- ") || !strings.HasPrefix(lines[1], "+++ ") {
+		return d.Diff
+	}
+	body := lines[2]
+	if !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	return fmt.Sprintf("--- a/%s\n+++ b/%s\n%s", d.URI, d.URI, body)
+}
+
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// unifiedDiff returns the unified diff of changing the file at path from orig
+// to rewritten, or the empty string if they are equal.
+func unifiedDiff(path, orig, rewritten string) string {
+	if orig == rewritten {
+		return ""
+	}
+
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToChars(orig, rewritten)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+
+	var lines []diffLine
+	for _, d := range diffs {
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text != "" {
+				lines = append(lines, diffLine{op: d.Type, text: text})
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- a/%s\n+++ b/%s\n", path, path)
+
+	// origLine and newLine are the 1-based line numbers of lines[i] in orig
+	// and rewritten.
+	origLine, newLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].op == diffmatchpatch.DiffEqual {
+			origLine++
+			newLine++
+			i++
+			continue
+		}
+
+		// lines[i] is the first change of a hunk. Extend the hunk while the
+		// next change is within twice the context of the previous one.
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines) && j <= end+2*diffContextLines+1; j++ {
+			if lines[j].op != diffmatchpatch.DiffEqual {
+				end = j
+			}
+		}
+		end += diffContextLines + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		hunkOrigStart, hunkNewStart := origLine-(i-start), newLine-(i-start)
+		var origCount, newCount int
+		var body strings.Builder
+		for _, l := range lines[start:end] {
+			prefix := " "
+			switch l.op {
+			case diffmatchpatch.DiffEqual:
+				origCount++
+				newCount++
+			case diffmatchpatch.DiffDelete:
+				prefix = "-"
+				origCount++
+			case diffmatchpatch.DiffInsert:
+				prefix = "+"
+				newCount++
+			}
+			body.WriteString(prefix)
+			body.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				body.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		for _, l := range lines[i:end] {
+			if l.op != diffmatchpatch.DiffInsert {
+				origLine++
+			}
+			if l.op != diffmatchpatch.DiffDelete {
+				newLine++
+			}
+		}
+
+		// Empty ranges start at the line before them.
+		if origCount == 0 {
+			hunkOrigStart--
+		}
+		if newCount == 0 {
+			hunkNewStart--
+		}
+		fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n%s", hunkOrigStart, origCount, hunkNewStart, newCount, body.String())
+		i = end
+	}
+	return sb.String()
+}
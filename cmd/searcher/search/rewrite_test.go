@@ -0,0 +1,126 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/comby"
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	lines := func(n int, change map[int]string) string {
+		var sb strings.Builder
+		for i := 1; i <= n; i++ {
+			if l, ok := change[i]; ok {
+				sb.WriteString(l)
+			} else {
+				sb.WriteString("line")
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String()
+	}
+
+	cases := []struct {
+		name      string
+		orig      string
+		rewritten string
+		want      string
+	}{{
+		name:      "unchanged",
+		orig:      "a\nb\n",
+		rewritten: "a\nb\n",
+		want:      "",
+	}, {
+		name:      "single line",
+		orig:      "foo",
+		rewritten: "bar",
+		want: `--- a/f
++++ b/f
+@@ -1,1 +1,1 @@
+-foo
+\ No newline at end of file
++bar
+\ No newline at end of file
+`,
+	}, {
+		name:      "separate hunks",
+		orig:      lines(20, map[int]string{2: "foo", 18: "foo"}),
+		rewritten: lines(20, map[int]string{2: "bar", 18: "bar"}),
+		want: `--- a/f
++++ b/f
+@@ -1,5 +1,5 @@
+ line
+-foo
++bar
+ line
+ line
+ line
+@@ -15,6 +15,6 @@
+ line
+ line
+ line
+-foo
++bar
+ line
+ line
+`,
+	}, {
+		name:      "merged hunks",
+		orig:      lines(12, map[int]string{4: "foo", 10: "foo"}),
+		rewritten: lines(12, map[int]string{4: "bar", 10: "bar"}),
+		want: `--- a/f
++++ b/f
+@@ -1,12 +1,12 @@
+ line
+ line
+ line
+-foo
++bar
+ line
+ line
+ line
+ line
+ line
+-foo
++bar
+ line
+ line
+`,
+	}, {
+		name:      "deleted lines",
+		orig:      "a\nb\nc\n",
+		rewritten: "a\nc\n",
+		want: `--- a/f
++++ b/f
+@@ -1,3 +1,2 @@
+ a
+-b
+ c
+`,
+	}}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := unifiedDiff("f", tc.orig, tc.rewritten)
+			if got != tc.want {
+				d, err := testutil.Diff(tc.want, got)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Errorf("unexpected diff:\n%s", d)
+			}
+		})
+	}
+}
+
+func TestNormalizeCombyDiff(t *testing.T) {
+	got := normalizeCombyDiff(comby.FileDiff{
+		URI:  "main.go",
+		Diff: "--- main.go\n+++ main.go\n@@ -1,1 +1,1 @@\n-func main() {}\n+func main(ctx context.Context) {}",
+	})
+	want := "--- a/main.go\n+++ b/main.go\n@@ -1,1 +1,1 @@\n-func main() {}\n+func main(ctx context.Context) {}\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -243,9 +243,16 @@ func (s *Service) search(ctx context.Context, p *protocol.Request, sender *limit
 
 	if p.IsStructuralPat {
 		return false, filteredStructuralSearch(ctx, zipPath, zf, &p.PatternInfo, p.Repo, sender)
-	} else {
-		return false, regexSearch(ctx, rg, zf, p.Limit, p.PatternMatchesContent, p.PatternMatchesPath, p.IsNegated, sender)
 	}
+	if err := regexSearch(ctx, rg, zf, p.Limit, p.PatternMatchesContent, p.PatternMatchesPath, p.IsNegated, sender); err != nil {
+		return false, err
+	}
+	if p.RewriteTemplate != "" {
+		// The collector cancels ctx once the limit is hit, but the matches it
+		// collected until then must still be rewritten.
+		return false, regexRewrite(sender.ParentContext(), &p.PatternInfo, zf, sender.Collected())
+	}
+	return false, nil
 }
 
 func validateParams(p *protocol.Request) error {
@@ -262,6 +269,9 @@ func validateParams(p *protocol.Request) error {
 	if p.IsNegated && p.IsStructuralPat {
 		return errors.New("Negated patterns are not supported for structural searches")
 	}
+	if p.RewriteTemplate != "" && (p.IsNegated || p.Pattern == "") {
+		return errors.New("RewriteTemplate requires a pattern which is not negated")
+	}
 	return nil
 }
 
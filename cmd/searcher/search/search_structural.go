@@ -202,7 +202,7 @@ func filteredStructuralSearch(ctx context.Context, zipPath string, zipFile *stor
 		extensionHint = filepath.Ext(matchedPaths[0])
 	}
 
-	return structuralSearch(ctx, zipPath, Subset(matchedPaths), extensionHint, p.Pattern, p.CombyRule, p.Languages, repo, sender)
+	return structuralSearch(ctx, zipPath, Subset(matchedPaths), extensionHint, p.Pattern, p.CombyRule, p.RewriteTemplate, p.Languages, repo, sender)
 }
 
 // toMatcher returns the matcher that parameterizes structural search. It
@@ -240,7 +240,10 @@ type Subset []string
 
 var All UniversalSet = struct{}{}
 
-func structuralSearch(ctx context.Context, zipPath string, paths filePatterns, extensionHint, pattern, rule string, languages []string, repo api.RepoName, sender *limitedStreamCollector) error {
+// structuralSearch sends the matches of pattern in the files of the zip at
+// zipPath to sender. If rewrite is non-empty the matches are sent with the
+// diff of rewriting them with rewrite.
+func structuralSearch(ctx context.Context, zipPath string, paths filePatterns, extensionHint, pattern, rule, rewrite string, languages []string, repo api.RepoName, sender *limitedStreamCollector) error {
 	log15.Info("structural search", "repo", string(repo))
 
 	// Cap the number of forked processes to limit the size of zip contents being mapped to memory. Resolving #7133 could help to lift this restriction.
@@ -268,11 +271,21 @@ func structuralSearch(ctx context.Context, zipPath string, paths filePatterns, e
 		return err
 	}
 
+	fileMatches := make([]protocol.FileMatch, 0, len(combyMatches))
 	for _, combyMatch := range combyMatches {
+		fileMatches = append(fileMatches, toFileMatch(combyMatch))
+	}
+	if rewrite != "" {
+		if err := structuralRewrite(ctx, args, rewrite, fileMatches); err != nil {
+			return err
+		}
+	}
+
+	for _, fm := range fileMatches {
 		if ctx.Err() != nil {
 			return nil
 		}
-		sender.Send(toFileMatch(combyMatch))
+		sender.Send(fm)
 	}
 	return nil
 }
@@ -326,7 +339,7 @@ func structuralSearchWithZoekt(ctx context.Context, p *protocol.Request, sender
 		extensionHint = filepath.Ext(filename)
 	}
 
-	return false, structuralSearch(ctx, zipFile.Name(), All, extensionHint, p.Pattern, p.CombyRule, p.Languages, p.Repo, sender)
+	return false, structuralSearch(ctx, zipFile.Name(), All, extensionHint, p.Pattern, p.CombyRule, p.RewriteTemplate, p.Languages, p.Repo, sender)
 }
 
 var requestTotalStructuralSearch = promauto.NewCounterVec(prometheus.CounterOpts{
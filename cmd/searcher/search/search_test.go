@@ -380,6 +380,18 @@ func TestSearch_badrequest(t *testing.T) {
 				IsStructuralPat:        true,
 			},
 		},
+
+		// rewrite with negated pattern
+		{
+			Repo:   "foo",
+			URL:    "u",
+			Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			PatternInfo: protocol.PatternInfo{
+				Pattern:         "fmt",
+				IsNegated:       true,
+				RewriteTemplate: "log",
+			},
+		},
 	}
 
 	store, cleanup, err := newStore(nil)
@@ -402,6 +414,74 @@ func TestSearch_badrequest(t *testing.T) {
 	}
 }
 
+func TestSearch_rewrite(t *testing.T) {
+	files := map[string]string{
+		"README.md": "Hello world",
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("Hello world")
+	fmt.Println("Hello moon")
+}
+`,
+	}
+
+	s, cleanup, err := newStore(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	ts := httptest.NewServer(&search.Service{Store: s})
+	defer ts.Close()
+
+	req := protocol.Request{
+		Repo:   "foo",
+		URL:    "u",
+		Commit: "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+		PatternInfo: protocol.PatternInfo{
+			Pattern:               `fmt\.Println\("Hello (\w+)"\)`,
+			IsRegExp:              true,
+			RewriteTemplate:       `log.Printf("hello %s", "$1")`,
+			PatternMatchesContent: true,
+		},
+		FetchTimeout: "10s",
+	}
+	want := `--- a/main.go
++++ b/main.go
+@@ -3,6 +3,6 @@
+ import "fmt"
+ 
+ func main() {
+-	fmt.Println("Hello world")
+-	fmt.Println("Hello moon")
++	log.Printf("hello %s", "world")
++	log.Printf("hello %s", "moon")
+ }
+`
+
+	// A limit below the number of matches cancels the search, but the
+	// collected file match is still rewritten in full.
+	for _, limit := range []int{0, 1} {
+		req.Limit = limit
+		m, err := doSearch(ts.URL, &req)
+		if err != nil {
+			t.Fatalf("limit %d: %s", limit, err)
+		}
+		if len(m) != 1 {
+			t.Fatalf("limit %d: expected 1 file match, got %d", limit, len(m))
+		}
+		if m[0].Diff != want {
+			d, err := testutil.Diff(want, m[0].Diff)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Fatalf("limit %d: unexpected diff:\n%s", limit, d)
+		}
+	}
+}
+
 func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 	form := url.Values{
 		"Repo":            []string{string(p.Repo)},
@@ -412,6 +492,10 @@ func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 		"IncludePatterns": p.IncludePatterns,
 		"ExcludePattern":  []string{p.ExcludePattern},
 		"CombyRule":       []string{p.CombyRule},
+		"RewriteTemplate": []string{p.RewriteTemplate},
+	}
+	if p.Limit != 0 {
+		form.Set("Limit", strconv.Itoa(p.Limit))
 	}
 	if p.IsRegExp {
 		form.Set("IsRegExp", "true")
@@ -14,13 +14,18 @@ type limitedStreamCollector struct {
 	remaining int
 	limitHit  bool
 	cancel    context.CancelFunc
+
+	// parent is the context the collector was created with, which isn't
+	// canceled when the limit is hit.
+	parent context.Context
 }
 
-func newLimitedStreamCollector(ctx context.Context, limit int) (context.Context, context.CancelFunc, *limitedStreamCollector) {
-	ctx, cancel := context.WithCancel(ctx)
+func newLimitedStreamCollector(parent context.Context, limit int) (context.Context, context.CancelFunc, *limitedStreamCollector) {
+	ctx, cancel := context.WithCancel(parent)
 	s := &limitedStreamCollector{
 		cancel:    cancel,
 		remaining: limit,
+		parent:    parent,
 	}
 	return ctx, cancel, s
 }
@@ -69,6 +74,13 @@ func (m *limitedStreamCollector) Collected() []protocol.FileMatch {
 	return m.collected
 }
 
+// ParentContext returns the context the collector was created with. Unlike
+// the context returned by newLimitedStreamCollector, it isn't canceled when
+// the limit is hit, so it can be used to post-process the collected matches.
+func (m *limitedStreamCollector) ParentContext() context.Context {
+	return m.parent
+}
+
 func (m *limitedStreamCollector) Remaining() int {
 	m.mux.Lock()
 	defer m.mux.Unlock()
@@ -33,6 +33,8 @@ type Config struct {
 	DisableHealthServer  bool
 	HealthServerPort     int
 	MaximumRuntimePerJob time.Duration
+	MirrorCachePath      string
+	MirrorCacheMaxAge    time.Duration
 }
 
 func (c *Config) Load() {
@@ -51,6 +53,8 @@ func (c *Config) Load() {
 	c.DisableHealthServer = c.GetBool("EXECUTOR_DISABLE_HEALTHSERVER", "false", "Whether or not to disable the health server.")
 	c.HealthServerPort = c.GetInt("EXECUTOR_HEALTH_SERVER_PORT", "3192", "The port to listen on for the health server.")
 	c.MaximumRuntimePerJob = c.GetInterval("EXECUTOR_MAXIMUM_RUNTIME_PER_JOB", "30m", "The maximum wall time that can be spent on a single job.")
+	c.MirrorCachePath = c.Get("EXECUTOR_MIRROR_CACHE_PATH", "", "Where to store bare mirrors of repositories reused between jobs. If unset, each job clones its repository from scratch.")
+	c.MirrorCacheMaxAge = c.GetInterval("EXECUTOR_MIRROR_CACHE_MAX_AGE", "24h", "The duration after which an unused repository mirror is removed.")
 }
 
 func (c *Config) APIWorkerOptions(transport http.RoundTripper) apiworker.Options {
@@ -62,6 +66,8 @@ func (c *Config) APIWorkerOptions(transport http.RoundTripper) apiworker.Options
 		MaximumRuntimePerJob: c.MaximumRuntimePerJob,
 		GitServicePath:       "/.executors/git",
 		ClientOptions:        c.ClientOptions(transport),
+		MirrorCachePath:      c.MirrorCachePath,
+		MirrorCacheMaxAge:    c.MirrorCacheMaxAge,
 		RedactedValues: map[string]string{
 			// 🚨 SECURITY: Catch uses of the shared frontend token used to clone
 			// git repositories that make it into commands or stdout/stderr streams.
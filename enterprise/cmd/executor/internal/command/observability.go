@@ -12,6 +12,10 @@ type Operations struct {
 	SetupGitFetch             *observation.Operation
 	SetupAddRemote            *observation.Operation
 	SetupGitCheckout          *observation.Operation
+	SetupGitMirrorInit        *observation.Operation
+	SetupGitMirrorFetch       *observation.Operation
+	SetupGitMirrorFsck        *observation.Operation
+	SetupGitClone             *observation.Operation
 	SetupDockerPull           *observation.Operation
 	SetupDockerSave           *observation.Operation
 	SetupDockerLoad           *observation.Operation
@@ -43,6 +47,10 @@ func NewOperations(observationContext *observation.Context) *Operations {
 		SetupGitFetch:             op("setup.git.fetch"),
 		SetupAddRemote:            op("setup.git.add-remote"),
 		SetupGitCheckout:          op("setup.git.checkout"),
+		SetupGitMirrorInit:        op("setup.git.mirror.init"),
+		SetupGitMirrorFetch:       op("setup.git.mirror.fetch"),
+		SetupGitMirrorFsck:        op("setup.git.mirror.fsck"),
+		SetupGitClone:             op("setup.git.clone"),
 		SetupDockerPull:           op("setup.docker.pull"),
 		SetupDockerSave:           op("setup.docker.save"),
 		SetupDockerLoad:           op("setup.docker.load"),
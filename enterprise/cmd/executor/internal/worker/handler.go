@@ -25,6 +25,7 @@ type handler struct {
 	store         workerutil.Store
 	options       Options
 	operations    *command.Operations
+	mirrors       *mirrorCache
 	runnerFactory func(dir string, logger *command.Logger, options command.Options, operations *command.Operations) command.Runner
 }
 
@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+)
+
+// mirrorRef is the ref in each mirror repository that is updated to point to the
+// most recently fetched commit. Keeping the last fetched commit reachable ensures
+// that subsequent fetches can negotiate against it and only transfer new objects.
+const mirrorRef = "refs/executor/last-fetched"
+
+// mirrorJanitorInterval is the interval between checks for stale mirrors.
+const mirrorJanitorInterval = time.Hour
+
+// mirrorCache manages a directory of bare repositories on the executor host which
+// are used as a local source of objects when preparing job workspaces. Access to a
+// single mirror is serialized between the concurrent jobs of this executor process;
+// the cache directory must not be shared between executor processes.
+type mirrorCache struct {
+	root   string
+	maxAge time.Duration
+
+	m     sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newMirrorCache(root string, maxAge time.Duration) *mirrorCache {
+	return &mirrorCache{
+		root:   root,
+		maxAge: maxAge,
+		locks:  map[string]*sync.Mutex{},
+	}
+}
+
+// path returns the directory of the bare mirror repository for the given repository.
+// Repository names are escaped so that each mirror is a direct child of the cache root.
+func (c *mirrorCache) path(repositoryName string) string {
+	return filepath.Join(c.root, url.PathEscape(repositoryName))
+}
+
+// lock acquires the lock for the mirror of the given repository and returns a function
+// that releases it. The mirror's modification time is bumped so that the janitor does
+// not consider a mirror in active use as stale.
+func (c *mirrorCache) lock(repositoryName string) (unlock func()) {
+	l := c.repositoryLock(repositoryName)
+	l.Lock()
+
+	if err := touch(c.path(repositoryName)); err != nil && !os.IsNotExist(err) {
+		log15.Warn("Failed to update mirror access time", "repositoryName", repositoryName, "error", err)
+	}
+
+	return l.Unlock
+}
+
+// repositoryLock returns the mutex guarding the mirror of the given repository.
+func (c *mirrorCache) repositoryLock(repositoryName string) *sync.Mutex {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	l, ok := c.locks[repositoryName]
+	if !ok {
+		l = &sync.Mutex{}
+		c.locks[repositoryName] = l
+	}
+
+	return l
+}
+
+// NewJanitor returns a background routine that periodically removes mirrors that have
+// not been used by any job within the configured maximum age.
+func (c *mirrorCache) NewJanitor(interval time.Duration) goroutine.BackgroundRoutine {
+	return goroutine.NewPeriodicGoroutine(context.Background(), interval, goroutine.NewHandlerWithErrorMessage(
+		"executor mirror janitor",
+		c.removeStaleMirrors,
+	))
+}
+
+func (c *mirrorCache) removeStaleMirrors(ctx context.Context) error {
+	entries, err := os.ReadDir(c.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		repositoryName, err := url.PathUnescape(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		if err := c.removeIfStale(repositoryName); err != nil {
+			return errors.Wrap(err, "removing stale mirror")
+		}
+	}
+
+	return nil
+}
+
+func (c *mirrorCache) removeIfStale(repositoryName string) error {
+	// Do not use c.lock here as we do not want to bump the access time
+	l := c.repositoryLock(repositoryName)
+	l.Lock()
+	defer l.Unlock()
+
+	path := c.path(repositoryName)
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+	if time.Since(info.ModTime()) < c.maxAge {
+		return nil
+	}
+
+	log15.Info("Removing stale mirror", "repositoryName", repositoryName, "lastUsed", info.ModTime())
+	return os.RemoveAll(path)
+}
+
+func touch(path string) error {
+	now := time.Now()
+	return os.Chtimes(path, now, now)
+}
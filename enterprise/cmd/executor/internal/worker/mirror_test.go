@@ -0,0 +1,57 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRemoveStaleMirrors(t *testing.T) {
+	cache := newMirrorCache(t.TempDir(), time.Hour)
+
+	for _, repositoryName := range []string{"github.com/foo/fresh", "github.com/foo/stale"} {
+		if err := os.MkdirAll(cache.path(repositoryName), os.ModePerm); err != nil {
+			t.Fatalf("unexpected error creating mirror: %s", err)
+		}
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(cache.path("github.com/foo/stale"), old, old); err != nil {
+		t.Fatalf("unexpected error setting mirror access time: %s", err)
+	}
+
+	if err := cache.removeStaleMirrors(context.Background()); err != nil {
+		t.Fatalf("unexpected error removing stale mirrors: %s", err)
+	}
+
+	if _, err := os.Stat(cache.path("github.com/foo/fresh")); err != nil {
+		t.Errorf("expected fresh mirror to exist: %s", err)
+	}
+	if _, err := os.Stat(cache.path("github.com/foo/stale")); !os.IsNotExist(err) {
+		t.Errorf("expected stale mirror to be removed: %v", err)
+	}
+}
+
+func TestMirrorLockBumpsAccessTime(t *testing.T) {
+	cache := newMirrorCache(t.TempDir(), time.Hour)
+
+	path := cache.path("github.com/foo/bar")
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		t.Fatalf("unexpected error creating mirror: %s", err)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("unexpected error setting mirror access time: %s", err)
+	}
+
+	cache.lock("github.com/foo/bar")()
+
+	if err := cache.removeStaleMirrors(context.Background()); err != nil {
+		t.Fatalf("unexpected error removing stale mirrors: %s", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected recently locked mirror to exist: %s", err)
+	}
+}
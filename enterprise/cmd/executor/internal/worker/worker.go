@@ -48,13 +48,22 @@ type Options struct {
 
 	// MaximumRuntimePerJob is the maximum wall time that can be spent on a single job.
 	MaximumRuntimePerJob time.Duration
+
+	// MirrorCachePath is a directory on the host in which bare mirrors of job repositories
+	// are kept between jobs. If empty, each job clones its repository from scratch.
+	MirrorCachePath string
+
+	// MirrorCacheMaxAge is the duration after which a mirror that has not been used by any
+	// job is removed from the mirror cache.
+	MirrorCacheMaxAge time.Duration
 }
 
 // NewWorker creates a worker that polls a remote job queue API for work. The returned
 // routine contains both a worker that periodically polls for new work to perform, as well
 // as a heartbeat routine that will periodically hit the remote API with the work that is
 // currently being performed, which is necessary so the job queue API doesn't hand out jobs
-// it thinks may have been dropped.
+// it thinks may have been dropped. If a mirror cache is configured, the returned routine
+// also periodically removes stale mirrors.
 func NewWorker(options Options, observationContext *observation.Context) goroutine.BackgroundRoutine {
 	queueStore := apiclient.New(options.ClientOptions, observationContext)
 	store := &storeShim{queueName: options.QueueName, queueStore: queueStore}
@@ -70,7 +79,13 @@ func NewWorker(options Options, observationContext *observation.Context) gorouti
 		runnerFactory: command.NewRunner,
 	}
 
-	return workerutil.NewWorker(context.Background(), store, handler, options.WorkerOptions)
+	worker := workerutil.NewWorker(context.Background(), store, handler, options.WorkerOptions)
+	if options.MirrorCachePath == "" {
+		return worker
+	}
+
+	handler.mirrors = newMirrorCache(options.MirrorCachePath, options.MirrorCacheMaxAge)
+	return goroutine.CombinedRoutine{worker, handler.mirrors.NewJanitor(mirrorJanitorInterval)}
 }
 
 // connectToFrontend will ping the configured Sourcegraph instance until it receives a 200 response.
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
 
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/executor/internal/command"
 )
@@ -16,6 +17,12 @@ import (
 // while processing a single job. It is up to the caller to ensure that this directory is
 // removed after the job has finished processing. If a repository name is supplied, then
 // that repository will be cloned (through the frontend API) into the workspace.
+//
+// If a mirror cache is configured, the target commit is first fetched into a local bare
+// mirror of the repository, and the workspace is created as a local clone of that mirror,
+// so only new objects cross the network. The clone copies the mirror's object files rather
+// than hardlinking them: job steps can write to the workspace, and must not be able to
+// corrupt the objects that later jobs of the same repository clone.
 func (h *handler) prepareWorkspace(ctx context.Context, commandRunner command.Runner, repositoryName, commit string) (_ string, err error) {
 	tempDir, err := makeTempDir()
 	if err != nil {
@@ -39,14 +46,48 @@ func (h *handler) prepareWorkspace(ctx context.Context, commandRunner command.Ru
 			return "", err
 		}
 
-		gitCommands := []command.CommandSpec{
-			{Key: "setup.git.init", Command: []string{"git", "-C", tempDir, "init"}, Operation: h.operations.SetupGitInit},
-			{Key: "setup.git.fetch", Command: []string{"git", "-C", tempDir, "-c", "protocol.version=2", "fetch", cloneURL.String(), "-t", commit}, Operation: h.operations.SetupGitFetch},
-			{Key: "setup.git.add-remote", Command: []string{"git", "-C", tempDir, "remote", "add", "origin", repositoryName}, Operation: h.operations.SetupAddRemote},
-			{Key: "setup.git.checkout", Command: []string{"git", "-C", tempDir, "checkout", commit}, Operation: h.operations.SetupGitCheckout},
+		var gitCommands []command.CommandSpec
+		if h.mirrors == nil {
+			gitCommands = []command.CommandSpec{
+				{Key: "setup.git.init", Command: []string{"git", "-C", tempDir, "init"}, Operation: h.operations.SetupGitInit},
+				{Key: "setup.git.fetch", Command: []string{"git", "-C", tempDir, "-c", "protocol.version=2", "fetch", cloneURL.String(), "-t", commit}, Operation: h.operations.SetupGitFetch},
+				{Key: "setup.git.add-remote", Command: []string{"git", "-C", tempDir, "remote", "add", "origin", repositoryName}, Operation: h.operations.SetupAddRemote},
+				{Key: "setup.git.checkout", Command: []string{"git", "-C", tempDir, "checkout", commit}, Operation: h.operations.SetupGitCheckout},
+			}
+		} else {
+			// Hold the mirror lock until the workspace clone has completed so that a concurrent
+			// fetch (or the janitor) cannot modify the mirror while objects are being linked.
+			unlock := h.mirrors.lock(repositoryName)
+			defer unlock()
+
+			mirrorDir := h.mirrors.path(repositoryName)
+			if _, err := os.Stat(mirrorDir); err != nil {
+				if !os.IsNotExist(err) {
+					return "", err
+				}
+
+				gitCommands = append(gitCommands, command.CommandSpec{
+					Key:       "setup.git.mirror.init",
+					Command:   []string{"git", "init", "--bare", mirrorDir},
+					Operation: h.operations.SetupGitMirrorInit,
+				})
+			}
+
+			gitCommands = append(gitCommands, []command.CommandSpec{
+				{Key: "setup.git.mirror.fetch", Command: []string{"git", "-C", mirrorDir, "-c", "protocol.version=2", "-c", "gc.autoDetach=false", "fetch", cloneURL.String(), "-t", fmt.Sprintf("+%s:%s", commit, mirrorRef)}, Operation: h.operations.SetupGitMirrorFetch},
+				{Key: "setup.git.clone", Command: []string{"git", "clone", "--local", "--no-hardlinks", "--no-checkout", mirrorDir, tempDir}, Operation: h.operations.SetupGitClone},
+				{Key: "setup.git.set-remote", Command: []string{"git", "-C", tempDir, "remote", "set-url", "origin", repositoryName}, Operation: h.operations.SetupAddRemote},
+				{Key: "setup.git.checkout", Command: []string{"git", "-C", tempDir, "checkout", commit}, Operation: h.operations.SetupGitCheckout},
+			}...)
 		}
+
 		for _, spec := range gitCommands {
 			if err := commandRunner.Run(ctx, spec); err != nil {
+				if h.mirrors != nil && spec.Key == "setup.git.mirror.fetch" {
+					// We still hold the mirror lock here, so no other job is using the mirror.
+					h.removeCorruptMirror(ctx, commandRunner, repositoryName)
+				}
+
 				return "", errors.Wrap(err, fmt.Sprintf("failed %s", spec.Key))
 			}
 		}
@@ -59,6 +100,31 @@ func (h *handler) prepareWorkspace(ctx context.Context, commandRunner command.Ru
 	return tempDir, nil
 }
 
+// removeCorruptMirror removes the mirror of the given repository after a failed fetch if the
+// mirror is corrupt (e.g. a partial initialization or a broken pack). Fetches also fail when
+// the job is canceled or on transient network errors, which leave the mirror intact. The
+// caller must hold the mirror lock.
+func (h *handler) removeCorruptMirror(ctx context.Context, commandRunner command.Runner, repositoryName string) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	mirrorDir := h.mirrors.path(repositoryName)
+	err := commandRunner.Run(ctx, command.CommandSpec{
+		Key:       "setup.git.mirror.fsck",
+		Command:   []string{"git", "-C", mirrorDir, "fsck", "--connectivity-only", "--no-progress"},
+		Operation: h.operations.SetupGitMirrorFsck,
+	})
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+
+	log15.Warn("Removing corrupt mirror", "repositoryName", repositoryName, "error", err)
+	if err := os.RemoveAll(mirrorDir); err != nil {
+		log15.Error("Failed to remove corrupt mirror", "repositoryName", repositoryName, "error", err)
+	}
+}
+
 func makeURL(base, username, password string, path ...string) (*url.URL, error) {
 	u, err := makeRelativeURL(base, path...)
 	if err != nil {
@@ -0,0 +1,61 @@
+package bitbucketcloudoauth
+
+import (
+	"net/url"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const PkgName = "bitbucketcloudoauth"
+
+func Init(db dbutil.DB) {
+	conf.ContributeValidator(func(cfg conf.Unified) conf.Problems {
+		_, problems := parseConfig(&cfg, db)
+		return problems
+	})
+	go func() {
+		conf.Watch(func() {
+			newProviders, _ := parseConfig(conf.Get(), db)
+			if len(newProviders) == 0 {
+				providers.Update(PkgName, nil)
+			} else {
+				newProvidersList := make([]providers.Provider, 0, len(newProviders))
+				for _, p := range newProviders {
+					newProvidersList = append(newProvidersList, p)
+				}
+				providers.Update(PkgName, newProvidersList)
+			}
+		})
+	}()
+}
+
+func parseConfig(cfg *conf.Unified, db dbutil.DB) (ps map[schema.BitbucketCloudAuthProvider]providers.Provider, problems conf.Problems) {
+	ps = make(map[schema.BitbucketCloudAuthProvider]providers.Provider)
+	for _, pr := range cfg.AuthProviders {
+		if pr.BitbucketCloud == nil {
+			continue
+		}
+
+		if cfg.ExternalURL == "" {
+			problems = append(problems, conf.NewSiteProblem("`externalURL` was empty and it is needed to determine the OAuth callback URL."))
+			continue
+		}
+		externalURL, err := url.Parse(cfg.ExternalURL)
+		if err != nil {
+			problems = append(problems, conf.NewSiteProblem("Could not parse `externalURL`, which is needed to determine the OAuth callback URL."))
+			continue
+		}
+		callbackURL := *externalURL
+		callbackURL.Path = "/.auth/bitbucketcloud/callback"
+
+		provider, providerMessages := parseProvider(db, callbackURL.String(), pr.BitbucketCloud, pr)
+		problems = append(problems, conf.NewSiteProblems(providerMessages...)...)
+		if provider != nil {
+			ps[*pr.BitbucketCloud] = provider
+		}
+	}
+	return ps, problems
+}
@@ -0,0 +1,84 @@
+package bitbucketcloudoauth
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestParseConfig(t *testing.T) {
+	bitbucket := schema.BitbucketCloudAuthProvider{
+		ClientKey:    "my-client-key",
+		ClientSecret: "my-client-secret",
+		DisplayName:  "Bitbucket",
+		Type:         extsvc.TypeBitbucketCloud,
+	}
+
+	for _, tc := range []struct {
+		name         string
+		cfg          *conf.Unified
+		wantConfigs  map[schema.BitbucketCloudAuthProvider]oauth2.Config
+		wantProblems []string
+	}{
+		{
+			name:        "No configs",
+			cfg:         &conf.Unified{},
+			wantConfigs: map[schema.BitbucketCloudAuthProvider]oauth2.Config{},
+		},
+		{
+			name: "No externalURL",
+			cfg: &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{{BitbucketCloud: &bitbucket}},
+			}},
+			wantConfigs:  map[schema.BitbucketCloudAuthProvider]oauth2.Config{},
+			wantProblems: []string{"`externalURL` was empty and it is needed to determine the OAuth callback URL."},
+		},
+		{
+			name: "1 Bitbucket Cloud config",
+			cfg: &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				ExternalURL:   "https://sourcegraph.example.com",
+				AuthProviders: []schema.AuthProviders{{BitbucketCloud: &bitbucket}},
+			}},
+			wantConfigs: map[schema.BitbucketCloudAuthProvider]oauth2.Config{
+				bitbucket: {
+					RedirectURL:  "https://sourcegraph.example.com/.auth/bitbucketcloud/callback",
+					ClientID:     "my-client-key",
+					ClientSecret: "my-client-secret",
+					Endpoint: oauth2.Endpoint{
+						AuthURL:  "https://bitbucket.org/site/oauth2/authorize",
+						TokenURL: "https://bitbucket.org/site/oauth2/access_token",
+					},
+					Scopes: []string{"account", "email", "repository"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps, problems := parseConfig(tc.cfg, nil)
+
+			configs := make(map[schema.BitbucketCloudAuthProvider]oauth2.Config)
+			for k, p := range ps {
+				p := p.(*oauth.Provider)
+				if p.ServiceID != "https://bitbucket.org/" {
+					t.Errorf("have service ID %q, want %q", p.ServiceID, "https://bitbucket.org/")
+				}
+				if p.ServiceType != extsvc.TypeBitbucketCloud {
+					t.Errorf("have service type %q, want %q", p.ServiceType, extsvc.TypeBitbucketCloud)
+				}
+				configs[k] = p.OAuth2Config()
+			}
+			if diff := cmp.Diff(tc.wantConfigs, configs); diff != "" {
+				t.Errorf("configs mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantProblems, problems.Messages()); diff != "" {
+				t.Errorf("problems mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
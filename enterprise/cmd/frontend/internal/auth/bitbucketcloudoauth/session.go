@@ -0,0 +1,131 @@
+package bitbucketcloudoauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/hubspot"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/hubspot/hubspotutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+)
+
+type sessionIssuerHelper struct {
+	*extsvc.CodeHost
+	db          dbutil.DB
+	client      *bitbucketcloud.Client
+	clientID    string
+	allowSignup bool
+}
+
+func (s *sessionIssuerHelper) GetOrCreateUser(ctx context.Context, token *oauth2.Token, anonymousUserID, firstSourceURL string) (actr *actor.Actor, safeErrMsg string, err error) {
+	bbUser, err := UserFromContext(ctx)
+	if err != nil {
+		return nil, "Could not read Bitbucket Cloud user from callback request.", errors.Wrap(err, "could not read user from context")
+	}
+
+	login, err := auth.NormalizeUsername(bbUser.Login())
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", login), err
+	}
+
+	// 🚨 SECURITY: Ensure that the user email is confirmed
+	confirmedEmails := getConfirmedEmails(ctx, s.client.WithToken(token.AccessToken))
+	if len(confirmedEmails) == 0 {
+		return nil, "Could not get confirmed email for Bitbucket Cloud user. Check that your Bitbucket Cloud account has a confirmed email that matches one of your Sourcegraph verified emails.", errors.New("no confirmed email")
+	}
+
+	// Try every confirmed email in succession until the first that succeeds
+	var data extsvc.AccountData
+	bitbucketcloud.SetExternalAccountData(&data, bbUser, token)
+	var (
+		firstSafeErrMsg string
+		firstErr        error
+	)
+	for i, email := range confirmedEmails {
+		userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, s.db, auth.GetAndSaveUserOp{
+			UserProps: database.NewUser{
+				Username:        login,
+				Email:           email,
+				EmailIsVerified: true,
+				DisplayName:     bbUser.DisplayName,
+				AvatarURL:       bbUser.Links.Avatar.Href,
+			},
+			ExternalAccount: extsvc.AccountSpec{
+				ServiceType: s.ServiceType,
+				ServiceID:   s.ServiceID,
+				ClientID:    s.clientID,
+				AccountID:   bbUser.UUID,
+			},
+			ExternalAccountData: data,
+			CreateIfNotExist:    s.allowSignup,
+		})
+		if err == nil {
+			go hubspotutil.SyncUser(email, hubspotutil.SignupEventID, &hubspot.ContactProperties{
+				AnonymousUserID: anonymousUserID,
+				FirstSourceURL:  firstSourceURL,
+			})
+			return actor.FromUser(userID), "", nil // success
+		}
+		if i == 0 {
+			firstSafeErrMsg, firstErr = safeErrMsg, err
+		}
+	}
+	// On failure, return the first error
+	return nil, fmt.Sprintf("No user exists matching any of the confirmed emails: %s.\n\nFirst error was: %s", strings.Join(confirmedEmails, ", "), firstSafeErrMsg), firstErr
+}
+
+// getConfirmedEmails returns the confirmed emails of the user the client's token belongs to,
+// with the primary email first.
+func getConfirmedEmails(ctx context.Context, client *bitbucketcloud.Client) (confirmedEmails []string) {
+	emails, err := client.CurrentUserEmails(ctx)
+	if err != nil {
+		log15.Warn("Could not get Bitbucket Cloud authenticated user emails", "error", err)
+		return nil
+	}
+
+	for _, email := range emails {
+		if !email.IsConfirmed {
+			continue
+		}
+		if email.IsPrimary {
+			confirmedEmails = append([]string{email.Email}, confirmedEmails...)
+			continue
+		}
+		confirmedEmails = append(confirmedEmails, email.Email)
+	}
+	return confirmedEmails
+}
+
+func (s *sessionIssuerHelper) CreateCodeHostConnection(ctx context.Context, token *oauth2.Token, providerID string) (safeErrMsg string, err error) {
+	return "Creating a Bitbucket Cloud code host connection from the OAuth flow is not supported.", errors.New("creating code host connections is not supported for Bitbucket Cloud")
+}
+
+func (s *sessionIssuerHelper) DeleteStateCookie(w http.ResponseWriter) {
+	stateConfig := getStateConfig()
+	stateConfig.MaxAge = -1
+	http.SetCookie(w, oauth.NewCookie(stateConfig, ""))
+}
+
+func (s *sessionIssuerHelper) SessionData(token *oauth2.Token) oauth.SessionData {
+	return oauth.SessionData{
+		ID: providers.ConfigID{
+			ID:   s.ServiceID,
+			Type: s.ServiceType,
+		},
+		AccessToken: token.AccessToken,
+		TokenType:   token.Type(),
+	}
+}
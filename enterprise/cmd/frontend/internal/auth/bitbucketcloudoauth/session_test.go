@@ -0,0 +1,109 @@
+package bitbucketcloudoauth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+)
+
+func TestSessionIssuerHelper_GetOrCreateUser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer alice-oauth-token" || r.URL.Path != "/2.0/user/emails" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"values": []map[string]interface{}{
+				{"email": "alice@unconfirmed.example.com", "is_primary": false, "is_confirmed": false},
+				{"email": "alice@old.example.com", "is_primary": false, "is_confirmed": true},
+				{"email": "alice@example.com", "is_primary": true, "is_confirmed": true},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	apiURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codeHost := extsvc.NewCodeHost(&url.URL{Scheme: "https", Host: "bitbucket.org"}, extsvc.TypeBitbucketCloud)
+
+	s := &sessionIssuerHelper{
+		CodeHost: codeHost,
+		client:   bitbucketcloud.NewClient(apiURL, srv.Client()),
+		clientID: "my-client-key",
+	}
+
+	alice := &bitbucketcloud.User{UUID: "{a1}", Username: "alice", DisplayName: "Alice"}
+	alice.Links.Avatar.Href = "https://bitbucket.org/avatar/alice"
+
+	t.Run("tries confirmed emails in order", func(t *testing.T) {
+		var emails []string
+		auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+			emails = append(emails, op.UserProps.Email)
+
+			if diff := cmp.Diff(extsvc.AccountSpec{
+				ServiceType: extsvc.TypeBitbucketCloud,
+				ServiceID:   "https://bitbucket.org/",
+				ClientID:    "my-client-key",
+				AccountID:   "{a1}",
+			}, op.ExternalAccount); diff != "" {
+				t.Errorf("account spec mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(database.NewUser{
+				Username:        "alice",
+				Email:           op.UserProps.Email,
+				EmailIsVerified: true,
+				DisplayName:     "Alice",
+				AvatarURL:       "https://bitbucket.org/avatar/alice",
+			}, op.UserProps); diff != "" {
+				t.Errorf("user props mismatch (-want +got):\n%s", diff)
+			}
+			if op.CreateIfNotExist {
+				t.Error("want CreateIfNotExist to be false")
+			}
+
+			if op.UserProps.Email != "alice@old.example.com" {
+				return 0, "no such user", database.MockUserNotFoundErr
+			}
+			return 42, "", nil
+		}
+		defer func() { auth.MockGetAndSaveUser = nil }()
+
+		ctx := WithUser(context.Background(), alice)
+		actr, _, err := s.GetOrCreateUser(ctx, &oauth2.Token{AccessToken: "alice-oauth-token"}, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actr.UID != 42 {
+			t.Errorf("have user ID %d, want 42", actr.UID)
+		}
+		if diff := cmp.Diff([]string{"alice@example.com", "alice@old.example.com"}, emails); diff != "" {
+			t.Errorf("emails mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("no confirmed email", func(t *testing.T) {
+		auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+			t.Fatal("GetAndSaveUser should not be called")
+			return 0, "", nil
+		}
+		defer func() { auth.MockGetAndSaveUser = nil }()
+
+		ctx := WithUser(context.Background(), alice)
+		if _, _, err := s.GetOrCreateUser(ctx, &oauth2.Token{AccessToken: "revoked"}, "", ""); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package giteaoauth
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestParseConfig(t *testing.T) {
+	gitea := schema.GiteaAuthProvider{
+		ClientID:     "my-client-id",
+		ClientSecret: "my-client-secret",
+		DisplayName:  "Gitea",
+		Type:         extsvc.TypeGitea,
+		Url:          "https://gitea.example.com/sub",
+	}
+
+	for _, tc := range []struct {
+		name         string
+		cfg          *conf.Unified
+		wantConfigs  map[schema.GiteaAuthProvider]oauth2.Config
+		wantProblems []string
+	}{
+		{
+			name:        "No configs",
+			cfg:         &conf.Unified{},
+			wantConfigs: map[schema.GiteaAuthProvider]oauth2.Config{},
+		},
+		{
+			name: "No externalURL",
+			cfg: &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{{Gitea: &gitea}},
+			}},
+			wantConfigs:  map[schema.GiteaAuthProvider]oauth2.Config{},
+			wantProblems: []string{"`externalURL` was empty and it is needed to determine the OAuth callback URL."},
+		},
+		{
+			name: "1 Gitea config",
+			cfg: &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				ExternalURL:   "https://sourcegraph.example.com",
+				AuthProviders: []schema.AuthProviders{{Gitea: &gitea}},
+			}},
+			wantConfigs: map[schema.GiteaAuthProvider]oauth2.Config{
+				gitea: {
+					RedirectURL:  "https://sourcegraph.example.com/.auth/gitea/callback",
+					ClientID:     "my-client-id",
+					ClientSecret: "my-client-secret",
+					Endpoint: oauth2.Endpoint{
+						AuthURL:  "https://gitea.example.com/sub/login/oauth/authorize",
+						TokenURL: "https://gitea.example.com/sub/login/oauth/access_token",
+					},
+					Scopes: []string{"read:user", "read:repository"},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps, problems := parseConfig(tc.cfg, nil)
+
+			configs := make(map[schema.GiteaAuthProvider]oauth2.Config)
+			for k, p := range ps {
+				p := p.(*oauth.Provider)
+				if p.ServiceID != "https://gitea.example.com/sub/" {
+					t.Errorf("have service ID %q, want %q", p.ServiceID, "https://gitea.example.com/sub/")
+				}
+				if p.ServiceType != extsvc.TypeGitea {
+					t.Errorf("have service type %q, want %q", p.ServiceType, extsvc.TypeGitea)
+				}
+				configs[k] = p.OAuth2Config()
+			}
+			if diff := cmp.Diff(tc.wantConfigs, configs); diff != "" {
+				t.Errorf("configs mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantProblems, problems.Messages()); diff != "" {
+				t.Errorf("problems mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package giteaoauth
+
+import (
+	"net/http"
+
+	"github.com/cockroachdb/errors"
+	"github.com/dghubble/gologin"
+	oauth2Login "github.com/dghubble/gologin/oauth2"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+)
+
+func LoginHandler(config *oauth2.Config, failure http.Handler) http.Handler {
+	return oauth2Login.LoginHandler(config, failure)
+}
+
+func CallbackHandler(config *oauth2.Config, client *gitea.Client, success, failure http.Handler) http.Handler {
+	success = giteaHandler(client, success, failure)
+	return oauth2Login.CallbackHandler(config, success, failure)
+}
+
+func giteaHandler(client *gitea.Client, success, failure http.Handler) http.Handler {
+	if failure == nil {
+		failure = gologin.DefaultFailureHandler
+	}
+	fn := func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		token, err := oauth2Login.TokenFromContext(ctx)
+		if err != nil {
+			ctx = gologin.WithError(ctx, err)
+			failure.ServeHTTP(w, req.WithContext(ctx))
+			return
+		}
+
+		user, err := client.WithToken(token.AccessToken).AuthenticatedUser(ctx)
+		err = validateResponse(user, err)
+		if err != nil {
+			ctx = gologin.WithError(ctx, err)
+			failure.ServeHTTP(w, req.WithContext(ctx))
+			return
+		}
+		ctx = WithUser(ctx, user)
+		success.ServeHTTP(w, req.WithContext(ctx))
+	}
+	return http.HandlerFunc(fn)
+}
+
+// validateResponse returns an error if the given Gitea user or error are unexpected. Returns nil
+// if they are valid.
+func validateResponse(user *gitea.User, err error) error {
+	if err != nil {
+		return errors.Wrap(err, "unable to get Gitea user")
+	}
+	if user == nil || user.ID == 0 {
+		return errors.Errorf("unable to get Gitea user: bad user info %#+v", user)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package giteaoauth
+
+import (
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const authPrefix = auth.AuthURLPrefix + "/gitea"
+
+func init() {
+	oauth.AddIsOAuth(func(p schema.AuthProviders) bool {
+		return p.Gitea != nil
+	})
+}
+
+func Middleware(db dbutil.DB) *auth.Middleware {
+	return &auth.Middleware{
+		API: func(next http.Handler) http.Handler {
+			return oauth.NewHandler(db, extsvc.TypeGitea, authPrefix, true, next)
+		},
+		App: func(next http.Handler) http.Handler {
+			return oauth.NewHandler(db, extsvc.TypeGitea, authPrefix, false, next)
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package giteaoauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/dghubble/gologin"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const sessionKey = "giteaoauth@0"
+
+func parseProvider(db dbutil.DB, callbackURL string, p *schema.GiteaAuthProvider, sourceCfg schema.AuthProviders) (provider *oauth.Provider, messages []string) {
+	parsedURL, err := url.Parse(p.Url)
+	if err != nil {
+		messages = append(messages, fmt.Sprintf("Could not parse Gitea URL %q. You will not be able to login via this Gitea instance.", p.Url))
+		return nil, messages
+	}
+	codeHost := extsvc.NewCodeHost(parsedURL, extsvc.TypeGitea)
+	client := gitea.NewClient(codeHost.BaseURL, nil)
+
+	return oauth.NewProvider(oauth.ProviderOp{
+		AuthPrefix: authPrefix,
+		OAuth2Config: func(extraScopes ...string) oauth2.Config {
+			return oauth2.Config{
+				RedirectURL:  callbackURL,
+				ClientID:     p.ClientID,
+				ClientSecret: p.ClientSecret,
+				Scopes:       requestedScopes(extraScopes),
+				Endpoint:     gitea.OAuthEndpoint(codeHost.BaseURL),
+			}
+		},
+		SourceConfig: sourceCfg,
+		StateConfig:  getStateConfig(),
+		ServiceID:    codeHost.ServiceID,
+		ServiceType:  codeHost.ServiceType,
+		Login: func(oauth2Cfg oauth2.Config) http.Handler {
+			return LoginHandler(&oauth2Cfg, nil)
+		},
+		Callback: func(oauth2Cfg oauth2.Config) http.Handler {
+			return CallbackHandler(
+				&oauth2Cfg,
+				client,
+				oauth.SessionIssuer(&sessionIssuerHelper{
+					CodeHost:    codeHost,
+					db:          db,
+					client:      client,
+					clientID:    p.ClientID,
+					allowSignup: p.AllowSignup,
+				}, sessionKey),
+				nil,
+			)
+		},
+	}), messages
+}
+
+func getStateConfig() gologin.CookieConfig {
+	cfg := gologin.CookieConfig{
+		Name:     "gitea-state-cookie",
+		Path:     "/",
+		MaxAge:   120, // 120 seconds
+		HTTPOnly: true,
+		Secure:   conf.IsExternalURLSecure(),
+	}
+	return cfg
+}
+
+func requestedScopes(extraScopes []string) []string {
+	// Gitea versions before 1.19 ignore scopes and grant full access to OAuth tokens. The
+	// repository scope is needed to sync the repository permissions of the user.
+	scopes := []string{"read:user", "read:repository"}
+	// Append extra scopes and ensure there are no duplicates
+	for _, s := range extraScopes {
+		var found bool
+		for _, inner := range scopes {
+			if inner == s {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return scopes
+}
@@ -0,0 +1,132 @@
+package giteaoauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/hubspot"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/hubspot/hubspotutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/oauth"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+)
+
+type sessionIssuerHelper struct {
+	*extsvc.CodeHost
+	db          dbutil.DB
+	client      *gitea.Client
+	clientID    string
+	allowSignup bool
+}
+
+func (s *sessionIssuerHelper) GetOrCreateUser(ctx context.Context, token *oauth2.Token, anonymousUserID, firstSourceURL string) (actr *actor.Actor, safeErrMsg string, err error) {
+	gUser, err := UserFromContext(ctx)
+	if err != nil {
+		return nil, "Could not read Gitea user from callback request.", errors.Wrap(err, "could not read user from context")
+	}
+
+	login, err := auth.NormalizeUsername(gUser.Login)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", login), err
+	}
+
+	// 🚨 SECURITY: Ensure that the user email is verified
+	verifiedEmails := getVerifiedEmails(ctx, s.client.WithToken(token.AccessToken))
+	if len(verifiedEmails) == 0 {
+		return nil, "Could not get verified email for Gitea user. Check that your Gitea account has a verified email that matches one of your Sourcegraph verified emails.", errors.New("no verified email")
+	}
+
+	// Try every verified email in succession until the first that succeeds
+	var data extsvc.AccountData
+	gitea.SetExternalAccountData(&data, gUser, token)
+	var (
+		firstSafeErrMsg string
+		firstErr        error
+	)
+	for i, email := range verifiedEmails {
+		userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, s.db, auth.GetAndSaveUserOp{
+			UserProps: database.NewUser{
+				Username:        login,
+				Email:           email,
+				EmailIsVerified: true,
+				DisplayName:     gUser.FullName,
+				AvatarURL:       gUser.AvatarURL,
+			},
+			ExternalAccount: extsvc.AccountSpec{
+				ServiceType: s.ServiceType,
+				ServiceID:   s.ServiceID,
+				ClientID:    s.clientID,
+				AccountID:   strconv.FormatInt(gUser.ID, 10),
+			},
+			ExternalAccountData: data,
+			CreateIfNotExist:    s.allowSignup,
+		})
+		if err == nil {
+			go hubspotutil.SyncUser(email, hubspotutil.SignupEventID, &hubspot.ContactProperties{
+				AnonymousUserID: anonymousUserID,
+				FirstSourceURL:  firstSourceURL,
+			})
+			return actor.FromUser(userID), "", nil // success
+		}
+		if i == 0 {
+			firstSafeErrMsg, firstErr = safeErrMsg, err
+		}
+	}
+	// On failure, return the first error
+	return nil, fmt.Sprintf("No user exists matching any of the verified emails: %s.\n\nFirst error was: %s", strings.Join(verifiedEmails, ", "), firstSafeErrMsg), firstErr
+}
+
+// getVerifiedEmails returns the verified emails of the user the client's token belongs to,
+// with the primary email first.
+func getVerifiedEmails(ctx context.Context, client *gitea.Client) (verifiedEmails []string) {
+	emails, err := client.ListEmails(ctx)
+	if err != nil {
+		log15.Warn("Could not get Gitea authenticated user emails", "error", err)
+		return nil
+	}
+
+	for _, email := range emails {
+		if !email.Verified {
+			continue
+		}
+		if email.Primary {
+			verifiedEmails = append([]string{email.Email}, verifiedEmails...)
+			continue
+		}
+		verifiedEmails = append(verifiedEmails, email.Email)
+	}
+	return verifiedEmails
+}
+
+func (s *sessionIssuerHelper) CreateCodeHostConnection(ctx context.Context, token *oauth2.Token, providerID string) (safeErrMsg string, err error) {
+	return "Creating a Gitea code host connection from the OAuth flow is not supported.", errors.New("creating code host connections is not supported for Gitea")
+}
+
+func (s *sessionIssuerHelper) DeleteStateCookie(w http.ResponseWriter) {
+	stateConfig := getStateConfig()
+	stateConfig.MaxAge = -1
+	http.SetCookie(w, oauth.NewCookie(stateConfig, ""))
+}
+
+func (s *sessionIssuerHelper) SessionData(token *oauth2.Token) oauth.SessionData {
+	return oauth.SessionData{
+		ID: providers.ConfigID{
+			ID:   s.ServiceID,
+			Type: s.ServiceType,
+		},
+		AccessToken: token.AccessToken,
+		TokenType:   token.Type(),
+	}
+}
@@ -0,0 +1,99 @@
+package giteaoauth
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+)
+
+func TestSessionIssuerHelper_GetOrCreateUser(t *testing.T) {
+	f := gitea.NewTestFixture()
+	srv := gitea.NewTestServer(t, f)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codeHost := extsvc.NewCodeHost(u, extsvc.TypeGitea)
+
+	s := &sessionIssuerHelper{
+		CodeHost:    codeHost,
+		client:      gitea.NewClient(codeHost.BaseURL, srv.Client()),
+		clientID:    "my-client-id",
+		allowSignup: true,
+	}
+
+	var ops []auth.GetAndSaveUserOp
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+		ops = append(ops, op)
+		return 42, "", nil
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	t.Run("verified email", func(t *testing.T) {
+		ops = nil
+		alice := f.UserTokens["alice-oauth-token"]
+		ctx := WithUser(context.Background(), alice)
+
+		actr, _, err := s.GetOrCreateUser(ctx, &oauth2.Token{AccessToken: "alice-oauth-token"}, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actr.UID != 42 {
+			t.Errorf("have user ID %d, want 42", actr.UID)
+		}
+
+		if len(ops) != 1 {
+			t.Fatalf("have %d calls to GetAndSaveUser, want 1", len(ops))
+		}
+		op := ops[0]
+		wantProps := database.NewUser{
+			Username:        "alice",
+			Email:           "alice@sourcegraph.test",
+			EmailIsVerified: true,
+			DisplayName:     "Alice Liddell",
+		}
+		if diff := cmp.Diff(wantProps, op.UserProps); diff != "" {
+			t.Errorf("user props mismatch (-want +got):\n%s", diff)
+		}
+		wantSpec := extsvc.AccountSpec{
+			ServiceType: extsvc.TypeGitea,
+			ServiceID:   codeHost.ServiceID,
+			ClientID:    "my-client-id",
+			AccountID:   "2",
+		}
+		if diff := cmp.Diff(wantSpec, op.ExternalAccount); diff != "" {
+			t.Errorf("account spec mismatch (-want +got):\n%s", diff)
+		}
+		if !op.CreateIfNotExist {
+			t.Error("want CreateIfNotExist to be true")
+		}
+
+		_, tok, err := gitea.GetExternalAccountData(&op.ExternalAccountData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.AccessToken != "alice-oauth-token" {
+			t.Errorf("have token %q in account data, want %q", tok.AccessToken, "alice-oauth-token")
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		ops = nil
+		ctx := WithUser(context.Background(), &gitea.User{ID: 2, Login: "alice"})
+
+		if _, _, err := s.GetOrCreateUser(ctx, &oauth2.Token{AccessToken: "revoked"}, "", ""); err == nil {
+			t.Fatal("expected error")
+		}
+		if len(ops) != 0 {
+			t.Fatalf("have %d calls to GetAndSaveUser, want 0", len(ops))
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package giteaoauth
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+)
+
+// unexported key type prevents collisions
+type key int
+
+const userKey key = iota
+
+// WithUser returns a copy of ctx that stores the Gitea User.
+func WithUser(ctx context.Context, user *gitea.User) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext returns the Gitea User from the ctx.
+func UserFromContext(ctx context.Context) (*gitea.User, error) {
+	user, ok := ctx.Value(userKey).(*gitea.User)
+	if !ok {
+		return nil, errors.Errorf("gitea: Context missing Gitea User")
+	}
+	return user, nil
+}
@@ -0,0 +1,211 @@
+// Package groupsync applies the mappings from the groups of users in an identity provider (such
+// as a SAML or OpenID Connect provider) to Sourcegraph organizations and site admin status.
+package groupsync
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// Apply updates the organization memberships and site admin status of the user to match the
+// mappings of the groups that the identity provider says they're in. It is called every time the
+// user signs in, so that users who leave a group also lose what the group gave them.
+//
+// Only the organizations in the mappings are changed, and site admin status is only changed if
+// there are site admin mappings. If groups is empty, the user is treated as a member of no groups,
+// which is also how callers must handle identity providers that leave out the groups of the user.
+//
+// 🚨 SECURITY: The groups must come from an authenticated response of the identity provider.
+func Apply(ctx context.Context, db dbutil.DB, userID int32, mappings *schema.AuthGroupMappings, groups []string) error {
+	if mappings == nil {
+		return nil
+	}
+	return apply(ctx, dbStore{db: db}, userID, mappings, groups)
+}
+
+func apply(ctx context.Context, s store, userID int32, mappings *schema.AuthGroupMappings, groups []string) error {
+	inGroup := func(group string) bool {
+		for _, g := range groups {
+			if strings.EqualFold(strings.TrimSpace(g), group) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(mappings.Orgs) > 0 {
+		if err := syncOrgs(ctx, s, userID, mappings.Orgs, inGroup); err != nil {
+			return err
+		}
+	}
+	if len(mappings.SiteAdmin) > 0 {
+		wantSiteAdmin := false
+		for _, g := range mappings.SiteAdmin {
+			if inGroup(g) {
+				wantSiteAdmin = true
+				break
+			}
+		}
+		if err := syncSiteAdmin(ctx, s, userID, wantSiteAdmin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func syncOrgs(ctx context.Context, s store, userID int32, mappings []*schema.AuthGroupOrgMapping, inGroup func(string) bool) error {
+	// An organization may be mapped from several groups, so the user is a member if they are in
+	// any of them.
+	want := map[int32]bool{}
+	names := map[int32]string{}
+	for _, m := range mappings {
+		org, err := s.GetOrgByName(ctx, m.Org)
+		if err != nil {
+			if errcode.IsNotFound(err) {
+				log15.Warn("Skipping group mapping to nonexistent organization.", "group", m.Group, "org", m.Org)
+				continue
+			}
+			return errors.Wrapf(err, "looking up organization %q", m.Org)
+		}
+		want[org.ID] = want[org.ID] || inGroup(m.Group)
+		names[org.ID] = org.Name
+	}
+
+	have, err := s.ListOrgIDsOfUser(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "listing organizations of user")
+	}
+	isMember := map[int32]bool{}
+	for _, id := range have {
+		isMember[id] = true
+	}
+
+	for orgID, member := range want {
+		switch {
+		case member && !isMember[orgID]:
+			if err := s.AddOrgMember(ctx, orgID, userID); err != nil {
+				return errors.Wrapf(err, "adding user to organization %q", names[orgID])
+			}
+			log15.Info("Added user to organization from group mappings.", "user", userID, "org", names[orgID])
+		case !member && isMember[orgID]:
+			if err := s.RemoveOrgMember(ctx, orgID, userID); err != nil {
+				return errors.Wrapf(err, "removing user from organization %q", names[orgID])
+			}
+			log15.Info("Removed user from organization from group mappings.", "user", userID, "org", names[orgID])
+		}
+	}
+	return nil
+}
+
+func syncSiteAdmin(ctx context.Context, s store, userID int32, siteAdmin bool) error {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "getting user")
+	}
+	if user.SiteAdmin == siteAdmin {
+		return nil
+	}
+	if !siteAdmin {
+		// 🚨 SECURITY: A change of groups in the identity provider must not lock everyone out of
+		// the site, so the last site admin keeps their status.
+		err := backend.CheckNotLastSiteAdmin(ctx, user, s.CountActiveSiteAdmins)
+		if errors.Is(err, backend.ErrLastSiteAdmin) {
+			log15.Warn("Skipping removal of site admin status from group mappings for the last site admin.", "user", userID)
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "counting site admins")
+		}
+	}
+	if err := s.SetIsSiteAdmin(ctx, userID, siteAdmin); err != nil {
+		return errors.Wrap(err, "setting site admin status")
+	}
+	log15.Info("Changed site admin status of user from group mappings.", "user", userID, "siteAdmin", siteAdmin)
+
+	from, to := "role_user", "role_site_admin"
+	if !siteAdmin {
+		from, to = to, from
+	}
+	argument, _ := json.Marshal(map[string]interface{}{"from": from, "to": to, "for": userID, "by": "groupMappings"})
+	if err := s.LogSecurityEvent(ctx, &database.SecurityEvent{
+		Name:      database.SecurityEventNameRoleChangeGranted,
+		UserID:    uint32(userID),
+		Argument:  argument,
+		Source:    "BACKEND",
+		Timestamp: time.Now(),
+	}); err != nil {
+		log15.Error("groupsync: failed to log security event", "err", err)
+	}
+	return nil
+}
+
+// store is the subset of the database that Apply uses.
+type store interface {
+	GetOrgByName(ctx context.Context, name string) (*types.Org, error)
+	ListOrgIDsOfUser(ctx context.Context, userID int32) ([]int32, error)
+	AddOrgMember(ctx context.Context, orgID, userID int32) error
+	RemoveOrgMember(ctx context.Context, orgID, userID int32) error
+	GetUser(ctx context.Context, id int32) (*types.User, error)
+	SetIsSiteAdmin(ctx context.Context, id int32, siteAdmin bool) error
+	CountActiveSiteAdmins(ctx context.Context) (int, error)
+	LogSecurityEvent(ctx context.Context, e *database.SecurityEvent) error
+}
+
+type dbStore struct {
+	db dbutil.DB
+}
+
+func (s dbStore) GetOrgByName(ctx context.Context, name string) (*types.Org, error) {
+	return database.Orgs(s.db).GetByName(ctx, name)
+}
+
+func (s dbStore) ListOrgIDsOfUser(ctx context.Context, userID int32) ([]int32, error) {
+	memberships, err := database.OrgMembers(s.db).GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int32, 0, len(memberships))
+	for _, m := range memberships {
+		ids = append(ids, m.OrgID)
+	}
+	return ids, nil
+}
+
+func (s dbStore) AddOrgMember(ctx context.Context, orgID, userID int32) error {
+	_, err := database.OrgMembers(s.db).Create(ctx, orgID, userID)
+	return err
+}
+
+func (s dbStore) RemoveOrgMember(ctx context.Context, orgID, userID int32) error {
+	return database.OrgMembers(s.db).Remove(ctx, orgID, userID)
+}
+
+func (s dbStore) GetUser(ctx context.Context, id int32) (*types.User, error) {
+	return database.Users(s.db).GetByID(ctx, id)
+}
+
+func (s dbStore) SetIsSiteAdmin(ctx context.Context, id int32, siteAdmin bool) error {
+	return database.Users(s.db).SetIsSiteAdmin(ctx, id, siteAdmin)
+}
+
+func (s dbStore) CountActiveSiteAdmins(ctx context.Context) (int, error) {
+	return backend.CountActiveSiteAdmins(ctx, s.db)
+}
+
+func (s dbStore) LogSecurityEvent(ctx context.Context, e *database.SecurityEvent) error {
+	// LogEvent only records events on Sourcegraph.com, but changes of site admin status must
+	// be recorded on all instances.
+	return database.SecurityEventLogs(s.db).Insert(ctx, e)
+}
@@ -0,0 +1,212 @@
+package groupsync
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type fakeStore struct {
+	orgs      map[string]int32          // name -> ID
+	members   map[int32]map[int32]bool  // user ID -> org IDs
+	siteAdmin map[int32]bool            // user ID -> site admin
+	events    []*database.SecurityEvent // logged security events
+}
+
+func (s *fakeStore) GetOrgByName(ctx context.Context, name string) (*types.Org, error) {
+	id, ok := s.orgs[name]
+	if !ok {
+		return nil, &database.OrgNotFoundError{Message: name}
+	}
+	return &types.Org{ID: id, Name: name}, nil
+}
+
+func (s *fakeStore) ListOrgIDsOfUser(ctx context.Context, userID int32) ([]int32, error) {
+	var ids []int32
+	for id := range s.members[userID] {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *fakeStore) AddOrgMember(ctx context.Context, orgID, userID int32) error {
+	if s.members[userID] == nil {
+		s.members[userID] = map[int32]bool{}
+	}
+	s.members[userID][orgID] = true
+	return nil
+}
+
+func (s *fakeStore) RemoveOrgMember(ctx context.Context, orgID, userID int32) error {
+	delete(s.members[userID], orgID)
+	return nil
+}
+
+func (s *fakeStore) GetUser(ctx context.Context, id int32) (*types.User, error) {
+	return &types.User{ID: id, SiteAdmin: s.siteAdmin[id]}, nil
+}
+
+func (s *fakeStore) SetIsSiteAdmin(ctx context.Context, id int32, siteAdmin bool) error {
+	s.siteAdmin[id] = siteAdmin
+	return nil
+}
+
+func (s *fakeStore) CountActiveSiteAdmins(ctx context.Context) (int, error) {
+	n := 0
+	for _, siteAdmin := range s.siteAdmin {
+		if siteAdmin {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *fakeStore) LogSecurityEvent(ctx context.Context, e *database.SecurityEvent) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *fakeStore) orgIDs(userID int32) []int32 {
+	ids, _ := s.ListOrgIDsOfUser(context.Background(), userID)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func TestApply(t *testing.T) {
+	const (
+		userID      = 1
+		otherUserID = 2
+		engineering = 10
+		sales       = 11
+		unmanaged   = 12
+	)
+	mappings := &schema.AuthGroupMappings{
+		Orgs: []*schema.AuthGroupOrgMapping{
+			{Group: "eng", Org: "engineering"},
+			{Group: "eng-contractors", Org: "engineering"},
+			{Group: "sales", Org: "sales"},
+			{Group: "missing", Org: "nonexistent"},
+		},
+		SiteAdmin: []string{"sourcegraph-admins"},
+	}
+
+	tests := map[string]struct {
+		mappings      *schema.AuthGroupMappings
+		groups        []string
+		haveOrgs      []int32
+		haveSiteAdmin bool
+		otherAdmin    bool
+
+		wantOrgs      []int32
+		wantSiteAdmin bool
+		wantEvents    int
+	}{
+		"adds to mapped orgs": {
+			mappings: mappings,
+			groups:   []string{"eng", "sales", "other"},
+			wantOrgs: []int32{engineering, sales},
+		},
+		"group names are case-insensitive": {
+			mappings: mappings,
+			groups:   []string{"ENG"},
+			wantOrgs: []int32{engineering},
+		},
+		"several groups map to an org": {
+			mappings: mappings,
+			groups:   []string{"eng-contractors"},
+			haveOrgs: []int32{engineering},
+			wantOrgs: []int32{engineering},
+		},
+		"removes from mapped orgs but not unmanaged orgs": {
+			mappings: mappings,
+			groups:   []string{"eng"},
+			haveOrgs: []int32{engineering, sales, unmanaged},
+			wantOrgs: []int32{engineering, unmanaged},
+		},
+		"empty groups": {
+			mappings:      mappings,
+			groups:        []string{},
+			haveOrgs:      []int32{engineering, sales},
+			haveSiteAdmin: true,
+			otherAdmin:    true,
+			wantOrgs:      nil,
+			wantSiteAdmin: false,
+			wantEvents:    1,
+		},
+		"missing groups": {
+			// Identity providers that leave out the groups of the user.
+			mappings:      mappings,
+			groups:        nil,
+			haveOrgs:      []int32{engineering, sales},
+			haveSiteAdmin: true,
+			otherAdmin:    true,
+			wantOrgs:      nil,
+			wantSiteAdmin: false,
+			wantEvents:    1,
+		},
+		"grants site admin": {
+			mappings:      mappings,
+			groups:        []string{"sourcegraph-admins"},
+			wantSiteAdmin: true,
+			wantEvents:    1,
+		},
+		"keeps site admin": {
+			mappings:      mappings,
+			groups:        []string{"sourcegraph-admins"},
+			haveSiteAdmin: true,
+			wantSiteAdmin: true,
+		},
+		"keeps the last site admin": {
+			mappings:      mappings,
+			groups:        []string{"eng"},
+			haveSiteAdmin: true,
+			wantOrgs:      []int32{engineering},
+			wantSiteAdmin: true,
+		},
+		"without site admin mappings, site admin status is unchanged": {
+			mappings:      &schema.AuthGroupMappings{Orgs: mappings.Orgs},
+			groups:        []string{"eng"},
+			haveSiteAdmin: true,
+			wantOrgs:      []int32{engineering},
+			wantSiteAdmin: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &fakeStore{
+				orgs:      map[string]int32{"engineering": engineering, "sales": sales, "unmanaged": unmanaged},
+				members:   map[int32]map[int32]bool{},
+				siteAdmin: map[int32]bool{userID: test.haveSiteAdmin, otherUserID: test.otherAdmin},
+			}
+			for _, id := range test.haveOrgs {
+				_ = s.AddOrgMember(context.Background(), id, userID)
+			}
+
+			if err := apply(context.Background(), s, userID, test.mappings, test.groups); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(test.wantOrgs, s.orgIDs(userID)); diff != "" {
+				t.Errorf("orgs mismatch (-want +got):\n%s", diff)
+			}
+			if got := s.siteAdmin[userID]; got != test.wantSiteAdmin {
+				t.Errorf("got site admin %v, want %v", got, test.wantSiteAdmin)
+			}
+			if len(s.events) != test.wantEvents {
+				t.Errorf("got %d security events, want %d", len(s.events), test.wantEvents)
+			}
+		})
+	}
+}
+
+func TestApply_NoMappings(t *testing.T) {
+	if err := Apply(context.Background(), nil, 1, nil, []string{"eng"}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cockroachdb/errors"
 	"github.com/inconshreveable/log15"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
@@ -111,6 +112,10 @@ func middleware(db dbutil.DB) func(next http.Handler) http.Handler {
 				LookUpByUsername: rawEmail == "", // if the email is provided, we should look up by email, otherwise username
 			})
 			if err != nil {
+				if errors.Is(err, auth.ErrUserDeactivated) {
+					http.Error(w, safeErrMsg, http.StatusForbidden)
+					return
+				}
 				log15.Error("unable to get/create user from SSO header", "header", authProvider.UsernameHeader, "rawUsername", rawUsername, "err", err, "userErr", safeErrMsg)
 				http.Error(w, safeErrMsg, http.StatusInternalServerError)
 				return
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/errors"
 
@@ -13,7 +15,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -179,6 +184,27 @@ func TestMiddleware(t *testing.T) {
 			t.Error("!calledMock")
 		}
 	})
+
+	t.Run("sent, deactivated user", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set(headerName, "alice")
+		deactivatedAt := time.Now()
+		database.Mocks.ExternalAccounts.LookupUserAndSave = func(extsvc.AccountSpec, extsvc.AccountData) (int32, error) {
+			return 1, nil
+		}
+		database.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+			return &types.User{ID: id, Username: "alice", DeactivatedAt: &deactivatedAt}, nil
+		}
+		defer func() { database.Mocks = database.MockStores{} }()
+		handler.ServeHTTP(rr, req)
+		if got, want := rr.Code, http.StatusForbidden; got != want {
+			t.Errorf("got status %d, want %d", got, want)
+		}
+		if strings.Contains(rr.Body.String(), "user 1") {
+			t.Errorf("deactivated user was authenticated: %q", rr.Body.String())
+		}
+	})
 }
 
 func TestMiddleware_stripPrefix(t *testing.T) {
@@ -0,0 +1,234 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	goldap "github.com/go-ldap/ldap/v3"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	defaultUserFilter  = "(uid={username})"
+	defaultGroupFilter = "(|(member={dn})(uniqueMember={dn})(memberUid={username}))"
+
+	defaultUsernameAttribute    = "uid"
+	defaultEmailAttribute       = "mail"
+	defaultDisplayNameAttribute = "cn"
+
+	timeout = 10 * time.Second
+)
+
+// errInvalidCredentials is returned by authenticate if the username or password is wrong, or
+// if the user isn't allowed to sign in.
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// ldapUser is a user who authenticated with the LDAP server.
+type ldapUser struct {
+	DN          string   `json:"dn"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Groups      []string `json:"groups,omitempty"` // the DNs of the groups, if allowGroups is set
+}
+
+// authenticate checks the username and password of a user with the LDAP server, and returns the
+// attributes of their entry.
+//
+// 🚨 SECURITY: It returns errInvalidCredentials unless the LDAP server accepted the password for
+// the entry of the user, and the user is in one of the allowed groups (if any).
+func authenticate(c *schema.LDAPAuthProvider, username, password string) (*ldapUser, error) {
+	// 🚨 SECURITY: A bind with an empty password is an unauthenticated bind, which LDAP servers
+	// accept for any DN.
+	if username == "" || password == "" {
+		return nil, errInvalidCredentials
+	}
+
+	conn, err := dial(c)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := bindServiceAccount(conn, c); err != nil {
+		return nil, err
+	}
+
+	attrs := attributes(c)
+	filter := c.UserFilter
+	if filter == "" {
+		filter = defaultUserFilter
+	}
+	// 🚨 SECURITY: The username must be escaped, so that it can't change the filter.
+	filter = strings.ReplaceAll(filter, "{username}", goldap.EscapeFilter(username))
+	res, err := conn.Search(goldap.NewSearchRequest(
+		c.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, int(timeout.Seconds()), false,
+		filter, []string{attrs.Username, attrs.Email, attrs.DisplayName}, nil,
+	))
+	if err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+			return nil, errors.Errorf("user filter %q matches more than 1 entry for username %q", filter, username)
+		}
+		return nil, errors.Wrap(err, "searching for user")
+	}
+	if len(res.Entries) == 0 {
+		return nil, errInvalidCredentials
+	}
+	if len(res.Entries) > 1 {
+		return nil, errors.Errorf("user filter %q matches more than 1 entry for username %q", filter, username)
+	}
+	entry := res.Entries[0]
+
+	// 🚨 SECURITY: Check the password of the user by binding as them.
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "binding as user")
+	}
+
+	// Attribute names are case-insensitive, and servers may return them in another case than the
+	// one in the site configuration.
+	u := &ldapUser{
+		DN:          entry.DN,
+		Username:    entry.GetEqualFoldAttributeValue(attrs.Username),
+		Email:       entry.GetEqualFoldAttributeValue(attrs.Email),
+		DisplayName: entry.GetEqualFoldAttributeValue(attrs.DisplayName),
+	}
+	if u.Username == "" {
+		return nil, errors.Errorf("entry %q has no %q attribute for the username", entry.DN, attrs.Username)
+	}
+
+	if len(c.AllowGroups) > 0 {
+		// Search for groups as the service account again, which may be allowed to see groups
+		// that the user isn't.
+		if err := bindServiceAccount(conn, c); err != nil {
+			return nil, err
+		}
+		groups, allowed, err := searchGroups(conn, c, u)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, errInvalidCredentials
+		}
+		u.Groups = groups
+	}
+	return u, nil
+}
+
+// dial connects to the LDAP server, with TLS if the URL has the ldaps scheme or StartTLS is
+// enabled.
+func dial(c *schema.LDAPAuthProvider) (*goldap.Conn, error) {
+	u, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing LDAP server URL")
+	}
+	tlsConfig, err := newTLSConfig(c, u.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := goldap.DialURL(c.Url,
+		goldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		goldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "connecting to LDAP server")
+	}
+	conn.SetTimeout(timeout)
+
+	if c.StartTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "starting TLS")
+		}
+	}
+	return conn, nil
+}
+
+func newTLSConfig(c *schema.LDAPAuthProvider, serverName string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: serverName,
+		// 🚨 SECURITY: Only skip verification if the site admin explicitly asked for it.
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.Certificate != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(c.Certificate)) {
+			return nil, errors.New("invalid LDAP server certificate")
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// bindServiceAccount binds as the service account, if there is one.
+func bindServiceAccount(conn *goldap.Conn, c *schema.LDAPAuthProvider) error {
+	if c.BindDN == "" {
+		return nil
+	}
+	if err := conn.Bind(c.BindDN, c.BindPassword); err != nil {
+		return errors.Wrap(err, "binding as service account")
+	}
+	return nil
+}
+
+// searchGroups returns the DNs of the groups of the user, and whether one of them is allowed.
+func searchGroups(conn *goldap.Conn, c *schema.LDAPAuthProvider, u *ldapUser) (groups []string, allowed bool, err error) {
+	baseDN := c.GroupBaseDN
+	if baseDN == "" {
+		baseDN = c.BaseDN
+	}
+	filter := c.GroupFilter
+	if filter == "" {
+		filter = defaultGroupFilter
+	}
+	filter = strings.NewReplacer(
+		"{dn}", goldap.EscapeFilter(u.DN),
+		"{username}", goldap.EscapeFilter(u.Username),
+	).Replace(filter)
+
+	res, err := conn.Search(goldap.NewSearchRequest(
+		baseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, int(timeout.Seconds()), false,
+		filter, []string{"cn"}, nil,
+	))
+	if err != nil {
+		return nil, false, errors.Wrap(err, "searching for groups")
+	}
+	for _, entry := range res.Entries {
+		groups = append(groups, entry.DN)
+		for _, g := range c.AllowGroups {
+			if strings.EqualFold(g, entry.DN) || strings.EqualFold(g, entry.GetEqualFoldAttributeValue("cn")) {
+				allowed = true
+			}
+		}
+	}
+	return groups, allowed, nil
+}
+
+func attributes(c *schema.LDAPAuthProvider) schema.LDAPAttributes {
+	var a schema.LDAPAttributes
+	if c.Attributes != nil {
+		a = *c.Attributes
+	}
+	if a.Username == "" {
+		a.Username = defaultUsernameAttribute
+	}
+	if a.Email == "" {
+		a.Email = defaultEmailAttribute
+	}
+	if a.DisplayName == "" {
+		a.DisplayName = defaultDisplayNameAttribute
+	}
+	return a
+}
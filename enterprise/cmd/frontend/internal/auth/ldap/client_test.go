@@ -0,0 +1,258 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	testBindDN       = "cn=admin,dc=example,dc=org"
+	testBindPassword = "adminpassword"
+	testBaseDN       = "ou=people,dc=example,dc=org"
+	testGroupBaseDN  = "ou=groups,dc=example,dc=org"
+	testAliceDN      = "uid=alice,ou=people,dc=example,dc=org"
+	testBobDN        = "uid=bob,ou=people,dc=example,dc=org"
+	testEngineersDN  = "cn=engineers,ou=groups,dc=example,dc=org"
+)
+
+func testEntries() []testEntry {
+	return []testEntry{
+		{dn: testBindDN, password: testBindPassword},
+		{
+			dn:       testAliceDN,
+			password: "alicepassword",
+			attributes: map[string][]string{
+				"objectclass":    {"inetOrgPerson"},
+				"uid":            {"alice"},
+				"mail":           {"alice@example.org"},
+				"cn":             {"Alice Example"},
+				"samaccountname": {"alice.ad"},
+			},
+		},
+		{
+			dn:       testBobDN,
+			password: "bobpassword",
+			attributes: map[string][]string{
+				"objectclass": {"inetOrgPerson"},
+				"uid":         {"bob"},
+				"cn":          {"Bob Example"},
+			},
+		},
+		{
+			dn: testEngineersDN,
+			attributes: map[string][]string{
+				"objectclass": {"groupOfNames"},
+				"cn":          {"engineers"},
+				"member":      {testAliceDN},
+			},
+		},
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	s := newTestServer(t, false, testEntries()...)
+
+	config := func(modify func(c *schema.LDAPAuthProvider)) *schema.LDAPAuthProvider {
+		c := &schema.LDAPAuthProvider{
+			Type:         providerType,
+			Url:          s.url(false),
+			BindDN:       testBindDN,
+			BindPassword: testBindPassword,
+			BaseDN:       testBaseDN,
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	alice := &ldapUser{
+		DN:          testAliceDN,
+		Username:    "alice",
+		Email:       "alice@example.org",
+		DisplayName: "Alice Example",
+	}
+
+	tests := map[string]struct {
+		config   *schema.LDAPAuthProvider
+		username string
+		password string
+		wantUser *ldapUser
+		wantErr  error // if set, the error must be this error
+		anyErr   bool  // if set, there must be an error
+	}{
+		"valid credentials": {
+			config:   config(nil),
+			username: "alice",
+			password: "alicepassword",
+			wantUser: alice,
+		},
+		"user without email": {
+			config:   config(nil),
+			username: "bob",
+			password: "bobpassword",
+			wantUser: &ldapUser{DN: testBobDN, Username: "bob", DisplayName: "Bob Example"},
+		},
+		"wrong password": {
+			config:   config(nil),
+			username: "alice",
+			password: "bobpassword",
+			wantErr:  errInvalidCredentials,
+		},
+		"unknown user": {
+			config:   config(nil),
+			username: "carol",
+			password: "alicepassword",
+			wantErr:  errInvalidCredentials,
+		},
+		"empty password": {
+			config:   config(nil),
+			username: "alice",
+			password: "",
+			wantErr:  errInvalidCredentials,
+		},
+		"wildcard username is escaped": {
+			config:   config(nil),
+			username: "*",
+			password: "alicepassword",
+			wantErr:  errInvalidCredentials,
+		},
+		"filter injection is escaped": {
+			config:   config(nil),
+			username: "bob)(uid=alice",
+			password: "alicepassword",
+			wantErr:  errInvalidCredentials,
+		},
+		"custom user filter and attributes": {
+			config: config(func(c *schema.LDAPAuthProvider) {
+				c.UserFilter = "(&(objectClass=inetOrgPerson)(sAMAccountName={username}))"
+				c.Attributes = &schema.LDAPAttributes{Username: "sAMAccountName"}
+			}),
+			username: "alice.ad",
+			password: "alicepassword",
+			wantUser: &ldapUser{DN: testAliceDN, Username: "alice.ad", Email: "alice@example.org", DisplayName: "Alice Example"},
+		},
+		"user filter matching multiple entries": {
+			config: config(func(c *schema.LDAPAuthProvider) {
+				c.UserFilter = "(|(uid={username})(objectClass=inetOrgPerson))"
+			}),
+			username: "alice",
+			password: "alicepassword",
+			anyErr:   true,
+		},
+		"wrong service account password": {
+			config: config(func(c *schema.LDAPAuthProvider) {
+				c.BindPassword = "wrong"
+			}),
+			username: "alice",
+			password: "alicepassword",
+			anyErr:   true,
+		},
+		"in allowed group by cn": {
+			config: config(func(c *schema.LDAPAuthProvider) {
+				c.GroupBaseDN = testGroupBaseDN
+				c.AllowGroups = []string{"Engineers"}
+			}),
+			username: "alice",
+			password: "alicepassword",
+			wantUser: &ldapUser{DN: testAliceDN, Username: "alice", Email: "alice@example.org", DisplayName: "Alice Example", Groups: []string{testEngineersDN}},
+		},
+		"allowed group outside of the base DN": {
+			config: config(func(c *schema.LDAPAuthProvider) {
+				c.AllowGroups = []string{testEngineersDN}
+			}),
+			username: "alice",
+			password: "alicepassword",
+			wantErr:  errInvalidCredentials, // groupBaseDN defaults to baseDN
+		},
+		"not in allowed group": {
+			config: config(func(c *schema.LDAPAuthProvider) {
+				c.GroupBaseDN = testGroupBaseDN
+				c.AllowGroups = []string{"engineers"}
+			}),
+			username: "bob",
+			password: "bobpassword",
+			wantErr:  errInvalidCredentials,
+		},
+		"server unreachable": {
+			config: config(func(c *schema.LDAPAuthProvider) {
+				c.Url = "ldap://127.0.0.1:1"
+			}),
+			username: "alice",
+			password: "alicepassword",
+			anyErr:   true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, err := authenticate(test.config, test.username, test.password)
+			switch {
+			case test.wantErr != nil:
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("got error %v, want %v", err, test.wantErr)
+				}
+			case test.anyErr:
+				if err == nil || errors.Is(err, errInvalidCredentials) {
+					t.Fatalf("got error %v, want a server or config error", err)
+				}
+			default:
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(test.wantUser, u); diff != "" {
+					t.Fatalf("user mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthenticate_TLS(t *testing.T) {
+	t.Run("ldaps", func(t *testing.T) {
+		s := newTestServer(t, true, testEntries()...)
+		c := &schema.LDAPAuthProvider{Url: s.url(true), BaseDN: testBaseDN, Certificate: s.certPEM}
+		u, err := authenticate(c, "alice", "alicepassword")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.DN != testAliceDN {
+			t.Errorf("got DN %q, want %q", u.DN, testAliceDN)
+		}
+	})
+
+	t.Run("StartTLS", func(t *testing.T) {
+		s := newTestServer(t, false, testEntries()...)
+		c := &schema.LDAPAuthProvider{Url: s.url(false), StartTLS: true, BaseDN: testBaseDN, Certificate: s.certPEM}
+		if _, err := authenticate(c, "alice", "alicepassword"); err != nil {
+			t.Fatal(err)
+		}
+		if got := s.startTLSCount(); got != 1 {
+			t.Errorf("got %d StartTLS operations, want 1", got)
+		}
+		// There's no service account, so the only bind is the one as the user.
+		if got, want := s.bindDNs(), []string{testAliceDN}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got binds %q, want %q", got, want)
+		}
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		s := newTestServer(t, true, testEntries()...)
+		c := &schema.LDAPAuthProvider{Url: s.url(true), BaseDN: testBaseDN}
+		if _, err := authenticate(c, "alice", "alicepassword"); err == nil || errors.Is(err, errInvalidCredentials) {
+			t.Fatalf("got error %v, want a TLS error", err)
+		}
+	})
+
+	t.Run("insecureSkipVerify", func(t *testing.T) {
+		s := newTestServer(t, true, testEntries()...)
+		c := &schema.LDAPAuthProvider{Url: s.url(true), BaseDN: testBaseDN, InsecureSkipVerify: true}
+		if _, err := authenticate(c, "alice", "alicepassword"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
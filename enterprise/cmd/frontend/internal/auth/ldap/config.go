@@ -0,0 +1,54 @@
+package ldap
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+func getProviders() []providers.Provider {
+	var ps []providers.Provider
+	for _, p := range conf.Get().AuthProviders {
+		if p.Ldap != nil {
+			ps = append(ps, &provider{config: *p.Ldap})
+		}
+	}
+	return ps
+}
+
+// getProvider returns the LDAP auth provider with the given config ID, or nil if there is none.
+func getProvider(id string) *provider {
+	p, _ := providers.GetProviderByConfigID(providers.ConfigID{Type: providerType, ID: id}).(*provider)
+	return p
+}
+
+func init() {
+	conf.ContributeValidator(validateConfig)
+}
+
+func validateConfig(c conf.Unified) (problems conf.Problems) {
+	seen := map[string]int{}
+	for i, p := range c.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+		id := providerConfigID(p.Ldap)
+		if j, ok := seen[id]; ok {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d has the same configID as index %d; set distinct configIDs", i, j)))
+		} else {
+			seen[id] = i
+		}
+		if p.Ldap.BindDN == "" && p.Ldap.BindPassword != "" {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d has a bindPassword but no bindDN", i)))
+		}
+		if p.Ldap.InsecureSkipVerify {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d skips the verification of the TLS certificate of the LDAP server (insecureSkipVerify), which is insecure", i)))
+		}
+		if strings.HasPrefix(p.Ldap.Url, "ldap://") && !p.Ldap.StartTLS {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d sends passwords to the LDAP server unencrypted; use an ldaps:// URL or set startTLS", i)))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,72 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestValidateCustom(t *testing.T) {
+	tests := map[string]struct {
+		input        conf.Unified
+		wantProblems conf.Problems
+	}{
+		"ldaps": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://ldap.example.com"}},
+				},
+			}},
+			wantProblems: nil,
+		},
+		"ldap with StartTLS": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://ldap.example.com", StartTLS: true}},
+				},
+			}},
+			wantProblems: nil,
+		},
+		"ldap without StartTLS": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://ldap.example.com"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("LDAP auth provider at index 0 sends passwords to the LDAP server unencrypted; use an ldaps:// URL or set startTLS"),
+		},
+		"insecureSkipVerify": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://ldap.example.com", InsecureSkipVerify: true}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("LDAP auth provider at index 0 skips the verification of the TLS certificate of the LDAP server (insecureSkipVerify), which is insecure"),
+		},
+		"bindPassword without bindDN": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://ldap.example.com", BindPassword: "secret"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("LDAP auth provider at index 0 has a bindPassword but no bindDN"),
+		},
+		"duplicate config IDs": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Builtin: &schema.BuiltinAuthProvider{Type: "builtin"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://ldap.example.com"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldaps://ldap.example.com", BaseDN: "ou=other"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", ConfigID: "other", Url: "ldaps://ldap.example.com"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("LDAP auth provider at index 2 has the same configID as index 1; set distinct configIDs"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.TestValidator(t, test.input, validateConfig, test.wantProblems)
+		})
+	}
+}
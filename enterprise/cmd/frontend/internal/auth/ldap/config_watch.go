@@ -0,0 +1,15 @@
+package ldap
+
+import (
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// Watch for configuration changes related to the LDAP auth providers.
+func init() {
+	go func() {
+		conf.Watch(func() {
+			providers.Update(providerType, getProviders())
+		})
+	}()
+}
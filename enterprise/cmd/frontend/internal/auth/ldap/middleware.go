@@ -0,0 +1,171 @@
+// Package ldap implements auth via LDAP, such as with Active Directory.
+package ldap
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/cookie"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// All LDAP endpoints are under this path prefix.
+const authPrefix = auth.AuthURLPrefix + "/ldap"
+
+// Middleware is middleware for LDAP authentication, adding the sign-in endpoint under the auth
+// path prefix ("/.auth"). Users sign in with the username and password of their LDAP entry
+// through the same kind of form as the builtin auth provider.
+//
+// 🚨 SECURITY
+func Middleware(db dbutil.DB) *auth.Middleware {
+	return &auth.Middleware{
+		API: func(next http.Handler) http.Handler { return next },
+		App: func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == authPrefix+"/sign-in" {
+					signInHandler(db)(w, r)
+					return
+				}
+				next.ServeHTTP(w, r)
+			})
+		},
+	}
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// signInHandler signs in the user with the username and password in the JSON request body, which
+// are checked with the LDAP auth provider given by the "pc" query parameter.
+//
+// 🚨 SECURITY
+func signInHandler(db dbutil.DB) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			http.Error(w, fmt.Sprintf("Unsupported method %s", r.Method), http.StatusBadRequest)
+			return
+		}
+		// 🚨 SECURITY: This handler runs before the CSRF middleware, so require a header that
+		// browsers don't allow cross-origin forms to set, to prevent login CSRF.
+		if r.Header.Get("X-Requested-With") == "" {
+			http.Error(w, "Missing X-Requested-With header.", http.StatusBadRequest)
+			return
+		}
+
+		p := getProvider(r.URL.Query().Get("pc"))
+		if p == nil {
+			http.Error(w, "No LDAP authentication provider found with the given ID.", http.StatusNotFound)
+			return
+		}
+
+		var creds credentials
+		if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+			http.Error(w, "Could not decode request body", http.StatusBadRequest)
+			return
+		}
+
+		var userID int32
+		signInResult := database.SecurityEventNameSignInAttempted
+		logSignInEvent(r, db, userID, signInResult, creds.Username)
+		// We have more failure scenarios and ONLY one successful scenario. By default assume a
+		// SignInFailed state so that the deferred logSignInEvent function call will log the
+		// correct security event in case of a failure.
+		signInResult = database.SecurityEventNameSignInFailed
+		defer func() { logSignInEvent(r, db, userID, signInResult, creds.Username) }()
+
+		u, err := authenticate(&p.config, creds.Username, creds.Password)
+		if err != nil {
+			if errors.Is(err, errInvalidCredentials) {
+				http.Error(w, "Authentication failed", http.StatusUnauthorized)
+				return
+			}
+			log15.Error("LDAP auth failed.", "url", p.config.Url, "username", creds.Username, "error", err)
+			http.Error(w, "Authentication failed. The LDAP server could not be reached or is misconfigured; ask a site admin to check the logs.", http.StatusInternalServerError)
+			return
+		}
+
+		actr, safeErrMsg, err := getOrCreateUser(r, db, p, u)
+		if err != nil {
+			log15.Error("LDAP auth failed: error looking up or creating user.", "username", u.Username, "error", err, "userErr", safeErrMsg)
+			http.Error(w, safeErrMsg, http.StatusInternalServerError)
+			return
+		}
+		userID = actr.UID
+
+		user, err := database.Users(db).GetByID(r.Context(), actr.UID)
+		if err != nil {
+			log15.Error("LDAP auth failed: error retrieving user from database.", "error", err)
+			http.Error(w, "Failed to retrieve user: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := session.SetActor(w, r, actr, 0, user.CreatedAt); err != nil {
+			log15.Error("LDAP auth failed: could not initiate session.", "error", err)
+			http.Error(w, "Authentication failed. Try signing in again (and clearing cookies for the current site). The error was: could not initiate session.", http.StatusInternalServerError)
+			return
+		}
+
+		signInResult = database.SecurityEventNameSignInSucceeded
+	}
+}
+
+// getOrCreateUser returns the actor of the Sourcegraph user of the LDAP user, and creates the
+// Sourcegraph user if they don't exist.
+func getOrCreateUser(r *http.Request, db dbutil.DB, p *provider, u *ldapUser) (_ *actor.Actor, safeErrMsg string, err error) {
+	username, err := auth.NormalizeUsername(u.Username)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", u.Username), err
+	}
+
+	var data extsvc.AccountData
+	data.SetAccountData(u)
+
+	userID, safeErrMsg, err := auth.GetAndSaveUser(r.Context(), db, auth.GetAndSaveUserOp{
+		UserProps: database.NewUser{
+			Username: username,
+			Email:    u.Email,
+			// The email address is managed by the directory, whose admins are trusted.
+			EmailIsVerified: u.Email != "",
+			DisplayName:     u.DisplayName,
+		},
+		ExternalAccount: extsvc.AccountSpec{
+			ServiceType: providerType,
+			ServiceID:   p.config.Url,
+			AccountID:   u.DN,
+		},
+		ExternalAccountData: data,
+		CreateIfNotExist:    true,
+	})
+	if err != nil {
+		return nil, safeErrMsg, err
+	}
+	return actor.FromUser(userID), "", nil
+}
+
+func logSignInEvent(r *http.Request, db dbutil.DB, userID int32, name database.SecurityEventName, username string) {
+	argument, _ := json.Marshal(map[string]string{"provider": providerType, "username": username})
+	event := &database.SecurityEvent{
+		Name:      name,
+		URL:       r.URL.Path,
+		UserID:    uint32(userID),
+		Argument:  argument,
+		Source:    "BACKEND",
+		Timestamp: time.Now(),
+	}
+
+	// Safe to ignore this error
+	event.AnonymousUserID, _ = cookie.AnonymousUID(r)
+
+	database.SecurityEventLogs(db).LogEvent(r.Context(), event)
+}
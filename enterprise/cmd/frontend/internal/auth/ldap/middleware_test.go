@@ -0,0 +1,124 @@
+package ldap
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestMiddleware(t *testing.T) {
+	cleanup := session.ResetMockSessionStore(t)
+	defer cleanup()
+
+	s := newTestServer(t, true, testEntries()...)
+	p := &provider{config: schema.LDAPAuthProvider{
+		Type:         providerType,
+		ConfigID:     "corp",
+		Url:          s.url(true),
+		Certificate:  s.certPEM,
+		BindDN:       testBindDN,
+		BindPassword: testBindPassword,
+		BaseDN:       testBaseDN,
+	}}
+	providers.MockProviders = []providers.Provider{p}
+	defer func() { providers.MockProviders = nil }()
+
+	const mockUserID = 123
+	var gotOp auth.GetAndSaveUserOp
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (userID int32, safeErrMsg string, err error) {
+		gotOp = op
+		if op.ExternalAccount.ServiceType == providerType && op.ExternalAccount.ServiceID == p.config.Url && op.ExternalAccount.AccountID == testAliceDN {
+			return mockUserID, "", nil
+		}
+		return 0, "safeErr", errors.Errorf("account %v not found in mock", op.ExternalAccount)
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	database.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, CreatedAt: time.Now()}, nil
+	}
+	defer func() { database.Mocks = database.MockStores{} }()
+
+	handler := Middleware(nil).App(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	doRequest := func(method, url, body string, xhr bool) *http.Response {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if xhr {
+			req.Header.Set("X-Requested-With", "Sourcegraph")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+
+	signInURL := p.CachedInfo().AuthenticationURL
+	if want := "/.auth/ldap/sign-in?pc=corp"; signInURL != want {
+		t.Fatalf("got authentication URL %q, want %q", signInURL, want)
+	}
+
+	t.Run("other paths are passed through", func(t *testing.T) {
+		if resp := doRequest("GET", "/search", "", false); resp.StatusCode != http.StatusTeapot {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusTeapot)
+		}
+	})
+
+	t.Run("valid credentials", func(t *testing.T) {
+		resp := doRequest("POST", signInURL, `{"username":"alice","password":"alicepassword"}`, true)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if len(resp.Cookies()) == 0 {
+			t.Error("got no session cookie")
+		}
+		if gotOp.UserProps.Username != "alice" || gotOp.UserProps.Email != "alice@example.org" || !gotOp.UserProps.EmailIsVerified || gotOp.UserProps.DisplayName != "Alice Example" {
+			t.Errorf("unexpected user props %+v", gotOp.UserProps)
+		}
+		if !gotOp.CreateIfNotExist {
+			t.Error("want users to be created if they don't exist")
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		resp := doRequest("POST", signInURL, `{"username":"alice","password":"bobpassword"}`, true)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+		if len(resp.Cookies()) != 0 {
+			t.Error("got a session cookie")
+		}
+	})
+
+	t.Run("missing X-Requested-With header", func(t *testing.T) {
+		resp := doRequest("POST", signInURL, `{"username":"alice","password":"alicepassword"}`, false)
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		if resp := doRequest("GET", signInURL, "", true); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		resp := doRequest("POST", "/.auth/ldap/sign-in?pc=other", `{"username":"alice","password":"alicepassword"}`, true)
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("got status %d, want %d", resp.StatusCode, http.StatusNotFound)
+		}
+	})
+}
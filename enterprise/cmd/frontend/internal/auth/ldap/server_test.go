@@ -0,0 +1,321 @@
+package ldap
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// The LDAP protocol operations and result codes that testServer supports (RFC 4511).
+const (
+	opBindRequest      = 0
+	opBindResponse     = 1
+	opUnbindRequest    = 2
+	opSearchRequest    = 3
+	opSearchEntry      = 4
+	opSearchDone       = 5
+	opExtendedRequest  = 23
+	opExtendedResponse = 24
+
+	resultSuccess            = 0
+	resultProtocolError      = 2
+	resultInvalidCredentials = 49
+	resultUnwillingToPerform = 53
+
+	oidStartTLS = "1.3.6.1.4.1.1466.20037"
+)
+
+// testEntry is an entry in the directory of testServer.
+type testEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string // keys are lowercase
+}
+
+// testServer is an in-process stand-in for an LDAP server. It supports simple binds, searches
+// with equality, presence, and, or and not filters, and StartTLS.
+type testServer struct {
+	t        *testing.T
+	listener net.Listener
+	tls      *tls.Config
+	entries  []testEntry
+
+	// certPEM is the PEM-encoded self-signed certificate of the server.
+	certPEM string
+
+	mu       sync.Mutex
+	binds    []string // the DNs of all binds, in order
+	startTLS int      // the number of StartTLS operations
+}
+
+// newTestServer starts a test LDAP server that listens on 127.0.0.1, with TLS from the start if
+// ldaps is true.
+func newTestServer(t *testing.T, ldaps bool, entries ...testEntry) *testServer {
+	t.Helper()
+
+	certPEM, cert := newTestCertificate(t)
+	s := &testServer{
+		t:       t,
+		tls:     &tls.Config{Certificates: []tls.Certificate{cert}},
+		entries: entries,
+		certPEM: certPEM,
+	}
+
+	var err error
+	if ldaps {
+		s.listener, err = tls.Listen("tcp", "127.0.0.1:0", s.tls)
+	} else {
+		s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.listener.Close() })
+
+	go func() {
+		for {
+			conn, err := s.listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// url returns the URL of the server, with the ldaps scheme if the server uses TLS from the start.
+func (s *testServer) url(ldaps bool) string {
+	if ldaps {
+		return "ldaps://" + s.listener.Addr().String()
+	}
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *testServer) bindDNs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *testServer) startTLSCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startTLS
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		messageID, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case opBindRequest:
+			s.write(conn, messageID, s.bind(op))
+
+		case opSearchRequest:
+			for _, entry := range s.search(op) {
+				s.write(conn, messageID, entry)
+			}
+			s.write(conn, messageID, result(opSearchDone, resultSuccess, ""))
+
+		case opExtendedRequest:
+			if len(op.Children) == 0 || op.Children[0].Data.String() != oidStartTLS {
+				s.write(conn, messageID, result(opExtendedResponse, resultProtocolError, "unsupported extended operation"))
+				continue
+			}
+			if _, ok := conn.(*tls.Conn); ok {
+				s.write(conn, messageID, result(opExtendedResponse, resultUnwillingToPerform, "TLS is already started"))
+				continue
+			}
+			s.write(conn, messageID, result(opExtendedResponse, resultSuccess, ""))
+			s.mu.Lock()
+			s.startTLS++
+			s.mu.Unlock()
+			conn = tls.Server(conn, s.tls)
+			defer conn.Close()
+
+		case opUnbindRequest:
+			return
+
+		default:
+			return
+		}
+	}
+}
+
+func (s *testServer) write(conn net.Conn, messageID int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(op)
+	if _, err := conn.Write(packet.Bytes()); err != nil {
+		s.t.Logf("writing LDAP response: %s", err)
+	}
+}
+
+func (s *testServer) bind(op *ber.Packet) *ber.Packet {
+	if len(op.Children) < 3 {
+		return result(opBindResponse, resultProtocolError, "invalid bind request")
+	}
+	dn, _ := op.Children[1].Value.(string)
+	password := op.Children[2].Data.String()
+
+	s.mu.Lock()
+	s.binds = append(s.binds, dn)
+	s.mu.Unlock()
+
+	// Like real LDAP servers, accept unauthenticated binds (with an empty password) for any DN.
+	if password == "" {
+		return result(opBindResponse, resultSuccess, "")
+	}
+	for _, e := range s.entries {
+		if strings.EqualFold(e.dn, dn) && e.password != "" && e.password == password {
+			return result(opBindResponse, resultSuccess, "")
+		}
+	}
+	return result(opBindResponse, resultInvalidCredentials, "invalid credentials")
+}
+
+func (s *testServer) search(op *ber.Packet) []*ber.Packet {
+	if len(op.Children) < 8 {
+		return nil
+	}
+	baseDN, _ := op.Children[0].Value.(string)
+	filter := op.Children[6]
+	var attrs []string
+	for _, a := range op.Children[7].Children {
+		name, _ := a.Value.(string)
+		attrs = append(attrs, strings.ToLower(name))
+	}
+
+	var entries []*ber.Packet
+	for _, e := range s.entries {
+		if !strings.HasSuffix(strings.ToLower(e.dn), strings.ToLower(baseDN)) || !matchFilter(filter, e) {
+			continue
+		}
+		entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, opSearchEntry, nil, "Search Result Entry")
+		entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "DN"))
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for _, name := range attrs {
+			values, ok := e.attributes[name]
+			if !ok {
+				continue
+			}
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, v := range values {
+				vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+			}
+			attribute.AppendChild(vals)
+			attributes.AppendChild(attribute)
+		}
+		entry.AppendChild(attributes)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// matchFilter reports whether the entry matches the filter, which must only use the and, or,
+// not, equality and presence filters.
+func matchFilter(f *ber.Packet, e testEntry) bool {
+	switch f.Tag {
+	case 0: // and
+		for _, c := range f.Children {
+			if !matchFilter(c, e) {
+				return false
+			}
+		}
+		return true
+	case 1: // or
+		for _, c := range f.Children {
+			if matchFilter(c, e) {
+				return true
+			}
+		}
+		return false
+	case 2: // not
+		return len(f.Children) == 1 && !matchFilter(f.Children[0], e)
+	case 3: // equality
+		if len(f.Children) != 2 {
+			return false
+		}
+		name, _ := f.Children[0].Value.(string)
+		value, _ := f.Children[1].Value.(string)
+		for _, v := range e.attributes[strings.ToLower(name)] {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	case 7: // present
+		return len(e.attributes[strings.ToLower(f.Data.String())]) > 0
+	default:
+		return false
+	}
+}
+
+// result returns an LDAPResult for the given protocol operation.
+func result(op ber.Tag, code int64, message string) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, op, nil, "Response")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	p.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, "Diagnostic Message"))
+	return p
+}
+
+// newTestCertificate returns a self-signed certificate for 127.0.0.1.
+func newTestCertificate(t *testing.T) (string, tls.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ldap.test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(certPEM), cert
+}
@@ -59,6 +59,10 @@ func (p *Provider) CachedInfo() *providers.Info {
 		displayName = p.SourceConfig.Github.DisplayName
 	case p.SourceConfig.Gitlab != nil && p.SourceConfig.Gitlab.DisplayName != "":
 		displayName = p.SourceConfig.Gitlab.DisplayName
+	case p.SourceConfig.BitbucketCloud != nil && p.SourceConfig.BitbucketCloud.DisplayName != "":
+		displayName = p.SourceConfig.BitbucketCloud.DisplayName
+	case p.SourceConfig.Gitea != nil && p.SourceConfig.Gitea.DisplayName != "":
+		displayName = p.SourceConfig.Gitea.DisplayName
 	}
 	return &providers.Info{
 		ServiceID:   p.ServiceID,
@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/groupsync"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -234,6 +235,20 @@ func authHandler(db dbutil.DB) func(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
+			// 🚨 SECURITY: Apply the group mappings on every sign-in, so that users who left a
+			// group lose the organization memberships and site admin status it gave them.
+			// Providers often leave out the groups claim when the user is in no groups, so a
+			// missing claim is treated as an empty list of groups.
+			groups, ok := groupsFromClaims(groupsClaim(&p.config), idToken, userInfo)
+			if !ok && p.config.GroupMappings != nil {
+				log15.Warn("OpenID Connect auth: the ID token and userinfo have no groups claim; applying group mappings as if the user is in no groups.", "claim", groupsClaim(&p.config), "user", actr.UID)
+			}
+			if err := groupsync.Apply(ctx, db, actr.UID, p.config.GroupMappings, groups); err != nil {
+				log15.Error("OpenID Connect auth failed: error applying group mappings.", "error", err)
+				http.Error(w, "Authentication failed. The group mappings of the OpenID Connect provider could not be applied; a site admin must check the configuration.", http.StatusInternalServerError)
+				return
+			}
+
 			user, err := database.GlobalUsers.GetByID(r.Context(), actr.UID)
 			if err != nil {
 				log15.Error("OpenID Connect auth failed: error retrieving user from database.", "error", err)
@@ -12,6 +12,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 // getOrCreateUser gets or creates a user account based on the OpenID Connect token. It returns the
@@ -79,3 +80,43 @@ func getOrCreateUser(ctx context.Context, db dbutil.DB, p *provider, idToken *oi
 	}
 	return actor.FromUser(userID), "", nil
 }
+
+// claimsSource is an ID token or a userinfo response.
+type claimsSource interface {
+	Claims(v interface{}) error
+}
+
+// groupsFromClaims returns the groups of the user in the claim with the given name of the ID token
+// and the userinfo response, because providers send the groups in either of them. The claim is a
+// list of strings or a single string. It returns false if neither of them has the claim, which
+// is different from an empty list of groups.
+func groupsFromClaims(name string, sources ...claimsSource) (groups []string, ok bool) {
+	for _, source := range sources {
+		var claims map[string]interface{}
+		if err := source.Claims(&claims); err != nil {
+			continue
+		}
+		if claims[name] != nil {
+			ok = true
+		}
+		switch v := claims[name].(type) {
+		case string:
+			groups = append(groups, v)
+		case []interface{}:
+			for _, g := range v {
+				if g, ok := g.(string); ok {
+					groups = append(groups, g)
+				}
+			}
+		}
+	}
+	return groups, ok
+}
+
+// groupsClaim returns the name of the claim that lists the groups of the user.
+func groupsClaim(c *schema.OpenIDConnectAuthProvider) string {
+	if c.GroupsClaim != "" {
+		return c.GroupsClaim
+	}
+	return "groups"
+}
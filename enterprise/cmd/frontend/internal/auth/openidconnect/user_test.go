@@ -0,0 +1,86 @@
+package openidconnect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type testClaims string
+
+func (c testClaims) Claims(v interface{}) error {
+	return json.Unmarshal([]byte(c), v)
+}
+
+func TestGroupsFromClaims(t *testing.T) {
+	tests := map[string]struct {
+		name    string
+		sources []claimsSource
+		want    []string
+		wantOK  bool
+	}{
+		"list": {
+			name:    "groups",
+			sources: []claimsSource{testClaims(`{"groups": ["eng", "admins"]}`)},
+			want:    []string{"eng", "admins"},
+			wantOK:  true,
+		},
+		"single string": {
+			name:    "groups",
+			sources: []claimsSource{testClaims(`{"groups": "eng"}`)},
+			want:    []string{"eng"},
+			wantOK:  true,
+		},
+		"custom claim": {
+			name:    "roles",
+			sources: []claimsSource{testClaims(`{"groups": ["eng"], "roles": ["admins"]}`)},
+			want:    []string{"admins"},
+			wantOK:  true,
+		},
+		"ID token and userinfo": {
+			name: "groups",
+			sources: []claimsSource{
+				testClaims(`{"groups": ["eng"]}`),
+				testClaims(`{"groups": ["admins"]}`),
+			},
+			want:   []string{"eng", "admins"},
+			wantOK: true,
+		},
+		"empty claim": {
+			name:    "groups",
+			sources: []claimsSource{testClaims(`{"groups": []}`)},
+			want:    nil,
+			wantOK:  true,
+		},
+		"missing claim": {
+			name:    "groups",
+			sources: []claimsSource{testClaims(`{"sub": "alice"}`)},
+			want:    nil,
+			wantOK:  false,
+		},
+		"non-string values are ignored": {
+			name:    "groups",
+			sources: []claimsSource{testClaims(`{"groups": ["eng", 1, {"name": "admins"}]}`)},
+			want:    []string{"eng"},
+			wantOK:  true,
+		},
+		"invalid claims": {
+			name:    "groups",
+			sources: []claimsSource{testClaims(`not json`), testClaims(`{"groups": ["eng"]}`)},
+			want:    []string{"eng"},
+			wantOK:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			groups, ok := groupsFromClaims(test.name, test.sources...)
+			if diff := cmp.Diff(test.want, groups); diff != "" {
+				t.Errorf("groups mismatch (-want +got):\n%s", diff)
+			}
+			if ok != test.wantOK {
+				t.Errorf("got ok %v, want %v", ok, test.wantOK)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/auth/groupsync"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -150,6 +151,19 @@ func samlSPHandler(db dbutil.DB) func(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 
+			// 🚨 SECURITY: Apply the group mappings on every sign-in, so that users who left a
+			// group lose the organization memberships and site admin status it gave them.
+			// Identity providers often leave out the groups attribute when the user is in no
+			// groups, so a missing attribute is treated as an empty list of groups.
+			if !info.hasGroups && p.config.GroupMappings != nil {
+				log15.Warn("The SAML response has no groups attribute; applying group mappings as if the user is in no groups.", "attribute", groupsAttributeName(&p.config), "user", actor.UID)
+			}
+			if err := groupsync.Apply(r.Context(), db, actor.UID, p.config.GroupMappings, info.groups); err != nil {
+				log15.Error("Error applying SAML group mappings.", "err", err)
+				http.Error(w, "Error applying the group mappings of the SAML provider. A site admin must check the configuration.", http.StatusInternalServerError)
+				return
+			}
+
 			user, err := database.GlobalUsers.GetByID(r.Context(), actor.UID)
 			if err != nil {
 				log15.Error("Error retrieving SAML-authenticated user from database.", "error", err)
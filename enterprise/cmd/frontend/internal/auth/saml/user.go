@@ -14,12 +14,15 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 type authnResponseInfo struct {
 	spec                 extsvc.AccountSpec
 	email, displayName   string
 	unnormalizedUsername string
+	groups               []string
+	hasGroups            bool // whether the response has the groups attribute, even if it is empty
 	accountData          interface{}
 }
 
@@ -71,6 +74,7 @@ func readAuthnResponse(p *provider, encodedResp string) (*authnResponseInfo, err
 		displayName:          firstNonempty(attr.Get("displayName"), attr.Get("givenName")+" "+attr.Get("surname"), attr.Get("http://schemas.xmlsoap.org/claims/CommonName"), attr.Get("http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname")),
 		accountData:          assertions,
 	}
+	info.groups, info.hasGroups = assertionGroups(assertions, groupsAttributeName(&p.config))
 	if assertions.NameID == "" {
 		return nil, errors.New("the SAML response did not contain a valid NameID")
 	}
@@ -117,6 +121,36 @@ func mightBeEmail(s string) bool {
 	return strings.Count(s, "@") == 1
 }
 
+// groupsAttributeName returns the name of the attribute that lists the groups of the user.
+func groupsAttributeName(c *schema.SAMLAuthProvider) string {
+	if c.GroupsAttributeName != "" {
+		return c.GroupsAttributeName
+	}
+	return "groups"
+}
+
+// assertionGroups returns all values of the attributes with the given name. Identity providers
+// send the groups of a user as a multi-valued attribute or as a repeated attribute, which is why
+// this reads the assertions instead of the values, which only keep the last attribute of a name.
+// It returns false if there is no attribute with the name.
+func assertionGroups(assertions *saml2.AssertionInfo, name string) (groups []string, ok bool) {
+	for _, assertion := range assertions.Assertions {
+		if assertion.AttributeStatement == nil {
+			continue
+		}
+		for _, a := range assertion.AttributeStatement.Attributes {
+			if a.Name != name && a.FriendlyName != name {
+				continue
+			}
+			ok = true
+			for _, v := range a.Values {
+				groups = append(groups, v.Value)
+			}
+		}
+	}
+	return groups, ok
+}
+
 type samlAssertionValues saml2.Values
 
 func (v samlAssertionValues) Get(key string) string {
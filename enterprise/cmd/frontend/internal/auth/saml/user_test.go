@@ -12,6 +12,7 @@ import (
 	dsig "github.com/russellhaering/goxmldsig"
 
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestReadAuthnResponse(t *testing.T) {
@@ -47,6 +48,36 @@ func TestReadAuthnResponse(t *testing.T) {
 	}
 }
 
+func TestReadAuthnResponse_Groups(t *testing.T) {
+	p := &provider{
+		config: schema.SAMLAuthProvider{GroupsAttributeName: "Role"},
+		samlSP: &saml2.SAMLServiceProvider{
+			IdentityProviderSSOURL:      "http://localhost:3220/auth/realms/master",
+			IdentityProviderIssuer:      "http://localhost:3220/auth/realms/master",
+			Clock:                       dsig.NewFakeClockAt(time.Date(2018, time.May, 20, 17, 12, 6, 0, time.UTC)),
+			IDPCertificateStore:         &dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{idpCert2}},
+			SPKeyStore:                  dsig.RandomKeyStoreForTest(),
+			AssertionConsumerServiceURL: "http://localhost:3080/.auth/saml/acs",
+			ServiceProviderIssuer:       "http://localhost:3080/.auth/saml/metadata",
+			AudienceURI:                 "http://localhost:3080/.auth/saml/metadata",
+		},
+	}
+	info, err := readAuthnResponse(p, base64.StdEncoding.EncodeToString([]byte(testAuthnResponse)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The Role attribute is repeated, once for each value.
+	if !info.hasGroups {
+		t.Fatal("got no groups attribute, want one")
+	}
+	if len(info.groups) != 24 {
+		t.Fatalf("got %d groups, want 24: %q", len(info.groups), info.groups)
+	}
+	if want := []string{"view-profile", "uma_authorization", "manage-account"}; !reflect.DeepEqual(info.groups[:3], want) {
+		t.Errorf("got groups %q, want them to start with %q", info.groups, want)
+	}
+}
+
 var idpCert2 = func() *x509.Certificate {
 	b, _ := pem.Decode([]byte(`-----BEGIN CERTIFICATE-----
 MIICmzCCAYMCBgFjcZU/LjANBgkqhkiG9w0BAQsFADARMQ8wDQYDVQQDDAZtYXN0ZXIwHhcNMTgwNTE4MDQ0ODE2WhcNMjgwNTE4MDQ0OTU2WjARMQ8wDQYDVQQDDAZtYXN0ZXIwggEiMA0GCSqGSIb3DQEBAQUAA4IBDwAwggEKAoIBAQDXZpJeHraEt9FPk478+RoMtP9RV83Ew/XRZhNKI4BPoY5MjRVuvaabvMOE5X1AK9Z0cEU++m/Y0LuHg3A4kQdPw3BGPBfGm0WSD6DEN42TcF3dc8XBA/osDNW5i6rZM071che8XtKNHcW9ZAv9ETfJeUb4NHFRkRg3K1lZ5kCwt0JNo+0akQ2EdQXXu/uEeQV49rOADr+Lp6GLhmGeCckC8xzBiNxZwR4pJsz9XWgB6fSdpIGvWhAnBfFZyyZIHnVuRnm2wJ53Exg6h2RB3SFYu3PXXuIHeuH71pel5WwnecTVTwV/RMwkAGLdCNC9jp9tdDtThhWLn4E9D0wZkpU9AgMBAAEwDQYJKoZIhvcNAQELBQADggEBAKT/zyjvSM09Fk2ON4rMSExnyrw6LXuJJOZlB0eD22KruQ53AikfKz5nJLCFLc0PT4PmK06s9OF0HG95k4jiiuvAdNMXZSLUGNcbaODeJ/ZzCJJp0cB2rWEmAqbKruXzBpTFttlgsW4mgpkvGxORztfhksiyAX0bLcNWtsQecl3fpvoVrJiIHXStD3c/v4exE2QPkuvhLCzwI2oXrrhrovyTKjCbyn2//lqOfFziA8X/ini3R/L4UzTVB5SWAz/LtkpgipPOwNpVqwErnZamexm6S38QX+OZ+uhZY/1JfTugs9vpXwRvj/xamGr8r+MqornuQiEBBNiCbCJ6B4iUWh4=
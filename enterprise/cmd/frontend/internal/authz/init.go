@@ -62,8 +62,8 @@ func Init(ctx context.Context, db dbutil.DB, outOfBandMigrationRunner *oobmigrat
 			return nil
 		}
 
-		// We currently support three types of authz providers: GitHub, GitLab and Bitbucket Server.
-		authzTypes := make(map[string]struct{}, 3)
+		// We currently support four types of authz providers: GitHub, GitLab, Bitbucket Server and Gitea.
+		authzTypes := make(map[string]struct{}, 4)
 		for _, p := range providers {
 			authzTypes[p.ServiceType()] = struct{}{}
 		}
@@ -77,6 +77,10 @@ func Init(ctx context.Context, db dbutil.DB, outOfBandMigrationRunner *oobmigrat
 				authzNames = append(authzNames, "GitLab")
 			case extsvc.TypeBitbucketServer:
 				authzNames = append(authzNames, "Bitbucket Server")
+			case extsvc.TypeBitbucketCloud:
+				authzNames = append(authzNames, "Bitbucket Cloud")
+			case extsvc.TypeGitea:
+				authzNames = append(authzNames, "Gitea")
 			default:
 				authzNames = append(authzNames, t)
 			}
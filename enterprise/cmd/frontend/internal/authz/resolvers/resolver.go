@@ -2,6 +2,7 @@ package resolvers
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	edb "github.com/sourcegraph/sourcegraph/enterprise/internal/database"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
 	"github.com/sourcegraph/sourcegraph/internal/authz"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
@@ -81,7 +83,8 @@ func (r *Resolver) SetRepositoryPermissionsForUsers(ctx context.Context, args *g
 		return nil, err
 	}
 	// Make sure the repo ID is valid.
-	if _, err = database.GlobalRepos.Get(ctx, repoID); err != nil {
+	repo, err := database.GlobalRepos.Get(ctx, repoID)
+	if err != nil {
 		return nil, err
 	}
 
@@ -138,6 +141,11 @@ func (r *Resolver) SetRepositoryPermissionsForUsers(ctx context.Context, args *g
 		pendingBindIDs = append(pendingBindIDs, id)
 	}
 
+	before := &authz.RepoPermissions{RepoID: p.RepoID, Perm: p.Perm}
+	if err := r.store.LoadRepoPermissions(ctx, before); err != nil && err != authz.ErrPermsNotFound {
+		return nil, errors.Wrap(err, "load repository permissions")
+	}
+
 	txs, err := r.store.Transact(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "start transaction")
@@ -156,6 +164,16 @@ func (r *Resolver) SetRepositoryPermissionsForUsers(ctx context.Context, args *g
 		return nil, errors.Wrap(err, "set repository pending permissions")
 	}
 
+	var beforeUsers uint64
+	if before.UserIDs != nil {
+		beforeUsers = before.UserIDs.GetCardinality()
+	}
+	audit.SetTarget(ctx, "repository %s", repo.Name)
+	audit.SetChange(ctx,
+		fmt.Sprintf("%d users", beforeUsers),
+		fmt.Sprintf("%d users, %d pending users", p.UserIDs.GetCardinality(), len(pendingBindIDs)),
+	)
+
 	return &graphqlbackend.EmptyResponse{}, nil
 }
 
@@ -187,6 +187,9 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 			database.Mocks.Repos.Get = func(_ context.Context, id api.RepoID) (*types.Repo, error) {
 				return &types.Repo{ID: id}, nil
 			}
+			edb.Mocks.Perms.LoadRepoPermissions = func(_ context.Context, _ *authz.RepoPermissions) error {
+				return authz.ErrPermsNotFound
+			}
 			edb.Mocks.Perms.Transact = func(_ context.Context) (*edb.PermsStore, error) {
 				return &edb.PermsStore{}, nil
 			}
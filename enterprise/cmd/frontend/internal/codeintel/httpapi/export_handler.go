@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/version"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/export"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+type ExportHandler struct {
+	dbStore   DBStore
+	lsifStore LSIFStore
+}
+
+func NewExportHandler(dbStore DBStore, lsifStore LSIFStore) http.Handler {
+	handler := &ExportHandler{
+		dbStore:   dbStore,
+		lsifStore: lsifStore,
+	}
+
+	return http.HandlerFunc(handler.handleExport)
+}
+
+// exportFormats maps the values of the format query parameter to the content type and file
+// extension of the response and the function that serializes the bundle.
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+	write       func(w io.Writer, bundle *semantic.GroupedBundleDataMaps, root string) error
+}{
+	"lsif": {
+		contentType: "application/x-ndjson",
+		extension:   "lsif",
+		write: func(w io.Writer, bundle *semantic.GroupedBundleDataMaps, root string) error {
+			return export.WriteLSIF(w, bundle, root, protocol.ToolInfo{Name: "sourcegraph", Version: version.Version()})
+		},
+	},
+	"jsonl": {
+		contentType: "application/x-ndjson",
+		extension:   "jsonl",
+		write: func(w io.Writer, bundle *semantic.GroupedBundleDataMaps, _ string) error {
+			return export.WriteJSONLines(w, bundle)
+		},
+	},
+}
+
+// GET /lsif/export?upload={id}&format={lsif,jsonl}
+//
+// Exports the processed data of a completed upload. The default format is LSIF, which can be
+// uploaded again with the root of the original upload. See the export package in lib/codeintel
+// for a description of the JSON lines format.
+func (h *ExportHandler) handleExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// 🚨 SECURITY: Exporting reads the entire bundle into memory and exposes the precise code
+	// intelligence data of any repository, so we restrict this endpoint to site admins.
+	if !isSiteAdmin(ctx) {
+		http.Error(w, "Must be a site admin to export uploads", http.StatusUnauthorized)
+		return
+	}
+
+	uploadID, err := strconv.Atoi(getQuery(r, "upload"))
+	if err != nil {
+		http.Error(w, "Upload must be a numeric identifier", http.StatusBadRequest)
+		return
+	}
+
+	formatName := getQuery(r, "format")
+	if formatName == "" {
+		formatName = "lsif"
+	}
+	format, ok := exportFormats[formatName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown export format %q", formatName), http.StatusBadRequest)
+		return
+	}
+
+	upload, exists, err := h.dbStore.GetUploadByID(ctx, uploadID)
+	if err != nil {
+		log15.Error("Failed to retrieve upload", "error", err)
+		http.Error(w, fmt.Sprintf("failed to retrieve upload: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "upload not found", http.StatusNotFound)
+		return
+	}
+	if upload.State != "completed" {
+		http.Error(w, fmt.Sprintf("upload is %s, only completed uploads can be exported", upload.State), http.StatusBadRequest)
+		return
+	}
+
+	bundle, err := h.lsifStore.Bundle(ctx, upload.ID)
+	if err != nil {
+		log15.Error("Failed to read bundle", "error", err)
+		http.Error(w, fmt.Sprintf("failed to read bundle: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=upload-%d.%s", upload.ID, format.extension))
+
+	if err := format.write(w, bundle, upload.Root); err != nil {
+		log15.Error("Failed to write export to client", "error", err)
+	}
+}
@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+func TestHandleExport(t *testing.T) {
+	setupSiteAdminMocks(t, true)
+
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockDBStore.GetUploadByIDFunc.SetDefaultReturn(store.Upload{ID: 42, State: "completed", Root: "sub/"}, true, nil)
+	mockLSIFStore.BundleFunc.SetDefaultReturn(testExportBundle(), nil)
+
+	for _, testCase := range []struct {
+		format    string
+		firstLine string
+		extension string
+	}{
+		{format: "", firstLine: `"label":"metaData"`, extension: "lsif"},
+		{format: "lsif", firstLine: `"projectRoot":"file:///sub/"`, extension: "lsif"},
+		{format: "jsonl", firstLine: `{"type":"document","path":"main.go"`, extension: "jsonl"},
+	} {
+		t.Run(testCase.format, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/export?upload=42&format="+testCase.format, nil)
+			NewExportHandler(mockDBStore, mockLSIFStore).ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status code. want=%d have=%d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if firstLine := strings.SplitN(w.Body.String(), "\n", 2)[0]; !strings.Contains(firstLine, testCase.firstLine) {
+				t.Errorf("unexpected first line. want substring=%q have=%q", testCase.firstLine, firstLine)
+			}
+			if disposition := w.Header().Get("Content-Disposition"); disposition != "attachment; filename=upload-42."+testCase.extension {
+				t.Errorf("unexpected content disposition %q", disposition)
+			}
+		})
+	}
+
+	if calls := mockLSIFStore.BundleFunc.History(); len(calls) != 3 || calls[0].Arg1 != 42 {
+		t.Errorf("unexpected bundle calls: %v", calls)
+	}
+}
+
+func TestHandleExportErrors(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockDBStore.GetUploadByIDFunc.SetDefaultHook(func(ctx context.Context, id int) (store.Upload, bool, error) {
+		if id == 43 {
+			return store.Upload{ID: 43, State: "processing"}, true, nil
+		}
+		return store.Upload{}, false, nil
+	})
+
+	for _, testCase := range []struct {
+		name       string
+		siteAdmin  bool
+		query      string
+		statusCode int
+	}{
+		{name: "not site admin", siteAdmin: false, query: "upload=42", statusCode: http.StatusUnauthorized},
+		{name: "malformed upload", siteAdmin: true, query: "upload=foo", statusCode: http.StatusBadRequest},
+		{name: "unknown format", siteAdmin: true, query: "upload=42&format=scip", statusCode: http.StatusBadRequest},
+		{name: "unknown upload", siteAdmin: true, query: "upload=42", statusCode: http.StatusNotFound},
+		{name: "incomplete upload", siteAdmin: true, query: "upload=43", statusCode: http.StatusBadRequest},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupSiteAdminMocks(t, testCase.siteAdmin)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/export?"+testCase.query, nil)
+			NewExportHandler(mockDBStore, mockLSIFStore).ServeHTTP(w, r)
+
+			if w.Code != testCase.statusCode {
+				t.Errorf("unexpected status code. want=%d have=%d", testCase.statusCode, w.Code)
+			}
+		})
+	}
+
+	if calls := mockLSIFStore.BundleFunc.History(); len(calls) != 0 {
+		t.Errorf("unexpected bundle calls: %v", calls)
+	}
+}
+
+func setupSiteAdminMocks(t testing.TB, siteAdmin bool) {
+	t.Cleanup(func() {
+		database.Mocks.Users.GetByCurrentAuthUser = nil
+	})
+
+	database.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: siteAdmin}, nil
+	}
+}
+
+func testExportBundle() *semantic.GroupedBundleDataMaps {
+	return &semantic.GroupedBundleDataMaps{
+		Meta: semantic.MetaData{NumResultChunks: 1},
+		Documents: map[string]semantic.DocumentData{
+			"main.go": {
+				Ranges: map[semantic.ID]semantic.RangeData{
+					"1": {StartLine: 1, StartCharacter: 2, EndLine: 1, EndCharacter: 5, DefinitionResultID: "2", HoverResultID: "3"},
+				},
+				HoverResults: map[semantic.ID]string{"3": "hover text"},
+			},
+		},
+		ResultChunks: map[int]semantic.ResultChunkData{
+			0: {
+				DocumentPaths:      map[semantic.ID]string{"4": "main.go"},
+				DocumentIDRangeIDs: map[semantic.ID][]semantic.DocumentIDRangeID{"2": {{DocumentID: "4", RangeID: "1"}}},
+			},
+		},
+	}
+}
@@ -1,3 +1,3 @@
 package httpapi
 
-//go:generate ../../../../../../dev/mockgen.sh github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/httpapi -i DBStore -o mock_iface_test.go
+//go:generate ../../../../../../dev/mockgen.sh github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/httpapi -i DBStore -i LSIFStore -o mock_iface_test.go
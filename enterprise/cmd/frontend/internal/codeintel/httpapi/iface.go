@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
 )
 
 type DBStore interface {
@@ -17,6 +18,10 @@ type DBStore interface {
 	MarkFailed(ctx context.Context, id int, reason string) error
 }
 
+type LSIFStore interface {
+	Bundle(ctx context.Context, bundleID int) (*semantic.GroupedBundleDataMaps, error)
+}
+
 type DBStoreShim struct {
 	*dbstore.Store
 }
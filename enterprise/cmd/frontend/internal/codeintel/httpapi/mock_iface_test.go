@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	dbstore "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	semantic "github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
 )
 
 // MockDBStore is a mock implementation of the DBStore interface (from the
@@ -859,3 +860,143 @@ func (c DBStoreTransactFuncCall) Args() []interface{} {
 func (c DBStoreTransactFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
+
+// MockLSIFStore is a mock implementation of the LSIFStore interface (from
+// the package
+// github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/httpapi)
+// used for unit testing.
+type MockLSIFStore struct {
+	// BundleFunc is an instance of a mock function object controlling the
+	// behavior of the method Bundle.
+	BundleFunc *LSIFStoreBundleFunc
+}
+
+// NewMockLSIFStore creates a new mock of the LSIFStore interface. All
+// methods return zero values for all results, unless overwritten.
+func NewMockLSIFStore() *MockLSIFStore {
+	return &MockLSIFStore{
+		BundleFunc: &LSIFStoreBundleFunc{
+			defaultHook: func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+				return nil, nil
+			},
+		},
+	}
+}
+
+// NewMockLSIFStoreFrom creates a new mock of the MockLSIFStore interface.
+// All methods delegate to the given implementation, unless overwritten.
+func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
+	return &MockLSIFStore{
+		BundleFunc: &LSIFStoreBundleFunc{
+			defaultHook: i.Bundle,
+		},
+	}
+}
+
+// LSIFStoreBundleFunc describes the behavior when the Bundle method of the
+// parent MockLSIFStore instance is invoked.
+type LSIFStoreBundleFunc struct {
+	defaultHook func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)
+	hooks       []func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)
+	history     []LSIFStoreBundleFuncCall
+	mutex       sync.Mutex
+}
+
+// Bundle delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockLSIFStore) Bundle(v0 context.Context, v1 int) (*semantic.GroupedBundleDataMaps, error) {
+	r0, r1 := m.BundleFunc.nextHook()(v0, v1)
+	m.BundleFunc.appendCall(LSIFStoreBundleFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the Bundle method of the
+// parent MockLSIFStore instance is invoked and the hook queue is empty.
+func (f *LSIFStoreBundleFunc) SetDefaultHook(hook func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Bundle method of the parent MockLSIFStore instance invokes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *LSIFStoreBundleFunc) PushHook(hook func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreBundleFunc) SetDefaultReturn(r0 *semantic.GroupedBundleDataMaps, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreBundleFunc) PushReturn(r0 *semantic.GroupedBundleDataMaps, r1 error) {
+	f.PushHook(func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+		return r0, r1
+	})
+}
+
+func (f *LSIFStoreBundleFunc) nextHook() func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreBundleFunc) appendCall(r0 LSIFStoreBundleFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreBundleFuncCall objects describing
+// the invocations of this function.
+func (f *LSIFStoreBundleFunc) History() []LSIFStoreBundleFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreBundleFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreBundleFuncCall is an object that describes an invocation of
+// method Bundle on an instance of MockLSIFStore.
+type LSIFStoreBundleFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *semantic.GroupedBundleDataMaps
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreBundleFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreBundleFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
@@ -0,0 +1,133 @@
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
+)
+
+type CallHierarchyItemResolver struct {
+	location gql.LocationResolver
+	item     resolvers.AdjustedCallHierarchyItem
+}
+
+func (r *CallHierarchyItemResolver) Location(ctx context.Context) (gql.LocationResolver, error) {
+	return r.location, nil
+}
+
+func (r *CallHierarchyItemResolver) Hover(ctx context.Context) (gql.HoverResolver, error) {
+	if r.item.HoverText == "" {
+		return nil, nil
+	}
+
+	return NewHoverResolver(r.item.HoverText, convertRange(r.item.Location.AdjustedRange)), nil
+}
+
+type CallHierarchyIncomingCallConnectionResolver struct {
+	calls            []resolvers.AdjustedIncomingCall
+	cursor           *string
+	locationResolver *CachedLocationResolver
+}
+
+func NewCallHierarchyIncomingCallConnectionResolver(calls []resolvers.AdjustedIncomingCall, cursor *string, locationResolver *CachedLocationResolver) gql.CallHierarchyIncomingCallConnectionResolver {
+	return &CallHierarchyIncomingCallConnectionResolver{
+		calls:            calls,
+		cursor:           cursor,
+		locationResolver: locationResolver,
+	}
+}
+
+func (r *CallHierarchyIncomingCallConnectionResolver) Nodes(ctx context.Context) ([]gql.CallHierarchyIncomingCallResolver, error) {
+	resolvedCalls := make([]gql.CallHierarchyIncomingCallResolver, 0, len(r.calls))
+	for _, call := range r.calls {
+		from, fromRanges, ok, err := resolveCall(ctx, r.locationResolver, call.From, call.FromRanges)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		resolvedCalls = append(resolvedCalls, &CallHierarchyIncomingCallResolver{from: from, fromRanges: fromRanges})
+	}
+
+	return resolvedCalls, nil
+}
+
+func (r *CallHierarchyIncomingCallConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	return encodeCursor(r.cursor), nil
+}
+
+type CallHierarchyIncomingCallResolver struct {
+	from       gql.CallHierarchyItemResolver
+	fromRanges []gql.LocationResolver
+}
+
+func (r *CallHierarchyIncomingCallResolver) From(ctx context.Context) (gql.CallHierarchyItemResolver, error) {
+	return r.from, nil
+}
+
+func (r *CallHierarchyIncomingCallResolver) FromRanges(ctx context.Context) ([]gql.LocationResolver, error) {
+	return r.fromRanges, nil
+}
+
+type CallHierarchyOutgoingCallConnectionResolver struct {
+	calls            []resolvers.AdjustedOutgoingCall
+	locationResolver *CachedLocationResolver
+}
+
+func NewCallHierarchyOutgoingCallConnectionResolver(calls []resolvers.AdjustedOutgoingCall, locationResolver *CachedLocationResolver) gql.CallHierarchyOutgoingCallConnectionResolver {
+	return &CallHierarchyOutgoingCallConnectionResolver{
+		calls:            calls,
+		locationResolver: locationResolver,
+	}
+}
+
+func (r *CallHierarchyOutgoingCallConnectionResolver) Nodes(ctx context.Context) ([]gql.CallHierarchyOutgoingCallResolver, error) {
+	resolvedCalls := make([]gql.CallHierarchyOutgoingCallResolver, 0, len(r.calls))
+	for _, call := range r.calls {
+		to, fromRanges, ok, err := resolveCall(ctx, r.locationResolver, call.To, call.FromRanges)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		resolvedCalls = append(resolvedCalls, &CallHierarchyOutgoingCallResolver{to: to, fromRanges: fromRanges})
+	}
+
+	return resolvedCalls, nil
+}
+
+type CallHierarchyOutgoingCallResolver struct {
+	to         gql.CallHierarchyItemResolver
+	fromRanges []gql.LocationResolver
+}
+
+func (r *CallHierarchyOutgoingCallResolver) To(ctx context.Context) (gql.CallHierarchyItemResolver, error) {
+	return r.to, nil
+}
+
+func (r *CallHierarchyOutgoingCallResolver) FromRanges(ctx context.Context) ([]gql.LocationResolver, error) {
+	return r.fromRanges, nil
+}
+
+// resolveCall creates resolvers for the call hierarchy item and call ranges of an incoming or outgoing
+// call. If the commit of the item's location is not known by gitserver, a false-valued flag is returned
+// and the call should be omitted.
+func resolveCall(ctx context.Context, locationResolver *CachedLocationResolver, item resolvers.AdjustedCallHierarchyItem, fromRanges []resolvers.AdjustedLocation) (gql.CallHierarchyItemResolver, []gql.LocationResolver, bool, error) {
+	location, err := resolveLocation(ctx, locationResolver, item.Location)
+	if err != nil || location == nil {
+		return nil, nil, false, err
+	}
+
+	resolvedFromRanges, err := resolveLocations(ctx, locationResolver, fromRanges)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	return &CallHierarchyItemResolver{location: location, item: item}, resolvedFromRanges, true, nil
+}
@@ -40,3 +40,15 @@ func unmarshalLSIFIndexGQLID(id graphql.ID) (indexID int64, err error) {
 	err = relay.UnmarshalSpec(id, &indexID)
 	return indexID, err
 }
+
+//
+//
+
+func marshalLSIFRetentionPolicyGQLID(policyID int64) graphql.ID {
+	return relay.MarshalID("LSIFRetentionPolicy", policyID)
+}
+
+func unmarshalLSIFRetentionPolicyGQLID(id graphql.ID) (policyID int64, err error) {
+	err = relay.UnmarshalSpec(id, &policyID)
+	return policyID, err
+}
@@ -86,6 +86,33 @@ func (r *QueryResolver) References(ctx context.Context, args *gql.LSIFPagedQuery
 	return NewLocationConnectionResolver(locations, strPtr(cursor), r.locationResolver), nil
 }
 
+func (r *QueryResolver) IncomingCalls(ctx context.Context, args *gql.LSIFPagedQueryPositionArgs) (gql.CallHierarchyIncomingCallConnectionResolver, error) {
+	limit := derefInt32(args.First, DefaultReferencesPageSize)
+	if limit <= 0 {
+		return nil, ErrIllegalLimit
+	}
+	cursor, err := decodeCursor(args.After)
+	if err != nil {
+		return nil, err
+	}
+
+	calls, cursor, err := r.resolver.IncomingCalls(ctx, int(args.Line), int(args.Character), limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCallHierarchyIncomingCallConnectionResolver(calls, strPtr(cursor), r.locationResolver), nil
+}
+
+func (r *QueryResolver) OutgoingCalls(ctx context.Context, args *gql.LSIFQueryPositionArgs) (gql.CallHierarchyOutgoingCallConnectionResolver, error) {
+	calls, err := r.resolver.OutgoingCalls(ctx, int(args.Line), int(args.Character))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCallHierarchyOutgoingCallConnectionResolver(calls, r.locationResolver), nil
+}
+
 func (r *QueryResolver) Hover(ctx context.Context, args *gql.LSIFQueryPositionArgs) (gql.HoverResolver, error) {
 	text, rx, exists, err := r.resolver.Hover(ctx, int(args.Line), int(args.Character))
 	if err != nil || !exists {
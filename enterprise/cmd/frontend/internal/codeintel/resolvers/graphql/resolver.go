@@ -3,6 +3,7 @@ package graphql
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/graph-gophers/graphql-go"
@@ -268,6 +269,181 @@ func (r *Resolver) GitBlobLSIFData(ctx context.Context, args *gql.GitBlobLSIFDat
 	return NewQueryResolver(resolver, r.locationResolver), nil
 }
 
+func (r *Resolver) LSIFUploadSymbolDiff(ctx context.Context, args *gql.LSIFUploadSymbolDiffArgs) (gql.LSIFSymbolDiffResolver, error) {
+	// 🚨 SECURITY: Only site admins may see LSIF upload data
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, dbconn.Global); err != nil {
+		return nil, err
+	}
+
+	baseUploadID, err := unmarshalLSIFUploadGQLID(args.Base)
+	if err != nil {
+		return nil, err
+	}
+	headUploadID, err := unmarshalLSIFUploadGQLID(args.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.symbolDiff(ctx, int(baseUploadID), int(headUploadID))
+}
+
+func (r *Resolver) LSIFSymbolDiffByRepo(ctx context.Context, args *gql.LSIFRepositorySymbolDiffArgs) (gql.LSIFSymbolDiffResolver, error) {
+	// 🚨 SECURITY: Only site admins may see LSIF upload data
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, dbconn.Global); err != nil {
+		return nil, err
+	}
+
+	repositoryID, err := gql.UnmarshalRepositoryID(args.RepositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	root := derefString(args.Root, "")
+	indexer := derefString(args.Indexer, "")
+
+	baseUpload, exists, err := r.resolver.UploadForCommit(ctx, int(repositoryID), args.Base, root, indexer)
+	if err != nil || !exists {
+		return nil, err
+	}
+	headUpload, exists, err := r.resolver.UploadForCommit(ctx, int(repositoryID), args.Head, root, indexer)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	return r.symbolDiff(ctx, baseUpload.ID, headUpload.ID)
+}
+
+func (r *Resolver) LSIFRetentionPolicies(ctx context.Context) ([]gql.LSIFRetentionPolicyResolver, error) {
+	// 🚨 SECURITY: Only site admins may see retention policies
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, dbconn.Global); err != nil {
+		return nil, err
+	}
+
+	policies, err := r.resolver.RetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]gql.LSIFRetentionPolicyResolver, 0, len(policies))
+	for _, policy := range policies {
+		resolvers = append(resolvers, NewRetentionPolicyResolver(policy))
+	}
+
+	return resolvers, nil
+}
+
+func (r *Resolver) CreateLSIFRetentionPolicy(ctx context.Context, args *gql.CreateLSIFRetentionPolicyArgs) (gql.LSIFRetentionPolicyResolver, error) {
+	// 🚨 SECURITY: Only site admins may configure retention policies
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, dbconn.Global); err != nil {
+		return nil, err
+	}
+
+	policy := store.RetentionPolicy{
+		Name:              args.Name,
+		RepositoryPattern: derefString(args.RepositoryPattern, "*"),
+		RefPattern:        args.RefPattern,
+		IndexingEnabled:   args.IndexingEnabled,
+	}
+	if args.MaxAgeSeconds != nil {
+		maxAge := time.Duration(*args.MaxAgeSeconds) * time.Second
+		policy.MaxAge = &maxAge
+	}
+	if args.KeepMostRecent != nil {
+		keepMostRecent := int(*args.KeepMostRecent)
+		policy.KeepMostRecent = &keepMostRecent
+	}
+	if err := store.ValidateRetentionPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	created, err := r.resolver.CreateRetentionPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRetentionPolicyResolver(created), nil
+}
+
+func (r *Resolver) DeleteLSIFRetentionPolicy(ctx context.Context, args *struct{ ID graphql.ID }) (*gql.EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins may configure retention policies
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, dbconn.Global); err != nil {
+		return nil, err
+	}
+
+	policyID, err := unmarshalLSIFRetentionPolicyGQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.resolver.DeleteRetentionPolicyByID(ctx, int(policyID)); err != nil {
+		return nil, err
+	}
+
+	return &gql.EmptyResponse{}, nil
+}
+
+func (r *Resolver) LSIFUploadsExpiredByRetentionPolicies(ctx context.Context, args *gql.LSIFUploadsExpiredByRetentionPoliciesArgs) ([]gql.LSIFUploadResolver, error) {
+	// 🚨 SECURITY: Only site admins may see LSIF upload data
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx, dbconn.Global); err != nil {
+		return nil, err
+	}
+
+	repositoryID := 0
+	if args.Repository != nil {
+		id, err := gql.UnmarshalRepositoryID(*args.Repository)
+		if err != nil {
+			return nil, err
+		}
+		repositoryID = int(id)
+	}
+
+	uploads, err := r.resolver.UploadsExpiredByRetentionPolicies(ctx, repositoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new prefetcher here as we only want to cache upload and index records in
+	// the same graphQL request, not across different request.
+	prefetcher := NewPrefetcher(r.resolver)
+
+	resolvers := make([]gql.LSIFUploadResolver, 0, len(uploads))
+	for _, upload := range uploads {
+		resolvers = append(resolvers, NewUploadResolver(upload, prefetcher, r.locationResolver))
+	}
+
+	return resolvers, nil
+}
+
+func (r *Resolver) symbolDiff(ctx context.Context, baseUploadID, headUploadID int) (gql.LSIFSymbolDiffResolver, error) {
+	// Create a new prefetcher here as we only want to cache upload and index records in
+	// the same graphQL request, not across different request.
+	prefetcher := NewPrefetcher(r.resolver)
+	prefetcher.MarkUpload(baseUploadID)
+	prefetcher.MarkUpload(headUploadID)
+
+	baseUpload, exists, err := prefetcher.GetUploadByID(ctx, baseUploadID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.Errorf("upload %d not found", baseUploadID)
+	}
+	headUpload, exists, err := prefetcher.GetUploadByID(ctx, headUploadID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.Errorf("upload %d not found", headUploadID)
+	}
+
+	diff, err := r.resolver.SymbolDiff(ctx, baseUploadID, headUploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSymbolDiffResolver(baseUpload, headUpload, diff, prefetcher, r.locationResolver), nil
+}
+
 // makeGetUploadsOptions translates the given GraphQL arguments into options defined by the
 // store.GetUploads operations.
 func makeGetUploadsOptions(ctx context.Context, args *gql.LSIFRepositoryUploadsQueryArgs) (store.GetUploadsOptions, error) {
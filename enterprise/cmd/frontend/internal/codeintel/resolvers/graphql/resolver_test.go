@@ -95,6 +95,114 @@ func TestDeleteLSIFIndexUnauthenticated(t *testing.T) {
 	}
 }
 
+func TestCreateLSIFRetentionPolicy(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	t.Cleanup(func() {
+		database.Mocks.Users.GetByCurrentAuthUser = nil
+	})
+	database.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{SiteAdmin: true}, nil
+	}
+
+	mockResolver := resolvermocks.NewMockResolver()
+	mockResolver.CreateRetentionPolicyFunc.SetDefaultHook(func(ctx context.Context, policy store.RetentionPolicy) (store.RetentionPolicy, error) {
+		policy.ID = 42
+		return policy, nil
+	})
+
+	maxAgeSeconds := int32(3600)
+	policy, err := NewResolver(db, mockResolver).CreateLSIFRetentionPolicy(context.Background(), &gql.CreateLSIFRetentionPolicyArgs{
+		Name:          "pull requests",
+		RefPattern:    "pr/*",
+		MaxAgeSeconds: &maxAgeSeconds,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(mockResolver.CreateRetentionPolicyFunc.History()) != 1 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 1, len(mockResolver.CreateRetentionPolicyFunc.History()))
+	}
+	if val := policy.RepositoryPattern(); val != "*" {
+		t.Errorf("unexpected repository pattern. want=%q have=%q", "*", val)
+	}
+	if val := policy.MaxAgeSeconds(); val == nil || *val != maxAgeSeconds {
+		t.Errorf("unexpected max age. want=%d have=%v", maxAgeSeconds, val)
+	}
+	if val := policy.KeepMostRecent(); val != nil {
+		t.Errorf("unexpected keep most recent. want=nil have=%d", *val)
+	}
+	if val := policy.ID(); val != graphql.ID(base64.StdEncoding.EncodeToString([]byte("LSIFRetentionPolicy:42"))) {
+		t.Errorf("unexpected id. have=%q", val)
+	}
+}
+
+func TestCreateLSIFRetentionPolicyInvalidPattern(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	t.Cleanup(func() {
+		database.Mocks.Users.GetByCurrentAuthUser = nil
+	})
+	database.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{SiteAdmin: true}, nil
+	}
+
+	mockResolver := resolvermocks.NewMockResolver()
+
+	if _, err := NewResolver(db, mockResolver).CreateLSIFRetentionPolicy(context.Background(), &gql.CreateLSIFRetentionPolicyArgs{
+		Name:       "invalid",
+		RefPattern: "[release",
+	}); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if len(mockResolver.CreateRetentionPolicyFunc.History()) != 0 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 0, len(mockResolver.CreateRetentionPolicyFunc.History()))
+	}
+}
+
+func TestLSIFUploadsExpiredByRetentionPolicies(t *testing.T) {
+	db := new(dbtesting.MockDB)
+
+	t.Cleanup(func() {
+		database.Mocks.Users.GetByCurrentAuthUser = nil
+	})
+	database.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{SiteAdmin: true}, nil
+	}
+
+	mockResolver := resolvermocks.NewMockResolver()
+	mockResolver.UploadsExpiredByRetentionPoliciesFunc.SetDefaultReturn([]store.Upload{{ID: 12}, {ID: 16}}, nil)
+
+	repositoryID := graphql.ID(base64.StdEncoding.EncodeToString([]byte("Repository:50")))
+	uploads, err := NewResolver(db, mockResolver).LSIFUploadsExpiredByRetentionPolicies(context.Background(), &gql.LSIFUploadsExpiredByRetentionPoliciesArgs{
+		Repository: &repositoryID,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(uploads) != 2 {
+		t.Fatalf("unexpected number of uploads. want=%d have=%d", 2, len(uploads))
+	}
+
+	if len(mockResolver.UploadsExpiredByRetentionPoliciesFunc.History()) != 1 {
+		t.Fatalf("unexpected call count. want=%d have=%d", 1, len(mockResolver.UploadsExpiredByRetentionPoliciesFunc.History()))
+	}
+	if val := mockResolver.UploadsExpiredByRetentionPoliciesFunc.History()[0].Arg1; val != 50 {
+		t.Fatalf("unexpected repository id. want=%d have=%d", 50, val)
+	}
+}
+
+func TestLSIFUploadsExpiredByRetentionPoliciesUnauthenticated(t *testing.T) {
+	db := new(dbtesting.MockDB)
+	mockResolver := resolvermocks.NewMockResolver()
+
+	if _, err := NewResolver(db, mockResolver).LSIFUploadsExpiredByRetentionPolicies(context.Background(), &gql.LSIFUploadsExpiredByRetentionPoliciesArgs{}); err != backend.ErrNotAuthenticated {
+		t.Errorf("unexpected error. want=%q have=%q", backend.ErrNotAuthenticated, err)
+	}
+}
+
 func TestMakeGetUploadsOptions(t *testing.T) {
 	t.Cleanup(func() {
 		database.Mocks.Repos.Get = nil
@@ -0,0 +1,40 @@
+package graphql
+
+import (
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+)
+
+type RetentionPolicyResolver struct {
+	policy store.RetentionPolicy
+}
+
+func NewRetentionPolicyResolver(policy store.RetentionPolicy) gql.LSIFRetentionPolicyResolver {
+	return &RetentionPolicyResolver{
+		policy: policy,
+	}
+}
+
+func (r *RetentionPolicyResolver) ID() graphql.ID {
+	return marshalLSIFRetentionPolicyGQLID(int64(r.policy.ID))
+}
+func (r *RetentionPolicyResolver) Name() string              { return r.policy.Name }
+func (r *RetentionPolicyResolver) RepositoryPattern() string { return r.policy.RepositoryPattern }
+func (r *RetentionPolicyResolver) RefPattern() string        { return r.policy.RefPattern }
+func (r *RetentionPolicyResolver) KeepMostRecent() *int32    { return toInt32(r.policy.KeepMostRecent) }
+func (r *RetentionPolicyResolver) IndexingEnabled() bool     { return r.policy.IndexingEnabled }
+func (r *RetentionPolicyResolver) CreatedAt() gql.DateTime {
+	return gql.DateTime{Time: r.policy.CreatedAt}
+}
+
+func (r *RetentionPolicyResolver) MaxAgeSeconds() *int32 {
+	if r.policy.MaxAge == nil {
+		return nil
+	}
+
+	return intPtr(int32(*r.policy.MaxAge / time.Second))
+}
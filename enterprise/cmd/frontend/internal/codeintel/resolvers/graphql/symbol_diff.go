@@ -0,0 +1,124 @@
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
+	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic/diff"
+)
+
+type SymbolDiffResolver struct {
+	base             store.Upload
+	head             store.Upload
+	diff             diff.SymbolDiff
+	prefetcher       *Prefetcher
+	locationResolver *CachedLocationResolver
+}
+
+func NewSymbolDiffResolver(base, head store.Upload, diff diff.SymbolDiff, prefetcher *Prefetcher, locationResolver *CachedLocationResolver) gql.LSIFSymbolDiffResolver {
+	return &SymbolDiffResolver{
+		base:             base,
+		head:             head,
+		diff:             diff,
+		prefetcher:       prefetcher,
+		locationResolver: locationResolver,
+	}
+}
+
+func (r *SymbolDiffResolver) Base() gql.LSIFUploadResolver {
+	return NewUploadResolver(r.base, r.prefetcher, r.locationResolver)
+}
+
+func (r *SymbolDiffResolver) Head() gql.LSIFUploadResolver {
+	return NewUploadResolver(r.head, r.prefetcher, r.locationResolver)
+}
+
+func (r *SymbolDiffResolver) Added() []gql.LSIFSymbolResolver {
+	return r.symbolResolvers(r.head, r.diff.Added)
+}
+
+func (r *SymbolDiffResolver) Removed() []gql.LSIFSymbolResolver {
+	return r.symbolResolvers(r.base, r.diff.Removed)
+}
+
+func (r *SymbolDiffResolver) Changed() []gql.LSIFSymbolChangeResolver {
+	resolvers := make([]gql.LSIFSymbolChangeResolver, 0, len(r.diff.Changed))
+	for _, changed := range r.diff.Changed {
+		resolvers = append(resolvers, &SymbolChangeResolver{
+			base: NewSymbolResolver(r.base, changed.Old, r.locationResolver),
+			head: NewSymbolResolver(r.head, changed.New, r.locationResolver),
+		})
+	}
+
+	return resolvers
+}
+
+func (r *SymbolDiffResolver) symbolResolvers(upload store.Upload, symbols []diff.Symbol) []gql.LSIFSymbolResolver {
+	resolvers := make([]gql.LSIFSymbolResolver, 0, len(symbols))
+	for _, symbol := range symbols {
+		resolvers = append(resolvers, NewSymbolResolver(upload, symbol, r.locationResolver))
+	}
+
+	return resolvers
+}
+
+type SymbolChangeResolver struct {
+	base gql.LSIFSymbolResolver
+	head gql.LSIFSymbolResolver
+}
+
+func (r *SymbolChangeResolver) Base() gql.LSIFSymbolResolver { return r.base }
+func (r *SymbolChangeResolver) Head() gql.LSIFSymbolResolver { return r.head }
+
+type SymbolResolver struct {
+	upload           store.Upload
+	symbol           diff.Symbol
+	locationResolver *CachedLocationResolver
+}
+
+func NewSymbolResolver(upload store.Upload, symbol diff.Symbol, locationResolver *CachedLocationResolver) gql.LSIFSymbolResolver {
+	return &SymbolResolver{
+		upload:           upload,
+		symbol:           symbol,
+		locationResolver: locationResolver,
+	}
+}
+
+func (r *SymbolResolver) Scheme() string     { return r.symbol.Scheme }
+func (r *SymbolResolver) Identifier() string { return r.symbol.Identifier }
+
+func (r *SymbolResolver) Hover() *gql.Markdown {
+	if r.symbol.Hover == "" {
+		return nil
+	}
+
+	hover := gql.Markdown(r.symbol.Hover)
+	return &hover
+}
+
+func (r *SymbolResolver) Definitions(ctx context.Context) ([]gql.LocationResolver, error) {
+	dump := store.Dump{
+		ID:           r.upload.ID,
+		Commit:       r.upload.Commit,
+		Root:         r.upload.Root,
+		RepositoryID: r.upload.RepositoryID,
+	}
+
+	locations := make([]resolvers.AdjustedLocation, 0, len(r.symbol.Definitions))
+	for _, location := range r.symbol.Definitions {
+		locations = append(locations, resolvers.AdjustedLocation{
+			Dump:           dump,
+			Path:           dump.Root + location.URI,
+			AdjustedCommit: dump.Commit,
+			AdjustedRange: lsifstore.Range{
+				Start: lsifstore.Position{Line: location.StartLine, Character: location.StartCharacter},
+				End:   lsifstore.Position{Line: location.EndLine, Character: location.EndCharacter},
+			},
+		})
+	}
+
+	return resolveLocations(ctx, r.locationResolver, locations)
+}
@@ -40,6 +40,10 @@ type DBStore interface {
 	DeleteIndexByID(ctx context.Context, id int) (bool, error)
 	GetIndexConfigurationByRepositoryID(ctx context.Context, repositoryID int) (store.IndexConfiguration, bool, error)
 	UpdateIndexConfigurationByRepositoryID(ctx context.Context, repositoryID int, data []byte) error
+	GetRetentionPolicies(ctx context.Context) ([]dbstore.RetentionPolicy, error)
+	CreateRetentionPolicy(ctx context.Context, policy dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)
+	DeleteRetentionPolicyByID(ctx context.Context, id int) (bool, error)
+	UploadsExpiredByRetentionPolicies(ctx context.Context, repositoryID int, now time.Time) ([]int, error)
 }
 
 type LSIFStore interface {
@@ -57,6 +61,8 @@ type LSIFStore interface {
 	DocumentationDefinitions(ctx context.Context, bundleID int, pathID string, limit, offset int) ([]lsifstore.Location, int, error)
 	DocumentationReferences(ctx context.Context, bundleID int, pathID string, limit, offset int) ([]lsifstore.Location, int, error)
 	DocumentationAtPosition(ctx context.Context, bundleID int, path string, line, character int) ([]string, error)
+	DefinitionsBundle(ctx context.Context, bundleID int) (*semantic.GroupedBundleDataMaps, error)
+	CallableDefinitions(ctx context.Context, bundleID int, path string) ([]lsifstore.CallableDefinition, error)
 }
 
 type IndexEnqueuer interface {
@@ -27,12 +27,19 @@ type MockDBStore struct {
 	// CommitGraphMetadataFunc is an instance of a mock function object
 	// controlling the behavior of the method CommitGraphMetadata.
 	CommitGraphMetadataFunc *DBStoreCommitGraphMetadataFunc
+	// CreateRetentionPolicyFunc is an instance of a mock function object
+	// controlling the behavior of the method CreateRetentionPolicy.
+	CreateRetentionPolicyFunc *DBStoreCreateRetentionPolicyFunc
 	// DefinitionDumpsFunc is an instance of a mock function object
 	// controlling the behavior of the method DefinitionDumps.
 	DefinitionDumpsFunc *DBStoreDefinitionDumpsFunc
 	// DeleteIndexByIDFunc is an instance of a mock function object
 	// controlling the behavior of the method DeleteIndexByID.
 	DeleteIndexByIDFunc *DBStoreDeleteIndexByIDFunc
+	// DeleteRetentionPolicyByIDFunc is an instance of a mock function
+	// object controlling the behavior of the method
+	// DeleteRetentionPolicyByID.
+	DeleteRetentionPolicyByIDFunc *DBStoreDeleteRetentionPolicyByIDFunc
 	// DeleteUploadByIDFunc is an instance of a mock function object
 	// controlling the behavior of the method DeleteUploadByID.
 	DeleteUploadByIDFunc *DBStoreDeleteUploadByIDFunc
@@ -59,6 +66,9 @@ type MockDBStore struct {
 	// GetIndexesByIDsFunc is an instance of a mock function object
 	// controlling the behavior of the method GetIndexesByIDs.
 	GetIndexesByIDsFunc *DBStoreGetIndexesByIDsFunc
+	// GetRetentionPoliciesFunc is an instance of a mock function object
+	// controlling the behavior of the method GetRetentionPolicies.
+	GetRetentionPoliciesFunc *DBStoreGetRetentionPoliciesFunc
 	// GetUploadByIDFunc is an instance of a mock function object
 	// controlling the behavior of the method GetUploadByID.
 	GetUploadByIDFunc *DBStoreGetUploadByIDFunc
@@ -87,6 +97,10 @@ type MockDBStore struct {
 	// function object controlling the behavior of the method
 	// UpdateIndexConfigurationByRepositoryID.
 	UpdateIndexConfigurationByRepositoryIDFunc *DBStoreUpdateIndexConfigurationByRepositoryIDFunc
+	// UploadsExpiredByRetentionPoliciesFunc is an instance of a mock
+	// function object controlling the behavior of the method
+	// UploadsExpiredByRetentionPolicies.
+	UploadsExpiredByRetentionPoliciesFunc *DBStoreUploadsExpiredByRetentionPoliciesFunc
 }
 
 // NewMockDBStore creates a new mock of the DBStore interface. All methods
@@ -98,6 +112,11 @@ func NewMockDBStore() *MockDBStore {
 				return false, nil, nil
 			},
 		},
+		CreateRetentionPolicyFunc: &DBStoreCreateRetentionPolicyFunc{
+			defaultHook: func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+				return dbstore.RetentionPolicy{}, nil
+			},
+		},
 		DefinitionDumpsFunc: &DBStoreDefinitionDumpsFunc{
 			defaultHook: func(context.Context, []semantic.QualifiedMonikerData) ([]dbstore.Dump, error) {
 				return nil, nil
@@ -108,6 +127,11 @@ func NewMockDBStore() *MockDBStore {
 				return false, nil
 			},
 		},
+		DeleteRetentionPolicyByIDFunc: &DBStoreDeleteRetentionPolicyByIDFunc{
+			defaultHook: func(context.Context, int) (bool, error) {
+				return false, nil
+			},
+		},
 		DeleteUploadByIDFunc: &DBStoreDeleteUploadByIDFunc{
 			defaultHook: func(context.Context, int) (bool, error) {
 				return false, nil
@@ -148,6 +172,11 @@ func NewMockDBStore() *MockDBStore {
 				return nil, nil
 			},
 		},
+		GetRetentionPoliciesFunc: &DBStoreGetRetentionPoliciesFunc{
+			defaultHook: func(context.Context) ([]dbstore.RetentionPolicy, error) {
+				return nil, nil
+			},
+		},
 		GetUploadByIDFunc: &DBStoreGetUploadByIDFunc{
 			defaultHook: func(context.Context, int) (dbstore.Upload, bool, error) {
 				return dbstore.Upload{}, false, nil
@@ -193,6 +222,11 @@ func NewMockDBStore() *MockDBStore {
 				return nil
 			},
 		},
+		UploadsExpiredByRetentionPoliciesFunc: &DBStoreUploadsExpiredByRetentionPoliciesFunc{
+			defaultHook: func(context.Context, int, time.Time) ([]int, error) {
+				return nil, nil
+			},
+		},
 	}
 }
 
@@ -203,12 +237,18 @@ func NewMockDBStoreFrom(i DBStore) *MockDBStore {
 		CommitGraphMetadataFunc: &DBStoreCommitGraphMetadataFunc{
 			defaultHook: i.CommitGraphMetadata,
 		},
+		CreateRetentionPolicyFunc: &DBStoreCreateRetentionPolicyFunc{
+			defaultHook: i.CreateRetentionPolicy,
+		},
 		DefinitionDumpsFunc: &DBStoreDefinitionDumpsFunc{
 			defaultHook: i.DefinitionDumps,
 		},
 		DeleteIndexByIDFunc: &DBStoreDeleteIndexByIDFunc{
 			defaultHook: i.DeleteIndexByID,
 		},
+		DeleteRetentionPolicyByIDFunc: &DBStoreDeleteRetentionPolicyByIDFunc{
+			defaultHook: i.DeleteRetentionPolicyByID,
+		},
 		DeleteUploadByIDFunc: &DBStoreDeleteUploadByIDFunc{
 			defaultHook: i.DeleteUploadByID,
 		},
@@ -233,6 +273,9 @@ func NewMockDBStoreFrom(i DBStore) *MockDBStore {
 		GetIndexesByIDsFunc: &DBStoreGetIndexesByIDsFunc{
 			defaultHook: i.GetIndexesByIDs,
 		},
+		GetRetentionPoliciesFunc: &DBStoreGetRetentionPoliciesFunc{
+			defaultHook: i.GetRetentionPolicies,
+		},
 		GetUploadByIDFunc: &DBStoreGetUploadByIDFunc{
 			defaultHook: i.GetUploadByID,
 		},
@@ -260,6 +303,9 @@ func NewMockDBStoreFrom(i DBStore) *MockDBStore {
 		UpdateIndexConfigurationByRepositoryIDFunc: &DBStoreUpdateIndexConfigurationByRepositoryIDFunc{
 			defaultHook: i.UpdateIndexConfigurationByRepositoryID,
 		},
+		UploadsExpiredByRetentionPoliciesFunc: &DBStoreUploadsExpiredByRetentionPoliciesFunc{
+			defaultHook: i.UploadsExpiredByRetentionPolicies,
+		},
 	}
 }
 
@@ -375,6 +421,116 @@ func (c DBStoreCommitGraphMetadataFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2}
 }
 
+// DBStoreCreateRetentionPolicyFunc describes the behavior when the
+// CreateRetentionPolicy method of the parent MockDBStore instance is
+// invoked.
+type DBStoreCreateRetentionPolicyFunc struct {
+	defaultHook func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)
+	hooks       []func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)
+	history     []DBStoreCreateRetentionPolicyFuncCall
+	mutex       sync.Mutex
+}
+
+// CreateRetentionPolicy delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockDBStore) CreateRetentionPolicy(v0 context.Context, v1 dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+	r0, r1 := m.CreateRetentionPolicyFunc.nextHook()(v0, v1)
+	m.CreateRetentionPolicyFunc.appendCall(DBStoreCreateRetentionPolicyFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// CreateRetentionPolicy method of the parent MockDBStore instance is
+// invoked and the hook queue is empty.
+func (f *DBStoreCreateRetentionPolicyFunc) SetDefaultHook(hook func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// CreateRetentionPolicy method of the parent MockDBStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *DBStoreCreateRetentionPolicyFunc) PushHook(hook func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStoreCreateRetentionPolicyFunc) SetDefaultReturn(r0 dbstore.RetentionPolicy, r1 error) {
+	f.SetDefaultHook(func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStoreCreateRetentionPolicyFunc) PushReturn(r0 dbstore.RetentionPolicy, r1 error) {
+	f.PushHook(func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBStoreCreateRetentionPolicyFunc) nextHook() func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStoreCreateRetentionPolicyFunc) appendCall(r0 DBStoreCreateRetentionPolicyFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBStoreCreateRetentionPolicyFuncCall
+// objects describing the invocations of this function.
+func (f *DBStoreCreateRetentionPolicyFunc) History() []DBStoreCreateRetentionPolicyFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStoreCreateRetentionPolicyFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStoreCreateRetentionPolicyFuncCall is an object that describes an
+// invocation of method CreateRetentionPolicy on an instance of MockDBStore.
+type DBStoreCreateRetentionPolicyFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 dbstore.RetentionPolicy
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 dbstore.RetentionPolicy
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStoreCreateRetentionPolicyFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStoreCreateRetentionPolicyFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // DBStoreDefinitionDumpsFunc describes the behavior when the
 // DefinitionDumps method of the parent MockDBStore instance is invoked.
 type DBStoreDefinitionDumpsFunc struct {
@@ -593,6 +749,118 @@ func (c DBStoreDeleteIndexByIDFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// DBStoreDeleteRetentionPolicyByIDFunc describes the behavior when the
+// DeleteRetentionPolicyByID method of the parent MockDBStore instance is
+// invoked.
+type DBStoreDeleteRetentionPolicyByIDFunc struct {
+	defaultHook func(context.Context, int) (bool, error)
+	hooks       []func(context.Context, int) (bool, error)
+	history     []DBStoreDeleteRetentionPolicyByIDFuncCall
+	mutex       sync.Mutex
+}
+
+// DeleteRetentionPolicyByID delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockDBStore) DeleteRetentionPolicyByID(v0 context.Context, v1 int) (bool, error) {
+	r0, r1 := m.DeleteRetentionPolicyByIDFunc.nextHook()(v0, v1)
+	m.DeleteRetentionPolicyByIDFunc.appendCall(DBStoreDeleteRetentionPolicyByIDFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// DeleteRetentionPolicyByID method of the parent MockDBStore instance is
+// invoked and the hook queue is empty.
+func (f *DBStoreDeleteRetentionPolicyByIDFunc) SetDefaultHook(hook func(context.Context, int) (bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// DeleteRetentionPolicyByID method of the parent MockDBStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *DBStoreDeleteRetentionPolicyByIDFunc) PushHook(hook func(context.Context, int) (bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStoreDeleteRetentionPolicyByIDFunc) SetDefaultReturn(r0 bool, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (bool, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStoreDeleteRetentionPolicyByIDFunc) PushReturn(r0 bool, r1 error) {
+	f.PushHook(func(context.Context, int) (bool, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBStoreDeleteRetentionPolicyByIDFunc) nextHook() func(context.Context, int) (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStoreDeleteRetentionPolicyByIDFunc) appendCall(r0 DBStoreDeleteRetentionPolicyByIDFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBStoreDeleteRetentionPolicyByIDFuncCall
+// objects describing the invocations of this function.
+func (f *DBStoreDeleteRetentionPolicyByIDFunc) History() []DBStoreDeleteRetentionPolicyByIDFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStoreDeleteRetentionPolicyByIDFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStoreDeleteRetentionPolicyByIDFuncCall is an object that describes an
+// invocation of method DeleteRetentionPolicyByID on an instance of
+// MockDBStore.
+type DBStoreDeleteRetentionPolicyByIDFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 bool
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStoreDeleteRetentionPolicyByIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStoreDeleteRetentionPolicyByIDFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // DBStoreDeleteUploadByIDFunc describes the behavior when the
 // DeleteUploadByID method of the parent MockDBStore instance is invoked.
 type DBStoreDeleteUploadByIDFunc struct {
@@ -1516,35 +1784,36 @@ func (c DBStoreGetIndexesByIDsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
-// DBStoreGetUploadByIDFunc describes the behavior when the GetUploadByID
-// method of the parent MockDBStore instance is invoked.
-type DBStoreGetUploadByIDFunc struct {
-	defaultHook func(context.Context, int) (dbstore.Upload, bool, error)
-	hooks       []func(context.Context, int) (dbstore.Upload, bool, error)
-	history     []DBStoreGetUploadByIDFuncCall
+// DBStoreGetRetentionPoliciesFunc describes the behavior when the
+// GetRetentionPolicies method of the parent MockDBStore instance is
+// invoked.
+type DBStoreGetRetentionPoliciesFunc struct {
+	defaultHook func(context.Context) ([]dbstore.RetentionPolicy, error)
+	hooks       []func(context.Context) ([]dbstore.RetentionPolicy, error)
+	history     []DBStoreGetRetentionPoliciesFuncCall
 	mutex       sync.Mutex
 }
 
-// GetUploadByID delegates to the next hook function in the queue and stores
-// the parameter and result values of this invocation.
-func (m *MockDBStore) GetUploadByID(v0 context.Context, v1 int) (dbstore.Upload, bool, error) {
-	r0, r1, r2 := m.GetUploadByIDFunc.nextHook()(v0, v1)
-	m.GetUploadByIDFunc.appendCall(DBStoreGetUploadByIDFuncCall{v0, v1, r0, r1, r2})
-	return r0, r1, r2
+// GetRetentionPolicies delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDBStore) GetRetentionPolicies(v0 context.Context) ([]dbstore.RetentionPolicy, error) {
+	r0, r1 := m.GetRetentionPoliciesFunc.nextHook()(v0)
+	m.GetRetentionPoliciesFunc.appendCall(DBStoreGetRetentionPoliciesFuncCall{v0, r0, r1})
+	return r0, r1
 }
 
-// SetDefaultHook sets function that is called when the GetUploadByID method
-// of the parent MockDBStore instance is invoked and the hook queue is
-// empty.
-func (f *DBStoreGetUploadByIDFunc) SetDefaultHook(hook func(context.Context, int) (dbstore.Upload, bool, error)) {
+// SetDefaultHook sets function that is called when the GetRetentionPolicies
+// method of the parent MockDBStore instance is invoked and the hook queue
+// is empty.
+func (f *DBStoreGetRetentionPoliciesFunc) SetDefaultHook(hook func(context.Context) ([]dbstore.RetentionPolicy, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// GetUploadByID method of the parent MockDBStore instance invokes the hook
-// at the front of the queue and discards it. After the queue is empty, the
-// default hook function is invoked for any future action.
-func (f *DBStoreGetUploadByIDFunc) PushHook(hook func(context.Context, int) (dbstore.Upload, bool, error)) {
+// GetRetentionPolicies method of the parent MockDBStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *DBStoreGetRetentionPoliciesFunc) PushHook(hook func(context.Context) ([]dbstore.RetentionPolicy, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1552,21 +1821,21 @@ func (f *DBStoreGetUploadByIDFunc) PushHook(hook func(context.Context, int) (dbs
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *DBStoreGetUploadByIDFunc) SetDefaultReturn(r0 dbstore.Upload, r1 bool, r2 error) {
-	f.SetDefaultHook(func(context.Context, int) (dbstore.Upload, bool, error) {
-		return r0, r1, r2
+func (f *DBStoreGetRetentionPoliciesFunc) SetDefaultReturn(r0 []dbstore.RetentionPolicy, r1 error) {
+	f.SetDefaultHook(func(context.Context) ([]dbstore.RetentionPolicy, error) {
+		return r0, r1
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *DBStoreGetUploadByIDFunc) PushReturn(r0 dbstore.Upload, r1 bool, r2 error) {
-	f.PushHook(func(context.Context, int) (dbstore.Upload, bool, error) {
-		return r0, r1, r2
+func (f *DBStoreGetRetentionPoliciesFunc) PushReturn(r0 []dbstore.RetentionPolicy, r1 error) {
+	f.PushHook(func(context.Context) ([]dbstore.RetentionPolicy, error) {
+		return r0, r1
 	})
 }
 
-func (f *DBStoreGetUploadByIDFunc) nextHook() func(context.Context, int) (dbstore.Upload, bool, error) {
+func (f *DBStoreGetRetentionPoliciesFunc) nextHook() func(context.Context) ([]dbstore.RetentionPolicy, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1579,61 +1848,167 @@ func (f *DBStoreGetUploadByIDFunc) nextHook() func(context.Context, int) (dbstor
 	return hook
 }
 
-func (f *DBStoreGetUploadByIDFunc) appendCall(r0 DBStoreGetUploadByIDFuncCall) {
+func (f *DBStoreGetRetentionPoliciesFunc) appendCall(r0 DBStoreGetRetentionPoliciesFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of DBStoreGetUploadByIDFuncCall objects
+// History returns a sequence of DBStoreGetRetentionPoliciesFuncCall objects
 // describing the invocations of this function.
-func (f *DBStoreGetUploadByIDFunc) History() []DBStoreGetUploadByIDFuncCall {
+func (f *DBStoreGetRetentionPoliciesFunc) History() []DBStoreGetRetentionPoliciesFuncCall {
 	f.mutex.Lock()
-	history := make([]DBStoreGetUploadByIDFuncCall, len(f.history))
+	history := make([]DBStoreGetRetentionPoliciesFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// DBStoreGetUploadByIDFuncCall is an object that describes an invocation of
-// method GetUploadByID on an instance of MockDBStore.
-type DBStoreGetUploadByIDFuncCall struct {
+// DBStoreGetRetentionPoliciesFuncCall is an object that describes an
+// invocation of method GetRetentionPolicies on an instance of MockDBStore.
+type DBStoreGetRetentionPoliciesFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
-	// Arg1 is the value of the 2nd argument passed to this method
-	// invocation.
-	Arg1 int
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 dbstore.Upload
+	Result0 []dbstore.RetentionPolicy
 	// Result1 is the value of the 2nd result returned from this method
 	// invocation.
-	Result1 bool
-	// Result2 is the value of the 3rd result returned from this method
-	// invocation.
-	Result2 error
+	Result1 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c DBStoreGetUploadByIDFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1}
+func (c DBStoreGetRetentionPoliciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c DBStoreGetUploadByIDFuncCall) Results() []interface{} {
-	return []interface{}{c.Result0, c.Result1, c.Result2}
+func (c DBStoreGetRetentionPoliciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
 }
 
-// DBStoreGetUploadsFunc describes the behavior when the GetUploads method
-// of the parent MockDBStore instance is invoked.
-type DBStoreGetUploadsFunc struct {
-	defaultHook func(context.Context, dbstore.GetUploadsOptions) ([]dbstore.Upload, int, error)
-	hooks       []func(context.Context, dbstore.GetUploadsOptions) ([]dbstore.Upload, int, error)
-	history     []DBStoreGetUploadsFuncCall
+// DBStoreGetUploadByIDFunc describes the behavior when the GetUploadByID
+// method of the parent MockDBStore instance is invoked.
+type DBStoreGetUploadByIDFunc struct {
+	defaultHook func(context.Context, int) (dbstore.Upload, bool, error)
+	hooks       []func(context.Context, int) (dbstore.Upload, bool, error)
+	history     []DBStoreGetUploadByIDFuncCall
+	mutex       sync.Mutex
+}
+
+// GetUploadByID delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDBStore) GetUploadByID(v0 context.Context, v1 int) (dbstore.Upload, bool, error) {
+	r0, r1, r2 := m.GetUploadByIDFunc.nextHook()(v0, v1)
+	m.GetUploadByIDFunc.appendCall(DBStoreGetUploadByIDFuncCall{v0, v1, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the GetUploadByID method
+// of the parent MockDBStore instance is invoked and the hook queue is
+// empty.
+func (f *DBStoreGetUploadByIDFunc) SetDefaultHook(hook func(context.Context, int) (dbstore.Upload, bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetUploadByID method of the parent MockDBStore instance invokes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *DBStoreGetUploadByIDFunc) PushHook(hook func(context.Context, int) (dbstore.Upload, bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStoreGetUploadByIDFunc) SetDefaultReturn(r0 dbstore.Upload, r1 bool, r2 error) {
+	f.SetDefaultHook(func(context.Context, int) (dbstore.Upload, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStoreGetUploadByIDFunc) PushReturn(r0 dbstore.Upload, r1 bool, r2 error) {
+	f.PushHook(func(context.Context, int) (dbstore.Upload, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBStoreGetUploadByIDFunc) nextHook() func(context.Context, int) (dbstore.Upload, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStoreGetUploadByIDFunc) appendCall(r0 DBStoreGetUploadByIDFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBStoreGetUploadByIDFuncCall objects
+// describing the invocations of this function.
+func (f *DBStoreGetUploadByIDFunc) History() []DBStoreGetUploadByIDFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStoreGetUploadByIDFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStoreGetUploadByIDFuncCall is an object that describes an invocation of
+// method GetUploadByID on an instance of MockDBStore.
+type DBStoreGetUploadByIDFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 dbstore.Upload
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 bool
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStoreGetUploadByIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStoreGetUploadByIDFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBStoreGetUploadsFunc describes the behavior when the GetUploads method
+// of the parent MockDBStore instance is invoked.
+type DBStoreGetUploadsFunc struct {
+	defaultHook func(context.Context, dbstore.GetUploadsOptions) ([]dbstore.Upload, int, error)
+	hooks       []func(context.Context, dbstore.GetUploadsOptions) ([]dbstore.Upload, int, error)
+	history     []DBStoreGetUploadsFuncCall
 	mutex       sync.Mutex
 }
 
@@ -2530,6 +2905,122 @@ func (c DBStoreUpdateIndexConfigurationByRepositoryIDFuncCall) Results() []inter
 	return []interface{}{c.Result0}
 }
 
+// DBStoreUploadsExpiredByRetentionPoliciesFunc describes the behavior when
+// the UploadsExpiredByRetentionPolicies method of the parent MockDBStore
+// instance is invoked.
+type DBStoreUploadsExpiredByRetentionPoliciesFunc struct {
+	defaultHook func(context.Context, int, time.Time) ([]int, error)
+	hooks       []func(context.Context, int, time.Time) ([]int, error)
+	history     []DBStoreUploadsExpiredByRetentionPoliciesFuncCall
+	mutex       sync.Mutex
+}
+
+// UploadsExpiredByRetentionPolicies delegates to the next hook function in
+// the queue and stores the parameter and result values of this invocation.
+func (m *MockDBStore) UploadsExpiredByRetentionPolicies(v0 context.Context, v1 int, v2 time.Time) ([]int, error) {
+	r0, r1 := m.UploadsExpiredByRetentionPoliciesFunc.nextHook()(v0, v1, v2)
+	m.UploadsExpiredByRetentionPoliciesFunc.appendCall(DBStoreUploadsExpiredByRetentionPoliciesFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// UploadsExpiredByRetentionPolicies method of the parent MockDBStore
+// instance is invoked and the hook queue is empty.
+func (f *DBStoreUploadsExpiredByRetentionPoliciesFunc) SetDefaultHook(hook func(context.Context, int, time.Time) ([]int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// UploadsExpiredByRetentionPolicies method of the parent MockDBStore
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *DBStoreUploadsExpiredByRetentionPoliciesFunc) PushHook(hook func(context.Context, int, time.Time) ([]int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStoreUploadsExpiredByRetentionPoliciesFunc) SetDefaultReturn(r0 []int, r1 error) {
+	f.SetDefaultHook(func(context.Context, int, time.Time) ([]int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStoreUploadsExpiredByRetentionPoliciesFunc) PushReturn(r0 []int, r1 error) {
+	f.PushHook(func(context.Context, int, time.Time) ([]int, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBStoreUploadsExpiredByRetentionPoliciesFunc) nextHook() func(context.Context, int, time.Time) ([]int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStoreUploadsExpiredByRetentionPoliciesFunc) appendCall(r0 DBStoreUploadsExpiredByRetentionPoliciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// DBStoreUploadsExpiredByRetentionPoliciesFuncCall objects describing the
+// invocations of this function.
+func (f *DBStoreUploadsExpiredByRetentionPoliciesFunc) History() []DBStoreUploadsExpiredByRetentionPoliciesFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStoreUploadsExpiredByRetentionPoliciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStoreUploadsExpiredByRetentionPoliciesFuncCall is an object that
+// describes an invocation of method UploadsExpiredByRetentionPolicies on an
+// instance of MockDBStore.
+type DBStoreUploadsExpiredByRetentionPoliciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 time.Time
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStoreUploadsExpiredByRetentionPoliciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStoreUploadsExpiredByRetentionPoliciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // MockEnqueuerDBStore is a mock implementation of the EnqueuerDBStore
 // interface (from the package
 // github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers)
@@ -2549,6 +3040,9 @@ type MockEnqueuerDBStore struct {
 	// function object controlling the behavior of the method
 	// GetRepositoriesWithIndexConfiguration.
 	GetRepositoriesWithIndexConfigurationFunc *EnqueuerDBStoreGetRepositoriesWithIndexConfigurationFunc
+	// GetRetentionPoliciesFunc is an instance of a mock function object
+	// controlling the behavior of the method GetRetentionPolicies.
+	GetRetentionPoliciesFunc *EnqueuerDBStoreGetRetentionPoliciesFunc
 	// HandleFunc is an instance of a mock function object controlling the
 	// behavior of the method Handle.
 	HandleFunc *EnqueuerDBStoreHandleFunc
@@ -2558,6 +3052,9 @@ type MockEnqueuerDBStore struct {
 	// IsQueuedFunc is an instance of a mock function object controlling the
 	// behavior of the method IsQueued.
 	IsQueuedFunc *EnqueuerDBStoreIsQueuedFunc
+	// RepoNameFunc is an instance of a mock function object controlling the
+	// behavior of the method RepoName.
+	RepoNameFunc *EnqueuerDBStoreRepoNameFunc
 	// TransactFunc is an instance of a mock function object controlling the
 	// behavior of the method Transact.
 	TransactFunc *EnqueuerDBStoreTransactFunc
@@ -2588,6 +3085,11 @@ func NewMockEnqueuerDBStore() *MockEnqueuerDBStore {
 				return nil, nil
 			},
 		},
+		GetRetentionPoliciesFunc: &EnqueuerDBStoreGetRetentionPoliciesFunc{
+			defaultHook: func(context.Context) ([]dbstore.RetentionPolicy, error) {
+				return nil, nil
+			},
+		},
 		HandleFunc: &EnqueuerDBStoreHandleFunc{
 			defaultHook: func() *basestore.TransactableHandle {
 				return nil
@@ -2603,6 +3105,11 @@ func NewMockEnqueuerDBStore() *MockEnqueuerDBStore {
 				return false, nil
 			},
 		},
+		RepoNameFunc: &EnqueuerDBStoreRepoNameFunc{
+			defaultHook: func(context.Context, int) (string, error) {
+				return "", nil
+			},
+		},
 		TransactFunc: &EnqueuerDBStoreTransactFunc{
 			defaultHook: func(context.Context) (enqueuer.DBStore, error) {
 				return nil, nil
@@ -2628,6 +3135,9 @@ func NewMockEnqueuerDBStoreFrom(i EnqueuerDBStore) *MockEnqueuerDBStore {
 		GetRepositoriesWithIndexConfigurationFunc: &EnqueuerDBStoreGetRepositoriesWithIndexConfigurationFunc{
 			defaultHook: i.GetRepositoriesWithIndexConfiguration,
 		},
+		GetRetentionPoliciesFunc: &EnqueuerDBStoreGetRetentionPoliciesFunc{
+			defaultHook: i.GetRetentionPolicies,
+		},
 		HandleFunc: &EnqueuerDBStoreHandleFunc{
 			defaultHook: i.Handle,
 		},
@@ -2637,6 +3147,9 @@ func NewMockEnqueuerDBStoreFrom(i EnqueuerDBStore) *MockEnqueuerDBStore {
 		IsQueuedFunc: &EnqueuerDBStoreIsQueuedFunc{
 			defaultHook: i.IsQueued,
 		},
+		RepoNameFunc: &EnqueuerDBStoreRepoNameFunc{
+			defaultHook: i.RepoName,
+		},
 		TransactFunc: &EnqueuerDBStoreTransactFunc{
 			defaultHook: i.Transact,
 		},
@@ -3085,6 +3598,115 @@ func (c EnqueuerDBStoreGetRepositoriesWithIndexConfigurationFuncCall) Results()
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// EnqueuerDBStoreGetRetentionPoliciesFunc describes the behavior when the
+// GetRetentionPolicies method of the parent MockEnqueuerDBStore instance is
+// invoked.
+type EnqueuerDBStoreGetRetentionPoliciesFunc struct {
+	defaultHook func(context.Context) ([]dbstore.RetentionPolicy, error)
+	hooks       []func(context.Context) ([]dbstore.RetentionPolicy, error)
+	history     []EnqueuerDBStoreGetRetentionPoliciesFuncCall
+	mutex       sync.Mutex
+}
+
+// GetRetentionPolicies delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockEnqueuerDBStore) GetRetentionPolicies(v0 context.Context) ([]dbstore.RetentionPolicy, error) {
+	r0, r1 := m.GetRetentionPoliciesFunc.nextHook()(v0)
+	m.GetRetentionPoliciesFunc.appendCall(EnqueuerDBStoreGetRetentionPoliciesFuncCall{v0, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the GetRetentionPolicies
+// method of the parent MockEnqueuerDBStore instance is invoked and the hook
+// queue is empty.
+func (f *EnqueuerDBStoreGetRetentionPoliciesFunc) SetDefaultHook(hook func(context.Context) ([]dbstore.RetentionPolicy, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetRetentionPolicies method of the parent MockEnqueuerDBStore instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *EnqueuerDBStoreGetRetentionPoliciesFunc) PushHook(hook func(context.Context) ([]dbstore.RetentionPolicy, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *EnqueuerDBStoreGetRetentionPoliciesFunc) SetDefaultReturn(r0 []dbstore.RetentionPolicy, r1 error) {
+	f.SetDefaultHook(func(context.Context) ([]dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *EnqueuerDBStoreGetRetentionPoliciesFunc) PushReturn(r0 []dbstore.RetentionPolicy, r1 error) {
+	f.PushHook(func(context.Context) ([]dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+func (f *EnqueuerDBStoreGetRetentionPoliciesFunc) nextHook() func(context.Context) ([]dbstore.RetentionPolicy, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *EnqueuerDBStoreGetRetentionPoliciesFunc) appendCall(r0 EnqueuerDBStoreGetRetentionPoliciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of EnqueuerDBStoreGetRetentionPoliciesFuncCall
+// objects describing the invocations of this function.
+func (f *EnqueuerDBStoreGetRetentionPoliciesFunc) History() []EnqueuerDBStoreGetRetentionPoliciesFuncCall {
+	f.mutex.Lock()
+	history := make([]EnqueuerDBStoreGetRetentionPoliciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// EnqueuerDBStoreGetRetentionPoliciesFuncCall is an object that describes
+// an invocation of method GetRetentionPolicies on an instance of
+// MockEnqueuerDBStore.
+type EnqueuerDBStoreGetRetentionPoliciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.RetentionPolicy
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c EnqueuerDBStoreGetRetentionPoliciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c EnqueuerDBStoreGetRetentionPoliciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // EnqueuerDBStoreHandleFunc describes the behavior when the Handle method
 // of the parent MockEnqueuerDBStore instance is invoked.
 type EnqueuerDBStoreHandleFunc struct {
@@ -3406,6 +4028,115 @@ func (c EnqueuerDBStoreIsQueuedFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// EnqueuerDBStoreRepoNameFunc describes the behavior when the RepoName
+// method of the parent MockEnqueuerDBStore instance is invoked.
+type EnqueuerDBStoreRepoNameFunc struct {
+	defaultHook func(context.Context, int) (string, error)
+	hooks       []func(context.Context, int) (string, error)
+	history     []EnqueuerDBStoreRepoNameFuncCall
+	mutex       sync.Mutex
+}
+
+// RepoName delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockEnqueuerDBStore) RepoName(v0 context.Context, v1 int) (string, error) {
+	r0, r1 := m.RepoNameFunc.nextHook()(v0, v1)
+	m.RepoNameFunc.appendCall(EnqueuerDBStoreRepoNameFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the RepoName method of
+// the parent MockEnqueuerDBStore instance is invoked and the hook queue is
+// empty.
+func (f *EnqueuerDBStoreRepoNameFunc) SetDefaultHook(hook func(context.Context, int) (string, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RepoName method of the parent MockEnqueuerDBStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *EnqueuerDBStoreRepoNameFunc) PushHook(hook func(context.Context, int) (string, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *EnqueuerDBStoreRepoNameFunc) SetDefaultReturn(r0 string, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (string, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *EnqueuerDBStoreRepoNameFunc) PushReturn(r0 string, r1 error) {
+	f.PushHook(func(context.Context, int) (string, error) {
+		return r0, r1
+	})
+}
+
+func (f *EnqueuerDBStoreRepoNameFunc) nextHook() func(context.Context, int) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *EnqueuerDBStoreRepoNameFunc) appendCall(r0 EnqueuerDBStoreRepoNameFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of EnqueuerDBStoreRepoNameFuncCall objects
+// describing the invocations of this function.
+func (f *EnqueuerDBStoreRepoNameFunc) History() []EnqueuerDBStoreRepoNameFuncCall {
+	f.mutex.Lock()
+	history := make([]EnqueuerDBStoreRepoNameFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// EnqueuerDBStoreRepoNameFuncCall is an object that describes an invocation
+// of method RepoName on an instance of MockEnqueuerDBStore.
+type EnqueuerDBStoreRepoNameFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 string
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c EnqueuerDBStoreRepoNameFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c EnqueuerDBStoreRepoNameFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // EnqueuerDBStoreTransactFunc describes the behavior when the Transact
 // method of the parent MockEnqueuerDBStore instance is invoked.
 type EnqueuerDBStoreTransactFunc struct {
@@ -3529,6 +4260,9 @@ type MockEnqueuerGitserverClient struct {
 	// RawContentsFunc is an instance of a mock function object controlling
 	// the behavior of the method RawContents.
 	RawContentsFunc *EnqueuerGitserverClientRawContentsFunc
+	// RefDescriptionsFunc is an instance of a mock function object
+	// controlling the behavior of the method RefDescriptions.
+	RefDescriptionsFunc *EnqueuerGitserverClientRefDescriptionsFunc
 	// ResolveRevisionFunc is an instance of a mock function object
 	// controlling the behavior of the method ResolveRevision.
 	ResolveRevisionFunc *EnqueuerGitserverClientResolveRevisionFunc
@@ -3559,6 +4293,11 @@ func NewMockEnqueuerGitserverClient() *MockEnqueuerGitserverClient {
 				return nil, nil
 			},
 		},
+		RefDescriptionsFunc: &EnqueuerGitserverClientRefDescriptionsFunc{
+			defaultHook: func(context.Context, int) (map[string]gitserver.RefDescription, error) {
+				return nil, nil
+			},
+		},
 		ResolveRevisionFunc: &EnqueuerGitserverClientResolveRevisionFunc{
 			defaultHook: func(context.Context, int, string) (api.CommitID, error) {
 				return "", nil
@@ -3584,6 +4323,9 @@ func NewMockEnqueuerGitserverClientFrom(i EnqueuerGitserverClient) *MockEnqueuer
 		RawContentsFunc: &EnqueuerGitserverClientRawContentsFunc{
 			defaultHook: i.RawContents,
 		},
+		RefDescriptionsFunc: &EnqueuerGitserverClientRefDescriptionsFunc{
+			defaultHook: i.RefDescriptions,
+		},
 		ResolveRevisionFunc: &EnqueuerGitserverClientResolveRevisionFunc{
 			defaultHook: i.ResolveRevision,
 		},
@@ -4056,6 +4798,119 @@ func (c EnqueuerGitserverClientRawContentsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// EnqueuerGitserverClientRefDescriptionsFunc describes the behavior when
+// the RefDescriptions method of the parent MockEnqueuerGitserverClient
+// instance is invoked.
+type EnqueuerGitserverClientRefDescriptionsFunc struct {
+	defaultHook func(context.Context, int) (map[string]gitserver.RefDescription, error)
+	hooks       []func(context.Context, int) (map[string]gitserver.RefDescription, error)
+	history     []EnqueuerGitserverClientRefDescriptionsFuncCall
+	mutex       sync.Mutex
+}
+
+// RefDescriptions delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockEnqueuerGitserverClient) RefDescriptions(v0 context.Context, v1 int) (map[string]gitserver.RefDescription, error) {
+	r0, r1 := m.RefDescriptionsFunc.nextHook()(v0, v1)
+	m.RefDescriptionsFunc.appendCall(EnqueuerGitserverClientRefDescriptionsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the RefDescriptions
+// method of the parent MockEnqueuerGitserverClient instance is invoked and
+// the hook queue is empty.
+func (f *EnqueuerGitserverClientRefDescriptionsFunc) SetDefaultHook(hook func(context.Context, int) (map[string]gitserver.RefDescription, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RefDescriptions method of the parent MockEnqueuerGitserverClient instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *EnqueuerGitserverClientRefDescriptionsFunc) PushHook(hook func(context.Context, int) (map[string]gitserver.RefDescription, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *EnqueuerGitserverClientRefDescriptionsFunc) SetDefaultReturn(r0 map[string]gitserver.RefDescription, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (map[string]gitserver.RefDescription, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *EnqueuerGitserverClientRefDescriptionsFunc) PushReturn(r0 map[string]gitserver.RefDescription, r1 error) {
+	f.PushHook(func(context.Context, int) (map[string]gitserver.RefDescription, error) {
+		return r0, r1
+	})
+}
+
+func (f *EnqueuerGitserverClientRefDescriptionsFunc) nextHook() func(context.Context, int) (map[string]gitserver.RefDescription, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *EnqueuerGitserverClientRefDescriptionsFunc) appendCall(r0 EnqueuerGitserverClientRefDescriptionsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// EnqueuerGitserverClientRefDescriptionsFuncCall objects describing the
+// invocations of this function.
+func (f *EnqueuerGitserverClientRefDescriptionsFunc) History() []EnqueuerGitserverClientRefDescriptionsFuncCall {
+	f.mutex.Lock()
+	history := make([]EnqueuerGitserverClientRefDescriptionsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// EnqueuerGitserverClientRefDescriptionsFuncCall is an object that
+// describes an invocation of method RefDescriptions on an instance of
+// MockEnqueuerGitserverClient.
+type EnqueuerGitserverClientRefDescriptionsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 map[string]gitserver.RefDescription
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c EnqueuerGitserverClientRefDescriptionsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c EnqueuerGitserverClientRefDescriptionsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // EnqueuerGitserverClientResolveRevisionFunc describes the behavior when
 // the ResolveRevision method of the parent MockEnqueuerGitserverClient
 // instance is invoked.
@@ -4718,9 +5573,15 @@ type MockLSIFStore struct {
 	// BulkMonikerResultsFunc is an instance of a mock function object
 	// controlling the behavior of the method BulkMonikerResults.
 	BulkMonikerResultsFunc *LSIFStoreBulkMonikerResultsFunc
+	// CallableDefinitionsFunc is an instance of a mock function object
+	// controlling the behavior of the method CallableDefinitions.
+	CallableDefinitionsFunc *LSIFStoreCallableDefinitionsFunc
 	// DefinitionsFunc is an instance of a mock function object controlling
 	// the behavior of the method Definitions.
 	DefinitionsFunc *LSIFStoreDefinitionsFunc
+	// DefinitionsBundleFunc is an instance of a mock function object
+	// controlling the behavior of the method DefinitionsBundle.
+	DefinitionsBundleFunc *LSIFStoreDefinitionsBundleFunc
 	// DiagnosticsFunc is an instance of a mock function object controlling
 	// the behavior of the method Diagnostics.
 	DiagnosticsFunc *LSIFStoreDiagnosticsFunc
@@ -4768,11 +5629,21 @@ func NewMockLSIFStore() *MockLSIFStore {
 				return nil, 0, nil
 			},
 		},
+		CallableDefinitionsFunc: &LSIFStoreCallableDefinitionsFunc{
+			defaultHook: func(context.Context, int, string) ([]lsifstore.CallableDefinition, error) {
+				return nil, nil
+			},
+		},
 		DefinitionsFunc: &LSIFStoreDefinitionsFunc{
 			defaultHook: func(context.Context, int, string, int, int, int, int) ([]lsifstore.Location, int, error) {
 				return nil, 0, nil
 			},
 		},
+		DefinitionsBundleFunc: &LSIFStoreDefinitionsBundleFunc{
+			defaultHook: func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+				return nil, nil
+			},
+		},
 		DiagnosticsFunc: &LSIFStoreDiagnosticsFunc{
 			defaultHook: func(context.Context, int, string, int, int) ([]lsifstore.Diagnostic, int, error) {
 				return nil, 0, nil
@@ -4843,9 +5714,15 @@ func NewMockLSIFStoreFrom(i LSIFStore) *MockLSIFStore {
 		BulkMonikerResultsFunc: &LSIFStoreBulkMonikerResultsFunc{
 			defaultHook: i.BulkMonikerResults,
 		},
+		CallableDefinitionsFunc: &LSIFStoreCallableDefinitionsFunc{
+			defaultHook: i.CallableDefinitions,
+		},
 		DefinitionsFunc: &LSIFStoreDefinitionsFunc{
 			defaultHook: i.Definitions,
 		},
+		DefinitionsBundleFunc: &LSIFStoreDefinitionsBundleFunc{
+			defaultHook: i.DefinitionsBundle,
+		},
 		DiagnosticsFunc: &LSIFStoreDiagnosticsFunc{
 			defaultHook: i.Diagnostics,
 		},
@@ -5010,6 +5887,119 @@ func (c LSIFStoreBulkMonikerResultsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2}
 }
 
+// LSIFStoreCallableDefinitionsFunc describes the behavior when the
+// CallableDefinitions method of the parent MockLSIFStore instance is
+// invoked.
+type LSIFStoreCallableDefinitionsFunc struct {
+	defaultHook func(context.Context, int, string) ([]lsifstore.CallableDefinition, error)
+	hooks       []func(context.Context, int, string) ([]lsifstore.CallableDefinition, error)
+	history     []LSIFStoreCallableDefinitionsFuncCall
+	mutex       sync.Mutex
+}
+
+// CallableDefinitions delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLSIFStore) CallableDefinitions(v0 context.Context, v1 int, v2 string) ([]lsifstore.CallableDefinition, error) {
+	r0, r1 := m.CallableDefinitionsFunc.nextHook()(v0, v1, v2)
+	m.CallableDefinitionsFunc.appendCall(LSIFStoreCallableDefinitionsFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the CallableDefinitions
+// method of the parent MockLSIFStore instance is invoked and the hook queue
+// is empty.
+func (f *LSIFStoreCallableDefinitionsFunc) SetDefaultHook(hook func(context.Context, int, string) ([]lsifstore.CallableDefinition, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// CallableDefinitions method of the parent MockLSIFStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *LSIFStoreCallableDefinitionsFunc) PushHook(hook func(context.Context, int, string) ([]lsifstore.CallableDefinition, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreCallableDefinitionsFunc) SetDefaultReturn(r0 []lsifstore.CallableDefinition, r1 error) {
+	f.SetDefaultHook(func(context.Context, int, string) ([]lsifstore.CallableDefinition, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreCallableDefinitionsFunc) PushReturn(r0 []lsifstore.CallableDefinition, r1 error) {
+	f.PushHook(func(context.Context, int, string) ([]lsifstore.CallableDefinition, error) {
+		return r0, r1
+	})
+}
+
+func (f *LSIFStoreCallableDefinitionsFunc) nextHook() func(context.Context, int, string) ([]lsifstore.CallableDefinition, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreCallableDefinitionsFunc) appendCall(r0 LSIFStoreCallableDefinitionsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreCallableDefinitionsFuncCall
+// objects describing the invocations of this function.
+func (f *LSIFStoreCallableDefinitionsFunc) History() []LSIFStoreCallableDefinitionsFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreCallableDefinitionsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreCallableDefinitionsFuncCall is an object that describes an
+// invocation of method CallableDefinitions on an instance of MockLSIFStore.
+type LSIFStoreCallableDefinitionsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []lsifstore.CallableDefinition
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreCallableDefinitionsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreCallableDefinitionsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // LSIFStoreDefinitionsFunc describes the behavior when the Definitions
 // method of the parent MockLSIFStore instance is invoked.
 type LSIFStoreDefinitionsFunc struct {
@@ -5137,6 +6127,115 @@ func (c LSIFStoreDefinitionsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2}
 }
 
+// LSIFStoreDefinitionsBundleFunc describes the behavior when the
+// DefinitionsBundle method of the parent MockLSIFStore instance is invoked.
+type LSIFStoreDefinitionsBundleFunc struct {
+	defaultHook func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)
+	hooks       []func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)
+	history     []LSIFStoreDefinitionsBundleFuncCall
+	mutex       sync.Mutex
+}
+
+// DefinitionsBundle delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLSIFStore) DefinitionsBundle(v0 context.Context, v1 int) (*semantic.GroupedBundleDataMaps, error) {
+	r0, r1 := m.DefinitionsBundleFunc.nextHook()(v0, v1)
+	m.DefinitionsBundleFunc.appendCall(LSIFStoreDefinitionsBundleFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the DefinitionsBundle
+// method of the parent MockLSIFStore instance is invoked and the hook queue
+// is empty.
+func (f *LSIFStoreDefinitionsBundleFunc) SetDefaultHook(hook func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// DefinitionsBundle method of the parent MockLSIFStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *LSIFStoreDefinitionsBundleFunc) PushHook(hook func(context.Context, int) (*semantic.GroupedBundleDataMaps, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *LSIFStoreDefinitionsBundleFunc) SetDefaultReturn(r0 *semantic.GroupedBundleDataMaps, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *LSIFStoreDefinitionsBundleFunc) PushReturn(r0 *semantic.GroupedBundleDataMaps, r1 error) {
+	f.PushHook(func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+		return r0, r1
+	})
+}
+
+func (f *LSIFStoreDefinitionsBundleFunc) nextHook() func(context.Context, int) (*semantic.GroupedBundleDataMaps, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *LSIFStoreDefinitionsBundleFunc) appendCall(r0 LSIFStoreDefinitionsBundleFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of LSIFStoreDefinitionsBundleFuncCall objects
+// describing the invocations of this function.
+func (f *LSIFStoreDefinitionsBundleFunc) History() []LSIFStoreDefinitionsBundleFuncCall {
+	f.mutex.Lock()
+	history := make([]LSIFStoreDefinitionsBundleFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// LSIFStoreDefinitionsBundleFuncCall is an object that describes an
+// invocation of method DefinitionsBundle on an instance of MockLSIFStore.
+type LSIFStoreDefinitionsBundleFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *semantic.GroupedBundleDataMaps
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c LSIFStoreDefinitionsBundleFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c LSIFStoreDefinitionsBundleFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // LSIFStoreDiagnosticsFunc describes the behavior when the Diagnostics
 // method of the parent MockLSIFStore instance is invoked.
 type LSIFStoreDiagnosticsFunc struct {
@@ -9,6 +9,7 @@ import (
 	graphqlbackend "github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	resolvers "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/codeintel/resolvers"
 	dbstore "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	diff "github.com/sourcegraph/sourcegraph/lib/codeintel/semantic/diff"
 )
 
 // MockResolver is a mock implementation of the Resolver interface (from the
@@ -19,9 +20,16 @@ type MockResolver struct {
 	// CommitGraphFunc is an instance of a mock function object controlling
 	// the behavior of the method CommitGraph.
 	CommitGraphFunc *ResolverCommitGraphFunc
+	// CreateRetentionPolicyFunc is an instance of a mock function object
+	// controlling the behavior of the method CreateRetentionPolicy.
+	CreateRetentionPolicyFunc *ResolverCreateRetentionPolicyFunc
 	// DeleteIndexByIDFunc is an instance of a mock function object
 	// controlling the behavior of the method DeleteIndexByID.
 	DeleteIndexByIDFunc *ResolverDeleteIndexByIDFunc
+	// DeleteRetentionPolicyByIDFunc is an instance of a mock function
+	// object controlling the behavior of the method
+	// DeleteRetentionPolicyByID.
+	DeleteRetentionPolicyByIDFunc *ResolverDeleteRetentionPolicyByIDFunc
 	// DeleteUploadByIDFunc is an instance of a mock function object
 	// controlling the behavior of the method DeleteUploadByID.
 	DeleteUploadByIDFunc *ResolverDeleteUploadByIDFunc
@@ -49,6 +57,12 @@ type MockResolver struct {
 	// QueueAutoIndexJobForRepoFunc is an instance of a mock function object
 	// controlling the behavior of the method QueueAutoIndexJobForRepo.
 	QueueAutoIndexJobForRepoFunc *ResolverQueueAutoIndexJobForRepoFunc
+	// RetentionPoliciesFunc is an instance of a mock function object
+	// controlling the behavior of the method RetentionPolicies.
+	RetentionPoliciesFunc *ResolverRetentionPoliciesFunc
+	// SymbolDiffFunc is an instance of a mock function object controlling
+	// the behavior of the method SymbolDiff.
+	SymbolDiffFunc *ResolverSymbolDiffFunc
 	// UpdateIndexConfigurationByRepositoryIDFunc is an instance of a mock
 	// function object controlling the behavior of the method
 	// UpdateIndexConfigurationByRepositoryID.
@@ -56,6 +70,13 @@ type MockResolver struct {
 	// UploadConnectionResolverFunc is an instance of a mock function object
 	// controlling the behavior of the method UploadConnectionResolver.
 	UploadConnectionResolverFunc *ResolverUploadConnectionResolverFunc
+	// UploadForCommitFunc is an instance of a mock function object
+	// controlling the behavior of the method UploadForCommit.
+	UploadForCommitFunc *ResolverUploadForCommitFunc
+	// UploadsExpiredByRetentionPoliciesFunc is an instance of a mock
+	// function object controlling the behavior of the method
+	// UploadsExpiredByRetentionPolicies.
+	UploadsExpiredByRetentionPoliciesFunc *ResolverUploadsExpiredByRetentionPoliciesFunc
 }
 
 // NewMockResolver creates a new mock of the Resolver interface. All methods
@@ -67,11 +88,21 @@ func NewMockResolver() *MockResolver {
 				return nil, nil
 			},
 		},
+		CreateRetentionPolicyFunc: &ResolverCreateRetentionPolicyFunc{
+			defaultHook: func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+				return dbstore.RetentionPolicy{}, nil
+			},
+		},
 		DeleteIndexByIDFunc: &ResolverDeleteIndexByIDFunc{
 			defaultHook: func(context.Context, int) error {
 				return nil
 			},
 		},
+		DeleteRetentionPolicyByIDFunc: &ResolverDeleteRetentionPolicyByIDFunc{
+			defaultHook: func(context.Context, int) error {
+				return nil
+			},
+		},
 		DeleteUploadByIDFunc: &ResolverDeleteUploadByIDFunc{
 			defaultHook: func(context.Context, int) error {
 				return nil
@@ -117,6 +148,16 @@ func NewMockResolver() *MockResolver {
 				return nil
 			},
 		},
+		RetentionPoliciesFunc: &ResolverRetentionPoliciesFunc{
+			defaultHook: func(context.Context) ([]dbstore.RetentionPolicy, error) {
+				return nil, nil
+			},
+		},
+		SymbolDiffFunc: &ResolverSymbolDiffFunc{
+			defaultHook: func(context.Context, int, int) (diff.SymbolDiff, error) {
+				return diff.SymbolDiff{}, nil
+			},
+		},
 		UpdateIndexConfigurationByRepositoryIDFunc: &ResolverUpdateIndexConfigurationByRepositoryIDFunc{
 			defaultHook: func(context.Context, int, string) error {
 				return nil
@@ -127,6 +168,16 @@ func NewMockResolver() *MockResolver {
 				return nil
 			},
 		},
+		UploadForCommitFunc: &ResolverUploadForCommitFunc{
+			defaultHook: func(context.Context, int, string, string, string) (dbstore.Upload, bool, error) {
+				return dbstore.Upload{}, false, nil
+			},
+		},
+		UploadsExpiredByRetentionPoliciesFunc: &ResolverUploadsExpiredByRetentionPoliciesFunc{
+			defaultHook: func(context.Context, int) ([]dbstore.Upload, error) {
+				return nil, nil
+			},
+		},
 	}
 }
 
@@ -137,9 +188,15 @@ func NewMockResolverFrom(i resolvers.Resolver) *MockResolver {
 		CommitGraphFunc: &ResolverCommitGraphFunc{
 			defaultHook: i.CommitGraph,
 		},
+		CreateRetentionPolicyFunc: &ResolverCreateRetentionPolicyFunc{
+			defaultHook: i.CreateRetentionPolicy,
+		},
 		DeleteIndexByIDFunc: &ResolverDeleteIndexByIDFunc{
 			defaultHook: i.DeleteIndexByID,
 		},
+		DeleteRetentionPolicyByIDFunc: &ResolverDeleteRetentionPolicyByIDFunc{
+			defaultHook: i.DeleteRetentionPolicyByID,
+		},
 		DeleteUploadByIDFunc: &ResolverDeleteUploadByIDFunc{
 			defaultHook: i.DeleteUploadByID,
 		},
@@ -167,12 +224,24 @@ func NewMockResolverFrom(i resolvers.Resolver) *MockResolver {
 		QueueAutoIndexJobForRepoFunc: &ResolverQueueAutoIndexJobForRepoFunc{
 			defaultHook: i.QueueAutoIndexJobForRepo,
 		},
+		RetentionPoliciesFunc: &ResolverRetentionPoliciesFunc{
+			defaultHook: i.RetentionPolicies,
+		},
+		SymbolDiffFunc: &ResolverSymbolDiffFunc{
+			defaultHook: i.SymbolDiff,
+		},
 		UpdateIndexConfigurationByRepositoryIDFunc: &ResolverUpdateIndexConfigurationByRepositoryIDFunc{
 			defaultHook: i.UpdateIndexConfigurationByRepositoryID,
 		},
 		UploadConnectionResolverFunc: &ResolverUploadConnectionResolverFunc{
 			defaultHook: i.UploadConnectionResolver,
 		},
+		UploadForCommitFunc: &ResolverUploadForCommitFunc{
+			defaultHook: i.UploadForCommit,
+		},
+		UploadsExpiredByRetentionPoliciesFunc: &ResolverUploadsExpiredByRetentionPoliciesFunc{
+			defaultHook: i.UploadsExpiredByRetentionPolicies,
+		},
 	}
 }
 
@@ -285,6 +354,117 @@ func (c ResolverCommitGraphFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// ResolverCreateRetentionPolicyFunc describes the behavior when the
+// CreateRetentionPolicy method of the parent MockResolver instance is
+// invoked.
+type ResolverCreateRetentionPolicyFunc struct {
+	defaultHook func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)
+	hooks       []func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)
+	history     []ResolverCreateRetentionPolicyFuncCall
+	mutex       sync.Mutex
+}
+
+// CreateRetentionPolicy delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockResolver) CreateRetentionPolicy(v0 context.Context, v1 dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+	r0, r1 := m.CreateRetentionPolicyFunc.nextHook()(v0, v1)
+	m.CreateRetentionPolicyFunc.appendCall(ResolverCreateRetentionPolicyFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// CreateRetentionPolicy method of the parent MockResolver instance is
+// invoked and the hook queue is empty.
+func (f *ResolverCreateRetentionPolicyFunc) SetDefaultHook(hook func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// CreateRetentionPolicy method of the parent MockResolver instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *ResolverCreateRetentionPolicyFunc) PushHook(hook func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverCreateRetentionPolicyFunc) SetDefaultReturn(r0 dbstore.RetentionPolicy, r1 error) {
+	f.SetDefaultHook(func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverCreateRetentionPolicyFunc) PushReturn(r0 dbstore.RetentionPolicy, r1 error) {
+	f.PushHook(func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+func (f *ResolverCreateRetentionPolicyFunc) nextHook() func(context.Context, dbstore.RetentionPolicy) (dbstore.RetentionPolicy, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverCreateRetentionPolicyFunc) appendCall(r0 ResolverCreateRetentionPolicyFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverCreateRetentionPolicyFuncCall
+// objects describing the invocations of this function.
+func (f *ResolverCreateRetentionPolicyFunc) History() []ResolverCreateRetentionPolicyFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverCreateRetentionPolicyFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverCreateRetentionPolicyFuncCall is an object that describes an
+// invocation of method CreateRetentionPolicy on an instance of
+// MockResolver.
+type ResolverCreateRetentionPolicyFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 dbstore.RetentionPolicy
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 dbstore.RetentionPolicy
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverCreateRetentionPolicyFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverCreateRetentionPolicyFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // ResolverDeleteIndexByIDFunc describes the behavior when the
 // DeleteIndexByID method of the parent MockResolver instance is invoked.
 type ResolverDeleteIndexByIDFunc struct {
@@ -391,6 +571,115 @@ func (c ResolverDeleteIndexByIDFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// ResolverDeleteRetentionPolicyByIDFunc describes the behavior when the
+// DeleteRetentionPolicyByID method of the parent MockResolver instance is
+// invoked.
+type ResolverDeleteRetentionPolicyByIDFunc struct {
+	defaultHook func(context.Context, int) error
+	hooks       []func(context.Context, int) error
+	history     []ResolverDeleteRetentionPolicyByIDFuncCall
+	mutex       sync.Mutex
+}
+
+// DeleteRetentionPolicyByID delegates to the next hook function in the
+// queue and stores the parameter and result values of this invocation.
+func (m *MockResolver) DeleteRetentionPolicyByID(v0 context.Context, v1 int) error {
+	r0 := m.DeleteRetentionPolicyByIDFunc.nextHook()(v0, v1)
+	m.DeleteRetentionPolicyByIDFunc.appendCall(ResolverDeleteRetentionPolicyByIDFuncCall{v0, v1, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the
+// DeleteRetentionPolicyByID method of the parent MockResolver instance is
+// invoked and the hook queue is empty.
+func (f *ResolverDeleteRetentionPolicyByIDFunc) SetDefaultHook(hook func(context.Context, int) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// DeleteRetentionPolicyByID method of the parent MockResolver instance
+// invokes the hook at the front of the queue and discards it. After the
+// queue is empty, the default hook function is invoked for any future
+// action.
+func (f *ResolverDeleteRetentionPolicyByIDFunc) PushHook(hook func(context.Context, int) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverDeleteRetentionPolicyByIDFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverDeleteRetentionPolicyByIDFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int) error {
+		return r0
+	})
+}
+
+func (f *ResolverDeleteRetentionPolicyByIDFunc) nextHook() func(context.Context, int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverDeleteRetentionPolicyByIDFunc) appendCall(r0 ResolverDeleteRetentionPolicyByIDFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverDeleteRetentionPolicyByIDFuncCall
+// objects describing the invocations of this function.
+func (f *ResolverDeleteRetentionPolicyByIDFunc) History() []ResolverDeleteRetentionPolicyByIDFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverDeleteRetentionPolicyByIDFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverDeleteRetentionPolicyByIDFuncCall is an object that describes an
+// invocation of method DeleteRetentionPolicyByID on an instance of
+// MockResolver.
+type ResolverDeleteRetentionPolicyByIDFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverDeleteRetentionPolicyByIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverDeleteRetentionPolicyByIDFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
 // ResolverDeleteUploadByIDFunc describes the behavior when the
 // DeleteUploadByID method of the parent MockResolver instance is invoked.
 type ResolverDeleteUploadByIDFunc struct {
@@ -1386,6 +1675,223 @@ func (c ResolverQueueAutoIndexJobForRepoFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// ResolverRetentionPoliciesFunc describes the behavior when the
+// RetentionPolicies method of the parent MockResolver instance is invoked.
+type ResolverRetentionPoliciesFunc struct {
+	defaultHook func(context.Context) ([]dbstore.RetentionPolicy, error)
+	hooks       []func(context.Context) ([]dbstore.RetentionPolicy, error)
+	history     []ResolverRetentionPoliciesFuncCall
+	mutex       sync.Mutex
+}
+
+// RetentionPolicies delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockResolver) RetentionPolicies(v0 context.Context) ([]dbstore.RetentionPolicy, error) {
+	r0, r1 := m.RetentionPoliciesFunc.nextHook()(v0)
+	m.RetentionPoliciesFunc.appendCall(ResolverRetentionPoliciesFuncCall{v0, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the RetentionPolicies
+// method of the parent MockResolver instance is invoked and the hook queue
+// is empty.
+func (f *ResolverRetentionPoliciesFunc) SetDefaultHook(hook func(context.Context) ([]dbstore.RetentionPolicy, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RetentionPolicies method of the parent MockResolver instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *ResolverRetentionPoliciesFunc) PushHook(hook func(context.Context) ([]dbstore.RetentionPolicy, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverRetentionPoliciesFunc) SetDefaultReturn(r0 []dbstore.RetentionPolicy, r1 error) {
+	f.SetDefaultHook(func(context.Context) ([]dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverRetentionPoliciesFunc) PushReturn(r0 []dbstore.RetentionPolicy, r1 error) {
+	f.PushHook(func(context.Context) ([]dbstore.RetentionPolicy, error) {
+		return r0, r1
+	})
+}
+
+func (f *ResolverRetentionPoliciesFunc) nextHook() func(context.Context) ([]dbstore.RetentionPolicy, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverRetentionPoliciesFunc) appendCall(r0 ResolverRetentionPoliciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverRetentionPoliciesFuncCall objects
+// describing the invocations of this function.
+func (f *ResolverRetentionPoliciesFunc) History() []ResolverRetentionPoliciesFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverRetentionPoliciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverRetentionPoliciesFuncCall is an object that describes an
+// invocation of method RetentionPolicies on an instance of MockResolver.
+type ResolverRetentionPoliciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.RetentionPolicy
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverRetentionPoliciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverRetentionPoliciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// ResolverSymbolDiffFunc describes the behavior when the SymbolDiff method
+// of the parent MockResolver instance is invoked.
+type ResolverSymbolDiffFunc struct {
+	defaultHook func(context.Context, int, int) (diff.SymbolDiff, error)
+	hooks       []func(context.Context, int, int) (diff.SymbolDiff, error)
+	history     []ResolverSymbolDiffFuncCall
+	mutex       sync.Mutex
+}
+
+// SymbolDiff delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockResolver) SymbolDiff(v0 context.Context, v1 int, v2 int) (diff.SymbolDiff, error) {
+	r0, r1 := m.SymbolDiffFunc.nextHook()(v0, v1, v2)
+	m.SymbolDiffFunc.appendCall(ResolverSymbolDiffFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the SymbolDiff method of
+// the parent MockResolver instance is invoked and the hook queue is empty.
+func (f *ResolverSymbolDiffFunc) SetDefaultHook(hook func(context.Context, int, int) (diff.SymbolDiff, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SymbolDiff method of the parent MockResolver instance invokes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *ResolverSymbolDiffFunc) PushHook(hook func(context.Context, int, int) (diff.SymbolDiff, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverSymbolDiffFunc) SetDefaultReturn(r0 diff.SymbolDiff, r1 error) {
+	f.SetDefaultHook(func(context.Context, int, int) (diff.SymbolDiff, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverSymbolDiffFunc) PushReturn(r0 diff.SymbolDiff, r1 error) {
+	f.PushHook(func(context.Context, int, int) (diff.SymbolDiff, error) {
+		return r0, r1
+	})
+}
+
+func (f *ResolverSymbolDiffFunc) nextHook() func(context.Context, int, int) (diff.SymbolDiff, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverSymbolDiffFunc) appendCall(r0 ResolverSymbolDiffFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverSymbolDiffFuncCall objects
+// describing the invocations of this function.
+func (f *ResolverSymbolDiffFunc) History() []ResolverSymbolDiffFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverSymbolDiffFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverSymbolDiffFuncCall is an object that describes an invocation of
+// method SymbolDiff on an instance of MockResolver.
+type ResolverSymbolDiffFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 diff.SymbolDiff
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverSymbolDiffFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverSymbolDiffFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // ResolverUpdateIndexConfigurationByRepositoryIDFunc describes the behavior
 // when the UpdateIndexConfigurationByRepositoryID method of the parent
 // MockResolver instance is invoked.
@@ -1605,3 +2111,237 @@ func (c ResolverUploadConnectionResolverFuncCall) Args() []interface{} {
 func (c ResolverUploadConnectionResolverFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
+
+// ResolverUploadForCommitFunc describes the behavior when the
+// UploadForCommit method of the parent MockResolver instance is invoked.
+type ResolverUploadForCommitFunc struct {
+	defaultHook func(context.Context, int, string, string, string) (dbstore.Upload, bool, error)
+	hooks       []func(context.Context, int, string, string, string) (dbstore.Upload, bool, error)
+	history     []ResolverUploadForCommitFuncCall
+	mutex       sync.Mutex
+}
+
+// UploadForCommit delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockResolver) UploadForCommit(v0 context.Context, v1 int, v2 string, v3 string, v4 string) (dbstore.Upload, bool, error) {
+	r0, r1, r2 := m.UploadForCommitFunc.nextHook()(v0, v1, v2, v3, v4)
+	m.UploadForCommitFunc.appendCall(ResolverUploadForCommitFuncCall{v0, v1, v2, v3, v4, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the UploadForCommit
+// method of the parent MockResolver instance is invoked and the hook queue
+// is empty.
+func (f *ResolverUploadForCommitFunc) SetDefaultHook(hook func(context.Context, int, string, string, string) (dbstore.Upload, bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// UploadForCommit method of the parent MockResolver instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *ResolverUploadForCommitFunc) PushHook(hook func(context.Context, int, string, string, string) (dbstore.Upload, bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverUploadForCommitFunc) SetDefaultReturn(r0 dbstore.Upload, r1 bool, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, string, string, string) (dbstore.Upload, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverUploadForCommitFunc) PushReturn(r0 dbstore.Upload, r1 bool, r2 error) {
+	f.PushHook(func(context.Context, int, string, string, string) (dbstore.Upload, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *ResolverUploadForCommitFunc) nextHook() func(context.Context, int, string, string, string) (dbstore.Upload, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverUploadForCommitFunc) appendCall(r0 ResolverUploadForCommitFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ResolverUploadForCommitFuncCall objects
+// describing the invocations of this function.
+func (f *ResolverUploadForCommitFunc) History() []ResolverUploadForCommitFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverUploadForCommitFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverUploadForCommitFuncCall is an object that describes an invocation
+// of method UploadForCommit on an instance of MockResolver.
+type ResolverUploadForCommitFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 dbstore.Upload
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 bool
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverUploadForCommitFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverUploadForCommitFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// ResolverUploadsExpiredByRetentionPoliciesFunc describes the behavior when
+// the UploadsExpiredByRetentionPolicies method of the parent MockResolver
+// instance is invoked.
+type ResolverUploadsExpiredByRetentionPoliciesFunc struct {
+	defaultHook func(context.Context, int) ([]dbstore.Upload, error)
+	hooks       []func(context.Context, int) ([]dbstore.Upload, error)
+	history     []ResolverUploadsExpiredByRetentionPoliciesFuncCall
+	mutex       sync.Mutex
+}
+
+// UploadsExpiredByRetentionPolicies delegates to the next hook function in
+// the queue and stores the parameter and result values of this invocation.
+func (m *MockResolver) UploadsExpiredByRetentionPolicies(v0 context.Context, v1 int) ([]dbstore.Upload, error) {
+	r0, r1 := m.UploadsExpiredByRetentionPoliciesFunc.nextHook()(v0, v1)
+	m.UploadsExpiredByRetentionPoliciesFunc.appendCall(ResolverUploadsExpiredByRetentionPoliciesFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// UploadsExpiredByRetentionPolicies method of the parent MockResolver
+// instance is invoked and the hook queue is empty.
+func (f *ResolverUploadsExpiredByRetentionPoliciesFunc) SetDefaultHook(hook func(context.Context, int) ([]dbstore.Upload, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// UploadsExpiredByRetentionPolicies method of the parent MockResolver
+// instance invokes the hook at the front of the queue and discards it.
+// After the queue is empty, the default hook function is invoked for any
+// future action.
+func (f *ResolverUploadsExpiredByRetentionPoliciesFunc) PushHook(hook func(context.Context, int) ([]dbstore.Upload, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *ResolverUploadsExpiredByRetentionPoliciesFunc) SetDefaultReturn(r0 []dbstore.Upload, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) ([]dbstore.Upload, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *ResolverUploadsExpiredByRetentionPoliciesFunc) PushReturn(r0 []dbstore.Upload, r1 error) {
+	f.PushHook(func(context.Context, int) ([]dbstore.Upload, error) {
+		return r0, r1
+	})
+}
+
+func (f *ResolverUploadsExpiredByRetentionPoliciesFunc) nextHook() func(context.Context, int) ([]dbstore.Upload, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ResolverUploadsExpiredByRetentionPoliciesFunc) appendCall(r0 ResolverUploadsExpiredByRetentionPoliciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// ResolverUploadsExpiredByRetentionPoliciesFuncCall objects describing the
+// invocations of this function.
+func (f *ResolverUploadsExpiredByRetentionPoliciesFunc) History() []ResolverUploadsExpiredByRetentionPoliciesFuncCall {
+	f.mutex.Lock()
+	history := make([]ResolverUploadsExpiredByRetentionPoliciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ResolverUploadsExpiredByRetentionPoliciesFuncCall is an object that
+// describes an invocation of method UploadsExpiredByRetentionPolicies on an
+// instance of MockResolver.
+type ResolverUploadsExpiredByRetentionPoliciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []dbstore.Upload
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ResolverUploadsExpiredByRetentionPoliciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ResolverUploadsExpiredByRetentionPoliciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
@@ -26,6 +26,9 @@ type operations struct {
 	documentationIDsToPathIDs *observation.Operation
 	documentationReferences   *observation.Operation
 	documentation             *observation.Operation
+	symbolDiff                *observation.Operation
+	incomingCalls             *observation.Operation
+	outgoingCalls             *observation.Operation
 
 	findClosestDumps *observation.Operation
 }
@@ -67,6 +70,9 @@ func newOperations(observationContext *observation.Context) *operations {
 		documentationIDsToPathIDs: op("DocumentationIDsToPathIDs"),
 		documentationReferences:   op("DocumentationReferences"),
 		documentation:             op("Documentation"),
+		symbolDiff:                op("SymbolDiff"),
+		incomingCalls:             op("IncomingCalls"),
+		outgoingCalls:             op("OutgoingCalls"),
 
 		findClosestDumps: subOp("findClosestDumps"),
 	}
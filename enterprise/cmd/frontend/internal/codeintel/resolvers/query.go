@@ -37,6 +37,27 @@ type AdjustedCodeIntelligenceRange struct {
 	DocumentationPathID string
 }
 
+// AdjustedCallHierarchyItem is the definition site of a callable symbol along with its hover text.
+// The location has been adjusted to fit the target (originally requested) commit.
+type AdjustedCallHierarchyItem struct {
+	Location  AdjustedLocation
+	HoverText string
+}
+
+// AdjustedIncomingCall pairs a callable symbol with the locations within its body that call the
+// symbol of a call hierarchy request.
+type AdjustedIncomingCall struct {
+	From       AdjustedCallHierarchyItem
+	FromRanges []AdjustedLocation
+}
+
+// AdjustedOutgoingCall pairs a callable symbol with the locations within the body of the symbol of
+// a call hierarchy request that call it.
+type AdjustedOutgoingCall struct {
+	To         AdjustedCallHierarchyItem
+	FromRanges []AdjustedLocation
+}
+
 func (a *AdjustedCodeIntelligenceRange) ToDocumentation() *Documentation {
 	if a.DocumentationPathID == "" {
 		return nil
@@ -59,6 +80,8 @@ type QueryResolver interface {
 	Documentation(ctx context.Context, line int, character int) ([]*Documentation, error)
 	DocumentationDefinitions(ctx context.Context, pathID string) ([]AdjustedLocation, error)
 	DocumentationReferences(ctx context.Context, pathID string, limit int, rawCursor string) ([]AdjustedLocation, string, error)
+	IncomingCalls(ctx context.Context, line, character, limit int, rawCursor string) ([]AdjustedIncomingCall, string, error)
+	OutgoingCalls(ctx context.Context, line, character int) ([]AdjustedOutgoingCall, error)
 }
 
 type Documentation struct {
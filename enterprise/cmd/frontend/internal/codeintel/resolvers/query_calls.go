@@ -0,0 +1,292 @@
+package resolvers
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+const slowIncomingCallsRequestThreshold = time.Second
+const slowOutgoingCallsRequestThreshold = time.Second
+
+// IncomingCalls returns the callable symbols whose bodies reference the symbol at the given position.
+// Reference locations are paginated exactly as in References, then grouped by the callable definition
+// enclosing each location. References which do not occur within a callable definition (e.g. imports
+// or package-level declarations) are not part of the result. A caller whose references span multiple
+// pages may be returned on each of those pages.
+func (r *queryResolver) IncomingCalls(ctx context.Context, line, character, limit int, rawCursor string) (_ []AdjustedIncomingCall, _ string, err error) {
+	ctx, traceLog, endObservation := observeResolver(ctx, &err, "IncomingCalls", r.operations.incomingCalls, slowIncomingCallsRequestThreshold, observation.Args{
+		LogFields: []log.Field{
+			log.Int("repositoryID", r.repositoryID),
+			log.String("commit", r.commit),
+			log.String("path", r.path),
+			log.Int("numUploads", len(r.uploads)),
+			log.String("uploads", uploadIDsToString(r.uploads)),
+			log.Int("line", line),
+			log.Int("character", character),
+		},
+	})
+	defer endObservation()
+
+	locations, uploadsByID, nextCursor, err := r.pageReferenceLocations(ctx, line, character, limit, rawCursor, traceLog)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Group each reference location by its enclosing callable definition. Each document is
+	// likely to contain several references, so we only fetch its callable definitions once.
+
+	type documentKey struct {
+		dumpID int
+		path   string
+	}
+	type callerKey struct {
+		documentKey
+		rn lsifstore.Range
+	}
+
+	var (
+		callers         []lsifstore.Location
+		callerHoverText []string
+		fromRanges      [][]lsifstore.Location
+		callerIndexes   = map[callerKey]int{}
+		definitions     = map[documentKey][]lsifstore.CallableDefinition{}
+	)
+
+	for _, location := range locations {
+		document := documentKey{dumpID: location.DumpID, path: location.Path}
+
+		callableDefinitions, ok := definitions[document]
+		if !ok {
+			callableDefinitions, err = r.lsifStore.CallableDefinitions(ctx, location.DumpID, location.Path)
+			if err != nil {
+				return nil, "", errors.Wrap(err, "lsifStore.CallableDefinitions")
+			}
+			definitions[document] = callableDefinitions
+		}
+
+		definition, ok := enclosingCallableDefinition(callableDefinitions, location.Range.Start)
+		if !ok || definition.Range == location.Range {
+			// Not within a callable body, or the reference is the definition site itself
+			continue
+		}
+
+		key := callerKey{documentKey: document, rn: definition.Range}
+		index, ok := callerIndexes[key]
+		if !ok {
+			index = len(callers)
+			callerIndexes[key] = index
+			callers = append(callers, lsifstore.Location{DumpID: location.DumpID, Path: location.Path, Range: definition.Range})
+			callerHoverText = append(callerHoverText, definition.HoverText)
+			fromRanges = append(fromRanges, nil)
+		}
+		fromRanges[index] = append(fromRanges[index], location)
+	}
+	traceLog(log.Int("numCallers", len(callers)))
+
+	// Adjust the locations back to the appropriate range in the target commits. This adjusts
+	// locations within the repository the user is browsing so that it appears all calls are
+	// occurring at the same commit they are looking at.
+
+	adjustedCallers, err := r.adjustLocations(ctx, uploadsByID, callers)
+	if err != nil {
+		return nil, "", err
+	}
+
+	calls := make([]AdjustedIncomingCall, 0, len(callers))
+	for i := range adjustedCallers {
+		adjustedFromRanges, err := r.adjustLocations(ctx, uploadsByID, fromRanges[i])
+		if err != nil {
+			return nil, "", err
+		}
+
+		calls = append(calls, AdjustedIncomingCall{
+			From: AdjustedCallHierarchyItem{
+				Location:  adjustedCallers[i],
+				HoverText: callerHoverText[i],
+			},
+			FromRanges: adjustedFromRanges,
+		})
+	}
+
+	return calls, nextCursor, nil
+}
+
+// maximumOutgoingCallMonikerSearches is the maximum number of call sites within a single callable body
+// whose definitions are resolved via moniker search. Call sites exceeding this limit are resolved only
+// when their definition is reachable by an LSIF graph traversal.
+const maximumOutgoingCallMonikerSearches = 50
+
+// OutgoingCalls returns the callable symbols referenced from the body of the callable symbol defined at
+// the given position. If the given position is not the definition site of a callable symbol, no calls
+// are returned. Callees defined in other indexes are resolved via moniker search.
+func (r *queryResolver) OutgoingCalls(ctx context.Context, line, character int) (_ []AdjustedOutgoingCall, err error) {
+	ctx, traceLog, endObservation := observeResolver(ctx, &err, "OutgoingCalls", r.operations.outgoingCalls, slowOutgoingCallsRequestThreshold, observation.Args{
+		LogFields: []log.Field{
+			log.Int("repositoryID", r.repositoryID),
+			log.String("commit", r.commit),
+			log.String("path", r.path),
+			log.Int("numUploads", len(r.uploads)),
+			log.String("uploads", uploadIDsToString(r.uploads)),
+			log.Int("line", line),
+			log.Int("character", character),
+		},
+	})
+	defer endObservation()
+
+	adjustedUploads, err := r.adjustUploads(ctx, line, character)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range adjustedUploads {
+		traceLog(log.Int("uploadID", adjustedUploads[i].Upload.ID))
+
+		callableDefinitions, err := r.lsifStore.CallableDefinitions(ctx, adjustedUploads[i].Upload.ID, adjustedUploads[i].AdjustedPathInBundle)
+		if err != nil {
+			return nil, errors.Wrap(err, "lsifStore.CallableDefinitions")
+		}
+
+		for _, definition := range callableDefinitions {
+			if rangeContainsPosition(definition.Range, adjustedUploads[i].AdjustedPosition) {
+				// If we have a callable definition, we won't find a better one and can exit early
+				return r.outgoingCalls(ctx, adjustedUploads[i], definition, traceLog)
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// outgoingCalls returns the callable symbols referenced within the extent of the given callable definition.
+func (r *queryResolver) outgoingCalls(ctx context.Context, upload adjustedUpload, definition lsifstore.CallableDefinition, traceLog observation.TraceLogger) ([]AdjustedOutgoingCall, error) {
+	ranges, err := r.lsifStore.Ranges(
+		ctx,
+		upload.Upload.ID,
+		upload.AdjustedPathInBundle,
+		definition.Extent.Start.Line,
+		definition.Extent.End.Line+1,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "lsifStore.Ranges")
+	}
+
+	uploadsByID := map[int]dbstore.Dump{
+		upload.Upload.ID: upload.Upload,
+	}
+
+	var (
+		callees          []lsifstore.Location
+		calleeHoverText  []string
+		fromRanges       [][]lsifstore.Location
+		calleeIndexes    = map[lsifstore.Location]int{}
+		monikerSearches  = 0
+		callSiteLocation = func(rn lsifstore.Range) lsifstore.Location {
+			return lsifstore.Location{DumpID: upload.Upload.ID, Path: upload.AdjustedPathInBundle, Range: rn}
+		}
+	)
+
+	for _, rn := range ranges {
+		if !definition.ExtentContains(rn.Range.Start) || !lsifstore.IsCallableHoverText(rn.HoverText) {
+			continue
+		}
+		if isDefinitionSite(rn, callSiteLocation(rn.Range)) {
+			// Skip the definition itself as well as nested callable definitions
+			continue
+		}
+
+		locations := rn.Definitions
+		if len(locations) == 0 {
+			if monikerSearches >= maximumOutgoingCallMonikerSearches {
+				continue
+			}
+			monikerSearches++
+
+			// The callee is not defined within this index; search for its definition via the
+			// import monikers attached to the call site.
+			callSite := adjustedUpload{
+				Upload:               upload.Upload,
+				AdjustedPath:         upload.AdjustedPath,
+				AdjustedPosition:     rn.Range.Start,
+				AdjustedPathInBundle: upload.AdjustedPathInBundle,
+			}
+
+			var definitionUploadsByID map[int]dbstore.Dump
+			locations, definitionUploadsByID, err = r.definitionLocations(ctx, []adjustedUpload{callSite}, traceLog)
+			if err != nil {
+				return nil, err
+			}
+			for id, dump := range definitionUploadsByID {
+				uploadsByID[id] = dump
+			}
+		}
+
+		for _, location := range locations {
+			index, ok := calleeIndexes[location]
+			if !ok {
+				index = len(callees)
+				calleeIndexes[location] = index
+				callees = append(callees, location)
+				calleeHoverText = append(calleeHoverText, rn.HoverText)
+				fromRanges = append(fromRanges, nil)
+			}
+			fromRanges[index] = append(fromRanges[index], callSiteLocation(rn.Range))
+		}
+	}
+	traceLog(
+		log.Int("numCallees", len(callees)),
+		log.Int("numMonikerSearches", monikerSearches),
+	)
+
+	adjustedCallees, err := r.adjustLocations(ctx, uploadsByID, callees)
+	if err != nil {
+		return nil, err
+	}
+
+	calls := make([]AdjustedOutgoingCall, 0, len(callees))
+	for i := range adjustedCallees {
+		adjustedFromRanges, err := r.adjustLocations(ctx, uploadsByID, fromRanges[i])
+		if err != nil {
+			return nil, err
+		}
+
+		calls = append(calls, AdjustedOutgoingCall{
+			To: AdjustedCallHierarchyItem{
+				Location:  adjustedCallees[i],
+				HoverText: calleeHoverText[i],
+			},
+			FromRanges: adjustedFromRanges,
+		})
+	}
+
+	return calls, nil
+}
+
+// enclosingCallableDefinition returns the callable definition whose extent contains the given position.
+// The given definitions are expected to be ordered by position, as returned by the LSIF store.
+func enclosingCallableDefinition(definitions []lsifstore.CallableDefinition, position lsifstore.Position) (lsifstore.CallableDefinition, bool) {
+	for _, definition := range definitions {
+		if definition.ExtentContains(position) {
+			return definition, true
+		}
+	}
+
+	return lsifstore.CallableDefinition{}, false
+}
+
+// isDefinitionSite returns true if the given range is one of its own definitions.
+func isDefinitionSite(rn lsifstore.CodeIntelligenceRange, location lsifstore.Location) bool {
+	for _, definition := range rn.Definitions {
+		if definition == location {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,254 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+func TestIncomingCalls(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := noopPositionAdjuster()
+
+	// Empty result set (prevents nil pointer as scanner is always non-nil)
+	mockDBStore.ReferenceIDsAndFiltersFunc.PushReturn(dbstore.PackageReferenceScannerFromSlice(), 0, nil)
+
+	callerA1 := newCallableDefinition(callRange(10, 5, 10, 10), 10, 5, 20, 0, "```go\nfunc A1()\n```")
+	callerA2 := newCallableDefinition(callRange(20, 5, 20, 10), 20, 5, 30, 0, "```go\nfunc A2()\n```")
+	callerB1 := newCallableDefinition(callRange(5, 5, 5, 10), 5, 5, 40, 0, "```go\nfunc B1()\n```")
+	mockLSIFStore.CallableDefinitionsFunc.SetDefaultHook(func(ctx context.Context, bundleID int, path string) ([]lsifstore.CallableDefinition, error) {
+		switch path {
+		case "a.go":
+			return []lsifstore.CallableDefinition{callerA1, callerA2}, nil
+		case "b.go":
+			return []lsifstore.CallableDefinition{callerB1}, nil
+		}
+		return nil, nil
+	})
+
+	locations := []lsifstore.Location{
+		{DumpID: 50, Path: "a.go", Range: callRange(2, 1, 2, 4)},    // outside of any callable
+		{DumpID: 50, Path: "a.go", Range: callRange(10, 5, 10, 10)}, // definition site
+		{DumpID: 50, Path: "a.go", Range: callRange(12, 1, 12, 4)},
+		{DumpID: 50, Path: "a.go", Range: callRange(15, 1, 15, 4)},
+		{DumpID: 50, Path: "a.go", Range: callRange(25, 1, 25, 4)},
+		{DumpID: 50, Path: "b.go", Range: callRange(35, 1, 35, 4)},
+	}
+	mockLSIFStore.ReferencesFunc.PushReturn(locations, len(locations), nil)
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"s1/main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+	calls, _, err := resolver.IncomingCalls(context.Background(), 10, 20, 50, "")
+	if err != nil {
+		t.Fatalf("unexpected error querying incoming calls: %s", err)
+	}
+
+	adjusted := func(path string, rn lsifstore.Range) AdjustedLocation {
+		return AdjustedLocation{Dump: uploads[0], Path: "sub1/" + path, AdjustedCommit: "deadbeef", AdjustedRange: rn}
+	}
+
+	expectedCalls := []AdjustedIncomingCall{
+		{
+			From: AdjustedCallHierarchyItem{Location: adjusted("a.go", callerA1.Range), HoverText: callerA1.HoverText},
+			FromRanges: []AdjustedLocation{
+				adjusted("a.go", callRange(12, 1, 12, 4)),
+				adjusted("a.go", callRange(15, 1, 15, 4)),
+			},
+		},
+		{
+			From:       AdjustedCallHierarchyItem{Location: adjusted("a.go", callerA2.Range), HoverText: callerA2.HoverText},
+			FromRanges: []AdjustedLocation{adjusted("a.go", callRange(25, 1, 25, 4))},
+		},
+		{
+			From:       AdjustedCallHierarchyItem{Location: adjusted("b.go", callerB1.Range), HoverText: callerB1.HoverText},
+			FromRanges: []AdjustedLocation{adjusted("b.go", callRange(35, 1, 35, 4))},
+		},
+	}
+	if diff := cmp.Diff(expectedCalls, calls); diff != "" {
+		t.Errorf("unexpected calls (-want +got):\n%s", diff)
+	}
+
+	if callCount := len(mockLSIFStore.CallableDefinitionsFunc.History()); callCount != 2 {
+		t.Errorf("unexpected number of CallableDefinitions calls. want=%d have=%d", 2, callCount)
+	}
+}
+
+func TestOutgoingCalls(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := noopPositionAdjuster()
+
+	definition := newCallableDefinition(callRange(10, 5, 10, 10), 10, 5, 20, 0, "```go\nfunc Target()\n```")
+	mockLSIFStore.CallableDefinitionsFunc.PushReturn([]lsifstore.CallableDefinition{definition}, nil)
+
+	localCallee := lsifstore.Location{DumpID: 50, Path: "b.go", Range: callRange(3, 5, 3, 10)}
+	ranges := []lsifstore.CodeIntelligenceRange{
+		{
+			// definition site of the target
+			Range:       definition.Range,
+			Definitions: []lsifstore.Location{{DumpID: 50, Path: "deadbeef", Range: definition.Range}},
+			HoverText:   definition.HoverText,
+		},
+		{
+			// call with a local definition
+			Range:       callRange(12, 1, 12, 6),
+			Definitions: []lsifstore.Location{localCallee},
+			HoverText:   "```go\nfunc Local()\n```",
+		},
+		{
+			// non-callable symbol
+			Range:       callRange(13, 1, 13, 2),
+			Definitions: []lsifstore.Location{{DumpID: 50, Path: "deadbeef", Range: callRange(11, 1, 11, 2)}},
+			HoverText:   "```go\nvar x int\n```",
+		},
+		{
+			// second call with a local definition
+			Range:       callRange(14, 1, 14, 6),
+			Definitions: []lsifstore.Location{localCallee},
+			HoverText:   "```go\nfunc Local()\n```",
+		},
+		{
+			// call with a remote definition
+			Range:     callRange(15, 1, 15, 8),
+			HoverText: "```go\nfunc Remote()\n```",
+		},
+		{
+			// call outside of the target's extent
+			Range:       callRange(22, 1, 22, 6),
+			Definitions: []lsifstore.Location{localCallee},
+			HoverText:   "```go\nfunc Local()\n```",
+		},
+	}
+	mockLSIFStore.RangesFunc.PushReturn(ranges, nil)
+
+	remoteUploads := []dbstore.Dump{{ID: 150, Commit: "deadbeef1", Root: "remote/"}}
+	mockDBStore.DefinitionDumpsFunc.PushReturn(remoteUploads, nil)
+	mockGitserverClient.CommitExistsFunc.SetDefaultReturn(true, nil)
+
+	moniker := semantic.MonikerData{Kind: "import", Scheme: "gomod", Identifier: "remote:Remote", PackageInformationID: "51"}
+	mockLSIFStore.MonikersByPositionFunc.PushReturn([][]semantic.MonikerData{{moniker}}, nil)
+	mockLSIFStore.PackageInformationFunc.PushReturn(semantic.PackageInformationData{Name: "remote", Version: "v1.0.0"}, true, nil)
+
+	remoteCallee := lsifstore.Location{DumpID: 150, Path: "c.go", Range: callRange(7, 5, 7, 11)}
+	mockLSIFStore.BulkMonikerResultsFunc.PushReturn([]lsifstore.Location{remoteCallee}, 1, nil)
+
+	uploads := []dbstore.Dump{
+		{ID: 50, Commit: "deadbeef", Root: "sub1/"},
+	}
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"s1/main.go",
+		uploads,
+		newOperations(&observation.TestContext),
+	)
+	calls, err := resolver.OutgoingCalls(context.Background(), 10, 6)
+	if err != nil {
+		t.Fatalf("unexpected error querying outgoing calls: %s", err)
+	}
+
+	callSite := func(rn lsifstore.Range) AdjustedLocation {
+		return AdjustedLocation{Dump: uploads[0], Path: "sub1/deadbeef", AdjustedCommit: "deadbeef", AdjustedRange: rn}
+	}
+
+	expectedCalls := []AdjustedOutgoingCall{
+		{
+			To: AdjustedCallHierarchyItem{
+				Location:  AdjustedLocation{Dump: uploads[0], Path: "sub1/b.go", AdjustedCommit: "deadbeef", AdjustedRange: localCallee.Range},
+				HoverText: "```go\nfunc Local()\n```",
+			},
+			FromRanges: []AdjustedLocation{
+				callSite(callRange(12, 1, 12, 6)),
+				callSite(callRange(14, 1, 14, 6)),
+			},
+		},
+		{
+			To: AdjustedCallHierarchyItem{
+				Location:  AdjustedLocation{Dump: remoteUploads[0], Path: "remote/c.go", AdjustedCommit: "deadbeef1", AdjustedRange: remoteCallee.Range},
+				HoverText: "```go\nfunc Remote()\n```",
+			},
+			FromRanges: []AdjustedLocation{callSite(callRange(15, 1, 15, 8))},
+		},
+	}
+	if diff := cmp.Diff(expectedCalls, calls); diff != "" {
+		t.Errorf("unexpected calls (-want +got):\n%s", diff)
+	}
+
+	if history := mockLSIFStore.MonikersByPositionFunc.History(); len(history) != 1 {
+		t.Errorf("unexpected number of moniker searches. want=%d have=%d", 1, len(history))
+	} else if history[0].Arg3 != 15 || history[0].Arg4 != 1 {
+		t.Errorf("unexpected moniker search position. want=%d:%d have=%d:%d", 15, 1, history[0].Arg3, history[0].Arg4)
+	}
+}
+
+func TestOutgoingCallsNotCallable(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+	mockPositionAdjuster := noopPositionAdjuster()
+
+	definition := newCallableDefinition(callRange(10, 5, 10, 10), 10, 5, 20, 0, "```go\nfunc Target()\n```")
+	mockLSIFStore.CallableDefinitionsFunc.SetDefaultReturn([]lsifstore.CallableDefinition{definition}, nil)
+
+	resolver := newQueryResolver(
+		mockDBStore,
+		mockLSIFStore,
+		newCachedCommitChecker(mockGitserverClient),
+		mockPositionAdjuster,
+		42,
+		"deadbeef",
+		"s1/main.go",
+		[]dbstore.Dump{{ID: 50, Commit: "deadbeef", Root: "sub1/"}},
+		newOperations(&observation.TestContext),
+	)
+	calls, err := resolver.OutgoingCalls(context.Background(), 15, 1)
+	if err != nil {
+		t.Fatalf("unexpected error querying outgoing calls: %s", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("unexpected calls: %v", calls)
+	}
+	if callCount := len(mockLSIFStore.RangesFunc.History()); callCount != 0 {
+		t.Errorf("unexpected number of Ranges calls. want=%d have=%d", 0, callCount)
+	}
+}
+
+func callRange(startLine, startCharacter, endLine, endCharacter int) lsifstore.Range {
+	return lsifstore.Range{
+		Start: lsifstore.Position{Line: startLine, Character: startCharacter},
+		End:   lsifstore.Position{Line: endLine, Character: endCharacter},
+	}
+}
+
+func newCallableDefinition(rn lsifstore.Range, extentStartLine, extentStartCharacter, extentEndLine, extentEndCharacter int, hoverText string) lsifstore.CallableDefinition {
+	return lsifstore.CallableDefinition{
+		Range:     rn,
+		Extent:    callRange(extentStartLine, extentStartCharacter, extentEndLine, extentEndCharacter),
+		HoverText: hoverText,
+	}
+}
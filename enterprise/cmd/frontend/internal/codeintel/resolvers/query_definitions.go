@@ -8,6 +8,7 @@ import (
 	"github.com/opentracing/opentracing-go/log"
 
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/lsifstore"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 )
 
@@ -39,6 +40,27 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 		return nil, err
 	}
 
+	locations, uploadsByID, err := r.definitionLocations(ctx, adjustedUploads, traceLog)
+	if err != nil {
+		return nil, err
+	}
+
+	// Adjust the locations back to the appropriate range in the target commits. This adjusts
+	// locations within the repository the user is browsing so that it appears all definitions
+	// are occurring at the same commit they are looking at.
+
+	adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, locations)
+	if err != nil {
+		return nil, err
+	}
+	traceLog(log.Int("numAdjustedLocations", len(adjustedLocations)))
+
+	return adjustedLocations, nil
+}
+
+// definitionLocations returns the (unadjusted) locations that define the symbol at the adjusted position
+// of each of the given uploads. The returned map contains the upload records of each returned location.
+func (r *queryResolver) definitionLocations(ctx context.Context, adjustedUploads []adjustedUpload, traceLog observation.TraceLogger) ([]lsifstore.Location, map[int]dbstore.Dump, error) {
 	// Gather the "local" reference locations that are reachable via a referenceResult vertex.
 	// If the definition exists within the index, it should be reachable via an LSIF graph
 	// traversal and should not require an additional moniker search in the same index.
@@ -56,7 +78,7 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 			0,
 		)
 		if err != nil {
-			return nil, errors.Wrap(err, "lsifStore.Definitions")
+			return nil, nil, errors.Wrap(err, "lsifStore.Definitions")
 		}
 		if len(locations) > 0 {
 			uploadsByID := map[int]dbstore.Dump{
@@ -64,14 +86,14 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 			}
 
 			// If we have a local definition, we won't find a better one and can exit early
-			return r.adjustLocations(ctx, uploadsByID, locations)
+			return locations, uploadsByID, nil
 		}
 	}
 
 	// Gather all import monikers attached to the ranges enclosing the requested position
 	orderedMonikers, err := r.orderedMonikers(ctx, adjustedUploads, "import")
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	traceLog(
 		log.Int("numMonikers", len(orderedMonikers)),
@@ -83,7 +105,7 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 	// any of the indexes we have already performed an LSIF graph traversal in above.
 	uploads, err := r.definitionUploads(ctx, orderedMonikers)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	traceLog(
 		log.Int("numDefinitionUploads", len(uploads)),
@@ -93,24 +115,14 @@ func (r *queryResolver) Definitions(ctx context.Context, line, character int) (_
 	// Perform the moniker search
 	locations, _, err := r.monikerLocations(ctx, uploads, orderedMonikers, "definitions", DefinitionsLimit, 0)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	traceLog(log.Int("numLocations", len(locations)))
 
-	// Adjust the locations back to the appropriate range in the target commits. This adjusts
-	// locations within the repository the user is browsing so that it appears all definitions
-	// are occurring at the same commit they are looking at.
-
 	uploadsByID := make(map[int]dbstore.Dump, len(uploads))
 	for i := range uploads {
 		uploadsByID[uploads[i].ID] = uploads[i]
 	}
 
-	adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, locations)
-	if err != nil {
-		return nil, err
-	}
-	traceLog(log.Int("numAdjustedLocations", len(adjustedLocations)))
-
-	return adjustedLocations, nil
+	return locations, uploadsByID, nil
 }
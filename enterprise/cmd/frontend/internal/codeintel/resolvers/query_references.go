@@ -35,6 +35,28 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	})
 	defer endObservation()
 
+	locations, uploadsByID, nextCursor, err := r.pageReferenceLocations(ctx, line, character, limit, rawCursor, traceLog)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Adjust the locations back to the appropriate range in the target commits. This adjusts
+	// locations within the repository the user is browsing so that it appears all references
+	// are occurring at the same commit they are looking at.
+
+	adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, locations)
+	if err != nil {
+		return nil, "", err
+	}
+	traceLog(log.Int("numAdjustedLocations", len(adjustedLocations)))
+
+	return adjustedLocations, nextCursor, nil
+}
+
+// pageReferenceLocations returns a single page of the (unadjusted) locations that reference the symbol
+// at the given position, along with the encoded cursor of the next page, which is empty if there are no
+// more results. The returned map contains the upload records of each returned location.
+func (r *queryResolver) pageReferenceLocations(ctx context.Context, line, character, limit int, rawCursor string, traceLog observation.TraceLogger) ([]lsifstore.Location, map[int]dbstore.Dump, string, error) {
 	// Maintain a map from identifers to hydrated upload records from the database. We use
 	// this map as a quick lookup when constructing the resulting location set. Any additional
 	// upload records pulled back from the database while processing this page will be added
@@ -50,7 +72,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	// cursor used to fetch the subsequent page of results in this result set.
 	cursor, err := decodeCursor(rawCursor)
 	if err != nil {
-		return nil, "", errors.Wrap(err, fmt.Sprintf("invalid cursor: %q", rawCursor))
+		return nil, nil, "", errors.Wrap(err, fmt.Sprintf("invalid cursor: %q", rawCursor))
 	}
 
 	// Adjust the path and position for each visible upload based on its git difference to
@@ -59,7 +81,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 
 	adjustedUploads, err := r.adjustedUploadsFromCursor(ctx, line, character, uploadsByID, &cursor)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 
 	// Gather allmonikers attached to the ranges enclosing the requested position. This data
@@ -68,7 +90,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 
 	orderedMonikers, err := r.orderedMonikersFromCursor(ctx, adjustedUploads, &cursor)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	traceLog(
 		log.Int("numMonikers", len(orderedMonikers)),
@@ -81,7 +103,7 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 
 	definitionUploadIDs, definitionUploads, err := r.definitionUploadIDsFromCursor(ctx, adjustedUploads, orderedMonikers, &cursor)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	traceLog(
 		log.Int("numDefinitionUploads", len(definitionUploadIDs)),
@@ -98,26 +120,16 @@ func (r *queryResolver) References(ctx context.Context, line, character, limit i
 	// Query a single page of location results
 	locations, hasMore, err := r.pageReferences(ctx, adjustedUploads, orderedMonikers, definitionUploadIDs, uploadsByID, &cursor, limit)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, "", err
 	}
 	traceLog(log.Int("numLocations", len(locations)))
 
-	// Adjust the locations back to the appropriate range in the target commits. This adjusts
-	// locations within the repository the user is browsing so that it appears all references
-	// are occurring at the same commit they are looking at.
-
-	adjustedLocations, err := r.adjustLocations(ctx, uploadsByID, locations)
-	if err != nil {
-		return nil, "", err
-	}
-	traceLog(log.Int("numAdjustedLocations", len(adjustedLocations)))
-
 	nextCursor := ""
 	if hasMore {
 		nextCursor = encodeCursor(cursor)
 	}
 
-	return adjustedLocations, nextCursor, nil
+	return locations, uploadsByID, nextCursor, nil
 }
 
 // ErrConcurrentModification occurs when a page of a references request cannot be resolved as
@@ -12,6 +12,7 @@ import (
 	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
 	"github.com/sourcegraph/sourcegraph/lib/codeintel/autoindex/config"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic/diff"
 )
 
 // Resolver is the main interface to code intel-related operations exposed to the GraphQL API.
@@ -33,6 +34,12 @@ type Resolver interface {
 	CommitGraph(ctx context.Context, repositoryID int) (gql.CodeIntelligenceCommitGraphResolver, error)
 	QueueAutoIndexJobForRepo(ctx context.Context, repositoryID int) error
 	QueryResolver(ctx context.Context, args *gql.GitBlobLSIFDataArgs) (QueryResolver, error)
+	SymbolDiff(ctx context.Context, baseUploadID, headUploadID int) (diff.SymbolDiff, error)
+	UploadForCommit(ctx context.Context, repositoryID int, commit, root, indexer string) (store.Upload, bool, error)
+	RetentionPolicies(ctx context.Context) ([]store.RetentionPolicy, error)
+	CreateRetentionPolicy(ctx context.Context, policy store.RetentionPolicy) (store.RetentionPolicy, error)
+	DeleteRetentionPolicyByID(ctx context.Context, id int) error
+	UploadsExpiredByRetentionPolicies(ctx context.Context, repositoryID int) ([]store.Upload, error)
 }
 
 type resolver struct {
@@ -0,0 +1,33 @@
+package resolvers
+
+import (
+	"context"
+	"time"
+
+	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+)
+
+func (r *resolver) RetentionPolicies(ctx context.Context) ([]store.RetentionPolicy, error) {
+	return r.dbStore.GetRetentionPolicies(ctx)
+}
+
+func (r *resolver) CreateRetentionPolicy(ctx context.Context, policy store.RetentionPolicy) (store.RetentionPolicy, error) {
+	return r.dbStore.CreateRetentionPolicy(ctx, policy)
+}
+
+func (r *resolver) DeleteRetentionPolicyByID(ctx context.Context, id int) error {
+	_, err := r.dbStore.DeleteRetentionPolicyByID(ctx, id)
+	return err
+}
+
+// UploadsExpiredByRetentionPolicies returns the uploads that would be deleted by the next run of the
+// janitor because they are no longer retained by any retention policy. If repositoryID is zero, the
+// uploads of all repositories are considered.
+func (r *resolver) UploadsExpiredByRetentionPolicies(ctx context.Context, repositoryID int) ([]store.Upload, error) {
+	ids, err := r.dbStore.UploadsExpiredByRetentionPolicies(ctx, repositoryID, time.Now())
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	return r.dbStore.GetUploadsByIDs(ctx, ids...)
+}
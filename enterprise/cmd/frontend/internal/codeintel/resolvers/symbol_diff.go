@@ -0,0 +1,85 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/opentracing/opentracing-go/log"
+
+	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic/diff"
+)
+
+// SymbolDiff compares the symbols defined by the two given uploads. Both uploads must have
+// finished processing.
+func (r *resolver) SymbolDiff(ctx context.Context, baseUploadID, headUploadID int) (_ diff.SymbolDiff, err error) {
+	ctx, endObservation := r.operations.symbolDiff.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("baseUploadID", baseUploadID),
+		log.Int("headUploadID", headUploadID),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	uploads, err := r.dbStore.GetUploadsByIDs(ctx, baseUploadID, headUploadID)
+	if err != nil {
+		return diff.SymbolDiff{}, err
+	}
+	for _, id := range []int{baseUploadID, headUploadID} {
+		if err := checkUploadCompleted(uploads, id); err != nil {
+			return diff.SymbolDiff{}, err
+		}
+	}
+
+	base, err := r.lsifStore.DefinitionsBundle(ctx, baseUploadID)
+	if err != nil {
+		return diff.SymbolDiff{}, errors.Wrap(err, "lsifStore.DefinitionsBundle")
+	}
+	head, err := r.lsifStore.DefinitionsBundle(ctx, headUploadID)
+	if err != nil {
+		return diff.SymbolDiff{}, errors.Wrap(err, "lsifStore.DefinitionsBundle")
+	}
+
+	return diff.DiffSymbols(base, head), nil
+}
+
+// UploadForCommit returns the completed upload with the given root and indexer that is
+// closest to the given commit. If no indexer is given, then the root must identify a
+// single upload. A false-valued flag is returned if no such upload is visible.
+func (r *resolver) UploadForCommit(ctx context.Context, repositoryID int, commit, root, indexer string) (_ store.Upload, _ bool, err error) {
+	cachedCommitChecker := newCachedCommitChecker(r.gitserverClient)
+	cachedCommitChecker.set(repositoryID, commit)
+
+	dumps, err := r.findClosestDumps(ctx, cachedCommitChecker, repositoryID, commit, root, false, indexer)
+	if err != nil {
+		return store.Upload{}, false, err
+	}
+
+	var matching []store.Dump
+	for _, dump := range dumps {
+		if dump.Root == root {
+			matching = append(matching, dump)
+		}
+	}
+	if len(matching) == 0 {
+		return store.Upload{}, false, nil
+	}
+	if len(matching) > 1 && indexer == "" {
+		return store.Upload{}, false, errors.Errorf("multiple uploads with root %q are visible from commit %s; specify an indexer", root, commit)
+	}
+
+	return r.dbStore.GetUploadByID(ctx, matching[0].ID)
+}
+
+func checkUploadCompleted(uploads []store.Upload, id int) error {
+	for _, upload := range uploads {
+		if upload.ID == id {
+			if upload.State != "completed" {
+				return errors.Errorf("upload %d is not completed (state %s)", id, upload.State)
+			}
+
+			return nil
+		}
+	}
+
+	return errors.Errorf("upload %d not found", id)
+}
@@ -0,0 +1,75 @@
+package resolvers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+func TestSymbolDiff(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+
+	mockDBStore.GetUploadsByIDsFunc.SetDefaultReturn([]dbstore.Upload{
+		{ID: 42, State: "completed"},
+		{ID: 43, State: "completed"},
+	}, nil)
+
+	makeBundle := func(identifiers ...string) *semantic.GroupedBundleDataMaps {
+		definitions := map[string][]semantic.LocationData{}
+		for _, identifier := range identifiers {
+			definitions[identifier] = []semantic.LocationData{{URI: "main.go"}}
+		}
+
+		return &semantic.GroupedBundleDataMaps{
+			Documents:   map[string]semantic.DocumentData{},
+			Definitions: map[string]map[string][]semantic.LocationData{"gomod": definitions},
+		}
+	}
+	mockLSIFStore.DefinitionsBundleFunc.PushReturn(makeBundle("pkg:Foo", "pkg:Bar"), nil)
+	mockLSIFStore.DefinitionsBundleFunc.PushReturn(makeBundle("pkg:Foo", "pkg:Baz"), nil)
+
+	resolver := NewResolver(mockDBStore, mockLSIFStore, mockGitserverClient, nil, nil, &observation.TestContext)
+	diff, err := resolver.SymbolDiff(context.Background(), 42, 43)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0].Identifier != "pkg:Baz" {
+		t.Errorf("unexpected added symbols: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Identifier != "pkg:Bar" {
+		t.Errorf("unexpected removed symbols: %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 0 {
+		t.Errorf("unexpected changed symbols: %+v", diff.Changed)
+	}
+
+	if history := mockLSIFStore.DefinitionsBundleFunc.History(); len(history) != 2 || history[0].Arg1 != 42 || history[1].Arg1 != 43 {
+		t.Errorf("unexpected calls to DefinitionsBundle: %+v", history)
+	}
+}
+
+func TestSymbolDiffIncompleteUpload(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockLSIFStore := NewMockLSIFStore()
+	mockGitserverClient := NewMockGitserverClient()
+
+	mockDBStore.GetUploadsByIDsFunc.SetDefaultReturn([]dbstore.Upload{
+		{ID: 42, State: "completed"},
+		{ID: 43, State: "processing"},
+	}, nil)
+
+	resolver := NewResolver(mockDBStore, mockLSIFStore, mockGitserverClient, nil, nil, &observation.TestContext)
+	if _, err := resolver.SymbolDiff(context.Background(), 42, 43); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if len(mockLSIFStore.DefinitionsBundleFunc.History()) != 0 {
+		t.Errorf("unexpected call to DefinitionsBundle")
+	}
+}
@@ -0,0 +1,36 @@
+package searchjobs
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+type Config struct {
+	env.BaseConfig
+
+	// UploadStoreConfig configures the blob store of the results. It uses the
+	// same backend and credentials as the code intelligence uploads, but its
+	// own bucket and TTL.
+	UploadStoreConfig *uploadstore.Config
+	WorkerConcurrency int
+}
+
+var config = &Config{}
+
+func init() {
+	uploadStoreConfig := &uploadstore.Config{}
+	uploadStoreConfig.Load()
+	uploadStoreConfig.Bucket = config.Get("SEARCH_JOBS_UPLOAD_BUCKET", "search-jobs", "The name of the bucket to store the results of search jobs in.")
+	uploadStoreConfig.TTL = config.GetInterval("SEARCH_JOBS_UPLOAD_TTL", "168h", "The maximum age of the results of a search job before deletion.")
+	config.UploadStoreConfig = uploadStoreConfig
+
+	config.WorkerConcurrency = config.GetInt("SEARCH_JOBS_WORKER_CONCURRENCY", "2", "The maximum number of search jobs run concurrently by each frontend.")
+}
+
+// resultsTTL is the time after which finished jobs and their results are
+// deleted.
+func resultsTTL() time.Duration {
+	return config.UploadStoreConfig.TTL
+}
@@ -0,0 +1,3 @@
+package searchjobs
+
+//go:generate ../../../../../dev/mockgen.sh github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/searchjobs -i JobStore -o mock_iface_test.go
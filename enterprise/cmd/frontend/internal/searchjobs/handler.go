@@ -0,0 +1,334 @@
+package searchjobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+type handler struct {
+	db      dbutil.DB
+	store   JobStore
+	results uploadstore.Store
+}
+
+// NewHandler returns the handler of the search jobs API, which serves the
+// routes below /.api/search/jobs.
+func NewHandler(db dbutil.DB, store JobStore, results uploadstore.Store) http.Handler {
+	h := &handler{db: db, store: store, results: results}
+
+	const prefix = "/.api/search/jobs"
+	r := mux.NewRouter()
+	r.Path(prefix).Methods("GET").HandlerFunc(h.handleList)
+	r.Path(prefix).Methods("POST").HandlerFunc(h.handleCreate)
+	r.Path(prefix + "/{id:[0-9]+}").Methods("GET").HandlerFunc(h.withJob(h.handleGet))
+	r.Path(prefix + "/{id:[0-9]+}").Methods("DELETE").HandlerFunc(h.withJob(h.handleDelete))
+	r.Path(prefix + "/{id:[0-9]+}/cancel").Methods("POST").HandlerFunc(h.withJob(h.handleCancel))
+	r.Path(prefix + "/{id:[0-9]+}/results").Methods("GET").HandlerFunc(h.withJob(h.handleResults))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !conf.SearchJobs().Enabled {
+			http.Error(w, "Search jobs are disabled. Ask a site admin to enable experimentalFeatures[\"search.jobs\"] in the site configuration.", http.StatusNotFound)
+			return
+		}
+		if !actor.FromContext(req.Context()).IsAuthenticated() {
+			http.Error(w, "Must be signed in to use search jobs", http.StatusUnauthorized)
+			return
+		}
+		r.ServeHTTP(w, req)
+	})
+}
+
+// jobJSON is the representation of a search job in the API.
+type jobJSON struct {
+	ID              int        `json:"id"`
+	Query           string     `json:"query"`
+	State           string     `json:"state"`
+	FailureMessage  *string    `json:"failureMessage"`
+	ReposTotal      int        `json:"reposTotal"`
+	ReposSearched   int        `json:"reposSearched"`
+	ReposIncomplete int        `json:"reposIncomplete"`
+	MatchCount      int64      `json:"matchCount"`
+	ResultSize      int64      `json:"resultSize"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	StartedAt       *time.Time `json:"startedAt"`
+	FinishedAt      *time.Time `json:"finishedAt"`
+}
+
+func toJobJSON(job *searchjobs.Job) jobJSON {
+	failureMessage := job.FailureMessage
+	if job.Cancel {
+		// The failure message of canceled jobs is redundant with their state.
+		failureMessage = nil
+	}
+	return jobJSON{
+		ID:              job.ID,
+		Query:           job.Query,
+		State:           job.Status(),
+		FailureMessage:  failureMessage,
+		ReposTotal:      job.ReposTotal,
+		ReposSearched:   job.ReposSearched,
+		ReposIncomplete: job.ReposIncomplete,
+		MatchCount:      job.MatchCount,
+		ResultSize:      job.ResultSize,
+		CreatedAt:       job.CreatedAt,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+	}
+}
+
+// GET /.api/search/jobs
+//
+// Lists the search jobs of the current user, newest first.
+func (h *handler) handleList(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.store.ListByUser(r.Context(), actor.FromContext(r.Context()).UID)
+	if err != nil {
+		log15.Error("Failed to list search jobs", "error", err)
+		http.Error(w, fmt.Sprintf("failed to list search jobs: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	payload := make([]jobJSON, 0, len(jobs))
+	for _, job := range jobs {
+		payload = append(payload, toJobJSON(job))
+	}
+	writeJSON(w, http.StatusOK, payload)
+}
+
+// POST /.api/search/jobs {"query": "..."}
+//
+// Queues a search job for the current user. The query is validated before
+// the job is queued. It fails with 429 Too Many Requests if the user reached
+// one of the limits on the number of search jobs.
+func (h *handler) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var args struct {
+		Query string `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+	if args.Query == "" {
+		http.Error(w, "Query must not be empty", http.StatusBadRequest)
+		return
+	}
+	if _, err := searchjobs.ParseQuery(args.Query); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid query: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	c := conf.SearchJobs()
+	job, err := h.store.Create(r.Context(), actor.FromContext(r.Context()).UID, args.Query, searchjobs.Limits{
+		MaxActiveJobs: c.MaxActiveJobsPerUser,
+		MaxJobs:       c.MaxJobsPerUser,
+	})
+	if err != nil {
+		if _, ok := err.(*searchjobs.QuotaExceededError); ok {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		log15.Error("Failed to create search job", "error", err)
+		http.Error(w, fmt.Sprintf("failed to create search job: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toJobJSON(job))
+}
+
+// GET /.api/search/jobs/{id}
+//
+// Returns the state and progress of a search job.
+func (h *handler) handleGet(w http.ResponseWriter, r *http.Request, job *searchjobs.Job) {
+	writeJSON(w, http.StatusOK, toJobJSON(job))
+}
+
+// POST /.api/search/jobs/{id}/cancel
+//
+// Cancels a search job. Queued jobs are canceled immediately, running jobs
+// are "canceling" until the worker stops. The results stored so far can
+// still be downloaded.
+func (h *handler) handleCancel(w http.ResponseWriter, r *http.Request, job *searchjobs.Job) {
+	ok, err := h.store.Cancel(r.Context(), job.ID)
+	if err != nil {
+		log15.Error("Failed to cancel search job", "error", err)
+		http.Error(w, fmt.Sprintf("failed to cancel search job: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "search job already finished", http.StatusConflict)
+		return
+	}
+
+	job, exists, err := h.store.GetByID(r.Context(), job.ID)
+	if err != nil {
+		log15.Error("Failed to retrieve search job", "error", err)
+		http.Error(w, fmt.Sprintf("failed to retrieve search job: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, toJobJSON(job))
+}
+
+// DELETE /.api/search/jobs/{id}
+//
+// Deletes a finished search job and its results.
+func (h *handler) handleDelete(w http.ResponseWriter, r *http.Request, job *searchjobs.Job) {
+	ok, err := h.store.Delete(r.Context(), job.ID)
+	if err != nil {
+		log15.Error("Failed to delete search job", "error", err)
+		http.Error(w, fmt.Sprintf("failed to delete search job: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, "search job is still running, cancel it before deleting it", http.StatusConflict)
+		return
+	}
+
+	searchjobs.DeleteResults(r.Context(), h.results, job)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resultFormats maps the values of the format query parameter to the content
+// type and file extension of the response and the function that converts the
+// stored JSON lines.
+var resultFormats = map[string]struct {
+	contentType string
+	extension   string
+	write       func(w io.Writer, r io.Reader) error
+}{
+	"jsonl": {
+		contentType: "application/x-ndjson",
+		extension:   "jsonl",
+		write: func(w io.Writer, r io.Reader) error {
+			_, err := io.Copy(w, r)
+			return err
+		},
+	},
+	"csv": {
+		contentType: "text/csv",
+		extension:   "csv",
+		write:       searchjobs.WriteCSV,
+	},
+}
+
+// GET /.api/search/jobs/{id}/results?format={jsonl,csv}
+//
+// Downloads the results of a search job. The default format is JSON lines,
+// with one result per line. Jobs which haven't completed return the results
+// stored so far.
+func (h *handler) handleResults(w http.ResponseWriter, r *http.Request, job *searchjobs.Job) {
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "jsonl"
+	}
+	format, ok := resultFormats[formatName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown result format %q", formatName), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=search-job-%d.%s", job.ID, format.extension))
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(h.readResults(r.Context(), pw, job))
+	}()
+	defer pr.Close()
+
+	if err := format.write(w, pr); err != nil {
+		// The status was sent already, but the response is truncated.
+		log15.Error("Failed to write search job results to client", "job", job.ID, "error", err)
+	}
+}
+
+// readResults writes the stored parts of the results of job to w, in order.
+func (h *handler) readResults(ctx context.Context, w io.Writer, job *searchjobs.Job) error {
+	for _, key := range job.ResultPartKeys() {
+		rc, err := h.results.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(w, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withJob wraps a handler of the routes of a single job. It loads the job and
+// checks that the current user may access it.
+func (h *handler) withJob(f func(http.ResponseWriter, *http.Request, *searchjobs.Job)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Search job must be a numeric identifier", http.StatusBadRequest)
+			return
+		}
+
+		job, exists, err := h.store.GetByID(ctx, id)
+		if err != nil {
+			log15.Error("Failed to retrieve search job", "error", err)
+			http.Error(w, fmt.Sprintf("failed to retrieve search job: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		// 🚨 SECURITY: The results of a job are searched with the permissions
+		// of its creator, so only they and site admins may access it. Jobs of
+		// other users are reported as missing so as not to reveal they exist.
+		if !exists || (job.UserID != actor.FromContext(ctx).UID && !h.isSiteAdmin(ctx)) {
+			http.Error(w, "search job not found", http.StatusNotFound)
+			return
+		}
+
+		f(w, r, job)
+	}
+}
+
+func (h *handler) isSiteAdmin(ctx context.Context) bool {
+	user, err := database.Users(h.db).GetByCurrentAuthUser(ctx)
+	if err != nil {
+		if !errcode.IsNotFound(err) && err != database.ErrNoCurrentUser {
+			log15.Error("Failed to get current user", "error", err)
+		}
+		return false
+	}
+	return user != nil && user.SiteAdmin
+}
+
+// writeJSON writes the JSON-encoded payload to w with the given status.
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to serialize result: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		log15.Error("Failed to write payload to client", "error", err)
+	}
+}
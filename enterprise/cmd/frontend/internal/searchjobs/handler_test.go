@@ -0,0 +1,220 @@
+package searchjobs
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	uploadstoremocks "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore/mocks"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func setupMocks(t *testing.T, enabled, siteAdmin bool) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{
+			SearchJobs: &schema.SearchJobs{Enabled: enabled, MaxActiveJobsPerUser: 1, MaxJobsPerUser: 3},
+		},
+	}})
+	database.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID, SiteAdmin: siteAdmin}, nil
+	}
+	t.Cleanup(func() {
+		conf.Mock(nil)
+		database.Mocks.Users.GetByCurrentAuthUser = nil
+	})
+}
+
+func serve(h http.Handler, uid int32, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if uid != 0 {
+		r = r.WithContext(actor.WithActor(r.Context(), actor.FromUser(uid)))
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func newMockJobStore() *MockJobStore {
+	store := NewMockJobStore()
+	store.GetByIDFunc.SetDefaultHook(func(ctx context.Context, id int) (*searchjobs.Job, bool, error) {
+		if id != 42 {
+			return nil, false, nil
+		}
+		return &searchjobs.Job{ID: 42, UserID: 1, Query: "foo", State: "completed", MatchCount: 2, ResultParts: 2}, true, nil
+	})
+	return store
+}
+
+func TestHandler_disabled(t *testing.T) {
+	setupMocks(t, false, false)
+
+	w := serve(NewHandler(nil, newMockJobStore(), uploadstoremocks.NewMockStore()), 1, "GET", "/.api/search/jobs", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("unexpected status code. want=%d have=%d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestHandler_unauthenticated(t *testing.T) {
+	setupMocks(t, true, false)
+
+	w := serve(NewHandler(nil, newMockJobStore(), uploadstoremocks.NewMockStore()), 0, "GET", "/.api/search/jobs", "")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status code. want=%d have=%d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	setupMocks(t, true, false)
+
+	store := newMockJobStore()
+	store.CreateFunc.SetDefaultHook(func(ctx context.Context, userID int32, query string, limits searchjobs.Limits) (*searchjobs.Job, error) {
+		if query == "quota" {
+			return nil, &searchjobs.QuotaExceededError{}
+		}
+		return &searchjobs.Job{ID: 43, UserID: userID, Query: query, State: "queued"}, nil
+	})
+	h := NewHandler(nil, store, uploadstoremocks.NewMockStore())
+
+	w := serve(h, 1, "POST", "/.api/search/jobs", `{"query": "repo:a foo"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("unexpected status code. want=%d have=%d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"id":43,"query":"repo:a foo","state":"queued"`) {
+		t.Errorf("unexpected body %s", w.Body.String())
+	}
+
+	calls := store.CreateFunc.History()
+	if len(calls) != 1 || calls[0].Arg1 != 1 || calls[0].Arg3 != (searchjobs.Limits{MaxActiveJobs: 1, MaxJobs: 3}) {
+		t.Errorf("unexpected create calls %+v", calls)
+	}
+
+	for _, tc := range []struct {
+		body string
+		code int
+	}{
+		{body: `{"query": ""}`, code: http.StatusBadRequest},
+		{body: `{"query": "foo case:maybe"}`, code: http.StatusBadRequest},
+		{body: `{`, code: http.StatusBadRequest},
+		{body: `{"query": "quota"}`, code: http.StatusTooManyRequests},
+	} {
+		if w := serve(h, 1, "POST", "/.api/search/jobs", tc.body); w.Code != tc.code {
+			t.Errorf("unexpected status code for %s. want=%d have=%d", tc.body, tc.code, w.Code)
+		}
+	}
+}
+
+func TestHandleGet_permissions(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		uid       int32
+		siteAdmin bool
+		path      string
+		code      int
+	}{
+		{name: "owner", uid: 1, path: "/.api/search/jobs/42", code: http.StatusOK},
+		{name: "other user", uid: 2, path: "/.api/search/jobs/42", code: http.StatusNotFound},
+		{name: "site admin", uid: 2, siteAdmin: true, path: "/.api/search/jobs/42", code: http.StatusOK},
+		{name: "missing", uid: 1, path: "/.api/search/jobs/43", code: http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setupMocks(t, true, tc.siteAdmin)
+
+			w := serve(NewHandler(nil, newMockJobStore(), uploadstoremocks.NewMockStore()), tc.uid, "GET", tc.path, "")
+			if w.Code != tc.code {
+				t.Fatalf("unexpected status code. want=%d have=%d", tc.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleCancel(t *testing.T) {
+	setupMocks(t, true, false)
+
+	store := newMockJobStore()
+	store.CancelFunc.SetDefaultReturn(false, nil)
+
+	w := serve(NewHandler(nil, store, uploadstoremocks.NewMockStore()), 1, "POST", "/.api/search/jobs/42/cancel", "")
+	if w.Code != http.StatusConflict {
+		t.Fatalf("unexpected status code. want=%d have=%d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestHandleDelete(t *testing.T) {
+	setupMocks(t, true, false)
+
+	store := newMockJobStore()
+	store.DeleteFunc.SetDefaultReturn(true, nil)
+	results := uploadstoremocks.NewMockStore()
+
+	w := serve(NewHandler(nil, store, results), 1, "DELETE", "/.api/search/jobs/42", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code. want=%d have=%d", http.StatusNoContent, w.Code)
+	}
+
+	var keys []string
+	for _, call := range results.DeleteFunc.History() {
+		keys = append(keys, call.Arg1)
+	}
+	if diff := cmp.Diff([]string{"search-jobs/42/0.jsonl", "search-jobs/42/1.jsonl"}, keys); diff != "" {
+		t.Errorf("unexpected deleted keys (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandleResults(t *testing.T) {
+	setupMocks(t, true, false)
+
+	results := uploadstoremocks.NewMockStore()
+	results.GetFunc.SetDefaultHook(func(ctx context.Context, key string) (io.ReadCloser, error) {
+		parts := map[string]string{
+			"search-jobs/42/0.jsonl": `{"type":"path","repository":"github.com/a/b","path":"a.go"}` + "\n",
+			"search-jobs/42/1.jsonl": `{"type":"path","repository":"github.com/a/c","path":"b.go"}` + "\n",
+		}
+		return ioutil.NopCloser(strings.NewReader(parts[key])), nil
+	})
+	h := NewHandler(nil, newMockJobStore(), results)
+
+	for _, tc := range []struct {
+		format      string
+		body        string
+		disposition string
+	}{
+		{
+			format:      "",
+			body:        `{"type":"path","repository":"github.com/a/b","path":"a.go"}` + "\n" + `{"type":"path","repository":"github.com/a/c","path":"b.go"}` + "\n",
+			disposition: "attachment; filename=search-job-42.jsonl",
+		},
+		{
+			format:      "csv",
+			body:        "type,repository,revision,path,lineNumber,preview,author,date\npath,github.com/a/b,,a.go,,,,\npath,github.com/a/c,,b.go,,,,\n",
+			disposition: "attachment; filename=search-job-42.csv",
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			w := serve(h, 1, "GET", "/.api/search/jobs/42/results?format="+tc.format, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status code. want=%d have=%d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if diff := cmp.Diff(tc.body, w.Body.String()); diff != "" {
+				t.Errorf("unexpected body (-want +got):\n%s", diff)
+			}
+			if disposition := w.Header().Get("Content-Disposition"); disposition != tc.disposition {
+				t.Errorf("unexpected content disposition %q", disposition)
+			}
+		})
+	}
+
+	if w := serve(h, 1, "GET", "/.api/search/jobs/42/results?format=xml", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("unexpected status code. want=%d have=%d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,15 @@
+package searchjobs
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+)
+
+type JobStore interface {
+	Create(ctx context.Context, userID int32, query string, limits searchjobs.Limits) (*searchjobs.Job, error)
+	GetByID(ctx context.Context, id int) (*searchjobs.Job, bool, error)
+	ListByUser(ctx context.Context, userID int32) ([]*searchjobs.Job, error)
+	Cancel(ctx context.Context, id int) (bool, error)
+	Delete(ctx context.Context, id int) (bool, error)
+}
@@ -0,0 +1,103 @@
+package searchjobs
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/enterprise"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/oobmigration"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker"
+)
+
+// Init starts the workers which run search jobs and registers the search jobs
+// API. The searches run in the frontend, so that they are subject to the
+// repository permissions of the users who created them.
+func Init(ctx context.Context, db dbutil.DB, outOfBandMigrationRunner *oobmigration.Runner, enterpriseServices *enterprise.Services) error {
+	if err := config.Validate(); err != nil {
+		return errors.Wrap(err, "loading search jobs config")
+	}
+	if err := config.UploadStoreConfig.Validate(); err != nil {
+		return errors.Wrap(err, "loading search jobs upload store config")
+	}
+
+	observationContext := &observation.Context{
+		Logger: log15.Root(),
+		Tracer: &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		// The upload store registers the same metrics as the one of code
+		// intelligence, so they are prefixed with search_jobs_.
+		Registerer: prometheus.WrapRegistererWithPrefix("search_jobs_", prometheus.DefaultRegisterer),
+	}
+
+	results, err := uploadstore.CreateLazy(ctx, config.UploadStoreConfig, observationContext)
+	if err != nil {
+		return errors.Wrap(err, "initializing search jobs upload store")
+	}
+
+	store := searchjobs.NewStore(db)
+	metrics := newMetrics()
+
+	routines := []goroutine.BackgroundRoutine{
+		searchjobs.NewWorker(ctx, store, &searcher{db: db}, results, config.WorkerConcurrency, metrics.workerMetrics),
+		searchjobs.NewResetter(store, dbworker.ResetterMetrics{
+			RecordResets:        metrics.resets,
+			RecordResetFailures: metrics.resetFailures,
+			Errors:              metrics.errors,
+		}),
+		searchjobs.NewJanitor(ctx, store, results, resultsTTL()),
+	}
+	go goroutine.MonitorBackgroundRoutines(ctx, routines...)
+
+	enterpriseServices.SearchJobsHandler = NewHandler(db, store, results)
+	return nil
+}
+
+type searchJobsMetrics struct {
+	workerMetrics workerutil.WorkerMetrics
+	resets        prometheus.Counter
+	resetFailures prometheus.Counter
+	errors        prometheus.Counter
+}
+
+func newMetrics() searchJobsMetrics {
+	observationContext := &observation.Context{
+		Logger:     log15.Root(),
+		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		Registerer: prometheus.DefaultRegisterer,
+	}
+
+	resetFailures := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "src_search_jobs_reset_failures_total",
+		Help: "The number of reset failures.",
+	})
+	observationContext.Registerer.MustRegister(resetFailures)
+
+	resets := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "src_search_jobs_resets_total",
+		Help: "The number of records reset.",
+	})
+	observationContext.Registerer.MustRegister(resets)
+
+	errors := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "src_search_jobs_errors_total",
+		Help: "The number of errors that occur during job.",
+	})
+	observationContext.Registerer.MustRegister(errors)
+
+	return searchJobsMetrics{
+		workerMetrics: workerutil.NewMetrics(observationContext, "search_jobs", nil),
+		resets:        resets,
+		resetFailures: resetFailures,
+		errors:        errors,
+	}
+}
@@ -0,0 +1,53 @@
+package searchjobs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/searchjobs"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
+)
+
+// searcher runs the searches of search jobs with the search backend of the
+// frontend, which enforces the repository permissions of the actor.
+type searcher struct {
+	db dbutil.DB
+}
+
+var _ searchjobs.Searcher = &searcher{}
+
+func (s *searcher) Search(ctx context.Context, query string, send func([]result.Match)) (bool, error) {
+	var (
+		mu    sync.Mutex
+		stats streaming.Stats
+	)
+	impl, err := graphqlbackend.NewSearchImplementer(ctx, s.db, &graphqlbackend.SearchArgs{
+		Query:   query,
+		Version: "V2",
+		Stream: streaming.StreamFunc(func(event streaming.SearchEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			stats.Update(&event.Stats)
+			if len(event.Results) > 0 {
+				send(event.Results)
+			}
+		}),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	results, err := impl.Results(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	stats.Update(&results.Stats)
+	return stats.Status.Any(search.RepoStatusCloning|search.RepoStatusMissing|search.RepoStatusTimedout|search.RepoStatusLimitHit) || stats.IsLimitHit, nil
+}
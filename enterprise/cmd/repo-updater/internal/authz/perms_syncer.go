@@ -501,6 +501,14 @@ func (s *PermsSyncer) syncRepoPerms(ctx context.Context, repoID api.RepoID, noPe
 		ExternalRepoSpec: repo.ExternalRepo,
 	})
 
+	// Some code hosts can't list the users who have access to a repository, in which case
+	// permissions are only synced from the users' side. We touch the repository permissions
+	// so the scheduler won't keep trying to fetch permissions of this same repository.
+	if errors.Is(err, authz.ErrUnimplemented) {
+		log15.Debug("PermsSyncer.syncRepoPerms.unimplemented", "repoID", repo.ID, "provider", provider.ServiceType(), "err", err)
+		return errors.Wrap(s.permsStore.TouchRepoPermissions(ctx, int32(repoID)), "touch repository permissions")
+	}
+
 	// Detect 404 error (i.e. not authorized to call given APIs) that often happens with GitHub.com
 	// when the owner of the token only has READ access. However, we don't want to fail
 	// so the scheduler won't keep trying to fetch permissions of this same repository, so we
@@ -480,6 +480,57 @@ func TestPermsSyncer_syncRepoPerms(t *testing.T) {
 		}
 	})
 
+	t.Run("TouchRepoPermissions is called when authz provider can't fetch repository permissions", func(t *testing.T) {
+		p := &mockProvider{
+			serviceType: extsvc.TypeBitbucketCloud,
+			serviceID:   "https://bitbucket.org/",
+			fetchRepoPerms: func(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error) {
+				return nil, errors.Wrap(authz.ErrUnimplemented, "fetch repository permissions")
+			},
+		}
+		authz.SetProviders(false, []authz.Provider{p})
+		defer authz.SetProviders(true, nil)
+
+		calledTouchRepoPermissions := false
+		edb.Mocks.Perms.TouchRepoPermissions = func(ctx context.Context, repoID int32) error {
+			calledTouchRepoPermissions = true
+			return nil
+		}
+		edb.Mocks.Perms.SetRepoPermissions = func(context.Context, *authz.RepoPermissions) error {
+			return errors.New("not supposed to be called")
+		}
+		database.Mocks.Repos.List = func(context.Context, database.ReposListOptions) ([]*types.Repo, error) {
+			return []*types.Repo{
+				{
+					ID:      1,
+					Private: true,
+					ExternalRepo: api.ExternalRepoSpec{
+						ServiceID: p.ServiceID(),
+					},
+					Sources: map[string]*types.SourceInfo{
+						p.URN(): {},
+					},
+				},
+			}, nil
+		}
+		defer func() {
+			edb.Mocks.Perms = edb.MockPerms{}
+			database.Mocks.Repos = database.MockRepos{}
+		}()
+
+		s := newPermsSyncer(repos.NewStore(&dbtesting.MockDB{}, sql.TxOptions{}))
+
+		// Partial results must not be used either.
+		err := s.syncRepoPerms(context.Background(), 1, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !calledTouchRepoPermissions {
+			t.Fatal("!calledTouchRepoPermissions")
+		}
+	})
+
 	p := &mockProvider{
 		serviceType: extsvc.TypeGitLab,
 		serviceID:   "https://gitlab.com/",
@@ -53,6 +53,10 @@ type MockDBStore struct {
 	// SoftDeleteOldUploadsFunc is an instance of a mock function object
 	// controlling the behavior of the method SoftDeleteOldUploads.
 	SoftDeleteOldUploadsFunc *DBStoreSoftDeleteOldUploadsFunc
+	// SoftDeleteUploadsExpiredByRetentionPoliciesFunc is an instance of a
+	// mock function object controlling the behavior of the method
+	// SoftDeleteUploadsExpiredByRetentionPolicies.
+	SoftDeleteUploadsExpiredByRetentionPoliciesFunc *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc
 	// StaleSourcedCommitsFunc is an instance of a mock function object
 	// controlling the behavior of the method StaleSourcedCommits.
 	StaleSourcedCommitsFunc *DBStoreStaleSourcedCommitsFunc
@@ -120,6 +124,11 @@ func NewMockDBStore() *MockDBStore {
 				return 0, nil
 			},
 		},
+		SoftDeleteUploadsExpiredByRetentionPoliciesFunc: &DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc{
+			defaultHook: func(context.Context, time.Time) (int, error) {
+				return 0, nil
+			},
+		},
 		StaleSourcedCommitsFunc: &DBStoreStaleSourcedCommitsFunc{
 			defaultHook: func(context.Context, time.Duration, int, time.Time) ([]dbstore.SourcedCommits, error) {
 				return nil, nil
@@ -170,6 +179,9 @@ func NewMockDBStoreFrom(i DBStore) *MockDBStore {
 		SoftDeleteOldUploadsFunc: &DBStoreSoftDeleteOldUploadsFunc{
 			defaultHook: i.SoftDeleteOldUploads,
 		},
+		SoftDeleteUploadsExpiredByRetentionPoliciesFunc: &DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc{
+			defaultHook: i.SoftDeleteUploadsExpiredByRetentionPolicies,
+		},
 		StaleSourcedCommitsFunc: &DBStoreStaleSourcedCommitsFunc{
 			defaultHook: i.StaleSourcedCommits,
 		},
@@ -1398,6 +1410,121 @@ func (c DBStoreSoftDeleteOldUploadsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc describes the
+// behavior when the SoftDeleteUploadsExpiredByRetentionPolicies method of
+// the parent MockDBStore instance is invoked.
+type DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc struct {
+	defaultHook func(context.Context, time.Time) (int, error)
+	hooks       []func(context.Context, time.Time) (int, error)
+	history     []DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall
+	mutex       sync.Mutex
+}
+
+// SoftDeleteUploadsExpiredByRetentionPolicies delegates to the next hook
+// function in the queue and stores the parameter and result values of this
+// invocation.
+func (m *MockDBStore) SoftDeleteUploadsExpiredByRetentionPolicies(v0 context.Context, v1 time.Time) (int, error) {
+	r0, r1 := m.SoftDeleteUploadsExpiredByRetentionPoliciesFunc.nextHook()(v0, v1)
+	m.SoftDeleteUploadsExpiredByRetentionPoliciesFunc.appendCall(DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// SoftDeleteUploadsExpiredByRetentionPolicies method of the parent
+// MockDBStore instance is invoked and the hook queue is empty.
+func (f *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc) SetDefaultHook(hook func(context.Context, time.Time) (int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SoftDeleteUploadsExpiredByRetentionPolicies method of the parent
+// MockDBStore instance invokes the hook at the front of the queue and
+// discards it. After the queue is empty, the default hook function is
+// invoked for any future action.
+func (f *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc) PushHook(hook func(context.Context, time.Time) (int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc) SetDefaultReturn(r0 int, r1 error) {
+	f.SetDefaultHook(func(context.Context, time.Time) (int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc) PushReturn(r0 int, r1 error) {
+	f.PushHook(func(context.Context, time.Time) (int, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc) nextHook() func(context.Context, time.Time) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc) appendCall(r0 DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of
+// DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall objects
+// describing the invocations of this function.
+func (f *DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFunc) History() []DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall {
+	f.mutex.Lock()
+	history := make([]DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall is an object
+// that describes an invocation of method
+// SoftDeleteUploadsExpiredByRetentionPolicies on an instance of
+// MockDBStore.
+type DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 time.Time
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBStoreSoftDeleteUploadsExpiredByRetentionPoliciesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // DBStoreStaleSourcedCommitsFunc describes the behavior when the
 // StaleSourcedCommits method of the parent MockDBStore instance is invoked.
 type DBStoreStaleSourcedCommitsFunc struct {
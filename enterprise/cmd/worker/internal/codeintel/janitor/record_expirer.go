@@ -22,7 +22,9 @@ var _ goroutine.Handler = &recordExpirer{}
 // NewRecordExpirer returns a background routine that periodically removes upload
 // and index records that are older than the given TTL. Upload records which have
 // valid LSIF data (not just a historic upload failure record) will only be deleted
-// if it is not visible at the tip of its repository's default branch.
+// if it is not visible at the tip of its repository's default branch. Uploads which
+// are visible from the tips of branches or tags matched by a retention policy are
+// instead deleted once they are no longer retained by those policies.
 func NewRecordExpirer(dbStore DBStore, ttl, interval time.Duration, metrics *metrics) goroutine.BackgroundRoutine {
 	return goroutine.NewPeriodicGoroutine(context.Background(), interval, &recordExpirer{
 		dbStore: dbStore,
@@ -55,7 +57,18 @@ func (e *recordExpirer) expireUploads(ctx context.Context) error {
 	}
 	defer func() { err = tx.Done(err) }()
 
-	count, err := tx.SoftDeleteOldUploads(ctx, e.ttl, time.Now())
+	now := time.Now()
+
+	count, err := tx.SoftDeleteUploadsExpiredByRetentionPolicies(ctx, now)
+	if err != nil {
+		return errors.Wrap(err, "SoftDeleteUploadsExpiredByRetentionPolicies")
+	}
+	if count > 0 {
+		log15.Debug("Deleted upload records expired by retention policies", "count", count)
+		e.metrics.numUploadRecordsRemoved.Add(float64(count))
+	}
+
+	count, err = tx.SoftDeleteOldUploads(ctx, e.ttl, now)
 	if err != nil {
 		return errors.Wrap(err, "SoftDeleteOldUploads")
 	}
@@ -19,6 +19,7 @@ import (
 	btypes "github.com/sourcegraph/sourcegraph/enterprise/internal/batches/types"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/audit"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/encryption"
@@ -413,6 +414,8 @@ func (r *Resolver) ApplyBatchChange(ctx context.Context, args *graphqlbackend.Ap
 	if err != nil {
 		return nil, err
 	}
+	audit.SetTarget(ctx, "batch change %d (%s)", batchChange.ID, batchChange.Name)
+	audit.SetChange(ctx, "", fmt.Sprintf("batch spec %d applied", batchChange.BatchSpecID))
 
 	arg := &batchChangeEventArg{BatchChangeID: batchChange.ID}
 	err = logBackendEvent(ctx, r.store.DB(), "BatchChangeCreatedOrUpdated", arg)
@@ -2,12 +2,14 @@ package enqueuer
 
 import (
 	"context"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	"github.com/inconshreveable/log15"
 	"github.com/opentracing/opentracing-go/log"
 	"golang.org/x/time/rate"
 
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/gitserver"
 	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
@@ -46,8 +48,8 @@ func NewIndexEnqueuer(
 }
 
 // QueueIndexesForRepository attempts to queue an index for the lastest commit on the default branch of the given
-// repository. If this repository and commit already has an index or upload record associated with it, this method
-// does nothing.
+// repository, as well as for the tips of the branches and tags selected by retention policies with indexing enabled.
+// If this repository and commit already has an index or upload record associated with it, this method does nothing.
 func (s *IndexEnqueuer) QueueIndexesForRepository(ctx context.Context, repositoryID int) error {
 	return s.queueIndexForRepository(ctx, repositoryID, false)
 }
@@ -149,7 +151,64 @@ func (s *IndexEnqueuer) queueIndexForRepository(ctx context.Context, repositoryI
 	}
 	traceLog(log.String("commit", commit))
 
-	return s.queueIndexForRepositoryAndCommit(ctx, repositoryID, commit, force, traceLog)
+	if err := s.queueIndexForRepositoryAndCommit(ctx, repositoryID, commit, force, traceLog); err != nil {
+		return err
+	}
+
+	return s.queueIndexesForRetainedRefs(ctx, repositoryID, commit, traceLog)
+}
+
+// queueIndexesForRetainedRefs attempts to queue an index for the tip of each branch and tag of the given repository
+// that is selected by a retention policy with indexing enabled. The given head commit has already been considered
+// and is skipped. Unlike the head commit, these commits are never forcibly re-indexed.
+func (s *IndexEnqueuer) queueIndexesForRetainedRefs(ctx context.Context, repositoryID int, headCommit string, traceLog observation.TraceLogger) error {
+	policies, err := s.dbStore.GetRetentionPolicies(ctx)
+	if err != nil {
+		return errors.Wrap(err, "dbstore.GetRetentionPolicies")
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+
+	repositoryName, err := s.dbStore.RepoName(ctx, repositoryID)
+	if err != nil {
+		return errors.Wrap(err, "dbstore.RepoName")
+	}
+
+	matcher, err := store.NewRetentionPolicyMatcher(policies)
+	if err != nil {
+		return err
+	}
+	matcher = matcher.ForRepository(repositoryName)
+	if matcher.Empty() {
+		return nil
+	}
+
+	refDescriptions, err := s.gitserverClient.RefDescriptions(ctx, repositoryID)
+	if err != nil {
+		return errors.Wrap(err, "gitserver.RefDescriptions")
+	}
+
+	refs := make([]gitserver.RefDescription, 0, len(refDescriptions))
+	commitsByRefName := make(map[string]string, len(refDescriptions))
+	for commit, refDescription := range refDescriptions {
+		refs = append(refs, refDescription)
+		commitsByRefName[refDescription.Name] = commit
+	}
+
+	for _, ref := range matcher.RefsToIndex(refs, time.Now()) {
+		commit := commitsByRefName[ref.Name]
+		if commit == headCommit {
+			continue
+		}
+		traceLog(log.String("ref", ref.Name), log.String("refCommit", commit))
+
+		if err := s.queueIndexForRepositoryAndCommit(ctx, repositoryID, commit, false, traceLog); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // queueIndexForRepositoryAndCommit determines a set of index jobs to enqueue for the given repository and commit.
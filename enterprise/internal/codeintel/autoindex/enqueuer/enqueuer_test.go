@@ -6,10 +6,12 @@ import (
 	"regexp"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/time/rate"
 
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/gitserver"
 	store "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/observation"
@@ -353,6 +355,44 @@ func TestQueueIndexesForRepositoryInferredTooLarge(t *testing.T) {
 	}
 }
 
+func TestQueueIndexesForRepositoryRetainedRefs(t *testing.T) {
+	mockDBStore := NewMockDBStore()
+	mockDBStore.TransactFunc.SetDefaultReturn(mockDBStore, nil)
+	mockDBStore.DoneFunc.SetDefaultHook(func(err error) error { return err })
+	mockDBStore.RepoNameFunc.SetDefaultReturn("github.com/foo/bar", nil)
+	mockDBStore.GetRetentionPoliciesFunc.SetDefaultReturn([]store.RetentionPolicy{
+		{ID: 1, RepositoryPattern: "github.com/foo/*", RefPattern: "release/*", IndexingEnabled: true},
+		{ID: 2, RepositoryPattern: "github.com/foo/*", RefPattern: "pr/*"},
+	}, nil)
+
+	now := time.Now()
+	mockGitserverClient := NewMockGitserverClient()
+	mockGitserverClient.HeadFunc.SetDefaultReturn("c1", true, nil)
+	mockGitserverClient.ListFilesFunc.SetDefaultReturn([]string{"go.mod"}, nil)
+	mockGitserverClient.RefDescriptionsFunc.SetDefaultReturn(map[string]gitserver.RefDescription{
+		"c1": {Name: "main", Type: gitserver.RefTypeBranch, IsDefaultBranch: true, CreatedDate: now},
+		"c2": {Name: "release/1.0", Type: gitserver.RefTypeBranch, CreatedDate: now},
+		"c3": {Name: "release/1.1", Type: gitserver.RefTypeBranch, CreatedDate: now},
+		"c4": {Name: "pr/4", Type: gitserver.RefTypeBranch, CreatedDate: now},
+	}, nil)
+
+	scheduler := NewIndexEnqueuer(mockDBStore, mockGitserverClient, nil, &testConfig, &observation.TestContext)
+
+	if err := scheduler.QueueIndexesForRepository(context.Background(), 42); err != nil {
+		t.Fatalf("unexpected error performing update: %s", err)
+	}
+
+	var commits []string
+	for _, call := range mockDBStore.InsertIndexFunc.History() {
+		commits = append(commits, call.Arg1.Commit)
+	}
+	sort.Strings(commits)
+
+	if diff := cmp.Diff([]string{"c1", "c2", "c3"}, commits); diff != "" {
+		t.Errorf("unexpected indexed commits (-want +got):\n%s", diff)
+	}
+}
+
 func TestQueueIndexesForPackage(t *testing.T) {
 	mockDBStore := NewMockDBStore()
 	mockDBStore.TransactFunc.SetDefaultReturn(mockDBStore, nil)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"regexp"
 
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/gitserver"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/dbstore"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
@@ -22,6 +23,8 @@ type DBStore interface {
 	InsertIndex(ctx context.Context, index dbstore.Index) (int, error)
 	GetRepositoriesWithIndexConfiguration(ctx context.Context) ([]int, error)
 	GetIndexConfigurationByRepositoryID(ctx context.Context, repositoryID int) (dbstore.IndexConfiguration, bool, error)
+	GetRetentionPolicies(ctx context.Context) ([]dbstore.RetentionPolicy, error)
+	RepoName(ctx context.Context, repositoryID int) (string, error)
 }
 
 type DBStoreShim struct {
@@ -48,6 +51,7 @@ type GitserverClient interface {
 	FileExists(ctx context.Context, repositoryID int, commit, file string) (bool, error)
 	RawContents(ctx context.Context, repositoryID int, commit, file string) ([]byte, error)
 	ResolveRevision(ctx context.Context, repositoryID int, versionString string) (api.CommitID, error)
+	RefDescriptions(ctx context.Context, repositoryID int) (map[string]gitserver.RefDescription, error)
 }
 
 type gitClient struct {
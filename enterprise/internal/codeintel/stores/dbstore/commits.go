@@ -190,8 +190,8 @@ func scanCommitGraphMetadata(rows *sql.Rows, queryErr error) (updateToken, dirty
 
 // CalculateVisibleUploads uses the given commit graph and the tip of non-stale branches and tags to determine the
 // set of LSIF uploads that are visible for each commit, and the set of uploads which are visible at the tip of a
-// non-stale branch or tag. The decorated commit graph is serialized to Postgres for use by find closest dumps
-// queries.
+// non-stale branch or tag (or of a branch or tag retained by a retention policy). The decorated commit graph is
+// serialized to Postgres for use by find closest dumps queries.
 //
 // If dirtyToken is supplied, the repository will be unmarked when the supplied token does matches the most recent
 // token stored in the database, the flag will not be cleared as another request for update has come in since this
@@ -232,6 +232,13 @@ func (s *Store) CalculateVisibleUploads(
 		log.String("maxAgeForNonStaleTags", maxAgeForNonStaleTags.String()),
 	)
 
+	// Determine the retention policies that apply to this repository. Uploads visible from the
+	// tips of branches and tags retained by a policy are tracked even if the ref is stale.
+	policies, err := retentionPolicyMatcherForRepository(ctx, tx, repositoryID)
+	if err != nil {
+		return err
+	}
+
 	// Pull all queryable upload metadata known to this repository so we can correlate
 	// it with the current  commit graph.
 	commitGraphView, err := scanCommitGraphView(tx.Store.Query(ctx, sqlf.Sprintf(calculateVisibleUploadsCommitGraphQuery, repositoryID)))
@@ -247,7 +254,7 @@ func (s *Store) CalculateVisibleUploads(
 	graph := commitgraph.NewGraph(commitGraph, commitGraphView)
 
 	// Write the graph into temporary tables in Postgres
-	if err := tx.writeVisibleUploads(ctx, sanitizeCommitInput(ctx, graph, refDescriptions, maxAgeForNonStaleBranches, maxAgeForNonStaleTags)); err != nil {
+	if err := tx.writeVisibleUploads(ctx, sanitizeCommitInput(ctx, graph, refDescriptions, maxAgeForNonStaleBranches, maxAgeForNonStaleTags, policies)); err != nil {
 		return err
 	}
 
@@ -342,6 +349,30 @@ FROM lsif_retention_configuration
 WHERE repository_id = %s
 `
 
+// retentionPolicyMatcherForRepository returns a matcher for the retention policies that apply to the
+// given repository.
+func retentionPolicyMatcherForRepository(ctx context.Context, store *Store, repositoryID int) (*RetentionPolicyMatcher, error) {
+	policies, err := store.GetRetentionPolicies(ctx)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+
+	repositoryName, err := store.RepoName(ctx, repositoryID)
+	if err != nil {
+		if err == ErrUnknownRepository {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	matcher, err := NewRetentionPolicyMatcher(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return matcher.ForRepository(repositoryName), nil
+}
+
 // writeVisibleUploads serializes the given input into a the following set of temporary tables in the database.
 //
 //   - t_lsif_nearest_uploads        (mirroring lsif_nearest_uploads)
@@ -728,6 +759,7 @@ func sanitizeCommitInput(
 	refDescriptions map[string]gitserver.RefDescription,
 	maxAgeForNonStaleBranches time.Duration,
 	maxAgeForNonStaleTags time.Duration,
+	policies *RetentionPolicyMatcher,
 ) *sanitizedCommitInput {
 	maxAges := map[gitserver.RefType]time.Duration{
 		gitserver.RefTypeBranch: maxAgeForNonStaleBranches,
@@ -781,7 +813,7 @@ func sanitizeCommitInput(
 		}
 
 		for commit, refDescription := range refDescriptions {
-			if !refDescription.IsDefaultBranch {
+			if !refDescription.IsDefaultBranch && !policies.RetainsRef(refDescription, time.Now()) {
 				maxAge, ok := maxAges[refDescription.Type]
 				if !ok || time.Since(refDescription.CreatedDate) > maxAge {
 					continue
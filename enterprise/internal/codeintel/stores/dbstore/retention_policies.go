@@ -0,0 +1,602 @@
+package dbstore
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/gobwas/glob"
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+// RetentionPolicy determines how long code intelligence data visible from the tip of a set of branches
+// or tags is kept, and whether or not those branches and tags are automatically indexed. A policy applies
+// to the branches and tags whose names match its ref pattern within repositories whose names match its
+// repository pattern.
+type RetentionPolicy struct {
+	ID                int
+	Name              string
+	RepositoryPattern string
+	RefPattern        string
+	MaxAge            *time.Duration
+	KeepMostRecent    *int
+	IndexingEnabled   bool
+	CreatedAt         time.Time
+}
+
+// scanRetentionPolicies scans a slice of retention policies from the return value of `*Store.query`.
+func scanRetentionPolicies(rows *sql.Rows, queryErr error) (_ []RetentionPolicy, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var policies []RetentionPolicy
+	for rows.Next() {
+		var policy RetentionPolicy
+		var maxAgeSeconds *int
+		if err := rows.Scan(
+			&policy.ID,
+			&policy.Name,
+			&policy.RepositoryPattern,
+			&policy.RefPattern,
+			&maxAgeSeconds,
+			&policy.KeepMostRecent,
+			&policy.IndexingEnabled,
+			&policy.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if maxAgeSeconds != nil {
+			maxAge := time.Duration(*maxAgeSeconds) * time.Second
+			policy.MaxAge = &maxAge
+		}
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// scanFirstRetentionPolicy scans a slice of retention policies from the return value of `*Store.query`
+// and returns the first.
+func scanFirstRetentionPolicy(rows *sql.Rows, err error) (RetentionPolicy, bool, error) {
+	policies, err := scanRetentionPolicies(rows, err)
+	if err != nil || len(policies) == 0 {
+		return RetentionPolicy{}, false, err
+	}
+	return policies[0], true, nil
+}
+
+// GetRetentionPolicies returns all retention policies ordered by identifier.
+func (s *Store) GetRetentionPolicies(ctx context.Context) (_ []RetentionPolicy, err error) {
+	ctx, traceLog, endObservation := s.operations.getRetentionPolicies.WithAndLogger(ctx, &err, observation.Args{})
+	defer endObservation(1, observation.Args{})
+
+	policies, err := scanRetentionPolicies(s.Store.Query(ctx, sqlf.Sprintf(getRetentionPoliciesQuery)))
+	if err != nil {
+		return nil, err
+	}
+	traceLog(log.Int("numPolicies", len(policies)))
+
+	return policies, nil
+}
+
+const getRetentionPoliciesQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/retention_policies.go:GetRetentionPolicies
+SELECT
+	p.id,
+	p.name,
+	p.repository_pattern,
+	p.ref_pattern,
+	p.max_age_seconds,
+	p.keep_most_recent,
+	p.indexing_enabled,
+	p.created_at
+FROM lsif_retention_policies p
+ORDER BY p.id
+`
+
+// ValidateRetentionPolicy returns an error if the patterns of the given policy are not valid globs or if
+// its limits are not positive.
+func ValidateRetentionPolicy(policy RetentionPolicy) error {
+	if policy.Name == "" {
+		return errors.New("retention policy name must not be empty")
+	}
+	if _, err := glob.Compile(policy.RepositoryPattern); err != nil {
+		return errors.Wrap(err, "invalid repository pattern")
+	}
+	if policy.RefPattern == "" {
+		return errors.New("retention policy ref pattern must not be empty")
+	}
+	if _, err := glob.Compile(policy.RefPattern); err != nil {
+		return errors.Wrap(err, "invalid ref pattern")
+	}
+	if policy.MaxAge != nil && *policy.MaxAge < time.Second {
+		return errors.New("retention policy max age must be at least one second")
+	}
+	if policy.KeepMostRecent != nil && *policy.KeepMostRecent <= 0 {
+		return errors.New("retention policy must keep a positive number of uploads")
+	}
+
+	return nil
+}
+
+// CreateRetentionPolicy inserts the given retention policy and returns the stored record. An empty
+// repository pattern matches every repository.
+func (s *Store) CreateRetentionPolicy(ctx context.Context, policy RetentionPolicy) (_ RetentionPolicy, err error) {
+	ctx, endObservation := s.operations.createRetentionPolicy.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.String("name", policy.Name),
+		log.String("repositoryPattern", policy.RepositoryPattern),
+		log.String("refPattern", policy.RefPattern),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	if policy.RepositoryPattern == "" {
+		policy.RepositoryPattern = "*"
+	}
+	if err := ValidateRetentionPolicy(policy); err != nil {
+		return RetentionPolicy{}, err
+	}
+
+	var maxAgeSeconds *int
+	if policy.MaxAge != nil {
+		seconds := int(*policy.MaxAge / time.Second)
+		maxAgeSeconds = &seconds
+	}
+
+	created, _, err := scanFirstRetentionPolicy(s.Store.Query(ctx, sqlf.Sprintf(
+		createRetentionPolicyQuery,
+		policy.Name,
+		policy.RepositoryPattern,
+		policy.RefPattern,
+		maxAgeSeconds,
+		policy.KeepMostRecent,
+		policy.IndexingEnabled,
+	)))
+	return created, err
+}
+
+const createRetentionPolicyQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/retention_policies.go:CreateRetentionPolicy
+INSERT INTO lsif_retention_policies (name, repository_pattern, ref_pattern, max_age_seconds, keep_most_recent, indexing_enabled)
+VALUES (%s, %s, %s, %s, %s, %s)
+RETURNING id, name, repository_pattern, ref_pattern, max_age_seconds, keep_most_recent, indexing_enabled, created_at
+`
+
+// DeleteRetentionPolicyByID deletes the retention policy with the given identifier. This method returns
+// true if the policy existed.
+func (s *Store) DeleteRetentionPolicyByID(ctx context.Context, id int) (_ bool, err error) {
+	ctx, endObservation := s.operations.deleteRetentionPolicyByID.With(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("id", id),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	_, exists, err := basestore.ScanFirstInt(s.Store.Query(ctx, sqlf.Sprintf(deleteRetentionPolicyByIDQuery, id)))
+	return exists, err
+}
+
+const deleteRetentionPolicyByIDQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/retention_policies.go:DeleteRetentionPolicyByID
+DELETE FROM lsif_retention_policies WHERE id = %s RETURNING id
+`
+
+// UploadsExpiredByRetentionPolicies returns the identifiers of the completed uploads that are expired by
+// the configured retention policies. If repositoryID is zero, uploads of all repositories are considered.
+//
+// Retention policies only apply to uploads visible from the tip of a branch or tag. An upload is expired
+// when each branch or tag from which it is visible is matched by a policy, and none of those policies
+// retain it. Uploads visible from the tip of the default branch are never expired by a policy, and all
+// other uploads are subject to the global expiration rules. Expired uploads that define a package which
+// is referenced by a non-expired upload are kept so that cross-repository queries remain intact.
+func (s *Store) UploadsExpiredByRetentionPolicies(ctx context.Context, repositoryID int, now time.Time) (_ []int, err error) {
+	ctx, traceLog, endObservation := s.operations.uploadsExpiredByRetentionPolicies.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("repositoryID", repositoryID),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	policies, err := s.GetRetentionPolicies(ctx)
+	if err != nil || len(policies) == 0 {
+		return nil, err
+	}
+	matcher, err := NewRetentionPolicyMatcher(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	conds := []*sqlf.Query{sqlf.Sprintf("u.state = 'completed'"), sqlf.Sprintf("r.deleted_at IS NULL")}
+	if repositoryID != 0 {
+		conds = append(conds, sqlf.Sprintf("u.repository_id = %s", repositoryID))
+	}
+
+	candidates, err := scanRetentionCandidates(s.Store.Query(ctx, sqlf.Sprintf(retentionCandidatesQuery, sqlf.Join(conds, " AND "))))
+	if err != nil {
+		return nil, err
+	}
+	traceLog(log.Int("numCandidates", len(candidates)))
+
+	expiredIDs := matcher.expiredUploads(candidates, now)
+	if len(expiredIDs) == 0 {
+		return nil, nil
+	}
+
+	idQueries := make([]*sqlf.Query, 0, len(expiredIDs))
+	for _, id := range expiredIDs {
+		idQueries = append(idQueries, sqlf.Sprintf("%s", id))
+	}
+	ids := sqlf.Join(idQueries, ", ")
+
+	expiredIDs, err = basestore.ScanInts(s.Store.Query(ctx, sqlf.Sprintf(unreferencedExpiredUploadsQuery, ids, ids)))
+	if err != nil {
+		return nil, err
+	}
+	traceLog(log.Int("numExpired", len(expiredIDs)))
+
+	return expiredIDs, nil
+}
+
+const retentionCandidatesQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/retention_policies.go:UploadsExpiredByRetentionPolicies
+SELECT
+	u.id,
+	u.repository_id,
+	r.name,
+	u.root,
+	u.indexer,
+	COALESCE(u.finished_at, u.uploaded_at),
+	vt.branch_or_tag_name,
+	vt.is_default_branch
+FROM lsif_uploads_visible_at_tip vt
+JOIN lsif_uploads u ON u.id = vt.upload_id
+JOIN repo r ON r.id = u.repository_id
+WHERE %s
+ORDER BY u.id
+`
+
+const unreferencedExpiredUploadsQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/retention_policies.go:UploadsExpiredByRetentionPolicies
+SELECT u.id
+FROM lsif_uploads u
+WHERE u.id IN (%s) AND NOT EXISTS (
+	SELECT 1
+	FROM lsif_packages p
+	JOIN lsif_references r ON r.scheme = p.scheme AND r.name = p.name AND r.version = p.version AND r.dump_id != p.dump_id
+	JOIN lsif_uploads d ON d.id = r.dump_id
+	WHERE p.dump_id = u.id AND d.state = 'completed' AND d.id NOT IN (%s)
+)
+ORDER BY u.id
+`
+
+// SoftDeleteUploadsExpiredByRetentionPolicies marks the uploads expired by the configured retention policies
+// as deleted. The associated repositories will be marked as dirty so that their commit graphs are updated in
+// the background.
+func (s *Store) SoftDeleteUploadsExpiredByRetentionPolicies(ctx context.Context, now time.Time) (count int, err error) {
+	ctx, traceLog, endObservation := s.operations.softDeleteUploadsExpiredByRetentionPolicies.WithAndLogger(ctx, &err, observation.Args{})
+	defer endObservation(1, observation.Args{})
+
+	tx, err := s.transact(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { err = tx.Done(err) }()
+
+	ids, err := tx.UploadsExpiredByRetentionPolicies(ctx, 0, now)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	idQueries := make([]*sqlf.Query, 0, len(ids))
+	for _, id := range ids {
+		idQueries = append(idQueries, sqlf.Sprintf("%s", id))
+	}
+
+	repositories, err := scanCounts(tx.Store.Query(ctx, sqlf.Sprintf(softDeleteUploadsExpiredByRetentionPoliciesQuery, sqlf.Join(idQueries, ", "))))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, numUpdated := range repositories {
+		count += numUpdated
+	}
+	traceLog(
+		log.Int("count", count),
+		log.Int("numRepositories", len(repositories)),
+	)
+
+	for repositoryID := range repositories {
+		if err := tx.MarkRepositoryAsDirty(ctx, repositoryID); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+const softDeleteUploadsExpiredByRetentionPoliciesQuery = `
+-- source: enterprise/internal/codeintel/stores/dbstore/retention_policies.go:SoftDeleteUploadsExpiredByRetentionPolicies
+WITH
+candidates AS (
+	SELECT u.id
+	FROM lsif_uploads u
+	WHERE u.id IN (%s) AND u.state = 'completed'
+
+	-- Lock these rows in a deterministic order so that we don't
+	-- deadlock with other processes updating the lsif_uploads table.
+	ORDER BY u.id FOR UPDATE
+),
+updated AS (
+	UPDATE lsif_uploads u
+	SET state = 'deleting'
+	WHERE u.id IN (SELECT id FROM candidates)
+	RETURNING u.id, u.repository_id
+)
+SELECT u.repository_id, count(*) FROM updated u GROUP BY u.repository_id
+`
+
+// retentionCandidate is an upload visible from the tip of a particular branch or tag. An upload visible
+// from the tips of several branches or tags is represented by several candidates.
+type retentionCandidate struct {
+	UploadID        int
+	RepositoryID    int
+	RepositoryName  string
+	Root            string
+	Indexer         string
+	UploadedAt      time.Time
+	RefName         string
+	IsDefaultBranch bool
+}
+
+// scanRetentionCandidates scans a slice of retention candidates from the return value of `*Store.query`.
+func scanRetentionCandidates(rows *sql.Rows, queryErr error) (_ []retentionCandidate, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var candidates []retentionCandidate
+	for rows.Next() {
+		var candidate retentionCandidate
+		if err := rows.Scan(
+			&candidate.UploadID,
+			&candidate.RepositoryID,
+			&candidate.RepositoryName,
+			&candidate.Root,
+			&candidate.Indexer,
+			&candidate.UploadedAt,
+			&candidate.RefName,
+			&candidate.IsDefaultBranch,
+		); err != nil {
+			return nil, err
+		}
+
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, nil
+}
+
+// RetentionPolicyMatcher matches repository, branch, and tag names against a set of retention policies.
+type RetentionPolicyMatcher struct {
+	policies []compiledRetentionPolicy
+}
+
+type compiledRetentionPolicy struct {
+	RetentionPolicy
+	repositoryGlob glob.Glob
+	refGlob        glob.Glob
+}
+
+// NewRetentionPolicyMatcher compiles the patterns of the given retention policies.
+func NewRetentionPolicyMatcher(policies []RetentionPolicy) (*RetentionPolicyMatcher, error) {
+	compiled := make([]compiledRetentionPolicy, 0, len(policies))
+	for _, policy := range policies {
+		repositoryGlob, err := glob.Compile(policy.RepositoryPattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid repository pattern for retention policy %d", policy.ID)
+		}
+		refGlob, err := glob.Compile(policy.RefPattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ref pattern for retention policy %d", policy.ID)
+		}
+
+		compiled = append(compiled, compiledRetentionPolicy{
+			RetentionPolicy: policy,
+			repositoryGlob:  repositoryGlob,
+			refGlob:         refGlob,
+		})
+	}
+
+	return &RetentionPolicyMatcher{policies: compiled}, nil
+}
+
+// ForRepository returns a matcher containing only the policies that apply to the given repository.
+func (m *RetentionPolicyMatcher) ForRepository(repositoryName string) *RetentionPolicyMatcher {
+	var policies []compiledRetentionPolicy
+	if m != nil {
+		for _, policy := range m.policies {
+			if policy.repositoryGlob.Match(repositoryName) {
+				policies = append(policies, policy)
+			}
+		}
+	}
+
+	return &RetentionPolicyMatcher{policies: policies}
+}
+
+// Empty returns true if the matcher has no policies.
+func (m *RetentionPolicyMatcher) Empty() bool {
+	return m == nil || len(m.policies) == 0
+}
+
+// RetainsRef returns true if a policy matches the given branch or tag and the ref has not been modified
+// for longer than that policy's max age. The uploads visible from the tip of such a ref are tracked even
+// when the ref would otherwise be considered stale.
+func (m *RetentionPolicyMatcher) RetainsRef(ref gitserver.RefDescription, now time.Time) bool {
+	if m == nil {
+		return false
+	}
+
+	for _, policy := range m.policies {
+		if policy.refGlob.Match(ref.Name) && (policy.MaxAge == nil || now.Sub(ref.CreatedDate) <= *policy.MaxAge) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RefsToIndex returns the branches and tags matched by a policy with indexing enabled. The refs matched by
+// a policy are limited by its max age and to its number of most recently modified refs. The result is
+// ordered by name.
+func (m *RetentionPolicyMatcher) RefsToIndex(refs []gitserver.RefDescription, now time.Time) []gitserver.RefDescription {
+	if m == nil {
+		return nil
+	}
+
+	selected := map[string]gitserver.RefDescription{}
+	for _, policy := range m.policies {
+		if !policy.IndexingEnabled {
+			continue
+		}
+
+		var matching []gitserver.RefDescription
+		for _, ref := range refs {
+			if !policy.refGlob.Match(ref.Name) {
+				continue
+			}
+			if policy.MaxAge != nil && now.Sub(ref.CreatedDate) > *policy.MaxAge {
+				continue
+			}
+
+			matching = append(matching, ref)
+		}
+
+		sort.Slice(matching, func(i, j int) bool {
+			if !matching[i].CreatedDate.Equal(matching[j].CreatedDate) {
+				return matching[i].CreatedDate.After(matching[j].CreatedDate)
+			}
+			return matching[i].Name < matching[j].Name
+		})
+		if policy.KeepMostRecent != nil && len(matching) > *policy.KeepMostRecent {
+			matching = matching[:*policy.KeepMostRecent]
+		}
+
+		for _, ref := range matching {
+			selected[ref.Name] = ref
+		}
+	}
+
+	flattened := make([]gitserver.RefDescription, 0, len(selected))
+	for _, ref := range selected {
+		flattened = append(flattened, ref)
+	}
+	sort.Slice(flattened, func(i, j int) bool {
+		return flattened[i].Name < flattened[j].Name
+	})
+
+	return flattened
+}
+
+// expiredUploads returns the sorted identifiers of the uploads in the given candidate set which are not
+// retained by the policies of this matcher. See UploadsExpiredByRetentionPolicies for the semantics.
+//
+// For the purposes of max age and "keep N most recent", the uploads matched by a policy are grouped by
+// repository, root, and indexer and ordered by the time the upload was processed.
+func (m *RetentionPolicyMatcher) expiredUploads(candidates []retentionCandidate, now time.Time) []int {
+	type groupKey struct {
+		policyID     int
+		repositoryID int
+		root         string
+		indexer      string
+	}
+
+	var (
+		matchers   = map[int]*RetentionPolicyMatcher{}
+		policies   = map[int]compiledRetentionPolicy{}
+		groups     = map[groupKey][]retentionCandidate{}
+		seen       = map[int]struct{}{}
+		unmatched  = map[int]struct{}{}
+		retained   = map[int]struct{}{}
+		expiredIDs []int
+	)
+
+	for _, candidate := range candidates {
+		seen[candidate.UploadID] = struct{}{}
+
+		matcher, ok := matchers[candidate.RepositoryID]
+		if !ok {
+			matcher = m.ForRepository(candidate.RepositoryName)
+			matchers[candidate.RepositoryID] = matcher
+		}
+
+		matched := false
+		if !candidate.IsDefaultBranch && candidate.RefName != "" {
+			for _, policy := range matcher.policies {
+				if !policy.refGlob.Match(candidate.RefName) {
+					continue
+				}
+
+				matched = true
+				policies[policy.ID] = policy
+				key := groupKey{policy.ID, candidate.RepositoryID, candidate.Root, candidate.Indexer}
+				groups[key] = append(groups[key], candidate)
+			}
+		}
+
+		if !matched {
+			// Visible from the default branch or from a ref not covered by any policy
+			unmatched[candidate.UploadID] = struct{}{}
+		}
+	}
+
+	for key, group := range groups {
+		policy := policies[key.policyID]
+
+		sort.Slice(group, func(i, j int) bool {
+			if !group[i].UploadedAt.Equal(group[j].UploadedAt) {
+				return group[i].UploadedAt.After(group[j].UploadedAt)
+			}
+			return group[i].UploadID > group[j].UploadID
+		})
+
+		rank := 0
+		for i, candidate := range group {
+			if i > 0 && group[i-1].UploadID == candidate.UploadID {
+				// Same upload visible from multiple matching refs
+				continue
+			}
+			rank++
+
+			if policy.MaxAge != nil && now.Sub(candidate.UploadedAt) > *policy.MaxAge {
+				continue
+			}
+			if policy.KeepMostRecent != nil && rank > *policy.KeepMostRecent {
+				continue
+			}
+
+			retained[candidate.UploadID] = struct{}{}
+		}
+	}
+
+	for id := range seen {
+		if _, ok := unmatched[id]; ok {
+			continue
+		}
+		if _, ok := retained[id]; ok {
+			continue
+		}
+
+		expiredIDs = append(expiredIDs, id)
+	}
+	sort.Ints(expiredIDs)
+
+	return expiredIDs
+}
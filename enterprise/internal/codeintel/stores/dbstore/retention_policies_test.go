@@ -0,0 +1,334 @@
+package dbstore
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+func TestCreateAndDeleteRetentionPolicies(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	store := testStore(db)
+
+	maxAge := time.Hour * 24 * 365
+	keepMostRecent := 5
+
+	created, err := store.CreateRetentionPolicy(context.Background(), RetentionPolicy{
+		Name:            "releases",
+		RefPattern:      "release/*",
+		MaxAge:          &maxAge,
+		KeepMostRecent:  &keepMostRecent,
+		IndexingEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating retention policy: %s", err)
+	}
+	if _, err := store.CreateRetentionPolicy(context.Background(), RetentionPolicy{Name: "invalid", RefPattern: "[release"}); err == nil {
+		t.Fatalf("expected error creating retention policy with an invalid pattern")
+	}
+
+	policies, err := store.GetRetentionPolicies(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting retention policies: %s", err)
+	}
+	expectedPolicies := []RetentionPolicy{
+		{
+			ID:                created.ID,
+			Name:              "releases",
+			RepositoryPattern: "*",
+			RefPattern:        "release/*",
+			MaxAge:            &maxAge,
+			KeepMostRecent:    &keepMostRecent,
+			IndexingEnabled:   true,
+			CreatedAt:         created.CreatedAt,
+		},
+	}
+	if diff := cmp.Diff(expectedPolicies, policies); diff != "" {
+		t.Errorf("unexpected retention policies (-want +got):\n%s", diff)
+	}
+
+	if exists, err := store.DeleteRetentionPolicyByID(context.Background(), created.ID); err != nil {
+		t.Fatalf("unexpected error deleting retention policy: %s", err)
+	} else if !exists {
+		t.Fatalf("expected retention policy to exist")
+	}
+	if exists, err := store.DeleteRetentionPolicyByID(context.Background(), created.ID); err != nil {
+		t.Fatalf("unexpected error deleting retention policy: %s", err)
+	} else if exists {
+		t.Fatalf("unexpected retention policy")
+	}
+}
+
+func TestSoftDeleteUploadsExpiredByRetentionPolicies(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	store := testStore(db)
+
+	t1 := time.Unix(1587396557, 0).UTC()
+	t2 := t1.Add(time.Hour * 24)
+	t3 := t1.Add(time.Hour * 24 * 2)
+	now := t1.Add(time.Hour * 24 * 3)
+
+	insertUploads(t, db,
+		Upload{ID: 11, RepositoryName: "github.com/foo/bar", FinishedAt: &t1},                    // default branch
+		Upload{ID: 12, RepositoryName: "github.com/foo/bar", FinishedAt: &t1},                    // expired pull request
+		Upload{ID: 13, RepositoryName: "github.com/foo/bar", FinishedAt: &t3},                    // recent pull request
+		Upload{ID: 14, RepositoryName: "github.com/foo/bar", FinishedAt: &t1},                    // pull request and unmatched branch
+		Upload{ID: 15, RepositoryName: "github.com/foo/bar", FinishedAt: &t1},                    // expired pull request, dependency of 13
+		Upload{ID: 16, RepositoryName: "github.com/foo/bar", FinishedAt: &t1},                    // old tag
+		Upload{ID: 17, RepositoryName: "github.com/foo/bar", FinishedAt: &t2},                    // recent tag
+		Upload{ID: 18, RepositoryName: "github.com/foo/bar", FinishedAt: &t3},                    // most recent tag
+		Upload{ID: 19, RepositoryID: 51, RepositoryName: "github.com/baz/bonk", FinishedAt: &t1}, // unmatched repository
+	)
+	insertVisibleAtTip(t, db, 50, 11)
+	insertVisibleAtTipRefs(t, db, map[int][]string{
+		12: {"pr/12"},
+		13: {"pr/13"},
+		14: {"pr/14", "feature"},
+		15: {"pr/15"},
+		16: {"v1.0.0"},
+		17: {"v1.1.0"},
+		18: {"v1.2.0"},
+		19: {"pr/19"},
+	})
+
+	if err := store.UpdatePackages(context.Background(), 15, []semantic.Package{{Scheme: "s", Name: "n", Version: "v"}}); err != nil {
+		t.Fatalf("unexpected error updating packages: %s", err)
+	}
+	if err := store.UpdatePackageReferences(context.Background(), 13, []semantic.PackageReference{{Package: semantic.Package{Scheme: "s", Name: "n", Version: "v"}}}); err != nil {
+		t.Fatalf("unexpected error updating package references: %s", err)
+	}
+
+	maxAge := time.Hour * 24
+	keepMostRecent := 2
+	for _, policy := range []RetentionPolicy{
+		{Name: "pull requests", RepositoryPattern: "github.com/foo/*", RefPattern: "pr/*", MaxAge: &maxAge},
+		{Name: "tags", RepositoryPattern: "github.com/foo/*", RefPattern: "v*", KeepMostRecent: &keepMostRecent},
+	} {
+		if _, err := store.CreateRetentionPolicy(context.Background(), policy); err != nil {
+			t.Fatalf("unexpected error creating retention policy: %s", err)
+		}
+	}
+
+	expired, err := store.UploadsExpiredByRetentionPolicies(context.Background(), 50, now)
+	if err != nil {
+		t.Fatalf("unexpected error listing expired uploads: %s", err)
+	}
+	if diff := cmp.Diff([]int{12, 16}, expired); diff != "" {
+		t.Errorf("unexpected expired uploads (-want +got):\n%s", diff)
+	}
+
+	if count, err := store.SoftDeleteUploadsExpiredByRetentionPolicies(context.Background(), now); err != nil {
+		t.Fatalf("unexpected error soft deleting uploads: %s", err)
+	} else if count != 2 {
+		t.Fatalf("unexpected number of uploads deleted: want=%d have=%d", 2, count)
+	}
+
+	expectedStates := map[int]string{
+		11: "completed",
+		12: "deleting",
+		13: "completed",
+		14: "completed",
+		15: "completed",
+		16: "deleting",
+		17: "completed",
+		18: "completed",
+		19: "completed",
+	}
+	if states, err := getUploadStates(db, 11, 12, 13, 14, 15, 16, 17, 18, 19); err != nil {
+		t.Fatalf("unexpected error getting states: %s", err)
+	} else if diff := cmp.Diff(expectedStates, states); diff != "" {
+		t.Errorf("unexpected upload states (-want +got):\n%s", diff)
+	}
+
+	repositoryIDs, err := store.DirtyRepositories(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error listing dirty repositories: %s", err)
+	}
+	if _, ok := repositoryIDs[50]; !ok {
+		t.Errorf("expected repository to be marked dirty")
+	}
+}
+
+func TestRetentionPolicyMatcherExpiredUploads(t *testing.T) {
+	now := time.Unix(1587396557, 0).UTC()
+	day := time.Hour * 24
+	maxAge := day * 7
+	keepMostRecent := 1
+
+	matcher, err := NewRetentionPolicyMatcher([]RetentionPolicy{
+		{ID: 1, RepositoryPattern: "github.com/foo/*", RefPattern: "pr/*", MaxAge: &maxAge},
+		{ID: 2, RepositoryPattern: "*", RefPattern: "release-*", KeepMostRecent: &keepMostRecent},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating matcher: %s", err)
+	}
+
+	candidate := func(uploadID int, repositoryName, root string, age time.Duration, refName string, isDefaultBranch bool) retentionCandidate {
+		repositoryID := 50
+		if repositoryName != "github.com/foo/bar" {
+			repositoryID = 51
+		}
+
+		return retentionCandidate{
+			UploadID:        uploadID,
+			RepositoryID:    repositoryID,
+			RepositoryName:  repositoryName,
+			Root:            root,
+			Indexer:         "lsif-go",
+			UploadedAt:      now.Add(-age),
+			RefName:         refName,
+			IsDefaultBranch: isDefaultBranch,
+		}
+	}
+
+	candidates := []retentionCandidate{
+		candidate(1, "github.com/foo/bar", "", day*30, "main", true),             // default branch
+		candidate(2, "github.com/foo/bar", "", day*30, "pr/2", false),            // expired by age
+		candidate(3, "github.com/foo/bar", "", day*2, "pr/3", false),             // young enough
+		candidate(4, "github.com/foo/bar", "", day*30, "pr/4", false),            // also visible from the default branch
+		candidate(4, "github.com/foo/bar", "", day*30, "main", true),             //
+		candidate(5, "github.com/foo/bar", "", day*30, "pr/5", false),            // also visible from an unmatched ref
+		candidate(5, "github.com/foo/bar", "", day*30, "feature", false),         //
+		candidate(6, "github.com/bar/baz", "", day*30, "pr/6", false),            // repository not matched
+		candidate(7, "github.com/foo/bar", "", day*30, "release-1.0", false),     // not most recent
+		candidate(8, "github.com/foo/bar", "", day*20, "release-1.1", false),     // most recent
+		candidate(9, "github.com/foo/bar", "sub/", day*30, "release-1.0", false), // most recent for root
+		candidate(10, "github.com/bar/baz", "", day*30, "release-2.0", false),    // most recent for repository
+		candidate(11, "github.com/foo/bar", "", day*40, "release-0.9", false),    // not most recent, old pull request
+		candidate(11, "github.com/foo/bar", "", day*40, "pr/11", false),          //
+		candidate(12, "github.com/foo/bar", "", day*40, "", false),               // unnamed ref
+	}
+
+	if diff := cmp.Diff([]int{2, 7, 11}, matcher.expiredUploads(candidates, now)); diff != "" {
+		t.Errorf("unexpected expired uploads (-want +got):\n%s", diff)
+	}
+}
+
+func TestRetentionPolicyMatcherRetainsRef(t *testing.T) {
+	now := time.Unix(1587396557, 0).UTC()
+	maxAge := time.Hour * 24 * 365
+
+	matcher, err := NewRetentionPolicyMatcher([]RetentionPolicy{
+		{ID: 1, RepositoryPattern: "github.com/foo/*", RefPattern: "release/*", MaxAge: &maxAge},
+		{ID: 2, RepositoryPattern: "github.com/bar/*", RefPattern: "*"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating matcher: %s", err)
+	}
+	matcher = matcher.ForRepository("github.com/foo/bar")
+
+	testCases := []struct {
+		ref      gitserver.RefDescription
+		expected bool
+	}{
+		{gitserver.RefDescription{Name: "release/1.0", CreatedDate: now.Add(-time.Hour)}, true},
+		{gitserver.RefDescription{Name: "release/0.1", CreatedDate: now.Add(-maxAge * 2)}, false},
+		{gitserver.RefDescription{Name: "feature", CreatedDate: now.Add(-time.Hour)}, false},
+	}
+
+	for _, testCase := range testCases {
+		if retained := matcher.RetainsRef(testCase.ref, now); retained != testCase.expected {
+			t.Errorf("unexpected result for %s. want=%v have=%v", testCase.ref.Name, testCase.expected, retained)
+		}
+	}
+
+	var nilMatcher *RetentionPolicyMatcher
+	if nilMatcher.RetainsRef(testCases[0].ref, now) {
+		t.Errorf("expected nil matcher to retain no refs")
+	}
+}
+
+func TestRetentionPolicyMatcherRefsToIndex(t *testing.T) {
+	now := time.Unix(1587396557, 0).UTC()
+	day := time.Hour * 24
+	maxAge := day * 30
+	keepMostRecent := 2
+
+	matcher, err := NewRetentionPolicyMatcher([]RetentionPolicy{
+		{ID: 1, RepositoryPattern: "*", RefPattern: "v*", KeepMostRecent: &keepMostRecent, IndexingEnabled: true},
+		{ID: 2, RepositoryPattern: "*", RefPattern: "release/*", MaxAge: &maxAge, IndexingEnabled: true},
+		{ID: 3, RepositoryPattern: "*", RefPattern: "*"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating matcher: %s", err)
+	}
+
+	refs := []gitserver.RefDescription{
+		{Name: "main", Type: gitserver.RefTypeBranch, IsDefaultBranch: true, CreatedDate: now},
+		{Name: "release/1.0", Type: gitserver.RefTypeBranch, CreatedDate: now.Add(-day * 60)},
+		{Name: "release/1.1", Type: gitserver.RefTypeBranch, CreatedDate: now.Add(-day * 10)},
+		{Name: "v1.0.0", Type: gitserver.RefTypeTag, CreatedDate: now.Add(-day * 60)},
+		{Name: "v1.1.0", Type: gitserver.RefTypeTag, CreatedDate: now.Add(-day * 10)},
+		{Name: "v1.2.0", Type: gitserver.RefTypeTag, CreatedDate: now.Add(-day * 5)},
+	}
+
+	var names []string
+	for _, ref := range matcher.RefsToIndex(refs, now) {
+		names = append(names, ref.Name)
+	}
+	if diff := cmp.Diff([]string{"release/1.1", "v1.1.0", "v1.2.0"}, names); diff != "" {
+		t.Errorf("unexpected refs (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateRetentionPolicy(t *testing.T) {
+	zero := 0
+	tooYoung := time.Millisecond
+
+	testCases := []struct {
+		policy  RetentionPolicy
+		isValid bool
+	}{
+		{RetentionPolicy{Name: "all", RepositoryPattern: "*", RefPattern: "*"}, true},
+		{RetentionPolicy{Name: "", RepositoryPattern: "*", RefPattern: "*"}, false},
+		{RetentionPolicy{Name: "no refs", RepositoryPattern: "*", RefPattern: ""}, false},
+		{RetentionPolicy{Name: "bad repos", RepositoryPattern: "[foo", RefPattern: "*"}, false},
+		{RetentionPolicy{Name: "bad refs", RepositoryPattern: "*", RefPattern: "release/[1"}, false},
+		{RetentionPolicy{Name: "keep none", RepositoryPattern: "*", RefPattern: "*", KeepMostRecent: &zero}, false},
+		{RetentionPolicy{Name: "too young", RepositoryPattern: "*", RefPattern: "*", MaxAge: &tooYoung}, false},
+	}
+
+	for _, testCase := range testCases {
+		if err := ValidateRetentionPolicy(testCase.policy); (err == nil) != testCase.isValid {
+			t.Errorf("unexpected validation result for %q. want valid=%v have error=%v", testCase.policy.Name, testCase.isValid, err)
+		}
+	}
+}
+
+// insertVisibleAtTipRefs populates rows of the lsif_uploads_visible_at_tip table with the given uploads
+// visible from the tips of the given (non-default) branches or tags.
+func insertVisibleAtTipRefs(t testing.TB, db *sql.DB, refs map[int][]string) {
+	var rows []*sqlf.Query
+	for uploadID, refNames := range refs {
+		for _, refName := range refNames {
+			rows = append(rows, sqlf.Sprintf(
+				"((SELECT repository_id FROM lsif_uploads WHERE id = %s), %s, %s, false)",
+				uploadID,
+				uploadID,
+				refName,
+			))
+		}
+	}
+
+	query := sqlf.Sprintf(
+		`INSERT INTO lsif_uploads_visible_at_tip (repository_id, upload_id, branch_or_tag_name, is_default_branch) VALUES %s`,
+		sqlf.Join(rows, ","),
+	)
+	if _, err := db.ExecContext(context.Background(), query.Query(sqlf.PostgresBindVar), query.Args()...); err != nil {
+		t.Fatalf("unexpected error while updating uploads visible at tip: %s", err)
+	}
+}
@@ -0,0 +1,148 @@
+package lsifstore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+// CallableDefinitions returns the definition sites of the function-like symbols within the given
+// document, ordered by position.
+//
+// LSIF does not encode the extent of a symbol's body, so both properties are approximated. A range
+// is a definition site if it is among the locations of its own definition result, and the defined
+// symbol is considered callable if the signature in its hover text contains a parameter list. The
+// extent of each definition ends (exclusively) where the next callable definition begins, so code
+// following a nested callable definition is attributed to the nested definition.
+func (s *Store) CallableDefinitions(ctx context.Context, bundleID int, path string) (_ []CallableDefinition, err error) {
+	ctx, traceLog, endObservation := s.operations.callableDefinitions.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("bundleID", bundleID),
+		log.String("path", path),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	documentData, exists, err := s.scanFirstDocumentData(s.Store.Query(ctx, sqlf.Sprintf(callableDefinitionsDocumentQuery, bundleID, path)))
+	if err != nil || !exists {
+		return nil, err
+	}
+	traceLog(log.Int("numRanges", len(documentData.Document.Ranges)))
+
+	var (
+		ranges = make([]semantic.RangeData, 0, len(documentData.Document.Ranges))
+		end    Position
+	)
+	for _, r := range documentData.Document.Ranges {
+		if !IsCallableHoverText(documentData.Document.HoverResults[r.HoverResultID]) {
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+	for _, r := range documentData.Document.Ranges {
+		if r.EndLine+1 > end.Line {
+			// Extents end exclusively; the last extent covers the remainder of the document
+			end = Position{Line: r.EndLine + 1}
+		}
+	}
+
+	definitionResultIDs := extractResultIDs(ranges, func(r semantic.RangeData) semantic.ID { return r.DefinitionResultID })
+	definitionLocations, err := s.locationsWithinFile(ctx, bundleID, definitionResultIDs, path, documentData.Document)
+	if err != nil {
+		return nil, err
+	}
+
+	var definitions []CallableDefinition
+	for _, r := range ranges {
+		rn := newRange(r.StartLine, r.StartCharacter, r.EndLine, r.EndCharacter)
+
+		for _, location := range definitionLocations[r.DefinitionResultID] {
+			if location.Range == rn {
+				definitions = append(definitions, CallableDefinition{
+					Range:     rn,
+					HoverText: documentData.Document.HoverResults[r.HoverResultID],
+				})
+				break
+			}
+		}
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return compareBundleRanges(definitions[i].Range, definitions[j].Range)
+	})
+	traceLog(log.Int("numCallableDefinitions", len(definitions)))
+
+	for i := range definitions {
+		extentEnd := end
+		if i+1 < len(definitions) {
+			extentEnd = definitions[i+1].Range.Start
+		}
+
+		definitions[i].Extent = Range{Start: definitions[i].Range.Start, End: extentEnd}
+	}
+
+	return definitions, nil
+}
+
+// ExtentContains returns true if the given position falls within the extent of the definition.
+func (d CallableDefinition) ExtentContains(position Position) bool {
+	return !comparePositions(position, d.Extent.Start) && comparePositions(position, d.Extent.End)
+}
+
+// comparePositions returns true if p1 occurs strictly before p2.
+func comparePositions(p1, p2 Position) bool {
+	if p1.Line != p2.Line {
+		return p1.Line < p2.Line
+	}
+
+	return p1.Character < p2.Character
+}
+
+// nonCallableSignaturePrefixes are the leading keywords of signatures that declare variables, fields,
+// and constants. The type of such a symbol may contain a parameter list (e.g. a field of function type)
+// without the symbol being a callable definition itself.
+var nonCallableSignaturePrefixes = []string{"var ", "const ", "let ", "field ", "(property) ", "(parameter) "}
+
+// IsCallableHoverText returns true if the signature of the given hover text declares a function,
+// method, or constructor. The signature is the first line of the first code block of the hover text.
+func IsCallableHoverText(text string) bool {
+	signature := strings.TrimSpace(text)
+	if strings.HasPrefix(signature, "```") {
+		if i := strings.Index(signature, "\n"); i >= 0 {
+			signature = signature[i+1:]
+		}
+	}
+	if i := strings.Index(signature, "\n"); i >= 0 {
+		signature = signature[:i]
+	}
+
+	for _, prefix := range nonCallableSignaturePrefixes {
+		if strings.HasPrefix(signature, prefix) {
+			return false
+		}
+	}
+
+	return strings.Contains(signature, "(")
+}
+
+const callableDefinitionsDocumentQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/callables.go:CallableDefinitions
+SELECT
+	dump_id,
+	path,
+	data,
+	ranges,
+	hovers,
+	NULL AS monikers,
+	NULL AS packages,
+	NULL AS diagnostics
+FROM
+	lsif_data_documents
+WHERE
+	dump_id = %s AND
+	path = %s
+LIMIT 1
+`
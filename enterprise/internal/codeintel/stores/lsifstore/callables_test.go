@@ -0,0 +1,99 @@
+package lsifstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestDatabaseCallableDefinitions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	populateTestStore(t)
+	store := NewStore(db, &observation.TestContext)
+
+	//   20: // NewWriter creates a new Writer.
+	//   21: func NewWriter(w io.Writer, addContents bool) *Writer {
+	//   22:     return &Writer{
+	//   ...
+	//   28: func (w *Writer) NumElements() int {
+
+	definitions, err := store.CallableDefinitions(context.Background(), testBundleID, "protocol/writer.go")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	for i, definition := range definitions {
+		if definition.Range != newRange(20, 5, 20, 14) {
+			continue
+		}
+		if i+1 >= len(definitions) {
+			t.Fatalf("expected a callable definition following NewWriter")
+		}
+
+		expectedNext := newRange(27, 17, 27, 28)
+		if diff := cmp.Diff(expectedNext, definitions[i+1].Range); diff != "" {
+			t.Errorf("unexpected next definition range (-want +got):\n%s", diff)
+		}
+
+		expectedExtent := Range{Start: Position{Line: 20, Character: 5}, End: Position{Line: 27, Character: 17}}
+		if diff := cmp.Diff(expectedExtent, definition.Extent); diff != "" {
+			t.Errorf("unexpected extent (-want +got):\n%s", diff)
+		}
+		return
+	}
+
+	t.Errorf("no callable definition found for NewWriter")
+}
+
+func TestIsCallableHoverText(t *testing.T) {
+	testCases := []struct {
+		text     string
+		callable bool
+	}{
+		{"```go\nfunc NewWriter(w io.Writer, addContents bool) *Writer\n```\n\n---\n\nNewWriter creates a new Writer.", true},
+		{"```go\nfunc (w *Writer) NumElements() int\n```", true},
+		{"```typescript\nfunction padLeft(value: string): string\n```", true},
+		{"```go\ntype Writer struct\n```\n\n---\n\n```go\nstruct {\n    w Writer\n}\n```", false},
+		{"```go\nvar fn func(x int) int\n```", false},
+		{"```go\nfield emit func() error\n```", false},
+		{"```typescript\n(property) onClose: () => void\n```", false},
+		{"", false},
+	}
+
+	for _, testCase := range testCases {
+		if callable := IsCallableHoverText(testCase.text); callable != testCase.callable {
+			t.Errorf("unexpected result for %q. want=%v have=%v", testCase.text, testCase.callable, callable)
+		}
+	}
+}
+
+func TestCallableDefinitionExtentContains(t *testing.T) {
+	definition := CallableDefinition{
+		Range:  newRange(20, 5, 20, 14),
+		Extent: Range{Start: Position{Line: 20, Character: 5}, End: Position{Line: 27, Character: 17}},
+	}
+
+	testCases := []struct {
+		position Position
+		contains bool
+	}{
+		{Position{Line: 20, Character: 4}, false},
+		{Position{Line: 20, Character: 5}, true},
+		{Position{Line: 24, Character: 0}, true},
+		{Position{Line: 27, Character: 16}, true},
+		{Position{Line: 27, Character: 17}, false},
+	}
+
+	for _, testCase := range testCases {
+		if contains := definition.ExtentContains(testCase.position); contains != testCase.contains {
+			t.Errorf("unexpected result for %v. want=%v have=%v", testCase.position, testCase.contains, contains)
+		}
+	}
+}
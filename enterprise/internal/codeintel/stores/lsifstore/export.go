@@ -0,0 +1,119 @@
+package lsifstore
+
+import (
+	"context"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+// Bundle reads the complete processed data of the given bundle: its metadata, documents, result
+// chunks, and moniker definitions and references. This is the inverse of the Write* methods, and
+// can be used to export a bundle whose original upload is no longer available (see the export
+// package in lib/codeintel/lsif). Package data is stored in the frontend database and is not
+// included. Documentation data is also not included.
+//
+// The entire bundle is held in memory, so this method should only be used for infrequent,
+// explicitly requested operations.
+func (s *Store) Bundle(ctx context.Context, bundleID int) (_ *semantic.GroupedBundleDataMaps, err error) {
+	ctx, traceLog, endObservation := s.operations.bundle.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("bundleID", bundleID),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	numResultChunks, exists, err := basestore.ScanFirstInt(s.Store.Query(ctx, sqlf.Sprintf(bundleMetadataQuery, bundleID)))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNoMetadata
+	}
+
+	documents := map[string]semantic.DocumentData{}
+	if err := s.makeDocumentVisitor(func(path string, document semantic.DocumentData) {
+		documents[path] = document
+	})(s.Store.Query(ctx, sqlf.Sprintf(bundleDocumentsQuery, bundleID))); err != nil {
+		return nil, err
+	}
+	traceLog(log.Int("numDocuments", len(documents)))
+
+	resultChunks := map[int]semantic.ResultChunkData{}
+	if err := s.makeResultChunkVisitor(s.Store.Query(ctx, sqlf.Sprintf(bundleResultChunksQuery, bundleID)))(func(index int, resultChunk semantic.ResultChunkData) {
+		resultChunks[index] = resultChunk
+	}); err != nil {
+		return nil, err
+	}
+	traceLog(log.Int("numResultChunks", len(resultChunks)))
+
+	definitions, err := s.readBundleMonikerLocations(ctx, bundleID, "lsif_data_definitions")
+	if err != nil {
+		return nil, err
+	}
+	references, err := s.readBundleMonikerLocations(ctx, bundleID, "lsif_data_references")
+	if err != nil {
+		return nil, err
+	}
+
+	return &semantic.GroupedBundleDataMaps{
+		Meta:         semantic.MetaData{NumResultChunks: numResultChunks},
+		Documents:    documents,
+		ResultChunks: resultChunks,
+		Definitions:  definitions,
+		References:   references,
+	}, nil
+}
+
+// readBundleMonikerLocations reads the moniker locations of the given bundle from the given
+// definitions or references table and returns them indexed by scheme and identifier.
+func (s *Store) readBundleMonikerLocations(ctx context.Context, bundleID int, tableName string) (map[string]map[string][]semantic.LocationData, error) {
+	monikerLocations, err := s.scanQualifiedMonikerLocations(s.Store.Query(ctx, sqlf.Sprintf(bundleMonikerLocationsQuery, sqlf.Sprintf(tableName), bundleID)))
+	if err != nil {
+		return nil, err
+	}
+
+	locations := map[string]map[string][]semantic.LocationData{}
+	for _, monikerLocation := range monikerLocations {
+		if _, ok := locations[monikerLocation.Scheme]; !ok {
+			locations[monikerLocation.Scheme] = map[string][]semantic.LocationData{}
+		}
+		locations[monikerLocation.Scheme][monikerLocation.Identifier] = monikerLocation.Locations
+	}
+
+	return locations, nil
+}
+
+const bundleMetadataQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/export.go:Bundle
+SELECT num_result_chunks FROM lsif_data_metadata WHERE dump_id = %s
+`
+
+const bundleDocumentsQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/export.go:Bundle
+SELECT
+	dump_id,
+	path,
+	data,
+	ranges,
+	hovers,
+	monikers,
+	packages,
+	diagnostics
+FROM
+	lsif_data_documents
+WHERE
+	dump_id = %s
+`
+
+const bundleResultChunksQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/export.go:Bundle
+SELECT idx, data FROM lsif_data_result_chunks WHERE dump_id = %s
+`
+
+const bundleMonikerLocationsQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/export.go:readBundleMonikerLocations
+SELECT dump_id, scheme, identifier, data FROM %s WHERE dump_id = %s
+`
@@ -0,0 +1,64 @@
+package lsifstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/export"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/validation"
+)
+
+func TestDatabaseBundle(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	populateTestStore(t)
+	store := NewStore(db, &observation.TestContext)
+
+	bundle, err := store.Bundle(context.Background(), testBundleID)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if bundle.Meta.NumResultChunks == 0 || len(bundle.ResultChunks) == 0 {
+		t.Fatalf("expected result chunks")
+	}
+	if _, ok := bundle.Documents["internal/index/indexer.go"]; !ok {
+		t.Fatalf("expected document internal/index/indexer.go")
+	}
+	if len(bundle.Definitions) == 0 || len(bundle.References) == 0 {
+		t.Fatalf("expected moniker definitions and references")
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteLSIF(&buf, bundle, "", protocol.ToolInfo{Name: "test"}); err != nil {
+		t.Fatalf("unexpected error writing LSIF: %s", err)
+	}
+
+	ctx := validation.NewValidationContext()
+	validator := &validation.Validator{Context: ctx}
+	if err := validator.Validate(&buf); err != nil {
+		t.Fatalf("unexpected error validating LSIF: %s", err)
+	}
+	for _, err := range ctx.Errors {
+		t.Errorf("unexpected validation error: %s", err)
+	}
+}
+
+func TestDatabaseBundleUnknownBundle(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	populateTestStore(t)
+	store := NewStore(db, &observation.TestContext)
+
+	if _, err := store.Bundle(context.Background(), testBundleID+1); err != ErrNoMetadata {
+		t.Fatalf("unexpected error. want=%q have=%q", ErrNoMetadata, err)
+	}
+}
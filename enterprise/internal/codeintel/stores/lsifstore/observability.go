@@ -9,8 +9,11 @@ import (
 
 type operations struct {
 	bulkMonikerResults            *observation.Operation
+	bundle                        *observation.Operation
+	callableDefinitions           *observation.Operation
 	clear                         *observation.Operation
 	definitions                   *observation.Operation
+	definitionsBundle             *observation.Operation
 	diagnostics                   *observation.Operation
 	exists                        *observation.Operation
 	hover                         *observation.Operation
@@ -67,8 +70,11 @@ func newOperations(observationContext *observation.Context) *operations {
 
 	return &operations{
 		bulkMonikerResults:            op("BulkMonikerResults"),
+		bundle:                        op("Bundle"),
+		callableDefinitions:           op("CallableDefinitions"),
 		clear:                         op("Clear"),
 		definitions:                   op("Definitions"),
+		definitionsBundle:             op("DefinitionsBundle"),
 		diagnostics:                   op("Diagnostics"),
 		exists:                        op("Exists"),
 		hover:                         op("Hover"),
@@ -0,0 +1,101 @@
+package lsifstore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/log"
+
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+// DefinitionsBundle returns a partial bundle consisting of the moniker definitions of the given
+// bundle and the range and hover data of each document containing one of those definitions. This
+// is the data required to determine the set of symbols defined by a bundle (see the semantic/diff
+// package in lib/codeintel).
+func (s *Store) DefinitionsBundle(ctx context.Context, bundleID int) (_ *semantic.GroupedBundleDataMaps, err error) {
+	ctx, traceLog, endObservation := s.operations.definitionsBundle.WithAndLogger(ctx, &err, observation.Args{LogFields: []log.Field{
+		log.Int("bundleID", bundleID),
+	}})
+	defer endObservation(1, observation.Args{})
+
+	monikerLocations, err := s.scanQualifiedMonikerLocations(s.Store.Query(ctx, sqlf.Sprintf(definitionsBundleDefinitionsQuery, bundleID)))
+	if err != nil {
+		return nil, err
+	}
+	traceLog(log.Int("numMonikers", len(monikerLocations)))
+
+	definitions := make(map[string]map[string][]semantic.LocationData)
+	pathSet := map[string]struct{}{}
+	for _, monikerLocation := range monikerLocations {
+		if _, ok := definitions[monikerLocation.Scheme]; !ok {
+			definitions[monikerLocation.Scheme] = map[string][]semantic.LocationData{}
+		}
+		definitions[monikerLocation.Scheme][monikerLocation.Identifier] = monikerLocation.Locations
+
+		for _, location := range monikerLocation.Locations {
+			pathSet[location.URI] = struct{}{}
+		}
+	}
+
+	paths := make([]string, 0, len(pathSet))
+	for path := range pathSet {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	traceLog(log.Int("numPaths", len(paths)))
+
+	documents := make(map[string]semantic.DocumentData, len(paths))
+	visitDocuments := s.makeDocumentVisitor(func(path string, document semantic.DocumentData) {
+		documents[path] = document
+	})
+
+	// Limit the number of document payloads Postgres needs to decode for a single query.
+	// See readRangesFromDocuments for the same technique.
+	for len(paths) > 0 {
+		var batch []string
+		if len(paths) <= documentBatchSize {
+			batch, paths = paths, nil
+		} else {
+			batch, paths = paths[:documentBatchSize], paths[documentBatchSize:]
+		}
+
+		pathQueries := make([]*sqlf.Query, 0, len(batch))
+		for _, path := range batch {
+			pathQueries = append(pathQueries, sqlf.Sprintf("%s", path))
+		}
+		if err := visitDocuments(s.Store.Query(ctx, sqlf.Sprintf(definitionsBundleDocumentsQuery, bundleID, sqlf.Join(pathQueries, ",")))); err != nil {
+			return nil, err
+		}
+	}
+
+	return &semantic.GroupedBundleDataMaps{
+		Documents:   documents,
+		Definitions: definitions,
+	}, nil
+}
+
+const definitionsBundleDefinitionsQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/symbols.go:DefinitionsBundle
+SELECT dump_id, scheme, identifier, data FROM lsif_data_definitions WHERE dump_id = %s ORDER BY scheme, identifier
+`
+
+const definitionsBundleDocumentsQuery = `
+-- source: enterprise/internal/codeintel/stores/lsifstore/symbols.go:DefinitionsBundle
+SELECT
+	dump_id,
+	path,
+	data,
+	ranges,
+	hovers,
+	NULL AS monikers,
+	NULL AS packages,
+	NULL AS diagnostics
+FROM
+	lsif_data_documents
+WHERE
+	dump_id = %s AND
+	path IN (%s)
+`
@@ -0,0 +1,41 @@
+package lsifstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+)
+
+func TestDatabaseDefinitionsBundle(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	db := dbtesting.GetDB(t)
+	populateTestStore(t)
+	store := NewStore(db, &observation.TestContext)
+
+	bundle, err := store.DefinitionsBundle(context.Background(), testBundleID)
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if len(bundle.Definitions) == 0 {
+		t.Fatalf("expected moniker definitions")
+	}
+
+	for scheme, identifiers := range bundle.Definitions {
+		for identifier, locations := range identifiers {
+			for _, location := range locations {
+				if _, ok := bundle.Documents[location.URI]; !ok {
+					t.Errorf("missing document %q for definition of %s:%s", location.URI, scheme, identifier)
+				}
+			}
+		}
+	}
+
+	if _, ok := bundle.Documents["internal/index/indexer.go"]; !ok {
+		t.Errorf("expected document internal/index/indexer.go to define a symbol")
+	}
+}
@@ -53,3 +53,12 @@ type CodeIntelligenceRange struct {
 	HoverText           string
 	DocumentationPathID string
 }
+
+// CallableDefinition is the definition site of a function-like symbol. The extent of the definition
+// approximates the span of the symbol's body: it begins at the definition site and ends where the
+// next callable definition in the same document begins (or at the end of the document).
+type CallableDefinition struct {
+	Range     Range
+	Extent    Range
+	HoverText string
+}
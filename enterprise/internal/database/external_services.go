@@ -3,7 +3,9 @@ package database
 import (
 	"database/sql"
 
+	"github.com/sourcegraph/sourcegraph/internal/authz/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/authz/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/internal/authz/gitea"
 	"github.com/sourcegraph/sourcegraph/internal/authz/github"
 	"github.com/sourcegraph/sourcegraph/internal/authz/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/authz/perforce"
@@ -27,6 +29,12 @@ func NewExternalServicesStore(db dbutil.DB) *database.ExternalServiceStore {
 	es.BitbucketServerValidators = []func(*schema.BitbucketServerConnection) error{
 		bitbucketserver.ValidateAuthz,
 	}
+	es.BitbucketCloudValidators = []func(*schema.BitbucketCloudConnection, []schema.AuthProviders) error{
+		bitbucketcloud.ValidateAuthz,
+	}
+	es.GiteaValidators = []func(*schema.GiteaConnection, []schema.AuthProviders) error{
+		gitea.ValidateAuthz,
+	}
 	es.PerforceValidators = []func(connection *schema.PerforceConnection) error{
 		perforce.ValidateAuthz,
 	}
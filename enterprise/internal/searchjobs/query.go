@@ -0,0 +1,108 @@
+package searchjobs
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+// repoFields are the fields which select the repositories searched by a
+// query.
+var repoFields = map[string]bool{
+	query.FieldRepo:               true,
+	query.FieldRepoGroup:          true,
+	query.FieldContext:            true,
+	query.FieldFork:               true,
+	query.FieldArchived:           true,
+	query.FieldVisibility:         true,
+	query.FieldRepoHasFile:        true,
+	query.FieldRepoHasCommitAfter: true,
+	query.FieldRevAtTime:          true,
+	query.FieldPatternType:        true,
+}
+
+// limitFields are the fields which limit the results of a search. Search jobs
+// are exhaustive, so they are replaced.
+var limitFields = map[string]bool{
+	query.FieldCount:   true,
+	query.FieldTimeout: true,
+}
+
+// ParseQuery parses and validates the query of a search job. The pattern type
+// is literal, unless the query has a patterntype: field.
+func ParseQuery(q string) (query.Plan, error) {
+	return query.Pipeline(query.Init(q, searchType(q)))
+}
+
+// searchType returns the pattern type set by the patterntype: field of q, or
+// literal.
+func searchType(q string) query.SearchType {
+	searchType := query.SearchTypeLiteral
+	nodes, err := query.Parse(q, query.SearchTypeLiteral)
+	if err != nil {
+		// Parse errors are returned by ParseQuery.
+		return searchType
+	}
+	query.VisitField(query.LowercaseFieldNames(nodes), query.FieldPatternType, func(value string, _ bool, _ query.Annotation) {
+		switch value {
+		case "regex", "regexp":
+			searchType = query.SearchTypeRegex
+		case "literal":
+			searchType = query.SearchTypeLiteral
+		case "structural":
+			searchType = query.SearchTypeStructural
+		}
+	})
+	return searchType
+}
+
+// reposQuery returns a query which lists all repositories searched by plan.
+func reposQuery(plan query.Plan) string {
+	return mapPlan(plan, func(b query.Basic) query.Basic {
+		var params []query.Parameter
+		for _, p := range b.Parameters {
+			if repoFields[p.Field] {
+				params = append(params, p)
+			}
+		}
+		params = append(params,
+			query.Parameter{Field: query.FieldType, Value: "repo"},
+			query.Parameter{Field: query.FieldCount, Value: "all"},
+		)
+		return query.Basic{Parameters: params}
+	})
+}
+
+// repoQuery returns a query which searches the single repository repo for all
+// results of plan.
+func repoQuery(plan query.Plan, repo api.RepoName) string {
+	return mapPlan(plan, func(b query.Basic) query.Basic {
+		var params []query.Parameter
+		for _, p := range b.Parameters {
+			if !limitFields[p.Field] {
+				params = append(params, p)
+			}
+		}
+		// The revisions of the repository are still selected by the repo:
+		// fields of the query, since a repo: field without revisions doesn't
+		// change them.
+		params = append(params,
+			query.Parameter{Field: query.FieldRepo, Value: "^" + regexp.QuoteMeta(string(repo)) + "$"},
+			query.Parameter{Field: query.FieldCount, Value: "all"},
+		)
+		return query.Basic{Parameters: params, Pattern: b.Pattern}
+	})
+}
+
+func mapPlan(plan query.Plan, f func(query.Basic) query.Basic) string {
+	if len(plan) == 1 {
+		return query.StringHuman(f(plan[0]).ToParseTree())
+	}
+	var queries []string
+	for _, b := range plan {
+		queries = append(queries, "("+query.StringHuman(f(b).ToParseTree())+")")
+	}
+	return strings.Join(queries, " or ")
+}
@@ -0,0 +1,69 @@
+package searchjobs
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestRepoQueries(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantRepos string
+		wantRepo  string
+	}{
+		{
+			query:     "foo",
+			wantRepos: "type:repo count:all",
+			wantRepo:  `repo:^github\.com/a/b$ count:all foo`,
+		},
+		{
+			query:     "repo:a@v1 file:go$ count:10 timeout:10s foo bar",
+			wantRepos: "repo:a@v1 type:repo count:all",
+			wantRepo:  `repo:a@v1 file:go$ repo:^github\.com/a/b$ count:all foo bar`,
+		},
+		{
+			query:     "repo:a rev:v1 fork:yes type:diff author:me",
+			wantRepos: "repo:a@v1 fork:yes type:repo count:all",
+			wantRepo:  `repo:a@v1 fork:yes type:diff author:me repo:^github\.com/a/b$ count:all`,
+		},
+		{
+			query:     "patterntype:regexp foo.*bar",
+			wantRepos: "patterntype:regexp type:repo count:all",
+			wantRepo:  `patterntype:regexp repo:^github\.com/a/b$ count:all foo.*bar`,
+		},
+		{
+			query:     "(repo:a foo) or (repo:b bar)",
+			wantRepos: "(repo:a type:repo count:all) or (repo:b type:repo count:all)",
+			wantRepo:  `(repo:a repo:^github\.com/a/b$ count:all foo) or (repo:b repo:^github\.com/a/b$ count:all bar)`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			plan, err := ParseQuery(tc.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reposQuery(plan); got != tc.wantRepos {
+				t.Errorf("got repos query %q, want %q", got, tc.wantRepos)
+			}
+			got := repoQuery(plan, api.RepoName("github.com/a/b"))
+			if got != tc.wantRepo {
+				t.Errorf("got repo query %q, want %q", got, tc.wantRepo)
+			}
+
+			// The queries must be valid.
+			for _, q := range []string{got, reposQuery(plan)} {
+				if _, err := ParseQuery(q); err != nil {
+					t.Errorf("invalid query %q: %s", q, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseQuery_invalid(t *testing.T) {
+	if _, err := ParseQuery("foo case:maybe"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,120 @@
+package searchjobs
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// Row is a single result of a search job. The results are stored as one JSON
+// encoded row per line.
+type Row struct {
+	// Type is one of "content", "path", "symbol", "repo", "commit" or "diff".
+	Type       string `json:"type"`
+	Repository string `json:"repository"`
+	// Revision is the commit of file and commit results, and the revision
+	// searched for repository results.
+	Revision string `json:"revision,omitempty"`
+	Path     string `json:"path,omitempty"`
+	// LineNumber is the 1-based line number of content results.
+	LineNumber int `json:"lineNumber,omitempty"`
+	// Preview is the matching line of content results, the name of symbol
+	// results and the subject of commit and diff results.
+	Preview string `json:"preview,omitempty"`
+	// Author and Date are set for commit and diff results.
+	Author string     `json:"author,omitempty"`
+	Date   *time.Time `json:"date,omitempty"`
+}
+
+// matchRows returns the rows of a search result. Content results have a row
+// per matching line, and symbol results a row per symbol.
+func matchRows(m result.Match) []Row {
+	switch v := m.(type) {
+	case *result.FileMatch:
+		file := Row{
+			Repository: string(v.Repo.Name),
+			Revision:   string(v.CommitID),
+			Path:       v.Path,
+		}
+		var rows []Row
+		for _, sym := range v.Symbols {
+			row := file
+			row.Type = "symbol"
+			row.LineNumber = sym.Symbol.Line
+			row.Preview = sym.Symbol.Name
+			rows = append(rows, row)
+		}
+		for _, lm := range v.LineMatches {
+			row := file
+			row.Type = "content"
+			row.LineNumber = int(lm.LineNumber) + 1
+			row.Preview = lm.Preview
+			rows = append(rows, row)
+		}
+		if len(rows) == 0 {
+			file.Type = "path"
+			rows = append(rows, file)
+		}
+		return rows
+
+	case *result.RepoMatch:
+		return []Row{{Type: "repo", Repository: string(v.Name), Revision: v.Rev}}
+
+	case *result.CommitMatch:
+		row := Row{
+			Type:       "commit",
+			Repository: string(v.Repo.Name),
+			Revision:   string(v.Commit.ID),
+			Preview:    v.Commit.Message.Subject(),
+			Author:     v.Commit.Author.Name + " <" + v.Commit.Author.Email + ">",
+			Date:       &v.Commit.Author.Date,
+		}
+		if v.DiffPreview != nil {
+			row.Type = "diff"
+		}
+		return []Row{row}
+	}
+	return nil
+}
+
+// csvHeader is the header of the CSV format of the results.
+var csvHeader = []string{"type", "repository", "revision", "path", "lineNumber", "preview", "author", "date"}
+
+// WriteCSV converts the results in JSON lines format read from r to CSV.
+func WriteCSV(w io.Writer, r io.Reader) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var row Row
+		if err := dec.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return errors.Wrap(err, "reading results")
+		}
+
+		var lineNumber, date string
+		if row.LineNumber > 0 {
+			lineNumber = strconv.Itoa(row.LineNumber)
+		}
+		if row.Date != nil {
+			date = row.Date.UTC().Format(time.RFC3339)
+		}
+		if err := cw.Write([]string{row.Type, row.Repository, row.Revision, row.Path, lineNumber, row.Preview, row.Author, date}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
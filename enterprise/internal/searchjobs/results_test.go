@@ -0,0 +1,135 @@
+package searchjobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestMatchRows(t *testing.T) {
+	date := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := types.RepoName{ID: 1, Name: "github.com/a/b"}
+	file := result.File{Repo: repo, CommitID: "deadbeef", Path: "main.go"}
+
+	tests := []struct {
+		name  string
+		match result.Match
+		want  []Row
+	}{
+		{
+			name: "content",
+			match: &result.FileMatch{
+				File: file,
+				LineMatches: []*result.LineMatch{
+					{Preview: "func main() {", LineNumber: 2},
+					{Preview: "}", LineNumber: 4},
+				},
+			},
+			want: []Row{
+				{Type: "content", Repository: "github.com/a/b", Revision: "deadbeef", Path: "main.go", LineNumber: 3, Preview: "func main() {"},
+				{Type: "content", Repository: "github.com/a/b", Revision: "deadbeef", Path: "main.go", LineNumber: 5, Preview: "}"},
+			},
+		},
+		{
+			name:  "path",
+			match: &result.FileMatch{File: file},
+			want: []Row{
+				{Type: "path", Repository: "github.com/a/b", Revision: "deadbeef", Path: "main.go"},
+			},
+		},
+		{
+			name: "symbol",
+			match: &result.FileMatch{
+				File:    file,
+				Symbols: []*result.SymbolMatch{{Symbol: result.Symbol{Name: "main", Line: 3}, File: &file}},
+			},
+			want: []Row{
+				{Type: "symbol", Repository: "github.com/a/b", Revision: "deadbeef", Path: "main.go", LineNumber: 3, Preview: "main"},
+			},
+		},
+		{
+			name:  "repo",
+			match: &result.RepoMatch{Name: "github.com/a/b", Rev: "v1"},
+			want: []Row{
+				{Type: "repo", Repository: "github.com/a/b", Revision: "v1"},
+			},
+		},
+		{
+			name: "commit",
+			match: &result.CommitMatch{
+				Repo: repo,
+				Commit: git.Commit{
+					ID:      "deadbeef",
+					Author:  git.Signature{Name: "Alice", Email: "alice@example.com", Date: date},
+					Message: "Fix bug\n\nDetails",
+				},
+			},
+			want: []Row{
+				{Type: "commit", Repository: "github.com/a/b", Revision: "deadbeef", Preview: "Fix bug", Author: "Alice <alice@example.com>", Date: &date},
+			},
+		},
+		{
+			name: "diff",
+			match: &result.CommitMatch{
+				Repo: repo,
+				Commit: git.Commit{
+					ID:      "deadbeef",
+					Author:  git.Signature{Name: "Alice", Email: "alice@example.com", Date: date},
+					Message: "Fix bug",
+				},
+				DiffPreview: &result.HighlightedString{Value: "main.go main.go\n@@ -1 +1 @@\n-a\n+b\n"},
+			},
+			want: []Row{
+				{Type: "diff", Repository: "github.com/a/b", Revision: "deadbeef", Preview: "Fix bug", Author: "Alice <alice@example.com>", Date: &date},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, matchRows(tc.match)); diff != "" {
+				t.Errorf("unexpected rows (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	date := time.Date(2021, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	rows := []Row{
+		{Type: "content", Repository: "github.com/a/b", Revision: "deadbeef", Path: "main.go", LineNumber: 3, Preview: `fmt.Println("a, b")`},
+		{Type: "commit", Repository: "github.com/a/b", Revision: "deadbeef", Preview: "Fix bug", Author: "Alice <alice@example.com>", Date: &date},
+	}
+	var jsonl bytes.Buffer
+	enc := json.NewEncoder(&jsonl)
+	for _, row := range rows {
+		if err := enc.Encode(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got strings.Builder
+	if err := WriteCSV(&got, &jsonl); err != nil {
+		t.Fatal(err)
+	}
+	want := `type,repository,revision,path,lineNumber,preview,author,date
+content,github.com/a/b,deadbeef,main.go,3,"fmt.Println(""a, b"")",,
+commit,github.com/a/b,deadbeef,,,Fix bug,Alice <alice@example.com>,2021-06-01T10:00:00Z
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("unexpected CSV (-want +got):\n%s", diff)
+	}
+}
+
+func TestWriteCSV_invalid(t *testing.T) {
+	if err := WriteCSV(&bytes.Buffer{}, strings.NewReader("{\"type\":")); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,81 @@
+// Package searchjobs runs exhaustive searches in the background.
+//
+// A search job searches every repository matched by the repository filters of
+// its query, one repository at a time and in order of the repository names, so
+// that no search is subject to the result limits and timeouts of interactive
+// searches. The results are written as JSON lines to blob storage in parts,
+// which are concatenated on download. After each part is stored the job
+// records the last repository it covers, so a job retried after a failure or
+// a restart resumes where it stopped.
+package searchjobs
+
+import (
+	"fmt"
+	"time"
+)
+
+// Job is a search job.
+type Job struct {
+	ID              int
+	UserID          int32
+	Query           string
+	State           string
+	FailureMessage  *string
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+	ProcessAfter    *time.Time
+	NumResets       int
+	NumFailures     int
+	Cancel          bool
+	ReposTotal      int
+	ReposSearched   int
+	ReposIncomplete int
+	LastRepo        string
+	MatchCount      int64
+	ResultParts     int
+	ResultSize      int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// RecordID implements workerutil.Record.
+func (j *Job) RecordID() int {
+	return j.ID
+}
+
+// Finished returns true if the job will not run again.
+func (j *Job) Finished() bool {
+	return j.State == "completed" || j.State == "failed"
+}
+
+// Status returns the state of the job as shown to users. It is the state of
+// the worker record, except that canceled jobs are "canceled" once they
+// stopped and "canceling" until then.
+func (j *Job) Status() string {
+	if j.Cancel {
+		if j.Finished() {
+			return "canceled"
+		}
+		return "canceling"
+	}
+	return j.State
+}
+
+// CanceledFailureMessage is the failure message of canceled jobs.
+const CanceledFailureMessage = "Canceled"
+
+// resultPartKey returns the blob storage key of the part of the results of the
+// job with the given index.
+func resultPartKey(jobID, part int) string {
+	return fmt.Sprintf("search-jobs/%d/%d.jsonl", jobID, part)
+}
+
+// ResultPartKeys returns the blob storage keys of the results of the job, in
+// order.
+func (j *Job) ResultPartKeys() []string {
+	keys := make([]string, 0, j.ResultParts)
+	for i := 0; i < j.ResultParts; i++ {
+		keys = append(keys, resultPartKey(j.ID, i))
+	}
+	return keys
+}
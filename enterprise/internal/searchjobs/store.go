@@ -0,0 +1,300 @@
+package searchjobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+)
+
+// Store reads and writes search jobs.
+type Store struct {
+	*basestore.Store
+}
+
+// NewStore returns a new Store backed by the given database.
+func NewStore(db dbutil.DB) *Store {
+	return &Store{Store: basestore.NewWithDB(db, sql.TxOptions{})}
+}
+
+// Transact creates a new transaction.
+func (s *Store) Transact(ctx context.Context) (*Store, error) {
+	txBase, err := s.Store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{Store: txBase}, nil
+}
+
+// QuotaExceededError is returned by Create if the user reached one of the
+// limits on the number of search jobs.
+type QuotaExceededError struct {
+	msg string
+}
+
+func (e *QuotaExceededError) Error() string {
+	return e.msg
+}
+
+// Limits are the per-user limits enforced by Create.
+type Limits struct {
+	// MaxActiveJobs is the maximum number of jobs of a user which haven't
+	// finished.
+	MaxActiveJobs int
+	// MaxJobs is the maximum number of jobs of a user.
+	MaxJobs int
+}
+
+const createLockUserFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:Create
+SELECT id FROM users WHERE id = %s FOR UPDATE
+`
+
+const countJobsFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:Create
+SELECT
+	COUNT(*),
+	COUNT(*) FILTER (WHERE state NOT IN ('completed', 'failed'))
+FROM search_jobs
+WHERE user_id = %s
+`
+
+const createJobFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:Create
+INSERT INTO search_jobs (user_id, query)
+VALUES (%s, %s)
+RETURNING %s
+`
+
+// Create queues a search job for the given user, unless the user reached one
+// of the limits, in which case a *QuotaExceededError is returned.
+func (s *Store) Create(ctx context.Context, userID int32, query string, limits Limits) (_ *Job, err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = tx.Done(err) }()
+
+	// Lock the user so that concurrent requests can't exceed the limits.
+	if err := tx.Exec(ctx, sqlf.Sprintf(createLockUserFmtStr, userID)); err != nil {
+		return nil, err
+	}
+
+	var total, active int
+	if err := tx.QueryRow(ctx, sqlf.Sprintf(countJobsFmtStr, userID)).Scan(&total, &active); err != nil {
+		return nil, err
+	}
+	if active >= limits.MaxActiveJobs {
+		return nil, &QuotaExceededError{msg: fmt.Sprintf("you can't have more than %d search jobs queued or running at once", limits.MaxActiveJobs)}
+	}
+	if total >= limits.MaxJobs {
+		return nil, &QuotaExceededError{msg: fmt.Sprintf("you can't have more than %d search jobs, delete finished jobs to create new ones", limits.MaxJobs)}
+	}
+
+	job, _, err := scanFirstJob(tx.Query(ctx, sqlf.Sprintf(createJobFmtStr, userID, query, sqlf.Join(JobColumns, ", "))))
+	return job, err
+}
+
+const getJobFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:GetByID
+SELECT %s FROM search_jobs WHERE id = %s
+`
+
+// GetByID returns the search job with the given identifier and true, or false
+// if it doesn't exist.
+func (s *Store) GetByID(ctx context.Context, id int) (*Job, bool, error) {
+	return scanFirstJob(s.Query(ctx, sqlf.Sprintf(getJobFmtStr, sqlf.Join(JobColumns, ", "), id)))
+}
+
+const listJobsFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:ListByUser
+SELECT %s FROM search_jobs WHERE user_id = %s ORDER BY id DESC
+`
+
+// ListByUser returns the search jobs of the given user, newest first.
+func (s *Store) ListByUser(ctx context.Context, userID int32) ([]*Job, error) {
+	return scanJobs(s.Query(ctx, sqlf.Sprintf(listJobsFmtStr, sqlf.Join(JobColumns, ", "), userID)))
+}
+
+const cancelJobFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:Cancel
+UPDATE search_jobs
+SET
+	cancel = true,
+	state = CASE WHEN state = 'processing' THEN state ELSE 'failed' END,
+	failure_message = CASE WHEN state = 'processing' THEN failure_message ELSE %s END,
+	finished_at = CASE WHEN state = 'processing' THEN finished_at ELSE now() END,
+	updated_at = now()
+WHERE id = %s AND state NOT IN ('completed', 'failed')
+`
+
+// Cancel cancels the job with the given identifier. Jobs which aren't running
+// fail immediately. Running jobs are marked for cancellation, and fail once
+// the worker notices. It returns false if the job already finished.
+func (s *Store) Cancel(ctx context.Context, id int) (bool, error) {
+	result, err := s.ExecResult(ctx, sqlf.Sprintf(cancelJobFmtStr, CanceledFailureMessage, id))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+const deleteJobFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:Delete
+DELETE FROM search_jobs
+WHERE id = %s AND state IN ('completed', 'failed')
+`
+
+// Delete deletes the job with the given identifier. Only finished jobs can be
+// deleted. It returns false if the job doesn't exist or hasn't finished. The
+// caller is responsible for deleting the results from blob storage.
+func (s *Store) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := s.ExecResult(ctx, sqlf.Sprintf(deleteJobFmtStr, id))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+const deleteExpiredJobsFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:DeleteExpired
+DELETE FROM search_jobs
+WHERE state IN ('completed', 'failed') AND finished_at < %s
+RETURNING %s
+`
+
+// DeleteExpired deletes the jobs which finished before the given time and
+// returns them, so that the caller can delete their results.
+func (s *Store) DeleteExpired(ctx context.Context, before time.Time) ([]*Job, error) {
+	return scanJobs(s.Query(ctx, sqlf.Sprintf(deleteExpiredJobsFmtStr, before, sqlf.Join(JobColumns, ", "))))
+}
+
+const updateProgressFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:UpdateProgress
+UPDATE search_jobs
+SET repos_total = %s, repos_searched = %s, updated_at = now()
+WHERE id = %s
+RETURNING cancel
+`
+
+// UpdateProgress records the number of repositories searched by the running
+// job with the given identifier. It returns whether the job was canceled.
+func (s *Store) UpdateProgress(ctx context.Context, id, reposTotal, reposSearched int) (bool, error) {
+	cancel, _, err := basestore.ScanFirstBool(s.Query(ctx, sqlf.Sprintf(updateProgressFmtStr, reposTotal, reposSearched, id)))
+	return cancel, err
+}
+
+const checkpointFmtStr = `
+-- source: enterprise/internal/searchjobs/store.go:Checkpoint
+UPDATE search_jobs
+SET
+	last_repo = %s,
+	repos_incomplete = %s,
+	match_count = %s,
+	result_parts = %s,
+	result_size = %s,
+	updated_at = now()
+WHERE id = %s
+RETURNING cancel
+`
+
+// Checkpoint records the results stored by the running job with the given
+// identifier. The fields of job recorded are LastRepo, ReposIncomplete,
+// MatchCount, ResultParts and ResultSize. It returns whether the job was
+// canceled.
+func (s *Store) Checkpoint(ctx context.Context, job *Job) (bool, error) {
+	cancel, _, err := basestore.ScanFirstBool(s.Query(ctx, sqlf.Sprintf(
+		checkpointFmtStr,
+		job.LastRepo,
+		job.ReposIncomplete,
+		job.MatchCount,
+		job.ResultParts,
+		job.ResultSize,
+		job.ID,
+	)))
+	return cancel, err
+}
+
+// JobColumns are the columns of a search job, in the order read by ScanJob.
+var JobColumns = []*sqlf.Query{
+	sqlf.Sprintf("search_jobs.id"),
+	sqlf.Sprintf("search_jobs.user_id"),
+	sqlf.Sprintf("search_jobs.query"),
+	sqlf.Sprintf("search_jobs.state"),
+	sqlf.Sprintf("search_jobs.failure_message"),
+	sqlf.Sprintf("search_jobs.started_at"),
+	sqlf.Sprintf("search_jobs.finished_at"),
+	sqlf.Sprintf("search_jobs.process_after"),
+	sqlf.Sprintf("search_jobs.num_resets"),
+	sqlf.Sprintf("search_jobs.num_failures"),
+	sqlf.Sprintf("search_jobs.cancel"),
+	sqlf.Sprintf("search_jobs.repos_total"),
+	sqlf.Sprintf("search_jobs.repos_searched"),
+	sqlf.Sprintf("search_jobs.repos_incomplete"),
+	sqlf.Sprintf("search_jobs.last_repo"),
+	sqlf.Sprintf("search_jobs.match_count"),
+	sqlf.Sprintf("search_jobs.result_parts"),
+	sqlf.Sprintf("search_jobs.result_size"),
+	sqlf.Sprintf("search_jobs.created_at"),
+	sqlf.Sprintf("search_jobs.updated_at"),
+}
+
+// ScanJob scans a single job from the given rows. It implements the scan
+// function of the worker store.
+func ScanJob(rows *sql.Rows, err error) (workerutil.Record, bool, error) {
+	return scanFirstJob(rows, err)
+}
+
+func scanFirstJob(rows *sql.Rows, err error) (*Job, bool, error) {
+	jobs, err := scanJobs(rows, err)
+	if err != nil || len(jobs) == 0 {
+		return nil, false, err
+	}
+	return jobs[0], true, nil
+}
+
+func scanJobs(rows *sql.Rows, queryErr error) (_ []*Job, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var jobs []*Job
+	for rows.Next() {
+		var j Job
+		if err := rows.Scan(
+			&j.ID,
+			&j.UserID,
+			&j.Query,
+			&j.State,
+			&j.FailureMessage,
+			&j.StartedAt,
+			&j.FinishedAt,
+			&j.ProcessAfter,
+			&j.NumResets,
+			&j.NumFailures,
+			&j.Cancel,
+			&j.ReposTotal,
+			&j.ReposSearched,
+			&j.ReposIncomplete,
+			&j.LastRepo,
+			&j.MatchCount,
+			&j.ResultParts,
+			&j.ResultSize,
+			&j.CreatedAt,
+			&j.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &j)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,201 @@
+package searchjobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+)
+
+func init() {
+	dbtesting.DBNameSuffix = "searchjobsstoredb"
+}
+
+func newTestStore(t *testing.T) (context.Context, *Store, int32) {
+	t.Helper()
+
+	ctx := context.Background()
+	s := NewStore(dbtesting.GetDB(t))
+
+	var userID int32
+	if err := s.QueryRow(ctx, sqlf.Sprintf("INSERT INTO users (username) VALUES ('searchjobs') RETURNING id")).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	return ctx, s, userID
+}
+
+func TestStore_Create(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx, s, userID := newTestStore(t)
+	limits := Limits{MaxActiveJobs: 2, MaxJobs: 3}
+
+	job, err := s.Create(ctx, userID, "foo", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.UserID != userID || job.Query != "foo" || job.State != "queued" {
+		t.Errorf("unexpected job %+v", job)
+	}
+	bar, err := s.Create(ctx, userID, "bar", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both jobs are active.
+	if _, err := s.Create(ctx, userID, "baz", limits); err == nil {
+		t.Fatal("expected quota error")
+	} else if _, ok := err.(*QuotaExceededError); !ok {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	if ok, err := s.Cancel(ctx, job.ID); err != nil || !ok {
+		t.Fatalf("failed to cancel job: %v", err)
+	}
+	if _, err := s.Create(ctx, userID, "baz", limits); err != nil {
+		t.Fatal(err)
+	}
+
+	// The canceled job still counts until it is deleted.
+	if ok, err := s.Cancel(ctx, bar.ID); err != nil || !ok {
+		t.Fatalf("failed to cancel job: %v", err)
+	}
+	if _, err := s.Create(ctx, userID, "qux", limits); err == nil {
+		t.Fatal("expected quota error")
+	}
+	if ok, err := s.Delete(ctx, job.ID); err != nil || !ok {
+		t.Fatalf("failed to delete job: %v", err)
+	}
+	if _, err := s.Create(ctx, userID, "qux", limits); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs, err := s.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []string
+	for _, j := range jobs {
+		queries = append(queries, j.Query)
+	}
+	if len(queries) != 3 || queries[0] != "qux" || queries[2] != "bar" {
+		t.Errorf("unexpected jobs %v", queries)
+	}
+}
+
+func TestStore_Cancel(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx, s, userID := newTestStore(t)
+	limits := Limits{MaxActiveJobs: 2, MaxJobs: 2}
+
+	queued, err := s.Create(ctx, userID, "foo", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processing, err := s.Create(ctx, userID, "bar", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Exec(ctx, sqlf.Sprintf("UPDATE search_jobs SET state = 'processing' WHERE id = %s", processing.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []int{queued.ID, processing.ID} {
+		if ok, err := s.Cancel(ctx, id); err != nil || !ok {
+			t.Fatalf("failed to cancel job: %v", err)
+		}
+	}
+
+	job, _, err := s.GetByID(ctx, queued.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status() != "canceled" || job.FinishedAt == nil {
+		t.Errorf("unexpected queued job after cancel %+v", job)
+	}
+
+	job, _, err = s.GetByID(ctx, processing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status() != "canceling" {
+		t.Errorf("unexpected processing job after cancel %+v", job)
+	}
+	if canceled, err := s.UpdateProgress(ctx, processing.ID, 10, 5); err != nil || !canceled {
+		t.Errorf("worker was not told to cancel: %v", err)
+	}
+
+	// Finished jobs can't be canceled.
+	if ok, err := s.Cancel(ctx, queued.ID); err != nil || ok {
+		t.Errorf("canceled finished job: %v", err)
+	}
+}
+
+func TestStore_Checkpoint(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx, s, userID := newTestStore(t)
+
+	job, err := s.Create(ctx, userID, "foo", Limits{MaxActiveJobs: 1, MaxJobs: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.UpdateProgress(ctx, job.ID, 10, 4); err != nil {
+		t.Fatal(err)
+	}
+	job.LastRepo = "github.com/a/b"
+	job.ReposIncomplete = 1
+	job.MatchCount = 100
+	job.ResultParts = 2
+	job.ResultSize = 1234
+	if canceled, err := s.Checkpoint(ctx, job); err != nil || canceled {
+		t.Fatalf("failed to checkpoint: %v", err)
+	}
+
+	got, _, err := s.GetByID(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ReposTotal != 10 || got.ReposSearched != 4 || got.LastRepo != "github.com/a/b" || got.ReposIncomplete != 1 ||
+		got.MatchCount != 100 || got.ResultParts != 2 || got.ResultSize != 1234 {
+		t.Errorf("unexpected job after checkpoint %+v", got)
+	}
+}
+
+func TestStore_DeleteExpired(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx, s, userID := newTestStore(t)
+	limits := Limits{MaxActiveJobs: 2, MaxJobs: 2}
+
+	old, err := s.Create(ctx, userID, "foo", limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create(ctx, userID, "bar", limits); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Exec(ctx, sqlf.Sprintf("UPDATE search_jobs SET state = 'completed', finished_at = %s WHERE id = %s", time.Now().Add(-48*time.Hour), old.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, err := s.DeleteExpired(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != old.ID {
+		t.Errorf("unexpected deleted jobs %+v", deleted)
+	}
+}
@@ -0,0 +1,254 @@
+package searchjobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker"
+	dbworkerstore "github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker/store"
+)
+
+// Searcher runs the searches of search jobs.
+type Searcher interface {
+	// Search runs query with the permissions of the actor in ctx and calls
+	// send with the results as they are found. It returns true if some of the
+	// repositories couldn't be searched completely, for example because they
+	// are still being cloned. send is not called concurrently.
+	Search(ctx context.Context, query string, send func([]result.Match)) (incomplete bool, err error)
+}
+
+const (
+	// partSize is the size of the results after which they are stored.
+	partSize = 16 * 1024 * 1024
+
+	// checkpointInterval is the maximum time between checkpoints, which bounds
+	// the work repeated when a job is retried.
+	checkpointInterval = time.Minute
+
+	// progressInterval is the minimum time between updates of the number of
+	// repositories searched.
+	progressInterval = 5 * time.Second
+)
+
+// NewWorker returns a worker which runs queued search jobs and stores their
+// results in the given blob store.
+func NewWorker(ctx context.Context, s *Store, searcher Searcher, results uploadstore.Store, numHandlers int, metrics workerutil.WorkerMetrics) *workerutil.Worker {
+	h := &handler{
+		store:              s,
+		searcher:           searcher,
+		results:            results,
+		partSize:           partSize,
+		checkpointInterval: checkpointInterval,
+		progressInterval:   progressInterval,
+	}
+	return dbworker.NewWorker(ctx, newWorkerStore(s), h, workerutil.WorkerOptions{
+		Name:              "search_jobs_worker",
+		NumHandlers:       numHandlers,
+		Interval:          5 * time.Second,
+		HeartbeatInterval: 15 * time.Second,
+		Metrics:           metrics,
+	})
+}
+
+// NewResetter returns a routine which requeues the search jobs whose worker
+// died, for example because the frontend was restarted.
+func NewResetter(s *Store, metrics dbworker.ResetterMetrics) *dbworker.Resetter {
+	return dbworker.NewResetter(newWorkerStore(s), dbworker.ResetterOptions{
+		Name:     "search_jobs_worker_resetter",
+		Interval: time.Minute,
+		Metrics:  metrics,
+	})
+}
+
+// NewJanitor returns a routine which deletes the search jobs which finished
+// more than ttl ago, along with their results.
+func NewJanitor(ctx context.Context, s *Store, results uploadstore.Store, ttl time.Duration) goroutine.BackgroundRoutine {
+	return goroutine.NewPeriodicGoroutine(ctx, time.Hour, goroutine.NewHandlerWithErrorMessage(
+		"search_jobs_janitor",
+		func(ctx context.Context) error {
+			jobs, err := s.DeleteExpired(ctx, time.Now().Add(-ttl))
+			if err != nil {
+				return err
+			}
+			for _, job := range jobs {
+				DeleteResults(ctx, results, job)
+			}
+			return nil
+		},
+	))
+}
+
+// DeleteResults deletes the results of a deleted job from blob storage. Errors
+// are only logged, since the blob store expires the results anyway.
+func DeleteResults(ctx context.Context, results uploadstore.Store, job *Job) {
+	for _, key := range job.ResultPartKeys() {
+		if err := results.Delete(ctx, key); err != nil {
+			log15.Warn("Failed to delete search job results", "job", job.ID, "key", key, "error", err)
+		}
+	}
+}
+
+func newWorkerStore(s *Store) dbworkerstore.Store {
+	return dbworkerstore.New(s.Handle(), dbworkerstore.Options{
+		Name:              "search_jobs_worker_store",
+		TableName:         "search_jobs",
+		ColumnExpressions: JobColumns,
+		Scan:              ScanJob,
+		OrderByExpression: sqlf.Sprintf("search_jobs.id"),
+		// Searches run for a long time, but the heartbeats keep them from
+		// being considered stalled.
+		StalledMaxAge: time.Minute,
+		MaxNumResets:  5,
+		RetryAfter:    time.Minute,
+		MaxNumRetries: 3,
+	})
+}
+
+// jobStore is the subset of *Store used by handler.
+type jobStore interface {
+	UpdateProgress(ctx context.Context, id, reposTotal, reposSearched int) (bool, error)
+	Checkpoint(ctx context.Context, job *Job) (bool, error)
+}
+
+var errCanceled = errcode.MakeNonRetryable(errors.New(CanceledFailureMessage))
+
+type handler struct {
+	store    jobStore
+	searcher Searcher
+	results  uploadstore.Store
+
+	partSize           int
+	checkpointInterval time.Duration
+	progressInterval   time.Duration
+}
+
+var _ workerutil.Handler = &handler{}
+
+func (h *handler) Handle(ctx context.Context, record workerutil.Record) error {
+	job := *record.(*Job)
+
+	// 🚨 SECURITY: The searches run with the permissions of the user who
+	// created the job.
+	ctx = actor.WithActor(ctx, actor.FromUser(job.UserID))
+
+	plan, err := ParseQuery(job.Query)
+	if err != nil {
+		return errcode.MakeNonRetryable(err)
+	}
+
+	repos, err := h.listRepos(ctx, reposQuery(plan))
+	if err != nil {
+		return errors.Wrap(err, "listing repositories")
+	}
+
+	// A previous attempt stored the results of the repositories up to
+	// job.LastRepo.
+	start := 0
+	if job.LastRepo != "" {
+		start = sort.Search(len(repos), func(i int) bool { return repos[i] > job.LastRepo })
+	}
+	if canceled, err := h.store.UpdateProgress(ctx, job.ID, len(repos), start); err != nil {
+		return err
+	} else if canceled {
+		return errCanceled
+	}
+
+	var (
+		buf               bytes.Buffer
+		enc               = json.NewEncoder(&buf)
+		pendingMatches    int64
+		pendingIncomplete int
+		encodeErr         error
+		lastCheckpoint    = time.Now()
+		lastProgress      = time.Now()
+	)
+
+	checkpoint := func(lastRepo string) (bool, error) {
+		if buf.Len() > 0 {
+			n, err := h.results.Upload(ctx, resultPartKey(job.ID, job.ResultParts), bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				return false, errors.Wrap(err, "storing results")
+			}
+			job.ResultParts++
+			job.ResultSize += n
+			buf.Reset()
+		}
+		job.LastRepo = lastRepo
+		job.MatchCount += pendingMatches
+		job.ReposIncomplete += pendingIncomplete
+		pendingMatches, pendingIncomplete = 0, 0
+		lastCheckpoint = time.Now()
+		return h.store.Checkpoint(ctx, &job)
+	}
+
+	for i := start; i < len(repos); i++ {
+		incomplete, err := h.searcher.Search(ctx, repoQuery(plan, api.RepoName(repos[i])), func(matches []result.Match) {
+			for _, m := range matches {
+				for _, row := range matchRows(m) {
+					if err := enc.Encode(row); err != nil && encodeErr == nil {
+						encodeErr = err
+					}
+					pendingMatches++
+				}
+			}
+		})
+		if err != nil {
+			return errors.Wrapf(err, "searching %s", repos[i])
+		}
+		if encodeErr != nil {
+			return encodeErr
+		}
+		if incomplete {
+			pendingIncomplete++
+		}
+
+		var canceled bool
+		last := i == len(repos)-1
+		if last || buf.Len() >= h.partSize || time.Since(lastCheckpoint) >= h.checkpointInterval {
+			if canceled, err = checkpoint(repos[i]); err != nil {
+				return err
+			}
+		}
+		if !canceled && (last || time.Since(lastProgress) >= h.progressInterval) {
+			if canceled, err = h.store.UpdateProgress(ctx, job.ID, len(repos), i+1); err != nil {
+				return err
+			}
+			lastProgress = time.Now()
+		}
+		if canceled {
+			return errCanceled
+		}
+	}
+	return nil
+}
+
+// listRepos returns the sorted names of the repositories found by the
+// repository search q.
+func (h *handler) listRepos(ctx context.Context, q string) ([]string, error) {
+	seen := map[api.RepoName]bool{}
+	var repos []string
+	_, err := h.searcher.Search(ctx, q, func(matches []result.Match) {
+		for _, m := range matches {
+			if rm, ok := m.(*result.RepoMatch); ok && !seen[rm.Name] {
+				seen[rm.Name] = true
+				repos = append(repos, string(rm.Name))
+			}
+		}
+	})
+	sort.Strings(repos)
+	return repos, err
+}
@@ -0,0 +1,233 @@
+package searchjobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+
+	uploadstoremocks "github.com/sourcegraph/sourcegraph/enterprise/internal/codeintel/stores/uploadstore/mocks"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// fakeSearcher returns the results in matches of the queries, and records the
+// queries run.
+type fakeSearcher struct {
+	matches    map[string][]result.Match
+	incomplete map[string]bool
+	queries    []string
+	userIDs    []int32
+}
+
+func (s *fakeSearcher) Search(ctx context.Context, q string, send func([]result.Match)) (bool, error) {
+	s.queries = append(s.queries, q)
+	s.userIDs = append(s.userIDs, actor.FromContext(ctx).UID)
+	if ms, ok := s.matches[q]; ok {
+		send(ms)
+	}
+	return s.incomplete[q], nil
+}
+
+// fakeJobStore records the progress and checkpoints of a job.
+type fakeJobStore struct {
+	progress    [][2]int
+	checkpoints []Job
+	cancelAfter int
+}
+
+func (s *fakeJobStore) UpdateProgress(ctx context.Context, id, reposTotal, reposSearched int) (bool, error) {
+	s.progress = append(s.progress, [2]int{reposTotal, reposSearched})
+	return s.canceled(), nil
+}
+
+func (s *fakeJobStore) Checkpoint(ctx context.Context, job *Job) (bool, error) {
+	s.checkpoints = append(s.checkpoints, *job)
+	return s.canceled(), nil
+}
+
+func (s *fakeJobStore) canceled() bool {
+	return s.cancelAfter > 0 && len(s.progress)+len(s.checkpoints) >= s.cancelAfter
+}
+
+func newTestHandler(t *testing.T, q string, repos ...string) (*handler, *fakeSearcher, *fakeJobStore, map[string][]byte) {
+	t.Helper()
+
+	plan, err := ParseQuery(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	searcher := &fakeSearcher{matches: map[string][]result.Match{}, incomplete: map[string]bool{}}
+	var repoMatches []result.Match
+	for _, name := range repos {
+		// Return the repositories unsorted and duplicated.
+		repoMatches = append([]result.Match{&result.RepoMatch{Name: api.RepoName(name)}}, repoMatches...)
+		repoMatches = append(repoMatches, &result.RepoMatch{Name: api.RepoName(name)})
+		searcher.matches[repoQuery(plan, api.RepoName(name))] = []result.Match{&result.FileMatch{
+			File: result.File{Repo: types.RepoName{Name: api.RepoName(name)}, CommitID: "deadbeef", Path: "README.md"},
+		}}
+	}
+	searcher.matches[reposQuery(plan)] = repoMatches
+
+	uploads := map[string][]byte{}
+	results := uploadstoremocks.NewMockStore()
+	results.UploadFunc.SetDefaultHook(func(ctx context.Context, key string, r io.Reader) (int64, error) {
+		b, err := ioutil.ReadAll(r)
+		uploads[key] = b
+		return int64(len(b)), err
+	})
+
+	store := &fakeJobStore{}
+	return &handler{
+		store:              store,
+		searcher:           searcher,
+		results:            results,
+		partSize:           1,
+		checkpointInterval: time.Hour,
+		progressInterval:   time.Hour,
+	}, searcher, store, uploads
+}
+
+func readRows(t *testing.T, uploads map[string][]byte, job *Job) []Row {
+	t.Helper()
+
+	var rows []Row
+	for _, key := range job.ResultPartKeys() {
+		dec := json.NewDecoder(bytes.NewReader(uploads[key]))
+		for dec.More() {
+			var row Row
+			if err := dec.Decode(&row); err != nil {
+				t.Fatal(err)
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func TestHandler(t *testing.T) {
+	h, searcher, store, uploads := newTestHandler(t, "file:README", "github.com/a/c", "github.com/a/b")
+	searcher.incomplete[`file:README repo:^github\.com/a/c$ count:all`] = true
+
+	job := &Job{ID: 1, UserID: 42, Query: "file:README"}
+	if err := h.Handle(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	wantQueries := []string{
+		"type:repo count:all",
+		`file:README repo:^github\.com/a/b$ count:all`,
+		`file:README repo:^github\.com/a/c$ count:all`,
+	}
+	if diff := cmp.Diff(wantQueries, searcher.queries); diff != "" {
+		t.Errorf("unexpected queries (-want +got):\n%s", diff)
+	}
+	for _, uid := range searcher.userIDs {
+		if uid != 42 {
+			t.Errorf("searched as user %d, want 42", uid)
+		}
+	}
+
+	if diff := cmp.Diff([][2]int{{2, 0}, {2, 2}}, store.progress); diff != "" {
+		t.Errorf("unexpected progress (-want +got):\n%s", diff)
+	}
+
+	if len(store.checkpoints) != 2 {
+		t.Fatalf("got %d checkpoints, want 2", len(store.checkpoints))
+	}
+	last := store.checkpoints[1]
+	if last.LastRepo != "github.com/a/c" || last.MatchCount != 2 || last.ReposIncomplete != 1 || last.ResultParts != 2 {
+		t.Errorf("unexpected last checkpoint %+v", last)
+	}
+	if int(last.ResultSize) != len(uploads[resultPartKey(1, 0)])+len(uploads[resultPartKey(1, 1)]) {
+		t.Errorf("got result size %d, want the size of the uploads", last.ResultSize)
+	}
+
+	wantRows := []Row{
+		{Type: "path", Repository: "github.com/a/b", Revision: "deadbeef", Path: "README.md"},
+		{Type: "path", Repository: "github.com/a/c", Revision: "deadbeef", Path: "README.md"},
+	}
+	if diff := cmp.Diff(wantRows, readRows(t, uploads, &last)); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
+	}
+
+	// The job is not modified.
+	if job.ResultParts != 0 {
+		t.Errorf("job was modified")
+	}
+}
+
+func TestHandler_resume(t *testing.T) {
+	h, searcher, store, uploads := newTestHandler(t, "file:README", "github.com/a/b", "github.com/a/c", "github.com/a/d")
+	uploads[resultPartKey(1, 0)] = []byte(`{"type":"path","repository":"github.com/a/b","path":"README.md"}` + "\n")
+
+	job := &Job{ID: 1, Query: "file:README", LastRepo: "github.com/a/b", MatchCount: 1, ResultParts: 1}
+	if err := h.Handle(context.Background(), job); err != nil {
+		t.Fatal(err)
+	}
+
+	wantQueries := []string{
+		"type:repo count:all",
+		`file:README repo:^github\.com/a/c$ count:all`,
+		`file:README repo:^github\.com/a/d$ count:all`,
+	}
+	if diff := cmp.Diff(wantQueries, searcher.queries); diff != "" {
+		t.Errorf("unexpected queries (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([2]int{3, 1}, store.progress[0]); diff != "" {
+		t.Errorf("unexpected initial progress (-want +got):\n%s", diff)
+	}
+
+	last := store.checkpoints[len(store.checkpoints)-1]
+	if last.MatchCount != 3 || last.ResultParts != 3 {
+		t.Errorf("unexpected last checkpoint %+v", last)
+	}
+	if rows := readRows(t, uploads, &last); len(rows) != 3 {
+		t.Errorf("got %d results, want 3", len(rows))
+	}
+}
+
+func TestHandler_cancel(t *testing.T) {
+	h, searcher, store, _ := newTestHandler(t, "file:README", "github.com/a/b", "github.com/a/c", "github.com/a/d")
+	// Canceled at the first checkpoint.
+	store.cancelAfter = 2
+
+	err := h.Handle(context.Background(), &Job{ID: 1, Query: "file:README"})
+	if err != errCanceled {
+		t.Fatalf("got error %v, want %v", err, errCanceled)
+	}
+	if !errcode.IsNonRetryable(err) {
+		t.Error("cancellation is retryable")
+	}
+	if len(searcher.queries) != 2 {
+		t.Errorf("got %d queries, want 2", len(searcher.queries))
+	}
+}
+
+func TestHandler_invalidQuery(t *testing.T) {
+	h, _, _, _ := newTestHandler(t, "foo")
+
+	err := h.Handle(context.Background(), &Job{ID: 1, Query: "foo case:maybe"})
+	if err == nil || !errcode.IsNonRetryable(err) {
+		t.Fatalf("got error %v, want non-retryable error", err)
+	}
+}
+
+func TestHandler_uploadError(t *testing.T) {
+	h, _, _, _ := newTestHandler(t, "foo", "github.com/a/b")
+	h.results.(*uploadstoremocks.MockStore).UploadFunc.SetDefaultReturn(0, errors.New("boom"))
+
+	err := h.Handle(context.Background(), &Job{ID: 1, Query: "foo"})
+	if err == nil || errcode.IsNonRetryable(err) {
+		t.Fatalf("got error %v, want retryable error", err)
+	}
+}
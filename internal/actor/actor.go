@@ -24,6 +24,15 @@ type Actor struct {
 	// to selectively display a logout link. (If the actor wasn't authenticated with a session
 	// cookie, logout would be ineffective.)
 	FromSessionCookie bool `json:"-"`
+
+	// Scopes are the scopes of the access token used to authenticate the actor, if the token is
+	// restricted to them (i.e., it doesn't have the "user:all" scope). It is nil if the actor may
+	// perform any action the user may.
+	Scopes []string `json:"-"`
+
+	// AccessTokenID is the ID of the access token used to authenticate the actor, or 0 if the actor
+	// wasn't authenticated with an access token. It is recorded in the audit log.
+	AccessTokenID int64 `json:"-"`
 }
 
 // FromUser returns an actor corresponding to a user
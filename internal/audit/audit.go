@@ -0,0 +1,121 @@
+// Package audit records the audit log: every mutating GraphQL operation and admin API call, with
+// the actor who performed it, where they performed it from, what it was performed on and how it
+// changed its target. Entries are stored in the database, from which they can be exported, and
+// forwarded to syslog if configured.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+var metricEntries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_audit_log_entries_total",
+	Help: "The number of audit log entries, by outcome (success, failure or write_failed).",
+}, []string{"outcome"})
+
+// Record is an audit log entry that is being recorded while its action is performed.
+type Record struct {
+	mu    sync.Mutex
+	entry database.AuditLogEntry
+}
+
+type recordKey struct{}
+
+// Begin starts recording the action (such as "graphql.deleteUser") performed by the actor of ctx.
+// The arguments are recorded with sensitive values redacted. If the arguments identify the target
+// of the action by ID, it is recorded as the target.
+//
+// The returned context carries the record, so that the code performing the action can describe
+// the target and how it changed with SetTarget and SetChange. The entry is written by Finish.
+func Begin(ctx context.Context, action string, arguments map[string]interface{}) (context.Context, *Record) {
+	r := &Record{entry: database.AuditLogEntry{
+		Action:    action,
+		Target:    targetFromArguments(arguments),
+		Arguments: redactArguments(arguments),
+	}}
+	return context.WithValue(ctx, recordKey{}, r), r
+}
+
+func fromContext(ctx context.Context) *Record {
+	r, _ := ctx.Value(recordKey{}).(*Record)
+	return r
+}
+
+// SetTarget records what the action being recorded in ctx is performed on, such as "external
+// service 12". It does nothing if no action is being recorded.
+func SetTarget(ctx context.Context, format string, args ...interface{}) {
+	if r := fromContext(ctx); r != nil {
+		r.mu.Lock()
+		r.entry.Target = fmt.Sprintf(format, args...)
+		r.mu.Unlock()
+	}
+}
+
+// SetChange records a summary of the target before and after the action being recorded in ctx,
+// such as "site admin: false" and "site admin: true". It does nothing if no action is being
+// recorded.
+//
+// 🚨 SECURITY: The summaries must not contain secrets, such as the tokens in the configuration of
+// an external service.
+func SetChange(ctx context.Context, before, after string) {
+	if r := fromContext(ctx); r != nil {
+		r.mu.Lock()
+		r.entry.Before, r.entry.After = before, after
+		r.mu.Unlock()
+	}
+}
+
+// Finish writes the entry for the recorded action, which failed if err is non-nil.
+func (r *Record) Finish(ctx context.Context, db dbutil.DB, err error) {
+	r.mu.Lock()
+	e := r.entry
+	r.mu.Unlock()
+
+	e.Outcome = database.AuditLogOutcomeSuccess
+	if err != nil {
+		e.Outcome = database.AuditLogOutcomeFailure
+		e.Error = err.Error()
+	}
+	Log(ctx, db, &e)
+}
+
+// Log writes an entry for an action performed by the actor of ctx, from the client of the HTTP
+// request of ctx (see WithRequest). It sets the actor and the client of the entry.
+//
+// Note that it does not return an error and will instead simply log it, like
+// database.SecurityEventLogStore.LogEvent. Actions are not failed because they couldn't be
+// audited.
+func Log(ctx context.Context, db dbutil.DB, e *database.AuditLogEntry) {
+	a := actor.FromContext(ctx)
+	e.ActorUserID = a.UID
+	e.AccessTokenID = a.AccessTokenID
+	if a.IsAuthenticated() {
+		// The username is stored so that the entry identifies the actor after they're deleted.
+		if user, err := database.Users(db).GetByID(ctx, a.UID); err == nil {
+			e.ActorUsername = user.Username
+		}
+	}
+	if c := clientFromContext(ctx); c != nil {
+		e.IP, e.UserAgent = c.IP, c.UserAgent
+	}
+
+	// The entry is written even if the request was canceled, because the action may still have
+	// been performed.
+	if err := database.AuditLogs(db).Insert(context.Background(), e); err != nil {
+		log15.Error("Failed to write audit log entry.", "action", e.Action, "actor", e.ActorUserID, "error", err)
+		metricEntries.WithLabelValues("write_failed").Inc()
+		return
+	}
+	metricEntries.WithLabelValues(e.Outcome).Inc()
+	forward(e)
+}
@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/graph-gophers/graphql-go/relay"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// mockInsert records the inserted entries instead of writing them to the database.
+func mockInsert(t *testing.T) *[]*database.AuditLogEntry {
+	var entries []*database.AuditLogEntry
+	database.Mocks.AuditLogs.Insert = func(ctx context.Context, e *database.AuditLogEntry) error {
+		entries = append(entries, e)
+		return nil
+	}
+	database.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	t.Cleanup(func() { database.Mocks = database.MockStores{} })
+	return &entries
+}
+
+func TestRedactArguments(t *testing.T) {
+	got := string(redactArguments(map[string]interface{}{
+		"lastID":      3,
+		"input":       `{"auth.providers": []}`,
+		"user":        "VXNlcjox",
+		"newPassword": "hunter2",
+		"externalService": map[string]interface{}{
+			"displayName": "GitHub",
+			"config":      `{"token": "secret"}`,
+		},
+		"edits": []interface{}{
+			map[string]interface{}{"keyPath": "a", "value": "secret"},
+		},
+		"spec": string(make([]byte, maxArgumentLength+1)),
+	}))
+	want := `{"edits":[{"keyPath":"a","value":"REDACTED"}],"externalService":{"config":"REDACTED","displayName":"GitHub"},"input":"REDACTED (22 bytes)","lastID":3,"newPassword":"REDACTED","spec":"REDACTED (257 bytes)","user":"VXNlcjox"}`
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("arguments mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := redactArguments(nil); got != nil {
+		t.Errorf("got %q for no arguments, want nil", got)
+	}
+}
+
+func TestTargetFromArguments(t *testing.T) {
+	got := targetFromArguments(map[string]interface{}{
+		"userID":          string(relay.MarshalID("User", 12)),
+		"externalService": string(relay.MarshalID("ExternalService", 3)),
+		"id":              string(relay.MarshalID("BatchChange", "abc")),
+		"namespaceID":     "not a relay ID",
+		"siteAdmin":       true,
+	})
+	if want := "BatchChange abc, User 12, not a relay ID"; got != want {
+		t.Errorf("got target %q, want %q", got, want)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	entries := mockInsert(t)
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1, AccessTokenID: 7})
+	req := httptest.NewRequest("POST", "/.api/graphql", nil)
+	// The header is set by the client, so it is ignored without trusted proxies.
+	req.Header.Set("X-Forwarded-For", "203.0.113.1, 10.0.0.1")
+	req.Header.Set("User-Agent", "src-cli")
+	ctx = WithRequest(ctx, req)
+
+	ctx, r := Begin(ctx, "graphql.setUserIsSiteAdmin", map[string]interface{}{"userID": string(relay.MarshalID("User", 2)), "siteAdmin": true})
+	SetChange(ctx, "role_user", "role_site_admin")
+	r.Finish(ctx, nil, nil)
+
+	ctx, r = Begin(ctx, "graphql.updateExternalService", nil)
+	SetTarget(ctx, "external service %d", 3)
+	r.Finish(ctx, nil, errors.New("boom"))
+
+	// SetTarget and SetChange do nothing outside of a record.
+	SetTarget(context.Background(), "x")
+	SetChange(context.Background(), "a", "b")
+
+	want := []*database.AuditLogEntry{
+		{
+			ActorUserID:   1,
+			ActorUsername: "alice",
+			AccessTokenID: 7,
+			IP:            "192.0.2.1",
+			UserAgent:     "src-cli",
+			Action:        "graphql.setUserIsSiteAdmin",
+			Target:        "User 2",
+			Arguments:     []byte(`{"siteAdmin":true,"userID":"VXNlcjoy"}`),
+			Before:        "role_user",
+			After:         "role_site_admin",
+			Outcome:       database.AuditLogOutcomeSuccess,
+		},
+		{
+			ActorUserID:   1,
+			ActorUsername: "alice",
+			AccessTokenID: 7,
+			IP:            "192.0.2.1",
+			UserAgent:     "src-cli",
+			Action:        "graphql.updateExternalService",
+			Target:        "external service 3",
+			Outcome:       database.AuditLogOutcomeFailure,
+			Error:         "boom",
+		},
+	}
+	if diff := cmp.Diff(want, *entries); diff != "" {
+		t.Errorf("entries mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	entries := mockInsert(t)
+
+	database.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice", SiteAdmin: id == 2}, nil
+	}
+	admin := func(r *http.Request) *http.Request {
+		return r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: 2}))
+	}
+
+	h := Middleware(nil, func(r *http.Request) bool { return r.URL.Path == "/skip" }, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c := clientFromContext(r.Context()); c == nil || c.IP != "192.0.2.1" {
+			t.Errorf("got client %+v, want IP 192.0.2.1", c)
+		}
+		if r.URL.Path == "/fail" {
+			http.Error(w, "nope", http.StatusForbidden)
+		}
+	}))
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/read", nil),
+		admin(httptest.NewRequest("GET", "/admin/read", nil)),
+		admin(httptest.NewRequest("GET", "/skip", nil)),
+		httptest.NewRequest("POST", "/skip", nil),
+		httptest.NewRequest("DELETE", "/users/1?token=abc&force=true", nil),
+		httptest.NewRequest("POST", "/fail", nil),
+	} {
+		req.RemoteAddr = "192.0.2.1:1234"
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := []*database.AuditLogEntry{
+		{
+			ActorUserID:   2,
+			ActorUsername: "alice",
+			IP:            "192.0.2.1",
+			Action:        "http.GET /admin/read",
+			Outcome:       database.AuditLogOutcomeSuccess,
+		},
+		{
+			IP:        "192.0.2.1",
+			Action:    "http.DELETE /users/1",
+			Arguments: []byte(`{"force":"true","token":"REDACTED"}`),
+			Outcome:   database.AuditLogOutcomeSuccess,
+		},
+		{
+			IP:      "192.0.2.1",
+			Action:  "http.POST /fail",
+			Outcome: database.AuditLogOutcomeFailure,
+			Error:   "HTTP status 403",
+		},
+	}
+	if diff := cmp.Diff(want, *entries); diff != "" {
+		t.Errorf("entries mismatch (-want +got):\n%s", diff)
+	}
+}
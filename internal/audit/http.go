@@ -0,0 +1,127 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/clientip"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// client is the client of the HTTP request in which an action is performed.
+type client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientKey struct{}
+
+// WithRequest returns a context that records the client of r (its IP address and user agent) in
+// the audit log entries written with it.
+func WithRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, clientKey{}, &client{IP: clientip.FromRequest(r), UserAgent: r.UserAgent()})
+}
+
+func clientFromContext(ctx context.Context) *client {
+	c, _ := ctx.Value(clientKey{}).(*client)
+	return c
+}
+
+// Middleware records the client of each request (see WithRequest) and writes an audit log entry
+// for each call that may mutate state, that is, each request whose method is not GET, HEAD or
+// OPTIONS, and for each call made by a site admin, whatever its method. Requests for which skip
+// returns true are not audited, such as GraphQL requests (whose mutations are audited one by one)
+// and webhooks.
+//
+// It must be called after the actor is set on the request context, so that the actor is
+// recorded.
+func Middleware(db dbutil.DB, skip func(*http.Request) bool, next http.Handler) http.Handler {
+	return middleware(db, skip, isSiteAdmin, next)
+}
+
+// AdminMiddleware is like Middleware, but writes an audit log entry for every request. It is used
+// for handlers that implement their own authentication and only serve admins, such as SCIM.
+func AdminMiddleware(db dbutil.DB, next http.Handler) http.Handler {
+	return middleware(db, nil, func(dbutil.DB, *http.Request) bool { return true }, next)
+}
+
+func middleware(db dbutil.DB, skip func(*http.Request) bool, auditReads func(dbutil.DB, *http.Request) bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(WithRequest(r.Context(), r))
+		if skip != nil && skip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if !auditReads(db, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		// The request body is not recorded, because it may contain secrets. The query parameters
+		// are recorded like the arguments of GraphQL mutations.
+		query := make(map[string]interface{}, len(r.URL.Query()))
+		for name, values := range r.URL.Query() {
+			query[name] = strings.Join(values, ",")
+		}
+		e := &database.AuditLogEntry{
+			Action:    "http." + r.Method + " " + r.URL.Path,
+			Arguments: redactArguments(query),
+			Outcome:   database.AuditLogOutcomeSuccess,
+		}
+		if sw.status >= http.StatusBadRequest {
+			e.Outcome = database.AuditLogOutcomeFailure
+			e.Error = fmt.Sprintf("HTTP status %d", sw.status)
+		}
+		Log(r.Context(), db, e)
+	})
+}
+
+// isSiteAdmin reports whether the actor of r is a site admin. Internal actors are not, because
+// their calls are made on behalf of other requests.
+func isSiteAdmin(db dbutil.DB, r *http.Request) bool {
+	a := actor.FromContext(r.Context())
+	if !a.IsAuthenticated() {
+		return false
+	}
+	user, err := database.Users(db).GetByID(r.Context(), a.UID)
+	if err != nil {
+		if !errcode.IsNotFound(err) {
+			log15.Error("Failed to get user to audit HTTP API call.", "user", a.UID, "error", err)
+		}
+		return false
+	}
+	return user.SiteAdmin
+}
+
+// statusWriter records the status code of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
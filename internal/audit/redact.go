@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+// redacted replaces sensitive argument values.
+const redacted = "REDACTED"
+
+// maxArgumentLength is the length above which string arguments are not recorded. Long strings are
+// usually documents (such as settings or batch specs) that would bloat the audit log.
+const maxArgumentLength = 256
+
+// sensitiveArgumentSuffixes are the (lowercase) suffixes of the names of arguments whose values
+// are never recorded, because they may be or contain secrets.
+var sensitiveArgumentSuffixes = []string{
+	"password",
+	"token",
+	"secret",
+	"key",
+	"credential",
+	"config",
+	"contents",
+	"value",
+}
+
+// redactArguments returns the JSON encoding of arguments with sensitive values redacted, or nil if
+// there are no arguments.
+func redactArguments(arguments map[string]interface{}) json.RawMessage {
+	if len(arguments) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(redactValue("", arguments))
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+func redactValue(name string, v interface{}) interface{} {
+	if isSensitiveArgument(name) {
+		return redacted
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = redactValue(k, e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = redactValue(name, e)
+		}
+		return l
+	case string:
+		// The site configuration is a string argument named input.
+		if strings.EqualFold(name, "input") || len(v) > maxArgumentLength {
+			return fmt.Sprintf("%s (%d bytes)", redacted, len(v))
+		}
+	}
+	return v
+}
+
+func isSensitiveArgument(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range sensitiveArgumentSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetFromArguments returns the target of a GraphQL mutation identified by its top-level ID
+// arguments, such as "User 12" for the argument user: "VXNlcjoxMg==". It returns "" if there are
+// no such arguments.
+func targetFromArguments(arguments map[string]interface{}) string {
+	var targets []string
+	for name, v := range arguments {
+		id, ok := v.(string)
+		if !ok || !(name == "id" || strings.HasSuffix(name, "ID")) {
+			continue
+		}
+		targets = append(targets, describeID(graphql.ID(id)))
+	}
+	sort.Strings(targets)
+	return strings.Join(targets, ", ")
+}
+
+// describeID returns a readable description of a GraphQL node ID, such as "User 12".
+func describeID(id graphql.ID) string {
+	kind := relay.UnmarshalKind(id)
+	var spec interface{}
+	if kind == "" || relay.UnmarshalSpec(id, &spec) != nil {
+		return string(id)
+	}
+	if s, ok := spec.(string); ok {
+		return kind + " " + s
+	}
+	return fmt.Sprintf("%s %v", kind, spec)
+}
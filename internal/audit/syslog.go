@@ -0,0 +1,204 @@
+package audit
+
+import (
+	"encoding/json"
+	"log/syslog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+var metricSyslogMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "src_audit_log_syslog_messages_total",
+	Help: "The number of audit log entries forwarded to syslog (sent) or dropped (dropped), and of failed attempts to forward them (failed).",
+}, []string{"status"})
+
+const (
+	defaultSyslogNetwork = "tcp"
+	defaultSyslogTag     = "sourcegraph-audit"
+
+	// syslogQueueSize is the number of entries that are queued while the syslog server is slow.
+	// Further entries are dropped, so that actions aren't slowed down.
+	syslogQueueSize = 1000
+
+	// syslogBufferSize is the number of entries that are kept while the syslog server is
+	// unreachable, and sent once it is reachable again. Older entries are dropped.
+	syslogBufferSize = 10000
+)
+
+// syslogRetryInterval is the minimum interval between attempts to connect to the syslog server.
+var syslogRetryInterval = 10 * time.Second
+
+var (
+	forwarderMu sync.Mutex
+	forwarder   *syslogForwarder
+)
+
+// Watch forwards audit log entries to the syslog server configured in the site configuration
+// (auditLog.syslog), and follows changes to the configuration.
+func Watch() {
+	var current *schema.Syslog
+	conf.Watch(func() {
+		var c *schema.Syslog
+		if auditLog := conf.Get().AuditLog; auditLog != nil {
+			c = auditLog.Syslog
+		}
+		if reflect.DeepEqual(c, current) {
+			return
+		}
+		current = c
+
+		forwarderMu.Lock()
+		defer forwarderMu.Unlock()
+		if forwarder != nil {
+			forwarder.stop()
+			forwarder = nil
+		}
+		if c != nil {
+			forwarder = newSyslogForwarder(c)
+		}
+	})
+}
+
+// forward forwards e to the syslog server, if one is configured.
+func forward(e *database.AuditLogEntry) {
+	forwarderMu.Lock()
+	defer forwarderMu.Unlock()
+	if forwarder != nil {
+		forwarder.send(e)
+	}
+}
+
+// syslogForwarder sends audit log entries to a syslog server as JSON messages, in the background.
+type syslogForwarder struct {
+	network, address, tag string
+
+	queue chan *database.AuditLogEntry
+	done  chan struct{}
+
+	// The state of the connection, only used by run.
+	w           *syslog.Writer
+	lastAttempt time.Time
+	pending     []*database.AuditLogEntry // entries that are not sent yet, oldest first
+	dropped     int                       // entries dropped since the last successful send
+}
+
+func newSyslogForwarder(c *schema.Syslog) *syslogForwarder {
+	f := &syslogForwarder{
+		network: c.Network,
+		address: c.Address,
+		tag:     c.Tag,
+		queue:   make(chan *database.AuditLogEntry, syslogQueueSize),
+		done:    make(chan struct{}),
+	}
+	if f.network == "" {
+		f.network = defaultSyslogNetwork
+	}
+	if f.tag == "" {
+		f.tag = defaultSyslogTag
+	}
+	go f.run()
+	return f
+}
+
+func (f *syslogForwarder) send(e *database.AuditLogEntry) {
+	select {
+	case f.queue <- e:
+	default:
+		metricSyslogMessages.WithLabelValues("dropped").Inc()
+	}
+}
+
+// stop stops the forwarder after it sends the queued entries.
+func (f *syslogForwarder) stop() {
+	close(f.queue)
+}
+
+func (f *syslogForwarder) run() {
+	defer close(f.done)
+	log := log15.Root().New("svc", "audit.syslog", "address", f.address)
+	defer func() {
+		if f.w != nil {
+			f.w.Close()
+		}
+	}()
+
+	// Buffered entries are retried periodically, even if no new entries are sent.
+	ticker := time.NewTicker(syslogRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case e, ok := <-f.queue:
+			if !ok {
+				// Make a last attempt to send the buffered entries.
+				f.lastAttempt = time.Time{}
+				f.flush(log)
+				if len(f.pending) > 0 {
+					metricSyslogMessages.WithLabelValues("dropped").Add(float64(len(f.pending)))
+				}
+				return
+			}
+			f.pending = append(f.pending, e)
+			if len(f.pending) > syslogBufferSize {
+				f.pending = f.pending[1:]
+				f.dropped++
+				metricSyslogMessages.WithLabelValues("dropped").Inc()
+			}
+		case <-ticker.C:
+		}
+		f.flush(log)
+	}
+}
+
+// flush sends the pending entries in order, connecting to the syslog server if needed. It stops
+// at the first entry that can't be sent, which is retried later.
+func (f *syslogForwarder) flush(log log15.Logger) {
+	if len(f.pending) == 0 {
+		return
+	}
+	if f.w == nil {
+		if time.Since(f.lastAttempt) < syslogRetryInterval {
+			return
+		}
+		f.lastAttempt = time.Now()
+		var err error
+		if f.w, err = syslog.Dial(f.network, f.address, syslog.LOG_INFO|syslog.LOG_AUTH, f.tag); err != nil {
+			log.Error("Failed to connect to syslog server. Audit log entries are buffered until it is reachable.", "error", err, "buffered", len(f.pending))
+			metricSyslogMessages.WithLabelValues("failed").Inc()
+			return
+		}
+	}
+
+	for len(f.pending) > 0 {
+		msg, err := json.Marshal(f.pending[0])
+		if err != nil {
+			metricSyslogMessages.WithLabelValues("failed").Inc()
+			f.pending = f.pending[1:]
+			continue
+		}
+		// The writer reconnects once if writing fails.
+		if err := f.w.Info(string(msg)); err != nil {
+			log.Error("Failed to forward audit log entry to syslog server.", "error", err)
+			metricSyslogMessages.WithLabelValues("failed").Inc()
+			f.w.Close()
+			f.w = nil
+			return
+		}
+		metricSyslogMessages.WithLabelValues("sent").Inc()
+		f.pending[0] = nil
+		f.pending = f.pending[1:]
+	}
+	if f.dropped > 0 {
+		log.Warn("Audit log entries were dropped while the syslog server was unreachable. They are still recorded in the database.", "dropped", f.dropped)
+		f.dropped = 0
+	}
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/database"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestSyslogForwarder(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	lines := make(chan string)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		s := bufio.NewScanner(conn)
+		for s.Scan() {
+			lines <- s.Text()
+		}
+		close(lines)
+	}()
+
+	f := newSyslogForwarder(&schema.Syslog{Address: l.Addr().String()})
+	f.send(&database.AuditLogEntry{ID: 1, Action: "graphql.deleteUser", Target: "User 2", Outcome: database.AuditLogOutcomeSuccess})
+	f.send(&database.AuditLogEntry{ID: 2, Action: "graphql.addExternalService", Outcome: database.AuditLogOutcomeFailure})
+	f.stop()
+	<-f.done
+
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d messages, want 2: %q", len(got), got)
+	}
+	for _, want := range []string{defaultSyslogTag + "[", `{"id":1,`, `"action":"graphql.deleteUser","target":"User 2"`} {
+		if !strings.Contains(got[0], want) {
+			t.Errorf("got message %q, want it to contain %q", got[0], want)
+		}
+	}
+	if !strings.Contains(got[1], `"outcome":"failure"`) {
+		t.Errorf("got message %q, want it to contain the outcome", got[1])
+	}
+}
+
+func TestSyslogForwarder_unreachable(t *testing.T) {
+	old := syslogRetryInterval
+	syslogRetryInterval = 10 * time.Millisecond
+	defer func() { syslogRetryInterval = old }()
+
+	// Reserve an address, and only listen on it once entries were sent.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	f := newSyslogForwarder(&schema.Syslog{Address: addr})
+	defer func() {
+		f.stop()
+		<-f.done
+	}()
+	for i := 1; i <= 3; i++ {
+		f.send(&database.AuditLogEntry{ID: int64(i), Action: "graphql.deleteUser"})
+	}
+	time.Sleep(5 * syslogRetryInterval)
+
+	if l, err = net.Listen("tcp", addr); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The entries sent while the server was unreachable are sent once it is reachable.
+	s := bufio.NewScanner(conn)
+	for i := 1; i <= 3; i++ {
+		if !s.Scan() {
+			t.Fatalf("got %d messages, want 3: %v", i-1, s.Err())
+		}
+		if want := `{"id":` + strconv.Itoa(i) + `,`; !strings.Contains(s.Text(), want) {
+			t.Errorf("got message %q, want it to contain %q", s.Text(), want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package bitbucketcloud
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// NewAuthzProviders returns the set of Bitbucket Cloud authz providers derived from the connections.
+// It also returns any validation problems with the config, separating these into "serious problems" and
+// "warnings". "Serious problems" are those that should make Sourcegraph set authz.allowAccessByDefault
+// to false. "Warnings" are all other validation problems.
+func NewAuthzProviders(
+	cfg *conf.Unified,
+	conns []*types.BitbucketCloudConnection,
+) (ps []authz.Provider, problems []string, warnings []string) {
+	for _, c := range conns {
+		p, err := newAuthzProvider(c, cfg.AuthProviders, nil)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if p != nil {
+			ps = append(ps, p)
+		}
+	}
+
+	for _, p := range ps {
+		for _, problem := range p.Validate() {
+			warnings = append(warnings, fmt.Sprintf("Bitbucket Cloud config for %s was invalid: %s", p.ServiceID(), problem))
+		}
+	}
+
+	return ps, problems, warnings
+}
+
+func newAuthzProvider(c *types.BitbucketCloudConnection, ps []schema.AuthProviders, cli httpcli.Doer) (authz.Provider, error) {
+	if c.Authorization == nil {
+		return nil, nil
+	}
+
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse URL for Bitbucket Cloud %q", c.Url)
+	}
+	baseURL = extsvc.NormalizeBaseURL(baseURL)
+
+	rawAPIURL := c.ApiURL
+	if rawAPIURL == "" {
+		rawAPIURL = "https://api.bitbucket.org"
+	}
+	apiURL, err := url.Parse(rawAPIURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse API URL for Bitbucket Cloud %q", rawAPIURL)
+	}
+	apiURL = extsvc.NormalizeBaseURL(apiURL)
+
+	switch idp := c.Authorization.IdentityProvider; idp.Type {
+	case "oauth":
+		for _, p := range ps {
+			if p.BitbucketCloud == nil {
+				continue
+			}
+			rawURL := p.BitbucketCloud.Url
+			if rawURL == "" {
+				rawURL = "https://bitbucket.org/"
+			}
+			authnURL, err := url.Parse(rawURL)
+			if err != nil {
+				// Ignore the error here, because the authn provider is responsible for its own validation
+				continue
+			}
+			if extsvc.NormalizeBaseURL(authnURL).String() != baseURL.String() {
+				continue
+			}
+
+			return NewProvider(bitbucketcloud.NewClient(apiURL, cli), c.URN, baseURL, &oauth2.Config{
+				ClientID:     p.BitbucketCloud.ClientKey,
+				ClientSecret: p.BitbucketCloud.ClientSecret,
+				Endpoint:     bitbucketcloud.OAuthEndpoint(baseURL),
+			}), nil
+		}
+		return nil, errors.Errorf("Did not find authentication provider matching %q. Check the [**site configuration**](/site-admin/configuration) to verify an entry in [`auth.providers`](https://docs.sourcegraph.com/admin/auth) exists for %s.", c.Url, c.Url)
+	case "":
+		return nil, errors.New("No identityProvider was specified")
+	default:
+		return nil, errors.Errorf("unsupported identityProvider type %q", idp.Type)
+	}
+}
+
+// ValidateAuthz validates the authorization fields of the given Bitbucket Cloud external
+// service config.
+func ValidateAuthz(c *schema.BitbucketCloudConnection, ps []schema.AuthProviders) error {
+	_, err := newAuthzProvider(&types.BitbucketCloudConnection{BitbucketCloudConnection: c}, ps, nil)
+	return err
+}
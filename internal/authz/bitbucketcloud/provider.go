@@ -0,0 +1,130 @@
+// Package bitbucketcloud contains an authorization provider for Bitbucket Cloud.
+package bitbucketcloud
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// Provider is an implementation of AuthzProvider that provides repository permissions as
+// determined from the Bitbucket Cloud API. The Bitbucket Cloud identity of a user is the
+// external account they signed in with through a Bitbucket Cloud authentication provider,
+// and their permissions are the private repositories they are a member of, listed with
+// their own OAuth token.
+type Provider struct {
+	urn          string
+	client       *bitbucketcloud.Client
+	codeHost     *extsvc.CodeHost
+	oauth2Config *oauth2.Config
+	pageSize     int // Page size to use in paginated requests.
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// NewProvider returns a new Bitbucket Cloud authorization provider that uses the given
+// bitbucketcloud.Client to talk to the Bitbucket Cloud API, and the given OAuth consumer of
+// the Bitbucket Cloud authentication provider to refresh the tokens of its users.
+func NewProvider(cli *bitbucketcloud.Client, urn string, baseURL *url.URL, oauth2Config *oauth2.Config) *Provider {
+	return &Provider{
+		urn:          urn,
+		client:       cli,
+		codeHost:     extsvc.NewCodeHost(baseURL, extsvc.TypeBitbucketCloud),
+		oauth2Config: oauth2Config,
+		pageSize:     100,
+	}
+}
+
+// Validate does nothing, because the provider only uses the tokens of users.
+func (p *Provider) Validate() []string {
+	return nil
+}
+
+func (p *Provider) URN() string {
+	return p.urn
+}
+
+// ServiceID returns the absolute URL that identifies Bitbucket Cloud.
+func (p *Provider) ServiceID() string { return p.codeHost.ServiceID }
+
+// ServiceType returns the type of this Provider, namely, "bitbucketCloud".
+func (p *Provider) ServiceType() string { return p.codeHost.ServiceType }
+
+// FetchAccount satisfies the authz.Provider interface. It always returns nil, because the
+// accounts are created when users sign in.
+func (p *Provider) FetchAccount(context.Context, *types.User, []*extsvc.Account, []string) (*extsvc.Account, error) {
+	return nil, nil
+}
+
+// FetchUserPerms returns a list of repository UUIDs (on code host) that the given account
+// has read access on the code host. The repository ID has the same value as it would be
+// used as api.ExternalRepoSpec.ID. The returned list only includes private repository IDs.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.atlassian.com/cloud/bitbucket/rest/api-group-repositories/#api-repositories-get
+func (p *Provider) FetchUserPerms(ctx context.Context, account *extsvc.Account) (*authz.ExternalUserPermissions, error) {
+	switch {
+	case account == nil:
+		return nil, errors.New("no account provided")
+	case !extsvc.IsHostOfAccount(p.codeHost, account):
+		return nil, errors.Errorf("not a code host of the account: want %q but have %q",
+			p.codeHost.ServiceID, account.AccountSpec.ServiceID)
+	}
+
+	_, tok, err := bitbucketcloud.GetExternalAccountData(&account.AccountData)
+	if err != nil {
+		return nil, errors.Wrap(err, "get external account data")
+	} else if tok == nil {
+		return nil, errors.New("no token found in the external account data")
+	}
+
+	tok, err = authz.RefreshOAuthToken(ctx, p.oauth2Config, tok)
+	if err != nil {
+		return nil, err
+	}
+	return p.FetchUserPermsByToken(ctx, tok.AccessToken)
+}
+
+// FetchUserPermsByToken is the same as FetchUserPerms but it only requires a token.
+func (p *Provider) FetchUserPermsByToken(ctx context.Context, token string) (*authz.ExternalUserPermissions, error) {
+	cli := p.client.WithToken(token)
+
+	var extIDs []extsvc.RepoID
+	for page := (&bitbucketcloud.PageToken{Pagelen: p.pageSize}); ; {
+		repos, next, err := cli.CurrentUserRepos(ctx, page)
+		if err != nil {
+			return &authz.ExternalUserPermissions{Exacts: extIDs}, err
+		}
+
+		for _, r := range repos {
+			if r.IsPrivate {
+				extIDs = append(extIDs, extsvc.RepoID(r.UUID))
+			}
+		}
+
+		if !next.HasMore() {
+			break
+		}
+		page = next
+	}
+
+	return &authz.ExternalUserPermissions{
+		Exacts: extIDs,
+	}, nil
+}
+
+// FetchRepoPerms is not supported, because Bitbucket Cloud doesn't list the users who have
+// access to a repository through the groups of its workspace. Permissions are only synced
+// from the users' side.
+func (p *Provider) FetchRepoPerms(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error) {
+	return nil, errors.Wrap(authz.ErrUnimplemented, "fetching repository permissions from Bitbucket Cloud")
+}
@@ -0,0 +1,161 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestProvider_FetchUserPerms(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer alice-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/2.0/repositories" || r.URL.Query().Get("role") != "member" {
+			http.NotFound(w, r)
+			return
+		}
+
+		// The repositories are returned one per page.
+		repos := []map[string]interface{}{
+			{"uuid": "{1}", "full_name": "acme/api", "is_private": true},
+			{"uuid": "{2}", "full_name": "acme/web", "is_private": false},
+			{"uuid": "{3}", "full_name": "alice/notes", "is_private": true},
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		resp := map[string]interface{}{"values": repos[page-1 : page]}
+		if page < len(repos) {
+			resp["next"] = srv.URL + "/2.0/repositories?role=member&page=" + strconv.Itoa(page+1)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	apiURL, _ := url.Parse(srv.URL)
+	baseURL, _ := url.Parse("https://bitbucket.org/")
+	p := NewProvider(bitbucketcloud.NewClient(apiURL, srv.Client()), "extsvc:bitbucketCloud:1", baseURL, nil)
+	p.pageSize = 1
+
+	account := func(serviceID, token string) *extsvc.Account {
+		var acct extsvc.Account
+		acct.ServiceType = extsvc.TypeBitbucketCloud
+		acct.ServiceID = serviceID
+		bitbucketcloud.SetExternalAccountData(&acct.AccountData, &bitbucketcloud.User{UUID: "{alice}"}, &oauth2.Token{AccessToken: token})
+		return &acct
+	}
+
+	if acct, err := p.FetchAccount(context.Background(), &types.User{Username: "alice"}, nil, nil); acct != nil || err != nil {
+		t.Fatalf("have (%v, %v) from FetchAccount, want (nil, nil)", acct, err)
+	}
+
+	t.Run("account of another code host", func(t *testing.T) {
+		if _, err := p.FetchUserPerms(context.Background(), account("https://bitbucket.example.com/", "alice-token")); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("user token", func(t *testing.T) {
+		perms, err := p.FetchUserPerms(context.Background(), account(p.ServiceID(), "alice-token"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]extsvc.RepoID{"{1}", "{3}"}, perms.Exacts); diff != "" {
+			t.Errorf("repo IDs mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		_, err := p.FetchUserPerms(context.Background(), account(p.ServiceID(), "revoked"))
+		if !errcode.IsUnauthorized(err) {
+			t.Fatalf("have error %v, want an unauthorized error", err)
+		}
+	})
+
+	t.Run("repository permissions", func(t *testing.T) {
+		_, err := p.FetchRepoPerms(context.Background(), &extsvc.Repository{})
+		if !errors.Is(err, authz.ErrUnimplemented) {
+			t.Fatalf("have error %v, want ErrUnimplemented", err)
+		}
+	})
+}
+
+func TestValidateAuthz(t *testing.T) {
+	authProviders := []schema.AuthProviders{{
+		BitbucketCloud: &schema.BitbucketCloudAuthProvider{Type: extsvc.TypeBitbucketCloud, ClientKey: "key"},
+	}}
+
+	for _, tc := range []struct {
+		name string
+		conn *schema.BitbucketCloudConnection
+		ps   []schema.AuthProviders
+		err  string
+	}{
+		{
+			name: "no authorization",
+			conn: &schema.BitbucketCloudConnection{Url: "https://bitbucket.org"},
+		},
+		{
+			name: "oauth",
+			conn: &schema.BitbucketCloudConnection{
+				Url: "https://bitbucket.org",
+				Authorization: &schema.BitbucketCloudAuthorization{
+					IdentityProvider: schema.BitbucketCloudIdentityProvider{Type: "oauth"},
+				},
+			},
+			ps: authProviders,
+		},
+		{
+			name: "oauth without authentication provider",
+			conn: &schema.BitbucketCloudConnection{
+				Url: "https://bitbucket.org",
+				Authorization: &schema.BitbucketCloudAuthorization{
+					IdentityProvider: schema.BitbucketCloudIdentityProvider{Type: "oauth"},
+				},
+			},
+			err: "Did not find authentication provider matching \"https://bitbucket.org\". Check the [**site configuration**](/site-admin/configuration) to verify an entry in [`auth.providers`](https://docs.sourcegraph.com/admin/auth) exists for https://bitbucket.org.",
+		},
+		{
+			name: "missing identity provider",
+			conn: &schema.BitbucketCloudConnection{
+				Url:           "https://bitbucket.org",
+				Authorization: &schema.BitbucketCloudAuthorization{},
+			},
+			ps:  authProviders,
+			err: "No identityProvider was specified",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAuthz(tc.conn, tc.ps)
+			if have := errString(err); have != tc.err {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, tc.err)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,274 @@
+// Package gitea contains an authorization provider for Gitea.
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+)
+
+// Provider is an implementation of AuthzProvider that provides repository permissions as
+// determined from a Gitea instance API. Permissions of a repository are granted to its
+// owner, its collaborators and the members of the organization teams with access to it.
+type Provider struct {
+	urn      string
+	client   *gitea.Client
+	codeHost *extsvc.CodeHost
+	pageSize int // Page size to use in paginated requests.
+
+	// oauth2Config is the OAuth application of the Gitea authentication provider, if
+	// the Gitea identities of users are their OAuth accounts.
+	oauth2Config *oauth2.Config
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// NewProvider returns a new Gitea authorization provider that uses the given gitea.Client
+// to talk to a Gitea API that is the source of truth for permissions. The client's token
+// must belong to a site admin. It assumes usernames of Sourcegraph accounts match 1-1
+// with usernames of Gitea users.
+func NewProvider(cli *gitea.Client, urn string) *Provider {
+	return &Provider{
+		urn:      urn,
+		client:   cli,
+		codeHost: extsvc.NewCodeHost(cli.URL, extsvc.TypeGitea),
+		pageSize: 50,
+	}
+}
+
+// NewOAuthProvider returns a new Gitea authorization provider like NewProvider, except that
+// the Gitea identity of a user is the external account they signed in with through the Gitea
+// authentication provider with the given OAuth application, and their permissions are
+// fetched with their own OAuth token. The client's token is still used to fetch the users
+// who have access to a repository, and must belong to a site admin.
+func NewOAuthProvider(cli *gitea.Client, urn string, oauth2Config *oauth2.Config) *Provider {
+	p := NewProvider(cli, urn)
+	p.oauth2Config = oauth2Config
+	return p
+}
+
+// Validate validates that the Provider has access to the Gitea API with the token it
+// was configured with, and that the token belongs to a site admin.
+func (p *Provider) Validate() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	user, err := p.client.AuthenticatedUser(ctx)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	if !user.IsAdmin {
+		return []string{"the token must belong to a site admin to enforce repository permissions"}
+	}
+
+	return nil
+}
+
+func (p *Provider) URN() string {
+	return p.urn
+}
+
+// ServiceID returns the absolute URL that identifies the Gitea instance this provider
+// is configured with.
+func (p *Provider) ServiceID() string { return p.codeHost.ServiceID }
+
+// ServiceType returns the type of this Provider, namely, "gitea".
+func (p *Provider) ServiceType() string { return p.codeHost.ServiceType }
+
+// FetchAccount satisfies the authz.Provider interface. It returns the Gitea user with
+// the same username as the given user, or nil if there is none. OAuth providers return
+// nil, because the accounts are created when users sign in.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, _ []*extsvc.Account, _ []string) (acct *extsvc.Account, err error) {
+	if user == nil || p.oauth2Config != nil {
+		return nil, nil
+	}
+
+	tr, ctx := trace.New(ctx, "gitea.authz.provider.FetchAccount", "")
+	defer func() {
+		tr.LogFields(
+			otlog.String("user.name", user.Username),
+			otlog.Int32("user.id", user.ID),
+		)
+
+		if err != nil {
+			tr.SetError(err)
+		}
+
+		tr.Finish()
+	}()
+
+	giteaUser, err := p.client.GetUser(ctx, user.Username)
+	if err != nil {
+		if gitea.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	accountData, err := json.Marshal(giteaUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extsvc.Account{
+		UserID: user.ID,
+		AccountSpec: extsvc.AccountSpec{
+			ServiceType: p.codeHost.ServiceType,
+			ServiceID:   p.codeHost.ServiceID,
+			AccountID:   strconv.FormatInt(giteaUser.ID, 10),
+		},
+		AccountData: extsvc.AccountData{
+			Data: (*json.RawMessage)(&accountData),
+		},
+	}, nil
+}
+
+// FetchUserPerms returns a list of repository IDs (on code host) that the given account
+// has read access on the code host. The repository ID has the same value as it would be
+// used as api.ExternalRepoSpec.ID. The returned list only includes private repository IDs.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+func (p *Provider) FetchUserPerms(ctx context.Context, account *extsvc.Account) (*authz.ExternalUserPermissions, error) {
+	switch {
+	case account == nil:
+		return nil, errors.New("no account provided")
+	case account.Data == nil:
+		return nil, errors.New("no account data provided")
+	case !extsvc.IsHostOfAccount(p.codeHost, account):
+		return nil, errors.Errorf("not a code host of the account: want %q but have %q",
+			p.codeHost.ServiceID, account.AccountSpec.ServiceID)
+	}
+
+	user, tok, err := gitea.GetExternalAccountData(&account.AccountData)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling account data")
+	}
+
+	// Impersonating the user, or using their own token, lists exactly the repositories
+	// they own or have been given access to, as a collaborator or through a team.
+	var cli *gitea.Client
+	if p.oauth2Config != nil {
+		if tok == nil {
+			return nil, errors.New("no token found in the external account data")
+		}
+		if tok, err = authz.RefreshOAuthToken(ctx, p.oauth2Config, tok); err != nil {
+			return nil, err
+		}
+		cli = p.client.WithToken(tok.AccessToken)
+	} else {
+		cli = p.client.Sudo(user.Login)
+	}
+
+	var extIDs []extsvc.RepoID
+	for page := (&gitea.Pagination{Page: 1, PerPage: p.pageSize}); page != nil; {
+		repos, next, err := cli.ListAccessibleRepos(ctx, page)
+		if err != nil {
+			return &authz.ExternalUserPermissions{Exacts: extIDs}, err
+		}
+
+		for _, r := range repos {
+			if r.Private {
+				extIDs = append(extIDs, extsvc.RepoID(strconv.FormatInt(r.ID, 10)))
+			}
+		}
+
+		page = next
+	}
+
+	return &authz.ExternalUserPermissions{
+		Exacts: extIDs,
+	}, nil
+}
+
+// FetchUserPermsByToken is currently only required for syncing permissions for
+// GitHub and GitLab on sourcegraph.com
+func (p *Provider) FetchUserPermsByToken(ctx context.Context, token string) (*authz.ExternalUserPermissions, error) {
+	return nil, errors.New("not implemented")
+}
+
+// FetchRepoPerms returns a list of user IDs (on code host) who have read access to
+// the given repo on the code host. The user ID has the same value as it would
+// be used as extsvc.Account.AccountID. The returned list includes the owner of a
+// user-owned repository, its collaborators and the members of the organization teams
+// with access to it.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+func (p *Provider) FetchRepoPerms(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error) {
+	switch {
+	case repo == nil:
+		return nil, errors.New("no repo provided")
+	case !extsvc.IsHostOfRepo(p.codeHost, &repo.ExternalRepoSpec):
+		return nil, errors.Errorf("not a code host of the repo: want %q but have %q",
+			p.codeHost.ServiceID, repo.ServiceID)
+	}
+
+	id, err := strconv.ParseInt(repo.ID, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing repository ID %q", repo.ID)
+	}
+
+	r, err := p.client.GetRepoByID(ctx, id)
+	if err != nil {
+		return nil, err
+	} else if r.Owner == nil {
+		return nil, errors.Errorf("repository %d has no owner", id)
+	}
+
+	seen := make(map[int64]bool)
+	var extIDs []extsvc.AccountID
+	add := func(users ...*gitea.User) {
+		for _, u := range users {
+			if !seen[u.ID] {
+				seen[u.ID] = true
+				extIDs = append(extIDs, extsvc.AccountID(strconv.FormatInt(u.ID, 10)))
+			}
+		}
+	}
+
+	teams, err := p.client.ListRepoTeams(ctx, r.Owner.Login, r.Name)
+	if err != nil {
+		return extIDs, err
+	}
+
+	// Repositories owned by a user have no teams, and their owner has access to them.
+	// Owners of an organization are members of its "Owners" team instead.
+	if teams == nil {
+		add(r.Owner)
+	}
+
+	for page := (&gitea.Pagination{Page: 1, PerPage: p.pageSize}); page != nil; {
+		users, next, err := p.client.ListCollaborators(ctx, r.Owner.Login, r.Name, page)
+		if err != nil {
+			return extIDs, err
+		}
+		add(users...)
+		page = next
+	}
+
+	for _, t := range teams {
+		for page := (&gitea.Pagination{Page: 1, PerPage: p.pageSize}); page != nil; {
+			users, next, err := p.client.ListTeamMembers(ctx, t.ID, page)
+			if err != nil {
+				return extIDs, err
+			}
+			add(users...)
+			page = next
+		}
+	}
+
+	return extIDs, nil
+}
@@ -0,0 +1,304 @@
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func newTestProvider(t *testing.T, f *gitea.Fixture) *Provider {
+	t.Helper()
+
+	srv := gitea.NewTestServer(t, f)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cli := gitea.NewClient(extsvc.NormalizeBaseURL(u), srv.Client())
+	cli.Token = f.Token
+	return NewProvider(cli, "extsvc:gitea:1")
+}
+
+func (p *Provider) account(t *testing.T, login string) *extsvc.Account {
+	t.Helper()
+
+	acct, err := p.FetchAccount(context.Background(), &types.User{ID: 42, Username: login}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acct
+}
+
+func TestProvider_Validate(t *testing.T) {
+	f := gitea.NewTestFixture()
+
+	t.Run("admin", func(t *testing.T) {
+		p := newTestProvider(t, f)
+		if problems := p.Validate(); len(problems) != 0 {
+			t.Fatalf("unexpected problems: %v", problems)
+		}
+	})
+
+	t.Run("non-admin", func(t *testing.T) {
+		p := newTestProvider(t, f)
+		p.client = p.client.Sudo("alice")
+
+		want := []string{"the token must belong to a site admin to enforce repository permissions"}
+		if diff := cmp.Diff(want, p.Validate()); diff != "" {
+			t.Fatalf("problems mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	f := gitea.NewTestFixture()
+	p := newTestProvider(t, f)
+
+	t.Run("nil user", func(t *testing.T) {
+		acct, err := p.FetchAccount(context.Background(), nil, nil, nil)
+		if err != nil || acct != nil {
+			t.Fatalf("have (%v, %v), want (nil, nil)", acct, err)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		if acct := p.account(t, "mallory"); acct != nil {
+			t.Fatalf("have account %+v, want nil", acct)
+		}
+	})
+
+	t.Run("known user", func(t *testing.T) {
+		acct := p.account(t, "alice")
+		if acct == nil {
+			t.Fatal("expected account")
+		}
+
+		want := extsvc.AccountSpec{
+			ServiceType: extsvc.TypeGitea,
+			ServiceID:   p.ServiceID(),
+			AccountID:   "2",
+		}
+		if diff := cmp.Diff(want, acct.AccountSpec); diff != "" {
+			t.Errorf("account spec mismatch (-want +got):\n%s", diff)
+		}
+		if acct.UserID != 42 {
+			t.Errorf("have user ID %d, want 42", acct.UserID)
+		}
+
+		var user gitea.User
+		if err := json.Unmarshal(*acct.Data, &user); err != nil {
+			t.Fatal(err)
+		}
+		if user.Login != "alice" {
+			t.Errorf("have login %q in account data, want %q", user.Login, "alice")
+		}
+	})
+}
+
+func TestProvider_FetchUserPerms(t *testing.T) {
+	f := gitea.NewTestFixture()
+	p := newTestProvider(t, f)
+	p.pageSize = 1
+
+	t.Run("account of another code host", func(t *testing.T) {
+		acct := p.account(t, "alice")
+		acct.ServiceID = "https://gitea.example.com/"
+
+		if _, err := p.FetchUserPerms(context.Background(), acct); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	for login, want := range map[string][]string{
+		"alice": {"100", "102", "104"},
+		"bob":   {"104"},
+		"carol": {"100"},
+		"dave":  {"100"},
+	} {
+		login, want := login, want
+		t.Run(login, func(t *testing.T) {
+			perms, err := p.FetchUserPerms(context.Background(), p.account(t, login))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var have []string
+			for _, id := range perms.Exacts {
+				have = append(have, string(id))
+			}
+			sort.Strings(have)
+
+			if diff := cmp.Diff(want, have); diff != "" {
+				t.Errorf("repo IDs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOAuthProvider_FetchUserPerms(t *testing.T) {
+	f := gitea.NewTestFixture()
+	p := newTestProvider(t, f)
+	p = NewOAuthProvider(p.client, p.urn, &oauth2.Config{})
+	p.pageSize = 1
+
+	account := func(login, token string) *extsvc.Account {
+		var acct extsvc.Account
+		acct.ServiceType = p.ServiceType()
+		acct.ServiceID = p.ServiceID()
+		gitea.SetExternalAccountData(&acct.AccountData, &gitea.User{Login: login}, &oauth2.Token{AccessToken: token})
+		return &acct
+	}
+
+	if acct, err := p.FetchAccount(context.Background(), &types.User{Username: "alice"}, nil, nil); acct != nil || err != nil {
+		t.Fatalf("have (%v, %v) from FetchAccount, want (nil, nil)", acct, err)
+	}
+
+	t.Run("user token", func(t *testing.T) {
+		perms, err := p.FetchUserPerms(context.Background(), account("bob", "bob-oauth-token"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]extsvc.RepoID{"104"}, perms.Exacts); diff != "" {
+			t.Errorf("repo IDs mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		_, err := p.FetchUserPerms(context.Background(), account("bob", "revoked"))
+		if !errcode.IsUnauthorized(err) {
+			t.Fatalf("have error %v, want an unauthorized error", err)
+		}
+	})
+}
+
+func TestProvider_FetchRepoPerms(t *testing.T) {
+	f := gitea.NewTestFixture()
+	p := newTestProvider(t, f)
+	p.pageSize = 1
+
+	repo := func(id int64, serviceID string) *extsvc.Repository {
+		return &extsvc.Repository{
+			URI: "gitea/" + strconv.FormatInt(id, 10),
+			ExternalRepoSpec: api.ExternalRepoSpec{
+				ID:          strconv.FormatInt(id, 10),
+				ServiceType: extsvc.TypeGitea,
+				ServiceID:   serviceID,
+			},
+		}
+	}
+
+	t.Run("repo of another code host", func(t *testing.T) {
+		if _, err := p.FetchRepoPerms(context.Background(), repo(100, "https://gitea.example.com/")); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	for _, tc := range []struct {
+		name string
+		id   int64
+		want []string
+	}{
+		// Owners team (alice), Backend team (carol) and a collaborator (dave).
+		{name: "organization repo", id: 100, want: []string{"2", "4", "5"}},
+		// Owners team (alice) only.
+		{name: "organization repo without extra access", id: 102, want: []string{"2"}},
+		// The owner (alice) and a collaborator (bob).
+		{name: "user repo", id: 104, want: []string{"2", "3"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ids, err := p.FetchRepoPerms(context.Background(), repo(tc.id, p.ServiceID()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var have []string
+			for _, id := range ids {
+				have = append(have, string(id))
+			}
+			sort.Strings(have)
+
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Errorf("account IDs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateAuthz(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		conn *schema.GiteaConnection
+		err  string
+	}{
+		{
+			name: "no authorization",
+			conn: &schema.GiteaConnection{Url: "https://gitea.example.com"},
+		},
+		{
+			name: "username",
+			conn: &schema.GiteaConnection{
+				Url: "https://gitea.example.com",
+				Authorization: &schema.GiteaAuthorization{
+					IdentityProvider: schema.GiteaIdentityProvider{Type: "username"},
+				},
+			},
+		},
+		{
+			name: "oauth",
+			conn: &schema.GiteaConnection{
+				Url: "https://gitea.example.com",
+				Authorization: &schema.GiteaAuthorization{
+					IdentityProvider: schema.GiteaIdentityProvider{Type: "oauth"},
+				},
+			},
+		},
+		{
+			name: "oauth without authentication provider",
+			conn: &schema.GiteaConnection{
+				Url: "https://forgejo.example.com",
+				Authorization: &schema.GiteaAuthorization{
+					IdentityProvider: schema.GiteaIdentityProvider{Type: "oauth"},
+				},
+			},
+			err: "Did not find authentication provider matching \"https://forgejo.example.com\". Check the [**site configuration**](/site-admin/configuration) to verify an entry in [`auth.providers`](https://docs.sourcegraph.com/admin/auth) exists for https://forgejo.example.com.",
+		},
+		{
+			name: "missing identity provider",
+			conn: &schema.GiteaConnection{
+				Url:           "https://gitea.example.com",
+				Authorization: &schema.GiteaAuthorization{},
+			},
+			err: "No identityProvider was specified",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateAuthz(tc.conn, []schema.AuthProviders{{
+				Gitea: &schema.GiteaAuthProvider{Type: extsvc.TypeGitea, Url: "https://gitea.example.com/"},
+			}})
+			if have := errString(err); have != tc.err {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, tc.err)
+			}
+		})
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -5,6 +5,8 @@ package authz
 import (
 	"context"
 
+	"github.com/cockroachdb/errors"
+
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 )
@@ -21,6 +23,10 @@ type ExternalUserPermissions struct {
 	ExcludePrefixes []extsvc.RepoID
 }
 
+// ErrUnimplemented is returned (wrapped) by the methods of a Provider that are not supported
+// by its code host.
+var ErrUnimplemented = errors.New("not supported by the authz provider")
+
 // Provider defines a source of truth of which repositories a user is authorized to view. The
 // user is identified by an extsvc.Account instance. Examples of authz providers include the
 // following:
@@ -71,6 +77,9 @@ type Provider interface {
 	// Because permissions fetching APIs are often expensive, the implementation should
 	// try to return partial but valid results in case of error, and it is up to callers
 	// to decide whether to discard.
+	//
+	// Implementations that can't list the users of a repository return an error wrapping
+	// ErrUnimplemented, in which case permissions are only synced from the users' side.
 	FetchRepoPerms(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error)
 
 	// ServiceType returns the service type (e.g., "gitlab") of this authz provider.
@@ -0,0 +1,36 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/cockroachdb/errors"
+	"golang.org/x/oauth2"
+)
+
+// RefreshOAuthToken returns the given OAuth token of an external account, refreshed with the
+// OAuth client configuration of the authentication provider it was obtained from if it has
+// expired. The refreshed token isn't saved, so this is only suitable for code hosts whose
+// refresh tokens can be used more than once.
+//
+// A token that has expired and can't be refreshed is reported as an unauthorized error, so that
+// the external account is marked as expired.
+func RefreshOAuthToken(ctx context.Context, config *oauth2.Config, tok *oauth2.Token) (*oauth2.Token, error) {
+	if tok.Valid() {
+		return tok, nil
+	}
+	if config == nil || tok.RefreshToken == "" {
+		return nil, &expiredTokenError{errors.New("OAuth token has expired and can't be refreshed")}
+	}
+
+	refreshed, err := config.TokenSource(ctx, tok).Token()
+	if err != nil {
+		return nil, &expiredTokenError{errors.Wrap(err, "refresh OAuth token")}
+	}
+	return refreshed, nil
+}
+
+type expiredTokenError struct{ error }
+
+func (e *expiredTokenError) Unauthorized() bool { return true }
+
+func (e *expiredTokenError) Unwrap() error { return e.error }
@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func TestRefreshOAuthToken(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("refresh_token") != "good-refresh-token" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"new-token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer srv.Close()
+
+	config := &oauth2.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Endpoint:     oauth2.Endpoint{TokenURL: srv.URL, AuthStyle: oauth2.AuthStyleInParams},
+	}
+	ctx := context.Background()
+	expired := time.Now().Add(-time.Minute)
+
+	t.Run("valid", func(t *testing.T) {
+		tok, err := RefreshOAuthToken(ctx, config, &oauth2.Token{AccessToken: "token"})
+		if err != nil || tok.AccessToken != "token" {
+			t.Fatalf("have (%v, %v), want the same token", tok, err)
+		}
+	})
+
+	t.Run("refreshed", func(t *testing.T) {
+		tok, err := RefreshOAuthToken(ctx, config, &oauth2.Token{AccessToken: "token", RefreshToken: "good-refresh-token", Expiry: expired})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.AccessToken != "new-token" {
+			t.Errorf("have access token %q, want %q", tok.AccessToken, "new-token")
+		}
+	})
+
+	for name, tc := range map[string]struct {
+		config *oauth2.Config
+		tok    *oauth2.Token
+	}{
+		"no refresh token":      {config, &oauth2.Token{AccessToken: "token", Expiry: expired}},
+		"no OAuth config":       {nil, &oauth2.Token{AccessToken: "token", RefreshToken: "good-refresh-token", Expiry: expired}},
+		"revoked refresh token": {config, &oauth2.Token{AccessToken: "token", RefreshToken: "bad-refresh-token", Expiry: expired}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := RefreshOAuthToken(ctx, tc.config, tc.tok)
+			if !errcode.IsUnauthorized(err) {
+				t.Fatalf("have error %v, want an unauthorized error", err)
+			}
+		})
+	}
+}
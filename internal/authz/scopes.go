@@ -4,10 +4,29 @@ const (
 	// Access token scopes.
 	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
 	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+
+	// Restricted access token scopes. A token with any of these scopes but without ScopeUserAll may
+	// only perform the actions they allow.
+	ScopeSearchRead        = "search:read"         // Read-only access to search, including the contents of the results.
+	ScopeRepoRead          = "repo:read"           // Read-only access to repositories and their contents.
+	ScopeCodeIntelUpload   = "codeintel:upload"    // Ability to upload code intelligence data.
+	ScopeBatchChangesWrite = "batch-changes:write" // Ability to create, apply and close batch changes.
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
 	ScopeSiteAdminSudo,
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCodeIntelUpload,
+	ScopeBatchChangesWrite,
+}
+
+// RestrictedScopes is a list of the access token scopes which grant less than ScopeUserAll.
+var RestrictedScopes = []string{
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCodeIntelUpload,
+	ScopeBatchChangesWrite,
 }
@@ -0,0 +1,46 @@
+// Package clientip determines the IP address of the client of an HTTP request.
+package clientip
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+var trustedProxyHops = env.MustGetInt("SRC_TRUSTED_PROXY_HOPS", 0, "Number of reverse proxies (such as load balancers) in front of Sourcegraph whose X-Forwarded-For header is trusted to determine the IP address of clients. If 0, the address of the connection is used.")
+
+// FromRequest returns the IP address of the client of r.
+//
+// The X-Forwarded-For header is set by the client and can't be trusted, except for the addresses
+// that the trusted reverse proxies in front of Sourcegraph (see SRC_TRUSTED_PROXY_HOPS) append to
+// it. If there are no trusted proxies, the address of the connection is returned.
+func FromRequest(r *http.Request) string {
+	return fromRequest(r, trustedProxyHops)
+}
+
+func fromRequest(r *http.Request, hops int) string {
+	if hops > 0 {
+		var addrs []string
+		for _, v := range r.Header.Values("X-Forwarded-For") {
+			for _, addr := range strings.Split(v, ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					addrs = append(addrs, addr)
+				}
+			}
+		}
+		// Each proxy appends the address it received the request from, so the client is the
+		// address added by the outermost trusted proxy. Addresses to the left of it may have
+		// been set by the client.
+		if len(addrs) >= hops {
+			return addrs[len(addrs)-hops]
+		} else if len(addrs) > 0 {
+			return addrs[0]
+		}
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
@@ -0,0 +1,34 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromRequest(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		xff  []string
+		hops int
+		want string
+	}{
+		{name: "no proxies", hops: 0, want: "192.0.2.1"},
+		{name: "no proxies ignores header", xff: []string{"203.0.113.1"}, hops: 0, want: "192.0.2.1"},
+		{name: "one proxy", xff: []string{"203.0.113.1"}, hops: 1, want: "203.0.113.1"},
+		{name: "one proxy with spoofed header", xff: []string{"198.51.100.7, 203.0.113.1"}, hops: 1, want: "203.0.113.1"},
+		{name: "two proxies", xff: []string{"198.51.100.7, 203.0.113.1, 10.0.0.1"}, hops: 2, want: "203.0.113.1"},
+		{name: "two proxies with several headers", xff: []string{"198.51.100.7", "203.0.113.1, 10.0.0.1"}, hops: 2, want: "203.0.113.1"},
+		{name: "fewer addresses than proxies", xff: []string{"203.0.113.1"}, hops: 2, want: "203.0.113.1"},
+		{name: "missing header", hops: 1, want: "192.0.2.1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			for _, v := range tc.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := fromRequest(r, tc.hops); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
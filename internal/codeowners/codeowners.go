@@ -0,0 +1,336 @@
+// Package codeowners parses CODEOWNERS files in the GitHub and GitLab syntax and
+// determines the owners of paths in a repository.
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// A Ruleset is a parsed CODEOWNERS file.
+//
+// GitHub CODEOWNERS files consist of a single list of rules, where the last rule
+// matching a path determines its owners. GitLab additionally supports sections,
+// which are evaluated independently: the owners of a path are the combined owners
+// of the last matching rule of every section.
+type Ruleset struct {
+	Sections []*Section
+}
+
+// A Section is a group of rules of a Ruleset. Rules that precede the first section
+// header of a file belong to an unnamed section.
+type Section struct {
+	Name string
+
+	// Optional is true for GitLab sections declared as ^[Section].
+	Optional bool
+
+	// Owners are the default owners of rules of the section that don't list owners
+	// themselves.
+	Owners []string
+
+	Rules []*Rule
+}
+
+// A Rule associates the paths matching Pattern with Owners.
+type Rule struct {
+	Pattern string
+	Owners  []string
+
+	// LineNumber is the 1-based line number of the rule in the CODEOWNERS file.
+	LineNumber int
+
+	match *regexp.Regexp
+}
+
+// Match returns true if the rule applies to the given path, which is relative to
+// the repository root.
+func (r *Rule) Match(path string) bool {
+	return r.match.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// ParseError is returned when a CODEOWNERS file has invalid syntax.
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return "codeowners: line " + strconv.Itoa(e.Line) + ": " + e.Msg
+}
+
+// Parse parses a CODEOWNERS file.
+func Parse(r io.Reader) (*Ruleset, error) {
+	rs := &Ruleset{}
+	current := &Section{}
+	rs.Sections = append(rs.Sections, current)
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if section, ok, err := parseSectionHeader(line); err != nil {
+			return nil, &ParseError{Line: n, Msg: err.Error()}
+		} else if ok {
+			current = section
+			rs.Sections = append(rs.Sections, current)
+			continue
+		}
+
+		fields := splitFields(line)
+		rule := &Rule{Pattern: fields[0], LineNumber: n}
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break // inline comment
+			}
+			if !isOwner(owner) {
+				return nil, &ParseError{Line: n, Msg: "invalid owner " + owner}
+			}
+			rule.Owners = append(rule.Owners, owner)
+		}
+
+		var err error
+		if rule.match, err = compilePattern(rule.Pattern); err != nil {
+			return nil, &ParseError{Line: n, Msg: err.Error()}
+		}
+		current.Rules = append(current.Rules, rule)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// FindOwners returns the owners of the given path, which is relative to the
+// repository root, or nil if the path has no owners.
+func (rs *Ruleset) FindOwners(path string) []string {
+	if rs == nil {
+		return nil
+	}
+
+	var owners []string
+	seen := map[string]bool{}
+	for _, section := range rs.Sections {
+		rule := section.findRule(path)
+		if rule == nil {
+			continue
+		}
+
+		ruleOwners := rule.Owners
+		if len(ruleOwners) == 0 {
+			ruleOwners = section.Owners
+		}
+		for _, o := range ruleOwners {
+			if key := normalizeOwner(o); !seen[key] {
+				seen[key] = true
+				owners = append(owners, o)
+			}
+		}
+	}
+	return owners
+}
+
+// findRule returns the last rule of the section matching path.
+func (s *Section) findRule(path string) *Rule {
+	for i := len(s.Rules) - 1; i >= 0; i-- {
+		if s.Rules[i].Match(path) {
+			return s.Rules[i]
+		}
+	}
+	return nil
+}
+
+// sectionHeader matches GitLab section headers like "[Docs]", "^[Optional docs][2]" or
+// "[Backend] @backend-team".
+var sectionHeader = regexp.MustCompile(`^(\^)?\[([^\]]*)\](?:\[\d+\])?(?:\s+(.*))?$`)
+
+func parseSectionHeader(line string) (*Section, bool, error) {
+	if !strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "^[") {
+		return nil, false, nil
+	}
+
+	m := sectionHeader.FindStringSubmatch(line)
+	if m == nil {
+		// GitLab paths may start with a character class like "[Dd]ocs/".
+		return nil, false, nil
+	}
+
+	name := strings.TrimSpace(m[2])
+	if name == "" {
+		return nil, false, errors.New("section name must not be empty")
+	}
+
+	section := &Section{Name: name, Optional: m[1] == "^"}
+	for _, owner := range strings.Fields(m[3]) {
+		if strings.HasPrefix(owner, "#") {
+			break
+		}
+		if !isOwner(owner) {
+			// Not a list of owners, so this is a pattern starting with a character
+			// class followed by owners.
+			return nil, false, nil
+		}
+		section.Owners = append(section.Owners, owner)
+	}
+	return section, true, nil
+}
+
+// splitFields splits a rule line into its pattern and owners, honoring escaped
+// whitespace and the escaped "\#" in patterns.
+func splitFields(line string) []string {
+	var (
+		fields []string
+		cur    strings.Builder
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == '\\' && i+1 < len(line) && (line[i+1] == ' ' || line[i+1] == '\t' || line[i+1] == '#'):
+			cur.WriteByte(line[i+1])
+			i++
+		case c == ' ' || c == '\t':
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+var (
+	ownerUsername = regexp.MustCompile(`^@[\w.-]+(/[\w.-]+)*$`)
+	ownerEmail    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// isOwner returns true if s is a username or team (@user, @org/team,
+// @group/subgroup) or an email address.
+func isOwner(s string) bool {
+	return ownerUsername.MatchString(s) || ownerEmail.MatchString(s)
+}
+
+// MatchOwner returns true if owner is one of owners. Owners are compared
+// case-insensitively and the leading @ of usernames and teams is optional.
+func MatchOwner(owners []string, owner string) bool {
+	owner = normalizeOwner(owner)
+	for _, o := range owners {
+		if normalizeOwner(o) == owner {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeOwner(owner string) string {
+	return strings.ToLower(strings.TrimPrefix(owner, "@"))
+}
+
+// compilePattern compiles a CODEOWNERS pattern, which follows the rules of
+// gitignore files, to a regular expression matching paths relative to the
+// repository root.
+//
+// A pattern matching a directory matches all the files within it, except for
+// patterns ending in "/*", which only match the files directly inside of it.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(pattern, "!") {
+		return nil, errors.New("negated patterns are not supported")
+	}
+
+	p := pattern
+	dir := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+
+	// Patterns containing a slash other than a trailing one are relative to the
+	// repository root, all others match at any depth.
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		p = "**"
+	}
+
+	var b bytes.Buffer
+	b.WriteString("^")
+	if !anchored && !strings.HasPrefix(p, "**") {
+		b.WriteString("(?:.*/)?")
+	}
+
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		last := i == len(segments)-1
+		if seg == "**" {
+			if last {
+				b.WriteString(".*")
+			} else {
+				b.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+
+		if err := writeSegment(&b, seg); err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q", pattern)
+		}
+		if !last {
+			b.WriteString("/")
+		}
+	}
+
+	last := segments[len(segments)-1]
+	switch {
+	case last == "**":
+	case last == "*" && !dir && len(segments) > 1:
+		// "docs/*" only matches files directly inside docs.
+	default:
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
+
+// writeSegment writes the regular expression for a single path segment of a
+// pattern to b.
+func writeSegment(b *bytes.Buffer, seg string) error {
+	for i := 0; i < len(seg); i++ {
+		switch c := seg[i]; c {
+		case '*':
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(seg[i+1:], ']')
+			if end < 0 {
+				return errors.New("unterminated character class")
+			}
+			class := seg[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(seg) {
+				i++
+				b.WriteString(regexp.QuoteMeta(seg[i : i+1]))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return nil
+}
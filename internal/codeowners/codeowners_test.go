@@ -0,0 +1,249 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    map[string]bool
+	}{
+		{
+			pattern: "*",
+			want: map[string]bool{
+				"README.md":       true,
+				"cmd/main.go":     true,
+				"a/b/c/d/file.go": true,
+			},
+		},
+		{
+			pattern: "*.js",
+			want: map[string]bool{
+				"index.js":        true,
+				"web/src/app.js":  true,
+				"web/src/app.jsx": false,
+				"main.go":         false,
+			},
+		},
+		{
+			pattern: "/build/logs/",
+			want: map[string]bool{
+				"build/logs/out.log":     true,
+				"build/logs/a/b/out.log": true,
+				"x/build/logs/out.log":   false,
+				"build/out.log":          false,
+			},
+		},
+		{
+			pattern: "apps/",
+			want: map[string]bool{
+				"apps/main.go":         true,
+				"web/apps/foo/main.go": true,
+				"application/main.go":  false,
+			},
+		},
+		{
+			pattern: "docs/*",
+			want: map[string]bool{
+				"docs/index.md":          true,
+				"docs/build/index.md":    false,
+				"web/docs/index.md":      false,
+				"docs.md":                false,
+				"docs/getting-started.m": true,
+			},
+		},
+		{
+			pattern: "**/logs",
+			want: map[string]bool{
+				"logs/out.log":          true,
+				"build/logs/out.log":    true,
+				"deep/in/logs/x/a.log":  true,
+				"build/mylogs/out.log":  false,
+				"build/logs.txt":        false,
+				"build/logs/nested/out": true,
+			},
+		},
+		{
+			pattern: "/internal/**/testdata",
+			want: map[string]bool{
+				"internal/testdata/a.json":     true,
+				"internal/x/y/testdata/a.json": true,
+				"cmd/internal/testdata/a.json": false,
+			},
+		},
+		{
+			pattern: "src/**",
+			want: map[string]bool{
+				"src/a.go":     true,
+				"src/a/b/c.go": true,
+				"lib/src/a.go": false,
+			},
+		},
+		{
+			pattern: "[Dd]ocs/?.md",
+			want: map[string]bool{
+				"Docs/a.md":  true,
+				"docs/b.md":  true,
+				"docs/ab.md": false,
+			},
+		},
+		{
+			pattern: `#hashtag`,
+			want: map[string]bool{
+				"#hashtag":       true,
+				"notes/#hashtag": true,
+				"hashtag":        false,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			re, err := compilePattern(tc.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tc.want {
+				if got := re.MatchString(path); got != want {
+					t.Errorf("pattern %q (%s) on %q: have %v, want %v", tc.pattern, re, path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleset_FindOwners(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want map[string][]string
+	}{
+		{
+			name: "github",
+			file: `
+# Default owners of everything in the repository.
+*       @acme/core
+
+# Order is important, the last matching pattern takes precedence.
+*.js    @acme/frontend @octocat # JavaScript
+/docs/  docs@example.com
+/docs/generated/
+\#notes @alice
+`,
+			want: map[string][]string{
+				"main.go":                 {"@acme/core"},
+				"web/app.js":              {"@acme/frontend", "@octocat"},
+				"docs/index.md":           {"docs@example.com"},
+				"docs/generated/index.md": nil,
+				"#notes":                  {"@alice"},
+			},
+		},
+		{
+			name: "gitlab sections",
+			file: `
+* @admins
+
+[Documentation] @docs-team
+docs/
+README.md @alice
+
+^[Database][2] @dba
+*.sql
+/db/migrations/ @dba @Alice
+`,
+			want: map[string][]string{
+				"main.go":              {"@admins"},
+				"docs/index.md":        {"@admins", "@docs-team"},
+				"README.md":            {"@admins", "@alice"},
+				"db/schema.sql":        {"@admins", "@dba"},
+				"db/migrations/1.sql":  {"@admins", "@dba", "@Alice"},
+				"db/migrations/README": {"@admins", "@dba", "@Alice"},
+			},
+		},
+		{
+			name: "character class pattern",
+			file: `[Dd]ocs/ @docs-team`,
+			want: map[string][]string{
+				"Docs/a.md": {"@docs-team"},
+				"main.go":   nil,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rs, err := Parse(strings.NewReader(tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for path, want := range tc.want {
+				if diff := cmp.Diff(want, rs.FindOwners(path)); diff != "" {
+					t.Errorf("owners of %q mismatch (-want +got):\n%s", path, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_Sections(t *testing.T) {
+	rs, err := Parse(strings.NewReader(`
+/cmd/ @alice
+
+^[Optional docs][2] @docs
+*.md
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rs.Sections) != 2 {
+		t.Fatalf("have %d sections, want 2", len(rs.Sections))
+	}
+	if s := rs.Sections[0]; s.Name != "" || len(s.Rules) != 1 || s.Rules[0].LineNumber != 2 {
+		t.Errorf("unexpected default section %+v", s)
+	}
+	if s := rs.Sections[1]; s.Name != "Optional docs" || !s.Optional || len(s.Rules) != 1 {
+		t.Errorf("unexpected section %+v", s)
+	}
+	if diff := cmp.Diff([]string{"@docs"}, rs.Sections[1].Owners); diff != "" {
+		t.Errorf("section owners mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		want string
+	}{
+		{file: "*.go not-an-owner", want: "codeowners: line 1: invalid owner not-an-owner"},
+		{file: "\n[] @alice", want: "codeowners: line 2: section name must not be empty"},
+		{file: "!vendor/ @alice", want: "codeowners: line 1: negated patterns are not supported"},
+		{file: "[abc @alice", want: `codeowners: line 1: invalid pattern "[abc": unterminated character class`},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.file))
+			if err == nil || err.Error() != tc.want {
+				t.Fatalf("have error %v, want %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestMatchOwner(t *testing.T) {
+	owners := []string{"@acme/Payments", "alice@example.com"}
+	for owner, want := range map[string]bool{
+		"@acme/payments":    true,
+		"acme/payments":     true,
+		"ALICE@example.com": true,
+		"@acme":             false,
+		"payments":          false,
+	} {
+		if got := MatchOwner(owners, owner); got != want {
+			t.Errorf("MatchOwner(%q): have %v, want %v", owner, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package codeowners
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// Paths are the locations of CODEOWNERS files supported by GitHub and GitLab in the
+// order they are looked up. Only the first file found is used.
+var Paths = []string{
+	".github/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// maxFileSize is the maximum size of a CODEOWNERS file that is read. GitHub ignores
+// CODEOWNERS files larger than 3 MB.
+const maxFileSize = 3 * 1024 * 1024
+
+// Fetch retrieves and parses the CODEOWNERS file of repo at commit from gitserver.
+// If the repository has no CODEOWNERS file, an empty Ruleset is returned.
+func Fetch(ctx context.Context, repo api.RepoName, commit api.CommitID) (*Ruleset, error) {
+	for _, path := range Paths {
+		data, err := git.ReadFile(ctx, repo, commit, path, maxFileSize)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "reading %s", path)
+		}
+
+		rs, err := Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", path)
+		}
+		return rs, nil
+	}
+	return &Ruleset{}, nil
+}
+
+// A Resolver determines the owners of files, fetching the CODEOWNERS file of each
+// repository and commit once unless the fetch is canceled. It is safe for concurrent use.
+type Resolver struct {
+	mu       sync.Mutex
+	rulesets map[resolverKey]*resolverEntry
+}
+
+type resolverKey struct {
+	repo   api.RepoName
+	commit api.CommitID
+}
+
+type resolverEntry struct {
+	mu   sync.Mutex
+	done bool
+	rs   *Ruleset
+	err  error
+}
+
+// NewResolver returns a new Resolver with an empty cache.
+func NewResolver() *Resolver {
+	return &Resolver{rulesets: map[resolverKey]*resolverEntry{}}
+}
+
+// Owners returns the owners of path in repo at commit.
+func (r *Resolver) Owners(ctx context.Context, repo api.RepoName, commit api.CommitID, path string) ([]string, error) {
+	rs, err := r.Ruleset(ctx, repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	return rs.FindOwners(path), nil
+}
+
+// Ruleset returns the parsed CODEOWNERS file of repo at commit.
+func (r *Resolver) Ruleset(ctx context.Context, repo api.RepoName, commit api.CommitID) (*Ruleset, error) {
+	key := resolverKey{repo: repo, commit: commit}
+
+	r.mu.Lock()
+	e, ok := r.rulesets[key]
+	if !ok {
+		e = &resolverEntry{}
+		r.rulesets[key] = e
+	}
+	r.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !e.done {
+		rs, err := Fetch(ctx, repo, commit)
+		if err != nil && ctx.Err() != nil {
+			// Errors caused by the cancellation of ctx aren't cached, so that
+			// callers with another context can still fetch the file.
+			return nil, err
+		}
+		e.rs, e.err, e.done = rs, err, true
+	}
+	return e.rs, e.err
+}
@@ -0,0 +1,112 @@
+package codeowners
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func mockFiles(t *testing.T, files map[string]string) *int32 {
+	var calls int32
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		if data, ok := files[string(commit)+":"+name]; ok {
+			return []byte(data), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	t.Cleanup(func() { git.Mocks.ReadFile = nil })
+	return &calls
+}
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	mockFiles(t, map[string]string{
+		"a:.github/CODEOWNERS": "* @github",
+		"a:CODEOWNERS":         "* @root",
+		"b:docs/CODEOWNERS":    "* @docs",
+		"c:CODEOWNERS":         "* invalid",
+	})
+
+	for commit, want := range map[api.CommitID][]string{
+		"a": {"@github"},
+		"b": {"@docs"},
+		"d": nil,
+	} {
+		rs, err := Fetch(ctx, "repo", commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, rs.FindOwners("main.go")); diff != "" {
+			t.Errorf("commit %s: owners mismatch (-want +got):\n%s", commit, diff)
+		}
+	}
+
+	if _, err := Fetch(ctx, "repo", "c"); err == nil {
+		t.Fatal("expected error for invalid CODEOWNERS file")
+	}
+}
+
+func TestResolver(t *testing.T) {
+	ctx := context.Background()
+	calls := mockFiles(t, map[string]string{
+		"a:CODEOWNERS": "*.go @gophers\n/docs/ @docs",
+	})
+
+	r := NewResolver()
+	for path, want := range map[string][]string{
+		"main.go":       {"@gophers"},
+		"docs/index.md": {"@docs"},
+		"README.md":     nil,
+	} {
+		owners, err := r.Owners(ctx, "repo", "a", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, owners); diff != "" {
+			t.Errorf("owners of %q mismatch (-want +got):\n%s", path, diff)
+		}
+	}
+
+	// .github/CODEOWNERS, .gitlab/CODEOWNERS and CODEOWNERS are read once.
+	if got := atomic.LoadInt32(calls); got != 3 {
+		t.Errorf("have %d reads, want 3", got)
+	}
+}
+
+func TestResolverCanceled(t *testing.T) {
+	canceled := true
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if canceled {
+			return nil, context.Canceled
+		}
+		if name == "CODEOWNERS" {
+			return []byte("* @root"), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	t.Cleanup(func() { git.Mocks.ReadFile = nil })
+
+	r := NewResolver()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := r.Owners(ctx, "repo", "a", "main.go"); err == nil {
+		t.Fatal("expected error for canceled context")
+	}
+
+	// The cancellation error isn't cached.
+	canceled = false
+	owners, err := r.Owners(context.Background(), "repo", "a", "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"@root"}, owners); diff != "" {
+		t.Errorf("owners mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -200,3 +200,47 @@ func Matches(ctx context.Context, args Args) (matches []FileMatch, err error) {
 	}
 	return matches, nil
 }
+
+// Diffs returns the diffs of rewriting the matches of args.MatchTemplate with
+// args.RewriteTemplate in all files for which comby finds matches. The input
+// is not modified.
+func Diffs(ctx context.Context, args Args) (diffs []FileDiff, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "Comby.Diffs")
+	defer span.Finish()
+
+	b := new(bytes.Buffer)
+	w := bufio.NewWriter(b)
+
+	args.MatchOnly = false
+
+	err = PipeTo(ctx, args, w)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(b)
+	// diffs of whole files can be much longer than match lines
+	scanner.Buffer(make([]byte, 100), 100*bufio.MaxScanTokenSize)
+	for scanner.Scan() {
+		var d *FileDiff
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			// warn on decode errors and skip
+			log15.Warn("comby error: skipping unmarshaling error", "err", err.Error())
+			continue
+		}
+		if d.Diff != "" {
+			diffs = append(diffs, *d)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read comby diffs")
+	}
+
+	if len(diffs) > 0 {
+		log15.Info("comby invocation", "num_diffs", strconv.Itoa(len(diffs)))
+	}
+	return diffs, nil
+}
@@ -124,3 +124,45 @@ func main() {
 		}
 	}
 }
+
+func TestDiffs(t *testing.T) {
+	// If we are not on CI skip the test if comby is not installed.
+	if os.Getenv("CI") == "" && !exists() {
+		t.Skip("comby is not installed on the PATH. Try running 'bash <(curl -sL get.comby.dev)'.")
+	}
+
+	files := map[string]string{
+		"README.md": "func",
+		"main.go": `package main
+
+func main() {}
+`,
+		"util.go": `package main
+`,
+	}
+
+	zipPath, cleanup, err := testutil.TempZipFromFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	diffs, err := Diffs(context.Background(), Args{
+		Input:           ZipPath(zipPath),
+		MatchTemplate:   "func :[name]()",
+		RewriteTemplate: "func :[name](ctx context.Context)",
+		FilePatterns:    []string{".go"},
+		Matcher:         ".go",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []FileDiff{{
+		URI:  "main.go",
+		Diff: "--- main.go\n+++ main.go\n@@ -1,3 +1,3 @@\n package main\n \n-func main() {}\n+func main(ctx context.Context) {}",
+	}}
+	if len(diffs) != len(want) || diffs[0] != want[0] {
+		t.Errorf("got %+v, want %+v", diffs, want)
+	}
+}
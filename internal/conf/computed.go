@@ -118,15 +118,16 @@ func AccessTokensAllow() AccessTokAllow {
 //
 // It's false for sites that do not have an email sending API key set up.
 func EmailVerificationRequired() bool {
-	return Get().EmailSmtp != nil
+	return CanSendEmail()
 }
 
 // CanSendEmail returns whether the site can send emails (e.g., to reset a password or
 // invite a user to an org).
 //
-// It's false for sites that do not have an email sending API key set up.
+// It's false for sites that do not have an SMTP server or email HTTP API set up.
 func CanSendEmail() bool {
-	return Get().EmailSmtp != nil
+	c := Get()
+	return c.EmailSmtp != nil || c.EmailHttp != nil
 }
 
 // Deploy type constants. Any changes here should be reflected in the DeployType type declared in web/src/globals.d.ts:
@@ -321,6 +322,40 @@ func AndOrQueryEnabled() bool {
 	return val == "enabled"
 }
 
+func CommitIndexEnabled() bool {
+	return ExperimentalFeatures().SearchCommitIndex == "enabled"
+}
+
+// SearchJobs returns the search jobs configuration, with the defaults for the
+// unset limits.
+func SearchJobs() schema.SearchJobs {
+	var c schema.SearchJobs
+	if v := ExperimentalFeatures().SearchJobs; v != nil {
+		c = *v
+	}
+	if c.MaxActiveJobsPerUser <= 0 {
+		c.MaxActiveJobsPerUser = 2
+	}
+	if c.MaxJobsPerUser <= 0 {
+		c.MaxJobsPerUser = 20
+	}
+	return c
+}
+
+// SearchArchivesMaxBytes returns the number of bytes of files in archives
+// which are searched per repository, or 0 if files in archives are not
+// searched.
+func SearchArchivesMaxBytes() int64 {
+	c := Get().SearchArchives
+	if c == nil || !c.Enabled {
+		return 0
+	}
+	if c.MaxBytes > 0 {
+		return int64(c.MaxBytes)
+	}
+	return 100 * 1024 * 1024
+}
+
 func ExperimentalFeatures() schema.ExperimentalFeatures {
 	val := Get().ExperimentalFeatures
 	if val == nil {
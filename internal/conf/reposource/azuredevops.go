@@ -0,0 +1,66 @@
+package reposource
+
+import (
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type AzureDevOps struct {
+	*schema.AzureDevOpsConnection
+}
+
+var _ RepoSource = AzureDevOps{}
+
+func (c AzureDevOps) CloneURLToRepoName(cloneURL string) (repoName api.RepoName, err error) {
+	parsedCloneURL, baseURL, match, err := parseURLs(cloneURL, c.Url)
+	if err != nil {
+		return "", err
+	}
+	// Azure DevOps Services serves SSH clone URLs from a dedicated host, e.g.
+	// git@ssh.dev.azure.com:v3/org/project/repo.
+	if baseURL == nil || (!match && hostname(parsedCloneURL) != "ssh."+hostname(baseURL)) {
+		return "", nil
+	}
+
+	// SSH clone URLs of Azure DevOps Services have the form "v3/org/project/repo". All
+	// other clone URLs are relative to the base URL, which may contain a path, and have
+	// the form "org/project/_git/repo".
+	path := strings.Trim(parsedCloneURL.Path, "/")
+	var parts []string
+	if strings.HasPrefix(path, "v3/") {
+		parts = strings.Split(strings.TrimPrefix(path, "v3/"), "/")
+	} else {
+		path = strings.TrimPrefix(strings.TrimPrefix(path, strings.Trim(baseURL.Path, "/")), "/")
+		parts = strings.Split(path, "/")
+		if len(parts) == 4 && parts[2] == "_git" {
+			parts = append(parts[:2], parts[3])
+		}
+	}
+	if len(parts) != 3 {
+		return "", nil
+	}
+
+	return AzureDevOpsRepoName(c.RepositoryPathPattern, baseURL.Hostname(), parts[0], parts[1], strings.TrimSuffix(parts[2], ".git")), nil
+}
+
+// AzureDevOpsRepoName returns the Sourcegraph name for an Azure DevOps repository given
+// the configured repositoryPathPattern, the hostname of the Azure DevOps instance and the
+// names of the repository's organization, project and the repository itself.
+//
+// Azure DevOps project and repository names may contain spaces, which are replaced with
+// dashes.
+func AzureDevOpsRepoName(repositoryPathPattern, host, org, project, repo string) api.RepoName {
+	if repositoryPathPattern == "" {
+		repositoryPathPattern = "{host}/{org}/{project}/{repo}"
+	}
+
+	clean := strings.NewReplacer(" ", "-")
+	return api.RepoName(strings.NewReplacer(
+		"{host}", host,
+		"{org}", clean.Replace(org),
+		"{project}", clean.Replace(project),
+		"{repo}", clean.Replace(repo),
+	).Replace(repositoryPathPattern))
+}
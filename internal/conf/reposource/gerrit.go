@@ -0,0 +1,52 @@
+package reposource
+
+import (
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type Gerrit struct {
+	*schema.GerritConnection
+}
+
+var _ RepoSource = Gerrit{}
+
+func (c Gerrit) CloneURLToRepoName(cloneURL string) (repoName api.RepoName, err error) {
+	parsedCloneURL, baseURL, match, err := parseURLs(cloneURL, c.Url)
+	if err != nil {
+		return "", err
+	}
+	if !match {
+		return "", nil
+	}
+
+	// HTTP clone URLs are relative to the base URL, which may contain a path, and
+	// authenticated clone URLs have an additional "/a/" prefix. SSH clone URLs are
+	// always relative to the root of the host.
+	name := strings.TrimPrefix(parsedCloneURL.Path, "/")
+	if parsedCloneURL.Scheme == "http" || parsedCloneURL.Scheme == "https" {
+		name = strings.TrimPrefix(name, strings.TrimPrefix(baseURL.Path, "/"))
+		name = strings.TrimPrefix(name, "a/")
+	}
+	name = strings.TrimSuffix(name, ".git")
+	if name == "" {
+		return "", nil
+	}
+
+	return GerritRepoName(c.RepositoryPathPattern, baseURL.Hostname(), name), nil
+}
+
+// GerritRepoName returns the Sourcegraph name for a Gerrit project given the configured
+// repositoryPathPattern, the hostname of the Gerrit instance and the project's name.
+func GerritRepoName(repositoryPathPattern, host, name string) api.RepoName {
+	if repositoryPathPattern == "" {
+		repositoryPathPattern = "{host}/{name}"
+	}
+
+	return api.RepoName(strings.NewReplacer(
+		"{host}", host,
+		"{name}", name,
+	).Replace(repositoryPathPattern))
+}
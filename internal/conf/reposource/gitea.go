@@ -0,0 +1,52 @@
+package reposource
+
+import (
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type Gitea struct {
+	*schema.GiteaConnection
+}
+
+var _ RepoSource = Gitea{}
+
+func (c Gitea) CloneURLToRepoName(cloneURL string) (repoName api.RepoName, err error) {
+	parsedCloneURL, baseURL, match, err := parseURLs(cloneURL, c.Url)
+	if err != nil {
+		return "", err
+	}
+	if !match {
+		return "", nil
+	}
+
+	// HTTP clone URLs are relative to the base URL, which may contain a path. SSH clone
+	// URLs are always relative to the root of the host.
+	nameWithOwner := strings.TrimPrefix(parsedCloneURL.Path, "/")
+	if parsedCloneURL.Scheme == "http" || parsedCloneURL.Scheme == "https" {
+		nameWithOwner = strings.TrimPrefix(nameWithOwner, strings.TrimPrefix(baseURL.Path, "/"))
+		nameWithOwner = strings.TrimPrefix(nameWithOwner, "/")
+	}
+	nameWithOwner = strings.TrimSuffix(nameWithOwner, ".git")
+	if strings.Count(nameWithOwner, "/") != 1 {
+		return "", nil
+	}
+
+	return GiteaRepoName(c.RepositoryPathPattern, baseURL.Hostname(), nameWithOwner), nil
+}
+
+// GiteaRepoName returns the Sourcegraph name for a Gitea repository given the configured
+// repositoryPathPattern, the hostname of the Gitea instance and the repository's
+// "owner/name".
+func GiteaRepoName(repositoryPathPattern, host, nameWithOwner string) api.RepoName {
+	if repositoryPathPattern == "" {
+		repositoryPathPattern = "{host}/{nameWithOwner}"
+	}
+
+	return api.RepoName(strings.NewReplacer(
+		"{host}", host,
+		"{nameWithOwner}", nameWithOwner,
+	).Replace(repositoryPathPattern))
+}
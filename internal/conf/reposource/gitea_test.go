@@ -0,0 +1,51 @@
+package reposource
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestGitea_cloneURLToRepoName(t *testing.T) {
+	tests := []struct {
+		conn schema.GiteaConnection
+		urls []urlToRepoName
+	}{{
+		conn: schema.GiteaConnection{
+			Url: "https://gitea.example.com",
+		},
+		urls: []urlToRepoName{
+			{"git@gitea.example.com:acme/api.git", "gitea.example.com/acme/api"},
+			{"https://gitea.example.com/acme/api.git", "gitea.example.com/acme/api"},
+			{"https://s3cr3t@gitea.example.com/acme/api.git", "gitea.example.com/acme/api"},
+
+			{"https://gitea.example.com/acme.git", ""},
+			{"git@asdf.com:acme/api.git", ""},
+			{"https://asdf.com/acme/api.git", ""},
+		},
+	}, {
+		conn: schema.GiteaConnection{
+			Url:                   "https://git.example.com/gitea/",
+			RepositoryPathPattern: "gitea/{nameWithOwner}",
+		},
+		urls: []urlToRepoName{
+			{"git@git.example.com:acme/api.git", "gitea/acme/api"},
+			{"https://git.example.com/gitea/acme/api.git", "gitea/acme/api"},
+			{"https://s3cr3t@git.example.com/gitea/acme/api.git", "gitea/acme/api"},
+
+			{"https://asdf.com/gitea/acme/api.git", ""},
+		},
+	}}
+
+	for _, test := range tests {
+		for _, u := range test.urls {
+			repoName, err := Gitea{&test.conn}.CloneURLToRepoName(u.cloneURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.repoName != string(repoName) {
+				t.Errorf("expected %q but got %q for clone URL %q (connection: %+v)", u.repoName, repoName, u.cloneURL, test.conn)
+			}
+		}
+	}
+}
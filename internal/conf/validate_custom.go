@@ -48,6 +48,9 @@ func validateCustom(cfg Unified) (problems Problems) {
 		if hasSMTP && cfg.EmailAddress == "" {
 			invalid(NewSiteProblem(`should set email.address because email.smtp is set`))
 		}
+		if cfg.EmailHttp != nil && cfg.EmailAddress == "" {
+			invalid(NewSiteProblem(`should set email.address because email.http is set`))
+		}
 		if hasSMTPAuth && (cfg.EmailSmtp.Username == "" && cfg.EmailSmtp.Password == "") {
 			invalid(NewSiteProblem(`must set email.smtp username and password for email.smtp authentication`))
 		}
@@ -26,6 +26,7 @@ type AccessToken struct {
 	CreatorUserID int32
 	CreatedAt     time.Time
 	LastUsedAt    *time.Time
+	ExpiresAt     *time.Time // the access token can't be used after this time, if set
 }
 
 // ErrAccessTokenNotFound occurs when a database operation expects a specific access token to exist
@@ -72,6 +73,15 @@ func (s *AccessTokenStore) Transact(ctx context.Context) (*AccessTokenStore, err
 // 🚨 SECURITY: The caller must ensure that the actor is permitted to create tokens for the
 // specified user (i.e., that the actor is either the user or a site admin).
 func (s *AccessTokenStore) Create(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error) {
+	return s.CreateWithExpiry(ctx, subjectUserID, scopes, note, creatorUserID, nil)
+}
+
+// CreateWithExpiry is like Create, but the access token can't be used after expiresAt (if it is
+// not nil).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is permitted to create tokens for the
+// specified user (i.e., that the actor is either the user or a site admin).
+func (s *AccessTokenStore) CreateWithExpiry(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (id int64, token string, err error) {
 	if Mocks.AccessTokens.Create != nil {
 		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID)
 	}
@@ -99,12 +109,12 @@ creator_user AS (
   SELECT id FROM users WHERE id=$5 AND deleted_at IS NULL FOR UPDATE
 ),
 insert_values AS (
-  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id
+  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id, $6::timestamptz AS expires_at
   FROM subject_user, creator_user
 )
-INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id) SELECT * FROM insert_values RETURNING id
+INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id, expires_at) SELECT * FROM insert_values RETURNING id
 `,
-		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID,
+		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID, expiresAt,
 	).Scan(&id); err != nil {
 		return 0, "", err
 	}
@@ -117,7 +127,7 @@ INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_u
 // Calling Lookup also updates the access token's last-used-at date.
 //
 // 🚨 SECURITY: This returns a user ID if and only if the tokenHexEncoded corresponds to a valid,
-// non-deleted access token.
+// non-deleted, unexpired access token.
 func (s *AccessTokenStore) Lookup(ctx context.Context, tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
 	if Mocks.AccessTokens.Lookup != nil {
 		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScope)
@@ -127,32 +137,62 @@ func (s *AccessTokenStore) Lookup(ctx context.Context, tokenHexEncoded, required
 		return 0, errors.New("no scope provided in access token lookup")
 	}
 
+	t, err := s.lookup(ctx, tokenHexEncoded, requiredScope)
+	if err != nil {
+		return 0, err
+	}
+	return t.SubjectUserID, nil
+}
+
+// LookupToken looks up the access token. If it's valid, it returns the access token, whatever its
+// scopes are. The LastUsedAt of the returned access token is the date it was last used before this
+// call. Otherwise ErrAccessTokenNotFound is returned.
+//
+// Calling LookupToken also updates the access token's last-used-at date.
+//
+// 🚨 SECURITY: This returns an access token if and only if the tokenHexEncoded corresponds to a
+// valid, non-deleted, unexpired access token. The caller must ensure that the actor only performs
+// the actions allowed by the token's scopes.
+func (s *AccessTokenStore) LookupToken(ctx context.Context, tokenHexEncoded string) (*AccessToken, error) {
+	if Mocks.AccessTokens.LookupToken != nil {
+		return Mocks.AccessTokens.LookupToken(tokenHexEncoded)
+	}
+
+	return s.lookup(ctx, tokenHexEncoded, "")
+}
+
+// lookup looks up the access token, which must have requiredScope if it is not empty.
+func (s *AccessTokenStore) lookup(ctx context.Context, tokenHexEncoded, requiredScope string) (*AccessToken, error) {
 	token, err := hex.DecodeString(tokenHexEncoded)
 	if err != nil {
-		return 0, errors.Wrap(err, "AccessTokens.Lookup")
+		return nil, errors.Wrap(err, "AccessTokens.Lookup")
 	}
 
+	var t AccessToken
 	if err := s.Handle().DB().QueryRowContext(ctx,
-		// Ensure that subject and creator users still exist.
+		// Ensure that subject and creator users still exist and aren't deactivated.
 		`
-UPDATE access_tokens t SET last_used_at=now()
-WHERE t.id IN (
-	SELECT t2.id FROM access_tokens t2
-	JOIN users subject_user ON t2.subject_user_id=subject_user.id AND subject_user.deleted_at IS NULL
-	JOIN users creator_user ON t2.creator_user_id=creator_user.id AND creator_user.deleted_at IS NULL
+WITH token AS (
+	SELECT t2.id, t2.last_used_at FROM access_tokens t2
+	JOIN users subject_user ON t2.subject_user_id=subject_user.id AND subject_user.deleted_at IS NULL AND subject_user.deactivated_at IS NULL
+	JOIN users creator_user ON t2.creator_user_id=creator_user.id AND creator_user.deleted_at IS NULL AND creator_user.deactivated_at IS NULL
 	WHERE t2.value_sha256=$1 AND t2.deleted_at IS NULL AND
-	$2 = ANY (t2.scopes)
+	(t2.expires_at IS NULL OR t2.expires_at > now()) AND
+	($2 = '' OR $2 = ANY (t2.scopes))
 )
-RETURNING t.subject_user_id
+UPDATE access_tokens t SET last_used_at=now()
+FROM token
+WHERE t.id=token.id
+RETURNING t.id, t.subject_user_id, t.scopes, t.note, t.creator_user_id, t.created_at, token.last_used_at, t.expires_at
 `,
 		toSHA256Bytes(token), requiredScope,
-	).Scan(&subjectUserID); err != nil {
+	).Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, &t.ExpiresAt); err != nil {
 		if err == sql.ErrNoRows {
-			return 0, ErrAccessTokenNotFound
+			return nil, ErrAccessTokenNotFound
 		}
-		return 0, err
+		return nil, err
 	}
-	return subjectUserID, nil
+	return &t, nil
 }
 
 // GetByID retrieves the access token (if any) given its ID.
@@ -221,7 +261,7 @@ func (s *AccessTokenStore) List(ctx context.Context, opt AccessTokensListOptions
 
 func (s *AccessTokenStore) list(ctx context.Context, conds []*sqlf.Query, limitOffset *LimitOffset) ([]*AccessToken, error) {
 	q := sqlf.Sprintf(`
-SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at FROM access_tokens
+SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at, expires_at FROM access_tokens
 WHERE (%s)
 ORDER BY now() - created_at < interval '5 minutes' DESC, -- show recently created tokens first
 last_used_at DESC NULLS FIRST, -- ensure newly created tokens show first
@@ -240,7 +280,7 @@ created_at DESC
 	var results []*AccessToken
 	for rows.Next() {
 		var t AccessToken
-		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, &t.ExpiresAt); err != nil {
 			return nil, err
 		}
 		results = append(results, &t)
@@ -309,8 +349,9 @@ func toSHA256Bytes(input []byte) []byte {
 }
 
 type MockAccessTokens struct {
-	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error)
-	DeleteByID func(id int64, subjectUserID int32) error
-	Lookup     func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error)
-	GetByID    func(id int64) (*AccessToken, error)
+	Create      func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error)
+	DeleteByID  func(id int64, subjectUserID int32) error
+	Lookup      func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error)
+	LookupToken func(tokenHexEncoded string) (*AccessToken, error)
+	GetByID     func(id int64) (*AccessToken, error)
 }
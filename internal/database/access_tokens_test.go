@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
 )
@@ -229,6 +230,68 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	}
 }
 
+// 🚨 SECURITY: This tests that LookupToken returns tokens whatever their scopes are, and that expired
+// tokens are invalid.
+func TestAccessTokens_LookupToken(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	db := dbtest.NewDB(t, "")
+	ctx := context.Background()
+
+	subject, err := Users(db).Create(ctx, NewUser{
+		Email:                 "a@example.com",
+		Username:              "u1",
+		Password:              "p1",
+		EmailVerificationCode: "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+	tid0, tv0, err := AccessTokens(db).CreateWithExpiry(ctx, subject.ID, []string{"a"}, "n0", subject.ID, &expiresAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := AccessTokens(db).LookupToken(ctx, tv0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != tid0 || got.SubjectUserID != subject.ID || len(got.Scopes) != 1 || got.Scopes[0] != "a" {
+		t.Errorf("got %+v", got)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("got expiry %v, want %v", got.ExpiresAt, expiresAt)
+	}
+	// LastUsedAt is the date of the previous use.
+	if got.LastUsedAt != nil {
+		t.Errorf("got last used at %v, want nil", got.LastUsedAt)
+	}
+	got, err = AccessTokens(db).LookupToken(ctx, tv0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LastUsedAt == nil {
+		t.Error("got last used at nil after first use")
+	}
+
+	// Expired tokens are invalid.
+	expiredAt := time.Now().Add(-time.Hour)
+	_, tv1, err := AccessTokens(db).CreateWithExpiry(ctx, subject.ID, []string{"a"}, "n1", subject.ID, &expiredAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AccessTokens(db).LookupToken(ctx, tv1); err != ErrAccessTokenNotFound {
+		t.Fatalf("got err %v, want %v", err, ErrAccessTokenNotFound)
+	}
+	if _, err := AccessTokens(db).Lookup(ctx, tv1, "a"); err != ErrAccessTokenNotFound {
+		t.Fatalf("got err %v, want %v", err, ErrAccessTokenNotFound)
+	}
+}
+
 // 🚨 SECURITY: This tests that deleting the subject or creator user of an access token invalidates
 // the token, and that no new access tokens may be created for deleted users.
 func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
@@ -0,0 +1,222 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+)
+
+// The outcomes of an audited action.
+const (
+	AuditLogOutcomeSuccess = "success"
+	AuditLogOutcomeFailure = "failure"
+)
+
+// AuditLogEntry is an entry of the audit log. Its JSON encoding is the format in which entries are
+// exported.
+type AuditLogEntry struct {
+	ID        int64     `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	// TxID is the transaction that inserted the entry, which orders entries by commit.
+	TxID int64 `json:"-"`
+
+	// ActorUserID is the user who performed the action, or 0 for anonymous and internal actors.
+	ActorUserID   int32  `json:"actorUserID,omitempty"`
+	ActorUsername string `json:"actorUsername,omitempty"`
+	// AccessTokenID is the access token used to authenticate the actor, if any.
+	AccessTokenID int64  `json:"accessTokenID,omitempty"`
+	IP            string `json:"ip,omitempty"`
+	UserAgent     string `json:"userAgent,omitempty"`
+
+	// Action is the GraphQL mutation ("graphql.updateSiteConfiguration") or the HTTP API call
+	// ("http.POST /.api/scim/v2/Users") that was performed.
+	Action string `json:"action"`
+	// Target is what the action was performed on, if known (such as "external service 12").
+	Target string `json:"target,omitempty"`
+	// Arguments are the arguments of the action, with sensitive values redacted.
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	// Before and After summarize the state of the target before and after the action, if known.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+
+	Outcome string `json:"outcome"`
+	Error   string `json:"error,omitempty"`
+}
+
+// An AuditLogStore provides persistence for the audit log.
+type AuditLogStore struct {
+	*basestore.Store
+}
+
+// AuditLogs instantiates and returns a new AuditLogStore.
+func AuditLogs(db dbutil.DB) *AuditLogStore {
+	return &AuditLogStore{Store: basestore.NewWithDB(db, sql.TxOptions{})}
+}
+
+// Insert adds an entry to the audit log and sets its ID, timestamp and transaction ID.
+func (s *AuditLogStore) Insert(ctx context.Context, e *AuditLogEntry) error {
+	if Mocks.AuditLogs.Insert != nil {
+		return Mocks.AuditLogs.Insert(ctx, e)
+	}
+
+	var arguments interface{}
+	if len(e.Arguments) > 0 {
+		arguments = []byte(e.Arguments)
+	}
+	q := sqlf.Sprintf(
+		auditLogInsertQueryFmtstr,
+		nullInt32Column(e.ActorUserID),
+		nullStringColumn(e.ActorUsername),
+		nullInt64Column(e.AccessTokenID),
+		nullStringColumn(e.IP),
+		nullStringColumn(e.UserAgent),
+		e.Action,
+		nullStringColumn(e.Target),
+		arguments,
+		nullStringColumn(e.Before),
+		nullStringColumn(e.After),
+		e.Outcome,
+		nullStringColumn(e.Error),
+	)
+	if err := s.QueryRow(ctx, q).Scan(&e.ID, &e.Timestamp, &e.TxID); err != nil {
+		return errors.Wrap(err, "INSERT")
+	}
+	return nil
+}
+
+const auditLogInsertQueryFmtstr = `
+-- source: internal/database/audit_logs.go:Insert
+INSERT INTO audit_log (actor_user_id, actor_username, access_token_id, ip, user_agent, action, target, arguments, before, after, outcome, error)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+RETURNING id, timestamp, txid
+`
+
+// AuditLogsListOptions specifies the options for listing audit log entries.
+type AuditLogsListOptions struct {
+	// AfterTxID and AfterID only list entries that come after the entry with this transaction ID
+	// and ID in commit order, for resuming an export. If AfterTxID is zero, entries with a greater
+	// ID are listed.
+	AfterTxID int64
+	AfterID   int64
+	// Since only lists entries at or after the time, if it is nonzero.
+	Since time.Time
+	// Limit is the maximum number of entries to list, if it is nonzero.
+	Limit int
+}
+
+// List lists audit log entries in the order in which they were committed.
+//
+// IDs are assigned when entries are inserted, not when they are committed, so an entry may become
+// visible after entries with greater IDs. To never skip such an entry when resuming after the last
+// listed one, List orders entries by transaction and only lists the entries of transactions that
+// are older than every running transaction. Entries of later transactions are listed once these
+// have finished.
+func (s *AuditLogStore) List(ctx context.Context, opt AuditLogsListOptions) ([]*AuditLogEntry, error) {
+	if Mocks.AuditLogs.List != nil {
+		return Mocks.AuditLogs.List(ctx, opt)
+	}
+
+	conds := []*sqlf.Query{sqlf.Sprintf("txid < txid_snapshot_xmin(txid_current_snapshot())")}
+	if opt.AfterTxID != 0 {
+		conds = append(conds, sqlf.Sprintf("(txid, id) > (%s, %s)", opt.AfterTxID, opt.AfterID))
+	} else {
+		conds = append(conds, sqlf.Sprintf("id > %d", opt.AfterID))
+	}
+	if !opt.Since.IsZero() {
+		conds = append(conds, sqlf.Sprintf("timestamp >= %s", opt.Since))
+	}
+	limit := sqlf.Sprintf("")
+	if opt.Limit > 0 {
+		limit = sqlf.Sprintf("LIMIT %d", opt.Limit)
+	}
+
+	rows, err := s.Query(ctx, sqlf.Sprintf(auditLogListQueryFmtstr, sqlf.Join(conds, "AND"), limit))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		var e AuditLogEntry
+		var arguments []byte
+		if err := rows.Scan(
+			&e.ID,
+			&e.Timestamp,
+			&e.TxID,
+			&e.ActorUserID,
+			&e.ActorUsername,
+			&e.AccessTokenID,
+			&e.IP,
+			&e.UserAgent,
+			&e.Action,
+			&e.Target,
+			&arguments,
+			&e.Before,
+			&e.After,
+			&e.Outcome,
+			&e.Error,
+		); err != nil {
+			return nil, err
+		}
+		if len(arguments) > 0 {
+			e.Arguments = arguments
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+const auditLogListQueryFmtstr = `
+-- source: internal/database/audit_logs.go:List
+SELECT
+	id,
+	timestamp,
+	txid,
+	COALESCE(actor_user_id, 0),
+	COALESCE(actor_username, ''),
+	COALESCE(access_token_id, 0),
+	COALESCE(ip, ''),
+	COALESCE(user_agent, ''),
+	action,
+	COALESCE(target, ''),
+	arguments,
+	COALESCE(before, ''),
+	COALESCE(after, ''),
+	outcome,
+	COALESCE(error, '')
+FROM audit_log
+WHERE %s
+ORDER BY txid ASC, id ASC
+%s
+`
+
+// GetTxID returns the transaction ID of the entry with the given ID, or 0 if there is no such
+// entry (for example because it was deleted by retention).
+func (s *AuditLogStore) GetTxID(ctx context.Context, id int64) (int64, error) {
+	if Mocks.AuditLogs.GetTxID != nil {
+		return Mocks.AuditLogs.GetTxID(ctx, id)
+	}
+
+	txID, _, err := basestore.ScanFirstInt(s.Query(ctx, sqlf.Sprintf(`SELECT txid FROM audit_log WHERE id = %s`, id)))
+	return int64(txID), err
+}
+
+// DeleteOlderThan deletes the audit log entries from before t.
+func (s *AuditLogStore) DeleteOlderThan(ctx context.Context, t time.Time) error {
+	return s.Exec(ctx, sqlf.Sprintf(`DELETE FROM audit_log WHERE timestamp < %s`, t))
+}
+
+func nullInt64Column(n int64) *int64 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
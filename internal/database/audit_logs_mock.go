@@ -0,0 +1,9 @@
+package database
+
+import "context"
+
+type MockAuditLogs struct {
+	Insert  func(ctx context.Context, e *AuditLogEntry) error
+	List    func(ctx context.Context, opt AuditLogsListOptions) ([]*AuditLogEntry, error)
+	GetTxID func(ctx context.Context, id int64) (int64, error)
+}
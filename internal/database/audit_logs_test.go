@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtest"
+)
+
+func TestAuditLogs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	db := dbtest.NewDB(t, "")
+	ctx := context.Background()
+	s := AuditLogs(db)
+
+	entries := []*AuditLogEntry{
+		{ActorUserID: 1, ActorUsername: "alice", IP: "10.0.0.1", Action: "graphql.updateSiteConfiguration", Before: "site configuration 1", After: "site configuration 2", Outcome: AuditLogOutcomeSuccess},
+		{Action: "http.POST /.api/scim/v2/Users", Arguments: json.RawMessage(`{"status":201}`), Outcome: AuditLogOutcomeSuccess},
+		{ActorUserID: 2, AccessTokenID: 3, Action: "graphql.deleteUser", Target: "user 4", Outcome: AuditLogOutcomeFailure, Error: "must be site admin"},
+	}
+	for _, e := range entries {
+		if err := s.Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		if e.ID == 0 || e.Timestamp.IsZero() {
+			t.Fatalf("got ID %d and timestamp %v, want them to be set", e.ID, e.Timestamp)
+		}
+	}
+	if err := s.Insert(ctx, &AuditLogEntry{Outcome: AuditLogOutcomeSuccess}); err == nil {
+		t.Error("got no error inserting an entry without an action")
+	}
+
+	got, err := s.List(ctx, AuditLogsListOptions{AfterID: entries[0].ID, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != entries[1].ID || string(got[0].Arguments) != `{"status": 201}` {
+		t.Fatalf("got %+v, want the second entry", got)
+	}
+	got, err = s.List(ctx, AuditLogsListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[2].Error != "must be site admin" || got[2].AccessTokenID != 3 {
+		t.Fatalf("got %+v, want all entries", got)
+	}
+
+	if err := s.DeleteOlderThan(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	got, err = s.List(ctx, AuditLogsListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %d entries after deleting them", len(got))
+	}
+}
+
+func TestAuditLogs_OutOfOrderCommits(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+	db := dbtest.NewDB(t, "")
+	ctx := context.Background()
+
+	insert := func(tx *sql.Tx) *AuditLogEntry {
+		t.Helper()
+		e := &AuditLogEntry{Action: "graphql.deleteUser", Outcome: AuditLogOutcomeSuccess}
+		if err := AuditLogs(tx).Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	listIDs := func(opt AuditLogsListOptions) []int64 {
+		t.Helper()
+		entries, err := AuditLogs(db).List(ctx, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, e := range entries {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	// The entry with the lower ID commits after the entry with the greater ID.
+	tx1, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tx1.Rollback() }()
+	first := insert(tx1)
+
+	tx2, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := insert(tx2)
+	if err := tx2.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if first.ID >= second.ID {
+		t.Fatalf("got IDs %d and %d, want increasing IDs", first.ID, second.ID)
+	}
+
+	// While the first transaction is running, the committed second entry must not be listed,
+	// otherwise an export that resumes after it would skip the first entry.
+	if ids := listIDs(AuditLogsListOptions{}); len(ids) != 0 {
+		t.Fatalf("got entries %v while an older transaction is running, want none", ids)
+	}
+
+	if err := tx1.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	ids := listIDs(AuditLogsListOptions{})
+	if len(ids) != 2 || ids[0] != first.ID || ids[1] != second.ID {
+		t.Fatalf("got entries %v, want %d and %d", ids, first.ID, second.ID)
+	}
+
+	// Resuming after the first entry in commit order lists the second one.
+	txID, err := AuditLogs(db).GetTxID(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := listIDs(AuditLogsListOptions{AfterTxID: txID, AfterID: first.ID}); len(ids) != 1 || ids[0] != second.ID {
+		t.Errorf("got entries %v after the first entry, want %d", ids, second.ID)
+	}
+}
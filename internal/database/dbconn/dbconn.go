@@ -53,6 +53,10 @@ type Opts struct {
 	// AppName overrides the application_name in the DSN. This separate parameter is needed
 	// because we have multiple apps connecting to the same database, but have a single shared DSN configured.
 	AppName string
+
+	// ReadOnly makes all transactions of the connection read-only, for commands that must not
+	// modify the database.
+	ReadOnly bool
 }
 
 // SetupGlobalConnection connects to the given data source and stores the handle
@@ -86,6 +90,9 @@ func New(opts Opts) (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	if opts.ReadOnly {
+		cfg.RuntimeParams["default_transaction_read_only"] = "on"
+	}
 
 	db, err := newWithConfig(cfg)
 	if err != nil {
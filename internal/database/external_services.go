@@ -46,6 +46,8 @@ type ExternalServiceStore struct {
 	GitHubValidators          []func(*schema.GitHubConnection) error
 	GitLabValidators          []func(*schema.GitLabConnection, []schema.AuthProviders) error
 	BitbucketServerValidators []func(*schema.BitbucketServerConnection) error
+	BitbucketCloudValidators  []func(*schema.BitbucketCloudConnection, []schema.AuthProviders) error
+	GiteaValidators           []func(*schema.GiteaConnection, []schema.AuthProviders) error
 	PerforceValidators        []func(*schema.PerforceConnection) error
 
 	key encryption.Key
@@ -60,6 +62,8 @@ func (e *ExternalServiceStore) copy() *ExternalServiceStore {
 		GitHubValidators:          e.GitHubValidators,
 		GitLabValidators:          e.GitLabValidators,
 		BitbucketServerValidators: e.BitbucketServerValidators,
+		BitbucketCloudValidators:  e.BitbucketCloudValidators,
+		GiteaValidators:           e.GiteaValidators,
 		PerforceValidators:        e.PerforceValidators,
 	}
 }
@@ -119,8 +123,11 @@ func (e *ExternalServiceStore) ensureStore() {
 // external services.
 var ExternalServiceKinds = map[string]ExternalServiceKind{
 	extsvc.KindAWSCodeCommit:   {CodeHost: true, JSONSchema: schema.AWSCodeCommitSchemaJSON},
+	extsvc.KindAzureDevOps:     {CodeHost: true, JSONSchema: schema.AzureDevOpsSchemaJSON},
 	extsvc.KindBitbucketCloud:  {CodeHost: true, JSONSchema: schema.BitbucketCloudSchemaJSON},
 	extsvc.KindBitbucketServer: {CodeHost: true, JSONSchema: schema.BitbucketServerSchemaJSON},
+	extsvc.KindGerrit:          {CodeHost: true, JSONSchema: schema.GerritSchemaJSON},
+	extsvc.KindGitea:           {CodeHost: true, JSONSchema: schema.GiteaSchemaJSON},
 	extsvc.KindGitHub:          {CodeHost: true, JSONSchema: schema.GitHubSchemaJSON},
 	extsvc.KindGitLab:          {CodeHost: true, JSONSchema: schema.GitLabSchemaJSON},
 	extsvc.KindGitolite:        {CodeHost: true, JSONSchema: schema.GitoliteSchemaJSON},
@@ -310,7 +317,28 @@ func (e *ExternalServiceStore) ValidateConfig(ctx context.Context, opt ValidateE
 		if err = jsoniter.Unmarshal(normalized, &c); err != nil {
 			return nil, err
 		}
-		err = e.validateBitbucketCloudConnection(ctx, opt.ExternalServiceID, &c)
+		err = e.validateBitbucketCloudConnection(ctx, opt.ExternalServiceID, &c, opt.AuthProviders)
+
+	case extsvc.KindAzureDevOps:
+		var c schema.AzureDevOpsConnection
+		if err = jsoniter.Unmarshal(normalized, &c); err != nil {
+			return nil, err
+		}
+		err = e.validateAzureDevOpsConnection(ctx, opt.ExternalServiceID, &c)
+
+	case extsvc.KindGerrit:
+		var c schema.GerritConnection
+		if err = jsoniter.Unmarshal(normalized, &c); err != nil {
+			return nil, err
+		}
+		err = e.validateGerritConnection(ctx, opt.ExternalServiceID, &c)
+
+	case extsvc.KindGitea:
+		var c schema.GiteaConnection
+		if err = jsoniter.Unmarshal(normalized, &c); err != nil {
+			return nil, err
+		}
+		err = e.validateGiteaConnection(ctx, opt.ExternalServiceID, &c, opt.AuthProviders)
 
 	case extsvc.KindPerforce:
 		var c schema.PerforceConnection
@@ -400,8 +428,54 @@ func (e *ExternalServiceStore) validateBitbucketServerConnection(ctx context.Con
 	return err.ErrorOrNil()
 }
 
-func (e *ExternalServiceStore) validateBitbucketCloudConnection(ctx context.Context, id int64, c *schema.BitbucketCloudConnection) error {
-	return e.validateDuplicateRateLimits(ctx, id, extsvc.KindBitbucketCloud, c)
+func (e *ExternalServiceStore) validateBitbucketCloudConnection(ctx context.Context, id int64, c *schema.BitbucketCloudConnection, ps []schema.AuthProviders) error {
+	err := new(multierror.Error)
+	for _, validate := range e.BitbucketCloudValidators {
+		err = multierror.Append(err, validate(c, ps))
+	}
+
+	err = multierror.Append(err, e.validateDuplicateRateLimits(ctx, id, extsvc.KindBitbucketCloud, c))
+
+	return err.ErrorOrNil()
+}
+
+func (e *ExternalServiceStore) validateAzureDevOpsConnection(ctx context.Context, id int64, c *schema.AzureDevOpsConnection) error {
+	err := new(multierror.Error)
+
+	if len(c.Orgs) == 0 && len(c.Projects) == 0 && len(c.Repos) == 0 {
+		err = multierror.Append(err, errors.New("at least one of orgs, projects or repos must be set"))
+	}
+
+	err = multierror.Append(err, e.validateDuplicateRateLimits(ctx, id, extsvc.KindAzureDevOps, c))
+
+	return err.ErrorOrNil()
+}
+
+func (e *ExternalServiceStore) validateGerritConnection(ctx context.Context, id int64, c *schema.GerritConnection) error {
+	err := new(multierror.Error)
+
+	if (c.Username == "") != (c.Password == "") {
+		err = multierror.Append(err, errors.New("username and password must be set together"))
+	}
+
+	err = multierror.Append(err, e.validateDuplicateRateLimits(ctx, id, extsvc.KindGerrit, c))
+
+	return err.ErrorOrNil()
+}
+
+func (e *ExternalServiceStore) validateGiteaConnection(ctx context.Context, id int64, c *schema.GiteaConnection, ps []schema.AuthProviders) error {
+	err := new(multierror.Error)
+	for _, validate := range e.GiteaValidators {
+		err = multierror.Append(err, validate(c, ps))
+	}
+
+	if c.Repos == nil && c.Orgs == nil && c.Users == nil && c.RepositoryQuery == nil {
+		err = multierror.Append(err, errors.New("at least one of repositoryQuery, repos, orgs or users must be set"))
+	}
+
+	err = multierror.Append(err, e.validateDuplicateRateLimits(ctx, id, extsvc.KindGitea, c))
+
+	return err.ErrorOrNil()
 }
 
 func (e *ExternalServiceStore) validatePerforceConnection(ctx context.Context, id int64, c *schema.PerforceConnection) error {
@@ -29,4 +29,8 @@ type MockStores struct {
 	Authz MockAuthz
 
 	EventLogs MockEventLogs
+
+	AuditLogs MockAuditLogs
+
+	SecurityEventLogs MockSecurityEventLogs
 }
@@ -40,6 +40,19 @@ const (
 	SecurityEventNameRoleChangeGranted SecurityEventName = "RoleChangeGranted"
 
 	SecurityEventNameAccessGranted SecurityEventName = "AccessGranted"
+
+	SecurityEventNameAccessTokenCreated SecurityEventName = "AccessTokenCreated"
+	SecurityEventNameAccessTokenDeleted SecurityEventName = "AccessTokenDeleted"
+	SecurityEventNameAccessTokenUsed    SecurityEventName = "AccessTokenUsed"
+
+	SecurityEventNameSCIMUserCreated     SecurityEventName = "SCIMUserCreated"
+	SecurityEventNameSCIMUserUpdated     SecurityEventName = "SCIMUserUpdated"
+	SecurityEventNameSCIMUserDeactivated SecurityEventName = "SCIMUserDeactivated"
+	SecurityEventNameSCIMUserReactivated SecurityEventName = "SCIMUserReactivated"
+	SecurityEventNameSCIMUserDeleted     SecurityEventName = "SCIMUserDeleted"
+	SecurityEventNameSCIMGroupCreated    SecurityEventName = "SCIMGroupCreated"
+	SecurityEventNameSCIMGroupUpdated    SecurityEventName = "SCIMGroupUpdated"
+	SecurityEventNameSCIMGroupDeleted    SecurityEventName = "SCIMGroupDeleted"
 )
 
 // SecurityEvent contains information needed for logging a security-relevant event.
@@ -65,6 +78,10 @@ func SecurityEventLogs(db dbutil.DB) *SecurityEventLogStore {
 
 // Insert adds a new security event to the store.
 func (s *SecurityEventLogStore) Insert(ctx context.Context, e *SecurityEvent) error {
+	if Mocks.SecurityEventLogs.Insert != nil {
+		return Mocks.SecurityEventLogs.Insert(ctx, e)
+	}
+
 	argument := e.Argument
 	if argument == nil {
 		argument = []byte(`{}`)
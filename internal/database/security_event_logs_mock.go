@@ -0,0 +1,7 @@
+package database
+
+import "context"
+
+type MockSecurityEventLogs struct {
+	Insert func(ctx context.Context, e *SecurityEvent) error
+}
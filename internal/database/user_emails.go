@@ -10,6 +10,7 @@ import (
 
 	"github.com/cockroachdb/errors"
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 
 	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbconn"
@@ -342,6 +343,19 @@ func (s *UserEmailsStore) ListByUser(ctx context.Context, opt UserEmailsListOpti
 	return s.getBySQL(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 }
 
+// ListByUsers returns the emails of the given users, in one query.
+func (s *UserEmailsStore) ListByUsers(ctx context.Context, userIDs []int32) ([]*UserEmail, error) {
+	if Mocks.UserEmails.ListByUsers != nil {
+		return Mocks.UserEmails.ListByUsers(ctx, userIDs)
+	}
+	if len(userIDs) == 0 {
+		return []*UserEmail{}, nil
+	}
+
+	q := sqlf.Sprintf("WHERE user_id = ANY(%s) ORDER BY user_id ASC, created_at ASC, email ASC", pq.Array(userIDs))
+	return s.getBySQL(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+}
+
 // getBySQL returns user emails matching the SQL query, if any exist.
 func (s *UserEmailsStore) getBySQL(ctx context.Context, query string, args ...interface{}) ([]*UserEmail, error) {
 	s.ensureStore()
@@ -12,4 +12,5 @@ type MockUserEmails struct {
 	GetLatestVerificationSentEmail func(ctx context.Context, email string) (*UserEmail, error)
 	GetVerifiedEmails              func(ctx context.Context, emails ...string) ([]*UserEmail, error)
 	ListByUser                     func(ctx context.Context, opt UserEmailsListOptions) ([]*UserEmail, error)
+	ListByUsers                    func(ctx context.Context, userIDs []int32) ([]*UserEmail, error)
 }
@@ -655,6 +655,33 @@ func (u *UserStore) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bo
 	return err
 }
 
+// SetDeactivated deactivates or reactivates the user with the given ID. Deactivated users keep
+// their data, but they are signed out and can't sign in or use access tokens until they are
+// reactivated.
+func (u *UserStore) SetDeactivated(ctx context.Context, id int32, deactivated bool) error {
+	if Mocks.Users.SetDeactivated != nil {
+		return Mocks.Users.SetDeactivated(ctx, id, deactivated)
+	}
+	u.ensureStore()
+
+	q := sqlf.Sprintf("UPDATE users SET deactivated_at=NULL, updated_at=now() WHERE id=%s AND deleted_at IS NULL", id)
+	if deactivated {
+		q = sqlf.Sprintf("UPDATE users SET deactivated_at=COALESCE(deactivated_at, now()), invalidated_sessions_at=now(), updated_at=now() WHERE id=%s AND deleted_at IS NULL", id)
+	}
+	res, err := u.ExecResult(ctx, q)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+	return nil
+}
+
 // CheckAndDecrementInviteQuota should be called before the user (identified
 // by userID) is allowed to invite any other user. If ok is false, then the
 // user is not allowed to invite any other user (either because they've
@@ -807,6 +834,9 @@ type UsersListOptions struct {
 
 	Tag string // only include users with this tag
 
+	SiteAdmins         bool // only include site admins
+	ExcludeDeactivated bool // exclude deactivated users
+
 	*LimitOffset
 }
 
@@ -888,6 +918,12 @@ func (*UserStore) listSQL(opt UsersListOptions) (conds []*sqlf.Query) {
 	if opt.Tag != "" {
 		conds = append(conds, sqlf.Sprintf("%s::text = ANY(u.tags)", opt.Tag))
 	}
+	if opt.SiteAdmins {
+		conds = append(conds, sqlf.Sprintf("site_admin"))
+	}
+	if opt.ExcludeDeactivated {
+		conds = append(conds, sqlf.Sprintf("deactivated_at IS NULL"))
+	}
 	return conds
 }
 
@@ -908,7 +944,7 @@ func (u *UserStore) getOneBySQL(ctx context.Context, q *sqlf.Query) (*types.User
 func (u *UserStore) getBySQL(ctx context.Context, query *sqlf.Query) ([]*types.User, error) {
 	u.ensureStore()
 
-	q := sqlf.Sprintf("SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags, u.invalidated_sessions_at FROM users u %s", query)
+	q := sqlf.Sprintf("SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags, u.invalidated_sessions_at, u.deactivated_at FROM users u %s", query)
 	rows, err := u.Query(ctx, q)
 	if err != nil {
 		return nil, err
@@ -919,7 +955,7 @@ func (u *UserStore) getBySQL(ctx context.Context, query *sqlf.Query) ([]*types.U
 	for rows.Next() {
 		var u types.User
 		var displayName, avatarURL sql.NullString
-		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.InvalidatedSessionsAt)
+		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.InvalidatedSessionsAt, &u.DeactivatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -13,6 +13,7 @@ type MockUsers struct {
 	Delete                       func(ctx context.Context, id int32) error
 	HardDelete                   func(ctx context.Context, id int32) error
 	SetIsSiteAdmin               func(id int32, isSiteAdmin bool) error
+	SetDeactivated               func(ctx context.Context, id int32, deactivated bool) error
 	CheckAndDecrementInviteQuota func(ctx context.Context, userID int32) (bool, error)
 	GetByID                      func(ctx context.Context, id int32) (*types.User, error)
 	GetByUsername                func(ctx context.Context, username string) (*types.User, error)
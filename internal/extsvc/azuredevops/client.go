@@ -0,0 +1,302 @@
+package azuredevops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+var requestCounter = metrics.NewRequestMeter("azuredevops_requests_count", "Total number of requests sent to the Azure DevOps API.")
+
+// These fields define the self-imposed Azure DevOps rate limit. Azure DevOps throttles
+// based on a usage budget that is not communicated up front, so we stay well below it.
+//
+// See https://godoc.org/golang.org/x/time/rate#Limiter for an explanation of these fields.
+const (
+	rateLimitRequestsPerSecond = 2 // 120/min or 7200/hr
+	RateLimitMaxBurstRequests  = 500
+)
+
+// apiVersion is the version of the REST API the client is written against. It is supported
+// by Azure DevOps Services and Azure DevOps Server 2020 and later.
+const apiVersion = "6.0"
+
+// continuationTokenHeader is the response header Azure DevOps uses to return the token
+// for the next page of a listing.
+const continuationTokenHeader = "X-Ms-Continuationtoken"
+
+// Client access Azure DevOps Services or Azure DevOps Server via its REST API.
+type Client struct {
+	// HTTP Client used to communicate with the API
+	httpClient httpcli.Doer
+
+	// URL is the base URL of Azure DevOps, without an organization or collection name.
+	URL *url.URL
+
+	// Token is the personal access token used to authenticate requests.
+	Token string
+
+	// RateLimit is the self-imposed rate limiter.
+	RateLimit *rate.Limiter
+}
+
+// NewClient creates a new Azure DevOps API client with the given base URL. If a nil
+// httpClient is provided, httpcli.ExternalDoer will be used.
+func NewClient(baseURL *url.URL, httpClient httpcli.Doer) *Client {
+	if httpClient == nil {
+		httpClient = httpcli.ExternalDoer()
+	}
+
+	httpClient = requestCounter.Doer(httpClient, func(u *url.URL) string {
+		// The first two components of the Path after the "_apis" segment (which follows
+		// the organization and project names) map to the type of API request we are making.
+		path := u.Path
+		if i := strings.Index(path, "/_apis/"); i >= 0 {
+			path = path[i+len("/_apis/"):]
+		}
+		parts := strings.SplitN(path, "/", 3)
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+		return strings.Join(parts, "/")
+	})
+
+	// Normally our registry will return a default infinite limiter when nothing has been
+	// synced from config. However, we always want to ensure there is at least some form of rate
+	// limiting for Azure DevOps.
+	defaultLimiter := rate.NewLimiter(rateLimitRequestsPerSecond, RateLimitMaxBurstRequests)
+	l := ratelimit.DefaultRegistry.GetOrSet(baseURL.String(), defaultLimiter)
+
+	return &Client{
+		httpClient: httpClient,
+		URL:        baseURL,
+		RateLimit:  l,
+	}
+}
+
+// Project is an Azure DevOps project (called a team project in older versions).
+//
+// See https://docs.microsoft.com/en-us/rest/api/azure/devops/core/projects/list?view=azure-devops-rest-6.0#teamprojectreference
+type Project struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	State       string `json:"state,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+}
+
+// Project visibilities as reported by Azure DevOps.
+const (
+	VisibilityPrivate = "private"
+	VisibilityPublic  = "public"
+)
+
+// Repository is a Git repository in an Azure DevOps project.
+//
+// See https://docs.microsoft.com/en-us/rest/api/azure/devops/git/repositories/list?view=azure-devops-rest-6.0#gitrepository
+type Repository struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Project       Project `json:"project"`
+	DefaultBranch string  `json:"defaultBranch,omitempty"`
+	Size          int64   `json:"size,omitempty"`
+	RemoteURL     string  `json:"remoteUrl"`
+	SSHURL        string  `json:"sshUrl"`
+	WebURL        string  `json:"webUrl"`
+	IsDisabled    bool    `json:"isDisabled,omitempty"`
+	IsFork        bool    `json:"isFork,omitempty"`
+}
+
+// ListProjectsArgs describes a page of a project listing.
+type ListProjectsArgs struct {
+	// Org is the name of the organization (or collection) whose projects are listed.
+	Org string
+
+	// PerPage is the maximum number of projects to return. If zero, the server default is used.
+	PerPage int
+
+	// ContinuationToken is the token returned by the previous call to ListProjects, if any.
+	ContinuationToken string
+}
+
+// ListProjects returns a page of the projects in the given organization that are visible
+// to the client. The returned continuation token is empty if there are no further pages.
+//
+// See https://docs.microsoft.com/en-us/rest/api/azure/devops/core/projects/list?view=azure-devops-rest-6.0
+func (c *Client) ListProjects(ctx context.Context, args ListProjectsArgs) (projects []*Project, next string, err error) {
+	qry := url.Values{}
+	if args.PerPage > 0 {
+		qry.Set("$top", strconv.Itoa(args.PerPage))
+	}
+	if args.ContinuationToken != "" {
+		qry.Set("continuationToken", args.ContinuationToken)
+	}
+
+	req, err := http.NewRequest("GET", c.path(qry, args.Org, "_apis", "projects"), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var resp struct {
+		Value []*Project `json:"value"`
+	}
+	header, err := c.do(ctx, req, &resp)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Value, header.Get(continuationTokenHeader), nil
+}
+
+// ListRepositories returns all Git repositories in the given project.
+//
+// See https://docs.microsoft.com/en-us/rest/api/azure/devops/git/repositories/list?view=azure-devops-rest-6.0
+func (c *Client) ListRepositories(ctx context.Context, org, project string) ([]*Repository, error) {
+	req, err := http.NewRequest("GET", c.path(nil, org, project, "_apis", "git", "repositories"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Value []*Repository `json:"value"`
+	}
+	if _, err := c.do(ctx, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Value, nil
+}
+
+// GetRepository returns the Git repository with the given name or ID in the given project.
+//
+// See https://docs.microsoft.com/en-us/rest/api/azure/devops/git/repositories/get-repository?view=azure-devops-rest-6.0
+func (c *Client) GetRepository(ctx context.Context, org, project, nameOrID string) (*Repository, error) {
+	req, err := http.NewRequest("GET", c.path(nil, org, project, "_apis", "git", "repositories", nameOrID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var repo Repository
+	if _, err := c.do(ctx, req, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// path returns the REST API path built from the given path segments, relative to the
+// base URL, with the API version and given query parameters.
+func (c *Client) path(qry url.Values, segments ...string) string {
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	if qry == nil {
+		qry = url.Values{}
+	}
+	qry.Set("api-version", apiVersion)
+
+	return strings.Join(segments, "/") + "?" + qry.Encode()
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, result interface{}) (http.Header, error) {
+	// The base URL may contain a path (e.g. https://example.com/tfs), so paths are
+	// resolved relative to it.
+	base := *c.URL
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	req.URL = base.ResolveReference(req.URL)
+	req.Header.Set("Accept", "application/json")
+
+	req, ht := nethttp.TraceRequest(ot.GetTracer(ctx),
+		req.WithContext(ctx),
+		nethttp.OperationName("Azure DevOps"),
+		nethttp.ClientTrace(false))
+	defer ht.Finish()
+
+	// Personal access tokens are sent as the password of basic authentication with an
+	// empty username.
+	if c.Token != "" {
+		req.SetBasicAuth("", c.Token)
+	}
+
+	startWait := time.Now()
+	if err := c.RateLimit.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if d := time.Since(startWait); d > 200*time.Millisecond {
+		log15.Warn("Azure DevOps self-enforced API rate limit: request delayed longer than expected due to rate limit", "delay", d)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Azure DevOps answers requests with an invalid token with its HTML sign-in page and
+	// 203 Non-Authoritative Information rather than with a 401.
+	statusCode := resp.StatusCode
+	if statusCode == http.StatusNonAuthoritativeInfo {
+		statusCode = http.StatusUnauthorized
+	}
+
+	if statusCode < 200 || statusCode >= 400 {
+		return nil, errors.WithStack(&httpError{
+			URL:        req.URL,
+			StatusCode: statusCode,
+			Body:       bs,
+		})
+	}
+
+	if result != nil {
+		return resp.Header, json.Unmarshal(bs, result)
+	}
+
+	return resp.Header, nil
+}
+
+type httpError struct {
+	StatusCode int
+	URL        *url.URL
+	Body       []byte
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("Azure DevOps API HTTP error: code=%d url=%q body=%q", e.StatusCode, e.URL, e.Body)
+}
+
+func (e *httpError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+func (e *httpError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsNotFound reports whether err is an Azure DevOps API "404 Not Found" error.
+func IsNotFound(err error) bool {
+	var e *httpError
+	return errors.As(err, &e) && e.NotFound()
+}
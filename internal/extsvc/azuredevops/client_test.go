@@ -0,0 +1,164 @@
+package azuredevops
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update testdata")
+
+var (
+	projectSourcegraph = Project{
+		ID:          "9a6b1f8e-3c1d-4e52-a3f0-5b2c7d8e9f01",
+		Name:        "sourcegraph",
+		Description: "Sourcegraph repositories mirrored from GitHub",
+		State:       "wellFormed",
+		Visibility:  VisibilityPublic,
+	}
+	projectDataScience = Project{
+		ID:         "4d2e6c1a-8b7f-4a93-9e15-0c3f2a1b6d02",
+		Name:       "Data Science",
+		State:      "wellFormed",
+		Visibility: VisibilityPrivate,
+	}
+	projectSandbox = Project{
+		ID:          "e1f0c9b8-a7d6-4c5e-b4f3-2a1908d7e603",
+		Name:        "sandbox",
+		Description: "Scratch space for trying out Azure Repos",
+		State:       "wellFormed",
+		Visibility:  VisibilityPrivate,
+	}
+)
+
+func TestClient_ListProjects(t *testing.T) {
+	cli, save := NewTestClient(t, "ListProjects", *update, &url.URL{Scheme: "https", Host: "dev.azure.com"})
+	defer save()
+
+	timeout, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context
+		args     ListProjectsArgs
+		projects []*Project
+		next     string
+		err      string
+	}{
+		{
+			name: "timeout",
+			ctx:  timeout,
+			args: ListProjectsArgs{Org: "sgtest", PerPage: 2},
+			err:  "context deadline exceeded",
+		},
+		{
+			name:     "pagination: first page",
+			args:     ListProjectsArgs{Org: "sgtest", PerPage: 2},
+			projects: []*Project{&projectSourcegraph, &projectDataScience},
+			next:     "2",
+		},
+		{
+			name:     "pagination: last page",
+			args:     ListProjectsArgs{Org: "sgtest", PerPage: 2, ContinuationToken: "2"},
+			projects: []*Project{&projectSandbox},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.ctx == nil {
+				tc.ctx = context.Background()
+			}
+
+			if tc.err == "" {
+				tc.err = "<nil>"
+			}
+
+			projects, next, err := cli.ListProjects(tc.ctx, tc.args)
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			if have, want := next, tc.next; have != want {
+				t.Errorf("next continuation token:\nhave: %q\nwant: %q", have, want)
+			}
+
+			if diff := cmp.Diff(tc.projects, projects); err == nil && diff != "" {
+				t.Errorf("projects mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClient_ListRepositories(t *testing.T) {
+	cli, save := NewTestClient(t, "ListRepositories", *update, &url.URL{Scheme: "https", Host: "dev.azure.com"})
+	defer save()
+
+	t.Run("found", func(t *testing.T) {
+		repos, err := cli.ListRepositories(context.Background(), "sgtest", "Data Science")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []*Repository{{
+			ID:            "c4e1e1b8-0c57-4f0a-8e2e-5d3c8f1c1a03",
+			Name:          "ML Models",
+			Project:       projectDataScience,
+			DefaultBranch: "refs/heads/main",
+			Size:          5120,
+			RemoteURL:     "https://sgtest@dev.azure.com/sgtest/Data%20Science/_git/ML%20Models",
+			SSHURL:        "git@ssh.dev.azure.com:v3/sgtest/Data%20Science/ML%20Models",
+			WebURL:        "https://dev.azure.com/sgtest/Data%20Science/_git/ML%20Models",
+		}}
+		if diff := cmp.Diff(want, repos); diff != "" {
+			t.Errorf("repositories mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := cli.ListRepositories(context.Background(), "sgtest", "does-not-exist")
+
+		if !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestClient_GetRepository(t *testing.T) {
+	cli, save := NewTestClient(t, "GetRepository", *update, &url.URL{Scheme: "https", Host: "dev.azure.com"})
+	defer save()
+
+	t.Run("found", func(t *testing.T) {
+		repo, err := cli.GetRepository(context.Background(), "sgtest", "sourcegraph", "src-cli")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &Repository{
+			ID:            "c4e1e1b8-0c57-4f0a-8e2e-5d3c8f1c1a01",
+			Name:          "src-cli",
+			Project:       projectSourcegraph,
+			DefaultBranch: "refs/heads/main",
+			Size:          2412381,
+			RemoteURL:     "https://sgtest@dev.azure.com/sgtest/sourcegraph/_git/src-cli",
+			SSHURL:        "git@ssh.dev.azure.com:v3/sgtest/sourcegraph/src-cli",
+			WebURL:        "https://dev.azure.com/sgtest/sourcegraph/_git/src-cli",
+		}
+		if diff := cmp.Diff(want, repo); diff != "" {
+			t.Errorf("repository mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := cli.GetRepository(context.Background(), "sgtest", "sourcegraph", "does-not-exist")
+
+		if !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
@@ -50,6 +50,10 @@ type Client struct {
 	// The username and app password credentials for accessing the server.
 	Username, AppPassword string
 
+	// Token is an OAuth access token. If set, it is used to authenticate requests
+	// instead of the username and app password.
+	Token string
+
 	// RateLimit is the self-imposed rate limiter (since Bitbucket does not have a concept
 	// of rate limiting in HTTP response headers).
 	RateLimit *rate.Limiter
@@ -86,6 +90,14 @@ func NewClient(apiURL *url.URL, httpClient httpcli.Doer) *Client {
 	}
 }
 
+// WithToken returns a copy of the client that authenticates requests with the given OAuth
+// access token.
+func (c *Client) WithToken(token string) *Client {
+	cli := *c
+	cli.Token = token
+	return &cli
+}
+
 // Repos returns a list of repositories that are fetched and populated based on given account
 // name and pagination criteria. If the account requested is a team, results will be filtered
 // down to the ones that the app password's user has access to.
@@ -104,6 +116,22 @@ func (c *Client) Repos(ctx context.Context, pageToken *PageToken, accountName st
 	return repos, next, err
 }
 
+// CurrentUserRepos returns a list of repositories the authenticated user is a member of,
+// including the repositories they have access to through a workspace or a group. It
+// requires the client to be authenticated as a user, with an OAuth token or an app password.
+// Pagination works the same way as with Repos.
+func (c *Client) CurrentUserRepos(ctx context.Context, pageToken *PageToken) ([]*Repo, *PageToken, error) {
+	var repos []*Repo
+	var next *PageToken
+	var err error
+	if pageToken.HasMore() {
+		next, err = c.reqPage(ctx, pageToken.Next, &repos)
+	} else {
+		next, err = c.page(ctx, "/2.0/repositories", url.Values{"role": []string{"member"}}, pageToken, &repos)
+	}
+	return repos, next, err
+}
+
 func (c *Client) page(ctx context.Context, path string, qry url.Values, token *PageToken, results interface{}) (*PageToken, error) {
 	if qry == nil {
 		qry = make(url.Values)
@@ -195,6 +223,10 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 }
 
 func (c *Client) authenticate(req *http.Request) error {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		return nil
+	}
 	req.SetBasicAuth(c.Username, c.AppPassword)
 	return nil
 }
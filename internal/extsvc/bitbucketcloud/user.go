@@ -0,0 +1,117 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/oauth2"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// User is a Bitbucket Cloud user.
+type User struct {
+	UUID        string    `json:"uuid"`
+	AccountID   string    `json:"account_id"`
+	Username    string    `json:"username"`
+	Nickname    string    `json:"nickname"`
+	DisplayName string    `json:"display_name"`
+	Links       UserLinks `json:"links"`
+}
+
+type UserLinks struct {
+	Avatar Link `json:"avatar"`
+	HTML   Link `json:"html"`
+}
+
+// Login returns the name the user signs in to Bitbucket Cloud with. Bitbucket Cloud only
+// returns the username of the authenticated user, so the nickname is used otherwise.
+func (u *User) Login() string {
+	if u.Username != "" {
+		return u.Username
+	}
+	return u.Nickname
+}
+
+// UserEmail is an email address of a Bitbucket Cloud user.
+type UserEmail struct {
+	Email       string `json:"email"`
+	IsPrimary   bool   `json:"is_primary"`
+	IsConfirmed bool   `json:"is_confirmed"`
+}
+
+// CurrentUser returns the authenticated user.
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	req, err := http.NewRequest("GET", "/2.0/user", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := c.do(ctx, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CurrentUserEmails returns all the email addresses of the authenticated user. It requires
+// the "email" scope.
+func (c *Client) CurrentUserEmails(ctx context.Context) ([]*UserEmail, error) {
+	var emails []*UserEmail
+	page := &PageToken{Pagelen: 100}
+	for {
+		var values []*UserEmail
+		var err error
+		if page.HasMore() {
+			page, err = c.reqPage(ctx, page.Next, &values)
+		} else {
+			page, err = c.page(ctx, "/2.0/user/emails", nil, page, &values)
+		}
+		if err != nil {
+			return nil, err
+		}
+		emails = append(emails, values...)
+		if !page.HasMore() {
+			return emails, nil
+		}
+	}
+}
+
+// GetExternalAccountData returns the deserialized user and token from the external account data
+// JSON blob in a typesafe way.
+func GetExternalAccountData(data *extsvc.AccountData) (usr *User, tok *oauth2.Token, err error) {
+	var (
+		u User
+		t oauth2.Token
+	)
+
+	if data.Data != nil {
+		if err := data.GetAccountData(&u); err != nil {
+			return nil, nil, err
+		}
+		usr = &u
+	}
+	if data.AuthData != nil {
+		if err := data.GetAuthData(&t); err != nil {
+			return nil, nil, err
+		}
+		tok = &t
+	}
+	return usr, tok, nil
+}
+
+// SetExternalAccountData sets the user and token into the external account data blob.
+func SetExternalAccountData(data *extsvc.AccountData, user *User, token *oauth2.Token) {
+	data.SetAccountData(user)
+	data.SetAuthData(token)
+}
+
+// OAuthEndpoint returns the OAuth2 endpoint of Bitbucket Cloud with the given base URL (not
+// the API URL).
+func OAuthEndpoint(baseURL *url.URL) oauth2.Endpoint {
+	return oauth2.Endpoint{
+		AuthURL:  baseURL.ResolveReference(&url.URL{Path: "/site/oauth2/authorize"}).String(),
+		TokenURL: baseURL.ResolveReference(&url.URL{Path: "/site/oauth2/access_token"}).String(),
+	}
+}
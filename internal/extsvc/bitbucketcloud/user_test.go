@@ -0,0 +1,80 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient_CurrentUser(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if have, want := r.Header.Get("Authorization"), "Bearer s3cr3t"; have != want {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var v interface{}
+		switch r.URL.Path + "?" + r.URL.RawQuery {
+		case "/2.0/user?":
+			v = map[string]interface{}{"uuid": "{1}", "username": "alice", "display_name": "Alice"}
+		case "/2.0/user/emails?pagelen=100":
+			v = map[string]interface{}{
+				"values": []map[string]interface{}{{"email": "alice@example.com", "is_primary": true, "is_confirmed": true}},
+				"next":   srv.URL + "/2.0/user/emails?pagelen=100&page=2",
+			}
+		case "/2.0/user/emails?pagelen=100&page=2":
+			v = map[string]interface{}{
+				"values": []map[string]interface{}{{"email": "alice@old.example.com"}},
+			}
+		case "/2.0/repositories?pagelen=1&role=member":
+			v = map[string]interface{}{
+				"values": []map[string]interface{}{{"uuid": "{2}", "full_name": "acme/api", "is_private": true}},
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(v)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	cli := NewClient(u, srv.Client())
+	cli.Username = "ignored"
+	cli.Token = "s3cr3t"
+	ctx := context.Background()
+
+	user, err := cli.CurrentUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(&User{UUID: "{1}", Username: "alice", DisplayName: "Alice"}, user); diff != "" {
+		t.Errorf("user mismatch (-want +got):\n%s", diff)
+	}
+
+	emails, err := cli.CurrentUserEmails(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEmails := []*UserEmail{
+		{Email: "alice@example.com", IsPrimary: true, IsConfirmed: true},
+		{Email: "alice@old.example.com"},
+	}
+	if diff := cmp.Diff(wantEmails, emails); diff != "" {
+		t.Errorf("emails mismatch (-want +got):\n%s", diff)
+	}
+
+	repos, next, err := cli.CurrentUserRepos(ctx, &PageToken{Pagelen: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 1 || repos[0].FullName != "acme/api" || next.HasMore() {
+		t.Errorf("got repos %+v and next page %+v", repos, next)
+	}
+}
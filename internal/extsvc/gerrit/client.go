@@ -0,0 +1,288 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+var requestCounter = metrics.NewRequestMeter("gerrit_requests_count", "Total number of requests sent to the Gerrit API.")
+
+// These fields define the self-imposed Gerrit rate limit (since Gerrit does not have a
+// concept of rate limiting in HTTP response headers).
+//
+// See https://godoc.org/golang.org/x/time/rate#Limiter for an explanation of these fields.
+const (
+	rateLimitRequestsPerSecond = 2 // 120/min or 7200/hr
+	RateLimitMaxBurstRequests  = 500
+)
+
+// xssiPrefix is the magic prefix Gerrit prepends to every JSON response body to prevent
+// cross-site script inclusion. It must be stripped before the body can be decoded.
+//
+// See https://gerrit-review.googlesource.com/Documentation/rest-api.html#output
+var xssiPrefix = []byte(")]}'")
+
+// Client access a Gerrit instance via its REST API.
+type Client struct {
+	// HTTP Client used to communicate with the API
+	httpClient httpcli.Doer
+
+	// URL is the base URL of the Gerrit instance.
+	URL *url.URL
+
+	// The username and HTTP password credentials for accessing the instance. If both
+	// are empty, requests are made anonymously.
+	Username, Password string
+
+	// RateLimit is the self-imposed rate limiter (since Gerrit does not have a concept
+	// of rate limiting in HTTP response headers).
+	RateLimit *rate.Limiter
+}
+
+// NewClient creates a new Gerrit API client with the given base URL. If a nil httpClient
+// is provided, httpcli.ExternalDoer will be used.
+func NewClient(baseURL *url.URL, httpClient httpcli.Doer) *Client {
+	if httpClient == nil {
+		httpClient = httpcli.ExternalDoer()
+	}
+
+	httpClient = requestCounter.Doer(httpClient, func(u *url.URL) string {
+		// The first component of the Path (after the optional authentication prefix)
+		// maps to the type of API request we are making.
+		path := strings.TrimPrefix(strings.TrimPrefix(u.Path, baseURL.Path), "/")
+		path = strings.TrimPrefix(path, "a/")
+		if i := strings.Index(path, "/"); i >= 0 {
+			path = path[:i]
+		}
+		return path
+	})
+
+	// Normally our registry will return a default infinite limiter when nothing has been
+	// synced from config. However, we always want to ensure there is at least some form of rate
+	// limiting for Gerrit.
+	defaultLimiter := rate.NewLimiter(rateLimitRequestsPerSecond, RateLimitMaxBurstRequests)
+	l := ratelimit.DefaultRegistry.GetOrSet(baseURL.String(), defaultLimiter)
+
+	return &Client{
+		httpClient: httpClient,
+		URL:        baseURL,
+		RateLimit:  l,
+	}
+}
+
+// Project is a Gerrit project as returned by the "List Projects" and "Get Project"
+// endpoints.
+//
+// See https://gerrit-review.googlesource.com/Documentation/rest-api-projects.html#project-info
+type Project struct {
+	// ID is the URL encoded project name, e.g. "platform%2Fbuild".
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Parent      string    `json:"parent,omitempty"`
+	Description string    `json:"description,omitempty"`
+	State       string    `json:"state,omitempty"`
+	WebLinks    []WebLink `json:"web_links,omitempty"`
+}
+
+// WebLink is a link to an external site (such as a repository browser) attached to a project.
+type WebLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// Project states as reported by Gerrit.
+const (
+	ProjectStateActive   = "ACTIVE"
+	ProjectStateReadOnly = "READ_ONLY"
+	ProjectStateHidden   = "HIDDEN"
+)
+
+// Pagination describes a page of a paginated Gerrit listing. Gerrit paginates by offset,
+// and signals that more results exist by setting a flag on the last returned item.
+type Pagination struct {
+	PerPage int
+	Skip    int
+}
+
+// ListProjects returns a page of the code projects visible to the client, ordered by
+// name. Permission-only projects (such as All-Projects) are not returned. The returned
+// Pagination is nil if there are no further pages.
+//
+// See https://gerrit-review.googlesource.com/Documentation/rest-api-projects.html#list-projects
+func (c *Client) ListProjects(ctx context.Context, page *Pagination) ([]*Project, *Pagination, error) {
+	if page == nil {
+		page = &Pagination{}
+	}
+
+	qry := url.Values{}
+	qry.Set("d", "")
+	qry.Set("type", "CODE")
+	if page.PerPage > 0 {
+		qry.Set("n", strconv.Itoa(page.PerPage))
+	}
+	if page.Skip > 0 {
+		qry.Set("S", strconv.Itoa(page.Skip))
+	}
+
+	req, err := http.NewRequest("GET", c.path("projects/")+"?"+qry.Encode(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Gerrit returns a map keyed by project name, in which each project lacks a name
+	// field. The last project in the page carries the pagination flag.
+	var resp map[string]*struct {
+		Project
+		MoreProjects bool `json:"_more_projects"`
+	}
+	if err := c.do(ctx, req, &resp); err != nil {
+		return nil, nil, err
+	}
+
+	projects := make([]*Project, 0, len(resp))
+	more := false
+	for name, p := range resp {
+		if p == nil {
+			continue
+		}
+		project := p.Project
+		project.Name = name
+		projects = append(projects, &project)
+		more = more || p.MoreProjects
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+
+	if !more || page.PerPage <= 0 {
+		return projects, nil, nil
+	}
+	return projects, &Pagination{PerPage: page.PerPage, Skip: page.Skip + len(resp)}, nil
+}
+
+// GetProject returns the project with the given name.
+//
+// See https://gerrit-review.googlesource.com/Documentation/rest-api-projects.html#get-project
+func (c *Client) GetProject(ctx context.Context, name string) (*Project, error) {
+	req, err := http.NewRequest("GET", c.path("projects/"+url.PathEscape(name)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var project Project
+	if err := c.do(ctx, req, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// authenticated returns true if the client has credentials configured.
+func (c *Client) authenticated() bool {
+	return c.Username != "" || c.Password != ""
+}
+
+// path returns the given REST API path relative to the base URL. Authenticated requests
+// must be made against endpoints with the "/a/" prefix.
+func (c *Client) path(p string) string {
+	if c.authenticated() {
+		return "a/" + p
+	}
+	return p
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, result interface{}) error {
+	// The base URL may contain a path (e.g. https://example.com/gerrit), so paths are
+	// resolved relative to it.
+	base := *c.URL
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	req.URL = base.ResolveReference(req.URL)
+	req.Header.Set("Accept", "application/json")
+
+	req, ht := nethttp.TraceRequest(ot.GetTracer(ctx),
+		req.WithContext(ctx),
+		nethttp.OperationName("Gerrit"),
+		nethttp.ClientTrace(false))
+	defer ht.Finish()
+
+	if c.authenticated() {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	startWait := time.Now()
+	if err := c.RateLimit.Wait(ctx); err != nil {
+		return err
+	}
+
+	if d := time.Since(startWait); d > 200*time.Millisecond {
+		log15.Warn("Gerrit self-enforced API rate limit: request delayed longer than expected due to rate limit", "delay", d)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return errors.WithStack(&httpError{
+			URL:        req.URL,
+			StatusCode: resp.StatusCode,
+			Body:       bs,
+		})
+	}
+
+	if result != nil {
+		return json.Unmarshal(bytes.TrimPrefix(bs, xssiPrefix), result)
+	}
+
+	return nil
+}
+
+type httpError struct {
+	StatusCode int
+	URL        *url.URL
+	Body       []byte
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("Gerrit API HTTP error: code=%d url=%q body=%q", e.StatusCode, e.URL, e.Body)
+}
+
+func (e *httpError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+func (e *httpError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsNotFound reports whether err is a Gerrit API "404 Not Found" error.
+func IsNotFound(err error) bool {
+	var e *httpError
+	return errors.As(err, &e) && e.NotFound()
+}
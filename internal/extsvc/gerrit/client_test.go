@@ -0,0 +1,147 @@
+package gerrit
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update testdata")
+
+func TestClient_ListProjects(t *testing.T) {
+	cli, save := NewTestClient(t, "ListProjects", *update, &url.URL{Scheme: "https", Host: "gerrit.sgdev.org"})
+	defer save()
+
+	timeout, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	projects := map[string]*Project{
+		"sourcegraph/about": {
+			ID:          "sourcegraph%2Fabout",
+			Name:        "sourcegraph/about",
+			Description: "Sourcegraph blog, feature announcements, and website",
+			State:       ProjectStateActive,
+			WebLinks:    []WebLink{{Name: "browse", URL: "/plugins/gitiles/sourcegraph/about"}},
+		},
+		"sourcegraph/jsonrpc2": {
+			ID:          "sourcegraph%2Fjsonrpc2",
+			Name:        "sourcegraph/jsonrpc2",
+			Description: "Package jsonrpc2 provides a client and server implementation of JSON-RPC 2.0",
+			State:       ProjectStateActive,
+			WebLinks:    []WebLink{{Name: "browse", URL: "/plugins/gitiles/sourcegraph/jsonrpc2"}},
+		},
+		"sandbox/playground": {
+			ID:     "sandbox%2Fplayground",
+			Name:   "sandbox/playground",
+			Parent: "sandbox",
+			State:  ProjectStateReadOnly,
+		},
+		"sourcegraph/src-cli": {
+			ID:          "sourcegraph%2Fsrc-cli",
+			Name:        "sourcegraph/src-cli",
+			Description: "Sourcegraph CLI",
+			State:       ProjectStateActive,
+			WebLinks:    []WebLink{{Name: "browse", URL: "/plugins/gitiles/sourcegraph/src-cli"}},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context
+		page     *Pagination
+		projects []*Project
+		next     *Pagination
+		err      string
+	}{
+		{
+			name: "timeout",
+			ctx:  timeout,
+			page: &Pagination{PerPage: 2},
+			err:  "context deadline exceeded",
+		},
+		{
+			name:     "pagination: first page",
+			page:     &Pagination{PerPage: 2},
+			projects: []*Project{projects["sourcegraph/about"], projects["sourcegraph/jsonrpc2"]},
+			next:     &Pagination{PerPage: 2, Skip: 2},
+		},
+		{
+			name:     "pagination: last page",
+			page:     &Pagination{PerPage: 2, Skip: 2},
+			projects: []*Project{projects["sandbox/playground"], projects["sourcegraph/src-cli"]},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.ctx == nil {
+				tc.ctx = context.Background()
+			}
+
+			if tc.err == "" {
+				tc.err = "<nil>"
+			}
+
+			projects, next, err := cli.ListProjects(tc.ctx, tc.page)
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			if diff := cmp.Diff(tc.next, next); diff != "" {
+				t.Errorf("next page mismatch (-want +got):\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.projects, projects); err == nil && diff != "" {
+				t.Errorf("projects mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClient_GetProject(t *testing.T) {
+	cli, save := NewTestClient(t, "GetProject", *update, &url.URL{Scheme: "https", Host: "gerrit.sgdev.org"})
+	defer save()
+
+	t.Run("found", func(t *testing.T) {
+		project, err := cli.GetProject(context.Background(), "sourcegraph/src-cli")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := &Project{
+			ID:          "sourcegraph%2Fsrc-cli",
+			Name:        "sourcegraph/src-cli",
+			Parent:      "All-Projects",
+			Description: "Sourcegraph CLI",
+			State:       ProjectStateActive,
+			WebLinks:    []WebLink{{Name: "browse", URL: "/plugins/gitiles/sourcegraph/src-cli"}},
+		}
+		if diff := cmp.Diff(want, project); diff != "" {
+			t.Errorf("project mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := cli.GetProject(context.Background(), "sourcegraph/does-not-exist")
+
+		if !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestClient_Authenticated(t *testing.T) {
+	cli := NewClient(&url.URL{Scheme: "https", Host: "gerrit.sgdev.org", Path: "/r"}, nil)
+	if have, want := cli.path("projects/"), "projects/"; have != want {
+		t.Errorf("unexpected anonymous path. want=%q have=%q", want, have)
+	}
+
+	cli.Username, cli.Password = "alice", "secret"
+	if have, want := cli.path("projects/"), "a/projects/"; have != want {
+		t.Errorf("unexpected authenticated path. want=%q have=%q", want, have)
+	}
+}
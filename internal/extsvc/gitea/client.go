@@ -0,0 +1,426 @@
+// Package gitea implements a Gitea API client. Forgejo, a fork of Gitea, exposes the
+// same API and is supported as well.
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	"golang.org/x/time/rate"
+
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+var requestCounter = metrics.NewRequestMeter("gitea_requests_count", "Total number of requests sent to the Gitea API.")
+
+// These fields define the self-imposed Gitea rate limit (since Gitea does not have a
+// concept of rate limiting in HTTP response headers).
+//
+// See https://godoc.org/golang.org/x/time/rate#Limiter for an explanation of these fields.
+const (
+	rateLimitRequestsPerSecond = 2 // 120/min or 7200/hr
+	RateLimitMaxBurstRequests  = 500
+)
+
+// apiPath is the path of the Gitea REST API relative to the base URL of the instance.
+const apiPath = "api/v1/"
+
+// Client access a Gitea instance via its REST API.
+//
+// See https://try.gitea.io/api/swagger
+type Client struct {
+	// HTTP Client used to communicate with the API
+	httpClient httpcli.Doer
+
+	// URL is the base URL of the Gitea instance.
+	URL *url.URL
+
+	// Token is the access token used to authenticate requests.
+	Token string
+
+	// RateLimit is the self-imposed rate limiter (since Gitea does not have a concept
+	// of rate limiting in HTTP response headers).
+	RateLimit *rate.Limiter
+
+	// sudo is the username of the user to impersonate, if any.
+	sudo string
+}
+
+// NewClient creates a new Gitea API client with the given base URL. If a nil httpClient
+// is provided, httpcli.ExternalDoer will be used.
+func NewClient(baseURL *url.URL, httpClient httpcli.Doer) *Client {
+	if httpClient == nil {
+		httpClient = httpcli.ExternalDoer()
+	}
+
+	httpClient = requestCounter.Doer(httpClient, func(u *url.URL) string {
+		// The first component of the Path after the API prefix maps to the type of API
+		// request we are making.
+		path := strings.TrimPrefix(u.Path, baseURL.Path)
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "/"), apiPath)
+		if i := strings.Index(path, "/"); i >= 0 {
+			path = path[:i]
+		}
+		return path
+	})
+
+	// Normally our registry will return a default infinite limiter when nothing has been
+	// synced from config. However, we always want to ensure there is at least some form of rate
+	// limiting for Gitea.
+	defaultLimiter := rate.NewLimiter(rateLimitRequestsPerSecond, RateLimitMaxBurstRequests)
+	l := ratelimit.DefaultRegistry.GetOrSet(baseURL.String(), defaultLimiter)
+
+	return &Client{
+		httpClient: httpClient,
+		URL:        baseURL,
+		RateLimit:  l,
+	}
+}
+
+// Sudo returns a copy of the client that makes requests on behalf of the user with the
+// given username. This requires the client's token to belong to a site admin.
+func (c *Client) Sudo(username string) *Client {
+	sudo := *c
+	sudo.sudo = username
+	return &sudo
+}
+
+// WithToken returns a copy of the client that authenticates requests with the given
+// access token, such as the OAuth token of a user.
+func (c *Client) WithToken(token string) *Client {
+	cli := *c
+	cli.Token = token
+	cli.sudo = ""
+	return &cli
+}
+
+// User is a Gitea user or organization.
+type User struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	FullName  string `json:"full_name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	AvatarURL string `json:"avatar_url,omitempty"`
+	IsAdmin   bool   `json:"is_admin,omitempty"`
+}
+
+// Repository is a Gitea repository.
+type Repository struct {
+	ID            int64  `json:"id"`
+	Owner         *User  `json:"owner"`
+	Name          string `json:"name"`
+	FullName      string `json:"full_name"`
+	Description   string `json:"description,omitempty"`
+	Empty         bool   `json:"empty,omitempty"`
+	Private       bool   `json:"private,omitempty"`
+	Fork          bool   `json:"fork,omitempty"`
+	Mirror        bool   `json:"mirror,omitempty"`
+	Archived      bool   `json:"archived,omitempty"`
+	HTMLURL       string `json:"html_url"`
+	CloneURL      string `json:"clone_url"`
+	SSHURL        string `json:"ssh_url"`
+	DefaultBranch string `json:"default_branch,omitempty"`
+	Stars         int    `json:"stars_count,omitempty"`
+}
+
+// Team is a team of an organization.
+type Team struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Permission string `json:"permission,omitempty"`
+
+	// IncludesAllRepositories is true if the team has access to all repositories of
+	// the organization, as is the case for the "Owners" team.
+	IncludesAllRepositories bool `json:"includes_all_repositories,omitempty"`
+}
+
+// Pagination describes a page of a paginated Gitea listing. Pages are 1-based.
+type Pagination struct {
+	Page    int
+	PerPage int
+}
+
+// next returns the page following p if a listing returned n items for p, and nil if
+// it was the last page.
+func (p *Pagination) next(n int) *Pagination {
+	if p.PerPage <= 0 || n < p.PerPage {
+		return nil
+	}
+	return &Pagination{Page: p.Page + 1, PerPage: p.PerPage}
+}
+
+func (p *Pagination) values() url.Values {
+	qry := url.Values{}
+	if p.Page > 0 {
+		qry.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.PerPage > 0 {
+		qry.Set("limit", strconv.Itoa(p.PerPage))
+	}
+	return qry
+}
+
+// ListOrgRepos returns a page of the repositories of the given organization. The
+// returned Pagination is nil if there are no further pages.
+func (c *Client) ListOrgRepos(ctx context.Context, org string, page *Pagination) ([]*Repository, *Pagination, error) {
+	return c.listRepos(ctx, "orgs/"+url.PathEscape(org)+"/repos", page)
+}
+
+// ListUserRepos returns a page of the repositories owned by the given user. The returned
+// Pagination is nil if there are no further pages.
+func (c *Client) ListUserRepos(ctx context.Context, username string, page *Pagination) ([]*Repository, *Pagination, error) {
+	return c.listRepos(ctx, "users/"+url.PathEscape(username)+"/repos", page)
+}
+
+// ListAccessibleRepos returns a page of the repositories the authenticated user (or the
+// impersonated user, see Sudo) owns or has been given access to. The returned Pagination
+// is nil if there are no further pages.
+func (c *Client) ListAccessibleRepos(ctx context.Context, page *Pagination) ([]*Repository, *Pagination, error) {
+	return c.listRepos(ctx, "user/repos", page)
+}
+
+func (c *Client) listRepos(ctx context.Context, path string, page *Pagination) ([]*Repository, *Pagination, error) {
+	if page == nil {
+		page = &Pagination{}
+	}
+
+	var repos []*Repository
+	if err := c.get(ctx, path, page.values(), &repos); err != nil {
+		return nil, nil, err
+	}
+	return repos, page.next(len(repos)), nil
+}
+
+// SearchRepos returns a page of the repositories visible to the client whose name
+// contains the given keyword. An empty keyword matches all repositories. The returned
+// Pagination is nil if there are no further pages.
+func (c *Client) SearchRepos(ctx context.Context, keyword string, page *Pagination) ([]*Repository, *Pagination, error) {
+	if page == nil {
+		page = &Pagination{}
+	}
+
+	qry := page.values()
+	if keyword != "" {
+		qry.Set("q", keyword)
+	}
+
+	var resp struct {
+		OK   bool          `json:"ok"`
+		Data []*Repository `json:"data"`
+	}
+	if err := c.get(ctx, "repos/search", qry, &resp); err != nil {
+		return nil, nil, err
+	}
+	return resp.Data, page.next(len(resp.Data)), nil
+}
+
+// GetRepo returns the repository with the given owner and name.
+func (c *Client) GetRepo(ctx context.Context, owner, name string) (*Repository, error) {
+	var repo Repository
+	if err := c.get(ctx, "repos/"+url.PathEscape(owner)+"/"+url.PathEscape(name), nil, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// GetRepoByID returns the repository with the given ID.
+func (c *Client) GetRepoByID(ctx context.Context, id int64) (*Repository, error) {
+	var repo Repository
+	if err := c.get(ctx, "repositories/"+strconv.FormatInt(id, 10), nil, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// ListCollaborators returns a page of the collaborators of the given repository. The
+// returned Pagination is nil if there are no further pages.
+func (c *Client) ListCollaborators(ctx context.Context, owner, name string, page *Pagination) ([]*User, *Pagination, error) {
+	if page == nil {
+		page = &Pagination{}
+	}
+
+	var users []*User
+	path := "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name) + "/collaborators"
+	if err := c.get(ctx, path, page.values(), &users); err != nil {
+		return nil, nil, err
+	}
+	return users, page.next(len(users)), nil
+}
+
+// ListRepoTeams returns the organization teams with access to the given repository. It
+// returns a nil slice if the repository is owned by a user rather than an organization,
+// and a non-nil slice otherwise.
+func (c *Client) ListRepoTeams(ctx context.Context, owner, name string) ([]*Team, error) {
+	var teams []*Team
+	path := "repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name) + "/teams"
+	if err := c.get(ctx, path, nil, &teams); err != nil {
+		// Gitea answers with 405 Method Not Allowed for repositories not owned by an
+		// organization.
+		var e *httpError
+		if errors.As(err, &e) && e.StatusCode == http.StatusMethodNotAllowed {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if teams == nil {
+		teams = []*Team{}
+	}
+	return teams, nil
+}
+
+// ListTeamMembers returns a page of the members of the team with the given ID. The
+// returned Pagination is nil if there are no further pages.
+func (c *Client) ListTeamMembers(ctx context.Context, teamID int64, page *Pagination) ([]*User, *Pagination, error) {
+	if page == nil {
+		page = &Pagination{}
+	}
+
+	var users []*User
+	if err := c.get(ctx, "teams/"+strconv.FormatInt(teamID, 10)+"/members", page.values(), &users); err != nil {
+		return nil, nil, err
+	}
+	return users, page.next(len(users)), nil
+}
+
+// GetUser returns the user with the given username.
+func (c *Client) GetUser(ctx context.Context, username string) (*User, error) {
+	var user User
+	if err := c.get(ctx, "users/"+url.PathEscape(username), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Email is an email address of a Gitea user.
+type Email struct {
+	Email    string `json:"email"`
+	Verified bool   `json:"verified"`
+	Primary  bool   `json:"primary"`
+}
+
+// ListEmails returns the email addresses of the user the client's token belongs to.
+func (c *Client) ListEmails(ctx context.Context) ([]*Email, error) {
+	var emails []*Email
+	if err := c.get(ctx, "user/emails", nil, &emails); err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+// AuthenticatedUser returns the user the client's token belongs to.
+func (c *Client) AuthenticatedUser(ctx context.Context) (*User, error) {
+	var user User
+	if err := c.get(ctx, "user", nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, qry url.Values, result interface{}) error {
+	u := &url.URL{Path: apiPath + path}
+	if len(qry) > 0 {
+		u.RawQuery = qry.Encode()
+	}
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, result)
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, result interface{}) error {
+	// The base URL may contain a path (e.g. https://example.com/gitea), so paths are
+	// resolved relative to it.
+	base := *c.URL
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+	req.URL = base.ResolveReference(req.URL)
+	req.Header.Set("Accept", "application/json")
+
+	req, ht := nethttp.TraceRequest(ot.GetTracer(ctx),
+		req.WithContext(ctx),
+		nethttp.OperationName("Gitea"),
+		nethttp.ClientTrace(false))
+	defer ht.Finish()
+
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+	if c.sudo != "" {
+		req.Header.Set("Sudo", c.sudo)
+	}
+
+	startWait := time.Now()
+	if err := c.RateLimit.Wait(ctx); err != nil {
+		return err
+	}
+
+	if d := time.Since(startWait); d > 200*time.Millisecond {
+		log15.Warn("Gitea self-enforced API rate limit: request delayed longer than expected due to rate limit", "delay", d)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	bs, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return errors.WithStack(&httpError{
+			URL:        req.URL,
+			StatusCode: resp.StatusCode,
+			Body:       bs,
+		})
+	}
+
+	if result != nil {
+		return json.Unmarshal(bs, result)
+	}
+
+	return nil
+}
+
+type httpError struct {
+	StatusCode int
+	URL        *url.URL
+	Body       []byte
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("Gitea API HTTP error: code=%d url=%q body=%q", e.StatusCode, e.URL, e.Body)
+}
+
+func (e *httpError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized
+}
+
+func (e *httpError) NotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+// IsNotFound reports whether err is a Gitea API "404 Not Found" error.
+func IsNotFound(err error) bool {
+	var e *httpError
+	return errors.As(err, &e) && e.NotFound()
+}
@@ -0,0 +1,244 @@
+package gitea
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestClient(t *testing.T, f *Fixture) *Client {
+	t.Helper()
+
+	srv := NewTestServer(t, f)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cli := NewClient(u, srv.Client())
+	cli.Token = f.Token
+	return cli
+}
+
+func repoNames(repos []*Repository) []string {
+	names := make([]string, 0, len(repos))
+	for _, r := range repos {
+		names = append(names, r.FullName)
+	}
+	return names
+}
+
+func userLogins(users []*User) []string {
+	logins := make([]string, 0, len(users))
+	for _, u := range users {
+		logins = append(logins, u.Login)
+	}
+	return logins
+}
+
+func TestClient_ListOrgRepos(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestClient(t, NewTestFixture())
+
+	t.Run("pagination", func(t *testing.T) {
+		var names []string
+		var pages int
+		for page := (&Pagination{Page: 1, PerPage: 2}); page != nil; pages++ {
+			repos, next, err := cli.ListOrgRepos(ctx, "acme", page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, repoNames(repos)...)
+			page = next
+		}
+
+		if diff := cmp.Diff([]string{"acme/api", "acme/web", "acme/legacy"}, names); diff != "" {
+			t.Errorf("repos mismatch (-want +got):\n%s", diff)
+		}
+		if pages != 2 {
+			t.Errorf("have %d pages, want 2", pages)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, _, err := cli.ListOrgRepos(ctx, "does-not-exist", nil)
+		if !IsNotFound(err) {
+			t.Fatalf("expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestClient_SearchRepos(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestClient(t, NewTestFixture())
+
+	for _, tc := range []struct {
+		keyword string
+		want    []string
+	}{
+		{keyword: "", want: []string{"acme/api", "acme/web", "acme/legacy", "alice/dotfiles", "alice/notes", "sandbox/playground"}},
+		{keyword: "E", want: []string{"acme/web", "acme/legacy", "alice/dotfiles", "alice/notes"}},
+		{keyword: "nothing", want: []string{}},
+	} {
+		t.Run(tc.keyword, func(t *testing.T) {
+			repos, next, err := cli.SearchRepos(ctx, tc.keyword, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next != nil {
+				t.Errorf("unexpected next page %+v", next)
+			}
+			if diff := cmp.Diff(tc.want, repoNames(repos)); diff != "" {
+				t.Errorf("repos mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestClient_GetRepo(t *testing.T) {
+	ctx := context.Background()
+	f := NewTestFixture()
+	cli := newTestClient(t, f)
+
+	repo, err := cli.GetRepo(ctx, "acme", "web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Repository{
+		ID:            101,
+		Owner:         &User{ID: 10, Login: "acme", FullName: "ACME Corp."},
+		Name:          "web",
+		FullName:      "acme/web",
+		Description:   "ACME website",
+		HTMLURL:       cli.URL.String() + "/acme/web",
+		CloneURL:      cli.URL.String() + "/acme/web.git",
+		SSHURL:        "git@" + cli.URL.Host + ":acme/web.git",
+		DefaultBranch: "main",
+		Stars:         12,
+	}
+	if diff := cmp.Diff(want, repo); diff != "" {
+		t.Errorf("repo mismatch (-want +got):\n%s", diff)
+	}
+
+	byID, err := cli.GetRepoByID(ctx, 101)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(repo, byID); diff != "" {
+		t.Errorf("repo by ID mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := cli.GetRepo(ctx, "acme", "does-not-exist"); !IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestClient_Sudo(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestClient(t, NewTestFixture())
+
+	user, err := cli.Sudo("bob").AuthenticatedUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Login != "bob" {
+		t.Errorf("have authenticated user %q, want %q", user.Login, "bob")
+	}
+
+	repos, _, err := cli.Sudo("bob").ListAccessibleRepos(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"alice/notes"}, repoNames(repos)); diff != "" {
+		t.Errorf("repos mismatch (-want +got):\n%s", diff)
+	}
+
+	// Bob is not allowed to see private repositories he has no access to.
+	if _, err := cli.Sudo("bob").GetRepo(ctx, "acme", "api"); !IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	// Sudo must not modify the original client.
+	admin, err := cli.AuthenticatedUser(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !admin.IsAdmin {
+		t.Errorf("expected %q to be a site admin", admin.Login)
+	}
+}
+
+func TestClient_ListEmails(t *testing.T) {
+	cli := newTestClient(t, NewTestFixture())
+	cli.Token = "alice-oauth-token"
+
+	emails, err := cli.ListEmails(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Email{{Email: "alice@sourcegraph.test", Verified: true, Primary: true}}
+	if diff := cmp.Diff(want, emails); diff != "" {
+		t.Errorf("emails mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClient_RepoAccess(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestClient(t, NewTestFixture())
+
+	collaborators, _, err := cli.ListCollaborators(ctx, "alice", "notes", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"bob"}, userLogins(collaborators)); diff != "" {
+		t.Errorf("collaborators mismatch (-want +got):\n%s", diff)
+	}
+
+	teams, err := cli.ListRepoTeams(ctx, "acme", "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Team{
+		{ID: 20, Name: "Owners", Permission: "owner", IncludesAllRepositories: true},
+		{ID: 21, Name: "Backend", Permission: "write"},
+	}
+	if diff := cmp.Diff(want, teams); diff != "" {
+		t.Errorf("teams mismatch (-want +got):\n%s", diff)
+	}
+
+	members, _, err := cli.ListTeamMembers(ctx, 21, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"carol"}, userLogins(members)); diff != "" {
+		t.Errorf("team members mismatch (-want +got):\n%s", diff)
+	}
+
+	// Repositories owned by users have no teams.
+	teams, err = cli.ListRepoTeams(ctx, "alice", "notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if teams != nil {
+		t.Errorf("have teams %v, want nil", teams)
+	}
+}
+
+func TestClient_Unauthorized(t *testing.T) {
+	cli := newTestClient(t, NewTestFixture())
+	cli.Token = "wrong"
+
+	_, err := cli.AuthenticatedUser(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var e *httpError
+	if !errors.As(err, &e) || !e.Unauthorized() {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+}
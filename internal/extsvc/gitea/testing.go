@@ -0,0 +1,393 @@
+package gitea
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Fixture is the state of an in-memory Gitea instance served by NewTestServer.
+type Fixture struct {
+	// Token is the access token requests must be authenticated with. It belongs to Admin.
+	Token string
+	Admin *User
+
+	Users []*User
+	Orgs  []*User
+	Repos []*Repository
+	Teams []*FixtureTeam
+
+	// Collaborators maps repository IDs to the users that have been added to the
+	// repository as collaborators.
+	Collaborators map[int64][]*User
+
+	// UserTokens maps OAuth access tokens to the users they belong to.
+	UserTokens map[string]*User
+}
+
+// FixtureTeam is a team of an organization of a Fixture.
+type FixtureTeam struct {
+	Team
+	Org     string
+	Members []*User
+	RepoIDs []int64
+}
+
+// NewTestFixture returns a Fixture with a few users, organizations, teams and
+// repositories with different visibilities and permissions:
+//
+//	acme/api           private  team "Owners" (alice), team "Backend" (carol), collaborator dave
+//	acme/web           public
+//	acme/legacy        private  archived, team "Owners" (alice)
+//	alice/dotfiles     public   fork
+//	alice/notes        private  collaborator bob
+//	sandbox/playground public
+func NewTestFixture() *Fixture {
+	admin := &User{ID: 1, Login: "sourcegraph", Email: "admin@sourcegraph.test", IsAdmin: true}
+	alice := &User{ID: 2, Login: "alice", FullName: "Alice Liddell", Email: "alice@sourcegraph.test"}
+	bob := &User{ID: 3, Login: "bob", Email: "bob@sourcegraph.test"}
+	carol := &User{ID: 4, Login: "carol", Email: "carol@sourcegraph.test"}
+	dave := &User{ID: 5, Login: "dave", Email: "dave@sourcegraph.test"}
+	acme := &User{ID: 10, Login: "acme", FullName: "ACME Corp."}
+	sandbox := &User{ID: 11, Login: "sandbox"}
+
+	repo := func(id int64, owner *User, name string, mod func(*Repository)) *Repository {
+		r := &Repository{
+			ID:            id,
+			Owner:         owner,
+			Name:          name,
+			FullName:      owner.Login + "/" + name,
+			DefaultBranch: "main",
+		}
+		if mod != nil {
+			mod(r)
+		}
+		return r
+	}
+
+	return &Fixture{
+		Token: "s3cr3t",
+		Admin: admin,
+		Users: []*User{admin, alice, bob, carol, dave},
+		Orgs:  []*User{acme, sandbox},
+		Repos: []*Repository{
+			repo(100, acme, "api", func(r *Repository) {
+				r.Description = "ACME public API"
+				r.Private = true
+			}),
+			repo(101, acme, "web", func(r *Repository) {
+				r.Description = "ACME website"
+				r.Stars = 12
+			}),
+			repo(102, acme, "legacy", func(r *Repository) {
+				r.Private = true
+				r.Archived = true
+			}),
+			repo(103, alice, "dotfiles", func(r *Repository) {
+				r.Fork = true
+			}),
+			repo(104, alice, "notes", func(r *Repository) {
+				r.Private = true
+			}),
+			repo(105, sandbox, "playground", func(r *Repository) {
+				r.Empty = true
+			}),
+		},
+		Teams: []*FixtureTeam{
+			{
+				Team:    Team{ID: 20, Name: "Owners", Permission: "owner", IncludesAllRepositories: true},
+				Org:     "acme",
+				Members: []*User{alice},
+			},
+			{
+				Team:    Team{ID: 21, Name: "Backend", Permission: "write"},
+				Org:     "acme",
+				Members: []*User{carol},
+				RepoIDs: []int64{100},
+			},
+		},
+		Collaborators: map[int64][]*User{
+			100: {dave},
+			104: {bob},
+		},
+		UserTokens: map[string]*User{
+			"alice-oauth-token": alice,
+			"bob-oauth-token":   bob,
+		},
+	}
+}
+
+// NewTestServer returns a running HTTP server that serves the subset of the Gitea API
+// used by the Client from the given fixture. The server is closed when the test ends.
+func NewTestServer(t testing.TB, f *Fixture) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(&fixtureHandler{Fixture: f, baseURL: srv.URL}).ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type fixtureHandler struct {
+	*Fixture
+	baseURL string
+}
+
+func (h *fixtureHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, `{"message":"method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "token ")
+	actor := h.UserTokens[token]
+	if token != h.Token && actor == nil {
+		http.Error(w, `{"message":"token is required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if actor == nil {
+		actor = h.Admin
+	}
+	if sudo := r.Header.Get("Sudo"); sudo != "" {
+		if !actor.IsAdmin {
+			http.Error(w, `{"message":"only site admins can sudo"}`, http.StatusForbidden)
+			return
+		}
+		if actor = h.user(sudo); actor == nil {
+			http.Error(w, `{"message":"sudo user does not exist"}`, http.StatusForbidden)
+			return
+		}
+	}
+
+	path := strings.Split(strings.TrimPrefix(r.URL.Path, "/"+apiPath), "/")
+	switch {
+	case len(path) == 1 && path[0] == "user":
+		h.write(w, actor)
+
+	case len(path) == 2 && path[0] == "user" && path[1] == "emails":
+		emails := []*Email{}
+		if actor.Email != "" {
+			emails = append(emails, &Email{Email: actor.Email, Verified: true, Primary: true})
+		}
+		h.write(w, emails)
+
+	case len(path) == 2 && path[0] == "user" && path[1] == "repos":
+		h.writeRepos(w, r, actor, func(repo *Repository) bool { return h.hasAccess(actor, repo) })
+
+	case len(path) == 2 && path[0] == "users":
+		if u := h.user(path[1]); u != nil {
+			h.write(w, u)
+			return
+		}
+		if org := h.org(path[1]); org != nil {
+			h.write(w, org)
+			return
+		}
+		h.notFound(w)
+
+	case len(path) == 3 && path[0] == "users" && path[2] == "repos":
+		if h.user(path[1]) == nil {
+			h.notFound(w)
+			return
+		}
+		h.writeRepos(w, r, actor, func(repo *Repository) bool { return repo.Owner.Login == path[1] })
+
+	case len(path) == 3 && path[0] == "orgs" && path[2] == "repos":
+		if h.org(path[1]) == nil {
+			h.notFound(w)
+			return
+		}
+		h.writeRepos(w, r, actor, func(repo *Repository) bool { return repo.Owner.Login == path[1] })
+
+	case len(path) == 2 && path[0] == "repos" && path[1] == "search":
+		q := strings.ToLower(r.URL.Query().Get("q"))
+		var repos []*Repository
+		for _, repo := range h.Repos {
+			if h.visible(actor, repo) && strings.Contains(strings.ToLower(repo.Name), q) {
+				repos = append(repos, h.withURLs(repo))
+			}
+		}
+		start, end := paginate(r, len(repos))
+		h.write(w, map[string]interface{}{"ok": true, "data": repos[start:end]})
+
+	case len(path) == 2 && path[0] == "repositories":
+		id, _ := strconv.ParseInt(path[1], 10, 64)
+		for _, repo := range h.Repos {
+			if repo.ID == id && h.visible(actor, repo) {
+				h.write(w, h.withURLs(repo))
+				return
+			}
+		}
+		h.notFound(w)
+
+	case len(path) >= 3 && path[0] == "repos":
+		repo := h.repo(path[1], path[2])
+		if repo == nil || !h.visible(actor, repo) {
+			h.notFound(w)
+			return
+		}
+
+		switch {
+		case len(path) == 3:
+			h.write(w, h.withURLs(repo))
+		case len(path) == 4 && path[3] == "collaborators":
+			users := h.Collaborators[repo.ID]
+			start, end := paginate(r, len(users))
+			h.write(w, users[start:end])
+		case len(path) == 4 && path[3] == "teams":
+			if h.org(repo.Owner.Login) == nil {
+				http.Error(w, `{"message":"repo is not owned by an organization"}`, http.StatusMethodNotAllowed)
+				return
+			}
+			teams := []*Team{}
+			for _, t := range h.Teams {
+				if t.Org == repo.Owner.Login && t.hasRepo(repo) {
+					teams = append(teams, &t.Team)
+				}
+			}
+			h.write(w, teams)
+		default:
+			h.notFound(w)
+		}
+
+	case len(path) == 3 && path[0] == "teams" && path[2] == "members":
+		id, _ := strconv.ParseInt(path[1], 10, 64)
+		for _, t := range h.Teams {
+			if t.ID == id {
+				start, end := paginate(r, len(t.Members))
+				h.write(w, t.Members[start:end])
+				return
+			}
+		}
+		h.notFound(w)
+
+	default:
+		h.notFound(w)
+	}
+}
+
+func (h *fixtureHandler) writeRepos(w http.ResponseWriter, r *http.Request, actor *User, match func(*Repository) bool) {
+	repos := []*Repository{}
+	for _, repo := range h.Repos {
+		if match(repo) && h.visible(actor, repo) {
+			repos = append(repos, h.withURLs(repo))
+		}
+	}
+	start, end := paginate(r, len(repos))
+	h.write(w, repos[start:end])
+}
+
+func (h *fixtureHandler) write(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (h *fixtureHandler) notFound(w http.ResponseWriter) {
+	http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+}
+
+func (h *fixtureHandler) user(login string) *User {
+	for _, u := range h.Users {
+		if u.Login == login {
+			return u
+		}
+	}
+	return nil
+}
+
+func (h *fixtureHandler) org(login string) *User {
+	for _, o := range h.Orgs {
+		if o.Login == login {
+			return o
+		}
+	}
+	return nil
+}
+
+func (h *fixtureHandler) repo(owner, name string) *Repository {
+	for _, r := range h.Repos {
+		if r.Owner.Login == owner && r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// visible returns true if the actor can see the repository.
+func (h *fixtureHandler) visible(actor *User, repo *Repository) bool {
+	return !repo.Private || actor.IsAdmin || h.hasAccess(actor, repo)
+}
+
+// hasAccess returns true if the actor owns the repository or has been given access to it
+// as a collaborator or through a team.
+func (h *fixtureHandler) hasAccess(actor *User, repo *Repository) bool {
+	if repo.Owner.ID == actor.ID {
+		return true
+	}
+	for _, u := range h.Collaborators[repo.ID] {
+		if u.ID == actor.ID {
+			return true
+		}
+	}
+	for _, t := range h.Teams {
+		if t.Org != repo.Owner.Login || !t.hasRepo(repo) {
+			continue
+		}
+		for _, u := range t.Members {
+			if u.ID == actor.ID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withURLs returns a copy of the repository with its URLs pointing at the server.
+func (h *fixtureHandler) withURLs(repo *Repository) *Repository {
+	r := *repo
+	r.HTMLURL = h.baseURL + "/" + r.FullName
+	r.CloneURL = r.HTMLURL + ".git"
+	r.SSHURL = "git@" + strings.TrimPrefix(h.baseURL, "http://") + ":" + r.FullName + ".git"
+	return &r
+}
+
+func (t *FixtureTeam) hasRepo(repo *Repository) bool {
+	if t.IncludesAllRepositories {
+		return true
+	}
+	for _, id := range t.RepoIDs {
+		if id == repo.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate returns the bounds of the page of n items requested by the page and limit
+// query parameters of r, which default to 1 and 30 like they do in Gitea.
+func paginate(r *http.Request, n int) (start, end int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 30
+	}
+
+	start = (page - 1) * limit
+	if start > n {
+		start = n
+	}
+	end = start + limit
+	if end > n {
+		end = n
+	}
+	return start, end
+}
@@ -108,6 +108,54 @@ func TestExtractRateLimitConfig(t *testing.T) {
 				IsDefault:   true,
 			},
 		},
+		{
+			name:        "Azure DevOps default",
+			config:      `{"url": "https://example.com/"}`,
+			kind:        KindAzureDevOps,
+			displayName: "AzureDevOps 1",
+			want: RateLimitConfig{
+				BaseURL:     "https://example.com/",
+				DisplayName: "AzureDevOps 1",
+				Limit:       2.0,
+				IsDefault:   true,
+			},
+		},
+		{
+			name:        "Gerrit default",
+			config:      `{"url": "https://example.com/"}`,
+			kind:        KindGerrit,
+			displayName: "Gerrit 1",
+			want: RateLimitConfig{
+				BaseURL:     "https://example.com/",
+				DisplayName: "Gerrit 1",
+				Limit:       2.0,
+				IsDefault:   true,
+			},
+		},
+		{
+			name:        "Gitea default",
+			config:      `{"url": "https://example.com/"}`,
+			kind:        KindGitea,
+			displayName: "Gitea 1",
+			want: RateLimitConfig{
+				BaseURL:     "https://example.com/",
+				DisplayName: "Gitea 1",
+				Limit:       2.0,
+				IsDefault:   true,
+			},
+		},
+		{
+			name:        "Gitea non-default",
+			config:      `{"url": "https://example.com/", "rateLimit": {"enabled": true, "requestsPerHour": 3600}}`,
+			kind:        KindGitea,
+			displayName: "Gitea 1",
+			want: RateLimitConfig{
+				BaseURL:     "https://example.com/",
+				DisplayName: "Gitea 1",
+				Limit:       1.0,
+				IsDefault:   false,
+			},
+		},
 		{
 			name:        "GitLab non-default",
 			config:      `{"url": "https://example.com/", "rateLimit": {"enabled": true, "requestsPerHour": 3600}}`,
@@ -304,6 +352,24 @@ func TestUniqueCodeHostIdentifier(t *testing.T) {
 			want:   "https://bitbucket.org/",
 		},
 
+		{
+			kind:   KindAzureDevOps,
+			config: `{"url": "https://dev.azure.com"}`,
+			want:   "https://dev.azure.com/",
+		},
+
+		{
+			kind:   KindGerrit,
+			config: `{"url": "https://Gerrit.example.com/r"}`,
+			want:   "https://gerrit.example.com/r/",
+		},
+
+		{
+			kind:   KindGitea,
+			config: `{"url": "https://codeberg.org"}`,
+			want:   "https://codeberg.org/",
+		},
+
 		{
 			kind:   KindGitolite,
 			config: `{"host": "ssh://git@gitolite.example.com:2222/"}`,
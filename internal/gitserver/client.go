@@ -695,6 +695,33 @@ func (c *Client) IsRepoCloned(ctx context.Context, repo api.RepoName) (bool, err
 	return cloned, nil
 }
 
+// MockSearchCommitIndex mocks (*Client).SearchCommitIndex for tests.
+var MockSearchCommitIndex func(*protocol.CommitIndexSearchRequest) (*protocol.CommitIndexSearchResponse, error)
+
+// SearchCommitIndex returns the commits on the default branch of a repository
+// that may match the request, using the commit index of gitserver.
+func (c *Client) SearchCommitIndex(ctx context.Context, req *protocol.CommitIndexSearchRequest) (*protocol.CommitIndexSearchResponse, error) {
+	if MockSearchCommitIndex != nil {
+		return MockSearchCommitIndex(req)
+	}
+
+	resp, err := c.httpPost(ctx, req.Repo, "commit-index-search", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, errors.Errorf("gitserver error (status code %d): %s", resp.StatusCode, string(body))
+	}
+
+	var res protocol.CommitIndexSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 func (c *Client) RepoCloneProgress(ctx context.Context, repos ...api.RepoName) (*protocol.RepoCloneProgressResponse, error) {
 	numPossibleShards := len(c.Addrs())
 	shards := make(map[string]*protocol.RepoCloneProgressRequest, (len(repos)/numPossibleShards)*2) // 2x because it may not be a perfect division
@@ -0,0 +1,240 @@
+// Package commitindex implements an incremental index of the commits on the
+// default branch of a git repository. For every commit it stores the trigrams
+// of the message, the author and the added and removed lines of the diff. Diff
+// and commit searches use it to find the few commits that can match a query
+// instead of running git log over the full history.
+//
+// The index consists of immutable segment files and a state file listing them,
+// which is replaced atomically. Each update adds a segment for the commits
+// added to HEAD since the last update.
+package commitindex
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	stateFile = "state.json"
+
+	// maxCandidates is the maximum number of commits returned by Search. If a
+	// query matches more commits the index doesn't help much, and the query is
+	// reported as unfiltered.
+	maxCandidates = 100000
+)
+
+// Index is the commit index of a repository stored in a directory.
+type Index struct {
+	dir string
+}
+
+// New returns the index stored in dir. The directory doesn't need to exist.
+func New(dir string) *Index {
+	return &Index{dir: dir}
+}
+
+type state struct {
+	Version int
+
+	// Head is the commit the index is up to date with.
+	Head string
+
+	// Next is the number of the last segment written. It is used to name new
+	// segments.
+	Next int
+
+	// Segments are ordered by the age of their commits, newest first.
+	Segments []segmentInfo
+}
+
+type segmentInfo struct {
+	Name    string
+	Commits int
+}
+
+func (ix *Index) readState() (*state, error) {
+	data, err := os.ReadFile(filepath.Join(ix.dir, stateFile))
+	if os.IsNotExist(err) {
+		return &state{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var st state
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, errors.Wrap(err, "invalid commit index state")
+	}
+	return &st, nil
+}
+
+func (ix *Index) writeState(st *state) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(ix.dir, stateFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(ix.dir, stateFile))
+}
+
+// Head returns the commit the index is up to date with, or "" if the
+// repository hasn't been indexed.
+func (ix *Index) Head() (string, error) {
+	st, err := ix.readState()
+	if err != nil || st.Version != stateVersion {
+		return "", err
+	}
+	return st.Head, nil
+}
+
+// Result is the result of a search.
+type Result struct {
+	// Head is the commit the index is up to date with. Commits added to HEAD
+	// after it are not searched.
+	Head string
+
+	// Filtered is false if the query can't be answered by the index. This
+	// happens if its patterns don't contain trigrams, or if it matches too many
+	// commits.
+	Filtered bool
+
+	// Commits are the candidate commits, newest first.
+	Commits []string
+}
+
+// Search returns the commits that may match q.
+func (ix *Index) Search(q Query) (*Result, error) {
+	st, err := ix.readState()
+	if err != nil {
+		return nil, err
+	}
+	if st.Version != stateVersion {
+		return &Result{}, nil
+	}
+	res := &Result{Head: st.Head}
+	if st.Head == "" {
+		return res, nil
+	}
+
+	var and [][]trigram
+	for _, p := range q.Diff {
+		if ts := queryTrigrams(fieldDiff, p); len(ts) > 0 {
+			and = append(and, ts)
+		}
+	}
+	for _, p := range q.Message {
+		if ts := queryTrigrams(fieldMessage, p); len(ts) > 0 {
+			and = append(and, ts)
+		}
+	}
+	var or [][]trigram
+	for _, p := range q.Author {
+		ts := queryTrigrams(fieldAuthor, p)
+		if len(ts) == 0 {
+			// This author is unconstrained, so all authors are.
+			or = nil
+			break
+		}
+		or = append(or, ts)
+	}
+	if len(and) == 0 && len(or) == 0 {
+		return res, nil
+	}
+
+	for _, info := range st.Segments {
+		commits, err := ix.searchSegment(info.Name, and, or)
+		if err != nil {
+			return nil, errors.Wrapf(err, "searching segment %s", info.Name)
+		}
+		res.Commits = append(res.Commits, commits...)
+		if len(res.Commits) > maxCandidates {
+			res.Commits = nil
+			return res, nil
+		}
+	}
+	res.Filtered = true
+	return res, nil
+}
+
+func (ix *Index) searchSegment(name string, and, or [][]trigram) ([]string, error) {
+	f, err := os.Open(filepath.Join(ix.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := readSegment(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// all returns the commits containing all trigrams of ts.
+	all := func(ts []trigram) ([]uint32, error) {
+		var result []uint32
+		for i, t := range ts {
+			postings, err := s.postings(t)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				result = postings
+			} else {
+				result = intersect(result, postings)
+			}
+			if len(result) == 0 {
+				break
+			}
+		}
+		return result, nil
+	}
+
+	var (
+		ordinals    []uint32
+		constrained bool
+	)
+	for _, ts := range and {
+		matches, err := all(ts)
+		if err != nil {
+			return nil, err
+		}
+		if constrained {
+			ordinals = intersect(ordinals, matches)
+		} else {
+			ordinals, constrained = matches, true
+		}
+	}
+	if len(or) > 0 && (!constrained || len(ordinals) > 0) {
+		var matches []uint32
+		for _, ts := range or {
+			m, err := all(ts)
+			if err != nil {
+				return nil, err
+			}
+			matches = union(matches, m)
+		}
+		if constrained {
+			ordinals = intersect(ordinals, matches)
+		} else {
+			ordinals = matches
+		}
+	}
+
+	truncated, err := s.truncated()
+	if err != nil {
+		return nil, err
+	}
+	ordinals = union(ordinals, truncated)
+
+	commits := make([]string, 0, len(ordinals))
+	for _, o := range ordinals {
+		c, err := s.commit(o)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
@@ -0,0 +1,211 @@
+package commitindex
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+	repo := t.TempDir()
+	run(t, repo, "git init")
+	run(t, repo,
+		"echo 'func Alpha() {}' > a.go",
+		"git add a.go",
+		"git commit -m 'add alpha'",
+	)
+	alpha := run(t, repo, "git rev-parse HEAD")
+	run(t, repo,
+		"echo 'func Beta() {}' >> a.go",
+		"git commit -am 'add beta' --author 'Jane <jane@example.com>'",
+	)
+	beta := run(t, repo, "git rev-parse HEAD")
+
+	ix := New(filepath.Join(t.TempDir(), "index"))
+
+	// Unindexed repositories return no head.
+	res, err := ix.Search(Query{Diff: []string{"Alpha"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Head != "" {
+		t.Fatalf("got head %q for unindexed repository", res.Head)
+	}
+
+	if err := ix.Update(ctx, repo); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(q Query) *Result {
+		t.Helper()
+		res, err := ix.Search(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want *Result
+	}{
+		{
+			name: "diff",
+			q:    Query{Diff: []string{"Alpha"}},
+			want: &Result{Head: beta, Filtered: true, Commits: []string{alpha}},
+		},
+		{
+			name: "diff case insensitive",
+			q:    Query{Diff: []string{"(?i)func beta"}},
+			want: &Result{Head: beta, Filtered: true, Commits: []string{beta}},
+		},
+		{
+			name: "message and diff",
+			q:    Query{Diff: []string{"func"}, Message: []string{"add beta"}},
+			want: &Result{Head: beta, Filtered: true, Commits: []string{beta}},
+		},
+		{
+			name: "message only matches message",
+			q:    Query{Message: []string{"func"}},
+			want: &Result{Head: beta, Filtered: true},
+		},
+		{
+			name: "authors",
+			q:    Query{Author: []string{"jane@", "nobody"}},
+			want: &Result{Head: beta, Filtered: true, Commits: []string{beta}},
+		},
+		{
+			name: "unconstrained author",
+			q:    Query{Diff: []string{"func"}, Author: []string{"jane", "a.*b"}},
+			want: &Result{Head: beta, Filtered: true, Commits: []string{beta, alpha}},
+		},
+		{
+			name: "no trigrams",
+			q:    Query{Diff: []string{"a.b"}},
+			want: &Result{Head: beta},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, search(tc.q)); diff != "" {
+				t.Fatalf("unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// Updates index new commits only.
+	run(t, repo,
+		"echo 'func Gamma() {}' >> a.go",
+		"git commit -am 'add gamma'",
+	)
+	gamma := run(t, repo, "git rev-parse HEAD")
+	if err := ix.Update(ctx, repo); err != nil {
+		t.Fatal(err)
+	}
+	st, err := ix.readState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(st.Segments); got != 2 {
+		t.Fatalf("got %d segments, want 2", got)
+	}
+	want := &Result{Head: gamma, Filtered: true, Commits: []string{gamma, beta, alpha}}
+	if diff := cmp.Diff(want, search(Query{Diff: []string{"func"}})); diff != "" {
+		t.Fatalf("unexpected result (-want +got):\n%s", diff)
+	}
+
+	// Rewriting HEAD rebuilds the index.
+	run(t, repo, "git reset --hard "+alpha)
+	if err := ix.Update(ctx, repo); err != nil {
+		t.Fatal(err)
+	}
+	want = &Result{Head: alpha, Filtered: true, Commits: []string{alpha}}
+	if diff := cmp.Diff(want, search(Query{Diff: []string{"func"}})); diff != "" {
+		t.Fatalf("unexpected result (-want +got):\n%s", diff)
+	}
+	entries, err := os.ReadDir(ix.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(entries); got != 2 {
+		t.Fatalf("got %d files in index, want state and one segment", got)
+	}
+}
+
+func TestIndex_truncatedAndCompacted(t *testing.T) {
+	ctx := context.Background()
+	repo := t.TempDir()
+	run(t, repo, "git init")
+
+	// A commit whose diff is too large to index is returned for every query.
+	run(t, repo,
+		fmt.Sprintf("head -c %d /dev/zero | tr '\\0' 'x' | fold -w 100 > big.txt", maxDiffBytes+1000),
+		"git add big.txt",
+		"git commit -m big",
+	)
+	big := run(t, repo, "git rev-parse HEAD")
+
+	ix := New(filepath.Join(t.TempDir(), "index"))
+	if err := ix.Update(ctx, repo); err != nil {
+		t.Fatal(err)
+	}
+
+	var commits []string
+	for i := 0; i < maxSmallSegments; i++ {
+		run(t, repo, fmt.Sprintf("git commit --allow-empty -m 'commit %d'", i))
+		commits = append([]string{run(t, repo, "git rev-parse HEAD")}, commits...)
+		if err := ix.Update(ctx, repo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	st, err := ix.readState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(st.Segments); got != 1 {
+		t.Fatalf("got %d segments, want 1 after compaction", got)
+	}
+
+	res, err := ix.Search(Query{Message: []string{"commit"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Result{Head: commits[0], Filtered: true, Commits: append(commits, big)}
+	if diff := cmp.Diff(want, res); diff != "" {
+		t.Fatalf("unexpected result (-want +got):\n%s", diff)
+	}
+}
+
+// run runs the shell commands in dir and returns the trimmed output of the
+// last one.
+func run(t *testing.T, dir string, cmds ...string) string {
+	t.Helper()
+	var out []byte
+	for _, c := range cmds {
+		cmd := exec.Command("bash", "-c", c)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_CONFIG_NOSYSTEM=1",
+			"HOME=/dev/null",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+			"GIT_COMMITTER_NAME=a",
+			"GIT_COMMITTER_EMAIL=a@a.com",
+		)
+		var err error
+		out, err = cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("Command %q failed. Output was:\n\n%s", c, out)
+		}
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,132 @@
+package commitindex
+
+import (
+	"regexp/syntax"
+	"strings"
+)
+
+// Query describes the commits to find in an index. All patterns are regular
+// expressions in the syntax of the Go regexp package, so patterns that git
+// reads as basic regular expressions must be translated first. The index only
+// returns candidates: every commit matching the query is returned, but not
+// every returned commit matches the query.
+type Query struct {
+	// Diff patterns must all match an added or removed line of the diff.
+	Diff []string
+
+	// Message patterns must all match the commit message.
+	Message []string
+
+	// Author patterns match the author ("name <email>"). At least one must
+	// match, like multiple --author flags of git log.
+	Author []string
+}
+
+// field namespaces trigrams so that a trigram of the message doesn't match the
+// same trigram in the diff.
+type field uint32
+
+const (
+	fieldDiff field = iota + 1
+	fieldMessage
+	fieldAuthor
+)
+
+// trigram is a field and three lowercased bytes packed into an uint32.
+type trigram uint32
+
+func newTrigram(f field, a, b, c byte) trigram {
+	return trigram(uint32(f)<<24 | uint32(lower(a))<<16 | uint32(lower(b))<<8 | uint32(lower(c)))
+}
+
+func lower(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+// trigrams calls fn for each trigram of text. Trigrams spanning lines are
+// skipped since patterns are matched line by line.
+func trigrams(f field, text string, fn func(trigram)) {
+	for i := 0; i+3 <= len(text); i++ {
+		a, b, c := text[i], text[i+1], text[i+2]
+		if a == '\n' || b == '\n' || c == '\n' {
+			continue
+		}
+		fn(newTrigram(f, a, b, c))
+	}
+}
+
+// queryTrigrams returns the trigrams that every text matching pattern must
+// contain. It returns nil if the pattern can't be filtered by trigrams.
+func queryTrigrams(f field, pattern string) []trigram {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	var result []trigram
+	seen := map[trigram]struct{}{}
+	for _, s := range requiredStrings(re.Simplify()) {
+		for _, line := range strings.Split(s, "\n") {
+			trigrams(f, line, func(t trigram) {
+				if !isASCII(t) {
+					// The index lowercases ASCII only, so case-insensitive
+					// patterns with other characters may not match.
+					return
+				}
+				if _, ok := seen[t]; !ok {
+					seen[t] = struct{}{}
+					result = append(result, t)
+				}
+			})
+		}
+	}
+	return result
+}
+
+func isASCII(t trigram) bool {
+	return t&0x808080 == 0
+}
+
+// requiredStrings returns strings that must occur in any text matching re.
+func requiredStrings(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredStrings(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return nil
+		}
+		return requiredStrings(re.Sub[0])
+
+	case syntax.OpConcat:
+		var (
+			result []string
+			run    strings.Builder
+		)
+		flush := func() {
+			if run.Len() > 0 {
+				result = append(result, run.String())
+				run.Reset()
+			}
+		}
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run.WriteString(string(sub.Rune))
+				continue
+			}
+			flush()
+			result = append(result, requiredStrings(sub)...)
+		}
+		flush()
+		return result
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package commitindex
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQueryTrigrams(t *testing.T) {
+	str := func(ts []trigram) []string {
+		var s []string
+		for _, t := range ts {
+			s = append(s, string([]byte{byte(t >> 16), byte(t >> 8), byte(t)}))
+		}
+		return s
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "foo", want: []string{"foo"}},
+		{pattern: "FooBar", want: []string{"foo", "oob", "oba", "bar"}},
+		{pattern: `(?i)foo`, want: []string{"foo"}},
+		{pattern: `foo\.bar`, want: []string{"foo", "oo.", "o.b", ".ba", "bar"}},
+		{pattern: "foo.*bar", want: []string{"foo", "bar"}},
+		{pattern: "(foo)+x", want: []string{"foo"}},
+		{pattern: "(foo){2}", want: []string{"foo"}},
+		{pattern: "(foo){0,2}bar", want: []string{"bar"}},
+		{pattern: "foo|bar", want: nil},
+		{pattern: "(foo)?bar", want: []string{"bar"}},
+		{pattern: "fo", want: nil},
+		{pattern: "a.b", want: nil},
+		{pattern: `foo\nbar`, want: []string{"foo", "bar"}},
+		{pattern: "öl", want: nil},
+		{pattern: "(", want: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			got := str(queryTrigrams(fieldDiff, tc.pattern))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Fatalf("unexpected trigrams (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package commitindex
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+)
+
+// A segment is an immutable file indexing a range of commits. Its layout is
+//
+//	magic "SGCI", version, #commits, #truncated, #trigrams (uint32 each)
+//	commit IDs (40 bytes each, in git log order)
+//	ordinals of truncated commits (uint32 each)
+//	trigram table, sorted by trigram (trigram, offset, count as uint32)
+//	postings, the ascending commit ordinals of each trigram (uint32 each)
+//
+// All integers are little endian.
+const (
+	segmentMagic   = "SGCI"
+	segmentVersion = 1
+	headerSize     = 20
+	commitSize     = 40
+	entrySize      = 12
+)
+
+// segmentBuilder accumulates commits in memory until they are written to a
+// segment.
+type segmentBuilder struct {
+	commits   []string
+	truncated []uint32
+	postings  map[trigram][]uint32
+	size      int // number of postings
+}
+
+func newSegmentBuilder() *segmentBuilder {
+	return &segmentBuilder{postings: map[trigram][]uint32{}}
+}
+
+// add adds a commit with the given trigrams. Truncated commits are commits
+// whose diff was too large to index, they are returned for every query.
+func (b *segmentBuilder) add(commit string, trigrams map[trigram]struct{}, truncated bool) error {
+	if len(commit) != commitSize {
+		return errors.Errorf("unsupported commit ID %q", commit)
+	}
+	ordinal := uint32(len(b.commits))
+	b.commits = append(b.commits, commit)
+	if truncated {
+		b.truncated = append(b.truncated, ordinal)
+	}
+	for t := range trigrams {
+		b.postings[t] = append(b.postings[t], ordinal)
+	}
+	b.size += len(trigrams)
+	return nil
+}
+
+// write writes the segment to path.
+func (b *segmentBuilder) write(path string) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	keys := make([]trigram, 0, len(b.postings))
+	for t := range b.postings {
+		keys = append(keys, t)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	w := bufio.NewWriter(f)
+	put := func(vs ...uint32) {
+		var buf [4]byte
+		for _, v := range vs {
+			binary.LittleEndian.PutUint32(buf[:], v)
+			_, _ = w.Write(buf[:])
+		}
+	}
+
+	_, _ = w.WriteString(segmentMagic)
+	put(segmentVersion, uint32(len(b.commits)), uint32(len(b.truncated)), uint32(len(keys)))
+	for _, c := range b.commits {
+		_, _ = w.WriteString(c)
+	}
+	put(b.truncated...)
+	var offset uint32
+	for _, t := range keys {
+		count := uint32(len(b.postings[t]))
+		put(uint32(t), offset, count)
+		offset += count
+	}
+	for _, t := range keys {
+		put(b.postings[t]...)
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// segment reads a segment file.
+type segment struct {
+	r            io.ReaderAt
+	numCommits   uint32
+	numTruncated uint32
+	numTrigrams  uint32
+}
+
+func readSegment(r io.ReaderAt) (*segment, error) {
+	var header [headerSize]byte
+	if _, err := r.ReadAt(header[:], 0); err != nil {
+		return nil, errors.Wrap(err, "reading segment header")
+	}
+	if string(header[:4]) != segmentMagic {
+		return nil, errors.New("invalid segment")
+	}
+	if v := binary.LittleEndian.Uint32(header[4:]); v != segmentVersion {
+		return nil, errors.Errorf("unsupported segment version %d", v)
+	}
+	return &segment{
+		r:            r,
+		numCommits:   binary.LittleEndian.Uint32(header[8:]),
+		numTruncated: binary.LittleEndian.Uint32(header[12:]),
+		numTrigrams:  binary.LittleEndian.Uint32(header[16:]),
+	}, nil
+}
+
+func (s *segment) truncatedOffset() int64 {
+	return headerSize + int64(s.numCommits)*commitSize
+}
+
+func (s *segment) tableOffset() int64 {
+	return s.truncatedOffset() + int64(s.numTruncated)*4
+}
+
+func (s *segment) postingsOffset() int64 {
+	return s.tableOffset() + int64(s.numTrigrams)*entrySize
+}
+
+func (s *segment) readUint32s(offset int64, n uint32) ([]uint32, error) {
+	buf := make([]byte, int(n)*4)
+	if _, err := s.r.ReadAt(buf, offset); err != nil {
+		return nil, err
+	}
+	vs := make([]uint32, n)
+	for i := range vs {
+		vs[i] = binary.LittleEndian.Uint32(buf[i*4:])
+	}
+	return vs, nil
+}
+
+// commit returns the ID of the commit with the given ordinal.
+func (s *segment) commit(ordinal uint32) (string, error) {
+	buf := make([]byte, commitSize)
+	if _, err := s.r.ReadAt(buf, headerSize+int64(ordinal)*commitSize); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func (s *segment) truncated() ([]uint32, error) {
+	return s.readUint32s(s.truncatedOffset(), s.numTruncated)
+}
+
+// postings returns the ordinals of the commits containing t.
+func (s *segment) postings(t trigram) ([]uint32, error) {
+	var (
+		err   error
+		entry []uint32
+	)
+	i := sort.Search(int(s.numTrigrams), func(i int) bool {
+		if err != nil {
+			return true
+		}
+		entry, err = s.readUint32s(s.tableOffset()+int64(i)*entrySize, 3)
+		return err != nil || trigram(entry[0]) >= t
+	})
+	if err != nil {
+		return nil, err
+	}
+	if i == int(s.numTrigrams) {
+		return nil, nil
+	}
+	if entry, err = s.readUint32s(s.tableOffset()+int64(i)*entrySize, 3); err != nil {
+		return nil, err
+	}
+	if trigram(entry[0]) != t {
+		return nil, nil
+	}
+	return s.readUint32s(s.postingsOffset()+int64(entry[1])*4, entry[2])
+}
+
+// all returns the trigrams of the segment and their postings. It is used to
+// merge segments.
+func (s *segment) all(fn func(trigram, []uint32)) error {
+	table, err := s.readUint32s(s.tableOffset(), s.numTrigrams*3)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(table); i += 3 {
+		postings, err := s.readUint32s(s.postingsOffset()+int64(table[i+1])*4, table[i+2])
+		if err != nil {
+			return err
+		}
+		fn(trigram(table[i]), postings)
+	}
+	return nil
+}
+
+// intersect returns the ordinals in both a and b, which must be sorted.
+func intersect(a, b []uint32) []uint32 {
+	var result []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// union returns the ordinals in a or b, which must be sorted.
+func union(a, b []uint32) []uint32 {
+	result := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			result = append(result, a[i])
+			i++
+		case a[i] > b[j]:
+			result = append(result, b[j])
+			j++
+		default:
+			result = append(result, a[i])
+			i++
+			j++
+		}
+	}
+	result = append(result, a[i:]...)
+	return append(result, b[j:]...)
+}
@@ -0,0 +1,344 @@
+package commitindex
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+const (
+	stateVersion = 1
+
+	// maxDiffBytes is the maximum number of diff bytes indexed per commit.
+	// Commits with larger diffs are marked as truncated.
+	maxDiffBytes = 1 << 20
+
+	// maxSegmentCommits and maxSegmentPostings bound the size of the segments
+	// written by an update, and thereby its memory usage.
+	maxSegmentCommits  = 10000
+	maxSegmentPostings = 20000000
+
+	// Updates usually add a small segment. If there are more than
+	// maxSmallSegments segments with less than smallSegmentCommits commits at
+	// the front of the index they are merged.
+	maxSmallSegments    = 16
+	smallSegmentCommits = 1000
+
+	// logFormat separates the header of each commit from its diff.
+	// %x1e starts a commit, %x1f ends its header.
+	logFormat = "%x1e%H%x00%an <%ae>%x00%B%x1f"
+)
+
+// Update indexes the commits added to HEAD of the git repository gitDir since
+// the last update. If HEAD was rewritten the index is rebuilt. Updates of the
+// same index must not run concurrently, but searches can run while the index
+// is updated.
+func (ix *Index) Update(ctx context.Context, gitDir string) error {
+	head, err := git(ctx, gitDir, "rev-parse", "--verify", "--quiet", "HEAD^{commit}")
+	if err != nil {
+		// Empty repository, nothing to index.
+		return nil
+	}
+	head = strings.TrimSpace(head)
+
+	if err := os.MkdirAll(ix.dir, 0700); err != nil {
+		return err
+	}
+	st, err := ix.readState()
+	if err != nil || st.Version != stateVersion {
+		st = &state{Version: stateVersion}
+	}
+	if st.Head == head {
+		return nil
+	}
+	if err := ix.removeOrphans(st); err != nil {
+		return err
+	}
+
+	args := []string{"log", "--no-merges", "--no-color", "--no-prefix", "--no-renames", "-p", "--unified=0", "--format=" + logFormat, head}
+	if st.Head != "" {
+		if _, err := git(ctx, gitDir, "merge-base", "--is-ancestor", st.Head, head); err == nil {
+			args = append(args, "^"+st.Head)
+		} else {
+			// HEAD was rewritten, or the old head was garbage collected.
+			st = &state{Version: stateVersion}
+		}
+	}
+	args = append(args, "--")
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = gitDir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	var segments []segmentInfo
+	b := newSegmentBuilder()
+	flush := func() error {
+		if len(b.commits) == 0 {
+			return nil
+		}
+		info := segmentInfo{Name: st.nextSegmentName(), Commits: len(b.commits)}
+		if err := b.write(filepath.Join(ix.dir, info.Name)); err != nil {
+			return err
+		}
+		segments = append(segments, info)
+		b = newSegmentBuilder()
+		return nil
+	}
+
+	err = parseLog(stdout, func(c *commit) error {
+		if err := b.add(c.id, c.trigrams, c.truncated); err != nil {
+			return err
+		}
+		if len(b.commits) >= maxSegmentCommits || b.size >= maxSegmentPostings {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+	if err := cmd.Wait(); err != nil {
+		return errors.Wrapf(err, "git log: %s", stderr.String())
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	st.Head = head
+	st.Segments = append(segments, st.Segments...)
+	if err := ix.compact(st); err != nil {
+		return err
+	}
+	if err := ix.writeState(st); err != nil {
+		return err
+	}
+	return ix.removeOrphans(st)
+}
+
+func (st *state) nextSegmentName() string {
+	st.Next++
+	return fmt.Sprintf("%d.seg", st.Next)
+}
+
+// removeOrphans removes the segments not referenced by st, which are left
+// behind by failed updates and compactions.
+func (ix *Index) removeOrphans(st *state) error {
+	entries, err := os.ReadDir(ix.dir)
+	if err != nil {
+		return err
+	}
+	referenced := map[string]bool{stateFile: true}
+	for _, info := range st.Segments {
+		referenced[info.Name] = true
+	}
+	for _, e := range entries {
+		if !referenced[e.Name()] {
+			if err := os.Remove(filepath.Join(ix.dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// compact merges the small segments at the front of the index.
+func (ix *Index) compact(st *state) error {
+	n := 0
+	for n < len(st.Segments) && st.Segments[n].Commits < smallSegmentCommits {
+		n++
+	}
+	if n <= maxSmallSegments {
+		return nil
+	}
+
+	b := newSegmentBuilder()
+	for _, info := range st.Segments[:n] {
+		if err := ix.mergeInto(b, info.Name); err != nil {
+			return errors.Wrapf(err, "merging segment %s", info.Name)
+		}
+	}
+	merged := segmentInfo{Name: st.nextSegmentName(), Commits: len(b.commits)}
+	if err := b.write(filepath.Join(ix.dir, merged.Name)); err != nil {
+		return err
+	}
+	st.Segments = append([]segmentInfo{merged}, st.Segments[n:]...)
+	return nil
+}
+
+func (ix *Index) mergeInto(b *segmentBuilder, name string) error {
+	f, err := os.Open(filepath.Join(ix.dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	s, err := readSegment(f)
+	if err != nil {
+		return err
+	}
+
+	offset := uint32(len(b.commits))
+	for i := uint32(0); i < s.numCommits; i++ {
+		c, err := s.commit(i)
+		if err != nil {
+			return err
+		}
+		b.commits = append(b.commits, c)
+	}
+	truncated, err := s.truncated()
+	if err != nil {
+		return err
+	}
+	for _, o := range truncated {
+		b.truncated = append(b.truncated, o+offset)
+	}
+	return s.all(func(t trigram, postings []uint32) {
+		for _, o := range postings {
+			b.postings[t] = append(b.postings[t], o+offset)
+		}
+		b.size += len(postings)
+	})
+}
+
+// commit is a commit parsed from git log.
+type commit struct {
+	id        string
+	trigrams  map[trigram]struct{}
+	truncated bool
+	diffBytes int
+}
+
+// parseLog parses the output of git log with logFormat and a patch, and calls
+// fn for each commit. The commit passed to fn is reused.
+func parseLog(r io.Reader, fn func(*commit) error) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	c := &commit{trigrams: map[trigram]struct{}{}}
+	var (
+		inHeader, inHunk bool
+		header           bytes.Buffer
+	)
+
+	emit := func() error {
+		if c.id == "" {
+			return nil
+		}
+		err := fn(c)
+		c.id, c.truncated, c.diffBytes = "", false, 0
+		for t := range c.trigrams {
+			delete(c.trigrams, t)
+		}
+		return err
+	}
+
+	for {
+		line, err := readLine(br)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if len(line) > 0 && line[0] == '\x1e' {
+			if err := emit(); err != nil {
+				return err
+			}
+			inHeader, inHunk = true, false
+			header.Reset()
+			line = line[1:]
+		}
+
+		if inHeader {
+			end := bytes.IndexByte(line, '\x1f')
+			if end < 0 {
+				header.Write(line)
+				header.WriteByte('\n')
+				continue
+			}
+			header.Write(line[:end])
+			inHeader = false
+			if err := c.parseHeader(header.String()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch {
+		case bytes.HasPrefix(line, []byte("diff --git ")):
+			inHunk = false
+		case bytes.HasPrefix(line, []byte("@@")):
+			inHunk = true
+		case inHunk && len(line) > 0 && (line[0] == '+' || line[0] == '-'):
+			if c.truncated {
+				continue
+			}
+			c.diffBytes += len(line)
+			if c.diffBytes > maxDiffBytes {
+				c.truncated = true
+				continue
+			}
+			c.add(fieldDiff, string(line[1:]))
+		}
+	}
+	return emit()
+}
+
+func (c *commit) parseHeader(header string) error {
+	parts := strings.SplitN(header, "\x00", 3)
+	if len(parts) != 3 {
+		return errors.Errorf("invalid commit header %q", header)
+	}
+	c.id = parts[0]
+	c.add(fieldAuthor, parts[1])
+	c.add(fieldMessage, parts[2])
+	return nil
+}
+
+func (c *commit) add(f field, text string) {
+	trigrams(f, text, func(t trigram) {
+		c.trigrams[t] = struct{}{}
+	})
+}
+
+// readLine returns the next line without its line terminator. Only the first
+// 64 KiB of long lines are returned.
+func readLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		// Copy since ReadSlice overwrites the buffer, then skip the rest.
+		line = append([]byte(nil), line...)
+		for err == bufio.ErrBufferFull {
+			_, err = br.ReadSlice('\n')
+		}
+	}
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(line, []byte("\n")), nil
+}
+
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return string(out), err
+}
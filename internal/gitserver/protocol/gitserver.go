@@ -134,6 +134,37 @@ type RepoInfoResponse struct {
 	Results map[api.RepoName]*RepoInfo
 }
 
+// CommitIndexSearchRequest is a request to find the commits on the default
+// branch of a repository that may match the given patterns, using the commit
+// index of gitserver. Patterns are regular expressions.
+type CommitIndexSearchRequest struct {
+	Repo api.RepoName
+
+	// Diff and Message patterns must all match, like the -G and --grep
+	// --all-match flags of git log.
+	Diff    []string
+	Message []string
+
+	// Author patterns match "name <email>". At least one must match, like
+	// multiple --author flags of git log.
+	Author []string
+}
+
+// CommitIndexSearchResponse is the response to a CommitIndexSearchRequest.
+type CommitIndexSearchResponse struct {
+	// Indexed is true if the index is up to date with HEAD of the repository.
+	Indexed bool
+
+	// Filtered is true if Commits are the only commits that can match the
+	// request. If false the index couldn't narrow down the commits, and all
+	// commits need to be searched.
+	Filtered bool
+
+	// Commits are the candidate commits, newest first. Every matching commit
+	// is included, but not every commit matches.
+	Commits []api.CommitID
+}
+
 // ReposStats is an aggregation of statistics from a gitserver.
 type ReposStats struct {
 	// UpdatedAt is the time these statistics were computed. If UpdateAt is
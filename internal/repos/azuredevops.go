@@ -0,0 +1,265 @@
+package repos
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/azuredevops"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// defaultAzureDevOpsUsername is the username sent along with the personal access token
+// in HTTP clone URLs. Azure DevOps ignores it, but git requires one to be set.
+const defaultAzureDevOpsUsername = "sourcegraph"
+
+// An AzureDevOpsSource yields repositories from a single Azure DevOps connection
+// configured in Sourcegraph via the external services configuration.
+type AzureDevOpsSource struct {
+	svc     *types.ExternalService
+	config  *schema.AzureDevOpsConnection
+	baseURL *url.URL
+	exclude excludeFunc
+	client  *azuredevops.Client
+
+	// perPage is the number of projects requested per page when listing the projects of
+	// an organization.
+	perPage int
+}
+
+// NewAzureDevOpsSource returns a new AzureDevOpsSource from the given external service.
+func NewAzureDevOpsSource(svc *types.ExternalService, cf *httpcli.Factory) (*AzureDevOpsSource, error) {
+	var c schema.AzureDevOpsConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return newAzureDevOpsSource(svc, &c, cf)
+}
+
+func newAzureDevOpsSource(svc *types.ExternalService, c *schema.AzureDevOpsConnection, cf *httpcli.Factory) (*AzureDevOpsSource, error) {
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, err
+	}
+	baseURL = extsvc.NormalizeBaseURL(baseURL)
+
+	if cf == nil {
+		cf = httpcli.NewExternalHTTPClientFactory()
+	}
+
+	cli, err := cf.Doer()
+	if err != nil {
+		return nil, err
+	}
+
+	var eb excludeBuilder
+	for _, r := range c.Exclude {
+		eb.Exact(r.Name)
+		eb.Exact(r.Id)
+		eb.Pattern(r.Pattern)
+	}
+	exclude, err := eb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	client := azuredevops.NewClient(baseURL, cli)
+	client.Token = c.Token
+
+	return &AzureDevOpsSource{
+		svc:     svc,
+		config:  c,
+		baseURL: baseURL,
+		exclude: exclude,
+		client:  client,
+		perPage: 100,
+	}, nil
+}
+
+// ListRepos returns all Azure DevOps repositories accessible to all connections
+// configured in Sourcegraph via the external services configuration.
+func (s AzureDevOpsSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	seen := make(map[string]bool)
+	s.listConfiguredRepos(ctx, seen, results)
+	s.listConfiguredProjects(ctx, seen, results)
+	s.listConfiguredOrgs(ctx, seen, results)
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s AzureDevOpsSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
+
+func (s *AzureDevOpsSource) listConfiguredRepos(ctx context.Context, seen map[string]bool, results chan SourceResult) {
+	for _, name := range s.config.Repos {
+		parts := strings.SplitN(name, "/", 3)
+		if len(parts) != 3 {
+			results <- SourceResult{Source: s, Err: errors.Errorf("azuredevops.repos: invalid name %q, expected org/project/repo", name)}
+			continue
+		}
+
+		repo, err := s.client.GetRepository(ctx, parts[0], parts[1], parts[2])
+		if err != nil {
+			if azuredevops.IsNotFound(err) {
+				log15.Warn("skipping missing azuredevops.repos entry:", "name", name, "err", err)
+				continue
+			}
+
+			results <- SourceResult{Source: s, Err: errors.Wrapf(err, "azuredevops.repos: name=%q", name)}
+			continue
+		}
+
+		s.emit(parts[0], []*azuredevops.Repository{repo}, seen, results)
+	}
+}
+
+func (s *AzureDevOpsSource) listConfiguredProjects(ctx context.Context, seen map[string]bool, results chan SourceResult) {
+	for _, name := range s.config.Projects {
+		parts := strings.SplitN(name, "/", 2)
+		if len(parts) != 2 {
+			results <- SourceResult{Source: s, Err: errors.Errorf("azuredevops.projects: invalid name %q, expected org/project", name)}
+			continue
+		}
+
+		repos, err := s.client.ListRepositories(ctx, parts[0], parts[1])
+		if err != nil {
+			if azuredevops.IsNotFound(err) {
+				log15.Warn("skipping missing azuredevops.projects entry:", "name", name, "err", err)
+				continue
+			}
+
+			results <- SourceResult{Source: s, Err: errors.Wrapf(err, "azuredevops.projects: name=%q", name)}
+			continue
+		}
+
+		s.emit(parts[0], repos, seen, results)
+	}
+}
+
+func (s *AzureDevOpsSource) listConfiguredOrgs(ctx context.Context, seen map[string]bool, results chan SourceResult) {
+	for _, org := range s.config.Orgs {
+		args := azuredevops.ListProjectsArgs{Org: org, PerPage: s.perPage}
+		for {
+			projects, next, err := s.client.ListProjects(ctx, args)
+			if err != nil {
+				if azuredevops.IsNotFound(err) {
+					log15.Warn("skipping missing azuredevops.orgs entry:", "name", org, "err", err)
+				} else {
+					results <- SourceResult{Source: s, Err: errors.Wrapf(err, "azuredevops.orgs: name=%q", org)}
+				}
+				break
+			}
+
+			for _, p := range projects {
+				repos, err := s.client.ListRepositories(ctx, org, p.Name)
+				if err != nil {
+					results <- SourceResult{Source: s, Err: errors.Wrapf(err, "azuredevops.orgs: name=%q project=%q", org, p.Name)}
+					continue
+				}
+				s.emit(org, repos, seen, results)
+			}
+
+			if next == "" {
+				break
+			}
+			args.ContinuationToken = next
+		}
+	}
+}
+
+// emit sends the given repositories of the given organization that have not been seen
+// before and are not excluded to results.
+func (s *AzureDevOpsSource) emit(org string, repos []*azuredevops.Repository, seen map[string]bool, results chan SourceResult) {
+	for _, r := range repos {
+		if !seen[r.ID] && !s.excludes(org, r) {
+			results <- SourceResult{Source: s, Repo: s.makeRepo(org, r)}
+			seen[r.ID] = true
+		}
+	}
+}
+
+func (s *AzureDevOpsSource) excludes(org string, r *azuredevops.Repository) bool {
+	return s.exclude(org+"/"+r.Project.Name+"/"+r.Name) || s.exclude(r.ID)
+}
+
+func (s *AzureDevOpsSource) makeRepo(org string, r *azuredevops.Repository) *types.Repo {
+	urn := s.svc.URN()
+	return &types.Repo{
+		Name: reposource.AzureDevOpsRepoName(
+			s.config.RepositoryPathPattern,
+			s.baseURL.Hostname(),
+			org,
+			r.Project.Name,
+			r.Name,
+		),
+		URI: string(reposource.AzureDevOpsRepoName(
+			"",
+			s.baseURL.Hostname(),
+			org,
+			r.Project.Name,
+			r.Name,
+		)),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          r.ID,
+			ServiceType: extsvc.TypeAzureDevOps,
+			ServiceID:   s.baseURL.String(),
+		},
+		// Azure DevOps repositories have no description of their own, so we use the
+		// description of the project they belong to.
+		Description: r.Project.Description,
+		Fork:        r.IsFork,
+		// Disabling a repository is how Azure DevOps archives it.
+		Archived: r.IsDisabled,
+		Private:  r.Project.Visibility != azuredevops.VisibilityPublic,
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: s.remoteURL(r),
+			},
+		},
+		Metadata: r,
+	}
+}
+
+// remoteURL returns the repository's Git remote URL
+//
+// note: this does not contain credentials
+// if you need to get an authenticated clone url use repos.CloneURL
+func (s *AzureDevOpsSource) remoteURL(r *azuredevops.Repository) string {
+	return azureDevOpsRemoteURL(r, s.config, false)
+}
+
+// azureDevOpsRemoteURL returns the Git remote URL of the given repository. If
+// authenticated is true, HTTP remote URLs contain the configured personal access token.
+func azureDevOpsRemoteURL(r *azuredevops.Repository, cfg *schema.AzureDevOpsConnection, authenticated bool) string {
+	if cfg.GitURLType == "ssh" {
+		return r.SSHURL
+	}
+
+	u, err := url.Parse(r.RemoteURL)
+	if err != nil {
+		log15.Warn("Invalid Azure DevOps remote URL.", "url", r.RemoteURL, "error", err)
+		return ""
+	}
+
+	// Azure DevOps includes the organization name as the username of remote URLs, which
+	// we never want to use.
+	u.User = nil
+	if authenticated && cfg.Token != "" {
+		username := cfg.Username
+		if username == "" {
+			username = defaultAzureDevOpsUsername
+		}
+		u.User = url.UserPassword(username, cfg.Token)
+	}
+	return u.String()
+}
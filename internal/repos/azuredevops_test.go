@@ -0,0 +1,181 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/azuredevops"
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestAzureDevOpsSource_ListRepos(t *testing.T) {
+	assertAllReposListed := func(want []string) types.ReposAssertion {
+		return func(t testing.TB, rs types.Repos) {
+			t.Helper()
+
+			have := rs.Names()
+			sort.Strings(have)
+			sort.Strings(want)
+
+			if diff := cmp.Diff(want, have); diff != "" {
+				t.Errorf("Mismatch (-want +got):\n%s", diff)
+			}
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		assert types.ReposAssertion
+		conf   *schema.AzureDevOpsConnection
+		err    string
+	}{
+		{
+			name: "orgs",
+			assert: assertAllReposListed([]string{
+				"dev.azure.com/sgtest/Data-Science/ML-Models",
+				"dev.azure.com/sgtest/sandbox/playground",
+				"dev.azure.com/sgtest/sourcegraph/jsonrpc2",
+				"dev.azure.com/sgtest/sourcegraph/src-cli",
+			}),
+			conf: &schema.AzureDevOpsConnection{
+				Url:   "https://dev.azure.com",
+				Token: os.Getenv("AZURE_DEVOPS_TOKEN"),
+				Orgs:  []string{"sgtest"},
+			},
+			err: "<nil>",
+		},
+		{
+			name: "exclude",
+			assert: assertAllReposListed([]string{
+				"dev.azure.com/sgtest/sourcegraph/src-cli",
+			}),
+			conf: &schema.AzureDevOpsConnection{
+				Url:   "https://dev.azure.com",
+				Token: os.Getenv("AZURE_DEVOPS_TOKEN"),
+				Orgs:  []string{"sgtest"},
+				Exclude: []*schema.ExcludedAzureDevOpsRepo{
+					{Name: "sgtest/sandbox/playground"},
+					{Id: "c4e1e1b8-0c57-4f0a-8e2e-5d3c8f1c1a02"},
+					{Pattern: "^sgtest/Data Science/"},
+				},
+			},
+			err: "<nil>",
+		},
+		{
+			name: "projects-and-repos",
+			assert: assertAllReposListed([]string{
+				"dev.azure.com/sgtest/sourcegraph/jsonrpc2",
+				"dev.azure.com/sgtest/sourcegraph/src-cli",
+			}),
+			conf: &schema.AzureDevOpsConnection{
+				Url:   "https://dev.azure.com",
+				Token: os.Getenv("AZURE_DEVOPS_TOKEN"),
+				Repos: []string{
+					"sgtest/sourcegraph/src-cli",
+					"sgtest/sourcegraph/does-not-exist",
+				},
+				Projects: []string{
+					"sgtest/sourcegraph",
+					"sgtest/does-not-exist",
+				},
+			},
+			err: "<nil>",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		tc.name = "AZUREDEVOPS-LIST-REPOS/" + tc.name
+		t.Run(tc.name, func(t *testing.T) {
+			cf, save := newClientFactory(t, tc.name)
+			defer save(t)
+
+			svc := &types.ExternalService{
+				Kind:   extsvc.KindAzureDevOps,
+				Config: marshalJSON(t, tc.conf),
+			}
+
+			adoSrc, err := newAzureDevOpsSource(svc, tc.conf, cf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			adoSrc.perPage = 2
+
+			repos, err := listAll(context.Background(), adoSrc)
+
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			if tc.assert != nil {
+				tc.assert(t, repos)
+			}
+		})
+	}
+}
+
+func TestAzureDevOpsSource_makeRepo(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "azuredevops-repos.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var repos []*azuredevops.Repository
+	if err := json.Unmarshal(b, &repos); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := types.ExternalService{ID: 1, Kind: extsvc.KindAzureDevOps}
+
+	tests := []struct {
+		name   string
+		schema *schema.AzureDevOpsConnection
+	}{
+		{
+			name: "simple",
+			schema: &schema.AzureDevOpsConnection{
+				Url:   "https://dev.azure.com",
+				Token: "secret",
+			},
+		}, {
+			name: "ssh",
+			schema: &schema.AzureDevOpsConnection{
+				Url:        "https://dev.azure.com",
+				Token:      "secret",
+				GitURLType: "ssh",
+			},
+		}, {
+			name: "path-pattern",
+			schema: &schema.AzureDevOpsConnection{
+				Url:                   "https://dev.azure.com",
+				Token:                 "secret",
+				RepositoryPathPattern: "ado/{project}/{repo}",
+			},
+		},
+	}
+	for _, test := range tests {
+		test.name = "AzureDevOpsSource_makeRepo_" + test.name
+		t.Run(test.name, func(t *testing.T) {
+			s, err := newAzureDevOpsSource(&svc, test.schema, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*types.Repo
+			for _, r := range repos {
+				got = append(got, s.makeRepo("sgtest", r))
+			}
+
+			testutil.AssertGolden(t, "testdata/golden/"+test.name, update(test.name), got)
+		})
+	}
+}
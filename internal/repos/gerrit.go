@@ -0,0 +1,231 @@
+package repos
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gerrit"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// defaultGerritSSHPort is the default port of the Gerrit SSH daemon.
+const defaultGerritSSHPort = 29418
+
+// A GerritSource yields repositories from a single Gerrit connection configured
+// in Sourcegraph via the external services configuration.
+type GerritSource struct {
+	svc     *types.ExternalService
+	config  *schema.GerritConnection
+	baseURL *url.URL
+	exclude excludeFunc
+	client  *gerrit.Client
+
+	// perPage is the number of projects requested per page when listing all projects.
+	perPage int
+}
+
+// NewGerritSource returns a new GerritSource from the given external service.
+func NewGerritSource(svc *types.ExternalService, cf *httpcli.Factory) (*GerritSource, error) {
+	var c schema.GerritConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return newGerritSource(svc, &c, cf)
+}
+
+func newGerritSource(svc *types.ExternalService, c *schema.GerritConnection, cf *httpcli.Factory) (*GerritSource, error) {
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, err
+	}
+	baseURL = extsvc.NormalizeBaseURL(baseURL)
+
+	if cf == nil {
+		cf = httpcli.NewExternalHTTPClientFactory()
+	}
+
+	cli, err := cf.Doer()
+	if err != nil {
+		return nil, err
+	}
+
+	var eb excludeBuilder
+	for _, r := range c.Exclude {
+		eb.Exact(r.Name)
+		eb.Pattern(r.Pattern)
+	}
+	exclude, err := eb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	client := gerrit.NewClient(baseURL, cli)
+	client.Username = c.Username
+	client.Password = c.Password
+
+	return &GerritSource{
+		svc:     svc,
+		config:  c,
+		baseURL: baseURL,
+		exclude: exclude,
+		client:  client,
+		perPage: 100,
+	}, nil
+}
+
+// ListRepos returns all Gerrit projects accessible to all connections configured
+// in Sourcegraph via the external services configuration.
+func (s GerritSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	if len(s.config.Projects) > 0 {
+		s.listConfiguredProjects(ctx, results)
+		return
+	}
+	s.listAllProjects(ctx, results)
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s GerritSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
+
+func (s *GerritSource) listConfiguredProjects(ctx context.Context, results chan SourceResult) {
+	seen := make(map[string]bool)
+	for _, name := range s.config.Projects {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		project, err := s.client.GetProject(ctx, name)
+		if err != nil {
+			if gerrit.IsNotFound(err) {
+				log15.Warn("skipping missing gerrit.projects entry:", "name", name, "err", err)
+				continue
+			}
+
+			results <- SourceResult{Source: s, Err: errors.Wrapf(err, "gerrit.projects: name=%q", name)}
+			continue
+		}
+
+		if !s.excludes(project) {
+			results <- SourceResult{Source: s, Repo: s.makeRepo(project)}
+		}
+	}
+}
+
+func (s *GerritSource) listAllProjects(ctx context.Context, results chan SourceResult) {
+	seen := make(map[string]bool)
+	for page := (&gerrit.Pagination{PerPage: s.perPage}); page != nil; {
+		var (
+			projects []*gerrit.Project
+			err      error
+		)
+		if projects, page, err = s.client.ListProjects(ctx, page); err != nil {
+			results <- SourceResult{Source: s, Err: errors.Wrap(err, "gerrit.listProjects")}
+			return
+		}
+
+		for _, project := range projects {
+			// Hidden projects are only visible to their owners and are not meant to be browsed.
+			if project.State == gerrit.ProjectStateHidden {
+				continue
+			}
+
+			if !seen[project.Name] && !s.excludes(project) {
+				results <- SourceResult{Source: s, Repo: s.makeRepo(project)}
+				seen[project.Name] = true
+			}
+		}
+	}
+}
+
+func (s *GerritSource) excludes(p *gerrit.Project) bool {
+	return s.exclude(p.Name)
+}
+
+func (s *GerritSource) makeRepo(p *gerrit.Project) *types.Repo {
+	urn := s.svc.URN()
+	return &types.Repo{
+		Name: reposource.GerritRepoName(
+			s.config.RepositoryPathPattern,
+			s.baseURL.Hostname(),
+			p.Name,
+		),
+		URI: string(reposource.GerritRepoName(
+			"",
+			s.baseURL.Hostname(),
+			p.Name,
+		)),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          p.ID,
+			ServiceType: extsvc.TypeGerrit,
+			ServiceID:   s.baseURL.String(),
+		},
+		Description: p.Description,
+		Archived:    p.State == gerrit.ProjectStateReadOnly,
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: s.remoteURL(p),
+			},
+		},
+		Metadata: p,
+	}
+}
+
+// remoteURL returns the project's Git remote URL
+//
+// note: this does not contain credentials
+// if you need to get an authenticated clone url use repos.CloneURL
+func (s *GerritSource) remoteURL(p *gerrit.Project) string {
+	return gerritRemoteURL(p, s.config, false)
+}
+
+// gerritRemoteURL returns the Git remote URL of the given project. If authenticated is
+// true and the connection has credentials configured, HTTP remote URLs contain them and
+// point to the authenticated "/a/" endpoint of the Gerrit instance.
+func gerritRemoteURL(p *gerrit.Project, cfg *schema.GerritConnection, authenticated bool) string {
+	u, err := url.Parse(cfg.Url)
+	if err != nil {
+		// This should never happen since the URL is validated by the JSON schema
+		log15.Warn("Invalid Gerrit URL.", "url", cfg.Url, "error", err)
+		return ""
+	}
+
+	if cfg.GitURLType == "ssh" {
+		port := cfg.SshPort
+		if port == 0 {
+			port = defaultGerritSSHPort
+		}
+
+		remote := &url.URL{
+			Scheme: "ssh",
+			Host:   net.JoinHostPort(u.Hostname(), strconv.Itoa(port)),
+			Path:   "/" + p.Name,
+		}
+		if cfg.Username != "" {
+			remote.User = url.User(cfg.Username)
+		}
+		return remote.String()
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/"
+	if authenticated && (cfg.Username != "" || cfg.Password != "") {
+		u.Path += "a/"
+		u.User = url.UserPassword(cfg.Username, cfg.Password)
+	}
+	u.Path += p.Name
+	return u.String()
+}
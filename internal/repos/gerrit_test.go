@@ -0,0 +1,173 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gerrit"
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestGerritSource_ListRepos(t *testing.T) {
+	assertAllReposListed := func(want []string) types.ReposAssertion {
+		return func(t testing.TB, rs types.Repos) {
+			t.Helper()
+
+			have := rs.Names()
+			sort.Strings(have)
+			sort.Strings(want)
+
+			if diff := cmp.Diff(want, have); diff != "" {
+				t.Errorf("Mismatch (-want +got):\n%s", diff)
+			}
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		assert types.ReposAssertion
+		conf   *schema.GerritConnection
+		err    string
+	}{
+		{
+			name: "all",
+			assert: assertAllReposListed([]string{
+				"gerrit.sgdev.org/sandbox/playground",
+				"gerrit.sgdev.org/sourcegraph/about",
+				"gerrit.sgdev.org/sourcegraph/jsonrpc2",
+				"gerrit.sgdev.org/sourcegraph/src-cli",
+			}),
+			conf: &schema.GerritConnection{
+				Url: "https://gerrit.sgdev.org",
+			},
+			err: "<nil>",
+		},
+		{
+			name: "exclude",
+			assert: assertAllReposListed([]string{
+				"gerrit.sgdev.org/sourcegraph/jsonrpc2",
+				"gerrit.sgdev.org/sourcegraph/src-cli",
+			}),
+			conf: &schema.GerritConnection{
+				Url: "https://gerrit.sgdev.org",
+				Exclude: []*schema.ExcludedGerritProject{
+					{Name: "sourcegraph/about"},
+					{Pattern: "^sandbox/"},
+				},
+			},
+			err: "<nil>",
+		},
+		{
+			name: "projects",
+			assert: assertAllReposListed([]string{
+				"gerrit.sgdev.org/sourcegraph/src-cli",
+			}),
+			conf: &schema.GerritConnection{
+				Url: "https://gerrit.sgdev.org",
+				Projects: []string{
+					"sourcegraph/src-cli",
+					"sourcegraph/does-not-exist",
+				},
+			},
+			err: "<nil>",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		tc.name = "GERRIT-LIST-REPOS/" + tc.name
+		t.Run(tc.name, func(t *testing.T) {
+			cf, save := newClientFactory(t, tc.name)
+			defer save(t)
+
+			svc := &types.ExternalService{
+				Kind:   extsvc.KindGerrit,
+				Config: marshalJSON(t, tc.conf),
+			}
+
+			gerritSrc, err := newGerritSource(svc, tc.conf, cf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gerritSrc.perPage = 2
+
+			repos, err := listAll(context.Background(), gerritSrc)
+
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			if tc.assert != nil {
+				tc.assert(t, repos)
+			}
+		})
+	}
+}
+
+func TestGerritSource_makeRepo(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "gerrit-projects.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var projects []*gerrit.Project
+	if err := json.Unmarshal(b, &projects); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := types.ExternalService{ID: 1, Kind: extsvc.KindGerrit}
+
+	tests := []struct {
+		name   string
+		schema *schema.GerritConnection
+	}{
+		{
+			name: "simple",
+			schema: &schema.GerritConnection{
+				Url:      "https://gerrit.sgdev.org",
+				Username: "alice",
+				Password: "secret",
+			},
+		}, {
+			name: "ssh",
+			schema: &schema.GerritConnection{
+				Url:        "https://gerrit.sgdev.org/r/",
+				Username:   "alice",
+				Password:   "secret",
+				GitURLType: "ssh",
+				SshPort:    2222,
+			},
+		}, {
+			name: "path-pattern",
+			schema: &schema.GerritConnection{
+				Url:                   "https://gerrit.sgdev.org/r/",
+				RepositoryPathPattern: "gerrit/{name}",
+			},
+		},
+	}
+	for _, test := range tests {
+		test.name = "GerritSource_makeRepo_" + test.name
+		t.Run(test.name, func(t *testing.T) {
+			s, err := newGerritSource(&svc, test.schema, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*types.Repo
+			for _, p := range projects {
+				got = append(got, s.makeRepo(p))
+			}
+
+			testutil.AssertGolden(t, "testdata/golden/"+test.name, update(test.name), got)
+		})
+	}
+}
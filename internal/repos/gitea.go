@@ -0,0 +1,228 @@
+package repos
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// A GiteaSource yields repositories from a single Gitea connection configured in
+// Sourcegraph via the external services configuration.
+type GiteaSource struct {
+	svc     *types.ExternalService
+	config  *schema.GiteaConnection
+	baseURL *url.URL
+	exclude excludeFunc
+	client  *gitea.Client
+
+	// perPage is the number of repositories requested per page of a listing.
+	perPage int
+}
+
+// NewGiteaSource returns a new GiteaSource from the given external service.
+func NewGiteaSource(svc *types.ExternalService, cf *httpcli.Factory) (*GiteaSource, error) {
+	var c schema.GiteaConnection
+	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
+		return nil, errors.Errorf("external service id=%d config error: %s", svc.ID, err)
+	}
+	return newGiteaSource(svc, &c, cf)
+}
+
+func newGiteaSource(svc *types.ExternalService, c *schema.GiteaConnection, cf *httpcli.Factory) (*GiteaSource, error) {
+	baseURL, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, err
+	}
+	baseURL = extsvc.NormalizeBaseURL(baseURL)
+
+	if cf == nil {
+		cf = httpcli.NewExternalHTTPClientFactory()
+	}
+
+	cli, err := cf.Doer()
+	if err != nil {
+		return nil, err
+	}
+
+	var eb excludeBuilder
+	for _, r := range c.Exclude {
+		eb.Exact(r.Name)
+		if r.Id != 0 {
+			eb.Exact(strconv.Itoa(r.Id))
+		}
+		eb.Pattern(r.Pattern)
+	}
+	exclude, err := eb.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	client := gitea.NewClient(baseURL, cli)
+	client.Token = c.Token
+
+	return &GiteaSource{
+		svc:     svc,
+		config:  c,
+		baseURL: baseURL,
+		exclude: exclude,
+		client:  client,
+		perPage: 50,
+	}, nil
+}
+
+// ListRepos returns all Gitea repositories accessible to all connections configured
+// in Sourcegraph via the external services configuration.
+func (s GiteaSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	seen := make(map[int64]bool)
+	s.listConfiguredRepos(ctx, seen, results)
+	s.listConfiguredOwners(ctx, "orgs", s.config.Orgs, s.client.ListOrgRepos, seen, results)
+	s.listConfiguredOwners(ctx, "users", s.config.Users, s.client.ListUserRepos, seen, results)
+	s.listRepositoryQueries(ctx, seen, results)
+}
+
+// ExternalServices returns a singleton slice containing the external service.
+func (s GiteaSource) ExternalServices() types.ExternalServices {
+	return types.ExternalServices{s.svc}
+}
+
+func (s *GiteaSource) listConfiguredRepos(ctx context.Context, seen map[int64]bool, results chan SourceResult) {
+	for _, nameWithOwner := range s.config.Repos {
+		parts := strings.SplitN(nameWithOwner, "/", 2)
+		if len(parts) != 2 {
+			results <- SourceResult{Source: s, Err: errors.Errorf("gitea.repos: invalid name %q, expected owner/name", nameWithOwner)}
+			continue
+		}
+
+		repo, err := s.client.GetRepo(ctx, parts[0], parts[1])
+		if err != nil {
+			if gitea.IsNotFound(err) {
+				log15.Warn("skipping missing gitea.repos entry:", "name", nameWithOwner, "err", err)
+				continue
+			}
+
+			results <- SourceResult{Source: s, Err: errors.Wrapf(err, "gitea.repos: name=%q", nameWithOwner)}
+			continue
+		}
+
+		s.emit([]*gitea.Repository{repo}, seen, results)
+	}
+}
+
+// listOwnerRepos lists a page of the repositories of an organization or a user.
+type listOwnerRepos func(ctx context.Context, owner string, page *gitea.Pagination) ([]*gitea.Repository, *gitea.Pagination, error)
+
+func (s *GiteaSource) listConfiguredOwners(ctx context.Context, field string, owners []string, list listOwnerRepos, seen map[int64]bool, results chan SourceResult) {
+	for _, owner := range owners {
+		for page := (&gitea.Pagination{Page: 1, PerPage: s.perPage}); page != nil; {
+			repos, next, err := list(ctx, owner, page)
+			if err != nil {
+				if gitea.IsNotFound(err) {
+					log15.Warn("skipping missing gitea."+field+" entry:", "name", owner, "err", err)
+				} else {
+					results <- SourceResult{Source: s, Err: errors.Wrapf(err, "gitea.%s: name=%q", field, owner)}
+				}
+				break
+			}
+
+			s.emit(repos, seen, results)
+			page = next
+		}
+	}
+}
+
+func (s *GiteaSource) listRepositoryQueries(ctx context.Context, seen map[int64]bool, results chan SourceResult) {
+	for _, query := range s.config.RepositoryQuery {
+		var keyword string
+		switch query {
+		case "none":
+			continue
+		case "all":
+			keyword = ""
+		default:
+			keyword = query
+		}
+
+		for page := (&gitea.Pagination{Page: 1, PerPage: s.perPage}); page != nil; {
+			repos, next, err := s.client.SearchRepos(ctx, keyword, page)
+			if err != nil {
+				results <- SourceResult{Source: s, Err: errors.Wrapf(err, "gitea.repositoryQuery: query=%q", query)}
+				break
+			}
+
+			s.emit(repos, seen, results)
+			page = next
+		}
+	}
+}
+
+// emit sends the given repositories that have not been seen before and are not excluded
+// to results.
+func (s *GiteaSource) emit(repos []*gitea.Repository, seen map[int64]bool, results chan SourceResult) {
+	for _, r := range repos {
+		if !seen[r.ID] && !s.excludes(r) {
+			results <- SourceResult{Source: s, Repo: s.makeRepo(r)}
+			seen[r.ID] = true
+		}
+	}
+}
+
+func (s *GiteaSource) excludes(r *gitea.Repository) bool {
+	return s.exclude(r.FullName) || s.exclude(strconv.FormatInt(r.ID, 10))
+}
+
+func (s *GiteaSource) makeRepo(r *gitea.Repository) *types.Repo {
+	urn := s.svc.URN()
+	return &types.Repo{
+		Name: reposource.GiteaRepoName(
+			s.config.RepositoryPathPattern,
+			s.baseURL.Hostname(),
+			r.FullName,
+		),
+		URI: string(reposource.GiteaRepoName(
+			"",
+			s.baseURL.Hostname(),
+			r.FullName,
+		)),
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          strconv.FormatInt(r.ID, 10),
+			ServiceType: extsvc.TypeGitea,
+			ServiceID:   s.baseURL.String(),
+		},
+		Description: r.Description,
+		Fork:        r.Fork,
+		Archived:    r.Archived,
+		Private:     r.Private,
+		Stars:       r.Stars,
+		Sources: map[string]*types.SourceInfo{
+			urn: {
+				ID:       urn,
+				CloneURL: s.remoteURL(r),
+			},
+		},
+		Metadata: r,
+	}
+}
+
+// remoteURL returns the repository's Git remote URL
+//
+// note: this does not contain credentials
+// if you need to get an authenticated clone url use repos.CloneURL
+func (s *GiteaSource) remoteURL(r *gitea.Repository) string {
+	if s.config.GitURLType == "ssh" {
+		return r.SSHURL // SSH authentication must be provided out-of-band
+	}
+	return r.CloneURL
+}
@@ -0,0 +1,188 @@
+package repos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitea"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/testutil"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestGiteaSource_ListRepos(t *testing.T) {
+	f := gitea.NewTestFixture()
+	srv := gitea.NewTestServer(t, f)
+
+	testCases := []struct {
+		name string
+		conf *schema.GiteaConnection
+		want []string
+		err  string
+	}{
+		{
+			name: "repos",
+			conf: &schema.GiteaConnection{
+				Repos: []string{"acme/api", "alice/notes", "acme/does-not-exist"},
+			},
+			want: []string{"acme/api", "alice/notes"},
+		},
+		{
+			name: "orgs",
+			conf: &schema.GiteaConnection{
+				Orgs: []string{"acme", "does-not-exist"},
+			},
+			want: []string{"acme/api", "acme/legacy", "acme/web"},
+		},
+		{
+			name: "users",
+			conf: &schema.GiteaConnection{
+				Users: []string{"alice"},
+			},
+			want: []string{"alice/dotfiles", "alice/notes"},
+		},
+		{
+			name: "repositoryQuery all",
+			conf: &schema.GiteaConnection{
+				RepositoryQuery: []string{"all"},
+			},
+			want: []string{"acme/api", "acme/legacy", "acme/web", "alice/dotfiles", "alice/notes", "sandbox/playground"},
+		},
+		{
+			name: "repositoryQuery keywords",
+			conf: &schema.GiteaConnection{
+				RepositoryQuery: []string{"none", "play", "notes"},
+			},
+			want: []string{"alice/notes", "sandbox/playground"},
+		},
+		{
+			name: "overlapping selections are deduplicated",
+			conf: &schema.GiteaConnection{
+				Repos:           []string{"acme/web"},
+				Orgs:            []string{"acme"},
+				RepositoryQuery: []string{"web"},
+			},
+			want: []string{"acme/api", "acme/legacy", "acme/web"},
+		},
+		{
+			name: "exclude",
+			conf: &schema.GiteaConnection{
+				RepositoryQuery: []string{"all"},
+				Exclude: []*schema.ExcludedGiteaRepo{
+					{Name: "acme/legacy"},
+					{Id: 103},
+					{Pattern: "^sandbox/"},
+				},
+			},
+			want: []string{"acme/api", "acme/web", "alice/notes"},
+		},
+		{
+			name: "invalid repo name",
+			conf: &schema.GiteaConnection{
+				Repos: []string{"acme"},
+			},
+			want: []string{},
+			err:  "1 error occurred:\n\t* gitea.repos: invalid name \"acme\", expected owner/name\n\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.conf.Url = srv.URL
+			tc.conf.Token = f.Token
+			tc.conf.RepositoryPathPattern = "{nameWithOwner}"
+
+			if tc.err == "" {
+				tc.err = "<nil>"
+			}
+
+			svc := &types.ExternalService{
+				Kind:   extsvc.KindGitea,
+				Config: marshalJSON(t, tc.conf),
+			}
+
+			src, err := newGiteaSource(svc, tc.conf, httpcli.NewFactory(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			src.perPage = 2
+
+			repos, err := listAll(context.Background(), src)
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			have := types.Repos(repos).Names()
+			sort.Strings(have)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Errorf("Mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGiteaSource_makeRepo(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "gitea-repos.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var repos []*gitea.Repository
+	if err := json.Unmarshal(b, &repos); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := types.ExternalService{ID: 1, Kind: extsvc.KindGitea}
+
+	tests := []struct {
+		name   string
+		schema *schema.GiteaConnection
+	}{
+		{
+			name: "simple",
+			schema: &schema.GiteaConnection{
+				Url:   "https://gitea.example.com",
+				Token: "secret",
+			},
+		}, {
+			name: "ssh",
+			schema: &schema.GiteaConnection{
+				Url:        "https://gitea.example.com",
+				Token:      "secret",
+				GitURLType: "ssh",
+			},
+		}, {
+			name: "path-pattern",
+			schema: &schema.GiteaConnection{
+				Url:                   "https://gitea.example.com",
+				Token:                 "secret",
+				RepositoryPathPattern: "gitea/{nameWithOwner}",
+			},
+		},
+	}
+	for _, test := range tests {
+		test.name = "GiteaSource_makeRepo_" + test.name
+		t.Run(test.name, func(t *testing.T) {
+			s, err := newGiteaSource(&svc, test.schema, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*types.Repo
+			for _, r := range repos {
+				got = append(got, s.makeRepo(r))
+			}
+
+			testutil.AssertGolden(t, "testdata/golden/"+test.name, update(test.name), got)
+		})
+	}
+}
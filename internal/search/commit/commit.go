@@ -231,51 +231,80 @@ func searchCommitsInRepoStream(ctx context.Context, db dbutil.DB, op search.Comm
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	// Start the commit search stream.
-	events := git.RawLogDiffSearchStream(ctx, diffParameters.Repo, diffParameters.Options)
+	// searchStream runs a commit search stream with opts and sends its results. It
+	// returns true if we have hit the limit.
+	searchStream := func(opts git.RawLogDiffSearchOptions) (bool, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Start the commit search stream.
+		events := git.RawLogDiffSearchStream(ctx, diffParameters.Repo, opts)
+
+		// Ensure we drain events if we return early (limitHit or error).
+		defer func() {
+			cancel()
+			for range events {
+			}
+		}()
 
-	// Ensure we drain events if we return early (limitHit or error).
-	defer func() {
-		cancel()
-		for range events {
-		}
-	}()
+		var results []*result.CommitMatch
+		for event := range events {
+			timedOut = timedOut || !event.Complete || ctx.Err() == context.DeadlineExceeded
 
-	var results []*result.CommitMatch
-	for event := range events {
-		timedOut = timedOut || !event.Complete || ctx.Err() == context.DeadlineExceeded
+			results = logCommitSearchResultsToMatches(&op, op.RepoRevs.Repo, event.Results)
+			if len(results) > 0 {
+				resultCount += len(event.Results)
+				limitHit = resultCount > int(op.PatternInfo.FileMatchLimit)
+			}
 
-		results = logCommitSearchResultsToMatches(&op, op.RepoRevs.Repo, event.Results)
-		if len(results) > 0 {
-			resultCount += len(event.Results)
-			limitHit = resultCount > int(op.PatternInfo.FileMatchLimit)
-		}
+			searchErr := event.Error
+			if searchErr != nil {
+				tr.LogFields(otlog.String("repo", string(op.RepoRevs.Repo.Name)), otlog.String("searchErr", searchErr.Error()), otlog.Bool("timeout", errcode.IsTimeout(searchErr)), otlog.Bool("temporary", errcode.IsTemporary(searchErr)))
+			}
 
-		searchErr := event.Error
-		if searchErr != nil {
-			tr.LogFields(otlog.String("repo", string(op.RepoRevs.Repo.Name)), otlog.String("searchErr", searchErr.Error()), otlog.Bool("timeout", errcode.IsTimeout(searchErr)), otlog.Bool("temporary", errcode.IsTemporary(searchErr)))
-		}
+			stats, err := repos.HandleRepoSearchResult(op.RepoRevs, limitHit, !event.Complete, searchErr)
+			if err != nil {
+				return false, errors.Wrapf(err, "failed to search commit %s %s", errorName(op.Diff), op.RepoRevs.String())
+			}
 
-		stats, err := repos.HandleRepoSearchResult(op.RepoRevs, limitHit, !event.Complete, searchErr)
-		if err != nil {
-			return errors.Wrapf(err, "failed to search commit %s %s", errorName(op.Diff), op.RepoRevs.String())
-		}
+			// Only send if we have something to report back.
+			if len(results) > 0 || !stats.Zero() {
+				s.Send(streaming.SearchEvent{
+					Results: commitMatchesToMatches(results),
+					Stats:   stats,
+				})
+			}
 
-		// Only send if we have something to report back.
-		if len(results) > 0 || !stats.Zero() {
-			s.Send(streaming.SearchEvent{
-				Results: commitMatchesToMatches(results),
-				Stats:   stats,
-			})
+			// If we have hit the limit we stop (after we sent the above results).
+			if limitHit {
+				return true, nil
+			}
 		}
+		return false, nil
+	}
+
+	// If the commit index of gitserver narrows down the commits, only search
+	// those.
+	if candidates, ok := searchCommitIndex(ctx, &op, diffParameters.Options.Args); ok {
+		tr.LazyPrintf("%d candidate commits from commit index", len(candidates))
+		for len(candidates) > 0 {
+			n := len(candidates)
+			if n > maxCandidatesPerSearch {
+				n = maxCandidatesPerSearch
+			}
+			opts := diffParameters.Options
+			opts.Args = append(append(append([]string{}, opts.Args...), "--no-walk"), candidates[:n]...)
+			candidates = candidates[n:]
 
-		// If we have hit the limit we stop (after we sent the above results).
-		if limitHit {
-			break
+			if stop, err := searchStream(opts); stop || err != nil {
+				return err
+			}
 		}
+		return nil
 	}
 
-	return nil
+	_, err = searchStream(diffParameters.Options)
+	return err
 }
 
 func errorName(diff bool) string {
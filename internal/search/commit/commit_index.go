@@ -0,0 +1,180 @@
+package commit
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+)
+
+// maxCandidatesPerSearch is the maximum number of candidate commits from the
+// commit index passed to a single git log.
+const maxCandidatesPerSearch = 500
+
+// searchCommitIndex asks gitserver for the commits that may match op, using
+// the commit index. args are the git log args for op. It returns false if the
+// commit index can't be used, in which case all commits need to be searched.
+//
+// The commit index only covers the default branch, so it is only used for
+// searches of the default branch.
+func searchCommitIndex(ctx context.Context, op *search.CommitParameters, args []string) ([]string, bool) {
+	if !conf.CommitIndexEnabled() {
+		return nil, false
+	}
+	if revs := op.RepoRevs.Revs; len(revs) != 1 || revs[0] != (search.RevisionSpecifier{}) {
+		return nil, false
+	}
+
+	req := commitIndexSearchRequest(op, args)
+	req.Repo = op.RepoRevs.GitserverRepo()
+	resp, err := gitserver.DefaultClient.SearchCommitIndex(ctx, req)
+	if err != nil {
+		log15.Warn("commit index search failed, falling back to git log", "repo", req.Repo, "error", err)
+		return nil, false
+	}
+	if !resp.Indexed || !resp.Filtered {
+		return nil, false
+	}
+
+	candidates := make([]string, 0, len(resp.Commits))
+	for _, c := range resp.Commits {
+		candidates = append(candidates, string(c))
+	}
+	return candidates, true
+}
+
+// commitIndexSearchRequest returns the commit index query equivalent to the
+// diff pattern of op and the --grep and --author flags in args.
+func commitIndexSearchRequest(op *search.CommitParameters, args []string) *protocol.CommitIndexSearchRequest {
+	var req protocol.CommitIndexSearchRequest
+	if op.Diff && op.PatternInfo.Pattern != "" {
+		pattern := op.PatternInfo.Pattern
+		if !op.PatternInfo.IsRegExp {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		req.Diff = []string{pattern}
+	}
+
+	// Without --extended-regexp, git reads the --grep and --author patterns as
+	// basic regular expressions, which must be translated to the Go syntax of
+	// the index.
+	extended := false
+	for _, arg := range args {
+		if arg == "--extended-regexp" {
+			extended = true
+		}
+	}
+	translate := func(pattern string) (string, bool) {
+		if extended {
+			return pattern, true
+		}
+		return basicToGoRegexp(pattern)
+	}
+
+	inverted, allAuthors := false, true
+	for _, arg := range args {
+		switch {
+		case arg == "--invert-grep":
+			inverted = true
+		case strings.HasPrefix(arg, "--grep="):
+			// All message patterns must match, so a pattern that can't be
+			// translated can be left out.
+			if pattern, ok := translate(strings.TrimPrefix(arg, "--grep=")); ok {
+				req.Message = append(req.Message, pattern)
+			}
+		case strings.HasPrefix(arg, "--author="):
+			// One author pattern must match, so if one can't be translated,
+			// the index can't filter by author at all.
+			if pattern, ok := translate(strings.TrimPrefix(arg, "--author=")); ok {
+				req.Author = append(req.Author, pattern)
+			} else {
+				allAuthors = false
+			}
+		}
+	}
+	if !allAuthors {
+		req.Author = nil
+	}
+	if inverted {
+		// The index can only tell which commits may match a pattern, not
+		// which commits don't.
+		req.Message, req.Author = nil, nil
+	}
+	return &req
+}
+
+// basicToGoRegexp translates a POSIX basic regular expression with the GNU
+// extensions that git supports to the syntax of the Go regexp package. It
+// returns false for syntax that isn't translated, such as back-references.
+func basicToGoRegexp(pattern string) (string, bool) {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			if i+1 == len(pattern) {
+				return "", false
+			}
+			i++
+			switch next := pattern[i]; next {
+			case '|', '?', '+', '(', ')', '{', '}':
+				// These are operators when escaped, and literals otherwise.
+				b.WriteByte(next)
+			case '.', '*', '[', ']', '^', '$', '\\':
+				b.WriteByte('\\')
+				b.WriteByte(next)
+			default:
+				return "", false
+			}
+		case '|', '?', '+', '(', ')', '{', '}':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '[':
+			end := bracketExpressionEnd(pattern, i)
+			if end < 0 {
+				return "", false
+			}
+			expr := pattern[i : end+1]
+			if strings.Contains(expr, "[=") || strings.Contains(expr, "[.") {
+				return "", false
+			}
+			// Backslashes are literals in bracket expressions.
+			b.WriteString(strings.ReplaceAll(expr, `\`, `\\`))
+			i = end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), true
+}
+
+// bracketExpressionEnd returns the index of the "]" that ends the bracket
+// expression starting at pattern[start], or -1 if it doesn't end.
+func bracketExpressionEnd(pattern string, start int) int {
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		// A "]" at the start is a literal.
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == ']':
+			return i
+		case strings.HasPrefix(pattern[i:], "[:"):
+			end := strings.Index(pattern[i+2:], ":]")
+			if end < 0 {
+				return -1
+			}
+			i += 2 + end + 1
+		}
+	}
+	return -1
+}
@@ -11,17 +11,23 @@ import (
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/google/go-cmp/cmp"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/database"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
 	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/search/result"
 	"github.com/sourcegraph/sourcegraph/internal/search/streaming"
 	"github.com/sourcegraph/sourcegraph/internal/types"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestSearchCommitsInRepo(t *testing.T) {
@@ -108,6 +114,112 @@ func TestSearchCommitsInRepo(t *testing.T) {
 	}
 }
 
+func TestSearchCommitsInRepo_commitIndex(t *testing.T) {
+	ctx := context.Background()
+	db := new(dbtesting.MockDB)
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{SearchCommitIndex: "enabled"},
+	}})
+	defer conf.Mock(nil)
+
+	var candidates []api.CommitID
+	for i := 0; i < maxCandidatesPerSearch+1; i++ {
+		candidates = append(candidates, api.CommitID(strconv.Itoa(i)))
+	}
+
+	var (
+		gotReq  *protocol.CommitIndexSearchRequest
+		indexed bool
+	)
+	gitserver.MockSearchCommitIndex = func(req *protocol.CommitIndexSearchRequest) (*protocol.CommitIndexSearchResponse, error) {
+		gotReq = req
+		return &protocol.CommitIndexSearchResponse{Indexed: indexed, Filtered: indexed, Commits: candidates}, nil
+	}
+	defer func() { gitserver.MockSearchCommitIndex = nil }()
+
+	var searchedRevs [][]string
+	git.Mocks.RawLogDiffSearch = func(opt git.RawLogDiffSearchOptions) ([]*git.LogCommitSearchResult, bool, error) {
+		var revs []string
+		for i, arg := range opt.Args {
+			if arg == "--no-walk" {
+				revs = opt.Args[i+1:]
+			}
+		}
+		searchedRevs = append(searchedRevs, revs)
+		return nil, true, nil
+	}
+	defer git.ResetMocks()
+
+	q, err := query.ParseLiteral("p.x message:fix author:alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := search.CommitParameters{
+		RepoRevs: &search.RepositoryRevisions{
+			Repo: types.RepoName{ID: 1, Name: "repo"},
+			Revs: []search.RevisionSpecifier{{}},
+		},
+		PatternInfo: &search.CommitPatternInfo{Pattern: "p.x", FileMatchLimit: int32(search.DefaultMaxSearchResults)},
+		Query:       q,
+		Diff:        true,
+	}
+
+	t.Run("unindexed", func(t *testing.T) {
+		searchedRevs = nil
+		if _, _, _, err := searchCommitsInRepo(ctx, db, op); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([][]string{nil}, searchedRevs); diff != "" {
+			t.Fatalf("unexpected searches (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("indexed", func(t *testing.T) {
+		indexed, searchedRevs = true, nil
+		if _, _, _, err := searchCommitsInRepo(ctx, db, op); err != nil {
+			t.Fatal(err)
+		}
+
+		wantReq := &protocol.CommitIndexSearchRequest{
+			Repo:    "repo",
+			Diff:    []string{`p\.x`},
+			Message: []string{"fix"},
+			Author:  []string{"alice"},
+		}
+		if diff := cmp.Diff(wantReq, gotReq); diff != "" {
+			t.Fatalf("unexpected request (-want +got):\n%s", diff)
+		}
+
+		var wantRevs [][]string
+		for _, batch := range [][]api.CommitID{candidates[:maxCandidatesPerSearch], candidates[maxCandidatesPerSearch:]} {
+			var revs []string
+			for _, c := range batch {
+				revs = append(revs, string(c))
+			}
+			wantRevs = append(wantRevs, revs)
+		}
+		if diff := cmp.Diff(wantRevs, searchedRevs); diff != "" {
+			t.Fatalf("unexpected searches (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("revision", func(t *testing.T) {
+		searchedRevs = nil
+		op := op
+		op.RepoRevs = &search.RepositoryRevisions{
+			Repo: types.RepoName{ID: 1, Name: "repo"},
+			Revs: []search.RevisionSpecifier{{RevSpec: "main"}},
+		}
+		if _, _, _, err := searchCommitsInRepo(ctx, db, op); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([][]string{nil}, searchedRevs); diff != "" {
+			t.Fatalf("unexpected searches (-want +got):\n%s", diff)
+		}
+	})
+}
+
 func resetMocks() {
 	database.Mocks = database.MockStores{}
 	backend.Mocks = backend.MockServices{}
@@ -362,3 +474,71 @@ func TestOrderedFuzzyRegexp(t *testing.T) {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
+
+func TestCommitIndexSearchRequest(t *testing.T) {
+	op := &search.CommitParameters{PatternInfo: &search.CommitPatternInfo{}}
+	tests := []struct {
+		name string
+		args []string
+		want *protocol.CommitIndexSearchRequest
+	}{
+		{
+			name: "basic alternation",
+			args: []string{"--all-match", `--grep=foo\|bar`, `--author=alice\|bob`},
+			want: &protocol.CommitIndexSearchRequest{Message: []string{"foo|bar"}, Author: []string{"alice|bob"}},
+		},
+		{
+			name: "basic literals",
+			args: []string{"--all-match", "--grep=fix(ui)?", "--grep=a+b", `--grep=[\w]`},
+			want: &protocol.CommitIndexSearchRequest{Message: []string{`fix\(ui\)\?`, `a\+b`, `[\\w]`}},
+		},
+		{
+			name: "extended",
+			args: []string{"--extended-regexp", "--all-match", "--grep=foo|bar", "--author=fix(ui)?"},
+			want: &protocol.CommitIndexSearchRequest{Message: []string{"foo|bar"}, Author: []string{"fix(ui)?"}},
+		},
+		{
+			name: "untranslatable",
+			args: []string{"--all-match", `--grep=\(a\)\1`, "--grep=fix", "--author=alice", `--author=\<bob`},
+			want: &protocol.CommitIndexSearchRequest{Message: []string{"fix"}},
+		},
+		{
+			name: "inverted",
+			args: []string{"--all-match", "--invert-grep", "--grep=fix"},
+			want: &protocol.CommitIndexSearchRequest{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if diff := cmp.Diff(test.want, commitIndexSearchRequest(op, test.args)); diff != "" {
+				t.Errorf("unexpected request (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestBasicToGoRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+		ok      bool
+	}{
+		{pattern: `foo\|bar`, want: `foo|bar`, ok: true},
+		{pattern: `foo|bar`, want: `foo\|bar`, ok: true},
+		{pattern: `colou\?r`, want: `colou?r`, ok: true},
+		{pattern: `a\{2,\}`, want: `a{2,}`, ok: true},
+		{pattern: `\(ab\)*c`, want: `(ab)*c`, ok: true},
+		{pattern: `^a.b\.c$`, want: `^a.b\.c$`, ok: true},
+		{pattern: `[]a[:digit:]\]x`, want: `[]a[:digit:]\\]x`, ok: true},
+		{pattern: `\(a\)\1`, ok: false},
+		{pattern: `\<word`, ok: false},
+		{pattern: `[abc`, ok: false},
+		{pattern: `trailing\`, ok: false},
+	}
+	for _, test := range tests {
+		got, ok := basicToGoRegexp(test.pattern)
+		if got != test.want || ok != test.ok {
+			t.Errorf("basicToGoRegexp(%q) = %q, %v, want %q, %v", test.pattern, got, ok, test.want, test.ok)
+		}
+	}
+}
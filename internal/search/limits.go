@@ -12,6 +12,12 @@ const (
 	DefaultMaxSearchResults          = 30
 	DefaultMaxSearchResultsStreaming = 500
 
+	// OwnerFilterFileMatchLimit is the minimum number of file matches searched
+	// for queries filtering on owners. Files are filtered by their owners after
+	// they are searched, so more files are searched to fill the requested
+	// number of results.
+	OwnerFilterFileMatchLimit = 10000
+
 	// The default timeout to use for queries.
 	DefaultTimeout = 20 * time.Second
 )
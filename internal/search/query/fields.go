@@ -15,10 +15,12 @@ const (
 	FieldType               = "type"
 	FieldRepoHasFile        = "repohasfile"
 	FieldRepoHasCommitAfter = "repohascommitafter"
+	FieldFileHasOwner       = "filehasowner"
 	FieldPatternType        = "patterntype"
 	FieldContent            = "content"
 	FieldVisibility         = "visibility"
 	FieldRev                = "rev"
+	FieldRevAtTime          = "revattime"
 	FieldContext            = "context"
 
 	// For diff and commit search only:
@@ -34,6 +36,7 @@ const (
 	FieldTimeout   = "timeout"
 	FieldCombyRule = "rule"
 	FieldSelect    = "select"
+	FieldRewrite   = "rewrite"
 )
 
 var allFields = map[string]struct{}{
@@ -56,6 +59,7 @@ var allFields = map[string]struct{}{
 	FieldVisibility:         empty,
 	FieldRepoHasFile:        empty,
 	FieldRepoHasCommitAfter: empty,
+	FieldFileHasOwner:       empty,
 	FieldBefore:             empty,
 	"until":                 empty,
 	FieldAfter:              empty,
@@ -70,8 +74,10 @@ var allFields = map[string]struct{}{
 	FieldTimeout:            empty,
 	FieldCombyRule:          empty,
 	FieldRev:                empty,
+	FieldRevAtTime:          empty,
 	"revision":              empty,
 	FieldSelect:             empty,
+	FieldRewrite:            empty,
 }
 
 var aliases = map[string]string{
@@ -25,6 +25,16 @@ type Predicate interface {
 	Plan(parent Basic) (Plan, error)
 }
 
+// FilterPredicate is a predicate that isn't evaluated as a subquery. Instead, it
+// is replaced by a parameter that filters the results of the query it appears
+// in. Unlike other predicates, filter predicates may be negated.
+type FilterPredicate interface {
+	Predicate
+
+	// Filter returns the parameters that replace the predicate in a query.
+	Filter(negated bool) []Parameter
+}
+
 var DefaultPredicateRegistry = predicateRegistry{
 	FieldRepo: {
 		"contains":              func() Predicate { return &RepoContainsPredicate{} },
@@ -35,6 +45,10 @@ var DefaultPredicateRegistry = predicateRegistry{
 	FieldFile: {
 		"contains.content": func() Predicate { return &FileContainsContentPredicate{} },
 		"contains":         func() Predicate { return &FileContainsContentPredicate{} },
+		"has.owner":        func() Predicate { return &FileHasOwnerPredicate{} },
+	},
+	FieldRev: {
+		"at.time": func() Predicate { return &RevAtTimePredicate{} },
 	},
 }
 
@@ -278,6 +292,93 @@ func (f *FileContainsContentPredicate) Plan(parent Basic) (Plan, error) {
 	return ToPlan(Dnf(nodes))
 }
 
+/* file:has.owner(owner) */
+
+// FileHasOwnerPredicate filters file results to those owned by Owner according to
+// the CODEOWNERS file of their repository. An empty Owner matches files that have
+// any owner, so -file:has.owner() matches files without owners.
+type FileHasOwnerPredicate struct {
+	Owner string
+}
+
+func (f *FileHasOwnerPredicate) ParseParams(params string) error {
+	params = strings.TrimSpace(params)
+	if strings.ContainsAny(params, " \t\n") {
+		return errors.Errorf("file:has.owner argument should be a single owner")
+	}
+	f.Owner = params
+	return nil
+}
+
+func (f FileHasOwnerPredicate) Field() string { return FieldFile }
+func (f FileHasOwnerPredicate) Name() string  { return "has.owner" }
+
+// AnyOwner is the value of a filehasowner parameter that matches files with any
+// owner.
+const AnyOwner = "*"
+
+func (f *FileHasOwnerPredicate) Filter(negated bool) []Parameter {
+	value := f.Owner
+	if value == "" {
+		value = AnyOwner
+	}
+	return []Parameter{{
+		Field:   FieldFileHasOwner,
+		Value:   value,
+		Negated: negated,
+	}}
+}
+
+func (f *FileHasOwnerPredicate) Plan(parent Basic) (Plan, error) {
+	return Plan{SubstituteFilterPredicates(parent)}, nil
+}
+
+/* rev:at.time(date[, ref]) */
+
+// RevAtTimePredicate searches the last commit of Ref (the default branch if
+// empty) that was committed before Time. Time is passed to git as is, so any
+// date format understood by git log --before is valid.
+type RevAtTimePredicate struct {
+	Time string
+	Ref  string
+}
+
+func (f *RevAtTimePredicate) ParseParams(params string) error {
+	parts := strings.SplitN(params, ",", 2)
+	f.Time = strings.TrimSpace(parts[0])
+	if f.Time == "" {
+		return errors.Errorf("rev:at.time requires a date argument, for example rev:at.time(2021-01-01)")
+	}
+	if len(parts) == 1 {
+		return nil
+	}
+
+	f.Ref = strings.TrimSpace(parts[1])
+	if f.Ref == "" || strings.ContainsAny(f.Ref, " \t\n,:") {
+		return errors.Errorf("rev:at.time second argument should be a single ref, for example rev:at.time(2021-01-01, main)")
+	}
+	if strings.HasPrefix(f.Ref, "*") || strings.HasPrefix(f.Ref, "^") {
+		return errors.Errorf("rev:at.time does not support ref globs or negated refs")
+	}
+	return nil
+}
+
+func (f RevAtTimePredicate) Field() string { return FieldRev }
+func (f RevAtTimePredicate) Name() string  { return "at.time" }
+
+func (f *RevAtTimePredicate) Filter(_ bool) []Parameter {
+	// Negation is rejected when validating rev: parameters.
+	params := []Parameter{{Field: FieldRevAtTime, Value: f.Time}}
+	if f.Ref != "" {
+		params = append(params, Parameter{Field: FieldRev, Value: f.Ref})
+	}
+	return params
+}
+
+func (f *RevAtTimePredicate) Plan(parent Basic) (Plan, error) {
+	return Plan{SubstituteFilterPredicates(parent)}, nil
+}
+
 // nonPredicateRepos returns the repo nodes in a query that aren't predicates,
 // respecting parameters that determine repo results.
 func nonPredicateRepos(q Basic) []Node {
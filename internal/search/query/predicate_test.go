@@ -55,6 +55,73 @@ func TestRepoContainsPredicate(t *testing.T) {
 	})
 }
 
+func TestFileHasOwnerPredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		valid := map[string]string{
+			``:                   ``,
+			`@acme/payments`:     `@acme/payments`,
+			` alice@example.com`: `alice@example.com`,
+		}
+		for params, want := range valid {
+			p := &FileHasOwnerPredicate{}
+			if err := p.ParseParams(params); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if p.Owner != want {
+				t.Fatalf("expected owner %q, got %q", want, p.Owner)
+			}
+		}
+
+		p := &FileHasOwnerPredicate{}
+		if err := p.ParseParams(`@alice @bob`); err == nil {
+			t.Fatal("expected error but got none")
+		}
+	})
+
+	t.Run("negation", func(t *testing.T) {
+		if _, err := Pipeline(InitLiteral(`-file:has.owner() TODO`)); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if _, err := Pipeline(InitLiteral(`-file:contains(TODO)`)); err == nil {
+			t.Fatal("expected error but got none")
+		}
+	})
+}
+
+func TestRevAtTimePredicate(t *testing.T) {
+	t.Run("ParseParams", func(t *testing.T) {
+		valid := map[string]RevAtTimePredicate{
+			`2021-01-01`:                {Time: "2021-01-01"},
+			` 2 weeks ago `:             {Time: "2 weeks ago"},
+			`2021-01-01, main`:          {Time: "2021-01-01", Ref: "main"},
+			`2021-01-01T10:00:00Z,v1.2`: {Time: "2021-01-01T10:00:00Z", Ref: "v1.2"},
+		}
+		for params, want := range valid {
+			p := &RevAtTimePredicate{}
+			if err := p.ParseParams(params); err != nil {
+				t.Fatalf("unexpected error for %q: %s", params, err)
+			}
+			if *p != want {
+				t.Fatalf("expected %+v, got %+v", want, *p)
+			}
+		}
+
+		invalid := []string{``, ` , main`, `2021-01-01,`, `2021-01-01, *refs/heads/*`, `2021-01-01, ^main`, `2021-01-01, main:dev`, `2021-01-01, a b`}
+		for _, params := range invalid {
+			p := &RevAtTimePredicate{}
+			if err := p.ParseParams(params); err == nil {
+				t.Fatalf("expected error for %q but got none", params)
+			}
+		}
+	})
+
+	t.Run("negation", func(t *testing.T) {
+		if _, err := Pipeline(InitLiteral(`repo:foo -rev:at.time(2021-01-01) TODO`)); err == nil {
+			t.Fatal("expected error but got none")
+		}
+	})
+}
+
 func TestParseAsPredicate(t *testing.T) {
 	tests := []struct {
 		input  string
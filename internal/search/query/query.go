@@ -142,6 +142,9 @@ func Pipeline(steps ...step) (Plan, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Filter predicates are substituted first since rev:at.time(...) expands
+	// to a rev: parameter.
+	plan = MapPlan(plan, SubstituteFilterPredicates)
 	plan = MapPlan(plan, ConcatRevFilters)
 	return plan, nil
 }
@@ -29,7 +29,8 @@ func TestPipeline_equivalence(t *testing.T) {
 		nodes, _ := Run(InitLiteral(input))
 		disjuncts := Dnf(nodes)
 		plan, _ := ToPlan(disjuncts)
-		manualPlan := MapPlan(plan, ConcatRevFilters)
+		manualPlan := MapPlan(plan, SubstituteFilterPredicates)
+		manualPlan = MapPlan(manualPlan, ConcatRevFilters)
 		if diff := cmp.Diff(
 			planToString(Dnf(pipelinePlan.ToParseTree())),
 			planToString(Dnf(manualPlan.ToParseTree())),
@@ -40,6 +41,8 @@ func TestPipeline_equivalence(t *testing.T) {
 	}
 
 	autogold.Want("equivalent or-expression", "equivalent").Equal(t, test("(repo:bob or repo:jim) ((rev:olga or rev:ham) demo123232)"))
+	autogold.Want("equivalent filter predicates", "equivalent").Equal(t, test("(file:has.owner(@alice) or -file:has.owner()) TODO"))
+	autogold.Want("equivalent at.time predicate", "equivalent").Equal(t, test("repo:foo rev:at.time(2021-01-01, main) TODO"))
 }
 
 func TestPipeline(t *testing.T) {
@@ -49,4 +52,7 @@ func TestPipeline(t *testing.T) {
 	}
 
 	autogold.Want("contains(...) spans newlines", `"repo:contains.file(\nfoo\n)"`).Equal(t, test("repo:contains.file(\nfoo\n)"))
+	autogold.Want("has.owner(...) is substituted", `"filehasowner:@acme/payments" "-filehasowner:@bob" "TODO"`).Equal(t, test("file:has.owner(@acme/payments) -file:has.owner(@bob) TODO"))
+	autogold.Want("at.time(...) is substituted", `"repo:foo@main" "revattime:2021-01-01" "TODO"`).Equal(t, test("repo:foo rev:at.time(2021-01-01, main) TODO"))
+	autogold.Want("at.time(...) without ref", `"repo:foo" "revattime:2 weeks ago" "TODO"`).Equal(t, test("repo:foo rev:at.time(2 weeks ago) TODO"))
 }
@@ -3,6 +3,7 @@ package query
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/cockroachdb/errors"
@@ -37,16 +38,28 @@ func LowercaseFieldNames(nodes []Node) []Node {
 	})
 }
 
+// CountAllLimit is the result limit of count:all.
+const CountAllLimit = 99999999
+
 // SubstituteCountAll replaces count:all with count:99999999.
 func SubstituteCountAll(nodes []Node) []Node {
 	return MapParameter(nodes, func(field, value string, negated bool, annotation Annotation) Node {
 		if field == FieldCount && strings.ToLower(value) == "all" {
-			return Parameter{Field: field, Value: "99999999", Negated: negated, Annotation: annotation}
+			return Parameter{Field: field, Value: strconv.Itoa(CountAllLimit), Negated: negated, Annotation: annotation}
 		}
 		return Parameter{Field: field, Value: value, Negated: negated, Annotation: annotation}
 	})
 }
 
+// CountAll adds count:all to a basic query without a count: parameter, so that
+// its search returns all results.
+func CountAll(b Basic) Basic {
+	if b.GetCount() != "" {
+		return b
+	}
+	return b.AddCount(CountAllLimit)
+}
+
 var ErrBadGlobPattern = errors.New("syntax error in glob pattern")
 
 // translateCharacterClass translates character classes like [a-zA-Z].
@@ -671,6 +684,28 @@ func ConcatRevFilters(b Basic) Basic {
 	return Basic{Parameters: toParameters(modified), Pattern: b.Pattern}
 }
 
+// SubstituteFilterPredicates replaces filter predicates like file:has.owner(...)
+// with the parameters that implement them.
+// Invariant: Guaranteed to succeed on a validated Basic query.
+func SubstituteFilterPredicates(b Basic) Basic {
+	parameters := make([]Parameter, 0, len(b.Parameters))
+	for _, param := range b.Parameters {
+		if !param.Annotation.Labels.IsSet(IsPredicate) {
+			parameters = append(parameters, param)
+			continue
+		}
+		name, params := ParseAsPredicate(param.Value)
+		predicate, ok := DefaultPredicateRegistry.Get(param.Field, name).(FilterPredicate)
+		if !ok {
+			parameters = append(parameters, param)
+			continue
+		}
+		_ = predicate.ParseParams(params) // Invariant: error already checked
+		parameters = append(parameters, predicate.Filter(param.Negated)...)
+	}
+	return Basic{Parameters: parameters, Pattern: b.Pattern}
+}
+
 // labelStructural converts Literal labels to Structural labels. Structural
 // queries are parsed the same as literal queries, we just convert the labels as
 // a postprocessing step to keep the parser lean.
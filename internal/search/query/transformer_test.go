@@ -973,3 +973,14 @@ func TestSubstituteCountAll(t *testing.T) {
 	autogold.Want("with integer count", `(and "count:3" "foo")`).Equal(t, test("foo count:3"))
 	autogold.Want("subexpressions", `(or (and "count:3" "foo") (and "count:99999999" "bar"))`).Equal(t, test("(foo count:3) or (bar count:all)"))
 }
+
+func TestCountAll(t *testing.T) {
+	test := func(input string) string {
+		plan, _ := Pipeline(InitLiteral(input))
+		return MapPlan(plan, CountAll).ToParseTree().String()
+	}
+
+	autogold.Want("no count", `(and "count:99999999" "foo")`).Equal(t, test("foo"))
+	autogold.Want("with count", `(and "count:3" "foo")`).Equal(t, test("foo count:3"))
+	autogold.Want("subexpressions", `(or (and "count:3" "foo") (and "count:99999999" "bar"))`).Equal(t, test("(foo count:3) or bar"))
+}
@@ -398,6 +398,8 @@ func (q Q) valueToTypedValue(field, value string, label labels) []*Value {
 
 	case
 		FieldRepoHasCommitAfter,
+		FieldFileHasOwner,
+		FieldRevAtTime,
 		FieldBefore, "until",
 		FieldAfter, "since":
 		return []*Value{{String: &value}}
@@ -412,7 +414,8 @@ func (q Q) valueToTypedValue(field, value string, label labels) []*Value {
 		FieldIndex,
 		FieldCount,
 		FieldTimeout,
-		FieldCombyRule:
+		FieldCombyRule,
+		FieldRewrite:
 		return []*Value{{String: &value}}
 	}
 	return []*Value{{String: &value}}
@@ -306,8 +306,12 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 		FieldRepoHasFile:
 		return satisfies(isValidRegexp)
 	case
-		FieldRepoHasCommitAfter:
+		FieldRepoHasCommitAfter,
+		FieldRevAtTime:
 		return satisfies(isSingular, isNotNegated)
+	case
+		FieldFileHasOwner:
+		return satisfies()
 	case
 		FieldBefore,
 		FieldAfter:
@@ -326,7 +330,8 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 		FieldCount:
 		return satisfies(isSingular, isNumber, isNotNegated)
 	case
-		FieldCombyRule:
+		FieldCombyRule,
+		FieldRewrite:
 		return satisfies(isSingular, isNotNegated)
 	case
 		FieldTimeout:
@@ -429,12 +434,12 @@ func validatePredicates(nodes []Node) error {
 			return
 		}
 		if annotation.Labels.IsSet(IsPredicate) {
-			if negated {
+			name, params := ParseAsPredicate(value)                // guaranteed to succeed
+			predicate := DefaultPredicateRegistry.Get(field, name) // guaranteed to succeed
+			if _, ok := predicate.(FilterPredicate); negated && !ok {
 				err = errors.New("predicates do not currently support negation")
 				return
 			}
-			name, params := ParseAsPredicate(value)                // guaranteed to succeed
-			predicate := DefaultPredicateRegistry.Get(field, name) // guaranteed to succeed
 			if parseErr := predicate.ParseParams(params); parseErr != nil {
 				err = errors.Errorf("invalid predicate value: %s", parseErr)
 			}
@@ -498,6 +503,28 @@ func validateParameters(nodes []Node) error {
 	return err
 }
 
+// validateRewrite checks that a query with a rewrite: field has a structural
+// or regexp pattern, which the rewrite template can refer to.
+func validateRewrite(nodes []Node) error {
+	seenRewrite := false
+	VisitField(nodes, FieldRewrite, func(_ string, _ bool, _ Annotation) {
+		seenRewrite = true
+	})
+	if !seenRewrite {
+		return nil
+	}
+	rewritable := false
+	VisitPattern(nodes, func(value string, negated bool, annotation Annotation) {
+		if !negated && (annotation.Labels.IsSet(Structural) || annotation.Labels.IsSet(Regexp)) {
+			rewritable = true
+		}
+	})
+	if !rewritable {
+		return errors.New("rewrite: requires a structural or regular expression search pattern. Add patterntype:structural or patterntype:regexp to the query")
+	}
+	return nil
+}
+
 func validatePattern(nodes []Node) error {
 	var err error
 	VisitPattern(nodes, func(value string, negated bool, annotation Annotation) {
@@ -532,6 +559,7 @@ func validate(nodes []Node) error {
 		validateCommitParameters,
 		validatePredicates,
 		validateTypeStructural,
+		validateRewrite,
 	)
 }
 
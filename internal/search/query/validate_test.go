@@ -109,6 +109,19 @@ func TestAndOrQuery_Validation(t *testing.T) {
 			want:       "this structural search query specifies `type:` and is not supported. Structural search syntax only applies to searching file contents and is not currently supported for diff searches",
 			searchType: SearchTypeStructural,
 		},
+		{
+			input:      "foo rewrite:bar",
+			want:       "rewrite: requires a structural or regular expression search pattern. Add patterntype:structural or patterntype:regexp to the query",
+			searchType: SearchTypeLiteral,
+		},
+		{
+			input: "-content:foo rewrite:bar",
+			want:  "rewrite: requires a structural or regular expression search pattern. Add patterntype:structural or patterntype:regexp to the query",
+		},
+		{
+			input: "foo rewrite:a rewrite:b",
+			want:  `field "rewrite" may not be used more than once`,
+		},
 	}
 	for _, c := range cases {
 		t.Run("validate and/or query", func(t *testing.T) {
@@ -107,6 +107,9 @@ func ToTextPatternInfo(q query.Basic, p Protocol, transform query.BasicPass) *Te
 	filesReposMustInclude, filesReposMustExclude := IncludeExcludeValues(q, query.FieldRepoHasFile)
 	selector, _ := filter.SelectPathFromString(q.FindValue(query.FieldSelect)) // Invariant: select is validated
 	count := count(q, p)
+	if q.FindValue(query.FieldFileHasOwner) != "" && count < OwnerFilterFileMatchLimit {
+		count = OwnerFilterFileMatchLimit
+	}
 
 	// Ugly assumption: for a literal search, the IsRegexp member of
 	// TextPatternInfo must be set true. The logic assumes that a literal
@@ -134,6 +137,14 @@ func ToTextPatternInfo(q query.Basic, p Protocol, transform query.BasicPass) *Te
 		negated = p.Negated
 	}
 
+	index := q.Index()
+	rewrite := q.FindValue(query.FieldRewrite)
+	if rewrite != "" && !q.IsStructural() {
+		// Searcher computes the rewrites of regexp patterns, so indexed
+		// search can't be used.
+		index = query.No
+	}
+
 	return &TextPatternInfo{
 		// Values dependent on pattern atom.
 		IsRegExp:        isRegexp,
@@ -151,7 +162,8 @@ func ToTextPatternInfo(q query.Basic, p Protocol, transform query.BasicPass) *Te
 		Languages:                    langInclude,
 		PathPatternsAreCaseSensitive: q.IsCaseSensitive(),
 		CombyRule:                    q.FindValue(query.FieldCombyRule),
-		Index:                        q.Index(),
+		RewriteTemplate:              rewrite,
+		Index:                        index,
 		Select:                       selector,
 	}
 }
@@ -307,6 +307,14 @@ func (r *Resolver) Resolve(ctx context.Context, op search.RepoOptions) (Resolved
 		tr.LazyPrintf("repohascommitafter removed %d repos in %s", before-len(repoRevs), time.Since(start))
 	}
 
+	if op.RevAtTime != "" && err == nil {
+		start := time.Now()
+		var missing []*search.RepositoryRevisions
+		repoRevs, missing, err = resolveRevsAtTime(ctx, repoRevs, op.RevAtTime)
+		missingRepoRevs = append(missingRepoRevs, missing...)
+		tr.LazyPrintf("rev:at.time resolved %d repos in %s", len(repoRevs), time.Since(start))
+	}
+
 	return Resolved{
 		RepoRevs:        repoRevs,
 		MissingRepoRevs: missingRepoRevs,
@@ -617,6 +625,75 @@ func filterRepoHasCommitAfter(ctx context.Context, revisions []*search.Repositor
 	return pass, err
 }
 
+// resolveRevsAtTime replaces each revision with the last commit of the revision
+// committed before date, which implements rev:at.time(...). Revisions without
+// such a commit are returned as missing.
+func resolveRevsAtTime(ctx context.Context, revisions []*search.RepositoryRevisions, date string) (resolved, missing []*search.RepositoryRevisions, err error) {
+	var (
+		mut sync.Mutex
+		run = parallel.NewRun(128)
+	)
+
+	for _, revs := range revisions {
+		run.Acquire()
+
+		revs := revs
+		goroutine.Go(func() {
+			defer run.Release()
+
+			var specifiers, missingSpecifiers []search.RevisionSpecifier
+			for _, rev := range revs.Revs {
+				if rev.RefGlob != "" || rev.ExcludeRefGlob != "" {
+					// Ref globs don't resolve to a single commit.
+					missingSpecifiers = append(missingSpecifiers, search.RevisionSpecifier{RevSpec: revAtTimeSpec(date, rev.String())})
+					continue
+				}
+
+				commit, err := git.LastCommitBefore(ctx, revs.GitserverRepo(), date, rev.RevSpec)
+				if err != nil && !errors.HasType(err, &gitserver.RevisionNotFoundError{}) && !vcs.IsRepoNotExist(err) {
+					run.Error(err)
+					continue
+				}
+				if commit == nil {
+					missingSpecifiers = append(missingSpecifiers, search.RevisionSpecifier{RevSpec: revAtTimeSpec(date, rev.RevSpec)})
+					continue
+				}
+				specifiers = append(specifiers, search.RevisionSpecifier{RevSpec: string(commit.ID)})
+			}
+
+			mut.Lock()
+			defer mut.Unlock()
+			if len(specifiers) > 0 {
+				resolved = append(resolved, &search.RepositoryRevisions{Repo: revs.Repo, Revs: specifiers})
+			}
+			if len(missingSpecifiers) > 0 {
+				missing = append(missing, &search.RepositoryRevisions{Repo: revs.Repo, Revs: missingSpecifiers})
+			}
+		})
+	}
+
+	err = run.Wait()
+
+	// Preserve the order of revisions, which is the rank of the repositories.
+	rank := make(map[api.RepoID]int, len(revisions))
+	for i, revs := range revisions {
+		rank[revs.Repo.ID] = i
+	}
+	sort.Slice(resolved, func(i, j int) bool { return rank[resolved[i].Repo.ID] < rank[resolved[j].Repo.ID] })
+	sort.Slice(missing, func(i, j int) bool { return rank[missing[i].Repo.ID] < rank[missing[j].Repo.ID] })
+
+	return resolved, missing, err
+}
+
+// revAtTimeSpec formats a revision that couldn't be resolved by rev:at.time(...)
+// the way it is written in the query.
+func revAtTimeSpec(date, rev string) string {
+	if rev == "" {
+		return fmt.Sprintf("at.time(%s)", date)
+	}
+	return fmt.Sprintf("at.time(%s, %s)", date, rev)
+}
+
 func optimizeRepoPatternWithHeuristics(repoPattern string) string {
 	if envvar.SourcegraphDotComMode() && (strings.HasPrefix(repoPattern, "github.com") || strings.HasPrefix(repoPattern, `github\.com`)) {
 		repoPattern = "^" + repoPattern
@@ -181,6 +181,106 @@ func TestRevisionValidation(t *testing.T) {
 	}
 }
 
+func TestRevAtTime(t *testing.T) {
+	git.Mocks.ResolveRevision = func(spec string, opt git.ResolveRevisionOptions) (api.CommitID, error) {
+		switch spec {
+		case "HEAD":
+			return "head", nil
+		case "main":
+			return "main", nil
+		case "deleted":
+			// The ref is deleted after it is resolved.
+			return "deleted", nil
+		}
+		return "", &gitserver.RevisionNotFoundError{Repo: "repoFoo", Spec: spec}
+	}
+	git.Mocks.Commits = func(repo api.RepoName, opt git.CommitsOptions) ([]*git.Commit, error) {
+		if opt.N != 1 || opt.Before != "2021-01-01" {
+			t.Fatalf("unexpected options %+v", opt)
+		}
+		if opt.Range == "deleted" {
+			return nil, &gitserver.RevisionNotFoundError{Repo: repo, Spec: opt.Range}
+		}
+		if repo == "repoOld" {
+			return nil, nil
+		}
+		return []*git.Commit{{ID: api.CommitID(string(repo) + "-" + opt.Range + "-2020")}}, nil
+	}
+	database.Mocks.Repos.ListRepoNames = func(ctx context.Context, opts database.ReposListOptions) ([]types.RepoName, error) {
+		return []types.RepoName{{ID: 1, Name: "repoFoo"}, {ID: 2, Name: "repoOld"}}, nil
+	}
+	t.Cleanup(func() {
+		git.Mocks.ResolveRevision = nil
+		git.Mocks.Commits = nil
+		database.Mocks.Repos.ListRepoNames = nil
+	})
+
+	tests := []struct {
+		repoFilters              []string
+		wantRepoRevs             []*search.RepositoryRevisions
+		wantMissingRepoRevisions []*search.RepositoryRevisions
+	}{
+		{
+			repoFilters: []string{"repo"},
+			wantRepoRevs: []*search.RepositoryRevisions{{
+				Repo: types.RepoName{ID: 1, Name: "repoFoo"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "repoFoo-head-2020"}},
+			}},
+			wantMissingRepoRevisions: []*search.RepositoryRevisions{{
+				Repo: types.RepoName{ID: 2, Name: "repoOld"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "at.time(2021-01-01)"}},
+			}},
+		},
+		{
+			repoFilters: []string{"repo@main"},
+			wantRepoRevs: []*search.RepositoryRevisions{{
+				Repo: types.RepoName{ID: 1, Name: "repoFoo"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "repoFoo-main-2020"}},
+			}},
+			wantMissingRepoRevisions: []*search.RepositoryRevisions{{
+				Repo: types.RepoName{ID: 2, Name: "repoOld"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "at.time(2021-01-01, main)"}},
+			}},
+		},
+		{
+			repoFilters: []string{"repo@deleted"},
+			wantMissingRepoRevisions: []*search.RepositoryRevisions{{
+				Repo: types.RepoName{ID: 1, Name: "repoFoo"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "at.time(2021-01-01, deleted)"}},
+			}, {
+				Repo: types.RepoName{ID: 2, Name: "repoOld"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "at.time(2021-01-01, deleted)"}},
+			}},
+		},
+		{
+			repoFilters: []string{"repo@nope"},
+			wantMissingRepoRevisions: []*search.RepositoryRevisions{{
+				Repo: types.RepoName{ID: 1, Name: "repoFoo"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "nope"}},
+			}, {
+				Repo: types.RepoName{ID: 2, Name: "repoOld"},
+				Revs: []search.RevisionSpecifier{{RevSpec: "nope"}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoFilters[0], func(t *testing.T) {
+			op := search.RepoOptions{RepoFilters: tt.repoFilters, RevAtTime: "2021-01-01"}
+			resolved, err := (&Resolver{}).Resolve(context.Background(), op)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantRepoRevs, resolved.RepoRevs); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.wantMissingRepoRevisions, resolved.MissingRepoRevs); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
 // TestSearchRevspecs tests a repository name against a list of
 // repository specs with optional revspecs, and determines whether
 // we get the expected error, list of matching rev specs, or list
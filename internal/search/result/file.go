@@ -46,6 +46,14 @@ type FileMatch struct {
 	LineMatches []*LineMatch
 	Symbols     []*SymbolMatch `json:"-"`
 
+	// Owners are the owners of the file according to the CODEOWNERS file of
+	// its repository. They are only resolved by searches filtering on owners.
+	Owners []string `json:"-"`
+
+	// RewriteDiff is the unified diff of applying the rewrite: template of
+	// the query to the file. It is empty if the query has no rewrite: field.
+	RewriteDiff string `json:"-"`
+
 	LimitHit bool
 }
 
@@ -111,6 +119,12 @@ func (fm *FileMatch) Select(selectPath filter.SelectPath) Match {
 func (fm *FileMatch) AppendMatches(src *FileMatch) {
 	fm.LineMatches = append(fm.LineMatches, src.LineMatches...)
 	fm.Symbols = append(fm.Symbols, src.Symbols...)
+	if fm.Owners == nil {
+		fm.Owners = src.Owners
+	}
+	if fm.RewriteDiff == "" {
+		fm.RewriteDiff = src.RewriteDiff
+	}
 	fm.LimitHit = fm.LimitHit || src.LimitHit
 }
 
@@ -30,6 +30,9 @@ type Aggregator struct {
 	parentStream streaming.Sender
 	db           dbutil.DB
 
+	// filters are applied to the results of every event, see AddFilter.
+	filters []func([]result.Match) ([]result.Match, error)
+
 	mu      sync.Mutex
 	results []result.Match
 	stats   streaming.Stats
@@ -45,7 +48,22 @@ func (a *Aggregator) Get() ([]result.Match, streaming.Stats, *multierror.Error)
 	return a.results, a.stats, a.errors
 }
 
+// AddFilter adds a filter that is applied to the results of every event sent to
+// the aggregator before they are sent to the parent stream or aggregated. The
+// errors returned by the filter are aggregated like search errors. It must be
+// called before any search is started.
+func (a *Aggregator) AddFilter(filter func([]result.Match) ([]result.Match, error)) {
+	a.filters = append(a.filters, filter)
+}
+
 func (a *Aggregator) Send(event streaming.SearchEvent) {
+	for _, filter := range a.filters {
+		var err error
+		if event.Results, err = filter(event.Results); err != nil {
+			a.Error(err)
+		}
+	}
+
 	if a.parentStream != nil {
 		a.parentStream.Send(event)
 	}
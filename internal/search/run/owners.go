@@ -0,0 +1,126 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// OwnersError is returned when the owners of the files of a repository can't
+// be resolved, so that its matches are missing from the results of a query
+// filtering on owners.
+type OwnersError struct {
+	Repo   api.RepoName
+	Commit api.CommitID
+	Err    error
+}
+
+func (e *OwnersError) Error() string {
+	return fmt.Sprintf("resolving code owners of %s@%s: %s", e.Repo, e.Commit, e.Err)
+}
+
+func (e *OwnersError) Unwrap() error { return e.Err }
+
+// OwnerFilter filters file matches by the owners declared for them in the
+// CODEOWNERS file of their repository at the searched commit. It implements the
+// filehasowner parameters that file:has.owner(...) predicates are replaced with.
+type OwnerFilter struct {
+	include  []string
+	exclude  []string
+	resolver *codeowners.Resolver
+
+	mu sync.Mutex
+	// failed are the repositories and commits whose owners couldn't be
+	// resolved and have already been reported.
+	failed map[ownersKey]bool
+}
+
+type ownersKey struct {
+	repo   api.RepoName
+	commit api.CommitID
+}
+
+// NewOwnerFilter returns an OwnerFilter for the filehasowner parameters of q, or
+// nil if q doesn't filter on owners.
+func NewOwnerFilter(q query.Q) *OwnerFilter {
+	include, exclude := q.StringValues(query.FieldFileHasOwner)
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	return &OwnerFilter{
+		include:  include,
+		exclude:  exclude,
+		resolver: codeowners.NewResolver(),
+		failed:   map[ownersKey]bool{},
+	}
+}
+
+// Filter returns the file matches of matches whose owners satisfy the filter and
+// sets their Owners. Other types of matches are removed since only files have
+// owners. The matches whose owners can't be resolved are removed too, and an
+// *OwnersError is returned once for each of their repositories and commits.
+func (f *OwnerFilter) Filter(ctx context.Context, matches []result.Match) ([]result.Match, error) {
+	var errs *multierror.Error
+	filtered := matches[:0]
+	for _, match := range matches {
+		fm, ok := match.(*result.FileMatch)
+		if !ok {
+			continue
+		}
+
+		owners, err := f.resolver.Owners(ctx, fm.Repo.Name, fm.CommitID, fm.Path)
+		if err != nil {
+			if ctx.Err() == nil && f.reportFailure(ownersKey{repo: fm.Repo.Name, commit: fm.CommitID}) {
+				errs = multierror.Append(errs, &OwnersError{Repo: fm.Repo.Name, Commit: fm.CommitID, Err: err})
+			}
+			continue
+		}
+
+		if f.matches(owners) {
+			fm.Owners = owners
+			filtered = append(filtered, fm)
+		}
+	}
+	return filtered, errs.ErrorOrNil()
+}
+
+// reportFailure returns true if the failure to resolve the owners of the
+// repository and commit of key hasn't been reported yet.
+func (f *OwnerFilter) reportFailure(key ownersKey) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failed[key] {
+		return false
+	}
+	f.failed[key] = true
+	return true
+}
+
+// matches returns true if owners satisfy all the filehasowner parameters.
+func (f *OwnerFilter) matches(owners []string) bool {
+	for _, owner := range f.include {
+		if !hasOwner(owners, owner) {
+			return false
+		}
+	}
+	for _, owner := range f.exclude {
+		if hasOwner(owners, owner) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasOwner(owners []string, owner string) bool {
+	if owner == query.AnyOwner {
+		return len(owners) > 0
+	}
+	return codeowners.MatchOwner(owners, owner)
+}
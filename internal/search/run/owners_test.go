@@ -0,0 +1,180 @@
+package run
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestOwnerFilter(t *testing.T) {
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if commit == "c1" && name == "CODEOWNERS" {
+			return []byte("*.go @gophers\n/web/ @acme/frontend alice@example.com\n/web/vendor/\n"), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	t.Cleanup(func() { git.Mocks.ReadFile = nil })
+
+	fileMatch := func(path string) *result.FileMatch {
+		return &result.FileMatch{File: result.File{
+			Repo:     types.RepoName{ID: 1, Name: "repo"},
+			CommitID: "c1",
+			Path:     path,
+		}}
+	}
+	matches := func() []result.Match {
+		return []result.Match{
+			fileMatch("main.go"),
+			fileMatch("web/index.ts"),
+			fileMatch("web/vendor/lib.js"),
+			fileMatch("README.md"),
+			&result.RepoMatch{Name: "repo", ID: 1},
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  map[string][]string
+	}{
+		{
+			query: "file:has.owner(@gophers)",
+			want:  map[string][]string{"main.go": {"@gophers"}},
+		},
+		{
+			query: "file:has.owner(ALICE@example.com)",
+			want:  map[string][]string{"web/index.ts": {"@acme/frontend", "alice@example.com"}},
+		},
+		{
+			query: "file:has.owner()",
+			want: map[string][]string{
+				"main.go":      {"@gophers"},
+				"web/index.ts": {"@acme/frontend", "alice@example.com"},
+			},
+		},
+		{
+			query: "-file:has.owner()",
+			want: map[string][]string{
+				"web/vendor/lib.js": nil,
+				"README.md":         nil,
+			},
+		},
+		{
+			query: "file:has.owner() -file:has.owner(gophers)",
+			want:  map[string][]string{"web/index.ts": {"@acme/frontend", "alice@example.com"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			plan, err := query.Pipeline(query.InitLiteral(tc.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f := NewOwnerFilter(plan.ToParseTree())
+			if f == nil {
+				t.Fatal("expected owner filter")
+			}
+
+			filtered, err := f.Filter(context.Background(), matches())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := map[string][]string{}
+			for _, m := range filtered {
+				fm := m.(*result.FileMatch)
+				got[fm.Path] = fm.Owners
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("filtered matches mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	plan, err := query.Pipeline(query.InitLiteral("file:main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := NewOwnerFilter(plan.ToParseTree()); f != nil {
+		t.Fatalf("unexpected owner filter %+v", f)
+	}
+}
+
+func TestOwnerFilter_Error(t *testing.T) {
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if commit == "bad" {
+			return nil, errors.New("gitserver unavailable")
+		}
+		if name == "CODEOWNERS" {
+			return []byte("*.go @gophers\n"), nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	t.Cleanup(func() { git.Mocks.ReadFile = nil })
+
+	fileMatch := func(commit api.CommitID, path string) *result.FileMatch {
+		return &result.FileMatch{File: result.File{
+			Repo:     types.RepoName{ID: 1, Name: "repo"},
+			CommitID: commit,
+			Path:     path,
+		}}
+	}
+	matches := func() []result.Match {
+		return []result.Match{
+			fileMatch("good", "main.go"),
+			fileMatch("bad", "main.go"),
+			fileMatch("bad", "README.md"),
+		}
+	}
+
+	t.Run("filter", func(t *testing.T) {
+		plan, err := query.Pipeline(query.InitLiteral("-file:has.owner()"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := NewOwnerFilter(plan.ToParseTree())
+
+		// The matches of the commit whose owners can't be resolved are removed,
+		// and the error is returned once.
+		filtered, err := f.Filter(context.Background(), matches())
+		if len(filtered) != 0 {
+			t.Errorf("got %d matches, want none", len(filtered))
+		}
+		var oErr *OwnersError
+		if !errors.As(err, &oErr) || oErr.Commit != "bad" {
+			t.Fatalf("got error %v, want OwnersError for commit bad", err)
+		}
+		if merr, ok := err.(*multierror.Error); !ok || len(merr.Errors) != 1 {
+			t.Errorf("got error %v, want a single error", err)
+		}
+
+		if _, err := f.Filter(context.Background(), matches()); err != nil {
+			t.Errorf("got error %v for an already reported commit", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		plan, err := query.Pipeline(query.InitLiteral("-file:has.owner()"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := NewOwnerFilter(plan.ToParseTree())
+
+		// Errors caused by the cancellation of the search aren't reported.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := f.Filter(ctx, matches()); err != nil {
+			t.Errorf("got error %v, want none", err)
+		}
+	})
+}
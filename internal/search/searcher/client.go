@@ -65,6 +65,7 @@ func Search(ctx context.Context, searcherURLs *endpoint.Map, repo api.RepoName,
 		"FetchTimeout":    []string{fetchTimeout.String()},
 		"Languages":       p.Languages,
 		"CombyRule":       []string{p.CombyRule},
+		"RewriteTemplate": []string{p.RewriteTemplate},
 
 		"PathPatternsAreRegExps": []string{"true"},
 		"IndexerEndpoints":       indexerEndpoints,
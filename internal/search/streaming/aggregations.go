@@ -0,0 +1,212 @@
+package streaming
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+)
+
+// AggregationMode is the property by which search aggregations group results.
+type AggregationMode string
+
+const (
+	// AggregationModeRepo counts the matches in each repository.
+	AggregationModeRepo AggregationMode = "repo"
+
+	// AggregationModePath counts the matches in each file. Files are
+	// labeled with their repository, so that equal paths in different
+	// repositories are counted separately.
+	AggregationModePath AggregationMode = "path"
+
+	// AggregationModeAuthor counts the commits and diffs by each author.
+	AggregationModeAuthor AggregationMode = "author"
+
+	// AggregationModeCaptureGroup counts the values of a capture group of
+	// the regexp pattern of the query on the matched lines. This is the
+	// first named capture group, or else the first capture group.
+	AggregationModeCaptureGroup AggregationMode = "capture_group"
+)
+
+// ParseAggregationMode returns the AggregationMode named by s.
+func ParseAggregationMode(s string) (AggregationMode, error) {
+	switch m := AggregationMode(s); m {
+	case AggregationModeRepo, AggregationModePath, AggregationModeAuthor, AggregationModeCaptureGroup:
+		return m, nil
+	}
+	return "", errors.Errorf("invalid aggregation mode %q, expected one of repo, path, author or capture_group", s)
+}
+
+// approximateStatus are the repository statuses which mean that not all
+// results of a repository were aggregated.
+const approximateStatus = search.RepoStatusCloning | search.RepoStatusMissing | search.RepoStatusTimedout | search.RepoStatusLimitHit
+
+// SearchAggregations groups and counts the results of a search by an
+// AggregationMode. Unlike SearchFilters it keeps a count for every group, so
+// it should only be used for searches which were asked to aggregate.
+type SearchAggregations struct {
+	mode AggregationMode
+
+	// captureGroup is the pattern of the query for AggregationModeCaptureGroup
+	// and captureIndex the index of the capture group which is counted.
+	captureGroup *regexp.Regexp
+	captureIndex int
+
+	counts      map[string]int
+	approximate bool
+
+	// Dirty is true if the counts changed since the last call to Compute.
+	Dirty bool
+}
+
+// NewSearchAggregations returns SearchAggregations which groups the results of
+// plan by mode. It returns an error if the results of plan can't be grouped by
+// mode.
+func NewSearchAggregations(mode AggregationMode, plan query.Plan) (*SearchAggregations, error) {
+	s := &SearchAggregations{
+		mode:   mode,
+		counts: make(map[string]int),
+	}
+	if mode != AggregationModeCaptureGroup {
+		return s, nil
+	}
+
+	// The capture group is only well defined for a single regexp pattern,
+	// since the pattern is applied to the matched lines again.
+	if len(plan) != 1 || !plan[0].IsRegexp() {
+		return nil, errors.New("capture_group aggregations require a query with a single regexp pattern")
+	}
+	pattern := plan[0].Pattern.(query.Pattern)
+	if pattern.Negated {
+		return nil, errors.New("capture_group aggregations require a pattern which is not negated")
+	}
+
+	expr := pattern.Value
+	if !plan[0].IsCaseSensitive() {
+		expr = "(?i:" + expr + ")"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if re.NumSubexp() == 0 {
+		return nil, errors.Errorf("capture_group aggregations require a pattern with a capture group, like %q", "foo\\((\\w+)\\)")
+	}
+	s.captureGroup = re
+	s.captureIndex = 1
+	// Patterns separated by whitespace are joined into groups like
+	// (foo).*?(bar), so a named group is preferred if there is one.
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			s.captureIndex = i
+			break
+		}
+	}
+	return s, nil
+}
+
+// Update the counts for the results in event.
+func (s *SearchAggregations) Update(event SearchEvent) {
+	if event.Stats.IsLimitHit || event.Stats.Status.Any(approximateStatus) {
+		s.approximate = true
+	}
+
+	for _, match := range event.Results {
+		switch v := match.(type) {
+		case *result.FileMatch:
+			if v.LimitHit {
+				s.approximate = true
+			}
+			switch s.mode {
+			case AggregationModeRepo:
+				s.add(string(v.Repo.Name), v.ResultCount())
+			case AggregationModePath:
+				s.add(fmt.Sprintf("%s/%s", v.Repo.Name, v.Path), v.ResultCount())
+			case AggregationModeCaptureGroup:
+				for _, lm := range v.LineMatches {
+					for _, m := range s.captureGroup.FindAllStringSubmatch(lm.Preview, -1) {
+						if value := m[s.captureIndex]; value != "" {
+							s.add(value, 1)
+						}
+					}
+				}
+			}
+		case *result.RepoMatch:
+			if s.mode == AggregationModeRepo {
+				s.add(string(v.Name), 1)
+			}
+		case *result.CommitMatch:
+			switch s.mode {
+			case AggregationModeRepo:
+				s.add(string(v.Repo.Name), v.ResultCount())
+			case AggregationModeAuthor:
+				s.add(fmt.Sprintf("%s <%s>", v.Commit.Author.Name, v.Commit.Author.Email), 1)
+			}
+		}
+	}
+}
+
+func (s *SearchAggregations) add(label string, count int) {
+	s.counts[label] += count
+	s.Dirty = true
+}
+
+// Aggregation is the count of a group of results.
+type Aggregation struct {
+	Label string
+	Count int
+}
+
+// Aggregations are the counts computed by SearchAggregations.
+type Aggregations struct {
+	Mode AggregationMode
+
+	// Groups are the groups with the highest counts, in descending order of
+	// their counts.
+	Groups []Aggregation
+
+	// OtherGroupCount is the number of groups which are not in Groups, and
+	// OtherResultCount is the sum of their counts.
+	OtherGroupCount  int
+	OtherResultCount int
+
+	// Approximate is true if some results were not aggregated, e.g. because
+	// a repository timed out or the search hit a limit.
+	Approximate bool
+}
+
+// Compute returns the limit groups with the highest counts. If limit is not
+// positive all groups are returned.
+func (s *SearchAggregations) Compute(limit int) *Aggregations {
+	s.Dirty = false
+
+	groups := make([]Aggregation, 0, len(s.counts))
+	for label, count := range s.counts {
+		groups = append(groups, Aggregation{Label: label, Count: count})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Label < groups[j].Label
+	})
+
+	a := &Aggregations{
+		Mode:        s.mode,
+		Groups:      groups,
+		Approximate: s.approximate,
+	}
+	if limit > 0 && len(groups) > limit {
+		a.Groups = groups[:limit]
+		for _, g := range groups[limit:] {
+			a.OtherGroupCount++
+			a.OtherResultCount += g.Count
+		}
+	}
+	return a
+}
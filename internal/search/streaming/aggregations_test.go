@@ -0,0 +1,195 @@
+package streaming
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func aggregationFileMatch(repo, path string, lines ...string) *result.FileMatch {
+	fm := &result.FileMatch{File: result.File{Repo: types.RepoName{Name: api.RepoName(repo)}, Path: path}}
+	for _, l := range lines {
+		fm.LineMatches = append(fm.LineMatches, &result.LineMatch{
+			Preview:          l,
+			OffsetAndLengths: [][2]int32{{0, int32(len(l))}},
+		})
+	}
+	return fm
+}
+
+func aggregationCommitMatch(repo, author string) *result.CommitMatch {
+	return &result.CommitMatch{
+		Repo:   types.RepoName{Name: api.RepoName(repo)},
+		Commit: git.Commit{Author: git.Signature{Name: author, Email: author + "@example.com"}},
+	}
+}
+
+func TestSearchAggregations(t *testing.T) {
+	results := []result.Match{
+		aggregationFileMatch("a", "main.go", `import "fmt"`, `import "os"`),
+		aggregationFileMatch("a", "util.go", `import "fmt"`),
+		aggregationFileMatch("b", "main.go", `import "FMT"`),
+		&result.RepoMatch{Name: "c"},
+		aggregationCommitMatch("b", "alice"),
+		aggregationCommitMatch("c", "alice"),
+		aggregationCommitMatch("c", "bob"),
+	}
+
+	plan, err := query.Pipeline(query.InitRegexp(`import\s"(\w+)"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		mode AggregationMode
+		want []Aggregation
+	}{{
+		mode: AggregationModeRepo,
+		want: []Aggregation{{"a", 3}, {"b", 2}, {"c", 3}},
+	}, {
+		mode: AggregationModePath,
+		want: []Aggregation{{"a/main.go", 2}, {"a/util.go", 1}, {"b/main.go", 1}},
+	}, {
+		mode: AggregationModeAuthor,
+		want: []Aggregation{{"alice <alice@example.com>", 2}, {"bob <bob@example.com>", 1}},
+	}, {
+		mode: AggregationModeCaptureGroup,
+		want: []Aggregation{{"fmt", 2}, {"FMT", 1}, {"os", 1}},
+	}} {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			s, err := NewSearchAggregations(tc.mode, plan)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.Update(SearchEvent{Results: results})
+			if !s.Dirty {
+				t.Error("expected aggregations to be dirty after update")
+			}
+
+			got := s.Compute(0)
+			if s.Dirty {
+				t.Error("expected aggregations not to be dirty after compute")
+			}
+			if got.Approximate {
+				t.Error("expected exact aggregations")
+			}
+			if diff := cmp.Diff(tc.want, got.Groups, cmp.Transformer("sort", sortAggregationsByLabel)); diff != "" {
+				t.Errorf("unexpected groups (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func sortAggregationsByLabel(groups []Aggregation) map[string]int {
+	m := make(map[string]int, len(groups))
+	for _, g := range groups {
+		m[g.Label] = g.Count
+	}
+	return m
+}
+
+func TestSearchAggregations_Compute(t *testing.T) {
+	s, _ := NewSearchAggregations(AggregationModeRepo, nil)
+	s.Update(SearchEvent{Results: []result.Match{
+		aggregationFileMatch("a", "1", "x"),
+		aggregationFileMatch("b", "1", "x", "x", "x"),
+		aggregationFileMatch("c", "1", "x", "x"),
+		aggregationFileMatch("d", "1", "x", "x"),
+		aggregationFileMatch("e", "1", "x"),
+	}})
+
+	want := &Aggregations{
+		Mode:             AggregationModeRepo,
+		Groups:           []Aggregation{{"b", 3}, {"c", 2}, {"d", 2}},
+		OtherGroupCount:  2,
+		OtherResultCount: 2,
+	}
+	if diff := cmp.Diff(want, s.Compute(3)); diff != "" {
+		t.Errorf("unexpected aggregations (-want +got):\n%s", diff)
+	}
+}
+
+func TestSearchAggregations_Approximate(t *testing.T) {
+	limitHit := aggregationFileMatch("a", "1", "x")
+	limitHit.LimitHit = true
+
+	var timedout search.RepoStatusMap
+	timedout.Update(1, search.RepoStatusTimedout)
+
+	for name, event := range map[string]SearchEvent{
+		"stats limit hit": {Stats: Stats{IsLimitHit: true}},
+		"repo timed out":  {Stats: Stats{Status: timedout}},
+		"file limit hit":  {Results: []result.Match{limitHit}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			s, _ := NewSearchAggregations(AggregationModeRepo, nil)
+			s.Update(SearchEvent{Results: []result.Match{aggregationFileMatch("b", "1", "x")}})
+			if s.Compute(0).Approximate {
+				t.Fatal("expected exact aggregations")
+			}
+			s.Update(event)
+			if !s.Compute(0).Approximate {
+				t.Fatal("expected approximate aggregations")
+			}
+		})
+	}
+}
+
+func TestNewSearchAggregations_CaptureGroup(t *testing.T) {
+	for _, tc := range []struct {
+		query     string
+		wantError bool
+	}{
+		{query: `foo\((\w+)\)`},
+		{query: `foo\((\w+)\) case:yes`},
+		{query: `foo\(\w+\)`, wantError: true},
+		{query: `(a) or (b)`, wantError: true},
+	} {
+		plan, err := query.Pipeline(query.InitRegexp(tc.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = NewSearchAggregations(AggregationModeCaptureGroup, plan)
+		if (err != nil) != tc.wantError {
+			t.Errorf("%s: unexpected error %v", tc.query, err)
+		}
+	}
+
+	// Named groups are preferred over the groups of joined patterns.
+	plan, err := query.Pipeline(query.InitRegexp(`func (?P<name>\w+)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewSearchAggregations(AggregationModeCaptureGroup, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Update(SearchEvent{Results: []result.Match{aggregationFileMatch("a", "a.go", "func main() {")}})
+	if diff := cmp.Diff([]Aggregation{{"main", 1}}, s.Compute(0).Groups); diff != "" {
+		t.Errorf("unexpected groups (-want +got):\n%s", diff)
+	}
+
+	plan, err = query.Pipeline(query.InitLiteral(`foo(bar)`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSearchAggregations(AggregationModeCaptureGroup, plan); err == nil {
+		t.Error("expected error for literal pattern")
+	}
+}
+
+func TestParseAggregationMode(t *testing.T) {
+	if m, err := ParseAggregationMode("capture_group"); err != nil || m != AggregationModeCaptureGroup {
+		t.Errorf("unexpected mode %q: %v", m, err)
+	}
+	if _, err := ParseAggregationMode("lang"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
@@ -1,10 +1,16 @@
 package streaming
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
+	"github.com/cockroachdb/errors"
 	"github.com/google/go-cmp/cmp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/result"
+	"github.com/sourcegraph/sourcegraph/internal/types"
 )
 
 func TestFilters(t *testing.T) {
@@ -68,3 +74,80 @@ func TestFilters(t *testing.T) {
 		t.Errorf("mismatch (-want +got):\n%s", d)
 	}
 }
+
+func TestSearchFilters_Owners(t *testing.T) {
+	fileMatch := func(path string, owners ...string) *result.FileMatch {
+		return &result.FileMatch{
+			File:   result.File{Repo: types.RepoName{ID: 1, Name: "repo"}, Path: path},
+			Owners: owners,
+		}
+	}
+
+	var s SearchFilters
+	s.Update(SearchEvent{Results: []result.Match{
+		fileMatch("a.go", "@gophers"),
+		fileMatch("web/a.ts", "@acme/frontend", "@gophers"),
+		fileMatch("README"),
+	}})
+
+	var got []string
+	for _, f := range s.Compute() {
+		if f.Kind == "owner" {
+			got = append(got, fmt.Sprintf("%s %s %d", f.Value, f.Label, f.Count))
+		}
+	}
+
+	want := []string{
+		"file:has.owner(@gophers) @gophers 2",
+		"file:has.owner(@acme/frontend) @acme/frontend 1",
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("mismatch (-want +got):\n%s", d)
+	}
+}
+
+func TestSearchFilters_AddOwners(t *testing.T) {
+	fileMatch := func(commit api.CommitID, path string) *result.FileMatch {
+		return &result.FileMatch{File: result.File{
+			Repo:     types.RepoName{ID: 1, Name: "repo"},
+			CommitID: commit,
+			Path:     path,
+		}}
+	}
+
+	s := SearchFilters{ResolveOwners: true}
+	matches := []result.Match{fileMatch("bad", "a.go"), fileMatch("bad", "b.go")}
+	for i := 0; i < maxOwnerFacetLookups; i++ {
+		matches = append(matches, fileMatch(api.CommitID(fmt.Sprintf("c%d", i)), "a.go"))
+	}
+	s.Update(SearchEvent{Results: matches})
+
+	lookups := map[api.CommitID]int{}
+	s.AddOwners(context.Background(), func(_ context.Context, _ api.RepoName, commit api.CommitID, _ string) ([]string, error) {
+		lookups[commit]++
+		if commit == "bad" {
+			return nil, errors.New("gitserver unavailable")
+		}
+		return []string{"@gophers"}, nil
+	})
+
+	// Only the files of the first maxOwnerFacetLookups commits are looked up,
+	// and a commit whose owners can't be resolved is looked up once.
+	if len(lookups) != maxOwnerFacetLookups {
+		t.Errorf("got lookups for %d commits, want %d", len(lookups), maxOwnerFacetLookups)
+	}
+	if lookups["bad"] != 1 {
+		t.Errorf("got %d lookups for the failing commit, want 1", lookups["bad"])
+	}
+
+	var got []string
+	for _, f := range s.Compute() {
+		if f.Kind == "owner" {
+			got = append(got, fmt.Sprintf("%s %d", f.Value, f.Count))
+		}
+	}
+	want := []string{fmt.Sprintf("file:has.owner(@gophers) %d", maxOwnerFacetLookups-1)}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("mismatch (-want +got):\n%s", d)
+	}
+}
@@ -28,12 +28,14 @@ func NewRequest(baseURL string, query string) (*http.Request, error) {
 // support streams which are generated by Sourcegraph. IE this is not a fully
 // compliant Server Sent Events decoder.
 type Decoder struct {
-	OnProgress func(*api.Progress)
-	OnMatches  func([]EventMatch)
-	OnFilters  func([]*EventFilter)
-	OnAlert    func(*EventAlert)
-	OnError    func(*EventError)
-	OnUnknown  func(event, data []byte)
+	OnProgress     func(*api.Progress)
+	OnMatches      func([]EventMatch)
+	OnFilters      func([]*EventFilter)
+	OnAggregations func(*EventAggregations)
+	OnRewrites     func([]*EventRewrite)
+	OnAlert        func(*EventAlert)
+	OnError        func(*EventError)
+	OnUnknown      func(event, data []byte)
 }
 
 func (rr Decoder) ReadAll(r io.Reader) error {
@@ -108,6 +110,24 @@ func (rr Decoder) ReadAll(r io.Reader) error {
 				return errors.Errorf("failed to decode filters payload: %w", err)
 			}
 			rr.OnFilters(d)
+		} else if bytes.Equal(event, []byte("aggregations")) {
+			if rr.OnAggregations == nil {
+				continue
+			}
+			var d EventAggregations
+			if err := json.Unmarshal(data, &d); err != nil {
+				return errors.Errorf("failed to decode aggregations payload: %w", err)
+			}
+			rr.OnAggregations(&d)
+		} else if bytes.Equal(event, []byte("rewrites")) {
+			if rr.OnRewrites == nil {
+				continue
+			}
+			var d []*EventRewrite
+			if err := json.Unmarshal(data, &d); err != nil {
+				return errors.Errorf("failed to decode rewrites payload: %w", err)
+			}
+			rr.OnRewrites(d)
 		} else if bytes.Equal(event, []byte("alert")) {
 			if rr.OnAlert == nil {
 				continue
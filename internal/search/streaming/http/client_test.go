@@ -56,6 +56,20 @@ func TestDecoder(t *testing.T) {
 		}, {
 			Value: "filter-2",
 		}},
+	}, {
+		Name: "aggregations",
+		Value: &EventAggregations{
+			Mode:   "repo",
+			Groups: []EventAggregation{{Label: "test", Count: 2}},
+			Done:   true,
+		},
+	}, {
+		Name: "rewrites",
+		Value: []*EventRewrite{{
+			Path:       "test",
+			Repository: "test",
+			Diff:       "--- a/test\n+++ b/test\n",
+		}},
 	}, {
 		Name: "alert",
 		Value: &EventAlert{
@@ -102,6 +116,12 @@ func TestDecoder(t *testing.T) {
 		OnFilters: func(d []*EventFilter) {
 			got = append(got, Event{Name: "filters", Value: d})
 		},
+		OnAggregations: func(d *EventAggregations) {
+			got = append(got, Event{Name: "aggregations", Value: d})
+		},
+		OnRewrites: func(d []*EventRewrite) {
+			got = append(got, Event{Name: "rewrites", Value: d})
+		},
 		OnAlert: func(d *EventAlert) {
 			got = append(got, Event{Name: "alert", Value: d})
 		},
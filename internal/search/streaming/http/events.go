@@ -23,6 +23,7 @@ type EventContentMatch struct {
 	RepoStars  int      `json:"repoStars,omitempty"`
 	Branches   []string `json:"branches,omitempty"`
 	Version    string   `json:"version,omitempty"`
+	Owners     []string `json:"owners,omitempty"`
 
 	LineMatches []EventLineMatch `json:"lineMatches"`
 }
@@ -42,6 +43,7 @@ type EventPathMatch struct {
 	RepoStars  int      `json:"repoStars,omitempty"`
 	Branches   []string `json:"branches,omitempty"`
 	Version    string   `json:"version,omitempty"`
+	Owners     []string `json:"owners,omitempty"`
 }
 
 func (e *EventPathMatch) eventMatch() {}
@@ -78,6 +80,7 @@ type EventSymbolMatch struct {
 	RepoStars  int      `json:"repoStars,omitempty"`
 	Branches   []string `json:"branches,omitempty"`
 	Version    string   `json:"version,omitempty"`
+	Owners     []string `json:"owners,omitempty"`
 
 	Symbols []Symbol `json:"symbols"`
 }
@@ -121,6 +124,39 @@ type EventFilter struct {
 	Kind     string `json:"kind"`
 }
 
+// EventAggregations are the counts of the results of a search grouped by
+// Mode. They are sent instead of matches when a search is asked to aggregate.
+// Every event contains all counts computed so far, so it replaces the
+// previous one.
+type EventAggregations struct {
+	Mode             string             `json:"mode"`
+	Groups           []EventAggregation `json:"groups"`
+	OtherGroupCount  int                `json:"otherGroupCount"`
+	OtherResultCount int                `json:"otherResultCount"`
+	// Approximate is true if the counts don't include all results, e.g.
+	// because a repository timed out.
+	Approximate bool `json:"approximate"`
+	// Done is true for the counts of the finished search.
+	Done bool `json:"done"`
+}
+
+// EventAggregation is the count of the results of a group.
+type EventAggregation struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// EventRewrite is the diff of applying the rewrite: template of a query to a
+// matched file. Diff is a unified diff with the file names a/Path and b/Path,
+// so the diffs of a search can be concatenated into a patch.
+type EventRewrite struct {
+	Path       string   `json:"name"`
+	Repository string   `json:"repository"`
+	Branches   []string `json:"branches,omitempty"`
+	Version    string   `json:"version,omitempty"`
+	Diff       string   `json:"diff"`
+}
+
 // EventAlert is GQL.SearchAlert. It replaces when sent to match existing
 // behaviour.
 type EventAlert struct {
@@ -1,12 +1,15 @@
 package streaming
 
 import (
+	"context"
 	"fmt"
 	"path"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/inconshreveable/log15"
+
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/inventory"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
@@ -24,7 +27,25 @@ type SearchFilters struct {
 	// Globbing is true if the user has enabled globbing support.
 	Globbing bool
 
+	// ResolveOwners is true if the owners of file matches that weren't resolved
+	// by the search should be resolved for the owner facet, see AddOwners.
+	ResolveOwners bool
+
 	filters filters
+
+	// unresolvedOwners are the file matches of the first maxOwnerFacetLookups
+	// repositories and commits whose owners are resolved by AddOwners.
+	unresolvedOwners []*result.FileMatch
+	ownerCommits     map[ownerCommit]bool
+}
+
+// maxOwnerFacetLookups is the maximum number of CODEOWNERS files that are
+// fetched to show the owners of file matches as a filter facet.
+const maxOwnerFacetLookups = 50
+
+type ownerCommit struct {
+	repo   api.RepoName
+	commit api.CommitID
 }
 
 // commonFileFilters are common filters used. It is used by SearchFilters to
@@ -141,6 +162,11 @@ func (s *SearchFilters) Update(event SearchEvent) {
 			addRepoFilter(v.Repo.Name, v.Repo.ID, rev, lines)
 			addLangFilter(v.Path, lines, v.LimitHit)
 			addFileFilter(v.Path, lines, v.LimitHit)
+			if v.Owners != nil {
+				s.addOwnerFilters(v.Owners, lines, v.LimitHit)
+			} else if s.ResolveOwners {
+				s.addUnresolvedOwners(v)
+			}
 
 			if len(v.Symbols) > 0 {
 				s.filters.Add("type:symbol", "type:symbol", 1, v.LimitHit, "symbol")
@@ -154,6 +180,58 @@ func (s *SearchFilters) Update(event SearchEvent) {
 	}
 }
 
+func (s *SearchFilters) addOwnerFilters(owners []string, lineMatchCount int32, limitHit bool) {
+	for _, owner := range owners {
+		s.filters.Add(fmt.Sprintf(`file:has.owner(%s)`, owner), owner, lineMatchCount, limitHit, "owner")
+	}
+}
+
+func (s *SearchFilters) addUnresolvedOwners(fm *result.FileMatch) {
+	if s.ownerCommits == nil {
+		s.ownerCommits = map[ownerCommit]bool{}
+	}
+	key := ownerCommit{repo: fm.Repo.Name, commit: fm.CommitID}
+	if !s.ownerCommits[key] {
+		if len(s.ownerCommits) >= maxOwnerFacetLookups {
+			return
+		}
+		s.ownerCommits[key] = true
+	}
+	s.unresolvedOwners = append(s.unresolvedOwners, fm)
+}
+
+// AddOwners adds the owner filters of the file matches whose owners weren't
+// resolved by the search, looking up their owners with owners. It is called
+// once the search is done, so that the lookups don't delay the results. Only
+// the file matches of the first maxOwnerFacetLookups repositories and commits
+// are looked up, and the ones whose owners can't be resolved are skipped.
+func (s *SearchFilters) AddOwners(ctx context.Context, owners func(ctx context.Context, repo api.RepoName, commit api.CommitID, path string) ([]string, error)) {
+	if s.filters == nil {
+		s.filters = make(filters)
+	}
+
+	failed := map[ownerCommit]bool{}
+	for _, fm := range s.unresolvedOwners {
+		if ctx.Err() != nil {
+			break
+		}
+		key := ownerCommit{repo: fm.Repo.Name, commit: fm.CommitID}
+		if failed[key] {
+			continue
+		}
+		o, err := owners(ctx, fm.Repo.Name, fm.CommitID, fm.Path)
+		if err != nil {
+			if ctx.Err() == nil {
+				log15.Warn("failed to resolve code owners", "repo", fm.Repo.Name, "commit", fm.CommitID, "error", err)
+			}
+			failed[key] = true
+			continue
+		}
+		s.addOwnerFilters(o, int32(fm.ResultCount()), fm.LimitHit)
+	}
+	s.unresolvedOwners = nil
+}
+
 // Compute returns an ordered slice of Filters to present to the user based on
 // events passed to Next.
 func (s *SearchFilters) Compute() []*Filter {
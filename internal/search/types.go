@@ -180,6 +180,9 @@ type TextPatternInfo struct {
 	IsRegExp        bool
 	IsStructuralPat bool
 	CombyRule       string
+	// RewriteTemplate if non-empty is the rewrite: template of the query.
+	// Searcher computes the diff of applying it to each file match.
+	RewriteTemplate string
 	IsWordMatch     bool
 	IsCaseSensitive bool
 	FileMatchLimit  int32
@@ -220,6 +223,9 @@ func (p *TextPatternInfo) String() string {
 	if p.IsCaseSensitive {
 		args = append(args, "case")
 	}
+	if p.RewriteTemplate != "" {
+		args = append(args, fmt.Sprintf("rewrite:%q", p.RewriteTemplate))
+	}
 	if !p.PatternMatchesContent {
 		args = append(args, "nocontent")
 	}
@@ -266,6 +272,7 @@ type RepoOptions struct {
 	NoArchived         bool
 	OnlyArchived       bool
 	CommitAfter        string
+	RevAtTime          string // search the last commit of each revision before this date
 	OnlyPrivate        bool
 	OnlyPublic         bool
 	Ranked             bool // Return results ordered by rank
@@ -298,6 +305,9 @@ func (op *RepoOptions) String() string {
 	if op.CommitAfter != "" {
 		_, _ = fmt.Fprintf(&b, " CommitAfter=%q", op.CommitAfter)
 	}
+	if op.RevAtTime != "" {
+		_, _ = fmt.Fprintf(&b, " RevAtTime=%q", op.RevAtTime)
+	}
 
 	if op.NoForks {
 		b.WriteString(" NoForks")
@@ -185,6 +185,7 @@ func searchFilesInRepo(ctx context.Context, searcherURLs *endpoint.Map, repo typ
 				InputRev: &rev,
 			},
 			LineMatches: lineMatches,
+			RewriteDiff: fm.Diff,
 			LimitHit:    fm.LimitHit,
 		})
 	}
@@ -0,0 +1,280 @@
+package store
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+)
+
+// ArchiveSeparator separates the path of an archive from the path of a file
+// in the archive in the virtual paths of the files of expanded archives, e.g.
+// "lib/foo.jar!/com/x/Y.java".
+const ArchiveSeparator = "!/"
+
+// maxArchiveDepth is the number of levels of archives which are expanded,
+// e.g. 2 expands "a.zip" and "a.zip!/b.jar" but not "a.zip!/b.jar!/c.zip".
+const maxArchiveDepth = 2
+
+// maxArchiveFiles is the number of files in archives which are written per
+// repository, so that archives with many small or empty files can't blow up
+// the size of the zip.
+var maxArchiveFiles = 100000
+
+type archiveFormat int
+
+const (
+	notArchive archiveFormat = iota
+	zipArchive
+	tarArchive
+	tarGzArchive
+)
+
+func archiveFormatOf(name string) archiveFormat {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(name, ".jar"),
+		strings.HasSuffix(name, ".war"),
+		strings.HasSuffix(name, ".ear"),
+		strings.HasSuffix(name, ".zip"):
+		return zipArchive
+	case strings.HasSuffix(name, ".tar"):
+		return tarArchive
+	case strings.HasSuffix(name, ".tar.gz"),
+		strings.HasSuffix(name, ".tgz"):
+		return tarGzArchive
+	}
+	return notArchive
+}
+
+// IsArchive returns true if the file at name is an archive whose files can
+// be searched.
+func IsArchive(name string) bool {
+	return archiveFormatOf(name) != notArchive
+}
+
+// SplitArchivePath splits the virtual path of a file in an archive, like
+// "lib/foo.jar!/com/x/Y.java", into the path of the archive and the path of
+// the file in it. The path of the file may be in a nested archive, like
+// "b.jar!/c.txt". ok is false if name is not the path of a file in an
+// archive.
+func SplitArchivePath(name string) (archive, file string, ok bool) {
+	for i := 0; i < len(name); {
+		j := strings.Index(name[i:], ArchiveSeparator)
+		if j < 0 {
+			break
+		}
+		i += j
+		if IsArchive(name[:i]) && i+len(ArchiveSeparator) < len(name) {
+			return name[:i], name[i+len(ArchiveSeparator):], true
+		}
+		i += len(ArchiveSeparator)
+	}
+	return "", "", false
+}
+
+// walkArchive calls fn for each regular file in the archive at name with
+// contents data. The names passed to fn are relative to the root of the
+// archive. If fn returns an error walkArchive stops and returns it.
+func walkArchive(name string, data []byte, fn func(name string, size int64, r io.Reader) error) error {
+	format := archiveFormatOf(name)
+	if format == zipArchive {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return err
+		}
+		for _, f := range zr.File {
+			if !f.Mode().IsRegular() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = fn(cleanArchivePath(f.Name), int64(f.UncompressedSize64), rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	switch format {
+	case tarGzArchive:
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	case tarArchive:
+	default:
+		return errors.Errorf("not an archive: %q", name)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if err := fn(cleanArchivePath(hdr.Name), hdr.Size, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// cleanArchivePath returns name relative to the root of its archive, without
+// any ".." elements.
+func cleanArchivePath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+var errArchiveFileFound = errors.New("archive file found")
+
+// ReadArchiveFile returns the contents of the file at the path file in the
+// archive at name with contents data. file may be in a nested archive, like
+// "b.jar!/c.txt". At most limit bytes of the file and of each nested archive
+// are read.
+func ReadArchiveFile(name string, data []byte, file string, limit int64) ([]byte, error) {
+	target := file
+	nested, nestedFile, isNested := SplitArchivePath(file)
+	if isNested {
+		target = nested
+	}
+
+	var contents []byte
+	err := walkArchive(name, data, func(n string, _ int64, r io.Reader) error {
+		if n != target {
+			return nil
+		}
+		b, err := io.ReadAll(io.LimitReader(r, limit))
+		if err != nil {
+			return err
+		}
+		contents = b
+		return errArchiveFileFound
+	})
+	if err != errArchiveFileFound {
+		if err == nil {
+			err = &fileNotFoundError{name: name + ArchiveSeparator + target}
+		}
+		return nil, err
+	}
+
+	if isNested {
+		return ReadArchiveFile(name+ArchiveSeparator+nested, contents, nestedFile, limit)
+	}
+	return contents, nil
+}
+
+type fileNotFoundError struct{ name string }
+
+func (e *fileNotFoundError) Error() string  { return "file not found: " + e.name }
+func (e *fileNotFoundError) NotFound() bool { return true }
+
+var errArchiveBudgetExhausted = errors.New("archive budget exhausted")
+
+// archiveExpander writes the files in archives to a zip at their virtual
+// paths, until it has read budget bytes of their contents (including the
+// contents of nested archives) or written maxArchiveFiles files. Files which
+// aren't searchable, or don't fit in the budget, are written without their
+// contents so that their paths can still be searched.
+type archiveExpander struct {
+	zw                *zip.Writer
+	largeFilePatterns []string
+	budget            int64
+	files             int
+
+	// err is the first error writing to zw.
+	err error
+}
+
+// exhausted returns true if no more files in archives can be written.
+func (e *archiveExpander) exhausted() bool {
+	return e.budget <= 0 || e.files >= maxArchiveFiles
+}
+
+// expand writes the files of the archive at name with contents data to
+// e.zw. It only returns errors writing to e.zw. Archives which can't be
+// read are skipped.
+func (e *archiveExpander) expand(name string, data []byte, depth int) error {
+	err := walkArchive(name, data, func(file string, size int64, r io.Reader) error {
+		if e.exhausted() {
+			return errArchiveBudgetExhausted
+		}
+		e.files++
+
+		vpath := name + ArchiveSeparator + file
+		if depth < maxArchiveDepth && IsArchive(file) && size <= e.budget {
+			// Nested archives are read into memory after being
+			// decompressed, so they are charged to the budget like
+			// the files in them.
+			nested, err := io.ReadAll(io.LimitReader(r, size))
+			if err != nil {
+				return err
+			}
+			e.budget -= int64(len(nested))
+			if err := e.write(vpath, nil); err != nil {
+				return err
+			}
+			return e.expand(vpath, nested, depth+1)
+		}
+
+		if size > e.budget || (size > maxFileSize && !ignoreSizeMax(vpath, e.largeFilePatterns)) {
+			return e.write(vpath, nil)
+		}
+
+		contents, err := io.ReadAll(io.LimitReader(r, size))
+		if err != nil {
+			return err
+		}
+		e.budget -= int64(len(contents))
+
+		// Same heuristic as copySearchable: binary files are only
+		// searched by their names.
+		head := contents
+		if len(head) > 32*1024 {
+			head = head[:32*1024]
+		}
+		if bytes.IndexByte(head, 0x00) >= 0 {
+			contents = nil
+		}
+		return e.write(vpath, contents)
+	})
+	if e.err != nil {
+		return e.err
+	}
+	if err != nil && err != errArchiveBudgetExhausted {
+		log15.Debug("failed to expand archive", "archive", name, "error", err)
+	}
+	return nil
+}
+
+func (e *archiveExpander) write(name string, contents []byte) error {
+	w, err := e.zw.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: zip.Store,
+	})
+	if err == nil {
+		_, err = w.Write(contents)
+	}
+	if err != nil {
+		e.err = err
+	}
+	return err
+}
@@ -0,0 +1,273 @@
+package store
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitArchivePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+		file    string
+		ok      bool
+	}{
+		{"lib/foo.jar!/com/x/Y.java", "lib/foo.jar", "com/x/Y.java", true},
+		{"a.zip!/b.jar!/c.txt", "a.zip", "b.jar!/c.txt", true},
+		{"a.tar.gz!/c.txt", "a.tar.gz", "c.txt", true},
+		{"wow!/a.zip!/c.txt", "wow!/a.zip", "c.txt", true},
+		{"a.zip!/", "", "", false},
+		{"a.txt!/c.txt", "", "", false},
+		{"lib/foo.jar", "", "", false},
+	}
+	for _, test := range tests {
+		archive, file, ok := SplitArchivePath(test.name)
+		if archive != test.archive || file != test.file || ok != test.ok {
+			t.Errorf("SplitArchivePath(%q) got (%q, %q, %v) want (%q, %q, %v)", test.name, archive, file, ok, test.archive, test.file, test.ok)
+		}
+	}
+}
+
+func TestCopySearchable_archives(t *testing.T) {
+	inner := zipBytes(t, map[string]string{
+		"c.txt": "nested",
+	})
+	jar := zipBytes(t, map[string]string{
+		"com/x/Y.java":  "class Y {}",
+		"com/x/Y.class": "\x00\x01binary",
+		"inner.zip":     string(inner),
+	})
+	tgz := tarGzBytes(t, map[string]string{
+		"../docs/README": "readme",
+	})
+	repo := tarBytes(t, map[string]string{
+		"main.go":      "package main",
+		"lib/foo.jar":  string(jar),
+		"docs.tar.gz":  string(tgz),
+		"broken.zip":   "not a zip",
+		"other/ok.txt": "ok",
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		got := copySearchableFiles(t, repo, 0)
+		want := map[string]string{
+			"main.go":      "package main",
+			"lib/foo.jar":  "",
+			"docs.tar.gz":  "",
+			"broken.zip":   "not a zip",
+			"other/ok.txt": "ok",
+		}
+		if d := cmp.Diff(want, got); d != "" {
+			t.Fatalf("unexpected files (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		got := copySearchableFiles(t, repo, 1<<20)
+		want := map[string]string{
+			"main.go":                       "package main",
+			"lib/foo.jar":                   "",
+			"lib/foo.jar!/com/x/Y.java":     "class Y {}",
+			"lib/foo.jar!/com/x/Y.class":    "",
+			"lib/foo.jar!/inner.zip":        "",
+			"lib/foo.jar!/inner.zip!/c.txt": "nested",
+			"docs.tar.gz":                   "",
+			"docs.tar.gz!/docs/README":      "readme",
+			"broken.zip":                    "",
+			"other/ok.txt":                  "ok",
+		}
+		if d := cmp.Diff(want, got); d != "" {
+			t.Fatalf("unexpected files (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		// The budget is enough for the contents of the first file in the
+		// archive, but not the second.
+		tgz := tarGzBytes(t, map[string]string{
+			"a.txt": strings.Repeat("a", 1000),
+			"b.txt": strings.Repeat("b", 1000),
+		})
+		got := copySearchableFiles(t, tarBytes(t, map[string]string{"big.tgz": string(tgz)}), 1500)
+		want := map[string]string{
+			"big.tgz":        "",
+			"big.tgz!/a.txt": strings.Repeat("a", 1000),
+			"big.tgz!/b.txt": "",
+		}
+		if d := cmp.Diff(want, got); d != "" {
+			t.Fatalf("unexpected files (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("nested archives are charged to the budget", func(t *testing.T) {
+		// The nested tar is 2560 bytes, which leaves too little of the
+		// budget for the file in it.
+		inner := tarBytes(t, map[string]string{"a.txt": strings.Repeat("a", 1000)})
+		tgz := tarGzBytes(t, map[string]string{"inner.tar": string(inner)})
+		got := copySearchableFiles(t, tarBytes(t, map[string]string{"big.tgz": string(tgz)}), 3000)
+		want := map[string]string{
+			"big.tgz":                   "",
+			"big.tgz!/inner.tar":        "",
+			"big.tgz!/inner.tar!/a.txt": "",
+		}
+		if d := cmp.Diff(want, got); d != "" {
+			t.Fatalf("unexpected files (-want +got):\n%s", d)
+		}
+	})
+
+	t.Run("max files", func(t *testing.T) {
+		old := maxArchiveFiles
+		maxArchiveFiles = 2
+		defer func() { maxArchiveFiles = old }()
+
+		tgz := tarGzBytes(t, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+		got := copySearchableFiles(t, tarBytes(t, map[string]string{"many.tgz": string(tgz), "more.tgz": string(tgz)}), 1<<20)
+		want := map[string]string{
+			"many.tgz":        "",
+			"many.tgz!/a.txt": "a",
+			"many.tgz!/b.txt": "b",
+			"more.tgz":        "",
+		}
+		if d := cmp.Diff(want, got); d != "" {
+			t.Fatalf("unexpected files (-want +got):\n%s", d)
+		}
+	})
+}
+
+func TestReadArchiveFile(t *testing.T) {
+	inner := tarGzBytes(t, map[string]string{
+		"c.txt": "nested",
+	})
+	jar := zipBytes(t, map[string]string{
+		"com/x/Y.java": "class Y {}",
+		"inner.tgz":    string(inner),
+	})
+
+	got, err := ReadArchiveFile("lib/foo.jar", jar, "com/x/Y.java", 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "class Y {}" {
+		t.Errorf("got %q", got)
+	}
+
+	got, err = ReadArchiveFile("lib/foo.jar", jar, "inner.tgz!/c.txt", 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "nested" {
+		t.Errorf("got %q", got)
+	}
+
+	_, err = ReadArchiveFile("lib/foo.jar", jar, "missing.txt", 1<<20)
+	if nf, ok := err.(interface{ NotFound() bool }); !ok || !nf.NotFound() {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func copySearchableFiles(t *testing.T, tarData []byte, archiveBudget int64) map[string]string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	filter := func(hdr *tar.Header) bool { return false }
+	if err := copySearchable(tar.NewReader(bytes.NewReader(tarData)), zw, nil, archiveBudget, filter); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func zipBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range sortedKeys(files) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range sortedKeys(files) {
+		err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(files[name])),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzBytes(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(tarBytes(t, files)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// sortedKeys returns the keys of m in the order files are added to the
+// archives in the tests, so that budgets are applied deterministically.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -130,9 +130,10 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 	}
 
 	largeFilePatterns := conf.Get().SearchLargeFiles
+	archiveBudget := conf.SearchArchivesMaxBytes()
 
 	// key is a sha256 hash since we want to use it for the disk name
-	h := sha256.Sum256([]byte(fmt.Sprintf("%q %q %q", repo, commit, largeFilePatterns)))
+	h := sha256.Sum256([]byte(fmt.Sprintf("%q %q %q %d", repo, commit, largeFilePatterns, archiveBudget)))
 	key := hex.EncodeToString(h[:])
 	span.LogKV("key", key)
 
@@ -149,7 +150,7 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 		// since we're just going to close it again immediately.
 		bgctx := opentracing.ContextWithSpan(context.Background(), opentracing.SpanFromContext(ctx))
 		f, err := s.cache.Open(bgctx, key, func(ctx context.Context) (io.ReadCloser, error) {
-			return s.fetch(ctx, repo, commit, largeFilePatterns)
+			return s.fetch(ctx, repo, commit, largeFilePatterns, archiveBudget)
 		})
 		var path string
 		if f != nil {
@@ -179,7 +180,7 @@ func (s *Store) PrepareZip(ctx context.Context, repo api.RepoName, commit api.Co
 // fetch fetches an archive from the network and stores it on disk. It does
 // not populate the in-memory cache. You should probably be calling
 // prepareZip.
-func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitID, largeFilePatterns []string) (rc io.ReadCloser, err error) {
+func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitID, largeFilePatterns []string, archiveBudget int64) (rc io.ReadCloser, err error) {
 	fetchQueueSize.Inc()
 	ctx, releaseFetchLimiter, err := s.fetchLimiter.Acquire(ctx) // Acquire concurrent fetches semaphore
 	if err != nil {
@@ -247,7 +248,7 @@ func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitI
 		defer r.Close()
 		tr := tar.NewReader(r)
 		zw := zip.NewWriter(pw)
-		err := copySearchable(tr, zw, largeFilePatterns, filter)
+		err := copySearchable(tr, zw, largeFilePatterns, archiveBudget, filter)
 		if err1 := zw.Close(); err == nil {
 			err = err1
 		}
@@ -261,9 +262,18 @@ func (s *Store) fetch(ctx context.Context, repo api.RepoName, commit api.CommitI
 
 // copySearchable copies searchable files from tr to zw. A searchable file is
 // any file that is under size limit, non-binary, and not matching the filter.
-func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string, filter FilterFunc) error {
+//
+// If archiveBudget is positive the files in archives are also copied, at
+// virtual paths like "lib/foo.jar!/com/x/Y.java", until archiveBudget bytes
+// of them have been read.
+func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string, archiveBudget int64, filter FilterFunc) error {
 	// 32*1024 is the same size used by io.Copy
 	buf := make([]byte, 32*1024)
+	expander := &archiveExpander{
+		zw:                zw,
+		largeFilePatterns: largeFilePatterns,
+		budget:            archiveBudget,
+	}
 	for {
 		hdr, err := tr.Next()
 		if err == io.EOF {
@@ -290,6 +300,22 @@ func copySearchable(tr *tar.Reader, zw *zip.Writer, largeFilePatterns []string,
 			continue
 		}
 
+		if !expander.exhausted() && IsArchive(hdr.Name) && hdr.Size <= expander.budget {
+			// Archives are binary, so we only search their names and
+			// the files in them.
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := expander.write(hdr.Name, nil); err != nil {
+				return err
+			}
+			if err := expander.expand(hdr.Name, data, 1); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// We are happy with the file, so we can write it to zw.
 		w, err := zw.CreateHeader(&zip.FileHeader{
 			Name:   hdr.Name,
@@ -0,0 +1,381 @@
+package txemail
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/textproto"
+	"time"
+
+	"github.com/jordan-wright/email"
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/basestore"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+)
+
+// OutboxMessage is a message in the outbox.
+type OutboxMessage struct {
+	ID             int
+	State          string
+	FailureMessage *string
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+	ProcessAfter   *time.Time
+	NumResets      int
+	NumFailures    int
+
+	Recipients []string
+	ReplyTo    *string
+	Subject    string
+	TextBody   string
+	HTMLBody   string
+	Headers    map[string][]string
+
+	DeliveredAt       *time.Time
+	ProviderMessageID *string
+	CreatedAt         time.Time
+}
+
+// RecordID implements workerutil.Record.
+func (m *OutboxMessage) RecordID() int {
+	return m.ID
+}
+
+// email returns the message to deliver, without a "From" address.
+func (m *OutboxMessage) email() *email.Email {
+	e := &email.Email{
+		To:      m.Recipients,
+		Subject: m.Subject,
+		Text:    []byte(m.TextBody),
+		HTML:    []byte(m.HTMLBody),
+		Headers: make(textproto.MIMEHeader, len(m.Headers)),
+	}
+	if m.ReplyTo != nil {
+		e.ReplyTo = []string{*m.ReplyTo}
+	}
+	for k, v := range m.Headers {
+		e.Headers[k] = v
+	}
+	return e
+}
+
+// Outbox states, as shown to site admins. Messages that failed and will be retried are queued.
+const (
+	OutboxStateQueued  = "queued"
+	OutboxStateSending = "sending"
+	OutboxStateSent    = "sent"
+	OutboxStateFailed  = "failed"
+)
+
+// Status returns the state of the message as shown to site admins.
+func (m *OutboxMessage) Status() string {
+	switch m.State {
+	case "processing":
+		return OutboxStateSending
+	case "completed":
+		return OutboxStateSent
+	case "failed":
+		return OutboxStateFailed
+	default:
+		return OutboxStateQueued
+	}
+}
+
+// workerStates returns the worker states of the messages in the given status.
+func workerStates(status string) []string {
+	switch status {
+	case OutboxStateQueued:
+		return []string{"queued", "errored"}
+	case OutboxStateSending:
+		return []string{"processing"}
+	case OutboxStateSent:
+		return []string{"completed"}
+	case OutboxStateFailed:
+		return []string{"failed"}
+	}
+	return nil
+}
+
+// OutboxStore reads and writes the messages in the outbox.
+type OutboxStore struct {
+	*basestore.Store
+}
+
+// NewOutboxStore returns a new OutboxStore backed by the given database.
+func NewOutboxStore(db dbutil.DB) *OutboxStore {
+	return &OutboxStore{Store: basestore.NewWithDB(db, sql.TxOptions{})}
+}
+
+const enqueueFmtStr = `
+-- source: internal/txemail/outbox.go:Enqueue
+INSERT INTO email_outbox (recipients, reply_to, subject, text_body, html_body, headers)
+VALUES (%s, %s, %s, %s, %s, %s)
+RETURNING id
+`
+
+// Enqueue adds a rendered message to the outbox and returns its ID. The "From" address of m
+// is ignored, since it is set on delivery.
+func (s *OutboxStore) Enqueue(ctx context.Context, m *email.Email) (int, error) {
+	var replyTo *string
+	if len(m.ReplyTo) > 0 {
+		replyTo = &m.ReplyTo[0]
+	}
+	headers := map[string][]string(m.Headers)
+	if headers == nil {
+		headers = map[string][]string{}
+	}
+	headersJSON, err := json.Marshal(headers)
+	if err != nil {
+		return 0, err
+	}
+
+	id, _, err := basestore.ScanFirstInt(s.Query(ctx, sqlf.Sprintf(
+		enqueueFmtStr,
+		pq.Array(m.To),
+		replyTo,
+		m.Subject,
+		string(m.Text),
+		string(m.HTML),
+		headersJSON,
+	)))
+	return id, err
+}
+
+const getMessageFmtStr = `
+-- source: internal/txemail/outbox.go:GetByID
+SELECT %s FROM email_outbox WHERE id = %s
+`
+
+// GetByID returns the message with the given ID and true, or false if it doesn't exist.
+func (s *OutboxStore) GetByID(ctx context.Context, id int) (*OutboxMessage, bool, error) {
+	return scanFirstOutboxMessage(s.Query(ctx, sqlf.Sprintf(getMessageFmtStr, sqlf.Join(OutboxColumns, ", "), id)))
+}
+
+// OutboxListOptions specifies the messages returned by List and Count.
+type OutboxListOptions struct {
+	// Status only includes the messages in this status (such as OutboxStateFailed), if set.
+	Status string
+	// BeforeID only includes the messages older than the one with this ID, if set.
+	BeforeID int
+	// Limit is the maximum number of messages returned, if set.
+	Limit int
+}
+
+func (o OutboxListOptions) conds() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.Status != "" {
+		conds = append(conds, sqlf.Sprintf("state = ANY(%s)", pq.Array(workerStates(o.Status))))
+	}
+	if o.BeforeID > 0 {
+		conds = append(conds, sqlf.Sprintf("id < %s", o.BeforeID))
+	}
+	return conds
+}
+
+const listMessagesFmtStr = `
+-- source: internal/txemail/outbox.go:List
+SELECT %s FROM email_outbox WHERE %s ORDER BY id DESC %s
+`
+
+// List returns the messages matching opt, newest first.
+func (s *OutboxStore) List(ctx context.Context, opt OutboxListOptions) ([]*OutboxMessage, error) {
+	limit := sqlf.Sprintf("")
+	if opt.Limit > 0 {
+		limit = sqlf.Sprintf("LIMIT %s", opt.Limit)
+	}
+	return scanOutboxMessages(s.Query(ctx, sqlf.Sprintf(
+		listMessagesFmtStr,
+		sqlf.Join(OutboxColumns, ", "),
+		sqlf.Join(opt.conds(), "AND"),
+		limit,
+	)))
+}
+
+const countMessagesFmtStr = `
+-- source: internal/txemail/outbox.go:Count
+SELECT COUNT(*) FROM email_outbox WHERE %s
+`
+
+// Count returns the number of messages matching opt, ignoring its limit.
+func (s *OutboxStore) Count(ctx context.Context, opt OutboxListOptions) (int, error) {
+	count, _, err := basestore.ScanFirstInt(s.Query(ctx, sqlf.Sprintf(countMessagesFmtStr, sqlf.Join(opt.conds(), "AND"))))
+	return count, err
+}
+
+const retryMessageFmtStr = `
+-- source: internal/txemail/outbox.go:Retry
+UPDATE email_outbox
+SET
+	state = 'queued',
+	num_failures = 0,
+	num_resets = 0,
+	process_after = NULL
+WHERE id = %s AND state IN ('errored', 'failed') AND delivered_at IS NULL AND (text_body <> '' OR html_body <> '')
+`
+
+// Retry queues the message with the given ID to be delivered again, with a new set of
+// attempts. Only messages that failed, were never delivered and still have their bodies (see
+// ClearFailedBodiesBefore) can be retried. It returns false if the message can't be retried.
+func (s *OutboxStore) Retry(ctx context.Context, id int) (bool, error) {
+	result, err := s.ExecResult(ctx, sqlf.Sprintf(retryMessageFmtStr, id))
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+const requeueAfterFailureFmtStr = `
+-- source: internal/txemail/outbox.go:requeueAfterFailure
+UPDATE email_outbox
+SET
+	state = 'queued',
+	failure_message = %s,
+	finished_at = clock_timestamp(),
+	process_after = %s,
+	num_failures = num_failures + 1
+WHERE id = %s AND state = 'processing'
+`
+
+// requeueAfterFailure records a failed delivery attempt of the message being processed, and
+// queues it to be retried after the given time.
+func (s *OutboxStore) requeueAfterFailure(ctx context.Context, id int, failureMessage string, after time.Time) error {
+	return s.Exec(ctx, sqlf.Sprintf(requeueAfterFailureFmtStr, failureMessage, after, id))
+}
+
+const markDeliveredFmtStr = `
+-- source: internal/txemail/outbox.go:markDelivered
+UPDATE email_outbox
+SET
+	delivered_at = clock_timestamp(),
+	provider_message_id = %s,
+	text_body = '',
+	html_body = ''
+WHERE id = %s
+`
+
+// markDelivered records that the transport accepted the message, and clears its bodies since
+// they may contain secrets such as password reset links.
+func (s *OutboxStore) markDelivered(ctx context.Context, id int, providerMessageID string) error {
+	var pid *string
+	if providerMessageID != "" {
+		pid = &providerMessageID
+	}
+	return s.Exec(ctx, sqlf.Sprintf(markDeliveredFmtStr, pid, id))
+}
+
+const clearFailedBodiesFmtStr = `
+-- source: internal/txemail/outbox.go:ClearFailedBodiesBefore
+UPDATE email_outbox
+SET
+	text_body = '',
+	html_body = ''
+WHERE state = 'failed' AND finished_at < %s AND (text_body <> '' OR html_body <> '')
+`
+
+// ClearFailedBodiesBefore clears the bodies of the messages that failed before the given time,
+// like markDelivered does for the messages that were sent. The messages are kept so that site
+// admins can see that they failed, but they can't be retried anymore.
+func (s *OutboxStore) ClearFailedBodiesBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.ExecResult(ctx, sqlf.Sprintf(clearFailedBodiesFmtStr, before))
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+const deleteFinishedFmtStr = `
+-- source: internal/txemail/outbox.go:DeleteFinishedBefore
+DELETE FROM email_outbox
+WHERE state IN ('completed', 'failed') AND finished_at < %s
+`
+
+// DeleteFinishedBefore deletes the messages that were sent or failed before the given time.
+func (s *OutboxStore) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.ExecResult(ctx, sqlf.Sprintf(deleteFinishedFmtStr, before))
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// OutboxColumns are the columns of a message, in the order read by ScanOutboxMessage.
+var OutboxColumns = []*sqlf.Query{
+	sqlf.Sprintf("email_outbox.id"),
+	sqlf.Sprintf("email_outbox.state"),
+	sqlf.Sprintf("email_outbox.failure_message"),
+	sqlf.Sprintf("email_outbox.started_at"),
+	sqlf.Sprintf("email_outbox.finished_at"),
+	sqlf.Sprintf("email_outbox.process_after"),
+	sqlf.Sprintf("email_outbox.num_resets"),
+	sqlf.Sprintf("email_outbox.num_failures"),
+	sqlf.Sprintf("email_outbox.recipients"),
+	sqlf.Sprintf("email_outbox.reply_to"),
+	sqlf.Sprintf("email_outbox.subject"),
+	sqlf.Sprintf("email_outbox.text_body"),
+	sqlf.Sprintf("email_outbox.html_body"),
+	sqlf.Sprintf("email_outbox.headers"),
+	sqlf.Sprintf("email_outbox.delivered_at"),
+	sqlf.Sprintf("email_outbox.provider_message_id"),
+	sqlf.Sprintf("email_outbox.created_at"),
+}
+
+// ScanOutboxMessage scans a single message from the given rows. It implements the scan
+// function of the worker store.
+func ScanOutboxMessage(rows *sql.Rows, err error) (workerutil.Record, bool, error) {
+	return scanFirstOutboxMessage(rows, err)
+}
+
+func scanFirstOutboxMessage(rows *sql.Rows, err error) (*OutboxMessage, bool, error) {
+	messages, err := scanOutboxMessages(rows, err)
+	if err != nil || len(messages) == 0 {
+		return nil, false, err
+	}
+	return messages[0], true, nil
+}
+
+func scanOutboxMessages(rows *sql.Rows, queryErr error) (_ []*OutboxMessage, err error) {
+	if queryErr != nil {
+		return nil, queryErr
+	}
+	defer func() { err = basestore.CloseRows(rows, err) }()
+
+	var messages []*OutboxMessage
+	for rows.Next() {
+		var (
+			m       OutboxMessage
+			headers []byte
+		)
+		if err := rows.Scan(
+			&m.ID,
+			&m.State,
+			&m.FailureMessage,
+			&m.StartedAt,
+			&m.FinishedAt,
+			&m.ProcessAfter,
+			&m.NumResets,
+			&m.NumFailures,
+			pq.Array(&m.Recipients),
+			&m.ReplyTo,
+			&m.Subject,
+			&m.TextBody,
+			&m.HTMLBody,
+			&headers,
+			&m.DeliveredAt,
+			&m.ProviderMessageID,
+			&m.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(headers, &m.Headers); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	return messages, nil
+}
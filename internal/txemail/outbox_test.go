@@ -0,0 +1,143 @@
+package txemail
+
+import (
+	"context"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jordan-wright/email"
+	"github.com/keegancsmith/sqlf"
+
+	"github.com/sourcegraph/sourcegraph/internal/database/dbtesting"
+)
+
+func init() {
+	dbtesting.DBNameSuffix = "txemailoutboxdb"
+}
+
+func TestOutboxStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	s := NewOutboxStore(dbtesting.GetDB(t))
+
+	replyTo := "support@example.com"
+	id, err := s.Enqueue(ctx, &email.Email{
+		From:    "ignored",
+		To:      []string{"alice@example.com", "bob@example.com"},
+		ReplyTo: []string{replyTo},
+		Subject: "Hello",
+		Text:    []byte("Hi"),
+		HTML:    []byte("<p>Hi</p>"),
+		Headers: textproto.MIMEHeader{"Message-Id": []string{"1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := s.Enqueue(ctx, &email.Email{To: []string{"carol@example.com"}, Subject: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, ok, err := s.GetByID(ctx, id)
+	if err != nil || !ok {
+		t.Fatalf("got message %v (exists: %v), error %v", msg, ok, err)
+	}
+	if msg.Status() != OutboxStateQueued {
+		t.Errorf("got status %q, want %q", msg.Status(), OutboxStateQueued)
+	}
+	want := &email.Email{
+		To:      []string{"alice@example.com", "bob@example.com"},
+		ReplyTo: []string{replyTo},
+		Subject: "Hello",
+		Text:    []byte("Hi"),
+		HTML:    []byte("<p>Hi</p>"),
+		Headers: textproto.MIMEHeader{"Message-Id": []string{"1"}},
+	}
+	if diff := cmp.Diff(want, msg.email()); diff != "" {
+		t.Errorf("message mismatch (-want +got):\n%s", diff)
+	}
+
+	// Fail the first message, as the worker does once it runs out of attempts.
+	if err := s.Exec(ctx, sqlf.Sprintf("UPDATE email_outbox SET state = 'failed', finished_at = %s, num_failures = 10 WHERE id = %s", time.Now().Add(-time.Hour), id)); err != nil {
+		t.Fatal(err)
+	}
+
+	listIDs := func(opt OutboxListOptions) []int {
+		t.Helper()
+		messages, err := s.List(ctx, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []int{}
+		for _, m := range messages {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+	if diff := cmp.Diff([]int{other, id}, listIDs(OutboxListOptions{})); diff != "" {
+		t.Errorf("list mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{id}, listIDs(OutboxListOptions{Status: OutboxStateFailed})); diff != "" {
+		t.Errorf("list failed mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{id}, listIDs(OutboxListOptions{BeforeID: other})); diff != "" {
+		t.Errorf("list before mismatch (-want +got):\n%s", diff)
+	}
+	if n, err := s.Count(ctx, OutboxListOptions{Status: OutboxStateQueued}); err != nil || n != 1 {
+		t.Errorf("got count %d, error %v, want 1", n, err)
+	}
+
+	if n, err := s.DeleteFinishedBefore(ctx, time.Now().Add(-2*time.Hour)); err != nil || n != 0 {
+		t.Errorf("got %d deleted messages, error %v, want 0", n, err)
+	}
+
+	if ok, err := s.Retry(ctx, other); err != nil || ok {
+		t.Errorf("retrying a queued message: got %v, error %v, want false", ok, err)
+	}
+	if ok, err := s.Retry(ctx, id); err != nil || !ok {
+		t.Errorf("retrying a failed message: got %v, error %v, want true", ok, err)
+	}
+	if msg, _, err := s.GetByID(ctx, id); err != nil || msg.Status() != OutboxStateQueued || msg.NumFailures != 0 {
+		t.Errorf("got message %+v, error %v, want a queued message", msg, err)
+	}
+
+	if err := s.markDelivered(ctx, other, "p-1"); err != nil {
+		t.Fatal(err)
+	}
+	if msg, _, err := s.GetByID(ctx, other); err != nil || msg.DeliveredAt == nil || msg.ProviderMessageID == nil || *msg.ProviderMessageID != "p-1" || msg.TextBody != "" {
+		t.Errorf("got message %+v, error %v, want a delivered message", msg, err)
+	}
+	if err := s.Exec(ctx, sqlf.Sprintf("UPDATE email_outbox SET state = 'completed', finished_at = %s WHERE id = %s", time.Now().Add(-time.Hour), other)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.DeleteFinishedBefore(ctx, time.Now()); err != nil || n != 1 {
+		t.Errorf("got %d deleted messages, error %v, want 1", n, err)
+	}
+
+	// The bodies of failed messages are cleared after a while, after which they can't be
+	// retried.
+	failed, err := s.Enqueue(ctx, &email.Email{To: []string{"dave@example.com"}, Subject: "Reset", Text: []byte("secret link")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Exec(ctx, sqlf.Sprintf("UPDATE email_outbox SET state = 'failed', finished_at = %s, num_failures = 10 WHERE id = %s", time.Now().Add(-2*time.Hour), failed)); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.ClearFailedBodiesBefore(ctx, time.Now().Add(-3*time.Hour)); err != nil || n != 0 {
+		t.Errorf("got %d cleared messages, error %v, want 0", n, err)
+	}
+	if n, err := s.ClearFailedBodiesBefore(ctx, time.Now().Add(-time.Hour)); err != nil || n != 1 {
+		t.Errorf("got %d cleared messages, error %v, want 1", n, err)
+	}
+	if msg, _, err := s.GetByID(ctx, failed); err != nil || msg.TextBody != "" || msg.Status() != OutboxStateFailed {
+		t.Errorf("got message %+v, error %v, want a failed message without a body", msg, err)
+	}
+	if ok, err := s.Retry(ctx, failed); err != nil || ok {
+		t.Errorf("retrying a failed message without a body: got %v, error %v, want false", ok, err)
+	}
+}
@@ -0,0 +1,248 @@
+package txemail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/jordan-wright/email"
+
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// A Transport delivers rendered messages, whose "From" address is already set.
+//
+// Errors that won't go away by retrying, such as a rejected recipient, are marked with
+// errcode.MakeNonRetryable.
+type Transport interface {
+	// Send delivers m and returns the ID the provider assigned to it, if any.
+	Send(ctx context.Context, m *email.Email) (providerMessageID string, err error)
+}
+
+// TransportFunc is a Transport implemented by a function.
+type TransportFunc func(ctx context.Context, m *email.Email) (string, error)
+
+func (f TransportFunc) Send(ctx context.Context, m *email.Email) (string, error) {
+	return f(ctx, m)
+}
+
+// MockTransport is used in tests to replace the transport configured in the site configuration.
+var MockTransport Transport
+
+// transportFromConfig returns the transport configured in c. The email HTTP API takes precedence
+// over the SMTP server.
+func transportFromConfig(c schema.SiteConfiguration) (Transport, error) {
+	if MockTransport != nil {
+		return MockTransport, nil
+	}
+	switch {
+	case c.EmailHttp != nil:
+		doer, err := emailHTTPDoer()
+		if err != nil {
+			return nil, err
+		}
+		return &httpTransport{config: c.EmailHttp, doer: doer}, nil
+	case c.EmailSmtp != nil:
+		return &smtpTransport{config: c.EmailSmtp}, nil
+	default:
+		return nil, errors.New("no SMTP server or email HTTP API configured (in email.smtp or email.http)")
+	}
+}
+
+// smtpTransport sends messages with an SMTP server.
+type smtpTransport struct {
+	config *schema.SMTPServerConfig
+}
+
+func (t *smtpTransport) Send(ctx context.Context, m *email.Email) (string, error) {
+	// Disable Mandrill features, because they make the emails look sketchy.
+	if t.config.Host == "smtp.mandrillapp.com" {
+		// Disable click tracking ("noclicks" could be any string; the docs say that anything will disable click tracking except
+		// those defined at
+		// https://mandrill.zendesk.com/hc/en-us/articles/205582117-How-to-Use-SMTP-Headers-to-Customize-Your-Messages#enable-open-and-click-tracking).
+		m.Headers["X-MC-Track"] = []string{"noclicks"}
+
+		m.Headers["X-MC-AutoText"] = []string{"false"}
+		m.Headers["X-MC-AutoHTML"] = []string{"false"}
+		m.Headers["X-MC-ViewContentLink"] = []string{"false"}
+	}
+
+	var smtpAuth smtp.Auth
+	switch t.config.Authentication {
+	case "none": // nothing to do
+	case "PLAIN":
+		smtpAuth = smtp.PlainAuth("", t.config.Username, t.config.Password, t.config.Host)
+	case "CRAM-MD5":
+		smtpAuth = smtp.CRAMMD5Auth(t.config.Username, t.config.Password)
+	default:
+		return "", errors.Errorf("invalid SMTP authentication type %q", t.config.Authentication)
+	}
+
+	addr := net.JoinHostPort(t.config.Host, strconv.Itoa(t.config.Port))
+
+	var err error
+	// TODO: 2020/11/12 @arussellsaw, after next release delete DisableTLS option
+	if t.config.DisableTLS || t.config.NoVerifyTLS {
+		err = m.SendWithStartTLS(addr, smtpAuth, &tls.Config{
+			InsecureSkipVerify: true,
+		})
+	} else {
+		err = m.Send(addr, smtpAuth)
+	}
+	return "", classifySMTPError(err)
+}
+
+// classifySMTPError marks permanent SMTP errors (5xx replies, such as an unknown mailbox) as
+// non-retryable. Authentication failures are retried, since they affect every message until the
+// credentials are configured again.
+func classifySMTPError(err error) error {
+	var tpErr *textproto.Error
+	if !errors.As(err, &tpErr) || tpErr.Code < 500 || tpErr.Code >= 600 {
+		return err
+	}
+	switch tpErr.Code {
+	case 530, // authentication required
+		534, // authentication mechanism is too weak
+		535, // authentication credentials invalid
+		538: // encryption required for the authentication mechanism
+		return err
+	}
+	return errcode.MakeNonRetryable(err)
+}
+
+var (
+	httpDoerOnce sync.Once
+	httpDoer     httpcli.Doer
+	httpDoerErr  error
+)
+
+// emailHTTPDoer returns the client of the email HTTP API. Unlike httpcli.ExternalDoer it
+// doesn't retry requests, which could deliver a message twice; the outbox retries failed
+// messages instead.
+func emailHTTPDoer() (httpcli.Doer, error) {
+	httpDoerOnce.Do(func() {
+		httpDoer, httpDoerErr = httpcli.NewFactory(
+			httpcli.NewMiddleware(httpcli.ContextErrorMiddleware),
+			httpcli.NewTimeoutOpt(time.Minute),
+			httpcli.ExternalTransportOpt,
+			httpcli.TracedTransportOpt,
+		).Doer()
+	})
+	return httpDoer, httpDoerErr
+}
+
+// httpTransport sends messages with an email HTTP API.
+type httpTransport struct {
+	config *schema.EmailHTTPConfig
+	doer   httpcli.Doer
+}
+
+// httpMessage is the JSON body sent to the email HTTP API.
+type httpMessage struct {
+	From    string              `json:"from"`
+	To      []string            `json:"to"`
+	ReplyTo string              `json:"replyTo,omitempty"`
+	Subject string              `json:"subject"`
+	Text    string              `json:"text"`
+	HTML    string              `json:"html"`
+	Headers map[string][]string `json:"headers,omitempty"`
+}
+
+func (t *httpTransport) Send(ctx context.Context, m *email.Email) (string, error) {
+	msg := httpMessage{
+		From:    m.From,
+		To:      m.To,
+		Subject: m.Subject,
+		Text:    string(m.Text),
+		HTML:    string(m.HTML),
+		Headers: m.Headers,
+	}
+	if len(m.ReplyTo) > 0 {
+		msg.ReplyTo = m.ReplyTo[0]
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("POST", t.config.Url, bytes.NewReader(body))
+	if err != nil {
+		return "", errcode.MakeNonRetryable(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.config.Token)
+	}
+
+	resp, err := t.doer.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err := errors.Errorf("email HTTP API responded with status %d: %s", resp.StatusCode, truncate(strings.TrimSpace(string(respBody)), 200))
+		if isRejectedMessageStatus(resp.StatusCode) {
+			return "", errcode.MakeNonRetryable(err)
+		}
+		return "", err
+	}
+
+	// The message ID is optional, and the message was accepted even if the body can't
+	// be parsed.
+	var result struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if json.Unmarshal(respBody, &result) != nil || len(result.ID) == 0 || string(result.ID) == "null" {
+		return "", nil
+	}
+	var id string
+	if json.Unmarshal(result.ID, &id) == nil {
+		return id, nil
+	}
+	// Numeric IDs are recorded as they are.
+	return string(result.ID), nil
+}
+
+// isRejectedMessageStatus returns true if the status code of the email HTTP API means that the
+// message itself was rejected, so that sending it again fails too. Client errors caused by the
+// configuration (such as an expired token or a wrong URL) are retried, since they affect every
+// message until the email HTTP API is configured again, and so are requests to slow down.
+func isRejectedMessageStatus(code int) bool {
+	switch code {
+	case http.StatusUnauthorized,
+		http.StatusForbidden,
+		http.StatusNotFound,
+		http.StatusMethodNotAllowed,
+		http.StatusProxyAuthRequired,
+		http.StatusRequestTimeout,
+		http.StatusTooManyRequests:
+		return false
+	}
+	return code >= 400 && code < 500
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n] + "..."
+	}
+	return s
+}
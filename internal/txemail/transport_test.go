@@ -0,0 +1,108 @@
+package txemail
+
+import (
+	"context"
+	"net/http"
+	"net/textproto"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/jordan-wright/email"
+
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/txemail/txemailtest"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	srv := txemailtest.NewServer()
+	defer srv.Close()
+	srv.Token = "s3cr3t"
+
+	transport := &httpTransport{
+		config: &schema.EmailHTTPConfig{Url: srv.URL, Token: "s3cr3t"},
+		doer:   srv.Client(),
+	}
+	m := &email.Email{
+		From:    "noreply@example.com",
+		To:      []string{"alice@example.com"},
+		ReplyTo: []string{"support@example.com"},
+		Subject: "Hello",
+		Text:    []byte("Hi Alice"),
+		HTML:    []byte("<p>Hi Alice</p>"),
+		Headers: textproto.MIMEHeader{"Message-Id": []string{"1"}},
+	}
+	ctx := context.Background()
+
+	id, err := transport.Send(ctx, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "1" {
+		t.Errorf("got provider message ID %q, want 1", id)
+	}
+	want := []txemailtest.Message{{
+		From:    "noreply@example.com",
+		To:      []string{"alice@example.com"},
+		ReplyTo: "support@example.com",
+		Subject: "Hello",
+		Text:    "Hi Alice",
+		HTML:    "<p>Hi Alice</p>",
+		Headers: map[string][]string{"Message-Id": {"1"}},
+	}}
+	if diff := cmp.Diff(want, srv.Messages()); diff != "" {
+		t.Errorf("messages mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, tc := range []struct {
+		status    int
+		retryable bool
+	}{
+		{http.StatusInternalServerError, true},
+		{http.StatusTooManyRequests, true},
+		{http.StatusUnprocessableEntity, false},
+		{http.StatusBadRequest, false},
+		// Configuration problems affect every message until they are fixed.
+		{http.StatusUnauthorized, true},
+		{http.StatusForbidden, true},
+		{http.StatusNotFound, true},
+	} {
+		srv.FailNext(tc.status)
+		_, err := transport.Send(ctx, m)
+		if err == nil {
+			t.Errorf("status %d: got no error", tc.status)
+			continue
+		}
+		if retryable := !errcode.IsNonRetryable(err); retryable != tc.retryable {
+			t.Errorf("status %d: got retryable %v, want %v (error: %v)", tc.status, retryable, tc.retryable, err)
+		}
+	}
+
+	transport.config.Token = "wrong"
+	if _, err := transport.Send(ctx, m); err == nil || errcode.IsNonRetryable(err) {
+		t.Errorf("got error %v, want a retryable error", err)
+	}
+}
+
+func TestClassifySMTPError(t *testing.T) {
+	for _, tc := range []struct {
+		err       error
+		retryable bool
+	}{
+		{&textproto.Error{Code: 421, Msg: "service not available"}, true},
+		{&textproto.Error{Code: 550, Msg: "mailbox unavailable"}, false},
+		{errors.Wrap(&textproto.Error{Code: 553, Msg: "mailbox name not allowed"}, "sending"), false},
+		{&textproto.Error{Code: 530, Msg: "authentication required"}, true},
+		{&textproto.Error{Code: 535, Msg: "authentication credentials invalid"}, true},
+		{errors.New("connection refused"), true},
+	} {
+		err := classifySMTPError(tc.err)
+		if retryable := !errcode.IsNonRetryable(err); retryable != tc.retryable {
+			t.Errorf("%v: got retryable %v, want %v", tc.err, retryable, tc.retryable)
+		}
+	}
+	if err := classifySMTPError(nil); err != nil {
+		t.Errorf("got error %v, want nil", err)
+	}
+}
@@ -3,12 +3,8 @@ package txemail
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
-	"net"
-	"net/smtp"
 	"net/textproto"
-	"strconv"
 
 	"github.com/cockroachdb/errors"
 	"github.com/jordan-wright/email"
@@ -69,7 +65,9 @@ func render(message Message) (*email.Email, error) {
 	return &m, nil
 }
 
-// Send sends a transactional email.
+// Send sends a transactional email. Once InitOutbox was called, the message is rendered and
+// queued in the outbox, from which it is delivered with retries; otherwise it is sent right
+// away.
 //
 // Callers that do not live in the frontend should call api.InternalClient.SendEmail
 // instead. TODO(slimsag): needs cleanup as part of upcoming configuration refactor.
@@ -85,54 +83,26 @@ func Send(ctx context.Context, message Message) error {
 	if conf.EmailAddress == "" {
 		return errors.New("no \"From\" email address configured (in email.address)")
 	}
-	if conf.EmailSmtp == nil {
-		return errors.New("no SMTP server configured (in email.smtp)")
+	t, err := transportFromConfig(conf.SiteConfiguration)
+	if err != nil {
+		return err
 	}
 
 	m, err := render(message)
 	if err != nil {
 		return err
 	}
-	m.From = conf.EmailAddress
-
-	// Disable Mandrill features, because they make the emails look sketchy.
-	if conf.EmailSmtp.Host == "smtp.mandrillapp.com" {
-		// Disable click tracking ("noclicks" could be any string; the docs say that anything will disable click tracking except
-		// those defined at
-		// https://mandrill.zendesk.com/hc/en-us/articles/205582117-How-to-Use-SMTP-Headers-to-Customize-Your-Messages#enable-open-and-click-tracking).
-		m.Headers["X-MC-Track"] = []string{"noclicks"}
-
-		m.Headers["X-MC-AutoText"] = []string{"false"}
-		m.Headers["X-MC-AutoHTML"] = []string{"false"}
-		m.Headers["X-MC-ViewContentLink"] = []string{"false"}
-	}
 
-	var smtpAuth smtp.Auth
-	switch conf.EmailSmtp.Authentication {
-	case "none": // nothing to do
-	case "PLAIN":
-		smtpAuth = smtp.PlainAuth("", conf.EmailSmtp.Username, conf.EmailSmtp.Password, conf.EmailSmtp.Host)
-	case "CRAM-MD5":
-		smtpAuth = smtp.CRAMMD5Auth(conf.EmailSmtp.Username, conf.EmailSmtp.Password)
-	default:
-		return errors.Errorf("invalid SMTP authentication type %q", conf.EmailSmtp.Authentication)
-	}
-
-	// TODO: 2020/11/12 @arussellsaw, after next release delete DisableTLS option
-	if conf.EmailSmtp.DisableTLS || conf.EmailSmtp.NoVerifyTLS {
-		return m.SendWithStartTLS(
-			net.JoinHostPort(conf.EmailSmtp.Host, strconv.Itoa(conf.EmailSmtp.Port)),
-			smtpAuth,
-			&tls.Config{
-				InsecureSkipVerify: true,
-			},
-		)
+	if s := getOutbox(); s != nil {
+		if _, err := s.Enqueue(ctx, m); err != nil {
+			return errors.Wrap(err, "queueing email")
+		}
+		return nil
 	}
 
-	return m.Send(
-		net.JoinHostPort(conf.EmailSmtp.Host, strconv.Itoa(conf.EmailSmtp.Port)),
-		smtpAuth,
-	)
+	m.From = conf.EmailAddress
+	_, err = t.Send(ctx, m)
+	return err
 }
 
 // MockSend is used in tests to mock the Send func.
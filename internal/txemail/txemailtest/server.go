@@ -0,0 +1,93 @@
+// Package txemailtest provides a local stand-in for an email HTTP API (see the "email.http"
+// site configuration), for use in tests.
+package txemailtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+)
+
+// Message is a message received by a Server.
+type Message struct {
+	From    string              `json:"from"`
+	To      []string            `json:"to"`
+	ReplyTo string              `json:"replyTo,omitempty"`
+	Subject string              `json:"subject"`
+	Text    string              `json:"text"`
+	HTML    string              `json:"html"`
+	Headers map[string][]string `json:"headers,omitempty"`
+}
+
+// Server is an email HTTP API which records the messages it receives instead of delivering
+// them. Each accepted message is assigned an ID, which is returned as {"id": "<n>"}.
+type Server struct {
+	*httptest.Server
+
+	// Token is the bearer token the requests must have, if set.
+	Token string
+
+	mu       sync.Mutex
+	messages []Message
+	failures []int
+}
+
+// NewServer starts a Server. The caller must call Close when done.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// FailNext makes the server respond to the next requests with the given status codes, in
+// order, without recording the messages.
+func (s *Server) FailNext(statusCodes ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statusCodes...)
+}
+
+// Messages returns the messages received so far.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method must be POST", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Token != "" && r.Header.Get("Authorization") != "Bearer "+s.Token {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	var m Message
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(m.To) == 0 {
+		http.Error(w, "no recipients", http.StatusUnprocessableEntity)
+		return
+	}
+
+	s.mu.Lock()
+	if len(s.failures) > 0 {
+		code := s.failures[0]
+		s.failures = s.failures[1:]
+		s.mu.Unlock()
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	s.messages = append(s.messages, m)
+	id := len(s.messages)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"id": strconv.Itoa(id)})
+}
@@ -0,0 +1,242 @@
+package txemail
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/inconshreveable/log15"
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/database/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/goroutine"
+	"github.com/sourcegraph/sourcegraph/internal/observation"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil"
+	"github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker"
+	dbworkerstore "github.com/sourcegraph/sourcegraph/internal/workerutil/dbworker/store"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	// maxAttempts is the number of times a message is sent before it fails. With the backoff
+	// below, messages are retried for about 3 hours.
+	maxAttempts = 10
+
+	// minRetryBackoff and maxRetryBackoff bound the time before a failed message is retried,
+	// which doubles after each attempt.
+	minRetryBackoff = 30 * time.Second
+	maxRetryBackoff = time.Hour
+
+	// outboxRetention is how long sent and failed messages are kept.
+	outboxRetention = 30 * 24 * time.Hour
+
+	// failedBodyRetention is how long the bodies of failed messages are kept, so that site
+	// admins can retry them. The bodies of sent messages are cleared right away.
+	failedBodyRetention = 24 * time.Hour
+)
+
+// retryBackoff returns the time to wait before retrying a message that failed the given
+// number of times, including the attempt that just failed.
+func retryBackoff(failures int) time.Duration {
+	d := minRetryBackoff
+	for i := 1; i < failures && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	return d
+}
+
+var outbox atomic.Value // *OutboxStore
+
+// InitOutbox makes Send queue messages in the outbox stored in db instead of sending them
+// directly, and returns the routines that deliver them. It is called by the frontend, since
+// only the frontend sends email.
+func InitOutbox(ctx context.Context, db dbutil.DB) []goroutine.BackgroundRoutine {
+	s := NewOutboxStore(db)
+	outbox.Store(s)
+
+	metrics := newMetrics()
+	return []goroutine.BackgroundRoutine{
+		NewOutboxWorker(ctx, s, metrics.workerMetrics),
+		NewOutboxResetter(s, dbworker.ResetterMetrics{
+			RecordResets:        metrics.resets,
+			RecordResetFailures: metrics.resetFailures,
+			Errors:              metrics.errors,
+		}),
+		NewOutboxJanitor(ctx, s, outboxRetention, failedBodyRetention),
+	}
+}
+
+func getOutbox() *OutboxStore {
+	s, _ := outbox.Load().(*OutboxStore)
+	return s
+}
+
+// NewOutboxWorker returns a worker which delivers the messages in the outbox with the
+// transport configured in the site configuration.
+func NewOutboxWorker(ctx context.Context, s *OutboxStore, metrics workerutil.WorkerMetrics) *workerutil.Worker {
+	h := &outboxHandler{
+		store:  s,
+		config: func() schema.SiteConfiguration { return conf.Get().SiteConfiguration },
+		now:    time.Now,
+	}
+	return dbworker.NewWorker(ctx, newOutboxWorkerStore(s), h, workerutil.WorkerOptions{
+		Name:        "email_outbox_worker",
+		NumHandlers: 4,
+		Interval:    time.Second,
+		Metrics:     metrics,
+	})
+}
+
+// NewOutboxResetter returns a routine which requeues the messages whose worker died, for
+// example because the frontend was restarted.
+func NewOutboxResetter(s *OutboxStore, metrics dbworker.ResetterMetrics) *dbworker.Resetter {
+	return dbworker.NewResetter(newOutboxWorkerStore(s), dbworker.ResetterOptions{
+		Name:     "email_outbox_worker_resetter",
+		Interval: time.Minute,
+		Metrics:  metrics,
+	})
+}
+
+// NewOutboxJanitor returns a routine which clears the bodies of the messages which failed more
+// than bodyTTL ago, and deletes the messages which were sent or failed more than ttl ago.
+func NewOutboxJanitor(ctx context.Context, s *OutboxStore, ttl, bodyTTL time.Duration) goroutine.BackgroundRoutine {
+	return goroutine.NewPeriodicGoroutine(ctx, time.Hour, goroutine.NewHandlerWithErrorMessage(
+		"email_outbox_janitor",
+		func(ctx context.Context) error {
+			if _, err := s.ClearFailedBodiesBefore(ctx, time.Now().Add(-bodyTTL)); err != nil {
+				return err
+			}
+			_, err := s.DeleteFinishedBefore(ctx, time.Now().Add(-ttl))
+			return err
+		},
+	))
+}
+
+func newOutboxWorkerStore(s *OutboxStore) dbworkerstore.Store {
+	return dbworkerstore.New(s.Handle(), dbworkerstore.Options{
+		Name:              "email_outbox_worker_store",
+		TableName:         "email_outbox",
+		ColumnExpressions: OutboxColumns,
+		Scan:              ScanOutboxMessage,
+		OrderByExpression: sqlf.Sprintf("email_outbox.id"),
+		StalledMaxAge:     time.Minute,
+		MaxNumResets:      5,
+		// The handler requeues failed messages itself with an exponential backoff. These
+		// only apply if that fails.
+		RetryAfter:    maxRetryBackoff,
+		MaxNumRetries: maxAttempts,
+	})
+}
+
+// outboxHandlerStore is the subset of *OutboxStore used by outboxHandler.
+type outboxHandlerStore interface {
+	requeueAfterFailure(ctx context.Context, id int, failureMessage string, after time.Time) error
+	markDelivered(ctx context.Context, id int, providerMessageID string) error
+}
+
+type outboxHandler struct {
+	store  outboxHandlerStore
+	config func() schema.SiteConfiguration
+	now    func() time.Time
+}
+
+var _ workerutil.Handler = &outboxHandler{}
+
+func (h *outboxHandler) Handle(ctx context.Context, record workerutil.Record) error {
+	msg := record.(*OutboxMessage)
+
+	// The worker died after the message was delivered, don't send it twice.
+	if msg.DeliveredAt != nil {
+		return nil
+	}
+
+	c := h.config()
+	t, err := transportFromConfig(c)
+	if err != nil {
+		// Email may be configured again before the message fails.
+		return h.retry(ctx, msg, err)
+	}
+	if c.EmailAddress == "" {
+		return h.retry(ctx, msg, errors.New("no \"From\" email address configured (in email.address)"))
+	}
+
+	m := msg.email()
+	m.From = c.EmailAddress
+	providerMessageID, err := t.Send(ctx, m)
+	if err != nil {
+		if errcode.IsNonRetryable(err) {
+			return err
+		}
+		return h.retry(ctx, msg, err)
+	}
+
+	if err := h.store.markDelivered(ctx, msg.ID, providerMessageID); err != nil {
+		// The message was sent, so it must not be retried.
+		log15.Error("email outbox: recording delivered message", "id", msg.ID, "error", err)
+	}
+	return nil
+}
+
+// retry queues the message to be sent again after a backoff, or fails it with err if it
+// reached the maximum number of attempts.
+func (h *outboxHandler) retry(ctx context.Context, msg *OutboxMessage, err error) error {
+	failures := msg.NumFailures + 1
+	if failures >= maxAttempts {
+		return errcode.MakeNonRetryable(err)
+	}
+	after := h.now().Add(retryBackoff(failures))
+	if err := h.store.requeueAfterFailure(ctx, msg.ID, err.Error(), after); err != nil {
+		return errors.Wrap(err, "requeueing message")
+	}
+	log15.Warn("email outbox: failed to send message, retrying", "id", msg.ID, "attempt", failures, "retryAt", after, "error", err)
+	return nil
+}
+
+type outboxMetrics struct {
+	workerMetrics workerutil.WorkerMetrics
+	resets        prometheus.Counter
+	resetFailures prometheus.Counter
+	errors        prometheus.Counter
+}
+
+func newMetrics() outboxMetrics {
+	observationContext := &observation.Context{
+		Logger:     log15.Root(),
+		Tracer:     &trace.Tracer{Tracer: opentracing.GlobalTracer()},
+		Registerer: prometheus.DefaultRegisterer,
+	}
+
+	resetFailures := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "src_email_outbox_reset_failures_total",
+		Help: "The number of reset failures.",
+	})
+	observationContext.Registerer.MustRegister(resetFailures)
+
+	resets := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "src_email_outbox_resets_total",
+		Help: "The number of records reset.",
+	})
+	observationContext.Registerer.MustRegister(resets)
+
+	errors := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "src_email_outbox_errors_total",
+		Help: "The number of errors that occur during job.",
+	})
+	observationContext.Registerer.MustRegister(errors)
+
+	return outboxMetrics{
+		workerMetrics: workerutil.NewMetrics(observationContext, "email_outbox", nil),
+		resets:        resets,
+		resetFailures: resetFailures,
+		errors:        errors,
+	}
+}
@@ -0,0 +1,179 @@
+package txemail
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jordan-wright/email"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/txemail/txemailtest"
+	"github.com/sourcegraph/sourcegraph/internal/txemail/txtypes"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	want := []time.Duration{
+		30 * time.Second,
+		time.Minute,
+		2 * time.Minute,
+		4 * time.Minute,
+		8 * time.Minute,
+		16 * time.Minute,
+		32 * time.Minute,
+		time.Hour,
+		time.Hour,
+	}
+	var got []time.Duration
+	for failures := 1; failures < maxAttempts; failures++ {
+		got = append(got, retryBackoff(failures))
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("backoff mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOutboxHandler(t *testing.T) {
+	srv := txemailtest.NewServer()
+	defer srv.Close()
+
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	config := schema.SiteConfiguration{
+		EmailAddress: "noreply@example.com",
+		EmailHttp:    &schema.EmailHTTPConfig{Url: srv.URL},
+	}
+	store := &fakeOutboxStore{}
+	h := &outboxHandler{
+		store:  store,
+		config: func() schema.SiteConfiguration { return config },
+		now:    func() time.Time { return now },
+	}
+	newMessage := func(id, numFailures int) *OutboxMessage {
+		return &OutboxMessage{
+			ID:          id,
+			NumFailures: numFailures,
+			Recipients:  []string{"alice@example.com"},
+			Subject:     "Hello",
+			TextBody:    "Hi Alice",
+			HTMLBody:    "<p>Hi Alice</p>",
+			Headers:     map[string][]string{},
+		}
+	}
+	ctx := context.Background()
+
+	t.Run("delivered", func(t *testing.T) {
+		if err := h.Handle(ctx, newMessage(1, 0)); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]string{"delivered 1 1"}, store.calls); diff != "" {
+			t.Errorf("calls mismatch (-want +got):\n%s", diff)
+		}
+		if msgs := srv.Messages(); len(msgs) != 1 || msgs[0].From != "noreply@example.com" {
+			t.Errorf("got messages %+v", msgs)
+		}
+	})
+
+	t.Run("already delivered", func(t *testing.T) {
+		store.calls = nil
+		msg := newMessage(2, 0)
+		msg.DeliveredAt = &now
+		if err := h.Handle(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+		if len(store.calls) != 0 || len(srv.Messages()) != 1 {
+			t.Errorf("message was sent again")
+		}
+	})
+
+	t.Run("transient failure", func(t *testing.T) {
+		store.calls = nil
+		srv.FailNext(http.StatusServiceUnavailable)
+		if err := h.Handle(ctx, newMessage(3, 2)); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"requeued 3 after 2m0s: email HTTP API responded with status 503: Service Unavailable"}
+		if diff := cmp.Diff(want, store.calls); diff != "" {
+			t.Errorf("calls mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("last attempt", func(t *testing.T) {
+		store.calls = nil
+		srv.FailNext(http.StatusServiceUnavailable)
+		err := h.Handle(ctx, newMessage(4, maxAttempts-1))
+		if err == nil || !errcode.IsNonRetryable(err) {
+			t.Errorf("got error %v, want a non-retryable error", err)
+		}
+		if len(store.calls) != 0 {
+			t.Errorf("got calls %v, want none", store.calls)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		store.calls = nil
+		msg := newMessage(5, 0)
+		msg.Recipients = nil
+		err := h.Handle(ctx, msg)
+		if err == nil || !errcode.IsNonRetryable(err) {
+			t.Errorf("got error %v, want a non-retryable error", err)
+		}
+		if len(store.calls) != 0 {
+			t.Errorf("got calls %v, want none", store.calls)
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		store.calls = nil
+		config = schema.SiteConfiguration{EmailAddress: "noreply@example.com"}
+		if err := h.Handle(ctx, newMessage(6, 0)); err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"requeued 6 after 30s: no SMTP server or email HTTP API configured (in email.smtp or email.http)"}
+		if diff := cmp.Diff(want, store.calls); diff != "" {
+			t.Errorf("calls mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestSend_Direct(t *testing.T) {
+	var sent []*email.Email
+	MockTransport = TransportFunc(func(_ context.Context, m *email.Email) (string, error) {
+		sent = append(sent, m)
+		return "", nil
+	})
+	defer func() { MockTransport = nil }()
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{EmailAddress: "noreply@example.com"}})
+	defer conf.Mock(nil)
+
+	err := Send(context.Background(), Message{
+		To:       []string{"alice@example.com"},
+		Template: txtypes.Templates{Subject: "Hello {{.}}", Text: "Hi", HTML: "Hi"},
+		Data:     "Alice",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || sent[0].From != "noreply@example.com" || sent[0].Subject != "Hello Alice" {
+		t.Errorf("got sent messages %+v", sent)
+	}
+}
+
+type fakeOutboxStore struct {
+	calls []string
+}
+
+func (s *fakeOutboxStore) requeueAfterFailure(_ context.Context, id int, failureMessage string, after time.Time) error {
+	s.calls = append(s.calls, fmt.Sprintf("requeued %d after %s: %s", id, after.Sub(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)), failureMessage))
+	return nil
+}
+
+func (s *fakeOutboxStore) markDelivered(_ context.Context, id int, providerMessageID string) error {
+	s.calls = append(s.calls, fmt.Sprintf("delivered %d %s", id, providerMessageID))
+	return nil
+}
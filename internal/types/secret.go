@@ -57,6 +57,12 @@ func (e *ExternalService) RedactConfigSecrets() error {
 		newCfg, err = redactField(e.Config, fields...)
 	case *schema.BitbucketCloudConnection:
 		newCfg, err = redactField(e.Config, "appPassword")
+	case *schema.AzureDevOpsConnection:
+		newCfg, err = redactField(e.Config, "token")
+	case *schema.GerritConnection:
+		newCfg, err = redactField(e.Config, "password")
+	case *schema.GiteaConnection:
+		newCfg, err = redactField(e.Config, "token")
 	case *schema.AWSCodeCommitConnection:
 		newCfg, err = redactField(e.Config, "secretAccessKey")
 	case *schema.PhabricatorConnection:
@@ -134,6 +140,12 @@ func (e *ExternalService) UnredactConfig(old *ExternalService) error {
 		unredacted, err = unredactField(old.Config, e.Config, &cfg, fields...)
 	case *schema.BitbucketCloudConnection:
 		unredacted, err = unredactField(old.Config, e.Config, &cfg, jsonStringField{"appPassword", &cfg.AppPassword})
+	case *schema.AzureDevOpsConnection:
+		unredacted, err = unredactField(old.Config, e.Config, &cfg, jsonStringField{"token", &cfg.Token})
+	case *schema.GerritConnection:
+		unredacted, err = unredactField(old.Config, e.Config, &cfg, jsonStringField{"password", &cfg.Password})
+	case *schema.GiteaConnection:
+		unredacted, err = unredactField(old.Config, e.Config, &cfg, jsonStringField{"token", &cfg.Token})
 	case *schema.AWSCodeCommitConnection:
 		unredacted, err = unredactField(old.Config, e.Config, &cfg, jsonStringField{"secretAccessKey", &cfg.SecretAccessKey})
 	case *schema.PhabricatorConnection:
@@ -24,6 +24,18 @@ func TestRoundTripRedactExternalServiceConfig(t *testing.T) {
 		AppPassword: someSecret,
 		Url:         "https://bitbucket.com",
 	}
+	azureDevOpsConfig := schema.AzureDevOpsConnection{
+		Token: someSecret,
+		Url:   "https://dev.azure.com",
+	}
+	gerritConfig := schema.GerritConnection{
+		Password: someSecret,
+		Url:      "https://gerrit.sgdev.org",
+	}
+	giteaConfig := schema.GiteaConnection{
+		Token: someSecret,
+		Url:   "https://gitea.example.com",
+	}
 	bitbucketServerConfigWithPassword := schema.BitbucketServerConnection{
 		Password: someSecret,
 		Url:      "https://bitbucket.com",
@@ -75,6 +87,24 @@ func TestRoundTripRedactExternalServiceConfig(t *testing.T) {
 			editField:   &bitbucketCloudConfig.Url,
 			secretField: &bitbucketCloudConfig.AppPassword,
 		},
+		{
+			kind:        extsvc.KindAzureDevOps,
+			config:      &azureDevOpsConfig,
+			editField:   &azureDevOpsConfig.Url,
+			secretField: &azureDevOpsConfig.Token,
+		},
+		{
+			kind:        extsvc.KindGerrit,
+			config:      &gerritConfig,
+			editField:   &gerritConfig.Url,
+			secretField: &gerritConfig.Password,
+		},
+		{
+			kind:        extsvc.KindGitea,
+			config:      &giteaConfig,
+			editField:   &giteaConfig.Url,
+			secretField: &giteaConfig.Token,
+		},
 		// BitbucketServer can have a password OR token, not both
 		{
 			kind:        extsvc.KindBitbucketServer,
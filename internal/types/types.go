@@ -629,6 +629,8 @@ type User struct {
 	BuiltinAuth           bool
 	Tags                  []string
 	InvalidatedSessionsAt time.Time
+	// DeactivatedAt is when the user was deactivated, if they are.
+	DeactivatedAt *time.Time
 }
 
 type Org struct {
@@ -191,6 +191,36 @@ func HasCommitAfter(ctx context.Context, repo api.RepoName, date string, revspec
 	return n > 0, err
 }
 
+// LastCommitBefore returns the most recent commit reachable from revspec that
+// was committed before the specified date. It returns nil if there is no such
+// commit.
+func LastCommitBefore(ctx context.Context, repo api.RepoName, date string, revspec string) (*Commit, error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "Git: LastCommitBefore")
+	span.SetTag("Date", date)
+	span.SetTag("RevSpec", revspec)
+	defer span.Finish()
+
+	if revspec == "" {
+		revspec = "HEAD"
+	}
+
+	commitid, err := ResolveRevision(ctx, repo, revspec, ResolveRevisionOptions{NoEnsureRevision: true})
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := Commits(ctx, repo, CommitsOptions{
+		N:                1,
+		Before:           date,
+		Range:            string(commitid),
+		NoEnsureRevision: true,
+	})
+	if err != nil || len(commits) == 0 {
+		return nil, err
+	}
+	return commits[0], nil
+}
+
 func isBadObjectErr(output, obj string) bool {
 	return output == "fatal: bad object "+obj
 }
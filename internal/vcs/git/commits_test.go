@@ -160,6 +160,42 @@ func TestRepository_HasCommitAfter(t *testing.T) {
 	}
 }
 
+func TestRepository_LastCommitBefore(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	repo := MakeGitRepository(t,
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2006-01-02T15:04:05Z git commit --allow-empty -m foo --author='a <a@a.com>'",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2007-01-02T15:04:05Z git commit --allow-empty -m foo --author='a <a@a.com>'",
+		"GIT_COMMITTER_NAME=a GIT_COMMITTER_EMAIL=a@a.com GIT_COMMITTER_DATE=2008-01-02T15:04:05Z git commit --allow-empty -m foo --author='a <a@a.com>'",
+	)
+
+	testCases := []struct {
+		before  string
+		revspec string
+		want    string
+	}{
+		{before: "2007-06-01", revspec: "master", want: "2007-01-02T15:04:05Z"},
+		{before: "2010-01-01", revspec: "", want: "2008-01-02T15:04:05Z"},
+		{before: "2006-01-02T15:04:05Z", revspec: "HEAD", want: "2006-01-02T15:04:05Z"},
+		{before: "2005-01-01", revspec: "HEAD", want: ""},
+	}
+
+	for _, tc := range testCases {
+		commit, err := LastCommitBefore(ctx, repo, tc.before, tc.revspec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if commit != nil {
+			got = commit.Committer.Date.Format(time.RFC3339)
+		}
+		if got != tc.want {
+			t.Errorf("before %q: got %q, want %q", tc.before, got, tc.want)
+		}
+	}
+}
+
 func TestRepository_FirstEverCommit(t *testing.T) {
 	t.Parallel()
 	ctx := context.Background()
@@ -112,7 +112,7 @@ var (
 		"--patch", "--unified", "-S", "-G", "--pickaxe-all", "--pickaxe-regex", "--function-context", "--branches", "--source", "--src-prefix", "--dst-prefix", "--no-prefix",
 		"--regexp-ignore-case", "--glob", "--cherry", "-z",
 		"--until", "--since", "--author", "--committer",
-		"--all-match", "--invert-grep", "--extended-regexp",
+		"--all-match", "--invert-grep", "--extended-regexp", "--no-walk",
 		"--no-color", "--decorate", "--no-patch", "--exclude",
 		"--no-merges",
 		"--full-index",
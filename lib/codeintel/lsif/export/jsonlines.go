@@ -0,0 +1,269 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+// WriteJSONLines writes the given bundle to w as newline-delimited JSON. This format mirrors
+// the way processed bundles are stored and is simpler to consume than an LSIF graph. Each line
+// is one of the following objects, distinguished by its type field:
+//
+//   - JSONLinesDocument: the ranges, hover text, monikers, and diagnostics of a single document.
+//     Lines of this type are written first, ordered by path.
+//   - JSONLinesResult: the ranges composing a single definition or reference result. Ranges of
+//     a document refer to results by identifier.
+//   - JSONLinesMonikerLocations: the locations of the definitions or references of a moniker.
+//
+// Identifiers of ranges, results, hover results, monikers, and package information are opaque
+// strings that are unique within the bundle.
+func WriteJSONLines(w io.Writer, bundle *semantic.GroupedBundleDataMaps) error {
+	bufferedWriter := bufio.NewWriter(w)
+	encoder := json.NewEncoder(bufferedWriter)
+
+	for _, path := range sortedDocumentPaths(bundle) {
+		if err := encoder.Encode(newJSONLinesDocument(path, bundle.Documents[path])); err != nil {
+			return err
+		}
+	}
+
+	indexes := make([]int, 0, len(bundle.ResultChunks))
+	for index := range bundle.ResultChunks {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		resultChunk := bundle.ResultChunks[index]
+
+		ids := make([]semantic.ID, 0, len(resultChunk.DocumentIDRangeIDs))
+		for id := range resultChunk.DocumentIDRangeIDs {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		for _, id := range ids {
+			ranges := make([]JSONLinesRangeRef, 0, len(resultChunk.DocumentIDRangeIDs[id]))
+			for _, documentIDRangeID := range resultChunk.DocumentIDRangeIDs[id] {
+				ranges = append(ranges, JSONLinesRangeRef{
+					Path:    resultChunk.DocumentPaths[documentIDRangeID.DocumentID],
+					RangeID: string(documentIDRangeID.RangeID),
+				})
+			}
+
+			if err := encoder.Encode(JSONLinesResult{Type: "result", ID: string(id), Ranges: ranges}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writeMonikerLocations(encoder, "definitions", bundle.Definitions); err != nil {
+		return err
+	}
+	if err := writeMonikerLocations(encoder, "references", bundle.References); err != nil {
+		return err
+	}
+
+	return bufferedWriter.Flush()
+}
+
+// JSONLinesDocument is a line of type "document".
+type JSONLinesDocument struct {
+	Type               string                                 `json:"type"`
+	Path               string                                 `json:"path"`
+	Ranges             []JSONLinesRange                       `json:"ranges"`
+	HoverResults       map[string]string                      `json:"hoverResults"`
+	Monikers           map[string]JSONLinesMoniker            `json:"monikers"`
+	PackageInformation map[string]JSONLinesPackageInformation `json:"packageInformation"`
+	Diagnostics        []JSONLinesDiagnostic                  `json:"diagnostics"`
+}
+
+// JSONLinesRange is a range within a document. Positions are 0-indexed. Result, hover, and
+// moniker identifiers are empty if the range has no such data.
+type JSONLinesRange struct {
+	ID                 string   `json:"id"`
+	StartLine          int      `json:"startLine"`
+	StartCharacter     int      `json:"startCharacter"`
+	EndLine            int      `json:"endLine"`
+	EndCharacter       int      `json:"endCharacter"`
+	DefinitionResultID string   `json:"definitionResultId,omitempty"`
+	ReferenceResultID  string   `json:"referenceResultId,omitempty"`
+	HoverResultID      string   `json:"hoverResultId,omitempty"`
+	MonikerIDs         []string `json:"monikerIds,omitempty"`
+}
+
+// JSONLinesMoniker is a moniker attached to a range.
+type JSONLinesMoniker struct {
+	Kind                 string `json:"kind"`
+	Scheme               string `json:"scheme"`
+	Identifier           string `json:"identifier"`
+	PackageInformationID string `json:"packageInformationId,omitempty"`
+}
+
+// JSONLinesPackageInformation is the package information of a moniker.
+type JSONLinesPackageInformation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// JSONLinesDiagnostic is a diagnostic reported for a document.
+type JSONLinesDiagnostic struct {
+	Severity       int    `json:"severity"`
+	Code           string `json:"code"`
+	Message        string `json:"message"`
+	Source         string `json:"source"`
+	StartLine      int    `json:"startLine"`
+	StartCharacter int    `json:"startCharacter"`
+	EndLine        int    `json:"endLine"`
+	EndCharacter   int    `json:"endCharacter"`
+}
+
+// JSONLinesResult is a line of type "result".
+type JSONLinesResult struct {
+	Type   string              `json:"type"`
+	ID     string              `json:"id"`
+	Ranges []JSONLinesRangeRef `json:"ranges"`
+}
+
+// JSONLinesRangeRef refers to a range of the document with the given path.
+type JSONLinesRangeRef struct {
+	Path    string `json:"path"`
+	RangeID string `json:"rangeId"`
+}
+
+// JSONLinesMonikerLocations is a line of type "definitions" or "references".
+type JSONLinesMonikerLocations struct {
+	Type       string              `json:"type"`
+	Scheme     string              `json:"scheme"`
+	Identifier string              `json:"identifier"`
+	Locations  []JSONLinesLocation `json:"locations"`
+}
+
+// JSONLinesLocation is a range qualified by the path of its containing document.
+type JSONLinesLocation struct {
+	Path           string `json:"path"`
+	StartLine      int    `json:"startLine"`
+	StartCharacter int    `json:"startCharacter"`
+	EndLine        int    `json:"endLine"`
+	EndCharacter   int    `json:"endCharacter"`
+}
+
+func newJSONLinesDocument(path string, document semantic.DocumentData) JSONLinesDocument {
+	rangeIDs := make([]semantic.ID, 0, len(document.Ranges))
+	for id := range document.Ranges {
+		rangeIDs = append(rangeIDs, id)
+	}
+	sort.Slice(rangeIDs, func(i, j int) bool {
+		return semantic.CompareRanges(document.Ranges[rangeIDs[i]], document.Ranges[rangeIDs[j]]) < 0
+	})
+
+	ranges := make([]JSONLinesRange, 0, len(rangeIDs))
+	for _, id := range rangeIDs {
+		r := document.Ranges[id]
+
+		var monikerIDs []string
+		for _, monikerID := range r.MonikerIDs {
+			monikerIDs = append(monikerIDs, string(monikerID))
+		}
+
+		ranges = append(ranges, JSONLinesRange{
+			ID:                 string(id),
+			StartLine:          r.StartLine,
+			StartCharacter:     r.StartCharacter,
+			EndLine:            r.EndLine,
+			EndCharacter:       r.EndCharacter,
+			DefinitionResultID: string(r.DefinitionResultID),
+			ReferenceResultID:  string(r.ReferenceResultID),
+			HoverResultID:      string(r.HoverResultID),
+			MonikerIDs:         monikerIDs,
+		})
+	}
+
+	hoverResults := make(map[string]string, len(document.HoverResults))
+	for id, text := range document.HoverResults {
+		hoverResults[string(id)] = text
+	}
+
+	monikers := make(map[string]JSONLinesMoniker, len(document.Monikers))
+	for id, moniker := range document.Monikers {
+		monikers[string(id)] = JSONLinesMoniker{
+			Kind:                 moniker.Kind,
+			Scheme:               moniker.Scheme,
+			Identifier:           moniker.Identifier,
+			PackageInformationID: string(moniker.PackageInformationID),
+		}
+	}
+
+	packageInformation := make(map[string]JSONLinesPackageInformation, len(document.PackageInformation))
+	for id, data := range document.PackageInformation {
+		packageInformation[string(id)] = JSONLinesPackageInformation{Name: data.Name, Version: data.Version}
+	}
+
+	diagnostics := make([]JSONLinesDiagnostic, 0, len(document.Diagnostics))
+	for _, diagnostic := range document.Diagnostics {
+		diagnostics = append(diagnostics, JSONLinesDiagnostic{
+			Severity:       diagnostic.Severity,
+			Code:           diagnostic.Code,
+			Message:        diagnostic.Message,
+			Source:         diagnostic.Source,
+			StartLine:      diagnostic.StartLine,
+			StartCharacter: diagnostic.StartCharacter,
+			EndLine:        diagnostic.EndLine,
+			EndCharacter:   diagnostic.EndCharacter,
+		})
+	}
+
+	return JSONLinesDocument{
+		Type:               "document",
+		Path:               path,
+		Ranges:             ranges,
+		HoverResults:       hoverResults,
+		Monikers:           monikers,
+		PackageInformation: packageInformation,
+		Diagnostics:        diagnostics,
+	}
+}
+
+func writeMonikerLocations(encoder *json.Encoder, typ string, monikers map[string]map[string][]semantic.LocationData) error {
+	schemes := make([]string, 0, len(monikers))
+	for scheme := range monikers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+
+	for _, scheme := range schemes {
+		identifiers := make([]string, 0, len(monikers[scheme]))
+		for identifier := range monikers[scheme] {
+			identifiers = append(identifiers, identifier)
+		}
+		sort.Strings(identifiers)
+
+		for _, identifier := range identifiers {
+			locations := make([]JSONLinesLocation, 0, len(monikers[scheme][identifier]))
+			for _, location := range monikers[scheme][identifier] {
+				locations = append(locations, JSONLinesLocation{
+					Path:           location.URI,
+					StartLine:      location.StartLine,
+					StartCharacter: location.StartCharacter,
+					EndLine:        location.EndLine,
+					EndCharacter:   location.EndCharacter,
+				})
+			}
+
+			if err := encoder.Encode(JSONLinesMonikerLocations{
+				Type:       typ,
+				Scheme:     scheme,
+				Identifier: identifier,
+				Locations:  locations,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteJSONLines(t *testing.T) {
+	bundle := readBundle(t, "../testdata/dump1.lsif")
+
+	var buf bytes.Buffer
+	if err := WriteJSONLines(&buf, bundle); err != nil {
+		t.Fatalf("unexpected error writing JSON lines: %s", err)
+	}
+
+	var types []string
+	var document JSONLinesDocument
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("unexpected error decoding line: %s", err)
+		}
+		types = append(types, line.Type)
+
+		if line.Type == "document" && document.Path == "" {
+			if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+				t.Fatalf("unexpected error decoding document: %s", err)
+			}
+		}
+	}
+
+	expectedTypes := []string{
+		"document",
+		"document",
+		"result",
+		"result",
+		"result",
+		"result",
+		"definitions",
+		"definitions",
+		"definitions",
+		"references",
+		"references",
+		"references",
+	}
+	if diff := cmp.Diff(expectedTypes, types); diff != "" {
+		t.Errorf("unexpected line types (-want +got):\n%s", diff)
+	}
+
+	expectedRanges := []JSONLinesRange{
+		{ID: "7", StartLine: 4, StartCharacter: 5, EndLine: 6, EndCharacter: 7, ReferenceResultID: "15", MonikerIDs: []string{"18"}},
+		{ID: "8", StartLine: 5, StartCharacter: 6, EndLine: 7, EndCharacter: 8, HoverResultID: "17"},
+		{ID: "9", StartLine: 6, StartCharacter: 7, EndLine: 8, EndCharacter: 9, DefinitionResultID: "12", ReferenceResultID: "14", HoverResultID: "16", MonikerIDs: []string{"19", "20", "21"}},
+	}
+	if document.Path != "root/bar.go" {
+		t.Fatalf("unexpected first document. want=%q have=%q", "root/bar.go", document.Path)
+	}
+	if diff := cmp.Diff(expectedRanges, document.Ranges); diff != "" {
+		t.Errorf("unexpected ranges (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,273 @@
+// Package export converts processed bundle data back into a serialized form that can be
+// consumed by tools outside of Sourcegraph. It is the inverse of the conversion package.
+package export
+
+import (
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol/writer"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+// WriteLSIF writes the given bundle to w as an LSIF graph. The given root is the directory
+// of the bundle relative to the repository root, and is used to construct the project root
+// and document URIs of the output. Re-uploading the output with the same root produces an
+// equivalent bundle.
+//
+// The graph does not contain result sets: each range links directly to its hover result,
+// monikers, and definition and reference results. Documentation data is not exported.
+func WriteLSIF(w io.Writer, bundle *semantic.GroupedBundleDataMaps, root string, info protocol.ToolInfo) error {
+	jsonWriter := writer.NewJSONWriter(w)
+	emitter := writer.NewEmitter(jsonWriter)
+
+	projectRoot := "/" + strings.Trim(root, "/")
+	if projectRoot != "/" {
+		projectRoot += "/"
+	}
+	emitter.EmitMetaData("file://"+projectRoot, info)
+
+	e := &lsifExporter{
+		bundle:            bundle,
+		emitter:           emitter,
+		documentIDs:       map[string]uint64{},
+		rangeIDs:          map[semantic.DocumentPathRangeID]uint64{},
+		hoverResultIDs:    map[semantic.ID]uint64{},
+		monikerIDs:        map[semantic.ID]uint64{},
+		packageIDs:        map[semantic.ID]uint64{},
+		definitionResults: map[semantic.ID][]uint64{},
+		referenceResults:  map[semantic.ID][]uint64{},
+	}
+
+	for _, path := range sortedDocumentPaths(bundle) {
+		e.emitDocument(projectRoot, path, bundle.Documents[path])
+	}
+	e.emitResults(e.definitionResults, false)
+	e.emitResults(e.referenceResults, true)
+
+	return emitter.Flush()
+}
+
+type lsifExporter struct {
+	bundle  *semantic.GroupedBundleDataMaps
+	emitter *writer.Emitter
+
+	// Identifiers of the vertices already written to the output.
+	documentIDs    map[string]uint64
+	rangeIDs       map[semantic.DocumentPathRangeID]uint64
+	hoverResultIDs map[semantic.ID]uint64
+	monikerIDs     map[semantic.ID]uint64
+	packageIDs     map[semantic.ID]uint64
+
+	// Map from definition and reference result identifiers to the range vertices
+	// that refer to them. Result vertices are emitted after all documents so that
+	// item edges can refer to ranges in any document.
+	definitionResults map[semantic.ID][]uint64
+	referenceResults  map[semantic.ID][]uint64
+}
+
+func (e *lsifExporter) emitDocument(projectRoot, documentPath string, document semantic.DocumentData) {
+	documentID := e.emitter.EmitDocument("", path.Join(projectRoot, documentPath))
+	e.documentIDs[documentPath] = documentID
+
+	rangeIDs := make([]semantic.ID, 0, len(document.Ranges))
+	for id := range document.Ranges {
+		rangeIDs = append(rangeIDs, id)
+	}
+	sort.Slice(rangeIDs, func(i, j int) bool {
+		return semantic.CompareRanges(document.Ranges[rangeIDs[i]], document.Ranges[rangeIDs[j]]) < 0
+	})
+
+	rangeVertexIDs := make([]uint64, 0, len(rangeIDs))
+	for _, id := range rangeIDs {
+		r := document.Ranges[id]
+		rangeID := e.emitter.EmitRange(
+			protocol.Pos{Line: r.StartLine, Character: r.StartCharacter},
+			protocol.Pos{Line: r.EndLine, Character: r.EndCharacter},
+		)
+		e.rangeIDs[semantic.DocumentPathRangeID{Path: documentPath, RangeID: id}] = rangeID
+		rangeVertexIDs = append(rangeVertexIDs, rangeID)
+	}
+	if len(rangeVertexIDs) > 0 {
+		e.emitter.EmitContains(documentID, rangeVertexIDs)
+	}
+
+	for i, id := range rangeIDs {
+		r := document.Ranges[id]
+		rangeID := rangeVertexIDs[i]
+
+		if r.HoverResultID != "" {
+			if hoverResultID, ok := e.hoverResultID(document, r.HoverResultID); ok {
+				e.emitter.EmitTextDocumentHover(rangeID, hoverResultID)
+			}
+		}
+
+		for _, monikerID := range r.MonikerIDs {
+			if monikerVertexID, ok := e.monikerID(document, monikerID); ok {
+				e.emitter.EmitMonikerEdge(rangeID, monikerVertexID)
+			}
+		}
+
+		if r.DefinitionResultID != "" {
+			e.definitionResults[r.DefinitionResultID] = append(e.definitionResults[r.DefinitionResultID], rangeID)
+		}
+		if r.ReferenceResultID != "" {
+			e.referenceResults[r.ReferenceResultID] = append(e.referenceResults[r.ReferenceResultID], rangeID)
+		}
+	}
+
+	if len(document.Diagnostics) > 0 {
+		diagnostics := make([]protocol.Diagnostic, 0, len(document.Diagnostics))
+		for _, diagnostic := range document.Diagnostics {
+			diagnostics = append(diagnostics, protocol.Diagnostic{
+				Severity: diagnostic.Severity,
+				Code:     diagnostic.Code,
+				Message:  diagnostic.Message,
+				Source:   diagnostic.Source,
+				Range: protocol.RangeData{
+					Start: protocol.Pos{Line: diagnostic.StartLine, Character: diagnostic.StartCharacter},
+					End:   protocol.Pos{Line: diagnostic.EndLine, Character: diagnostic.EndCharacter},
+				},
+			})
+		}
+
+		e.emitter.EmitTextDocumentDiagnostic(documentID, e.emitter.EmitDiagnosticResult(diagnostics))
+	}
+}
+
+// hoverResultID returns the identifier of the hover result vertex with the given identifier,
+// emitting it on first use. Hover result identifiers are unique within a bundle.
+func (e *lsifExporter) hoverResultID(document semantic.DocumentData, id semantic.ID) (uint64, bool) {
+	if hoverResultID, ok := e.hoverResultIDs[id]; ok {
+		return hoverResultID, true
+	}
+
+	text, ok := document.HoverResults[id]
+	if !ok {
+		return 0, false
+	}
+
+	hoverResultID := e.emitter.EmitHoverResult(protocol.NewMarkupContent(text, protocol.Markdown))
+	e.hoverResultIDs[id] = hoverResultID
+	return hoverResultID, true
+}
+
+// monikerID returns the identifier of the moniker vertex with the given identifier, emitting
+// it and its package information on first use. Moniker identifiers are unique within a bundle.
+func (e *lsifExporter) monikerID(document semantic.DocumentData, id semantic.ID) (uint64, bool) {
+	if monikerID, ok := e.monikerIDs[id]; ok {
+		return monikerID, true
+	}
+
+	moniker, ok := document.Monikers[id]
+	if !ok {
+		return 0, false
+	}
+
+	monikerID := e.emitter.EmitMoniker(moniker.Kind, moniker.Scheme, moniker.Identifier)
+	e.monikerIDs[id] = monikerID
+
+	if moniker.PackageInformationID != "" {
+		packageID, ok := e.packageIDs[moniker.PackageInformationID]
+		if !ok {
+			if packageInformation, exists := document.PackageInformation[moniker.PackageInformationID]; exists {
+				packageID = e.emitter.EmitPackageInformation(packageInformation.Name, moniker.Scheme, packageInformation.Version)
+				e.packageIDs[moniker.PackageInformationID] = packageID
+				ok = true
+			}
+		}
+		if ok {
+			e.emitter.EmitPackageInformationEdge(monikerID, packageID)
+		}
+	}
+
+	return monikerID, true
+}
+
+// emitResults emits a definition or reference result vertex for each of the given result
+// identifiers. Each result is linked to the ranges it contains via item edges (one per
+// containing document), and to the ranges that refer to it.
+func (e *lsifExporter) emitResults(results map[semantic.ID][]uint64, references bool) {
+	resultIDs := make([]semantic.ID, 0, len(results))
+	for id := range results {
+		resultIDs = append(resultIDs, id)
+	}
+	sort.Slice(resultIDs, func(i, j int) bool { return resultIDs[i] < resultIDs[j] })
+
+	for _, id := range resultIDs {
+		var resultID uint64
+		if references {
+			resultID = e.emitter.EmitReferenceResult()
+		} else {
+			resultID = e.emitter.EmitDefinitionResult()
+		}
+
+		for _, item := range e.resultItems(id) {
+			if references {
+				e.emitter.EmitItemOfReferences(resultID, item.rangeIDs, item.documentID)
+			} else {
+				e.emitter.EmitItem(resultID, item.rangeIDs, item.documentID)
+			}
+		}
+
+		for _, rangeID := range results[id] {
+			if references {
+				e.emitter.EmitTextDocumentReferences(rangeID, resultID)
+			} else {
+				e.emitter.EmitTextDocumentDefinition(rangeID, resultID)
+			}
+		}
+	}
+}
+
+type resultItem struct {
+	documentID uint64
+	rangeIDs   []uint64
+}
+
+// resultItems returns the range vertices of the given definition or reference result grouped
+// by their containing document. Ranges which were not emitted are skipped.
+func (e *lsifExporter) resultItems(id semantic.ID) []resultItem {
+	if e.bundle.Meta.NumResultChunks == 0 {
+		return nil
+	}
+	resultChunk, ok := e.bundle.ResultChunks[semantic.HashKey(id, e.bundle.Meta.NumResultChunks)]
+	if !ok {
+		return nil
+	}
+
+	var items []resultItem
+	itemIndexes := map[uint64]int{}
+	for _, documentIDRangeID := range resultChunk.DocumentIDRangeIDs[id] {
+		documentPath := resultChunk.DocumentPaths[documentIDRangeID.DocumentID]
+
+		rangeID, ok := e.rangeIDs[semantic.DocumentPathRangeID{Path: documentPath, RangeID: documentIDRangeID.RangeID}]
+		if !ok {
+			continue
+		}
+		documentID := e.documentIDs[documentPath]
+
+		index, ok := itemIndexes[documentID]
+		if !ok {
+			index = len(items)
+			itemIndexes[documentID] = index
+			items = append(items, resultItem{documentID: documentID})
+		}
+		items[index].rangeIDs = append(items[index].rangeIDs, rangeID)
+	}
+
+	return items
+}
+
+func sortedDocumentPaths(bundle *semantic.GroupedBundleDataMaps) []string {
+	paths := make([]string, 0, len(bundle.Documents))
+	for path := range bundle.Documents {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
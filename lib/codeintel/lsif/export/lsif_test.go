@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/protocol"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/validation"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic/diff"
+)
+
+var testDumps = []struct {
+	path string
+	// The synthetic dump1 fixture contains overlapping ranges, so neither it nor
+	// its export passes validation.
+	valid bool
+}{
+	{path: "../testdata/dump1.lsif", valid: false},
+	{path: "../testdata/dump2.lsif", valid: true},
+	{path: "../testdata/dump3.lsif", valid: true},
+	{path: "../../semantic/diff/testdata/project1/dump.lsif", valid: true},
+}
+
+func TestWriteLSIF(t *testing.T) {
+	for _, testDump := range testDumps {
+		t.Run(testDump.path, func(t *testing.T) {
+			bundle := readBundle(t, testDump.path)
+
+			var buf bytes.Buffer
+			if err := WriteLSIF(&buf, bundle, "sub/dir/", protocol.ToolInfo{Name: "test"}); err != nil {
+				t.Fatalf("unexpected error writing LSIF: %s", err)
+			}
+
+			if testDump.valid {
+				ctx := validation.NewValidationContext()
+				validator := &validation.Validator{Context: ctx}
+				if err := validator.Validate(bytes.NewReader(buf.Bytes())); err != nil {
+					t.Fatalf("unexpected error validating LSIF: %s", err)
+				}
+				for _, err := range ctx.Errors {
+					t.Errorf("unexpected validation error: %s", err)
+				}
+			}
+
+			// Re-processing the output should result in an equivalent bundle
+			chans, err := conversion.Correlate(context.Background(), bytes.NewReader(buf.Bytes()), "sub/dir/", nil)
+			if err != nil {
+				t.Fatalf("unexpected error correlating exported LSIF: %s", err)
+			}
+			if diff := diff.Diff(bundle, semantic.GroupedBundleDataChansToMaps(chans)); diff != "" {
+				t.Errorf("unexpected difference after round trip:\n%s", diff)
+			}
+		})
+	}
+}
+
+func readBundle(t *testing.T, dumpPath string) *semantic.GroupedBundleDataMaps {
+	input, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatalf("unexpected error reading test file: %s", err)
+	}
+
+	chans, err := conversion.Correlate(context.Background(), bytes.NewReader(input), "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error correlating input: %s", err)
+	}
+
+	return semantic.GroupedBundleDataChansToMaps(chans)
+}
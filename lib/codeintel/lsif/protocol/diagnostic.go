@@ -0,0 +1,47 @@
+package protocol
+
+type DiagnosticResult struct {
+	Vertex
+	Result []Diagnostic `json:"result"`
+}
+
+type Diagnostic struct {
+	Severity int       `json:"severity"`
+	Code     string    `json:"code"`
+	Message  string    `json:"message"`
+	Source   string    `json:"source"`
+	Range    RangeData `json:"range"`
+}
+
+func NewDiagnosticResult(id uint64, result []Diagnostic) DiagnosticResult {
+	return DiagnosticResult{
+		Vertex: Vertex{
+			Element: Element{
+				ID:   id,
+				Type: ElementVertex,
+			},
+			Label: VertexDianosticResult,
+		},
+		Result: result,
+	}
+}
+
+type TextDocumentDiagnostic struct {
+	Edge
+	OutV uint64 `json:"outV"`
+	InV  uint64 `json:"inV"`
+}
+
+func NewTextDocumentDiagnostic(id, outV, inV uint64) TextDocumentDiagnostic {
+	return TextDocumentDiagnostic{
+		Edge: Edge{
+			Element: Element{
+				ID:   id,
+				Type: ElementEdge,
+			},
+			Label: EdgeTextDocumentDiagnostic,
+		},
+		OutV: outV,
+		InV:  inV,
+	}
+}
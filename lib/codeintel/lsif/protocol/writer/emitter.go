@@ -158,6 +158,18 @@ func (e *Emitter) EmitPackageInformationEdge(outV, inV uint64) uint64 {
 	return id
 }
 
+func (e *Emitter) EmitDiagnosticResult(result []protocol.Diagnostic) uint64 {
+	id := e.nextID()
+	e.writer.Write(protocol.NewDiagnosticResult(id, result))
+	return id
+}
+
+func (e *Emitter) EmitTextDocumentDiagnostic(outV, inV uint64) uint64 {
+	id := e.nextID()
+	e.writer.Write(protocol.NewTextDocumentDiagnostic(id, outV, inV))
+	return id
+}
+
 func (e *Emitter) EmitContains(outV uint64, inVs []uint64) uint64 {
 	id := e.nextID()
 	e.writer.Write(protocol.NewContains(id, outV, inVs))
@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"sort"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+// Symbol is a moniker-identified symbol defined within a bundle, such as an exported
+// function or type. Symbols are identified by scheme and identifier rather than by
+// location so that they can be compared across bundles for different commits.
+type Symbol struct {
+	Scheme      string
+	Identifier  string
+	Hover       string
+	Definitions []semantic.LocationData
+}
+
+// ChangedSymbol pairs the old and new versions of a symbol that exists in both bundles
+// but whose hover text or set of defining documents differs.
+type ChangedSymbol struct {
+	Old Symbol
+	New Symbol
+}
+
+// SymbolDiff describes the symbols added, removed, or changed between two bundles.
+type SymbolDiff struct {
+	Added   []Symbol
+	Removed []Symbol
+	Changed []ChangedSymbol
+}
+
+// Symbols returns the symbols defined in the given bundle ordered by scheme and identifier.
+// Only the definitions and documents (ranges and hover results) of the bundle are read;
+// a partial bundle consisting of this data is sufficient.
+func Symbols(bundle *semantic.GroupedBundleDataMaps) []Symbol {
+	var symbols []Symbol
+	for scheme, identifiers := range bundle.Definitions {
+		for identifier, locations := range identifiers {
+			definitions := make([]semantic.LocationData, len(locations))
+			copy(definitions, locations)
+			sortLocations(definitions)
+
+			symbols = append(symbols, Symbol{
+				Scheme:      scheme,
+				Identifier:  identifier,
+				Hover:       hoverAtLocations(bundle, definitions),
+				Definitions: definitions,
+			})
+		}
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		return compareSymbols(symbols[i], symbols[j]) < 0
+	})
+
+	return symbols
+}
+
+// DiffSymbols compares the symbols defined in the given bundles. A symbol is considered
+// changed if its hover text differs or it is defined in a different set of documents.
+// Definitions that only move within the same documents are not considered changes.
+func DiffSymbols(old, new *semantic.GroupedBundleDataMaps) SymbolDiff {
+	oldSymbols := Symbols(old)
+	newSymbols := Symbols(new)
+
+	var diff SymbolDiff
+	i, j := 0, 0
+	for i < len(oldSymbols) || j < len(newSymbols) {
+		var cmp int
+		switch {
+		case i >= len(oldSymbols):
+			cmp = 1
+		case j >= len(newSymbols):
+			cmp = -1
+		default:
+			cmp = compareSymbols(oldSymbols[i], newSymbols[j])
+		}
+
+		switch {
+		case cmp < 0:
+			diff.Removed = append(diff.Removed, oldSymbols[i])
+			i++
+		case cmp > 0:
+			diff.Added = append(diff.Added, newSymbols[j])
+			j++
+		default:
+			if symbolChanged(oldSymbols[i], newSymbols[j]) {
+				diff.Changed = append(diff.Changed, ChangedSymbol{Old: oldSymbols[i], New: newSymbols[j]})
+			}
+			i++
+			j++
+		}
+	}
+
+	return diff
+}
+
+func compareSymbols(a, b Symbol) int {
+	if a.Scheme != b.Scheme {
+		if a.Scheme < b.Scheme {
+			return -1
+		}
+		return 1
+	}
+	if a.Identifier != b.Identifier {
+		if a.Identifier < b.Identifier {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func symbolChanged(old, new Symbol) bool {
+	if old.Hover != new.Hover {
+		return true
+	}
+
+	oldPaths := definitionPaths(old)
+	newPaths := definitionPaths(new)
+	if len(oldPaths) != len(newPaths) {
+		return true
+	}
+	for path := range oldPaths {
+		if _, ok := newPaths[path]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func definitionPaths(symbol Symbol) map[string]struct{} {
+	paths := make(map[string]struct{}, len(symbol.Definitions))
+	for _, location := range symbol.Definitions {
+		paths[location.URI] = struct{}{}
+	}
+	return paths
+}
+
+// hoverAtLocations returns the first non-empty hover text attached to a range exactly
+// matching one of the given locations.
+func hoverAtLocations(bundle *semantic.GroupedBundleDataMaps, locations []semantic.LocationData) string {
+	for _, location := range locations {
+		document, ok := bundle.Documents[location.URI]
+		if !ok {
+			continue
+		}
+
+		for _, r := range semantic.FindRanges(document.Ranges, location.StartLine, location.StartCharacter) {
+			if r.StartLine != location.StartLine || r.StartCharacter != location.StartCharacter || r.EndLine != location.EndLine || r.EndCharacter != location.EndCharacter {
+				continue
+			}
+
+			if hover := document.HoverResults[r.HoverResultID]; hover != "" {
+				return hover
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/lsif/conversion"
+	"github.com/sourcegraph/sourcegraph/lib/codeintel/semantic"
+)
+
+func TestDiffSymbolsOnPermutedDumps(t *testing.T) {
+	bundle1 := readBundle(t, dumpPath)
+	bundle2 := readBundle(t, dumpPermutedPath)
+
+	diff := DiffSymbols(bundle1, bundle2)
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Changed) != 0 {
+		t.Fatalf("expected no symbol changes between %v and %v, got %+v", dumpPath, dumpPermutedPath, diff)
+	}
+}
+
+func TestDiffSymbolsOnEditedDumps(t *testing.T) {
+	diff := DiffSymbols(readBundle(t, dumpOldPath), readBundle(t, dumpNewPath))
+
+	if len(diff.Removed) != 1 || diff.Removed[0].Identifier != "github.com/sourcegraph/sourcegraph/lib/codeintel/semantic/diff/testdata/project1:Function1" {
+		t.Errorf("unexpected removed symbols: %+v", diff.Removed)
+	}
+	if len(diff.Added) != 1 || diff.Added[0].Identifier != "github.com/sourcegraph/sourcegraph/lib/codeintel/semantic/diff/testdata/project1:Struct1.Field1" {
+		t.Errorf("unexpected added symbols: %+v", diff.Added)
+	}
+	if len(diff.Added) == 1 && diff.Added[0].Hover != "```go\nstruct field Field1 int\n```" {
+		t.Errorf("unexpected hover text for added symbol: %q", diff.Added[0].Hover)
+	}
+}
+
+func TestDiffSymbolsChangedHover(t *testing.T) {
+	makeBundle := func(hover string, line int) *semantic.GroupedBundleDataMaps {
+		return &semantic.GroupedBundleDataMaps{
+			Documents: map[string]semantic.DocumentData{
+				"main.go": {
+					Ranges: map[semantic.ID]semantic.RangeData{
+						"r1": {StartLine: line, StartCharacter: 5, EndLine: line, EndCharacter: 8, HoverResultID: "h1"},
+					},
+					HoverResults: map[semantic.ID]string{"h1": hover},
+				},
+			},
+			Definitions: map[string]map[string][]semantic.LocationData{
+				"gomod": {
+					"pkg:Foo": {{URI: "main.go", StartLine: line, StartCharacter: 5, EndLine: line, EndCharacter: 8}},
+				},
+			},
+		}
+	}
+
+	if diff := DiffSymbols(makeBundle("func Foo()", 3), makeBundle("func Foo()", 10)); len(diff.Changed) != 0 {
+		t.Errorf("expected moved definition not to be a change, got %+v", diff.Changed)
+	}
+
+	diff := DiffSymbols(makeBundle("func Foo()", 3), makeBundle("func Foo(x int)", 3))
+	if len(diff.Changed) != 1 {
+		t.Fatalf("expected one changed symbol, got %+v", diff)
+	}
+	if diff.Changed[0].Old.Hover != "func Foo()" || diff.Changed[0].New.Hover != "func Foo(x int)" {
+		t.Errorf("unexpected changed symbol: %+v", diff.Changed[0])
+	}
+}
+
+func readBundle(t *testing.T, path string) *semantic.GroupedBundleDataMaps {
+	bundle, err := conversion.CorrelateLocalGit(context.Background(), path, filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Unexpected error reading dump: %v", err)
+	}
+
+	return semantic.GroupedBundleDataChansToMaps(bundle)
+}
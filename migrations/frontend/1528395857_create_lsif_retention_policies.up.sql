@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS lsif_retention_policies (
+    id serial PRIMARY KEY,
+    name text NOT NULL,
+    repository_pattern text NOT NULL DEFAULT '*',
+    ref_pattern text NOT NULL,
+    max_age_seconds integer CHECK (max_age_seconds > 0),
+    keep_most_recent integer CHECK (keep_most_recent > 0),
+    indexing_enabled boolean NOT NULL DEFAULT false,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+COMMENT ON TABLE lsif_retention_policies IS 'Stores retention policies for code intelligence data scoped to repositories and branches or tags.';
+COMMENT ON COLUMN lsif_retention_policies.name IS 'A human-readable name of the policy.';
+COMMENT ON COLUMN lsif_retention_policies.repository_pattern IS 'A glob pattern matched against the names of repositories to which the policy applies.';
+COMMENT ON COLUMN lsif_retention_policies.ref_pattern IS 'A glob pattern matched against the names of branches and tags to which the policy applies.';
+COMMENT ON COLUMN lsif_retention_policies.max_age_seconds IS 'The number of seconds an upload visible from a matching branch or tag is retained. A null value retains uploads regardless of age.';
+COMMENT ON COLUMN lsif_retention_policies.keep_most_recent IS 'The number of most recent uploads per root and indexer visible from matching branches or tags which are retained. A null value retains all matching uploads.';
+COMMENT ON COLUMN lsif_retention_policies.indexing_enabled IS 'Whether or not the tips of matching branches and tags are automatically indexed.';
+
+COMMIT;
@@ -0,0 +1,43 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS search_jobs (
+    id serial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    query text NOT NULL,
+    state text NOT NULL DEFAULT 'queued',
+    failure_message text,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    process_after timestamp with time zone,
+    num_resets integer NOT NULL DEFAULT 0,
+    num_failures integer NOT NULL DEFAULT 0,
+    last_heartbeat_at timestamp with time zone,
+    execution_logs json[],
+    worker_hostname text NOT NULL DEFAULT '',
+    cancel boolean NOT NULL DEFAULT false,
+    repos_total integer NOT NULL DEFAULT 0,
+    repos_searched integer NOT NULL DEFAULT 0,
+    repos_incomplete integer NOT NULL DEFAULT 0,
+    last_repo text NOT NULL DEFAULT '',
+    match_count bigint NOT NULL DEFAULT 0,
+    result_parts integer NOT NULL DEFAULT 0,
+    result_size bigint NOT NULL DEFAULT 0,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS search_jobs_state ON search_jobs(state);
+CREATE INDEX IF NOT EXISTS search_jobs_user_id ON search_jobs(user_id);
+
+COMMENT ON TABLE search_jobs IS 'Stores exhaustive searches run in the background. The results are stored in blob storage.';
+COMMENT ON COLUMN search_jobs.user_id IS 'The user who submitted the search. The search runs with the permissions of this user.';
+COMMENT ON COLUMN search_jobs.cancel IS 'Whether the user requested to cancel the search.';
+COMMENT ON COLUMN search_jobs.repos_total IS 'The number of repositories matched by the repository filters of the query.';
+COMMENT ON COLUMN search_jobs.repos_searched IS 'The number of repositories searched so far.';
+COMMENT ON COLUMN search_jobs.repos_incomplete IS 'The number of repositories which could not be searched completely, because they timed out or were still being cloned.';
+COMMENT ON COLUMN search_jobs.last_repo IS 'The name of the last repository whose results are stored. Repositories are searched in order of their names, so a retried search resumes after this repository.';
+COMMENT ON COLUMN search_jobs.match_count IS 'The number of results stored.';
+COMMENT ON COLUMN search_jobs.result_parts IS 'The number of objects in blob storage holding the results, which are concatenated on download.';
+COMMENT ON COLUMN search_jobs.result_size IS 'The total size of the results in bytes.';
+
+COMMIT;
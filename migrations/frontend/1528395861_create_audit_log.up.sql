@@ -0,0 +1,33 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id bigserial PRIMARY KEY,
+    "timestamp" timestamp with time zone NOT NULL DEFAULT now(),
+    actor_user_id integer,
+    actor_username text,
+    access_token_id bigint,
+    ip text,
+    user_agent text,
+    action text NOT NULL,
+    target text,
+    arguments jsonb,
+    before text,
+    after text,
+    outcome text NOT NULL,
+    error text,
+    CONSTRAINT audit_log_action_not_empty CHECK (action <> ''::text)
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_timestamp ON audit_log USING btree ("timestamp");
+CREATE INDEX IF NOT EXISTS audit_log_actor_user_id ON audit_log USING btree (actor_user_id);
+
+COMMENT ON TABLE audit_log IS 'Records every mutating GraphQL operation and admin API call.';
+COMMENT ON COLUMN audit_log.actor_user_id IS 'The user who performed the action. It is not a foreign key so that entries outlive deleted users.';
+COMMENT ON COLUMN audit_log.actor_username IS 'The username of the actor at the time of the action.';
+COMMENT ON COLUMN audit_log.access_token_id IS 'The access token used to authenticate the actor, if any.';
+COMMENT ON COLUMN audit_log.action IS 'The action, such as graphql.updateSiteConfiguration or "http.POST /.api/scim/v2/Users".';
+COMMENT ON COLUMN audit_log.target IS 'What the action was performed on, such as an external service or a user.';
+COMMENT ON COLUMN audit_log.arguments IS 'The arguments of the action, with sensitive values redacted.';
+COMMENT ON COLUMN audit_log.outcome IS 'Either success or failure.';
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id serial PRIMARY KEY,
+    state text NOT NULL DEFAULT 'queued',
+    failure_message text,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    process_after timestamp with time zone,
+    num_resets integer NOT NULL DEFAULT 0,
+    num_failures integer NOT NULL DEFAULT 0,
+    last_heartbeat_at timestamp with time zone,
+    execution_logs json[],
+    worker_hostname text NOT NULL DEFAULT '',
+    recipients text[] NOT NULL,
+    reply_to text,
+    subject text NOT NULL,
+    text_body text NOT NULL,
+    html_body text NOT NULL,
+    headers jsonb NOT NULL DEFAULT '{}'::jsonb,
+    delivered_at timestamp with time zone,
+    provider_message_id text,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS email_outbox_state ON email_outbox(state);
+CREATE INDEX IF NOT EXISTS email_outbox_finished_at ON email_outbox(finished_at);
+
+COMMENT ON TABLE email_outbox IS 'Stores transactional emails until they are delivered. The "From" address is set from the site configuration on delivery.';
+COMMENT ON COLUMN email_outbox.recipients IS 'The "To" addresses of the message.';
+COMMENT ON COLUMN email_outbox.text_body IS 'The plain text body of the message. It is cleared once the message is delivered, since it may contain secrets such as password reset links.';
+COMMENT ON COLUMN email_outbox.html_body IS 'The HTML body of the message. It is cleared once the message is delivered.';
+COMMENT ON COLUMN email_outbox.headers IS 'Additional headers of the message, such as Message-ID and References.';
+COMMENT ON COLUMN email_outbox.delivered_at IS 'When the transport accepted the message. A message that was delivered is never sent again, even if its worker died before marking it completed.';
+COMMENT ON COLUMN email_outbox.provider_message_id IS 'The ID of the message returned by the email HTTP API, if any.';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at timestamp with time zone;
+
+COMMENT ON COLUMN users.deactivated_at IS 'When the user was deactivated, for example by the identity provider through the SCIM API. Deactivated users keep their data but can''t sign in or use access tokens until they are reactivated.';
+
+COMMIT;
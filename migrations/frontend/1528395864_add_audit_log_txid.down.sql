@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS audit_log_txid_id;
+ALTER TABLE audit_log DROP COLUMN IF EXISTS txid;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS txid bigint NOT NULL DEFAULT txid_current();
+
+CREATE INDEX IF NOT EXISTS audit_log_txid_id ON audit_log USING btree (txid, id);
+
+COMMENT ON COLUMN audit_log.txid IS 'The transaction that inserted the entry. Entries are exported in (txid, id) order, and only once no older transaction is running, so that entries that commit late are not skipped.';
+
+COMMIT;
@@ -60,16 +60,53 @@ type AdditionalProperties struct {
 	Value string `json:"value"`
 }
 
-// ApiRatelimit description: Configuration for API rate limiting
+// ApiRateLimitPolicy description: Rate limits and daily quotas of an API endpoint. Limits that are not set are not enforced.
+type ApiRateLimitPolicy struct {
+	DailyQuota *ApiRateLimitQuota `json:"dailyQuota,omitempty"`
+	// PerIP description: Limit granted per IP per hour, only applied to anonymous users
+	PerIP int `json:"perIP,omitempty"`
+	// PerToken description: Limit granted per access token per hour. Defaults to perUser.
+	PerToken int `json:"perToken,omitempty"`
+	// PerUser description: Limit granted per user per hour
+	PerUser int `json:"perUser,omitempty"`
+}
+
+// ApiRateLimitQuota description: Daily quotas of an API endpoint. A quota is replenished continuously over 24 hours, so a consumer that used up its quota can make a new request after 24h / quota. Quotas that are not set are not enforced.
+type ApiRateLimitQuota struct {
+	// PerIP description: Quota granted per IP per day, only applied to anonymous users
+	PerIP int `json:"perIP,omitempty"`
+	// PerToken description: Quota granted per access token per day. Defaults to perUser.
+	PerToken int `json:"perToken,omitempty"`
+	// PerUser description: Quota granted per user per day
+	PerUser int `json:"perUser,omitempty"`
+}
+
+// ApiRatelimit description: Configuration for API rate limiting. The top-level limits apply to GraphQL requests and are counted in estimated query cost. Limits are stored in Redis and shared by all frontend instances.
 type ApiRatelimit struct {
+	// CodeIntelUploads description: Limits of code intelligence (LSIF) upload requests, counted in requests. Each part of a multipart upload counts as a request.
+	CodeIntelUploads *ApiRateLimitPolicy `json:"codeIntelUploads,omitempty"`
+	// DailyQuota description: Daily quotas of GraphQL requests, counted in estimated query cost.
+	DailyQuota *ApiRateLimitQuota `json:"dailyQuota,omitempty"`
 	// Enabled description: Whether API rate limiting is enabled
 	Enabled bool `json:"enabled"`
 	// Overrides description: An array of rate limit overrides
 	Overrides []*Overrides `json:"overrides,omitempty"`
 	// PerIP description: Limit granted per IP per hour, only applied to anonymous users
 	PerIP int `json:"perIP"`
+	// PerToken description: Limit granted per access token per hour. Requests authenticated with an access token are limited by this instead of perUser. Defaults to perUser.
+	PerToken int `json:"perToken,omitempty"`
 	// PerUser description: Limit granted per user per hour
 	PerUser int `json:"perUser"`
+	// Search description: Limits of streaming search requests, counted in requests.
+	Search *ApiRateLimitPolicy `json:"search,omitempty"`
+}
+
+// AuditLog description: Configuration for the audit log, which records every mutating GraphQL operation and admin API call. See https://docs.sourcegraph.com/admin/audit_log.
+type AuditLog struct {
+	// RetentionDays description: The number of days to keep audit log entries in the database. Older entries are deleted.
+	RetentionDays int `json:"retentionDays,omitempty"`
+	// Syslog description: Forward audit log entries as JSON messages to a syslog server, such as a SIEM.
+	Syslog *Syslog `json:"syslog,omitempty"`
 }
 
 // AuthAccessTokens description: Settings for access tokens, which enable external tools to access the Sourcegraph API with the privileges of the user.
@@ -78,18 +115,37 @@ type AuthAccessTokens struct {
 	Allow string `json:"allow,omitempty"`
 }
 
+// AuthGroupMappings description: Maps the groups of users in the identity provider to Sourcegraph organizations and site admin status. The mappings are applied every time a user signs in: they are added to the mapped organizations of their groups and removed from the mapped organizations of groups they are no longer in. Organizations that are not mapped are never changed.
+type AuthGroupMappings struct {
+	// Orgs description: The organizations whose members are the members of groups.
+	Orgs []*AuthGroupOrgMapping `json:"orgs,omitempty"`
+	// SiteAdmin description: The groups whose members are site admins. If set, users who are in none of these groups stop being site admins when they sign in.
+	SiteAdmin []string `json:"siteAdmin,omitempty"`
+}
+
+// AuthGroupOrgMapping description: Maps a group of the identity provider to a Sourcegraph organization.
+type AuthGroupOrgMapping struct {
+	// Group description: The name of the group, as in the groups of the user that the identity provider sends.
+	Group string `json:"group"`
+	// Org description: The name of the organization, which must already exist.
+	Org string `json:"org"`
+}
+
 // AuthProviderCommon description: Common properties for authentication providers.
 type AuthProviderCommon struct {
 	// DisplayName description: The name to use when displaying this authentication provider in the UI. Defaults to an auto-generated name with the type of authentication provider and other relevant identifiers (such as a hostname).
 	DisplayName string `json:"displayName,omitempty"`
 }
 type AuthProviders struct {
-	Builtin       *BuiltinAuthProvider
-	Saml          *SAMLAuthProvider
-	Openidconnect *OpenIDConnectAuthProvider
-	HttpHeader    *HTTPHeaderAuthProvider
-	Github        *GitHubAuthProvider
-	Gitlab        *GitLabAuthProvider
+	Builtin        *BuiltinAuthProvider
+	Saml           *SAMLAuthProvider
+	Openidconnect  *OpenIDConnectAuthProvider
+	HttpHeader     *HTTPHeaderAuthProvider
+	Github         *GitHubAuthProvider
+	Gitlab         *GitLabAuthProvider
+	BitbucketCloud *BitbucketCloudAuthProvider
+	Gitea          *GiteaAuthProvider
+	Ldap           *LDAPAuthProvider
 }
 
 func (v AuthProviders) MarshalJSON() ([]byte, error) {
@@ -111,6 +167,15 @@ func (v AuthProviders) MarshalJSON() ([]byte, error) {
 	if v.Gitlab != nil {
 		return json.Marshal(v.Gitlab)
 	}
+	if v.BitbucketCloud != nil {
+		return json.Marshal(v.BitbucketCloud)
+	}
+	if v.Gitea != nil {
+		return json.Marshal(v.Gitea)
+	}
+	if v.Ldap != nil {
+		return json.Marshal(v.Ldap)
+	}
 	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
 }
 func (v *AuthProviders) UnmarshalJSON(data []byte) error {
@@ -121,22 +186,77 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return err
 	}
 	switch d.DiscriminantProperty {
+	case "bitbucketCloud":
+		return json.Unmarshal(data, &v.BitbucketCloud)
 	case "builtin":
 		return json.Unmarshal(data, &v.Builtin)
+	case "gitea":
+		return json.Unmarshal(data, &v.Gitea)
 	case "github":
 		return json.Unmarshal(data, &v.Github)
 	case "gitlab":
 		return json.Unmarshal(data, &v.Gitlab)
 	case "http-header":
 		return json.Unmarshal(data, &v.HttpHeader)
+	case "ldap":
+		return json.Unmarshal(data, &v.Ldap)
 	case "openidconnect":
 		return json.Unmarshal(data, &v.Openidconnect)
 	case "saml":
 		return json.Unmarshal(data, &v.Saml)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "bitbucketCloud", "gitea", "ldap"})
 }
 
+// AuthScim description: Configures the SCIM 2.0 API at /.api/scim/v2, which identity providers use to provision and deprovision users (as SCIM Users) and organizations (as SCIM Groups).
+type AuthScim struct {
+	// Token description: The bearer token that the identity provider sends to authenticate its SCIM requests. It should be long and random, for example the output of `openssl rand -hex 32`.
+	Token string `json:"token"`
+}
+
+// AzureDevOpsConnection description: Configuration for a connection to Azure DevOps Services or Azure DevOps Server.
+type AzureDevOpsConnection struct {
+	// Exclude description: A list of repositories to never mirror from Azure DevOps. Takes precedence over "orgs", "projects" and "repos" configuration.
+	//
+	// Supports excluding by name ({"name": "myorg/myproject/myrepo"}), by ID ({"id": "278d5cd2-584d-4b63-824a-2ba458937249"}) or by regular expression ({"pattern": "^myorg/sandbox/.*"}).
+	Exclude []*ExcludedAzureDevOpsRepo `json:"exclude,omitempty"`
+	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on Azure DevOps.
+	//
+	// If "http", Sourcegraph will access Azure DevOps repositories using Git URLs of the form https://dev.azure.com/myorg/myproject/_git/myrepo, including the configured token.
+	//
+	// If "ssh", Sourcegraph will access Azure DevOps repositories using Git URLs of the form git@ssh.dev.azure.com:v3/myorg/myproject/myrepo. See the documentation for how to provide SSH private keys and known_hosts: https://docs.sourcegraph.com/admin/repo/auth#repositories-that-need-http-s-or-ssh-authentication.
+	GitURLType string `json:"gitURLType,omitempty"`
+	// Orgs description: An array of organization (or, on Azure DevOps Server, collection) names whose repositories in all projects should be mirrored on Sourcegraph.
+	Orgs []string `json:"orgs,omitempty"`
+	// Projects description: An array of projects, in the form "org/project", whose repositories should be mirrored on Sourcegraph.
+	Projects []string `json:"projects,omitempty"`
+	// RateLimit description: Rate limit applied when making background API requests to Azure DevOps.
+	RateLimit *AzureDevOpsRateLimit `json:"rateLimit,omitempty"`
+	// Repos description: An array of repositories, in the form "org/project/repo", to mirror on Sourcegraph.
+	Repos []string `json:"repos,omitempty"`
+	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for an Azure DevOps repository.
+	//
+	//  - "{host}" is replaced with the Azure DevOps URL's host (such as dev.azure.com), "{org}" with the organization (or collection) name, "{project}" with the project name and "{repo}" with the repository name. Spaces in names are replaced with "-".
+	//
+	// For example, if your Azure DevOps is https://dev.azure.com and your Sourcegraph is https://src.example.com, then a repositoryPathPattern of "{host}/{org}/{project}/{repo}" would mean that the repository "myrepo" in the project "myproject" of the organization "myorg" is available on Sourcegraph at https://src.example.com/dev.azure.com/myorg/myproject/myrepo.
+	//
+	// It is important that the Sourcegraph repository name generated with this pattern be unique to this code host. If different code hosts generate repository names that collide, Sourcegraph's behavior is undefined.
+	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// Token description: A personal access token with the "Code (Read)" and "Project and Team (Read)" scopes, used to list and clone repositories.
+	Token string `json:"token"`
+	// Url description: URL of Azure DevOps Services (https://dev.azure.com) or of an Azure DevOps Server instance, without the organization (collection) name.
+	Url string `json:"url"`
+	// Username description: The username to send along with the personal access token when cloning repositories over HTTP(S). Azure DevOps accepts any non-empty username, so this only needs to be set if a proxy in front of the instance requires a specific one.
+	Username string `json:"username,omitempty"`
+}
+
+// AzureDevOpsRateLimit description: Rate limit applied when making background API requests to Azure DevOps.
+type AzureDevOpsRateLimit struct {
+	// Enabled description: true if rate limiting is enabled.
+	Enabled bool `json:"enabled"`
+	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second. Internally, the burst limit is set to 500, which implies that for a requests per hour limit as low as 1, users will continue to be able to send a maximum of 500 requests immediately, provided that the complexity cost of each request is 1.
+	RequestsPerHour float64 `json:"requestsPerHour"`
+}
 type BatchChangeRolloutWindow struct {
 	// Days description: Day(s) the window applies to. If omitted, this rule applies to all days of the week.
 	Days []string `json:"days,omitempty"`
@@ -168,12 +288,36 @@ type BatchSpec struct {
 	Workspaces []*WorkspaceConfiguration `json:"workspaces,omitempty"`
 }
 
+// BitbucketCloudAuthProvider description: Configures the Bitbucket Cloud OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create an OAuth consumer in your Bitbucket Cloud workspace settings: https://support.atlassian.com/bitbucket-cloud/docs/use-oauth-on-bitbucket-cloud/. The consumer should have the `Account: Email`, `Account: Read` and `Repositories: Read` permissions and the callback URL set to the concatenation of your Sourcegraph instance URL and "/.auth/bitbucketcloud/callback".
+type BitbucketCloudAuthProvider struct {
+	// AllowSignup description: Allows new visitors to sign up for accounts via Bitbucket Cloud authentication. If false, users signing in via Bitbucket Cloud must have an existing Sourcegraph account, which will be linked to their Bitbucket Cloud identity after sign-in.
+	AllowSignup bool `json:"allowSignup,omitempty"`
+	// ApiURL description: The API URL of Bitbucket Cloud. Generally, admins should not modify the value of this option because Bitbucket Cloud is a public hosting platform.
+	ApiURL string `json:"apiURL,omitempty"`
+	// ClientKey description: The Key of the Bitbucket Cloud OAuth consumer.
+	ClientKey string `json:"clientKey"`
+	// ClientSecret description: The Secret of the Bitbucket Cloud OAuth consumer.
+	ClientSecret string `json:"clientSecret"`
+	DisplayName  string `json:"displayName,omitempty"`
+	Type         string `json:"type"`
+	// Url description: URL of Bitbucket Cloud. It must match the URL of the Bitbucket Cloud code host connection for permissions to be enforced.
+	Url string `json:"url,omitempty"`
+}
+
+// BitbucketCloudAuthorization description: If non-null, enforces Bitbucket Cloud repository permissions. Permissions of a user are the private repositories they are a member of, listed with the OAuth token of the account they signed in with through a Bitbucket Cloud authentication provider (in the `auth.providers` site configuration).
+type BitbucketCloudAuthorization struct {
+	// IdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. Only 'oauth' is supported, which requires a Bitbucket Cloud authentication provider.
+	IdentityProvider BitbucketCloudIdentityProvider `json:"identityProvider"`
+}
+
 // BitbucketCloudConnection description: Configuration for a connection to Bitbucket Cloud.
 type BitbucketCloudConnection struct {
 	// ApiURL description: The API URL of Bitbucket Cloud, such as https://api.bitbucket.org. Generally, admin should not modify the value of this option because Bitbucket Cloud is a public hosting platform.
 	ApiURL string `json:"apiURL,omitempty"`
 	// AppPassword description: The app password to use when authenticating to the Bitbucket Cloud. Also set the corresponding "username" field.
 	AppPassword string `json:"appPassword"`
+	// Authorization description: If non-null, enforces Bitbucket Cloud repository permissions. Permissions of a user are the private repositories they are a member of, listed with the OAuth token of the account they signed in with through a Bitbucket Cloud authentication provider (in the `auth.providers` site configuration).
+	Authorization *BitbucketCloudAuthorization `json:"authorization,omitempty"`
 	// Exclude description: A list of repositories to never mirror from Bitbucket Cloud. Takes precedence over "teams" configuration.
 	//
 	// Supports excluding by name ({"name": "myorg/myrepo"}) or by UUID ({"uuid": "{fceb73c7-cef6-4abe-956d-e471281126bd}"}).
@@ -202,6 +346,11 @@ type BitbucketCloudConnection struct {
 	Username string `json:"username"`
 }
 
+// BitbucketCloudIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. Only 'oauth' is supported, which requires a Bitbucket Cloud authentication provider.
+type BitbucketCloudIdentityProvider struct {
+	Type string `json:"type"`
+}
+
 // BitbucketCloudRateLimit description: Rate limit applied when making background API requests to Bitbucket Cloud.
 type BitbucketCloudRateLimit struct {
 	// Enabled description: true if rate limiting is enabled.
@@ -409,6 +558,14 @@ type Dotcom struct {
 	SlackLicenseExpirationWebhook string `json:"slackLicenseExpirationWebhook,omitempty"`
 }
 
+// EmailHTTPConfig description: An HTTP API used to send transactional emails instead of an SMTP server, such as the API of an email delivery provider or a relay in front of one. Sourcegraph POSTs each message as JSON to the URL. If both `email.http` and `email.smtp` are set, `email.http` is used.
+type EmailHTTPConfig struct {
+	// Token description: The token sent in the `Authorization: Bearer` header of the requests.
+	Token string `json:"token,omitempty"`
+	// Url description: The URL messages are POSTed to. The JSON body has the fields `from`, `to`, `replyTo`, `subject`, `text`, `html` and `headers`. A successful response may be a JSON object with an `id` field, which is recorded as the provider's message ID.
+	Url string `json:"url"`
+}
+
 // EncryptionKey description: Config for a key
 type EncryptionKey struct {
 	Cloudkms *CloudKMSEncryptionKey
@@ -468,6 +625,14 @@ type ExcludedAWSCodeCommitRepo struct {
 	// Name description: The name of an AWS CodeCommit repository ("repo-name") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
 }
+type ExcludedAzureDevOpsRepo struct {
+	// Id description: The ID of an Azure DevOps repository (as returned by the Azure DevOps API) to exclude from mirroring.
+	Id string `json:"id,omitempty"`
+	// Name description: The name of an Azure DevOps repository ("org/project/repo") to exclude from mirroring.
+	Name string `json:"name,omitempty"`
+	// Pattern description: Regular expression which matches against the name ("org/project/repo") of an Azure DevOps repository.
+	Pattern string `json:"pattern,omitempty"`
+}
 type ExcludedBitbucketCloudRepo struct {
 	// Name description: The name of a Bitbucket Cloud repo ("myorg/myrepo") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
@@ -484,6 +649,12 @@ type ExcludedBitbucketServerRepo struct {
 	// Pattern description: Regular expression which matches against the name of a Bitbucket Server repo.
 	Pattern string `json:"pattern,omitempty"`
 }
+type ExcludedGerritProject struct {
+	// Name description: The name of a Gerrit project ("platform/build") to exclude from mirroring.
+	Name string `json:"name,omitempty"`
+	// Pattern description: Regular expression which matches against the name of a Gerrit project.
+	Pattern string `json:"pattern,omitempty"`
+}
 type ExcludedGitHubRepo struct {
 	// Archived description: If set to true, archived repositories will be excluded.
 	Archived bool `json:"archived,omitempty"`
@@ -502,6 +673,14 @@ type ExcludedGitLabProject struct {
 	// Name description: The name of a GitLab project ("group/name") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
 }
+type ExcludedGiteaRepo struct {
+	// Id description: The ID of a Gitea repository (as returned by the Gitea instance's API) to exclude from mirroring.
+	Id int `json:"id,omitempty"`
+	// Name description: The name of a Gitea repository ("owner/name") to exclude from mirroring.
+	Name string `json:"name,omitempty"`
+	// Pattern description: Regular expression which matches against the name ("owner/name") of a Gitea repository.
+	Pattern string `json:"pattern,omitempty"`
+}
 type ExcludedGitoliteRepo struct {
 	// Name description: The name of a Gitolite repo ("my-repo") to exclude from mirroring.
 	Name string `json:"name,omitempty"`
@@ -541,8 +720,12 @@ type ExperimentalFeatures struct {
 	Ranking *Ranking `json:"ranking,omitempty"`
 	// RateLimitAnonymous description: Configures the hourly rate limits for anonymous calls to the GraphQL API. Setting limit to 0 disables the limiter. This is only relevant if unauthenticated calls to the API are permitted.
 	RateLimitAnonymous int `json:"rateLimitAnonymous,omitempty"`
+	// SearchCommitIndex description: Enables the commit index. Gitserver maintains an index of the commit messages, authors and diffs of the default branch of each repository, which diff and commit searches use to skip commits that can't match. Building the index for the first time requires reading the full history of every repository.
+	SearchCommitIndex string `json:"search.commitIndex,omitempty"`
 	// SearchIndexBranches description: A map from repository name to a list of extra revs (branch, ref, tag, commit sha, etc) to index for a repository. We always index the default branch ("HEAD") and revisions in version contexts. This allows specifying additional revisions. Sourcegraph can index up to 64 branches per repository.
 	SearchIndexBranches map[string][]string `json:"search.index.branches,omitempty"`
+	// SearchJobs description: Configures search jobs, which run exhaustive searches in the background and store their results for download.
+	SearchJobs *SearchJobs `json:"search.jobs,omitempty"`
 	// SearchMultipleRevisionsPerRepository description: DEPRECATED. Always on. Will be removed in 3.19.
 	SearchMultipleRevisionsPerRepository *bool `json:"searchMultipleRevisionsPerRepository,omitempty"`
 	// StructuralSearch description: Enables structural search.
@@ -574,6 +757,50 @@ type ExternalIdentity struct {
 	Type           string `json:"type"`
 }
 
+// GerritConnection description: Configuration for a connection to Gerrit.
+type GerritConnection struct {
+	// Exclude description: A list of Gerrit projects to never mirror. Takes precedence over "projects" configuration.
+	//
+	// Supports excluding by name ({"name": "platform/build"}) or by regular expression ({"pattern": "^sandbox/.*"}).
+	Exclude []*ExcludedGerritProject `json:"exclude,omitempty"`
+	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this Gerrit instance.
+	//
+	// If "http", Sourcegraph will access Gerrit projects using Git URLs of the form https://gerrit.example.com/a/myproject, including the configured credentials.
+	//
+	// If "ssh", Sourcegraph will access Gerrit projects using Git URLs of the form ssh://username@gerrit.example.com:29418/myproject. See the documentation for how to provide SSH private keys and known_hosts: https://docs.sourcegraph.com/admin/repo/auth#repositories-that-need-http-s-or-ssh-authentication.
+	GitURLType string `json:"gitURLType,omitempty"`
+	// Password description: The HTTP password to use when authenticating to the Gerrit instance, as generated in the user's "HTTP Credentials" settings. Also set the corresponding "username" field.
+	Password string `json:"password,omitempty"`
+	// Projects description: An array of Gerrit project names that should be mirrored on Sourcegraph. If not set, all projects visible to the configured user are mirrored.
+	Projects []string `json:"projects,omitempty"`
+	// RateLimit description: Rate limit applied when making background API requests to Gerrit.
+	RateLimit *GerritRateLimit `json:"rateLimit,omitempty"`
+	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for a Gerrit project.
+	//
+	//  - "{host}" is replaced with the Gerrit URL's host (such as gerrit.example.com), and "{name}" is replaced with the Gerrit project's name (such as "platform/build").
+	//
+	// For example, if your Gerrit is https://gerrit.example.com and your Sourcegraph is https://src.example.com, then a repositoryPathPattern of "{host}/{name}" would mean that a Gerrit project named "platform/build" is available on Sourcegraph at https://src.example.com/gerrit.example.com/platform/build.
+	//
+	// It is important that the Sourcegraph repository name generated with this pattern be unique to this code host. If different code hosts generate repository names that collide, Sourcegraph's behavior is undefined.
+	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// SshPort description: The port of the Gerrit instance's SSH daemon. Only used if "gitURLType" is "ssh".
+	SshPort int `json:"sshPort,omitempty"`
+	// Url description: URL of a Gerrit instance, such as https://gerrit.example.com.
+	Url string `json:"url"`
+	// Username description: The username to use when authenticating to the Gerrit instance. Also set the corresponding "password" field.
+	//
+	// If neither "username" nor "password" are set, Sourcegraph lists and clones projects anonymously.
+	Username string `json:"username,omitempty"`
+}
+
+// GerritRateLimit description: Rate limit applied when making background API requests to Gerrit.
+type GerritRateLimit struct {
+	// Enabled description: true if rate limiting is enabled.
+	Enabled bool `json:"enabled"`
+	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second. Internally, the burst limit is set to 500, which implies that for a requests per hour limit as low as 1, users will continue to be able to send a maximum of 500 requests immediately, provided that the complexity cost of each request is 1.
+	RequestsPerHour float64 `json:"requestsPerHour"`
+}
+
 // GitCommitAuthor description: The author of the Git commit.
 type GitCommitAuthor struct {
 	// Email description: The Git commit author email.
@@ -777,6 +1004,83 @@ type GitLabWebhook struct {
 	Secret string `json:"secret"`
 }
 
+// GiteaAuthProvider description: Configures the Gitea (or Forgejo) OAuth authentication provider for SSO. In addition to specifying this configuration object, you must also create an OAuth2 application on your Gitea instance: https://docs.gitea.io/en-us/oauth2-provider/. The application's redirect URI should be set to the concatenation of your Sourcegraph instance URL and "/.auth/gitea/callback".
+type GiteaAuthProvider struct {
+	// AllowSignup description: Allows new visitors to sign up for accounts via Gitea authentication. If false, users signing in via Gitea must have an existing Sourcegraph account, which will be linked to their Gitea identity after sign-in.
+	AllowSignup bool `json:"allowSignup,omitempty"`
+	// ClientID description: The Client ID of the Gitea OAuth2 application, accessible from the "Settings > Applications" page of the user or organization that owns it.
+	ClientID string `json:"clientID"`
+	// ClientSecret description: The Client Secret of the Gitea OAuth2 application, accessible from the "Settings > Applications" page of the user or organization that owns it.
+	ClientSecret string `json:"clientSecret"`
+	DisplayName  string `json:"displayName,omitempty"`
+	Type         string `json:"type"`
+	// Url description: URL of the Gitea instance, such as https://gitea.example.com. It must match the URL of the Gitea code host connection for permissions to be enforced.
+	Url string `json:"url"`
+}
+
+// GiteaAuthorization description: If non-null, enforces Gitea repository permissions. Permissions are computed from the collaborators of each repository and the members of the organization teams with access to it. This requires the configured token to belong to a site admin.
+type GiteaAuthorization struct {
+	// IdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Gitea identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Gitea accounts and `auth.enableUsernameChanges` must be set to false for security reasons. When 'oauth' is used, the Gitea identity of a user is the account they signed in with through a Gitea authentication provider (in the `auth.providers` site configuration) for the same Gitea instance, and the repositories they can access are listed with their own OAuth token.
+	IdentityProvider GiteaIdentityProvider `json:"identityProvider"`
+}
+
+// GiteaConnection description: Configuration for a connection to Gitea or Forgejo.
+type GiteaConnection struct {
+	// Authorization description: If non-null, enforces Gitea repository permissions. Permissions are computed from the collaborators of each repository and the members of the organization teams with access to it. This requires the configured token to belong to a site admin.
+	Authorization *GiteaAuthorization `json:"authorization,omitempty"`
+	// Exclude description: A list of repositories to never mirror from this Gitea instance. Takes precedence over "orgs", "users", "repos" and "repositoryQuery" configuration.
+	//
+	// Supports excluding by name ({"name": "owner/name"}), by ID ({"id": 42}) or by regular expression ({"pattern": "^sandbox/.*"}).
+	Exclude []*ExcludedGiteaRepo `json:"exclude,omitempty"`
+	// GitURLType description: The type of Git URLs to use for cloning and fetching Git repositories on this Gitea instance.
+	//
+	// If "http", Sourcegraph will access Gitea repositories using Git URLs of the form https://gitea.example.com/myorg/myrepo.git, including the configured token.
+	//
+	// If "ssh", Sourcegraph will access Gitea repositories using Git URLs of the form git@gitea.example.com:myorg/myrepo.git. See the documentation for how to provide SSH private keys and known_hosts: https://docs.sourcegraph.com/admin/repo/auth#repositories-that-need-http-s-or-ssh-authentication.
+	GitURLType string `json:"gitURLType,omitempty"`
+	// Orgs description: An array of organization names identifying Gitea organizations whose repositories should be mirrored on Sourcegraph.
+	Orgs []string `json:"orgs,omitempty"`
+	// RateLimit description: Rate limit applied when making background API requests to Gitea.
+	RateLimit *GiteaRateLimit `json:"rateLimit,omitempty"`
+	// Repos description: An array of repository "owner/name" strings specifying which Gitea repositories to mirror on Sourcegraph.
+	Repos []string `json:"repos,omitempty"`
+	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for a Gitea repository. In the pattern, the variable "{host}" is replaced with the Gitea URL's host (such as gitea.example.com), and "{nameWithOwner}" is replaced with the Gitea repository's "owner/name" (such as "myorg/myrepo").
+	//
+	// For example, if your Gitea is https://gitea.example.com and your Sourcegraph is https://src.example.com, then a repositoryPathPattern of "{host}/{nameWithOwner}" would mean that a Gitea repository at https://gitea.example.com/myorg/myrepo is available on Sourcegraph at https://src.example.com/gitea.example.com/myorg/myrepo.
+	//
+	// It is important that the Sourcegraph repository name generated with this pattern be unique to this code host. If different code hosts generate repository names that collide, Sourcegraph's behavior is undefined.
+	RepositoryPathPattern string `json:"repositoryPathPattern,omitempty"`
+	// RepositoryQuery description: An array of strings specifying which Gitea repositories to mirror on Sourcegraph. The valid values are:
+	//
+	// - `all` mirrors all repositories visible to the configured token's user
+	//
+	// - `none` mirrors no repositories (except those specified in the `repos`, `orgs` and `users` configuration properties)
+	//
+	// - All other values are executed as a Gitea repository search, matching repositories whose name contains the value.
+	//
+	// If multiple values are provided, their results are unioned.
+	RepositoryQuery []string `json:"repositoryQuery,omitempty"`
+	// Token description: An access token for the Gitea instance, generated in the user's "Settings > Applications" page. The token must belong to a site admin if "authorization" is set.
+	Token string `json:"token"`
+	// Url description: URL of a Gitea or Forgejo instance, such as https://gitea.example.com.
+	Url string `json:"url"`
+	// Users description: An array of usernames identifying Gitea users whose repositories should be mirrored on Sourcegraph.
+	Users []string `json:"users,omitempty"`
+}
+
+// GiteaIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Gitea identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Gitea accounts and `auth.enableUsernameChanges` must be set to false for security reasons. When 'oauth' is used, the Gitea identity of a user is the account they signed in with through a Gitea authentication provider (in the `auth.providers` site configuration) for the same Gitea instance, and the repositories they can access are listed with their own OAuth token.
+type GiteaIdentityProvider struct {
+	Type string `json:"type"`
+}
+
+// GiteaRateLimit description: Rate limit applied when making background API requests to Gitea.
+type GiteaRateLimit struct {
+	// Enabled description: true if rate limiting is enabled.
+	Enabled bool `json:"enabled"`
+	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second. Internally, the burst limit is set to 500, which implies that for a requests per hour limit as low as 1, users will continue to be able to send a maximum of 500 requests immediately, provided that the complexity cost of each request is 1.
+	RequestsPerHour float64 `json:"requestsPerHour"`
+}
+
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
 	// Exclude description: A list of repositories to never mirror from this Gitolite instance. Supports excluding by exact name ({"name": "foo"}).
@@ -881,6 +1185,47 @@ type JVMPackagesConnection struct {
 	Maven *Maven `json:"maven,omitempty"`
 }
 
+// LDAPAttributes description: The attributes of the user's entry in the LDAP directory that are used for their Sourcegraph account.
+type LDAPAttributes struct {
+	// DisplayName description: The attribute that is the display name.
+	DisplayName string `json:"displayName,omitempty"`
+	// Email description: The attribute that is the email address, which is considered verified.
+	Email string `json:"email,omitempty"`
+	// Username description: The attribute that is the username, which is normalized.
+	Username string `json:"username,omitempty"`
+}
+
+// LDAPAuthProvider description: Configures the LDAP authentication provider, which authenticates users with the username and password of their entry in an LDAP directory, such as Active Directory.
+type LDAPAuthProvider struct {
+	// AllowGroups description: If set, only the members of at least one of these groups can sign in. Groups are given by their DN or by their common name (cn).
+	AllowGroups []string        `json:"allowGroups,omitempty"`
+	Attributes  *LDAPAttributes `json:"attributes,omitempty"`
+	// BaseDN description: The DN under which users are searched.
+	BaseDN string `json:"baseDN"`
+	// BindDN description: The DN of the service account that searches the directory for users and groups. If empty, the searches are anonymous.
+	BindDN string `json:"bindDN,omitempty"`
+	// BindPassword description: The password of the service account.
+	BindPassword string `json:"bindPassword,omitempty"`
+	// Certificate description: A PEM-encoded certificate of a certificate authority to trust when connecting to the LDAP server with TLS, in addition to the system's trusted certificates.
+	Certificate string `json:"certificate,omitempty"`
+	// ConfigID description: An identifier that can be used to reference this authentication provider in other parts of the config. It defaults to the URL of the LDAP server.
+	ConfigID    string `json:"configID,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	// GroupBaseDN description: The DN under which groups are searched. Defaults to baseDN.
+	GroupBaseDN string `json:"groupBaseDN,omitempty"`
+	// GroupFilter description: The LDAP filter that finds the groups which the user who signs in is a member of. {dn} is replaced with the DN of their entry and {username} with their username attribute.
+	GroupFilter string `json:"groupFilter,omitempty"`
+	// InsecureSkipVerify description: Whether to skip the verification of the TLS certificate of the LDAP server. This is insecure and should only be used for testing.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// StartTLS description: Whether to upgrade ldap:// connections to TLS with the StartTLS operation before sending credentials.
+	StartTLS bool   `json:"startTLS,omitempty"`
+	Type     string `json:"type"`
+	// Url description: The URL of the LDAP server. Use the ldaps:// scheme for LDAP over TLS, or set startTLS.
+	Url string `json:"url"`
+	// UserFilter description: The LDAP filter that finds the entry of the user who signs in. {username} is replaced with the username that they entered.
+	UserFilter string `json:"userFilter,omitempty"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	// Sentry description: Configuration for Sentry
@@ -1075,8 +1420,11 @@ type OpenIDConnectAuthProvider struct {
 	// For Google Apps: obtain this value from the API console (https://console.developers.google.com), as described at https://developers.google.com/identity/protocols/OpenIDConnect#getcredentials
 	ClientSecret string `json:"clientSecret"`
 	// ConfigID description: An identifier that can be used to reference this authentication provider in other parts of the config. For example, in configuration for a code host, you may want to designate this authentication provider as the identity provider for the code host.
-	ConfigID    string `json:"configID,omitempty"`
-	DisplayName string `json:"displayName,omitempty"`
+	ConfigID      string             `json:"configID,omitempty"`
+	DisplayName   string             `json:"displayName,omitempty"`
+	GroupMappings *AuthGroupMappings `json:"groupMappings,omitempty"`
+	// GroupsClaim description: The claim of the ID token or userinfo response that lists the groups of the user, which are mapped with groupMappings.
+	GroupsClaim string `json:"groupsClaim,omitempty"`
 	// Issuer description: The URL of the OpenID Connect issuer.
 	//
 	// For Google Apps: https://accounts.google.com
@@ -1098,7 +1446,7 @@ type OtherExternalServiceConnection struct {
 	Url                   string `json:"url,omitempty"`
 }
 type Overrides struct {
-	// Key description: The key that we want to override for example a username
+	// Key description: The key that we want to override: a user ID, or an IP address for anonymous users. Overrides only apply to GraphQL requests.
 	Key string `json:"key,omitempty"`
 	// Limit description: The limit per hour, 'unlimited' or 'blocked'
 	Limit interface{} `json:"limit,omitempty"`
@@ -1206,8 +1554,11 @@ type SAMLAuthProvider struct {
 	// AllowSignup description: Allows new visitors to sign up for accounts via SAML authentication. If false, users signing in via SAML must have an existing Sourcegraph account, which will be linked to their SAML identity after sign-in.
 	AllowSignup *bool `json:"allowSignup,omitempty"`
 	// ConfigID description: An identifier that can be used to reference this authentication provider in other parts of the config. For example, in configuration for a code host, you may want to designate this authentication provider as the identity provider for the code host.
-	ConfigID    string `json:"configID,omitempty"`
-	DisplayName string `json:"displayName,omitempty"`
+	ConfigID      string             `json:"configID,omitempty"`
+	DisplayName   string             `json:"displayName,omitempty"`
+	GroupMappings *AuthGroupMappings `json:"groupMappings,omitempty"`
+	// GroupsAttributeName description: The name of the SAML assertion attribute that lists the groups of the user, which are mapped with groupMappings.
+	GroupsAttributeName string `json:"groupsAttributeName,omitempty"`
 	// IdentityProviderMetadata description: The SAML Identity Provider metadata XML contents (for static configuration of the SAML Service Provider). The value of this field should be an XML document whose root element is `<EntityDescriptor>` or `<EntityDescriptors>`. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.
 	IdentityProviderMetadata string `json:"identityProviderMetadata,omitempty"`
 	// IdentityProviderMetadataURL description: The SAML Identity Provider metadata URL (for dynamic configuration of the SAML Service Provider).
@@ -1247,6 +1598,24 @@ type SMTPServerConfig struct {
 	Username string `json:"username,omitempty"`
 }
 
+// SearchArchives description: Configures the search of files in archives (.jar, .war, .ear, .zip, .tar, .tar.gz and .tgz files) in repositories. The files in an archive are searched at virtual paths like lib/foo.jar!/com/example/Foo.java. Archives are only expanded by unindexed search.
+type SearchArchives struct {
+	// Enabled description: Whether the files in archives are searched. Defaults to false.
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxBytes description: The maximum number of uncompressed bytes which are searched in the archives of a repository at a commit. Files in archives beyond this budget are only matched by their paths. Defaults to 100 MB.
+	MaxBytes int `json:"maxBytes,omitempty"`
+}
+
+// SearchJobs description: Configures search jobs, which run exhaustive searches in the background and store their results for download.
+type SearchJobs struct {
+	// Enabled description: Enables search jobs.
+	Enabled bool `json:"enabled,omitempty"`
+	// MaxActiveJobsPerUser description: The maximum number of search jobs of a user which are queued or running at once.
+	MaxActiveJobsPerUser int `json:"maxActiveJobsPerUser,omitempty"`
+	// MaxJobsPerUser description: The maximum number of search jobs a user can keep, including finished jobs. Finished jobs count until they are deleted or expire.
+	MaxJobsPerUser int `json:"maxJobsPerUser,omitempty"`
+}
+
 // SearchLimits description: Limits that search applies for number of repositories searched and timeouts.
 type SearchLimits struct {
 	// CommitDiffMaxRepos description: The maximum number of repositories to search across when doing a "type:diff" or "type:commit". The user is prompted to narrow their query if the limit is exceeded. There is a separate limit (commitDiffWithTimeFilterMaxRepos) when "after:" or "before:" is specified because those queries are faster. Defaults to 50.
@@ -1404,8 +1773,10 @@ type SettingsExperimentalFeatures struct {
 
 // SiteConfiguration description: Configuration for a Sourcegraph site.
 type SiteConfiguration struct {
-	// ApiRatelimit description: Configuration for API rate limiting
+	// ApiRatelimit description: Configuration for API rate limiting. The top-level limits apply to GraphQL requests and are counted in estimated query cost. Limits are stored in Redis and shared by all frontend instances.
 	ApiRatelimit *ApiRatelimit `json:"api.ratelimit,omitempty"`
+	// AuditLog description: Configuration for the audit log, which records every mutating GraphQL operation and admin API call. See https://docs.sourcegraph.com/admin/audit_log.
+	AuditLog *AuditLog `json:"auditLog,omitempty"`
 	// AuthAccessTokens description: Settings for access tokens, which enable external tools to access the Sourcegraph API with the privileges of the user.
 	AuthAccessTokens *AuthAccessTokens `json:"auth.accessTokens,omitempty"`
 	// AuthEnableUsernameChanges description: Enables users to change their username after account creation. Warning: setting this to be true has security implications if you have enabled (or will at any point in the future enable) repository permissions with an option that relies on username equivalency between Sourcegraph and an external service or authentication provider. Do NOT set this to true if you are using non-built-in authentication OR rely on username equivalency for repository permissions.
@@ -1418,6 +1789,8 @@ type SiteConfiguration struct {
 	AuthProviders []AuthProviders `json:"auth.providers,omitempty"`
 	// AuthPublic description: WARNING: This option has been removed as of 3.8.
 	AuthPublic bool `json:"auth.public,omitempty"`
+	// AuthScim description: Configures the SCIM 2.0 API at /.api/scim/v2, which identity providers use to provision and deprovision users (as SCIM Users) and organizations (as SCIM Groups).
+	AuthScim *AuthScim `json:"auth.scim,omitempty"`
 	// AuthSessionExpiry description: The duration of a user session, after which it expires and the user is required to re-authenticate. The default is 90 days. There is typically no need to set this, but some users may have specific internal security requirements.
 	//
 	// The string format is that of the Duration type in the Go time package (https://golang.org/pkg/time/#ParseDuration). E.g., "720h", "43200m", "2592000s" all indicate a timespan of 30 days.
@@ -1472,6 +1845,8 @@ type SiteConfiguration struct {
 	Dotcom *Dotcom `json:"dotcom,omitempty"`
 	// EmailAddress description: The "from" address for emails sent by this server.
 	EmailAddress string `json:"email.address,omitempty"`
+	// EmailHttp description: An HTTP API used to send transactional emails instead of an SMTP server, such as the API of an email delivery provider or a relay in front of one. Sourcegraph POSTs each message as JSON to the URL. If both `email.http` and `email.smtp` are set, `email.http` is used.
+	EmailHttp *EmailHTTPConfig `json:"email.http,omitempty"`
 	// EmailSmtp description: The SMTP server used to send transactional emails (such as email verifications, reset-password emails, and notifications).
 	EmailSmtp *SMTPServerConfig `json:"email.smtp,omitempty"`
 	// EncryptionKeys description: Configuration for encryption keys used to encrypt data at rest in the database.
@@ -1544,6 +1919,8 @@ type SiteConfiguration struct {
 	RepoConcurrentExternalServiceSyncers int `json:"repoConcurrentExternalServiceSyncers,omitempty"`
 	// RepoListUpdateInterval description: Interval (in minutes) for checking code hosts (such as GitHub, Gitolite, etc.) for new repositories.
 	RepoListUpdateInterval int `json:"repoListUpdateInterval,omitempty"`
+	// SearchArchives description: Configures the search of files in archives (.jar, .war, .ear, .zip, .tar, .tar.gz and .tgz files) in repositories. The files in an archive are searched at virtual paths like lib/foo.jar!/com/example/Foo.java. Archives are only expanded by unindexed search.
+	SearchArchives *SearchArchives `json:"search.archives,omitempty"`
 	// SearchIndexEnabled description: Whether indexed search is enabled. If unset Sourcegraph detects the environment to decide if indexed search is enabled. Indexed search is RAM heavy, and is disabled by default in the single docker image. All other environments will have it enabled by default. The size of all your repository working copies is the amount of additional RAM required.
 	SearchIndexEnabled *bool `json:"search.index.enabled,omitempty"`
 	// SearchIndexSymbolsEnabled description: Whether indexed symbol search is enabled. This is contingent on the indexed search configuration, and is true by default for instances with indexed search enabled. Enabling this will cause every repository to re-index, which is a time consuming (several hours) operation. Additionally, it requires more storage and ram to accommodate the added symbols information in the search index.
@@ -1578,6 +1955,16 @@ type Step struct {
 	Run string `json:"run"`
 }
 
+// Syslog description: Forward audit log entries as JSON messages to a syslog server, such as a SIEM.
+type Syslog struct {
+	// Address description: The host:port address of the syslog server.
+	Address string `json:"address"`
+	// Network description: The network protocol used to connect to the syslog server.
+	Network string `json:"network,omitempty"`
+	// Tag description: The syslog tag of the messages.
+	Tag string `json:"tag,omitempty"`
+}
+
 // TlsExternal description: Global TLS/SSL settings for Sourcegraph to use when communicating with code hosts.
 type TlsExternal struct {
 	// Certificates description: TLS certificates to accept. This is only necessary if you are using self-signed certificates or an internal CA. Can be an internal CA certificate or a self-signed certificate. To get the certificate of a webserver run `openssl s_client -connect HOST:443 -showcerts < /dev/null 2> /dev/null | openssl x509 -outform PEM`. To escape the value into a JSON string, you may want to use a tool like https://json-escape-text.now.sh.